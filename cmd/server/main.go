@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errandShop/config"
 	"errandShop/internal/database"
 	"errandShop/internal/domain/analytics"
@@ -14,14 +15,20 @@ import (
 	"errandShop/internal/domain/orders"
 	"errandShop/internal/domain/payments"
 	"errandShop/internal/domain/products"
+	"errandShop/internal/domain/reviews"
+	"errandShop/internal/domain/webhooks"
 
 	"errandShop/internal/middleware"
 	"errandShop/internal/services/audit"
 	"errandShop/internal/services/email"
+	"errandShop/internal/services/sms"
+	"errandShop/internal/services/upload"
 	v1 "errandShop/internal/transport/http/v1"
 	"fmt"
 	"log"
 	"strings"
+	"time"
+	_ "time/tzdata" // embedded zone data so STORE_TIMEZONE resolves on hosts without it
 
 	"errandShop/internal/http/handlers"
 	"errandShop/internal/repos"
@@ -38,37 +45,16 @@ func (t *tempPaymentService) InitializePayment(req payments.CreatePaymentRequest
 	return nil, fmt.Errorf("payment service not yet initialized")
 }
 
-// CORS CSV built once from defaults + ALLOWED_ORIGINS / AllowedOrigins env (via config).
+// CORS CSV built once from ALLOWED_ORIGINS / AllowedOrigins env (via config, which supplies defaults).
 var corsAllowCSV string
 
 func initCORSAllowList(cfg *config.Config) {
-	seen := make(map[string]struct{})
-	out := []string{}
-	add := func(s string) {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			return
-		}
-		if _, ok := seen[s]; ok {
-			return
-		}
-		seen[s] = struct{}{}
-		out = append(out, s)
-	}
-	for _, o := range []string{
-		"https://v0-errand-shop-dashboard.vercel.app",
-		"https://v0-errand-shop-dashboard-git-main-ronalking182s-projects.vercel.app",
-		"https://v0-errand-shop-dashboard-jcjvf4fer-ronalking182s-projects.vercel.app",
-		"http://localhost:5173",
-		"http://localhost:3000",
-		"http://localhost:3001",
-	} {
-		add(o)
+	origins := cfg.AllowedOriginList()
+	if len(origins) == 0 {
+		log.Fatal("No valid CORS origins configured (check ALLOWED_ORIGINS)")
 	}
-	for _, o := range strings.Split(cfg.AllowedOrigins, ",") {
-		add(o)
-	}
-	corsAllowCSV = strings.Join(out, ",")
+	corsAllowCSV = strings.Join(origins, ",")
+	log.Printf("🌍 CORS allowed origins: %s", corsAllowCSV)
 }
 
 func allowedOrigins() string { return corsAllowCSV }
@@ -233,10 +219,28 @@ func main() {
 	// Update auth service initialization with customer service
 	authService := auth.NewService(authRepo, cfg, emailService, auditService, customersService)
 
+	// Optional SMS channel for OTP fallback and transactional notifications
+	smsSender, err := sms.NewSender(cfg.SMSProvider, sms.Config{
+		TermiiAPIKey:     cfg.SMSAPIKey,
+		TermiiSenderID:   cfg.SMSSenderID,
+		TwilioAccountSID: cfg.TwilioAccountSID,
+		TwilioAuthToken:  cfg.TwilioAuthToken,
+		TwilioFromPhone:  cfg.TwilioFromPhone,
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid SMS configuration: %v", err)
+	}
+	if smsSender != nil {
+		authService.SetSMSSender(smsSender)
+		log.Printf("✅ SMS provider enabled: %s", cfg.SMSProvider)
+	}
+
 	// Add rate limiting
 	app.Use("/api/v1/auth", middleware.AuthRateLimit())
 	app.Use("/api/v1", middleware.APIRateLimit())
 	authHandler := auth.NewHandler(authService)
+	// Impersonation tokens are checked against their session and audited on every request
+	middleware.SetImpersonationTracker(authService)
 	log.Println("✅ Authentication domain initialized")
 
 	// 🛣️ API Routes Setup
@@ -291,22 +295,31 @@ func main() {
 	// 🔒 Protected Authentication Routes (JWT Required)
 	log.Println("🔒 Configuring protected auth routes...")
 	protectedAuth := authRoutes.Group("", middleware.JWTMiddleware(cfg))
-	protectedAuth.Post("/logout", authHandler.Logout)                  // 🚪 User logout
-	protectedAuth.Get("/me", authHandler.Me)                           // 👤 Get current user info
-	protectedAuth.Post("/password/change", authHandler.ChangePassword) // 🔑 Change password
+	protectedAuth.Post("/logout", middleware.NoImpersonation(), authHandler.Logout)                  // 🚪 User logout
+	protectedAuth.Get("/me", authHandler.Me)                                                         // 👤 Get current user info
+	protectedAuth.Get("/me/full", authHandler.MeFull)                                                // 🧾 User + customer profile + default address
+	protectedAuth.Get("/me/export", middleware.NoImpersonation(), authHandler.ExportMyData)          // 📦 Download all personal data
+	protectedAuth.Delete("/me", middleware.NoImpersonation(), authHandler.DeleteMyAccount)           // 🗑️ Delete own account (password confirmed)
+	protectedAuth.Post("/password/change", middleware.NoImpersonation(), authHandler.ChangePassword) // 🔑 Change password
+	protectedAuth.Post("/phone/send-code", middleware.NoImpersonation(), authHandler.SendPhoneCode)  // 📲 Text a phone verification code
+	protectedAuth.Post("/phone/verify", middleware.NoImpersonation(), authHandler.VerifyPhone)       // ✅ Confirm phone number
+	protectedAuth.Get("/sessions", authHandler.ListSessions)                                         // 📱 Active sessions
+	protectedAuth.Delete("/sessions/:id", middleware.NoImpersonation(), authHandler.RevokeSession)   // 🚪 Sign out one session
+	protectedAuth.Post("/impersonation/end", authHandler.EndImpersonation)                           // 🎭 End a support impersonation session
 
 	// 👑 Admin Routes (JWT + Admin Role Required)
 	log.Println("👑 Configuring admin routes...")
 	adminRoutes := api.Group("/admin", middleware.JWTMiddleware(cfg), middleware.AdminMiddleware())
-	adminRoutes.Get("/users", authHandler.GetUsers)                                // 👥 List all users
-	adminRoutes.Post("/users", authHandler.CreateUser)                             // ➕ Create new user
-	adminRoutes.Get("/users/:id", authHandler.GetUserByID)                         // 👤 Get user by ID
-	adminRoutes.Put("/users/:id", authHandler.UpdateUser)                          // ✏️ Update user
-	adminRoutes.Delete("/users/:id", authHandler.DeleteUser)                       // 🗑️ Delete user
-	adminRoutes.Patch("/users/:id/status", authHandler.UpdateUserStatus)           // 🔄 Update user status
-	adminRoutes.Get("/permissions/available", authHandler.GetAvailablePermissions) // 📋 Get available permissions
-	adminRoutes.Put("/users/:id/permissions", authHandler.UpdateUserPermissions)   // 🔐 Update permissions
-	adminRoutes.Put("/users/:id/force-reset", authHandler.ForcePasswordReset)      // 🔒 Force password reset
+	adminRoutes.Get("/users", authHandler.GetUsers)                                                            // 👥 List all users
+	adminRoutes.Post("/users", authHandler.CreateUser)                                                         // ➕ Create new user
+	adminRoutes.Get("/users/:id", authHandler.GetUserByID)                                                     // 👤 Get user by ID
+	adminRoutes.Put("/users/:id", authHandler.UpdateUser)                                                      // ✏️ Update user
+	adminRoutes.Delete("/users/:id", authHandler.DeleteUser)                                                   // 🗑️ Delete user
+	adminRoutes.Patch("/users/:id/status", authHandler.UpdateUserStatus)                                       // 🔄 Update user status
+	adminRoutes.Get("/permissions/available", authHandler.GetAvailablePermissions)                             // 📋 Get available permissions
+	adminRoutes.Put("/users/:id/permissions", authHandler.UpdateUserPermissions)                               // 🔐 Update permissions
+	adminRoutes.Put("/users/:id/force-reset", authHandler.ForcePasswordReset)                                  // 🔒 Force password reset
+	adminRoutes.Post("/users/:id/impersonate", middleware.SuperAdminMiddleware(), authHandler.ImpersonateUser) // 🎭 Act as a user for support
 
 	// 🔍 Admin-only DB introspection endpoint for incident diagnostics
 	adminRoutes.Get("/system/db", func(c *fiber.Ctx) error {
@@ -391,12 +404,27 @@ func main() {
 	log.Println("🛍️ Setting up products domain...")
 	productsRepo := products.NewRepository(db)
 	productsService := products.NewService(productsRepo)
+	storeLocation, err := time.LoadLocation(cfg.StoreTimezone)
+	if err != nil {
+		log.Printf("⚠️ Invalid STORE_TIMEZONE %q: %v; using UTC for product availability", cfg.StoreTimezone, err)
+		storeLocation = time.UTC
+	}
+	productsService.SetStoreLocation(storeLocation)
+	productsService.SetBestsellerWindow(cfg.BestsellerWindow)
+	productsService.SetShowOutOfStock(cfg.ShowOutOfStockProducts)
+	cloudinaryService := upload.NewCloudinaryService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, cfg.CloudinaryFolder)
+	if cloudinaryService.Configured() {
+		productsService.SetImageStore(cloudinaryService)
+		log.Println("✅ Cloudinary image uploads enabled")
+	} else {
+		log.Println("⚠️ Cloudinary not configured; product image uploads disabled")
+	}
 	productsHandler := products.NewHandler(productsService)
 	log.Println("✅ Products domain initialized (using external image hosting)")
 
 	// Configure product routes
 	log.Println("🛍️ Configuring public product routes...")
-	v1.MountProductRoutes(api, productsHandler)
+	v1.MountProductRoutes(api, productsHandler, middleware.OptionalJWTMiddleware(cfg))
 	log.Println("👑 Configuring admin product routes...")
 	v1.MountAdminProductRoutes(adminRoutes, productsHandler)
 
@@ -405,6 +433,33 @@ func main() {
 	superAdminRoutes := adminRoutes.Group("", middleware.SuperAdminMiddleware())
 	v1.MountSuperAdminCategoryRoutes(superAdminRoutes, productsHandler)
 
+	// 🧾 SuperAdmin-only migration status (applied/pending per migration ID)
+	superAdminRoutes.Get("/system/migrations", func(c *fiber.Ctx) error {
+		statuses, err := database.GetMigrationStatuses(db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+
+		pending := 0
+		for _, s := range statuses {
+			if s.Pending {
+				pending++
+			}
+		}
+
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data": fiber.Map{
+				"migrations": statuses,
+				"total":      len(statuses),
+				"pending":    pending,
+			},
+		})
+	})
+
 	// 👥 Setup Customers Routes (service already initialized above)
 	log.Println("👥 Setting up customers routes...")
 	customersHandler := customers.NewHandler(customersService)
@@ -434,6 +489,14 @@ func main() {
 	templateRepo := notifications.NewTemplateRepository(db)
 	pushTokenRepo := notifications.NewPushTokenRepository(db)
 	notificationService := notifications.NewNotificationService(notificationRepo, templateRepo, pushTokenRepo)
+	notificationService.SetRetryPolicy(cfg.NotificationMaxSendAttempts, cfg.NotificationRetryBackoff)
+	if smsSender != nil {
+		notificationService.SetSMSSender(smsSender, authService.VerifiedPhone)
+	}
+	notificationService.SetDigestEmailer(emailService, authService.VerifiedEmail)
+	notificationService.SetDefaultTimezone(storeLocation)
+	notifications.NewRetryWorker(notificationService, cfg.NotificationRetryInterval).Start(context.Background())
+	notifications.NewDigestJob(notificationService, cfg.NotificationDigestHour).Start(context.Background())
 	notificationHandler := notifications.NewNotificationHandler(notificationService)
 	notifications.SetupRoutes(app, cfg, notificationHandler)
 	notifications.SetupAdminRoutes(app, cfg, notificationHandler)
@@ -482,16 +545,59 @@ func main() {
 	// Initialize delivery service (needed by orders)
 	deliveryRepo := delivery.NewDeliveryRepository(db)
 	deliveryService := delivery.NewDeliveryService(deliveryRepo, notificationService, ordersRepo, customersService)
+	deliveryService.SetProofThreshold(cfg.ProofOfDeliveryThresholdKobo)
+	deliveryService.SetWeightPricing(cfg.DeliveryFreeWeightGrams, cfg.DeliveryPerKgSurchargeKobo)
+	deliveryService.SetDriverFeeShare(cfg.DriverFeeSharePercent)
+	deliveryService.SetMaxFailedAttempts(cfg.MaxFailedDeliveryAttempts)
+	if cloudinaryService.Configured() {
+		deliveryService.SetProofAssetStore(cloudinaryService) // erased with the customer's data
+	}
+	if deliveryHandler != nil {
+		deliveryService.SetZoneMatcher(deliveryHandler.GetMatcher())
+	}
+
+	// Outbound order webhooks
+	webhooksService := webhooks.NewService(webhooks.NewRepository(db))
+	webhooksService.SetRetryPolicy(cfg.WebhookMaxAttempts, cfg.WebhookRetryBackoff)
+	webhooks.NewRetryWorker(webhooksService, cfg.WebhookRetryInterval).Start(context.Background())
+	webhooks.SetupAdminRoutes(app, webhooks.NewHandler(webhooksService), cfg)
 
 	// Initialize orders service first (without payments service)
 	var ordersService *orders.Service
 	ordersService = orders.NewService(ordersRepo, productsRepo, couponsService, customersService, authService, &tempPaymentService{}, deliveryService, addressRepo, deliveryMatcher, notificationService, customRequestsService, db)
+	// Payments keeps this first instance, so it needs the dispatcher for order.paid too
+	ordersService.SetWebhookDispatcher(webhooksService)
 
 	// Now initialize payments service with orders service
 	paymentsService := payments.NewService(paymentsRepo, paystackClient, ordersService)
 
 	// Update orders service with real payments service
 	ordersService = orders.NewService(ordersRepo, productsRepo, couponsService, customersService, authService, paymentsService, deliveryService, addressRepo, deliveryMatcher, notificationService, customRequestsService, db)
+	ordersService.SetMinOrderSubtotal(cfg.MinOrderSubtotalKobo)
+	if err := ordersService.SetCustomerCancelCutoff(orders.OrderStatus(cfg.CustomerCancelCutoff)); err != nil {
+		log.Printf("⚠️ %v; keeping default customer cancel cutoff", err)
+	}
+	ordersService.SetIdempotencyTTL(cfg.OrderIdempotencyTTL)
+	ordersService.SetDuplicateOrderWindow(cfg.DuplicateOrderWindow)
+	ordersService.SetScheduling(cfg.ScheduledOrderLeadTime, cfg.ScheduledOrderMaxAhead)
+	ordersService.SetReferralReward(cfg.ReferralRewardKobo)
+	ordersService.SetVATRate(cfg.VATRatePercent)
+	ordersService.SetTaxInclusive(cfg.TaxInclusivePricing)
+	ordersService.SetStockReservationTTL(cfg.StockReservationTTL)
+	ordersService.SetStoreLocation(storeLocation)
+	ordersService.SetEmailVerifier(authService, cfg.RequireVerifiedEmailForOrders)
+	ordersService.SetRefunder(paymentsService)
+	ordersService.SetDeliveryTracker(deliveryService)
+	ordersService.SetWebhookDispatcher(webhooksService)
+	productsService.SetWishlistChecker(ordersService) // flags saved products on product detail
+	// Account data export and deletion; orders go first as they refuse while one is in progress
+	authService.SetAccountDataSources(
+		auth.NamedAccountDataSource{Name: "orders", Source: ordersService},
+		auth.NamedAccountDataSource{Name: "profile", Source: customersService},
+		auth.NamedAccountDataSource{Name: "customRequests", Source: customRequestsService},
+		auth.NamedAccountDataSource{Name: "deliveries", Source: deliveryService},
+		auth.NamedAccountDataSource{Name: "pushDevices", Source: notificationService},
+	)
 
 	// Setup payments routes
 	paymentsHandler := payments.NewHandler(paymentsService)
@@ -506,6 +612,40 @@ func main() {
 	orders.SetupRoutes(app, cfg, ordersHandler, cartHandler, couponHandler)
 	log.Println("✅ Orders domain with cart functionality initialized")
 
+	// 🛒 Abandoned cart reminders
+	orders.NewCartReminderJob(db, notificationService, cfg.CartReminderAfter, cfg.CartReminderMaxReminders).Start(context.Background())
+	log.Println("✅ Abandoned cart reminder job scheduled")
+
+	// 👋 Welcome-back coupons for lapsed customers
+	orders.NewWinBackJob(db, couponsService, notificationService, cfg.WinBackAfter, cfg.WinBackCooldown, cfg.WinBackCouponPercent).Start(context.Background())
+	log.Println("✅ Win-back coupon job scheduled")
+
+	// 📅 Release scheduled orders for fulfilment
+	orders.NewScheduledOrderJob(ordersService).Start(context.Background())
+	log.Println("✅ Scheduled order release job started")
+
+	// ⏳ Release stock held by orders that were never paid
+	orders.NewStockReservationJob(ordersService).Start(context.Background())
+	log.Println("✅ Stock reservation expiry job started")
+
+	// ⏳ Custom request quote expiry reminders and expiry processing
+	customRequestsService.SetNotificationService(notificationService)
+	customRequestsService.SetCatalogSearch(productsService)
+	customRequestsService.SetResponseSLA(map[custom_requests.RequestPriority]time.Duration{
+		custom_requests.PriorityUrgent: cfg.CustomRequestSLAUrgent,
+		custom_requests.PriorityHigh:   cfg.CustomRequestSLAHigh,
+		custom_requests.PriorityMedium: cfg.CustomRequestSLAMedium,
+		custom_requests.PriorityLow:    cfg.CustomRequestSLALow,
+	})
+	if err := customRequestsService.SetBudgetCapMode(custom_requests.BudgetCapMode(cfg.CustomRequestBudgetCapMode)); err != nil {
+		log.Printf("⚠️ %v; keeping default budget cap mode", err)
+	}
+	if err := customRequestsService.SetAutoAssignMode(custom_requests.AutoAssignMode(cfg.CustomRequestAutoAssignMode)); err != nil {
+		log.Printf("⚠️ %v; auto-assignment stays off", err)
+	}
+	custom_requests.NewQuoteExpiryJob(customRequestsService, cfg.QuoteExpiryReminderBefore).Start(context.Background())
+	log.Println("✅ Quote expiry job scheduled")
+
 	// 🚚 Setup Delivery Routes (service and costing already initialized above)
 	log.Println("🚚 Setting up delivery routes...")
 	if deliveryHandler != nil {
@@ -519,10 +659,26 @@ func main() {
 	}
 	log.Println("✅ Delivery routes initialized")
 
+	// ⭐ Initialize Reviews Domain
+	log.Println("⭐ Setting up reviews domain...")
+	reviewsRepo := reviews.NewRepository(db)
+	reviewsService := reviews.NewService(reviewsRepo)
+	reviewsService.SetFeedbackWindow(cfg.DeliveryFeedbackWindow)
+	reviewsService.SetTipPayments(paymentsService)
+	paymentsService.SetTipRecorder(reviewsService)
+	reviewsHandler := reviews.NewHandler(reviewsService)
+	reviews.SetupRoutes(app, reviewsHandler, cfg)
+	log.Println("✅ Reviews domain initialized")
+
 	// 📊 Initialize Analytics Domain
 	log.Println("📊 Setting up analytics domain...")
 	analyticsRepo := analytics.NewAnalyticsRepository(db)
 	analyticsService := analytics.NewAnalyticsService(analyticsRepo)
+	liveSales := analytics.NewLiveSalesCounter(analyticsRepo, storeLocation, cfg.LiveSalesReconcileInterval)
+	analyticsService.SetLiveSalesCounter(liveSales)
+	ordersService.SetSaleRecorder(liveSales)
+	ordersService.SetFunnelRecorder(analytics.NewFunnelTracker(db))
+	liveSales.Start(context.Background())
 	analyticsHandler := analytics.NewAnalyticsHandler(analyticsService)
 	analytics.SetupAnalyticsRoutes(app, analyticsHandler, cfg)
 	log.Println("✅ Analytics domain initialized")
@@ -29,17 +29,133 @@ type Config struct {
 	JWTRefreshSecret string
 	AccessTTL        time.Duration
 	RefreshTTL       time.Duration
+	ImpersonationTTL time.Duration // Lifetime of a support impersonation token
 
 	// Payment & Notifications
 	FCMServerKey             string
 	UploadBucketURL          string
 
+	// Cloudinary (product images)
+	CloudinaryCloudName      string
+	CloudinaryAPIKey         string
+	CloudinaryAPISecret      string
+	CloudinaryFolder         string
+
 	// Paystack Configuration
 	PaystackSecretKey        string
 	PaystackPublicKey        string
 	PaystackWebhookSecret    string
 	AppBaseURL               string
 	CallbackURL              string
+
+	// Orders
+	MinOrderSubtotalKobo     int64 // Global minimum items subtotal; delivery zones may override it
+	CustomerCancelCutoff     string // Last order status at which customers may self-cancel
+	OrderIdempotencyTTL      time.Duration // How long an idempotency key returns the order it created
+	DuplicateOrderWindow     time.Duration // How long an identical order sent without a key returns the first; 0 disables
+	ScheduledOrderLeadTime   time.Duration // Minimum notice for a scheduled order; also how early it is released for fulfilment
+	ScheduledOrderMaxAhead   time.Duration // Furthest ahead an order may be scheduled
+	ReferralRewardKobo       int64 // Coupon each side of a referral gets on the first delivered order (0 disables)
+	VATRatePercent           float64 // VAT added to new orders, e.g. 7.5 (0 disables)
+	TaxInclusivePricing      bool    // Prices already include VAT; orders back it out instead of adding it
+	StockReservationTTL      time.Duration // How long an unpaid online-payment order holds its stock (0 disables expiry)
+	StoreTimezone            string // IANA timezone product availability schedules are read in
+	RequireVerifiedEmailForOrders bool // Checkout needs a verified email; browsing and carts stay open
+	BestsellerWindow         time.Duration // Trailing window of sales ranked on the bestsellers rail
+	ShowOutOfStockProducts   bool // List zero-stock products (not purchasable) instead of hiding them
+
+	// Abandoned cart reminders
+	CartReminderAfter        time.Duration // Idle time before a cart counts as abandoned
+	CartReminderMaxReminders int           // Reminders per cart before giving up (0 disables the job)
+
+	// Win-back coupons for lapsed customers
+	WinBackAfter         time.Duration // Time since the last delivered order before a customer counts as lapsed
+	WinBackCooldown      time.Duration // Minimum time between win-back coupons to the same customer
+	WinBackCouponPercent float64       // Percentage off on the win-back coupon (0 disables the job)
+
+	// Custom request quotes
+	QuoteExpiryReminderBefore time.Duration // How long before ValidUntil customers are reminded (0 disables reminders)
+
+	// Custom request response SLA: time from submission to first admin action, per priority
+	CustomRequestSLAUrgent time.Duration
+	CustomRequestSLAHigh   time.Duration
+	CustomRequestSLAMedium time.Duration
+	CustomRequestSLALow    time.Duration
+
+	// What happens when a quote is above the customer's budget cap: "warn" flags it, "block" rejects it
+	CustomRequestBudgetCapMode string
+
+	// How new custom requests are handed to the admin assignee pool: "off", "round_robin" or "least_loaded"
+	CustomRequestAutoAssignMode string
+
+	// Delivery
+	ProofOfDeliveryThresholdKobo int64         // Orders at or above this total need proof to be marked delivered (0 disables)
+	DeliveryFreeWeightGrams      int           // Order weight included in the delivery fee
+	DeliveryPerKgSurchargeKobo   int64         // Charged per started kg above the free weight (0 disables)
+	DriverFeeSharePercent        int           // Driver's cut of each delivery fee; tips always go to the driver in full
+	MaxFailedDeliveryAttempts    int           // Failed hand-overs before a delivery needs admin action
+	DeliveryFeedbackWindow       time.Duration // How long after delivery a customer can rate and tip the driver (0 means no limit)
+
+	// Notification push retries
+	NotificationMaxSendAttempts int           // Attempts before a push is dead-lettered
+	NotificationRetryBackoff    time.Duration // First retry delay; doubles on every failure
+	NotificationRetryInterval   time.Duration // How often the retry worker runs (0 disables it)
+	NotificationDigestHour      int           // Local hour (0-23) the daily digest goes out; negative disables it
+
+	// Outbound order webhooks
+	WebhookMaxAttempts   int           // Attempts before a delivery is dead-lettered
+	WebhookRetryBackoff  time.Duration // First retry delay; doubles on every failure
+	WebhookRetryInterval time.Duration // How often the retry worker runs (0 disables it)
+
+	// Live sales counter on the admin dashboard
+	LiveSalesReconcileInterval time.Duration // How often the running total is corrected from the orders table (0 disables it)
+
+	// SMS channel: "termii", "twilio" or empty to disable
+	SMSProvider string
+	SMSAPIKey   string // Termii API key
+	SMSSenderID string // Termii sender ID
+
+	// Per-user rate limits (requests per minute, keyed on the JWT subject)
+	OrderCreateRateLimit     int
+	CouponGenerateRateLimit  int
+}
+
+// defaultAllowedOrigins is used for CORS when ALLOWED_ORIGINS is not configured
+var defaultAllowedOrigins = []string{
+	"https://v0-errand-shop-dashboard.vercel.app",
+	"https://v0-errand-shop-dashboard-git-main-ronalking182s-projects.vercel.app",
+	"https://v0-errand-shop-dashboard-jcjvf4fer-ronalking182s-projects.vercel.app",
+	"http://localhost:5173",
+	"http://localhost:3000",
+	"http://localhost:3001",
+}
+
+// AllowedOriginList returns the configured CORS origins, trimmed and de-duplicated.
+// Wildcards and malformed entries are dropped: credentials are always allowed, and
+// browsers reject "Access-Control-Allow-Origin: *" on credentialed requests.
+func (c *Config) AllowedOriginList() []string {
+	seen := make(map[string]struct{})
+	origins := []string{}
+	for _, o := range strings.Split(c.AllowedOrigins, ",") {
+		o = strings.TrimRight(strings.TrimSpace(o), "/")
+		if o == "" {
+			continue
+		}
+		if strings.Contains(o, "*") {
+			log.Printf("Warning: ignoring wildcard CORS origin %q (not allowed with credentials)", o)
+			continue
+		}
+		if !strings.HasPrefix(o, "http://") && !strings.HasPrefix(o, "https://") {
+			log.Printf("Warning: ignoring CORS origin %q (must start with http:// or https://)", o)
+			continue
+		}
+		if _, ok := seen[o]; ok {
+			continue
+		}
+		seen[o] = struct{}{}
+		origins = append(origins, o)
+	}
+	return origins
 }
 
 // Add to LoadConfig() function
@@ -69,7 +185,8 @@ func LoadConfig() *Config {
         allowedOrigins = os.Getenv("ALLOWED_ORIGINS")
     }
     if allowedOrigins == "" {
-        log.Fatal("AllowedOrigins url is missing")
+        log.Println("Warning: ALLOWED_ORIGINS not set, using default dashboard and localhost origins")
+        allowedOrigins = strings.Join(defaultAllowedOrigins, ",")
     }
 
 	versionStr := os.Getenv("VERSION")
@@ -108,17 +225,93 @@ func LoadConfig() *Config {
 		TwilioAuthToken:  twilioToken,
 		TwilioFromPhone:  twilioFrom,
 		JWTSecret:        jwtSecret,
+		ImpersonationTTL: time.Duration(getEnvInt("IMPERSONATION_TOKEN_MINUTES", 30)) * time.Minute,
 
 		// Notifications
 		FCMServerKey:             getEnv("FCM_SERVER_KEY", ""),
 		UploadBucketURL:          getEnv("UPLOAD_BUCKET_URL", ""),
 
+		// Cloudinary
+		CloudinaryCloudName:      getEnv("CLOUDINARY_CLOUD_NAME", ""),
+		CloudinaryAPIKey:         getEnv("CLOUDINARY_API_KEY", ""),
+		CloudinaryAPISecret:      getEnv("CLOUDINARY_API_SECRET", ""),
+		CloudinaryFolder:         getEnv("CLOUDINARY_FOLDER", "errandshop/products"),
+
 		// Paystack Configuration
 		PaystackSecretKey:        getEnv("PAYSTACK_SECRET_KEY", ""),
 		PaystackPublicKey:        getEnv("PAYSTACK_PUBLIC_KEY", ""),
 		PaystackWebhookSecret:    getEnv("PAYSTACK_WEBHOOK_SECRET", ""),
 		AppBaseURL:               getEnv("APP_BASE_URL", "http://localhost:9090"),
 		CallbackURL:              getEnv("CALLBACK_URL", ""),
+
+		// Orders
+		MinOrderSubtotalKobo:     int64(getEnvInt("MIN_ORDER_SUBTOTAL_KOBO", 0)),
+		CustomerCancelCutoff:     getEnv("CUSTOMER_CANCEL_CUTOFF_STATUS", "preparing"),
+		OrderIdempotencyTTL:      time.Duration(getEnvInt("ORDER_IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour,
+		DuplicateOrderWindow:     time.Duration(getEnvInt("DUPLICATE_ORDER_WINDOW_SECONDS", 30)) * time.Second,
+		ScheduledOrderLeadTime:   time.Duration(getEnvInt("SCHEDULED_ORDER_LEAD_MINUTES", 60)) * time.Minute,
+		ScheduledOrderMaxAhead:   time.Duration(getEnvInt("SCHEDULED_ORDER_MAX_DAYS", 7)) * 24 * time.Hour,
+		ReferralRewardKobo:       int64(getEnvInt("REFERRAL_REWARD_KOBO", 100000)),
+		VATRatePercent:           getEnvFloat("VAT_RATE_PERCENT", 0),
+		TaxInclusivePricing:      getEnvBool("TAX_INCLUSIVE_PRICING", false),
+		StockReservationTTL:      time.Duration(getEnvInt("STOCK_RESERVATION_MINUTES", 15)) * time.Minute,
+		StoreTimezone:            getEnv("STORE_TIMEZONE", "Africa/Lagos"),
+		RequireVerifiedEmailForOrders: getEnvBool("REQUIRE_VERIFIED_EMAIL_FOR_ORDERS", true),
+		BestsellerWindow:         time.Duration(getEnvInt("BESTSELLER_WINDOW_DAYS", 30)) * 24 * time.Hour,
+		ShowOutOfStockProducts:   getEnvBool("SHOW_OUT_OF_STOCK_PRODUCTS", true),
+
+		// Abandoned cart reminders
+		CartReminderAfter:        time.Duration(getEnvInt("CART_REMINDER_AFTER_HOURS", 24)) * time.Hour,
+		CartReminderMaxReminders: getEnvInt("CART_REMINDER_MAX_REMINDERS", 2),
+
+		// Win-back coupons for lapsed customers
+		WinBackAfter:         time.Duration(getEnvInt("WINBACK_AFTER_DAYS", 60)) * 24 * time.Hour,
+		WinBackCooldown:      time.Duration(getEnvInt("WINBACK_COOLDOWN_DAYS", 90)) * 24 * time.Hour,
+		WinBackCouponPercent: getEnvFloat("WINBACK_COUPON_PERCENT", 10),
+
+		// Custom request quotes
+		QuoteExpiryReminderBefore: time.Duration(getEnvInt("QUOTE_EXPIRY_REMINDER_HOURS", 24)) * time.Hour,
+
+		// Custom request response SLA
+		CustomRequestSLAUrgent: time.Duration(getEnvInt("CUSTOM_REQUEST_SLA_URGENT_MINUTES", 60)) * time.Minute,
+		CustomRequestSLAHigh:   time.Duration(getEnvInt("CUSTOM_REQUEST_SLA_HIGH_MINUTES", 240)) * time.Minute,
+		CustomRequestSLAMedium: time.Duration(getEnvInt("CUSTOM_REQUEST_SLA_MEDIUM_MINUTES", 720)) * time.Minute,
+		CustomRequestSLALow:    time.Duration(getEnvInt("CUSTOM_REQUEST_SLA_LOW_MINUTES", 1440)) * time.Minute,
+
+		CustomRequestBudgetCapMode: getEnv("CUSTOM_REQUEST_BUDGET_CAP_MODE", "warn"),
+
+		CustomRequestAutoAssignMode: getEnv("CUSTOM_REQUEST_AUTO_ASSIGN_MODE", "off"),
+
+		// Delivery
+		ProofOfDeliveryThresholdKobo: int64(getEnvInt("PROOF_OF_DELIVERY_THRESHOLD_KOBO", 5000000)),
+		DeliveryFreeWeightGrams:      getEnvInt("DELIVERY_FREE_WEIGHT_GRAMS", 5000),
+		DeliveryPerKgSurchargeKobo:   int64(getEnvInt("DELIVERY_PER_KG_SURCHARGE_KOBO", 0)),
+		DriverFeeSharePercent:        getEnvInt("DRIVER_FEE_SHARE_PERCENT", 80),
+		MaxFailedDeliveryAttempts:    getEnvInt("MAX_FAILED_DELIVERY_ATTEMPTS", 3),
+		DeliveryFeedbackWindow:       time.Duration(getEnvInt("DELIVERY_FEEDBACK_WINDOW_DAYS", 7)) * 24 * time.Hour,
+
+		// Notification push retries
+		NotificationMaxSendAttempts: getEnvInt("NOTIFICATION_MAX_SEND_ATTEMPTS", 5),
+		NotificationRetryBackoff:    time.Duration(getEnvInt("NOTIFICATION_RETRY_BACKOFF_SECONDS", 60)) * time.Second,
+		NotificationRetryInterval:   time.Duration(getEnvInt("NOTIFICATION_RETRY_INTERVAL_SECONDS", 60)) * time.Second,
+		NotificationDigestHour:      getEnvInt("NOTIFICATION_DIGEST_HOUR", 18),
+
+		// Outbound order webhooks
+		WebhookMaxAttempts:   getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+		WebhookRetryBackoff:  time.Duration(getEnvInt("WEBHOOK_RETRY_BACKOFF_SECONDS", 60)) * time.Second,
+		WebhookRetryInterval: time.Duration(getEnvInt("WEBHOOK_RETRY_INTERVAL_SECONDS", 60)) * time.Second,
+
+		// Live sales counter
+		LiveSalesReconcileInterval: time.Duration(getEnvInt("LIVE_SALES_RECONCILE_MINUTES", 10)) * time.Minute,
+
+		// SMS channel
+		SMSProvider: getEnv("SMS_PROVIDER", ""),
+		SMSAPIKey:   getEnv("SMS_API_KEY", ""),
+		SMSSenderID: getEnv("SMS_SENDER_ID", ""),
+
+		// Per-user rate limits
+		OrderCreateRateLimit:     getEnvInt("USER_RATE_LIMIT_ORDER_CREATE", 10),
+		CouponGenerateRateLimit:  getEnvInt("USER_RATE_LIMIT_COUPON_GENERATE", 5),
 	}
 }
 
@@ -139,3 +332,23 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+// getEnvBool tries to get the boolean value of the key from the environment variables
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+// getEnvFloat tries to get the float value of the key from the environment variables
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,72 @@
+package match
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"errandShop/internal/core/types"
+)
+
+// MatchCoordinates places a map pin in the delivery zone whose area contains it, choosing the
+// nearest area centre when areas overlap. Without a match it suggests the closest areas.
+func (m *Matcher) MatchCoordinates(lat, lng float64) (*types.MatchResult, *types.NoMatchResult) {
+	type areaDistance struct {
+		zone types.DeliveryZone
+		area types.ZoneArea
+		km   float64
+	}
+
+	var areas []areaDistance
+	for _, zone := range m.zones {
+		for _, area := range zone.Areas {
+			areas = append(areas, areaDistance{zone: zone, area: area, km: haversineKm(lat, lng, area.Latitude, area.Longitude)})
+		}
+	}
+	sort.Slice(areas, func(i, j int) bool { return areas[i].km < areas[j].km })
+
+	for _, a := range areas {
+		if a.km <= a.area.RadiusKm {
+			return &types.MatchResult{
+				ZoneID:         a.zone.ZoneID,
+				ZoneName:       fmt.Sprintf("Zone %d", a.zone.ZoneID),
+				MatchedKeyword: a.area.Name,
+				MatchedBy:      "coordinates",
+				Confidence:     1.0,
+				Price:          a.zone.Price,
+				MinOrder:       a.zone.MinOrder,
+				PrepMinutes:    a.zone.PrepMinutes,
+				TransitMinutes: a.zone.TransitMinutes,
+			}, nil
+		}
+	}
+
+	var suggestions []types.MatchSuggestion
+	for i := 0; i < len(areas) && i < 3; i++ {
+		a := areas[i]
+		suggestions = append(suggestions, types.MatchSuggestion{
+			ZoneID:     a.zone.ZoneID,
+			Keyword:    a.area.Name,
+			Price:      a.zone.Price,
+			Confidence: math.Round(a.area.RadiusKm/a.km*100) / 100, // below 1: how close the pin is to the area's edge
+		})
+	}
+	return nil, &types.NoMatchResult{
+		MatchedBy:   "none",
+		Message:     "No delivery zone covers the selected location",
+		Suggestions: suggestions,
+	}
+}
+
+// haversineKm returns the great-circle distance between two points in kilometres
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371 // Earth's radius in kilometers
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return earthRadius * c
+}
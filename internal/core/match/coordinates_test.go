@@ -0,0 +1,41 @@
+package match
+
+import (
+	"testing"
+
+	"errandShop/internal/core/types"
+)
+
+func TestMatchCoordinates(t *testing.T) {
+	m := NewMatcher([]types.DeliveryZone{
+		{ZoneID: 2, Price: 2500, Locations: []string{"Wuse"}, Areas: []types.ZoneArea{
+			{Name: "Wuse", Latitude: 9.0700, Longitude: 7.4800, RadiusKm: 2},
+		}},
+		{ZoneID: 3, Price: 3000, Locations: []string{"Garki"}, Areas: []types.ZoneArea{
+			{Name: "Garki", Latitude: 9.0300, Longitude: 7.4900, RadiusKm: 3},
+		}},
+		{ZoneID: 7, Price: 5500, Locations: []string{"Dutse"}},
+	})
+
+	match, noMatch := m.MatchCoordinates(9.0710, 7.4810)
+	if match == nil || match.ZoneID != 2 || match.MatchedBy != "coordinates" || match.Price != 2500 {
+		t.Fatalf("pin in Wuse: got %+v, %+v", match, noMatch)
+	}
+
+	// About 2.4km from Garki and 2.8km from Wuse: only Garki's larger radius covers it
+	match, _ = m.MatchCoordinates(9.0450, 7.4750)
+	if match == nil || match.ZoneID != 3 {
+		t.Fatalf("pin between areas: got %+v", match)
+	}
+
+	match, noMatch = m.MatchCoordinates(9.2000, 7.3000)
+	if match != nil {
+		t.Fatalf("pin outside every area matched %+v", match)
+	}
+	if noMatch == nil || len(noMatch.Suggestions) != 2 || noMatch.Suggestions[0].Keyword != "Wuse" {
+		t.Fatalf("expected the two areas as suggestions, nearest first; got %+v", noMatch)
+	}
+	if c := noMatch.Suggestions[0].Confidence; c <= 0 || c >= 1 {
+		t.Fatalf("suggestion confidence = %v, want between 0 and 1", c)
+	}
+}
@@ -69,6 +69,9 @@ func (m *Matcher) exactMatch(normalizedAddress string) *types.MatchResult {
 						MatchedBy:      "exact",
 						Confidence:     1.0,
 						Price:          zone.Price,
+						MinOrder:       zone.MinOrder,
+						PrepMinutes:    zone.PrepMinutes,
+						TransitMinutes: zone.TransitMinutes,
 					}
 				}
 			}
@@ -104,6 +107,9 @@ func (m *Matcher) fuzzyMatch(normalizedAddress string) *types.MatchResult {
 						MatchedBy:      "fuzzy",
 						Confidence:     score,
 						Price:          zone.Price,
+						MinOrder:       zone.MinOrder,
+						PrepMinutes:    zone.PrepMinutes,
+						TransitMinutes: zone.TransitMinutes,
 					}
 				}
 			}
@@ -2,9 +2,23 @@ package types
 
 // DeliveryZone represents a delivery zone with pricing and locations
 type DeliveryZone struct {
-	ZoneID    int      `json:"zoneId"`
-	Price     int      `json:"price"`
-	Locations []string `json:"locations"`
+	ZoneID    int        `json:"zoneId"`
+	Price     int        `json:"price"`
+	MinOrder  int        `json:"minOrder,omitempty"` // Minimum items subtotal in naira; 0 uses the global default
+	Locations []string   `json:"locations"`
+	Areas     []ZoneArea `json:"areas,omitempty"` // map circles for pricing a dropped pin; zones without areas match by text only
+
+	// Typical minutes to prepare an order and to ride it to the zone; 0 falls back to the speed model
+	PrepMinutes    int `json:"prepMinutes,omitempty"`
+	TransitMinutes int `json:"transitMinutes,omitempty"`
+}
+
+// ZoneArea is a circle on the map that belongs to a delivery zone
+type ZoneArea struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+	RadiusKm  float64 `json:"radiusKm"`
 }
 
 // MatchResult represents the result of address matching
@@ -15,6 +29,13 @@ type MatchResult struct {
 	MatchedBy      string  `json:"matchedBy"` // "exact" or "fuzzy"
 	Confidence     float64 `json:"confidence"`
 	Price          int     `json:"price"`
+	MinOrder       int     `json:"minOrder,omitempty"`
+	PrepMinutes    int     `json:"prepMinutes,omitempty"`    // the zone's typical prep time, if configured
+	TransitMinutes int     `json:"transitMinutes,omitempty"` // the zone's typical ride time, if configured
+
+	// Set while a surge window raises the zone's price; Price already includes it
+	SurgeMultiplier float64 `json:"surgeMultiplier,omitempty"`
+	HighDemandNote  string  `json:"highDemandNote,omitempty"`
 }
 
 // NoMatchResult represents when no match is found
@@ -32,10 +53,14 @@ type MatchSuggestion struct {
 	Confidence float64 `json:"confidence"`
 }
 
-// DeliveryEstimateRequest represents the request for delivery estimation
+// DeliveryEstimateRequest represents the request for delivery estimation. Send a saved addressId,
+// or for users without one a free-text address and/or the coordinates of a map pin.
 type DeliveryEstimateRequest struct {
-	AddressID string `json:"addressId" validate:"required"`
-	UserID    string `json:"userId,omitempty"` // Optional, used when not authenticated
+	AddressID string   `json:"addressId,omitempty"`
+	UserID    string   `json:"userId,omitempty"` // Optional, used when not authenticated
+	Address   string   `json:"address,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
 }
 
 // OrderConfirmRequest represents the request for order confirmation
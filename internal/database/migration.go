@@ -1,18 +1,25 @@
 package database
 
 import (
+	"errandShop/internal/domain/analytics"
 	"errandShop/internal/domain/chat"
 	"errandShop/internal/domain/coupons"
+	"errandShop/internal/domain/custom_requests"
 	"errandShop/internal/domain/customers"
 	"errandShop/internal/domain/delivery"
 	"errandShop/internal/domain/notifications"
 	"errandShop/internal/domain/orders"
 	"errandShop/internal/domain/payments"
 	"errandShop/internal/domain/products"
+	"errandShop/internal/domain/reviews"
+	"errandShop/internal/domain/webhooks"
 	"errandShop/internal/pkg/models"
 	"errandShop/internal/services/audit"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
@@ -20,9 +27,43 @@ import (
 )
 
 type MigrationStatus struct {
-	Version   int       `json:"version"`
-	AppliedAt time.Time `json:"applied_at"`
-	Pending   bool      `json:"pending"`
+	ID        string     `json:"id"`
+	Version   int        `json:"version"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // Only recorded for migrations applied after 0034
+	Pending   bool       `json:"pending"`
+}
+
+// AllowDestructiveMigrationsEnabled reports whether ALLOW_DESTRUCTIVE_MIGRATIONS is explicitly switched on.
+// Without it, migrations never drop a table that still contains rows.
+func AllowDestructiveMigrationsEnabled() bool {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv("ALLOW_DESTRUCTIVE_MIGRATIONS")))
+	return v == "1" || v == "true" || v == "yes" || v == "on"
+}
+
+// dropTableIfSafe drops an existing table so it can be recreated, but only when it is empty or
+// destructive migrations are explicitly allowed. A populated table is kept as-is and the caller's
+// AutoMigrate applies additive changes instead.
+func dropTableIfSafe(tx *gorm.DB, model interface{}, name string) error {
+	if !tx.Migrator().HasTable(model) {
+		return nil
+	}
+
+	var rows int64
+	if err := tx.Model(model).Unscoped().Count(&rows).Error; err != nil {
+		return fmt.Errorf("count %s rows: %w", name, err)
+	}
+
+	if rows > 0 && !AllowDestructiveMigrationsEnabled() {
+		log.Printf("⚠️ %s table has existing rows — refusing to drop it (set ALLOW_DESTRUCTIVE_MIGRATIONS=true to force). Applying additive changes only.", name)
+		return nil
+	}
+
+	if rows > 0 {
+		log.Printf("⚠️ ALLOW_DESTRUCTIVE_MIGRATIONS is set: dropping %s table with existing rows and recreating...", name)
+	} else {
+		log.Printf("%s table exists but is empty, dropping and recreating...", name)
+	}
+	return tx.Migrator().DropTable(model)
 }
 
 // EnsureMinimalDashboardTables creates dashboard-dependent tables via AutoMigrate even when migration
@@ -151,13 +192,9 @@ func getMigrations() []*gormigrate.Migration {
 			Migrate: func(tx *gorm.DB) error {
 				log.Println("Adding products, categories, and stock_history tables...")
 
-				// Check if products table exists and has data
-				if tx.Migrator().HasTable(&products.Product{}) {
-					log.Println("Products table exists, dropping and recreating...")
-					// Drop the existing products table to avoid constraint issues
-					if err := tx.Migrator().DropTable(&products.Product{}); err != nil {
-						return err
-					}
+				// Drop the existing products table to avoid constraint issues, unless it holds data
+				if err := dropTableIfSafe(tx, &products.Product{}, "products"); err != nil {
+					return err
 				}
 
 				// Create all tables with proper constraints
@@ -183,20 +220,12 @@ func getMigrations() []*gormigrate.Migration {
 			Migrate: func(tx *gorm.DB) error {
 				log.Println("Adding orders and order_items tables...")
 
-				// Check if order_items table exists and drop it to avoid type conflicts
-				if tx.Migrator().HasTable(&orders.OrderItem{}) {
-					log.Println("Order items table exists, dropping and recreating...")
-					if err := tx.Migrator().DropTable(&orders.OrderItem{}); err != nil {
-						return err
-					}
+				// Drop order_items then orders to avoid type conflicts, unless they hold data
+				if err := dropTableIfSafe(tx, &orders.OrderItem{}, "order_items"); err != nil {
+					return err
 				}
-
-				// Check if orders table exists and drop it to avoid conflicts
-				if tx.Migrator().HasTable(&orders.Order{}) {
-					log.Println("Orders table exists, dropping and recreating...")
-					if err := tx.Migrator().DropTable(&orders.Order{}); err != nil {
-						return err
-					}
+				if err := dropTableIfSafe(tx, &orders.Order{}, "orders"); err != nil {
+					return err
 				}
 
 				return tx.AutoMigrate(
@@ -839,24 +868,1032 @@ WHERE lower(email) = lower(?)`,
 				return nil
 			},
 		},
-	}
-}
-
-func RunMigrations(db *gorm.DB) error {
-	m := gormigrate.New(
-		db,
-		gormigrate.DefaultOptions,
-		getMigrations(),
-	)
-
-	// Do NOT use InitSchema: on a fresh DB it only runs the bootstrap callback, then inserts
-	// every migration ID as already applied without executing Migrate() for 0002+ — leaving
-	// tables like orders missing. See gormigrate.runInitSchema().
-	if err := m.Migrate(); err != nil {
-		return err
-	}
-	// Idempotent: guarantees custom_requests / chat / etc. exist even if an older binary skipped ConnectDB ensure.
-	return EnsureMinimalDashboardTables(db)
+		{
+			ID: "0033_cart_reminder_tracking",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0033: adding abandoned cart reminder tracking to carts...")
+				if err := tx.Exec("ALTER TABLE carts ADD COLUMN IF NOT EXISTS last_reminder_at TIMESTAMPTZ").Error; err != nil {
+					return err
+				}
+				return tx.Exec("ALTER TABLE carts ADD COLUMN IF NOT EXISTS reminder_count INTEGER NOT NULL DEFAULT 0").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0033 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0034_migrations_applied_at",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0034: recording applied_at on the migrations table (earlier migrations stay NULL)...")
+				// gormigrate never recorded when a migration ran, so there is nothing to backfill from.
+				// Add the column without a default first so existing rows stay NULL instead of all
+				// getting this deploy's timestamp, then default it for migrations applied from now on.
+				table := gormigrate.DefaultOptions.TableName
+				if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS applied_at TIMESTAMPTZ", table)).Error; err != nil {
+					return err
+				}
+				return tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN applied_at SET DEFAULT NOW()", table)).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0034 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0035_order_items_unit_cost",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0035: adding unit_cost snapshot to order_items (existing rows default to 0)...")
+				return tx.Exec("ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_cost BIGINT NOT NULL DEFAULT 0").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0035 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0036_coupons_starts_at",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0036: adding starts_at to coupons (existing coupons stay immediately usable)...")
+				return tx.Exec("ALTER TABLE coupons ADD COLUMN IF NOT EXISTS starts_at TIMESTAMPTZ").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0036 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0037_order_tips",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0037: adding tip_kobo to orders and deliveries...")
+				if err := tx.Exec("ALTER TABLE orders ADD COLUMN IF NOT EXISTS tip_kobo BIGINT NOT NULL DEFAULT 0").Error; err != nil {
+					return err
+				}
+				return tx.Exec("ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS tip_kobo BIGINT NOT NULL DEFAULT 0").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0037 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0038_delivery_proof",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0038: adding proof-of-delivery columns to deliveries...")
+				return tx.Exec(`ALTER TABLE deliveries
+					ADD COLUMN IF NOT EXISTS proof_photo_url VARCHAR(500),
+					ADD COLUMN IF NOT EXISTS proof_signature_url VARCHAR(500),
+					ADD COLUMN IF NOT EXISTS proof_recipient_name VARCHAR(100),
+					ADD COLUMN IF NOT EXISTS proof_captured_at TIMESTAMPTZ`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0038 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0039_notification_send_status",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0039: adding push send tracking to notifications (existing rows marked sent)...")
+				// Backfill existing rows as sent so the retry worker never replays old notifications
+				if err := tx.Exec(`ALTER TABLE notifications
+					ADD COLUMN IF NOT EXISTS send_status VARCHAR(20) NOT NULL DEFAULT 'sent',
+					ADD COLUMN IF NOT EXISTS send_attempts INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS last_send_error TEXT,
+					ADD COLUMN IF NOT EXISTS next_send_at TIMESTAMPTZ`).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("ALTER TABLE notifications ALTER COLUMN send_status SET DEFAULT 'pending'").Error; err != nil {
+					return err
+				}
+				return tx.Exec("CREATE INDEX IF NOT EXISTS idx_notifications_send_status ON notifications (send_status)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0039 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0040_driver_first_name",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0040: adding first_name to delivery_drivers for public tracking...")
+				return tx.Exec("ALTER TABLE delivery_drivers ADD COLUMN IF NOT EXISTS first_name VARCHAR(100)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0040 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0041_product_price_history",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0041: creating product_price_history...")
+				return tx.AutoMigrate(&products.ProductPriceHistory{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&products.ProductPriceHistory{})
+			},
+		},
+		{
+			ID: "0042_quote_reminder_sent_at",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0042: adding reminder_sent_at to quotes...")
+				return tx.Exec("ALTER TABLE quotes ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMPTZ").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0042 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0043_products_weight_grams",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0043: adding weight_grams to products (existing products weigh 0 until set)...")
+				return tx.Exec("ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0043 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0044_payments_fees_settlement",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0044: adding fees_kobo and settlement_kobo to payments...")
+				if err := tx.Exec("ALTER TABLE payments ADD COLUMN IF NOT EXISTS fees_kobo BIGINT NOT NULL DEFAULT 0").Error; err != nil {
+					return err
+				}
+				return tx.Exec("ALTER TABLE payments ADD COLUMN IF NOT EXISTS settlement_kobo BIGINT NOT NULL DEFAULT 0").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0044 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0045_orders_payment_method_received_at",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0045: adding payment_method and received_at to orders...")
+				if err := tx.Exec("ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_method VARCHAR(50)").Error; err != nil {
+					return err
+				}
+				return tx.Exec("ALTER TABLE orders ADD COLUMN IF NOT EXISTS received_at TIMESTAMPTZ").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0045 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0046_reviews",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0046: creating reviews table...")
+				if err := tx.AutoMigrate(&reviews.Review{}); err != nil {
+					return err
+				}
+				// One review per product per order, and one delivery review per order
+				if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_order_product ON reviews (order_id, product_id) WHERE type = 'product'").Error; err != nil {
+					return err
+				}
+				return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_order_delivery ON reviews (order_id) WHERE type = 'delivery'").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&reviews.Review{})
+			},
+		},
+		{
+			ID: "0047_orders_idempotency_scope",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0047: scoping order idempotency keys per customer and adding payload fingerprint...")
+				if err := tx.Exec("ALTER TABLE orders ADD COLUMN IF NOT EXISTS idempotency_fingerprint VARCHAR(64)").Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_orders_idempotency_key").Error; err != nil {
+					return err
+				}
+				return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_customer_idempotency_key ON orders (customer_id, idempotency_key)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0047 (keys may no longer be globally unique).")
+				return nil
+			},
+		},
+		{
+			ID: "0048_categories_parent_id",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0048: adding parent_id to categories (existing categories stay top-level)...")
+				if err := tx.Exec("ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id UUID").Error; err != nil {
+					return err
+				}
+				return tx.Exec("CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id)").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0048 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0049_orders_recipient_details",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0049: adding recipient and delivery instruction columns to orders...")
+				for _, stmt := range []string{
+					"ALTER TABLE orders ADD COLUMN IF NOT EXISTS recipient_name VARCHAR(100)",
+					"ALTER TABLE orders ADD COLUMN IF NOT EXISTS recipient_phone VARCHAR(20)",
+					"ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_instructions TEXT",
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0049 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0050_orders_delivery_sla",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0050: adding delivery SLA variance and zone to orders...")
+				for _, stmt := range []string{
+					"ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_variance_minutes INTEGER",
+					"ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_zone VARCHAR(100)",
+					"CREATE INDEX IF NOT EXISTS idx_orders_delivery_zone ON orders (delivery_zone)",
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0050 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0051_coupon_amounts_in_kobo",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0051: converting coupon money columns to kobo...")
+				// Fixed values, minimums and refund credits were entered in naira. Recorded discounts
+				// were computed against kobo order amounts, so they are already kobo and only change type.
+				for _, stmt := range []string{
+					"ALTER TABLE coupons ALTER COLUMN value TYPE DECIMAL(14,2)",
+					"UPDATE coupons SET value = value * 100 WHERE type = 'fixed'",
+					"ALTER TABLE coupons ALTER COLUMN minimum_order_amount TYPE BIGINT USING ROUND(minimum_order_amount * 100)",
+					"ALTER TABLE coupon_usages ALTER COLUMN discount_amount TYPE BIGINT USING ROUND(discount_amount)",
+					"ALTER TABLE user_refund_credits ALTER COLUMN refund_amount TYPE BIGINT USING ROUND(refund_amount * 100)",
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("0051 rollback: converting coupon money columns back to naira...")
+				for _, stmt := range []string{
+					"ALTER TABLE user_refund_credits ALTER COLUMN refund_amount TYPE DECIMAL(10,2) USING refund_amount / 100.0",
+					"ALTER TABLE coupon_usages ALTER COLUMN discount_amount TYPE DECIMAL(10,2)",
+					"ALTER TABLE coupons ALTER COLUMN minimum_order_amount TYPE DECIMAL(10,2) USING minimum_order_amount / 100.0",
+					"UPDATE coupons SET value = value / 100 WHERE type = 'fixed'",
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			ID: "0052_stock_history_reason_code",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0052: adding typed reason codes to stock history...")
+				// Existing rows only have the free-text reason; map the phrases the order flow wrote
+				for _, stmt := range []string{
+					"ALTER TABLE stock_history ADD COLUMN IF NOT EXISTS reason_code VARCHAR(20) NOT NULL DEFAULT 'correction'",
+					"UPDATE stock_history SET reason_code = 'sale' WHERE reason = 'Order creation'",
+					"UPDATE stock_history SET reason_code = 'cancellation' WHERE reason LIKE 'Order cancellation%'",
+					"CREATE INDEX IF NOT EXISTS idx_stock_history_reason_code ON stock_history (reason_code, created_at)",
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0052 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0053_refresh_token_sessions",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0053: adding session metadata to refresh tokens...")
+				for _, stmt := range []string{
+					"ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS device_name VARCHAR(100)",
+					"ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS user_agent VARCHAR(500)",
+					"ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45)",
+					"CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id)",
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0053 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0054_orders_scheduled_for",
+			Migrate: func(tx *gorm.DB) error {
+				for _, stmt := range []string{
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ`,
+					`CREATE INDEX IF NOT EXISTS idx_orders_scheduled_for ON orders(scheduled_for) WHERE status = 'scheduled'`,
+					`ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_status`,
+					`ALTER TABLE orders ADD CONSTRAINT chk_orders_status CHECK (status IN ('scheduled', 'pending', 'confirmed', 'preparing', 'out_for_delivery', 'delivered', 'cancelled'))`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0054 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0055_user_referrals",
+			Migrate: func(tx *gorm.DB) error {
+				for _, stmt := range []string{
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_code VARCHAR(16)`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS referred_by_id UUID`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_credited_at TIMESTAMPTZ`,
+					// Existing users get a code derived from their id so the unique index can be built
+					`UPDATE users SET referral_code = UPPER(SUBSTRING(MD5(id::text) FROM 1 FOR 8)) WHERE referral_code IS NULL OR referral_code = ''`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_referral_code ON users(referral_code) WHERE referral_code IS NOT NULL AND referral_code <> ''`,
+					`CREATE INDEX IF NOT EXISTS idx_users_referred_by_id ON users(referred_by_id)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0055 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0056_delivery_failed_attempts",
+			Migrate: func(tx *gorm.DB) error {
+				for _, stmt := range []string{
+					`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS last_failure_reason TEXT`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0056 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0057_product_sale_price",
+			Migrate: func(tx *gorm.DB) error {
+				for _, stmt := range []string{
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_price DECIMAL(10,2)`,
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_starts_at TIMESTAMPTZ`,
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_ends_at TIMESTAMPTZ`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0057 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0058_orders_version",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0058 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0059_webhooks",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0059: creating webhooks and webhook_deliveries tables...")
+				return tx.AutoMigrate(&webhooks.Webhook{}, &webhooks.WebhookDelivery{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&webhooks.WebhookDelivery{}, &webhooks.Webhook{})
+			},
+		},
+		{
+			ID: "0060_normalize_product_tags",
+			Migrate: func(tx *gorm.DB) error {
+				// Same rule as products.NormalizeTag: lowercase, trim, whitespace runs become hyphens, no duplicates
+				return tx.Exec(`
+					UPDATE products SET tags = COALESCE((
+						SELECT jsonb_agg(tag ORDER BY first_pos)
+						FROM (
+							SELECT regexp_replace(lower(btrim(t.value)), '\s+', '-', 'g') AS tag, MIN(t.ordinality) AS first_pos
+							FROM jsonb_array_elements_text(products.tags) WITH ORDINALITY AS t(value, ordinality)
+							WHERE btrim(t.value) <> ''
+							GROUP BY 1
+						) normalized
+					), '[]'::jsonb)
+					WHERE jsonb_typeof(tags) = 'array'`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0060 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0061_delivery_batches",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0061: creating delivery_batches and batch columns on deliveries...")
+				if err := tx.AutoMigrate(&delivery.DeliveryBatch{}); err != nil {
+					return err
+				}
+				for _, stmt := range []string{
+					`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS batch_id BIGINT`,
+					`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS batch_sequence INTEGER`,
+					`CREATE INDEX IF NOT EXISTS idx_deliveries_batch_id ON deliveries (batch_id)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0061 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0062_orders_tax",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_kobo BIGINT DEFAULT 0`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0062 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0063_delivery_driver_accounts",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0063: linking delivery drivers to user accounts...")
+				for _, stmt := range []string{
+					`ALTER TABLE delivery_drivers ADD COLUMN IF NOT EXISTS account_id UUID`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_drivers_account_id ON delivery_drivers (account_id)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0063 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0064_orders_coupon_auto_applied",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0064: recording auto-applied coupons on orders...")
+				return tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_auto_applied BOOLEAN NOT NULL DEFAULT FALSE`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0064 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0065_orders_stock_reservations",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0065: adding stock reservation expiry to orders...")
+				for _, stmt := range []string{
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS stock_reserved_until TIMESTAMPTZ`,
+					`CREATE INDEX IF NOT EXISTS idx_orders_stock_reserved_until ON orders (stock_reserved_until)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0065 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0066_order_adjustments",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0066: adding manual order adjustments...")
+				if err := tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS adjustment_kobo BIGINT NOT NULL DEFAULT 0`).Error; err != nil {
+					return err
+				}
+				return tx.AutoMigrate(&orders.OrderAdjustment{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&orders.OrderAdjustment{})
+			},
+		},
+		{
+			ID: "0067_delivery_actual_distance",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0067: tracking distance traveled on deliveries...")
+				return tx.Exec(`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS actual_distance DOUBLE PRECISION NOT NULL DEFAULT 0`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0067 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0068_impersonation_sessions",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0068: creating impersonation_sessions...")
+				return tx.AutoMigrate(&models.ImpersonationSession{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&models.ImpersonationSession{})
+			},
+		},
+		{
+			ID: "0069_order_refund_methods",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0069: recording refund method on orders and adjustments...")
+				for _, stmt := range []string{
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS refund_method VARCHAR(20)`,
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS refunded_kobo BIGINT NOT NULL DEFAULT 0`,
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS refund_reference VARCHAR(100)`,
+					`ALTER TABLE order_adjustments ADD COLUMN IF NOT EXISTS refund_method VARCHAR(20)`,
+					`ALTER TABLE order_adjustments ADD COLUMN IF NOT EXISTS refund_coupon_id UUID`,
+					// Adjustments refunded before store credit existed all went back to the payment
+					`UPDATE order_adjustments SET refund_method = 'original_payment' WHERE refund_id IS NOT NULL AND refund_method IS NULL`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0069 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0070_product_order_quantity_limits",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0070: adding per-order quantity limits to products...")
+				for _, stmt := range []string{
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS min_order_qty INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS max_order_qty INTEGER NOT NULL DEFAULT 0`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0070 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0071_cash_collections",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0071: creating cash_collections for cash-on-delivery reconciliation...")
+				return tx.AutoMigrate(&delivery.CashCollection{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("cash_collections")
+			},
+		},
+		{
+			ID: "0072_custom_request_budget_cap",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec(`ALTER TABLE custom_requests ADD COLUMN IF NOT EXISTS budget_cap_kobo BIGINT`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0072 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0073_notification_preferences",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0073: creating notification_preferences for the daily digest...")
+				return tx.AutoMigrate(&notifications.NotificationPreference{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("notification_preferences")
+			},
+		},
+		{
+			ID: "0074_product_availability_schedule",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0074: adding availability schedules to products...")
+				for _, stmt := range []string{
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS available_days JSONB`,
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS available_from VARCHAR(5)`,
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS available_until VARCHAR(5)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0074 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0075_zone_restricted_coupons",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0075: adding delivery zone restrictions to coupons...")
+				for _, stmt := range []string{
+					`ALTER TABLE coupons ADD COLUMN IF NOT EXISTS applicable_zone_ids TEXT`,
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_discount BIGINT DEFAULT 0`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0075 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0076_product_featured_flag",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0076: adding featured flag to products...")
+				for _, stmt := range []string{
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS is_featured BOOLEAN DEFAULT false`,
+					`CREATE INDEX IF NOT EXISTS idx_products_is_featured ON products (is_featured)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0076 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0077_saved_items",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0077: creating saved items (wishlist)...")
+				return tx.AutoMigrate(&orders.SavedItem{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&orders.SavedItem{})
+			},
+		},
+		{
+			ID: "0078_notification_locales",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0078: keying notification templates by (key, locale) and adding a locale preference...")
+				for _, stmt := range []string{
+					`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'en'`,
+					`ALTER TABLE notification_templates ADD COLUMN IF NOT EXISTS template_key VARCHAR(60)`,
+					`ALTER TABLE notification_templates ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'en'`,
+					`UPDATE notification_templates SET template_key = type WHERE template_key IS NULL OR template_key = ''`,
+					`ALTER TABLE notification_templates ALTER COLUMN template_key SET NOT NULL`,
+					// Several templates can now share a type (one per key and locale)
+					`DROP INDEX IF EXISTS idx_notification_templates_type`,
+					`CREATE INDEX IF NOT EXISTS idx_notification_templates_type ON notification_templates (type)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_key_locale ON notification_templates (template_key, locale)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0078 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0079_stock_shortfall_substitutes",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0079: adding substitute products for stock shortfalls...")
+				for _, stmt := range []string{
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS substitute_product_id UUID`,
+					`ALTER TABLE order_items ADD COLUMN IF NOT EXISTS substitute_for UUID`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0079 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0080_live_sales",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0080: adding orders.paid_at and live_sales_snapshots...")
+				for _, stmt := range []string{
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS paid_at TIMESTAMPTZ`,
+					`CREATE INDEX IF NOT EXISTS idx_orders_paid_at ON orders (paid_at)`,
+					// Best guess for orders paid before the column existed
+					`UPDATE orders SET paid_at = updated_at WHERE paid_at IS NULL AND payment_status = 'paid'`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return tx.AutoMigrate(&analytics.LiveSalesSnapshot{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&analytics.LiveSalesSnapshot{})
+			},
+		},
+		{
+			ID: "0081_custom_request_assignees",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0081: creating custom_request_assignees...")
+				return tx.AutoMigrate(&custom_requests.Assignee{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&custom_requests.Assignee{})
+			},
+		},
+		{
+			ID: "0082_order_attribution",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0082: adding UTM attribution to orders...")
+				for _, stmt := range []string{
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS utm_source VARCHAR(100) NOT NULL DEFAULT 'direct'`,
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(100)`,
+					`ALTER TABLE orders ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(100)`,
+					`CREATE INDEX IF NOT EXISTS idx_orders_utm_source ON orders (utm_source)`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0082 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0083_product_barcodes",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0083: adding product barcodes...")
+				for _, stmt := range []string{
+					`ALTER TABLE products ADD COLUMN IF NOT EXISTS barcode VARCHAR(64)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products (barcode) WHERE barcode IS NOT NULL AND deleted_at IS NULL`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0083 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0084_delivery_surge_windows",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0084: creating delivery surge windows and orders.delivery_surge_multiplier...")
+				if err := tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_surge_multiplier NUMERIC(4,2) NOT NULL DEFAULT 1`).Error; err != nil {
+					return err
+				}
+				return tx.AutoMigrate(&delivery.SurgeWindow{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&delivery.SurgeWindow{})
+			},
+		},
+		{
+			ID: "0085_order_tax_mode",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0085: adding orders.tax_inclusive...")
+				return tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT false`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0085 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0086_order_quote_snapshots",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0086: adding orders.quote_snapshots...")
+				return tx.Exec(`ALTER TABLE orders ADD COLUMN IF NOT EXISTS quote_snapshots JSONB NOT NULL DEFAULT '[]'`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0086 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0087_product_images",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0087: creating product_images and adding existing product images to it...")
+				if err := tx.AutoMigrate(&products.ProductImage{}); err != nil {
+					return err
+				}
+				return tx.Exec(`
+INSERT INTO product_images (id, product_id, url, public_id, sort_order, is_primary, created_at, updated_at)
+SELECT gen_random_uuid(), p.id, p.image_url, p.image_public_id, 0, true, NOW(), NOW()
+FROM products p
+WHERE p.image_url <> ''
+  AND NOT EXISTS (SELECT 1 FROM product_images pi WHERE pi.product_id = p.id)`).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&products.ProductImage{})
+			},
+		},
+		{
+			ID: "0088_notification_quiet_hours",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0088: adding quiet hours to notification_preferences...")
+				for _, stmt := range []string{
+					`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5) NOT NULL DEFAULT ''`,
+					`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5) NOT NULL DEFAULT ''`,
+					`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT ''`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0088 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0089_orders_optional_idempotency_key",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0089: allowing orders without an idempotency key...")
+				for _, stmt := range []string{
+					`DROP INDEX IF EXISTS idx_orders_customer_idempotency_key`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_customer_idempotency_key ON orders (customer_id, idempotency_key) WHERE idempotency_key IS NOT NULL AND idempotency_key <> ''`,
+				} {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				log.Println("Rollback skipped for 0089 (no destructive changes).")
+				return nil
+			},
+		},
+		{
+			ID: "0090_delivery_tips",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0090: creating delivery_tips for tips added after delivery...")
+				return tx.AutoMigrate(&reviews.DeliveryTip{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&reviews.DeliveryTip{})
+			},
+		},
+		{
+			ID: "0091_winback_coupons",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0091: creating winback_coupons...")
+				return tx.AutoMigrate(&orders.WinBackCoupon{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&orders.WinBackCoupon{})
+			},
+		},
+		{
+			ID: "0092_funnel_events",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0092: creating funnel_events for the conversion funnel report...")
+				return tx.AutoMigrate(&analytics.FunnelEvent{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&analytics.FunnelEvent{})
+			},
+		},
+		{
+			ID: "0093_users_phone_verified_at",
+			Migrate: func(tx *gorm.DB) error {
+				log.Println("0093: adding phone_verified_at to users (existing phones start unverified)...")
+				return tx.Exec("ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at").Error
+			},
+		},
+	}
+}
+
+func RunMigrations(db *gorm.DB) error {
+	m := gormigrate.New(
+		db,
+		gormigrate.DefaultOptions,
+		getMigrations(),
+	)
+
+	// Do NOT use InitSchema: on a fresh DB it only runs the bootstrap callback, then inserts
+	// every migration ID as already applied without executing Migrate() for 0002+ — leaving
+	// tables like orders missing. See gormigrate.runInitSchema().
+	if err := m.Migrate(); err != nil {
+		return err
+	}
+	// Idempotent: guarantees custom_requests / chat / etc. exist even if an older binary skipped ConnectDB ensure.
+	return EnsureMinimalDashboardTables(db)
+}
+
+// GetMigrationStatuses lists every known migration in order with whether it has been applied.
+// Applied rows recorded before 0034 have no applied_at timestamp.
+func GetMigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	table := gormigrate.DefaultOptions.TableName
+
+	type appliedRow struct {
+		ID        string
+		AppliedAt *time.Time
+	}
+	var rows []appliedRow
+	if db.Migrator().HasColumn(table, "applied_at") {
+		if err := db.Table(table).Select("id, applied_at").Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+	} else if db.Migrator().HasTable(table) {
+		if err := db.Table(table).Select("id").Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+	}
+
+	applied := make(map[string]appliedRow, len(rows))
+	for _, row := range rows {
+		applied[row.ID] = row
+	}
+
+	migrations := getMigrations()
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{ID: m.ID, Pending: true}
+		// IDs are prefixed with a zero-padded sequence number, e.g. "0033_cart_reminder_tracking"
+		if prefix, _, ok := strings.Cut(m.ID, "_"); ok {
+			status.Version, _ = strconv.Atoi(prefix)
+		}
+		if row, ok := applied[m.ID]; ok {
+			status.Pending = false
+			status.AppliedAt = row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
 }
 
 // Rollback the last migration
@@ -18,6 +18,11 @@ func SetupAnalyticsRoutes(app *fiber.App, handler *AnalyticsHandler, cfg *config
 	dashboard.Use(middleware.JWTMiddleware(cfg))
 	dashboard.Use(middleware.RBACMiddleware("admin", "superadmin"))
 
+	// Admin analytics group
+	adminAnalytics := app.Group("/api/v1/admin/analytics")
+	adminAnalytics.Use(middleware.JWTMiddleware(cfg))
+	adminAnalytics.Use(middleware.RBACMiddleware("admin", "superadmin"))
+
 	// Dashboard endpoints - Individual metrics endpoints as per specification
 	dashboard.Get("/data", handler.GetDashboardData)                    // Combined dashboard data
 	dashboard.Get("/today-sales", handler.GetTodaySales)                 // Today's Sales
@@ -28,6 +33,12 @@ func SetupAnalyticsRoutes(app *fiber.App, handler *AnalyticsHandler, cfg *config
 	dashboard.Get("/low-stock-alerts", handler.GetLowStockAlerts)        // Low Stock Alerts
 	dashboard.Get("/sales-overview", handler.GetSalesOverviewEndpoint)   // Sales Overview
 
+	// Live sales counter, cheap enough to poll
+	adminAnalytics.Get("/live", handler.GetLiveSales)
+
+	// Conversion funnel: cart started -> order placed -> paid -> delivered
+	adminAnalytics.Get("/funnel", handler.GetFunnelReport)
+
 	// Legacy analytics endpoints
 	analytics.Get("/dashboard", handler.GetDashboard)
 
@@ -38,6 +49,10 @@ func SetupAnalyticsRoutes(app *fiber.App, handler *AnalyticsHandler, cfg *config
 	analytics.Get("/reports/orders", handler.GetOrdersReport)
 	analytics.Get("/reports/delivery", handler.GetDeliveryReport)
 	analytics.Get("/reports/payments", handler.GetPaymentsReport)
+	analytics.Get("/reports/coupons", handler.GetCouponROIReport)
+	analytics.Get("/reports/delivery-sla", handler.GetDeliverySLAReport)
+	analytics.Get("/reports/payment-methods", handler.GetPaymentMethodReport)
+	analytics.Get("/reports/sources", handler.GetSourceReport)
 
 	// Legacy individual report endpoints (keeping for backward compatibility)
 	analytics.Get("/customer", handler.GetCustomerReport)
@@ -84,10 +84,11 @@ type DashboardWidgetRequest struct {
 
 // Dashboard Data Structures (matching frontend requirements)
 type DashboardKPIs struct {
-	TotalSales    float64 `json:"totalSales"`    // Total sales amount in currency
-	ActiveUsers   int64   `json:"activeUsers"`   // Count of active mobile app users
-	TotalProducts int64   `json:"totalProducts"` // Total number of products
-	CouponsIssued int64   `json:"couponsIssued"` // Number of coupons issued
+	TotalSales     float64 `json:"totalSales"`     // Total sales amount in currency
+	ActiveUsers    int64   `json:"activeUsers"`    // Customers who logged in or ordered in the range
+	MobileAppUsers int64   `json:"mobileAppUsers"` // Customers with an active FCM token seen in the range
+	TotalProducts  int64   `json:"totalProducts"`  // Total number of products
+	CouponsIssued  int64   `json:"couponsIssued"`  // Number of coupons issued
 }
 
 type RecentOrder struct {
@@ -146,6 +147,47 @@ type CouponPerformance struct {
 	Type    string  `json:"type"` // "percentage" | "fixed"
 }
 
+// CouponROI is the revenue impact of a single coupon over a period (amounts in naira)
+type CouponROI struct {
+	CouponID           string  `json:"couponId"`
+	Code               string  `json:"code"`
+	Type               string  `json:"type"`
+	OrderCount         int64   `json:"orderCount"`
+	TotalDiscount      float64 `json:"totalDiscount"`
+	GrossRevenue       float64 `json:"grossRevenue"`
+	RevenuePerDiscount float64 `json:"revenuePerDiscount"` // gross revenue per naira of discount; 0 when no discount was given
+}
+
+// DeliverySLAZone is on-time delivery performance for one delivery zone. An order is on
+// time when it was delivered at or before its EstimatedDelivery.
+type DeliverySLAZone struct {
+	Zone               string  `json:"zone"`
+	Delivered          int64   `json:"delivered"`
+	OnTime             int64   `json:"onTime"`
+	OnTimeRate         float64 `json:"onTimeRate"`         // percentage of delivered orders that were on time
+	AvgVarianceMinutes float64 `json:"avgVarianceMinutes"` // positive means late on average
+}
+
+type DeliverySLAReport struct {
+	Delivered          int64             `json:"delivered"`
+	OnTime             int64             `json:"onTime"`
+	OnTimeRate         float64           `json:"onTimeRate"`
+	AvgVarianceMinutes float64           `json:"avgVarianceMinutes"`
+	Zones              []DeliverySLAZone `json:"zones"` // slowest zones first
+}
+
+// PaymentMethodBreakdown is order volume and revenue for one checkout payment method (amounts in naira)
+type PaymentMethodBreakdown struct {
+	Method             string  `json:"method"`
+	OrderCount         int64   `json:"orderCount"`
+	PaidOrders         int64   `json:"paidOrders"` // orders counted toward revenue (confirmed or delivered)
+	Revenue            float64 `json:"revenue"`
+	SuccessRate        float64 `json:"successRate"` // percentage of orders that reached revenue
+	PaymentAttempts    int64   `json:"paymentAttempts"`
+	SuccessfulPayments int64   `json:"successfulPayments"`
+	PaymentSuccessRate float64 `json:"paymentSuccessRate"` // percentage of gateway payment attempts that completed; 0 without attempts
+}
+
 type StorePerformance struct {
 	Name              string  `json:"name"`
 	Revenue           float64 `json:"revenue"`
@@ -184,6 +226,67 @@ type PaymentReportResponse struct {
 	Data    PaymentAnalytics `json:"data"`
 }
 
+// SourceBreakdown is order volume and revenue for one marketing source, split by campaign (amounts in naira)
+type SourceBreakdown struct {
+	Source       string              `json:"source"` // "direct" for orders placed without attribution
+	OrderCount   int64               `json:"orderCount"`
+	PaidOrders   int64               `json:"paidOrders"` // orders counted toward revenue (confirmed or delivered)
+	Revenue      float64             `json:"revenue"`
+	RevenueShare float64             `json:"revenueShare"` // percentage of revenue across all sources
+	Campaigns    []CampaignBreakdown `json:"campaigns"`    // highest revenue first
+}
+
+// CampaignBreakdown is one campaign's share of a source (amounts in naira)
+type CampaignBreakdown struct {
+	Campaign   string  `json:"campaign"` // empty for orders without a campaign
+	OrderCount int64   `json:"orderCount"`
+	PaidOrders int64   `json:"paidOrders"`
+	Revenue    float64 `json:"revenue"`
+}
+
+
+type CouponROIReportResponse struct {
+	Success bool        `json:"success"`
+	Data    []CouponROI `json:"data"`
+}
+
+type PaymentMethodReportResponse struct {
+	Success bool                     `json:"success"`
+	Data    []PaymentMethodBreakdown `json:"data"`
+}
+
+type SourceReportResponse struct {
+	Success bool              `json:"success"`
+	Data    []SourceBreakdown `json:"data"`
+}
+
+// FunnelReport is how many carts and orders reached each funnel stage in a period, with the
+// conversion between stages as percentages
+type FunnelReport struct {
+	StartDate         time.Time          `json:"startDate"`
+	EndDate           time.Time          `json:"endDate"`
+	Stages            []FunnelStageCount `json:"stages"`            // cart started, order placed, paid, delivered
+	OverallConversion float64            `json:"overallConversion"` // delivered orders per cart started
+}
+
+// FunnelStageCount is one stage of a FunnelReport
+type FunnelStageCount struct {
+	Stage                  FunnelStage `json:"stage"`
+	Count                  int64       `json:"count"`
+	ConversionFromPrevious float64     `json:"conversionFromPrevious"` // percentage of the previous stage's count
+	ConversionFromStart    float64     `json:"conversionFromStart"`    // percentage of carts started
+}
+
+type FunnelReportResponse struct {
+	Success bool         `json:"success"`
+	Data    FunnelReport `json:"data"`
+}
+
+type DeliverySLAReportResponse struct {
+	Success bool              `json:"success"`
+	Data    DeliverySLAReport `json:"data"`
+}
+
 // Individual Dashboard Endpoint Response DTOs
 type TodaySalesResponse struct {
 	Success bool            `json:"success"`
@@ -199,6 +302,17 @@ type TodaySalesData struct {
 	LastUpdated  string  `json:"lastUpdated"`  // ISO timestamp
 }
 
+// LiveSalesData is the live sales counter: orders paid since store-timezone midnight
+type LiveSalesData struct {
+	Date         string     `json:"date"`   // store-timezone day being counted, YYYY-MM-DD
+	Amount       float64    `json:"amount"` // in naira
+	AmountKobo   int64      `json:"amountKobo"`
+	Currency     string     `json:"currency"` // "NGN"
+	OrdersCount  int64      `json:"ordersCount"`
+	ReconciledAt *time.Time `json:"reconciledAt"` // last correction from the orders table
+	AsOf         time.Time  `json:"asOf"`
+}
+
 type ActiveUsersResponse struct {
 	Success bool             `json:"success"`
 	Data    ActiveUsersData  `json:"data"`
@@ -270,6 +384,8 @@ type SalesOverviewResponse struct {
 type DashboardSalesOverviewPayload struct {
 	Period            string                           `json:"period"`
 	TotalSales        float64                          `json:"total_sales"`
+	PaymentFees       float64                          `json:"payment_fees"` // Paystack fees deducted from TotalSales
+	NetSales          float64                          `json:"net_sales"`    // TotalSales minus PaymentFees, i.e. what settles to the bank
 	TotalOrders       int64                            `json:"total_orders"`
 	AverageOrderValue float64                          `json:"average_order_value"`
 	SalesData         []DashboardSalesOverviewDailyRow `json:"sales_data"`
@@ -0,0 +1,136 @@
+package analytics
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FunnelStage is one step from shopping to delivery in the conversion funnel
+type FunnelStage string
+
+const (
+	FunnelCartStarted    FunnelStage = "cart_started"    // first item added to an empty cart
+	FunnelOrderPlaced    FunnelStage = "order_placed"    // order created, from the cart or directly
+	FunnelOrderPaid      FunnelStage = "order_paid"      // order payment first succeeded
+	FunnelOrderDelivered FunnelStage = "order_delivered" // order marked delivered
+)
+
+// funnelStages is the funnel in order; each stage converts from the one before it
+var funnelStages = []FunnelStage{FunnelCartStarted, FunnelOrderPlaced, FunnelOrderPaid, FunnelOrderDelivered}
+
+// FunnelEvent records that a customer's cart or order reached a funnel stage
+type FunnelEvent struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	Stage      FunnelStage `gorm:"type:varchar(20);not null;index:idx_funnel_events_stage_occurred_at,priority:1" json:"stage"`
+	UserID     *uuid.UUID  `gorm:"type:uuid" json:"userId,omitempty"`
+	OrderID    *uuid.UUID  `gorm:"type:uuid;index" json:"orderId,omitempty"`
+	OccurredAt time.Time   `gorm:"not null;index:idx_funnel_events_stage_occurred_at,priority:2" json:"occurredAt"`
+}
+
+func (FunnelEvent) TableName() string {
+	return "funnel_events"
+}
+
+// FunnelTracker writes funnel events as carts and orders move through checkout. It implements
+// orders.FunnelRecorder; a failed write is logged and never fails the cart or order change.
+type FunnelTracker struct {
+	db     *gorm.DB
+	logger *log.Logger
+}
+
+func NewFunnelTracker(db *gorm.DB) *FunnelTracker {
+	return &FunnelTracker{db: db, logger: log.New(log.Writer(), "[FUNNEL] ", log.LstdFlags)}
+}
+
+func (t *FunnelTracker) CartStarted(userID uuid.UUID) {
+	t.record(FunnelCartStarted, &userID, nil)
+}
+
+func (t *FunnelTracker) OrderPlaced(userID, orderID uuid.UUID) {
+	t.record(FunnelOrderPlaced, &userID, &orderID)
+}
+
+func (t *FunnelTracker) OrderPaid(orderID uuid.UUID) {
+	t.record(FunnelOrderPaid, nil, &orderID)
+}
+
+func (t *FunnelTracker) OrderDelivered(orderID uuid.UUID) {
+	t.record(FunnelOrderDelivered, nil, &orderID)
+}
+
+func (t *FunnelTracker) record(stage FunnelStage, userID, orderID *uuid.UUID) {
+	event := &FunnelEvent{Stage: stage, UserID: userID, OrderID: orderID, OccurredAt: time.Now()}
+	if err := t.db.Create(event).Error; err != nil {
+		t.logger.Printf("failed to record %s: %v", stage, err)
+	}
+}
+
+// GetFunnelCounts counts each stage's events in [startDate, endDate). Order stages count each
+// order once, so a status set twice is not counted twice.
+func (r *analyticsRepository) GetFunnelCounts(startDate, endDate time.Time) (map[FunnelStage]int64, error) {
+	var rows []struct {
+		Stage  FunnelStage
+		Events int64
+		Orders int64
+	}
+	err := r.db.Table("funnel_events").
+		Select("stage, COUNT(*) AS events, COUNT(DISTINCT order_id) AS orders").
+		Where("occurred_at >= ? AND occurred_at < ?", startDate, endDate).
+		Group("stage").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[FunnelStage]int64, len(rows))
+	for _, row := range rows {
+		if row.Stage == FunnelCartStarted {
+			counts[row.Stage] = row.Events
+		} else {
+			counts[row.Stage] = row.Orders
+		}
+	}
+	return counts, nil
+}
+
+func (s *analyticsService) GetFunnelReport(req *ReportRequest) (*FunnelReportResponse, error) {
+	startDate, endDate := s.getDateRange(req.TimeRange, req.StartDate, req.EndDate)
+
+	counts, err := s.repo.GetFunnelCounts(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FunnelReportResponse{
+		Success: true,
+		Data:    buildFunnelReport(startDate, endDate, counts),
+	}, nil
+}
+
+// buildFunnelReport lays the stage counts out in funnel order with the conversion into each stage
+// from the one before it and from the top of the funnel. Stages are counted over the same period,
+// not as a cohort, so a conversion can exceed 100% when earlier carts convert in the period.
+func buildFunnelReport(startDate, endDate time.Time, counts map[FunnelStage]int64) FunnelReport {
+	report := FunnelReport{StartDate: startDate, EndDate: endDate, Stages: make([]FunnelStageCount, len(funnelStages))}
+	for i, stage := range funnelStages {
+		report.Stages[i] = FunnelStageCount{Stage: stage, Count: counts[stage]}
+		if i > 0 {
+			report.Stages[i].ConversionFromPrevious = conversionPercent(counts[stage], counts[funnelStages[i-1]])
+			report.Stages[i].ConversionFromStart = conversionPercent(counts[stage], counts[funnelStages[0]])
+		}
+	}
+	report.OverallConversion = report.Stages[len(report.Stages)-1].ConversionFromStart
+	return report
+}
+
+// conversionPercent is part as a percentage of whole to one decimal place, 0 when whole is 0
+func conversionPercent(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*1000) / 10
+}
@@ -0,0 +1,82 @@
+package analytics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	analytics "errandShop/internal/domain/analytics"
+)
+
+func TestFunnelReportConvertsBetweenStages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE funnel_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		stage TEXT NOT NULL,
+		user_id TEXT,
+		order_id TEXT,
+		occurred_at DATETIME NOT NULL
+	)`).Error; err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	tracker := analytics.NewFunnelTracker(db)
+	for i := 0; i < 4; i++ {
+		tracker.CartStarted(uuid.New())
+	}
+	paid := []uuid.UUID{uuid.New(), uuid.New()}
+	customerID := uuid.New()
+	tracker.OrderPlaced(customerID, paid[0])
+	tracker.OrderPlaced(customerID, paid[1])
+	tracker.OrderPlaced(customerID, uuid.New())
+	tracker.OrderPaid(paid[0])
+	tracker.OrderPaid(paid[1])
+	tracker.OrderDelivered(paid[0])
+	tracker.OrderDelivered(paid[0]) // marked delivered twice; counted once
+
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	service := analytics.NewAnalyticsService(analytics.NewAnalyticsRepository(db))
+	report, err := service.GetFunnelReport(&analytics.ReportRequest{TimeRange: analytics.TimeRangeCustom, StartDate: &start, EndDate: &end})
+	if err != nil {
+		t.Fatalf("GetFunnelReport returned error: %v", err)
+	}
+
+	want := []struct {
+		stage    analytics.FunnelStage
+		count    int64
+		previous float64
+	}{
+		{analytics.FunnelCartStarted, 4, 0},
+		{analytics.FunnelOrderPlaced, 3, 75},
+		{analytics.FunnelOrderPaid, 2, 66.7},
+		{analytics.FunnelOrderDelivered, 1, 50},
+	}
+	if len(report.Data.Stages) != len(want) {
+		t.Fatalf("got %d stages, want %d", len(report.Data.Stages), len(want))
+	}
+	for i, w := range want {
+		got := report.Data.Stages[i]
+		if got.Stage != w.stage || got.Count != w.count || got.ConversionFromPrevious != w.previous {
+			t.Errorf("stage %d = %+v, want %s count %d conversion %v", i, got, w.stage, w.count, w.previous)
+		}
+	}
+	if report.Data.OverallConversion != 25 {
+		t.Errorf("overall conversion = %v, want 25", report.Data.OverallConversion)
+	}
+
+	// Events outside the period are not counted
+	earlier := start.Add(-48 * time.Hour)
+	report, err = service.GetFunnelReport(&analytics.ReportRequest{TimeRange: analytics.TimeRangeCustom, StartDate: &earlier, EndDate: &start})
+	if err != nil {
+		t.Fatalf("GetFunnelReport returned error: %v", err)
+	}
+	if report.Data.Stages[0].Count != 0 || report.Data.OverallConversion != 0 {
+		t.Errorf("an empty period should report zeros, got %+v", report.Data)
+	}
+}
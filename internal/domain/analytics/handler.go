@@ -72,6 +72,22 @@ func (h *AnalyticsHandler) GetActiveUsers(c *fiber.Ctx) error {
 	})
 }
 
+// GET /api/v1/admin/analytics/live - Today's paid sales, updated as payments succeed
+func (h *AnalyticsHandler) GetLiveSales(c *fiber.Ctx) error {
+	sales, err := h.service.GetLiveSales()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get live sales",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sales,
+	})
+}
+
 // GET /api/v1/dashboard/total-products - Total Products endpoint
 func (h *AnalyticsHandler) GetTotalProducts(c *fiber.Ctx) error {
 	products, err := h.service.GetTotalProducts()
@@ -334,6 +350,117 @@ func (h *AnalyticsHandler) GetPaymentsReport(c *fiber.Ctx) error {
 	return c.JSON(report)
 }
 
+// GET /analytics/reports/coupons - Per-coupon discount vs. revenue (ROI)
+func (h *AnalyticsHandler) GetCouponROIReport(c *fiber.Ctx) error {
+	var req ReportRequest
+	req.ReportType = ReportCoupons
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request parameters",
+		})
+	}
+
+	report, err := h.service.GetCouponROIReport(&req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get coupon ROI report",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// GET /api/v1/analytics/reports/delivery-sla
+func (h *AnalyticsHandler) GetDeliverySLAReport(c *fiber.Ctx) error {
+	var req ReportRequest
+	req.ReportType = ReportDelivery
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request parameters",
+		})
+	}
+
+	report, err := h.service.GetDeliverySLAReport(&req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get delivery SLA report",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// GET /api/v1/analytics/reports/payment-methods - Orders, revenue and success rates per payment method
+func (h *AnalyticsHandler) GetPaymentMethodReport(c *fiber.Ctx) error {
+	var req ReportRequest
+	req.ReportType = ReportPayments
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request parameters",
+		})
+	}
+
+	report, err := h.service.GetPaymentMethodReport(&req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get payment method report",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// GET /api/v1/analytics/reports/sources - Orders and revenue per marketing source and campaign
+func (h *AnalyticsHandler) GetSourceReport(c *fiber.Ctx) error {
+	var req ReportRequest
+	req.ReportType = ReportOrders
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request parameters",
+		})
+	}
+
+	report, err := h.service.GetSourceReport(&req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get source report",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// GET /api/v1/admin/analytics/funnel - Carts started, orders placed, paid and delivered in a
+// period, with the conversion between each stage
+func (h *AnalyticsHandler) GetFunnelReport(c *fiber.Ctx) error {
+	var req ReportRequest
+	req.ReportType = ReportOrders
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request parameters",
+		})
+	}
+
+	report, err := h.service.GetFunnelReport(&req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get funnel report",
+		})
+	}
+
+	return c.JSON(report)
+}
+
 // Legacy handlers for backward compatibility
 
 // GET /api/v1/analytics/reports/customer (legacy)
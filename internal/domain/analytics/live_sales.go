@@ -0,0 +1,261 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// liveSalesPersistInterval is how often a changed live total is written to live_sales_snapshots
+const liveSalesPersistInterval = time.Minute
+
+// LiveSalesSnapshot is the last persisted live sales total for a store-timezone day, so a restart
+// carries on from it instead of from zero
+type LiveSalesSnapshot struct {
+	Day         string    `gorm:"type:varchar(10);primaryKey" json:"day"` // YYYY-MM-DD in the store timezone
+	TotalKobo   int64     `gorm:"not null;default:0" json:"totalKobo"`
+	OrdersCount int64     `gorm:"not null;default:0" json:"ordersCount"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// LiveSalesStore is the storage the live sales counter reads and writes
+type LiveSalesStore interface {
+	GetPaidSalesTotals(startDate, endDate time.Time) (totalKobo int64, ordersCount int64, err error)
+	GetLiveSalesSnapshot(day string) (*LiveSalesSnapshot, error)
+	SaveLiveSalesSnapshot(snapshot *LiveSalesSnapshot) error
+}
+
+// LiveSalesCounter keeps a running total of today's paid orders in memory, so the dashboard can
+// poll it without summing the orders table. Payment success adds to it, store-timezone midnight
+// resets it, and a periodic reconcile replaces it with the database sum to correct drift from
+// missed or duplicated events.
+type LiveSalesCounter struct {
+	store             LiveSalesStore
+	location          *time.Location
+	reconcileInterval time.Duration
+	now               func() time.Time
+	logger            *log.Logger
+
+	mu           sync.Mutex
+	day          time.Time // store-timezone midnight the totals are for
+	totalKobo    int64
+	ordersCount  int64
+	dirty        bool // changed since it was last persisted
+	reconciledAt *time.Time
+}
+
+func NewLiveSalesCounter(store LiveSalesStore, location *time.Location, reconcileInterval time.Duration) *LiveSalesCounter {
+	if location == nil {
+		location = time.Local
+	}
+	return &LiveSalesCounter{
+		store:             store,
+		location:          location,
+		reconcileInterval: reconcileInterval,
+		now:               time.Now,
+		logger:            log.New(log.Writer(), "[LIVE-SALES] ", log.LstdFlags),
+	}
+}
+
+// Start restores today's persisted total, reconciles it, then persists and reconciles on their
+// intervals until ctx is cancelled
+func (c *LiveSalesCounter) Start(ctx context.Context) {
+	if err := c.Restore(); err != nil {
+		c.logger.Printf("restore failed: %v", err)
+	}
+	if c.reconcileInterval > 0 {
+		if err := c.Reconcile(); err != nil {
+			c.logger.Printf("reconcile failed: %v", err)
+		}
+	}
+
+	go func() {
+		persist := time.NewTicker(liveSalesPersistInterval)
+		defer persist.Stop()
+		var reconcile <-chan time.Time
+		if c.reconcileInterval > 0 {
+			ticker := time.NewTicker(c.reconcileInterval)
+			defer ticker.Stop()
+			reconcile = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				if err := c.Persist(); err != nil {
+					c.logger.Printf("persist failed: %v", err)
+				}
+				return
+			case <-persist.C:
+				if err := c.Persist(); err != nil {
+					c.logger.Printf("persist failed: %v", err)
+				}
+			case <-reconcile:
+				if err := c.Reconcile(); err != nil {
+					c.logger.Printf("reconcile failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// RecordSale adds a paid order to today's total. Sales paid before today's store-timezone
+// midnight belong to a closed day and are ignored.
+func (c *LiveSalesCounter) RecordSale(amountKobo int64, paidAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover()
+	if paidAt.Before(c.day) {
+		return
+	}
+	c.totalKobo += amountKobo
+	c.ordersCount++
+	c.dirty = true
+}
+
+// Snapshot returns today's running totals
+func (c *LiveSalesCounter) Snapshot() LiveSalesData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover()
+	return LiveSalesData{
+		Date:         c.day.Format(time.DateOnly),
+		Amount:       float64(c.totalKobo) / 100.0,
+		AmountKobo:   c.totalKobo,
+		Currency:     "NGN",
+		OrdersCount:  c.ordersCount,
+		ReconciledAt: c.reconciledAt,
+		AsOf:         c.now(),
+	}
+}
+
+// Restore loads today's persisted totals into an empty counter
+func (c *LiveSalesCounter) Restore() error {
+	c.mu.Lock()
+	c.rollover()
+	day := c.day
+	c.mu.Unlock()
+
+	snapshot, err := c.store.GetLiveSalesSnapshot(day.Format(time.DateOnly))
+	if err != nil || snapshot == nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.day.Equal(day) && !c.dirty {
+		c.totalKobo = snapshot.TotalKobo
+		c.ordersCount = snapshot.OrdersCount
+	}
+	return nil
+}
+
+// Reconcile replaces today's totals with the sum of orders paid since store-timezone midnight
+func (c *LiveSalesCounter) Reconcile() error {
+	c.mu.Lock()
+	c.rollover()
+	day := c.day
+	c.mu.Unlock()
+
+	totalKobo, ordersCount, err := c.store.GetPaidSalesTotals(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("failed to sum today's paid orders: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.day.Equal(day) {
+		return nil // midnight passed during the query; the new day starts from zero
+	}
+	if drift := totalKobo - c.totalKobo; drift != 0 {
+		c.logger.Printf("corrected drift of %d kobo (%d orders counted, %d paid)", drift, c.ordersCount, ordersCount)
+	}
+	if totalKobo != c.totalKobo || ordersCount != c.ordersCount {
+		c.totalKobo = totalKobo
+		c.ordersCount = ordersCount
+		c.dirty = true
+	}
+	now := c.now()
+	c.reconciledAt = &now
+	return nil
+}
+
+// Persist writes today's totals if they changed since the last write
+func (c *LiveSalesCounter) Persist() error {
+	c.mu.Lock()
+	c.rollover()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	snapshot := LiveSalesSnapshot{
+		Day:         c.day.Format(time.DateOnly),
+		TotalKobo:   c.totalKobo,
+		OrdersCount: c.ordersCount,
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	if err := c.store.SaveLiveSalesSnapshot(&snapshot); err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+		return fmt.Errorf("failed to save live sales snapshot: %w", err)
+	}
+	return nil
+}
+
+// rollover resets the totals once the store-timezone day changes. Callers hold c.mu.
+func (c *LiveSalesCounter) rollover() {
+	now := c.now().In(c.location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, c.location)
+	if !today.After(c.day) {
+		return
+	}
+	c.day = today
+	c.totalKobo = 0
+	c.ordersCount = 0
+	c.dirty = false
+	c.reconciledAt = nil
+}
+
+// GetPaidSalesTotals sums the orders first marked paid in [startDate, endDate)
+func (r *analyticsRepository) GetPaidSalesTotals(startDate, endDate time.Time) (int64, int64, error) {
+	var totals struct {
+		TotalKobo   int64
+		OrdersCount int64
+	}
+	err := r.db.Table("orders").
+		Select("COALESCE(SUM(total_amount), 0) AS total_kobo, COUNT(*) AS orders_count").
+		Where("paid_at >= ? AND paid_at < ?", startDate, endDate).
+		Scan(&totals).Error
+	return totals.TotalKobo, totals.OrdersCount, err
+}
+
+// GetLiveSalesSnapshot returns the persisted totals for day, or nil if none were saved
+func (r *analyticsRepository) GetLiveSalesSnapshot(day string) (*LiveSalesSnapshot, error) {
+	var snapshot LiveSalesSnapshot
+	if err := r.db.Where("day = ?", day).First(&snapshot).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// SaveLiveSalesSnapshot inserts or overwrites the snapshot for its day
+func (r *analyticsRepository) SaveLiveSalesSnapshot(snapshot *LiveSalesSnapshot) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_kobo", "orders_count", "updated_at"}),
+	}).Create(snapshot).Error
+}
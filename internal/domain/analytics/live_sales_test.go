@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeLiveSalesStore struct {
+	paidKobo  int64
+	paidCount int64
+	snapshots map[string]LiveSalesSnapshot
+}
+
+func (f *fakeLiveSalesStore) GetPaidSalesTotals(startDate, endDate time.Time) (int64, int64, error) {
+	return f.paidKobo, f.paidCount, nil
+}
+
+func (f *fakeLiveSalesStore) GetLiveSalesSnapshot(day string) (*LiveSalesSnapshot, error) {
+	snapshot, ok := f.snapshots[day]
+	if !ok {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
+
+func (f *fakeLiveSalesStore) SaveLiveSalesSnapshot(snapshot *LiveSalesSnapshot) error {
+	f.snapshots[snapshot.Day] = *snapshot
+	return nil
+}
+
+func newTestCounter(t *testing.T, store *fakeLiveSalesStore, now *time.Time) *LiveSalesCounter {
+	t.Helper()
+	lagos, err := time.LoadLocation("Africa/Lagos")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	counter := NewLiveSalesCounter(store, lagos, time.Minute)
+	counter.now = func() time.Time { return *now }
+	return counter
+}
+
+func TestLiveSalesCounterResetsAtStoreMidnight(t *testing.T) {
+	store := &fakeLiveSalesStore{snapshots: map[string]LiveSalesSnapshot{}}
+	// 22:30 UTC is 23:30 in Lagos
+	now := time.Date(2026, 3, 10, 22, 30, 0, 0, time.UTC)
+	counter := newTestCounter(t, store, &now)
+
+	counter.RecordSale(150000, now)
+	counter.RecordSale(50000, now)
+	if got := counter.Snapshot(); got.AmountKobo != 200000 || got.OrdersCount != 2 || got.Date != "2026-03-10" {
+		t.Fatalf("before midnight: got %+v", got)
+	}
+
+	// 23:15 UTC is already the next day in Lagos
+	now = time.Date(2026, 3, 10, 23, 15, 0, 0, time.UTC)
+	if got := counter.Snapshot(); got.AmountKobo != 0 || got.OrdersCount != 0 || got.Date != "2026-03-11" {
+		t.Fatalf("after midnight: got %+v", got)
+	}
+
+	// A late event for an order paid yesterday does not count today
+	counter.RecordSale(70000, time.Date(2026, 3, 10, 22, 50, 0, 0, time.UTC))
+	counter.RecordSale(30000, now)
+	if got := counter.Snapshot(); got.AmountKobo != 30000 || got.OrdersCount != 1 {
+		t.Fatalf("after late event: got %+v", got)
+	}
+}
+
+func TestLiveSalesCounterReconcileAndPersist(t *testing.T) {
+	store := &fakeLiveSalesStore{snapshots: map[string]LiveSalesSnapshot{
+		"2026-03-10": {Day: "2026-03-10", TotalKobo: 400000, OrdersCount: 3},
+	}}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	counter := newTestCounter(t, store, &now)
+
+	if err := counter.Restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	counter.RecordSale(100000, now)
+	if got := counter.Snapshot(); got.AmountKobo != 500000 || got.OrdersCount != 4 {
+		t.Fatalf("after restore: got %+v", got)
+	}
+
+	// The orders table disagrees with the running total; reconcile takes its sum
+	store.paidKobo, store.paidCount = 450000, 3
+	if err := counter.Reconcile(); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	got := counter.Snapshot()
+	if got.AmountKobo != 450000 || got.OrdersCount != 3 || got.ReconciledAt == nil {
+		t.Fatalf("after reconcile: got %+v", got)
+	}
+
+	if err := counter.Persist(); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if saved := store.snapshots["2026-03-10"]; saved.TotalKobo != 450000 || saved.OrdersCount != 3 {
+		t.Fatalf("persisted snapshot = %+v", saved)
+	}
+}
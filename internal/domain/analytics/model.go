@@ -17,6 +17,7 @@ const (
 	ReportOrders    ReportType = "orders"
 	ReportDelivery  ReportType = "delivery"
 	ReportPayments  ReportType = "payments"
+	ReportCoupons   ReportType = "coupons"
 
 	// Metric Types
 	MetricRevenue    MetricType = "revenue"
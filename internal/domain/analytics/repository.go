@@ -1,6 +1,7 @@
 package analytics
 
 import (
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,6 +26,7 @@ type AnalyticsRepository interface {
 	GetDashboardKPIs(startDate, endDate time.Time) (*DashboardKPIs, error)
 	GetRecentOrders(limit int) ([]RecentOrder, error)
 	GetLowStockProducts(threshold int) ([]LowStockProduct, error)
+	GetActiveUsersCount(startDate, endDate time.Time) (int64, error)
 	GetMobileAppUsersCount(startDate, endDate time.Time) (int64, error)
 
 	// Individual dashboard metrics methods
@@ -33,12 +35,21 @@ type AnalyticsRepository interface {
 	GetTotalProducts() (int64, error)
 	GetCouponsAnalytics() (int64, error)
 
+	// Live sales counter storage
+	LiveSalesStore
+
 	// Reports methods
 	GetSalesOverview(startDate, endDate time.Time, period string) (*SalesOverviewData, error)
 	GetRevenueAndOrderTotals(startDate, endDate time.Time) (revenue float64, orderCount int64, err error)
+	GetPaymentFees(startDate, endDate time.Time) (float64, error)
 
 	GetTopProductsReport(startDate, endDate time.Time, limit int) ([]TopProduct, error)
 	GetCouponPerformance(startDate, endDate time.Time) ([]CouponPerformance, error)
+	GetCouponROI(startDate, endDate time.Time) ([]CouponROI, error)
+	GetDeliverySLAByZone(startDate, endDate time.Time) ([]DeliverySLAZone, error)
+	GetRevenueByPaymentMethod(startDate, endDate time.Time) ([]PaymentMethodBreakdown, error)
+	GetRevenueBySource(startDate, endDate time.Time) ([]SourceBreakdown, error)
+	GetFunnelCounts(startDate, endDate time.Time) (map[FunnelStage]int64, error)
 	GetStorePerformance(startDate, endDate time.Time) ([]StorePerformance, error)
 
 	// Legacy methods (keeping for backward compatibility)
@@ -254,6 +265,22 @@ func (r *analyticsRepository) GetRevenueAndOrderTotals(startDate, endDate time.T
 	return out.TotalKobo / 100.0, out.N, nil
 }
 
+// GetPaymentFees sums provider processing fees (naira) on completed payments for the
+// revenue orders counted by GetRevenueAndOrderTotals, so callers can report net revenue.
+func (r *analyticsRepository) GetPaymentFees(startDate, endDate time.Time) (float64, error) {
+	var feesKobo float64
+	if err := r.db.Table("payments p").
+		Select("COALESCE(SUM(p.fees_kobo), 0)").
+		Joins("JOIN orders o ON o.id = p.order_id").
+		Where("p.status = ? AND p.deleted_at IS NULL", "completed").
+		Where("o.status IN ?", revenueOrderStatuses).
+		Where("o.created_at BETWEEN ? AND ?", startDate, endDate).
+		Scan(&feesKobo).Error; err != nil {
+		return 0, err
+	}
+	return feesKobo / 100.0, nil
+}
+
 func (r *analyticsRepository) GetTopProducts(startDate, endDate time.Time, limit int) ([]ProductSales, error) {
 	var products []ProductSales
 
@@ -339,12 +366,18 @@ func (r *analyticsRepository) GetDashboardKPIs(startDate, endDate time.Time) (*D
 	}
 	kpis.TotalSales = totalKobo / 100.0
 
-	activeUsers, err := r.GetMobileAppUsersCount(startDate, endDate)
+	activeUsers, err := r.GetActiveUsersCount(startDate, endDate)
 	if err != nil {
 		activeUsers = 0
 	}
 	kpis.ActiveUsers = activeUsers
 
+	mobileUsers, err := r.GetMobileAppUsersCount(startDate, endDate)
+	if err != nil {
+		mobileUsers = 0
+	}
+	kpis.MobileAppUsers = mobileUsers
+
 	if err := r.db.Table("products").
 		Where("is_active = ?", true).
 		Count(&kpis.TotalProducts).Error; err != nil {
@@ -360,15 +393,38 @@ func (r *analyticsRepository) GetDashboardKPIs(startDate, endDate time.Time) (*D
 	return &kpis, nil
 }
 
+// GetActiveUsersCount counts active users in [startDate, endDate]: customers with an
+// active account who either logged in or placed at least one order in the window.
+// Signing up alone does not make a user active.
+func (r *analyticsRepository) GetActiveUsersCount(startDate, endDate time.Time) (int64, error) {
+	var count int64
+
+	err := r.db.Table("users u").
+		Where("u.role = ? AND u.status = ?", "customer", "active").
+		Where(`u.last_login_at BETWEEN ? AND ? OR EXISTS (
+			SELECT 1 FROM orders o
+			WHERE o.customer_id = u.id AND o.created_at BETWEEN ? AND ?
+		)`, startDate, endDate, startDate, endDate).
+		Count(&count).Error
+
+	return count, err
+}
+
+// GetMobileAppUsersCount counts mobile app users in [startDate, endDate]: customers
+// with at least one active FCM token that was registered on or before endDate and
+// registered or refreshed (the app re-registers on launch) on or after startDate.
+// A user with several devices is counted once.
 func (r *analyticsRepository) GetMobileAppUsersCount(startDate, endDate time.Time) (int64, error) {
 	var count int64
 
-	db := r.db.Table("users").
-		Where("role = ? AND status = ? AND (last_login_at BETWEEN ? AND ? OR created_at BETWEEN ? AND ?)",
-			"customer", "active", startDate, endDate, startDate, endDate).
-		Count(&count)
+	err := r.db.Table("fcm_tokens t").
+		Joins("JOIN users u ON u.id = t.user_id").
+		Where("u.role = ? AND t.is_active = ?", "customer", true).
+		Where("t.created_at <= ? AND t.updated_at >= ?", endDate, startDate).
+		Distinct("t.user_id").
+		Count(&count).Error
 
-	return count, db.Error
+	return count, err
 }
 
 func (r *analyticsRepository) GetRecentOrders(limit int) ([]RecentOrder, error) {
@@ -522,6 +578,161 @@ func (r *analyticsRepository) GetCouponPerformance(startDate, endDate time.Time)
 	return coupons, nil
 }
 
+// GetCouponROI returns, per coupon redeemed in the period, the discount given and the revenue of the
+// orders it was used on. Only revenue-counted orders are included so cancellations don't inflate ROI.
+func (r *analyticsRepository) GetCouponROI(startDate, endDate time.Time) ([]CouponROI, error) {
+	var coupons []CouponROI
+
+	err := r.db.Table("coupon_usages cu").
+		Select(`c.id AS coupon_id, c.code, c.type,
+			COUNT(DISTINCT o.id) AS order_count,
+			COALESCE(SUM(o.coupon_discount), 0) AS total_discount,
+			COALESCE(SUM(o.total_amount), 0) AS gross_revenue`).
+		Joins("JOIN coupons c ON c.id = cu.coupon_id").
+		Joins("JOIN orders o ON o.id = cu.order_id").
+		Where("cu.used_at BETWEEN ? AND ?", startDate, endDate).
+		Where("o.status IN ?", revenueOrderStatuses).
+		Group("c.id, c.code, c.type").
+		Order("gross_revenue DESC").
+		Scan(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range coupons {
+		coupons[i].TotalDiscount /= 100.0
+		coupons[i].GrossRevenue /= 100.0
+		if coupons[i].TotalDiscount > 0 {
+			coupons[i].RevenuePerDiscount = coupons[i].GrossRevenue / coupons[i].TotalDiscount
+		}
+	}
+
+	return coupons, nil
+}
+
+// GetDeliverySLAByZone groups orders delivered in the period by the zone matched at checkout.
+// Orders without a recorded variance (no estimate, or delivered before SLA tracking) are skipped.
+func (r *analyticsRepository) GetDeliverySLAByZone(startDate, endDate time.Time) ([]DeliverySLAZone, error) {
+	var zones []DeliverySLAZone
+
+	err := r.db.Table("orders").
+		Select(`COALESCE(NULLIF(delivery_zone, ''), 'Unzoned') AS zone,
+			COUNT(*) AS delivered,
+			SUM(CASE WHEN delivery_variance_minutes <= 0 THEN 1 ELSE 0 END) AS on_time,
+			AVG(delivery_variance_minutes) AS avg_variance_minutes`).
+		Where("delivered_at BETWEEN ? AND ?", startDate, endDate).
+		Where("delivery_variance_minutes IS NOT NULL").
+		Group("zone").
+		Scan(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range zones {
+		if zones[i].Delivered > 0 {
+			zones[i].OnTimeRate = float64(zones[i].OnTime) / float64(zones[i].Delivered) * 100
+		}
+	}
+
+	return zones, nil
+}
+
+// GetRevenueByPaymentMethod groups orders placed in the period by the payment method chosen at
+// checkout, so cash on delivery is reported next to gateway payments. Gateway attempts come from
+// the payments table; orders that never created a payment (cash on delivery) have no attempts.
+func (r *analyticsRepository) GetRevenueByPaymentMethod(startDate, endDate time.Time) ([]PaymentMethodBreakdown, error) {
+	var methods []PaymentMethodBreakdown
+
+	payments := r.db.Table("payments").
+		Select("order_id, COUNT(*) AS attempts, SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END) AS completed").
+		Where("deleted_at IS NULL").
+		Group("order_id")
+
+	err := r.db.Table("orders o").
+		Select(`COALESCE(NULLIF(o.payment_method, ''), 'unknown') AS method,
+			COUNT(*) AS order_count,
+			SUM(CASE WHEN o.status IN ? THEN 1 ELSE 0 END) AS paid_orders,
+			COALESCE(SUM(CASE WHEN o.status IN ? THEN o.total_amount ELSE 0 END), 0) AS revenue,
+			COALESCE(SUM(p.attempts), 0) AS payment_attempts,
+			COALESCE(SUM(p.completed), 0) AS successful_payments`, revenueOrderStatuses, revenueOrderStatuses).
+		Joins("LEFT JOIN (?) p ON p.order_id = o.id", payments).
+		Where("o.created_at BETWEEN ? AND ?", startDate, endDate).
+		Group("method").
+		Order("revenue DESC").
+		Scan(&methods).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range methods {
+		methods[i].Revenue /= 100.0
+		if methods[i].OrderCount > 0 {
+			methods[i].SuccessRate = float64(methods[i].PaidOrders) / float64(methods[i].OrderCount) * 100
+		}
+		if methods[i].PaymentAttempts > 0 {
+			methods[i].PaymentSuccessRate = float64(methods[i].SuccessfulPayments) / float64(methods[i].PaymentAttempts) * 100
+		}
+	}
+
+	return methods, nil
+}
+
+// GetRevenueBySource groups orders placed in the period by the marketing source and campaign they
+// were attributed to at checkout, sources with the most revenue first
+func (r *analyticsRepository) GetRevenueBySource(startDate, endDate time.Time) ([]SourceBreakdown, error) {
+	var rows []struct {
+		Source     string
+		Campaign   string
+		OrderCount int64
+		PaidOrders int64
+		Revenue    float64
+	}
+	err := r.db.Table("orders o").
+		Select(`COALESCE(NULLIF(o.utm_source, ''), 'direct') AS source,
+			COALESCE(o.utm_campaign, '') AS campaign,
+			COUNT(*) AS order_count,
+			SUM(CASE WHEN o.status IN ? THEN 1 ELSE 0 END) AS paid_orders,
+			COALESCE(SUM(CASE WHEN o.status IN ? THEN o.total_amount ELSE 0 END), 0) AS revenue`, revenueOrderStatuses, revenueOrderStatuses).
+		Where("o.created_at BETWEEN ? AND ?", startDate, endDate).
+		Group("source, campaign").
+		Order("revenue DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []SourceBreakdown
+	index := make(map[string]int)
+	var totalRevenue float64
+	for _, row := range rows {
+		i, ok := index[row.Source]
+		if !ok {
+			i = len(sources)
+			index[row.Source] = i
+			sources = append(sources, SourceBreakdown{Source: row.Source})
+		}
+		revenue := row.Revenue / 100.0
+		sources[i].OrderCount += row.OrderCount
+		sources[i].PaidOrders += row.PaidOrders
+		sources[i].Revenue += revenue
+		sources[i].Campaigns = append(sources[i].Campaigns, CampaignBreakdown{
+			Campaign:   row.Campaign,
+			OrderCount: row.OrderCount,
+			PaidOrders: row.PaidOrders,
+			Revenue:    revenue,
+		})
+		totalRevenue += revenue
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Revenue > sources[j].Revenue })
+	for i := range sources {
+		if totalRevenue > 0 {
+			sources[i].RevenueShare = sources[i].Revenue / totalRevenue * 100
+		}
+	}
+	return sources, nil
+}
+
 func (r *analyticsRepository) GetStorePerformance(startDate, endDate time.Time) ([]StorePerformance, error) {
 	var stores []StorePerformance
 
@@ -556,17 +767,10 @@ func (r *analyticsRepository) GetTodaySales() (float64, error) {
 	return totalSales / 100.0, nil // Convert from kobo to naira
 }
 
+// GetActiveUsers counts active users (see GetActiveUsersCount) over the last 24 hours.
 func (r *analyticsRepository) GetActiveUsers() (int64, error) {
 	now := time.Now()
-	last24Hours := now.AddDate(0, 0, -1)
-
-	var count int64
-	r.db.Table("users").
-		Where("role = ? AND status = ? AND (last_login_at >= ? OR created_at >= ?)",
-			"customer", "active", last24Hours, last24Hours).
-		Count(&count)
-
-	return count, nil
+	return r.GetActiveUsersCount(now.AddDate(0, 0, -1), now)
 }
 
 func (r *analyticsRepository) GetTotalProducts() (int64, error) {
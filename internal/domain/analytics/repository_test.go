@@ -0,0 +1,285 @@
+package analytics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	analytics "errandShop/internal/domain/analytics"
+)
+
+func setupAnalyticsDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	// Only the columns the user metrics read; the real tables use Postgres-specific types
+	for _, stmt := range []string{
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			role TEXT,
+			status TEXT,
+			last_login_at DATETIME,
+			created_at DATETIME
+		)`,
+		`CREATE TABLE orders (
+			id TEXT PRIMARY KEY,
+			customer_id TEXT,
+			created_at DATETIME
+		)`,
+		`CREATE TABLE fcm_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT,
+			token TEXT,
+			is_active BOOLEAN,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	return db
+}
+
+func addUser(t *testing.T, db *gorm.DB, role, status string, lastLogin *time.Time, createdAt time.Time) string {
+	id := uuid.NewString()
+	if err := db.Exec("INSERT INTO users (id, role, status, last_login_at, created_at) VALUES (?, ?, ?, ?, ?)",
+		id, role, status, lastLogin, createdAt).Error; err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	return id
+}
+
+func addOrder(t *testing.T, db *gorm.DB, customerID string, createdAt time.Time) {
+	if err := db.Exec("INSERT INTO orders (id, customer_id, created_at) VALUES (?, ?, ?)",
+		uuid.NewString(), customerID, createdAt).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+}
+
+func addToken(t *testing.T, db *gorm.DB, userID string, active bool, createdAt, updatedAt time.Time) {
+	if err := db.Exec("INSERT INTO fcm_tokens (user_id, token, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		userID, uuid.NewString(), active, createdAt, updatedAt).Error; err != nil {
+		t.Fatalf("failed to insert fcm token: %v", err)
+	}
+}
+
+func TestGetActiveUsersCount(t *testing.T) {
+	db := setupAnalyticsDB(t)
+	repo := analytics.NewAnalyticsRepository(db)
+
+	end := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -30)
+	inWindow := end.AddDate(0, 0, -5)
+	beforeWindow := start.AddDate(0, 0, -10)
+
+	// Logged in during the window
+	addUser(t, db, "customer", "active", &inWindow, beforeWindow)
+
+	// Never logged in recently but ordered during the window
+	orderer := addUser(t, db, "customer", "active", &beforeWindow, beforeWindow)
+	addOrder(t, db, orderer, inWindow)
+
+	// Logged in and ordered twice: counted once
+	both := addUser(t, db, "customer", "active", &inWindow, beforeWindow)
+	addOrder(t, db, both, inWindow)
+	addOrder(t, db, both, inWindow.Add(time.Hour))
+
+	// Signed up in the window without logging in or ordering
+	addUser(t, db, "customer", "active", nil, inWindow)
+
+	// Only ordered before the window
+	stale := addUser(t, db, "customer", "active", &beforeWindow, beforeWindow)
+	addOrder(t, db, stale, beforeWindow)
+
+	// Active in the window but not an active customer account
+	addUser(t, db, "admin", "active", &inWindow, beforeWindow)
+	addUser(t, db, "customer", "suspended", &inWindow, beforeWindow)
+
+	got, err := repo.GetActiveUsersCount(start, end)
+	if err != nil {
+		t.Fatalf("GetActiveUsersCount returned error: %v", err)
+	}
+	if got != 3 {
+		t.Fatalf("expected 3 active users, got %d", got)
+	}
+}
+
+func TestGetMobileAppUsersCount(t *testing.T) {
+	db := setupAnalyticsDB(t)
+	repo := analytics.NewAnalyticsRepository(db)
+
+	end := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -30)
+	inWindow := end.AddDate(0, 0, -5)
+	beforeWindow := start.AddDate(0, 0, -10)
+	afterWindow := end.Add(time.Hour)
+
+	// Registered in the window
+	fresh := addUser(t, db, "customer", "active", nil, beforeWindow)
+	addToken(t, db, fresh, true, inWindow, inWindow)
+
+	// Registered earlier and refreshed in the window, on two devices: counted once
+	returning := addUser(t, db, "customer", "active", nil, beforeWindow)
+	addToken(t, db, returning, true, beforeWindow, inWindow)
+	addToken(t, db, returning, true, inWindow, inWindow)
+
+	// Token not seen since before the window
+	lapsed := addUser(t, db, "customer", "active", nil, beforeWindow)
+	addToken(t, db, lapsed, true, beforeWindow, beforeWindow)
+
+	// Token deactivated after a send failure
+	inactive := addUser(t, db, "customer", "active", nil, beforeWindow)
+	addToken(t, db, inactive, false, inWindow, inWindow)
+
+	// Registered after the window closed
+	late := addUser(t, db, "customer", "active", nil, beforeWindow)
+	addToken(t, db, late, true, afterWindow, afterWindow)
+
+	// Staff using the app are not customers
+	admin := addUser(t, db, "admin", "active", nil, beforeWindow)
+	addToken(t, db, admin, true, inWindow, inWindow)
+
+	got, err := repo.GetMobileAppUsersCount(start, end)
+	if err != nil {
+		t.Fatalf("GetMobileAppUsersCount returned error: %v", err)
+	}
+	if got != 2 {
+		t.Fatalf("expected 2 mobile app users, got %d", got)
+	}
+}
+
+func TestGetRevenueByPaymentMethod(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (
+			id TEXT PRIMARY KEY,
+			status TEXT,
+			payment_method TEXT,
+			total_amount INTEGER,
+			created_at DATETIME
+		)`,
+		`CREATE TABLE payments (
+			id TEXT PRIMARY KEY,
+			order_id TEXT,
+			status TEXT,
+			deleted_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+
+	end := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -30)
+	inWindow := end.AddDate(0, 0, -5)
+
+	addOrder := func(status, method string, totalKobo int64, createdAt time.Time, paymentStatuses ...string) {
+		id := uuid.NewString()
+		if err := db.Exec("INSERT INTO orders (id, status, payment_method, total_amount, created_at) VALUES (?, ?, ?, ?, ?)",
+			id, status, method, totalKobo, createdAt).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+		for _, ps := range paymentStatuses {
+			if err := db.Exec("INSERT INTO payments (id, order_id, status) VALUES (?, ?, ?)", uuid.NewString(), id, ps).Error; err != nil {
+				t.Fatalf("failed to insert payment: %v", err)
+			}
+		}
+	}
+
+	// Paystack: one order paid after a failed attempt, one abandoned
+	addOrder("confirmed", "paystack", 500000, inWindow, "failed", "completed")
+	addOrder("cancelled", "paystack", 300000, inWindow, "failed")
+	// Cash on delivery: one delivered, one still pending, no payment rows
+	addOrder("delivered", "cash_on_delivery", 200000, inWindow)
+	addOrder("pending", "cash_on_delivery", 100000, inWindow)
+	// Outside the window
+	addOrder("delivered", "paystack", 900000, start.AddDate(0, 0, -1), "completed")
+
+	repo := analytics.NewAnalyticsRepository(db)
+	got, err := repo.GetRevenueByPaymentMethod(start, end)
+	if err != nil {
+		t.Fatalf("GetRevenueByPaymentMethod returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 payment methods, got %d: %+v", len(got), got)
+	}
+
+	paystack, cod := got[0], got[1]
+	if paystack.Method != "paystack" || paystack.OrderCount != 2 || paystack.PaidOrders != 1 || paystack.Revenue != 5000 {
+		t.Fatalf("unexpected paystack breakdown: %+v", paystack)
+	}
+	if paystack.PaymentAttempts != 3 || paystack.SuccessfulPayments != 1 || paystack.SuccessRate != 50 {
+		t.Fatalf("unexpected paystack payment stats: %+v", paystack)
+	}
+	if cod.Method != "cash_on_delivery" || cod.OrderCount != 2 || cod.Revenue != 2000 || cod.PaymentAttempts != 0 || cod.PaymentSuccessRate != 0 {
+		t.Fatalf("unexpected cash on delivery breakdown: %+v", cod)
+	}
+}
+
+func TestGetRevenueBySource(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE orders (
+		id TEXT PRIMARY KEY,
+		status TEXT,
+		utm_source TEXT,
+		utm_campaign TEXT,
+		total_amount INTEGER,
+		created_at DATETIME
+	)`).Error; err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	end := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -30)
+	inWindow := end.AddDate(0, 0, -5)
+
+	addOrder := func(status, source, campaign string, totalKobo int64, createdAt time.Time) {
+		if err := db.Exec("INSERT INTO orders (id, status, utm_source, utm_campaign, total_amount, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			uuid.NewString(), status, source, campaign, totalKobo, createdAt).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+	}
+
+	// Instagram: two campaigns, one order cancelled
+	addOrder("delivered", "instagram", "easter_sale", 600000, inWindow)
+	addOrder("cancelled", "instagram", "easter_sale", 100000, inWindow)
+	addOrder("confirmed", "instagram", "", 200000, inWindow)
+	// Direct, including an order from before attribution was recorded
+	addOrder("delivered", "direct", "", 200000, inWindow)
+	addOrder("delivered", "", "", 0, inWindow)
+	// Outside the window
+	addOrder("delivered", "newsletter", "june", 900000, start.AddDate(0, 0, -1))
+
+	repo := analytics.NewAnalyticsRepository(db)
+	got, err := repo.GetRevenueBySource(start, end)
+	if err != nil {
+		t.Fatalf("GetRevenueBySource returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 sources, got %d: %+v", len(got), got)
+	}
+
+	instagram, direct := got[0], got[1]
+	if instagram.Source != "instagram" || instagram.OrderCount != 3 || instagram.PaidOrders != 2 || instagram.Revenue != 8000 || instagram.RevenueShare != 80 {
+		t.Fatalf("unexpected instagram breakdown: %+v", instagram)
+	}
+	if len(instagram.Campaigns) != 2 || instagram.Campaigns[0].Campaign != "easter_sale" || instagram.Campaigns[0].OrderCount != 2 || instagram.Campaigns[0].Revenue != 6000 {
+		t.Fatalf("unexpected instagram campaigns: %+v", instagram.Campaigns)
+	}
+	if direct.Source != "direct" || direct.OrderCount != 2 || direct.Revenue != 2000 || len(direct.Campaigns) != 1 {
+		t.Fatalf("unexpected direct breakdown: %+v", direct)
+	}
+}
@@ -2,6 +2,7 @@ package analytics
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -17,6 +18,8 @@ type AnalyticsService interface {
 	GetRecentOrdersData(limit int) (*RecentOrdersResponse, error)
 	GetLowStockAlerts(threshold int) (*LowStockAlertsResponse, error)
 	GetSalesOverviewData(period string) (*DashboardSalesOverviewPayload, error)
+	GetLiveSales() (*LiveSalesData, error)
+	SetLiveSalesCounter(counter *LiveSalesCounter)
 	
 	// New reports endpoints
 	GetSalesReport(req *ReportRequest) (*SalesReportResponse, error)
@@ -25,6 +28,11 @@ type AnalyticsService interface {
 	GetOrdersReport(req *ReportRequest) (*OrderReportResponse, error)
 	GetDeliveryReport(req *ReportRequest) (*DeliveryReportResponse, error)
 	GetPaymentsReport(req *ReportRequest) (*PaymentReportResponse, error)
+	GetCouponROIReport(req *ReportRequest) (*CouponROIReportResponse, error)
+	GetDeliverySLAReport(req *ReportRequest) (*DeliverySLAReportResponse, error)
+	GetPaymentMethodReport(req *ReportRequest) (*PaymentMethodReportResponse, error)
+	GetSourceReport(req *ReportRequest) (*SourceReportResponse, error)
+	GetFunnelReport(req *ReportRequest) (*FunnelReportResponse, error)
 	
 	// Legacy methods (keeping for backward compatibility)
 	GetDashboard(req *AnalyticsRequest) (*DashboardResponse, error)
@@ -35,13 +43,42 @@ type AnalyticsService interface {
 }
 
 type analyticsService struct {
-	repo AnalyticsRepository
+	repo      AnalyticsRepository
+	liveSales *LiveSalesCounter
 }
 
 func NewAnalyticsService(repo AnalyticsRepository) AnalyticsService {
 	return &analyticsService{repo: repo}
 }
 
+// SetLiveSalesCounter serves GetLiveSales from the in-memory counter; nil sums the orders table instead
+func (s *analyticsService) SetLiveSalesCounter(counter *LiveSalesCounter) {
+	s.liveSales = counter
+}
+
+// GetLiveSales returns today's paid sales so far
+func (s *analyticsService) GetLiveSales() (*LiveSalesData, error) {
+	if s.liveSales != nil {
+		data := s.liveSales.Snapshot()
+		return &data, nil
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	totalKobo, ordersCount, err := s.repo.GetPaidSalesTotals(startOfDay, startOfDay.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live sales: %w", err)
+	}
+	return &LiveSalesData{
+		Date:        startOfDay.Format(time.DateOnly),
+		Amount:      float64(totalKobo) / 100.0,
+		AmountKobo:  totalKobo,
+		Currency:    "NGN",
+		OrdersCount: ordersCount,
+		AsOf:        now,
+	}, nil
+}
+
 func (s *analyticsService) GetDashboard(req *AnalyticsRequest) (*DashboardResponse, error) {
 	// Legacy endpoint - returns basic dashboard data
 	startDate, endDate := s.getDateRange(req.TimeRange, req.StartDate, req.EndDate)
@@ -231,6 +268,86 @@ func (s *analyticsService) GetPaymentsReport(req *ReportRequest) (*PaymentReport
 	}, nil
 }
 
+func (s *analyticsService) GetCouponROIReport(req *ReportRequest) (*CouponROIReportResponse, error) {
+	startDate, endDate := s.getDateRange(req.TimeRange, req.StartDate, req.EndDate)
+
+	coupons, err := s.repo.GetCouponROI(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coupon ROI: %w", err)
+	}
+
+	return &CouponROIReportResponse{
+		Success: true,
+		Data:    coupons,
+	}, nil
+}
+
+func (s *analyticsService) GetDeliverySLAReport(req *ReportRequest) (*DeliverySLAReportResponse, error) {
+	startDate, endDate := s.getDateRange(req.TimeRange, req.StartDate, req.EndDate)
+
+	zones, err := s.repo.GetDeliverySLAByZone(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery SLA: %w", err)
+	}
+
+	report := DeliverySLAReport{Zones: zones}
+	var totalVariance float64
+	for _, z := range zones {
+		report.Delivered += z.Delivered
+		report.OnTime += z.OnTime
+		totalVariance += z.AvgVarianceMinutes * float64(z.Delivered)
+	}
+	if report.Delivered > 0 {
+		report.OnTimeRate = float64(report.OnTime) / float64(report.Delivered) * 100
+		report.AvgVarianceMinutes = totalVariance / float64(report.Delivered)
+	}
+	sort.SliceStable(report.Zones, func(i, j int) bool {
+		return report.Zones[i].OnTimeRate < report.Zones[j].OnTimeRate
+	})
+	if report.Zones == nil {
+		report.Zones = []DeliverySLAZone{}
+	}
+
+	return &DeliverySLAReportResponse{
+		Success: true,
+		Data:    report,
+	}, nil
+}
+
+func (s *analyticsService) GetPaymentMethodReport(req *ReportRequest) (*PaymentMethodReportResponse, error) {
+	startDate, endDate := s.getDateRange(req.TimeRange, req.StartDate, req.EndDate)
+
+	methods, err := s.repo.GetRevenueByPaymentMethod(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue by payment method: %w", err)
+	}
+	if methods == nil {
+		methods = []PaymentMethodBreakdown{}
+	}
+
+	return &PaymentMethodReportResponse{
+		Success: true,
+		Data:    methods,
+	}, nil
+}
+
+func (s *analyticsService) GetSourceReport(req *ReportRequest) (*SourceReportResponse, error) {
+	startDate, endDate := s.getDateRange(req.TimeRange, req.StartDate, req.EndDate)
+
+	sources, err := s.repo.GetRevenueBySource(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue by source: %w", err)
+	}
+	if sources == nil {
+		sources = []SourceBreakdown{}
+	}
+
+	return &SourceReportResponse{
+		Success: true,
+		Data:    sources,
+	}, nil
+}
+
 // Individual Dashboard Metrics Service Methods
 func (s *analyticsService) GetTodaySales() (*TodaySalesResponse, error) {
 	now := time.Now()
@@ -294,15 +411,15 @@ func (s *analyticsService) GetActiveUsers() (*ActiveUsersResponse, error) {
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	yesterday := startOfDay.AddDate(0, 0, -1)
 
-	// Get active users count (users who placed orders in the last 30 days)
+	// Active users: customers who logged in or placed an order in the last 30 days
 	last30Days := now.AddDate(0, 0, -30)
-	activeCount, err := s.repo.GetMobileAppUsersCount(last30Days, now)
+	activeCount, err := s.repo.GetActiveUsersCount(last30Days, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active users: %w", err)
 	}
 
 	// Get yesterday's active users for comparison
-	yesterdayCount, err := s.repo.GetMobileAppUsersCount(last30Days.AddDate(0, 0, -1), yesterday)
+	yesterdayCount, err := s.repo.GetActiveUsersCount(last30Days.AddDate(0, 0, -1), yesterday)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get yesterday's active users: %w", err)
 	}
@@ -468,6 +585,11 @@ func (s *analyticsService) GetSalesOverviewData(period string) (*DashboardSalesO
 		return nil, fmt.Errorf("failed to get sales totals: %w", err)
 	}
 
+	paymentFees, err := s.repo.GetPaymentFees(startDate, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment fees: %w", err)
+	}
+
 	points, err := s.repo.GetRevenueByDay(startDate, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get revenue by day: %w", err)
@@ -521,6 +643,8 @@ func (s *analyticsService) GetSalesOverviewData(period string) (*DashboardSalesO
 	return &DashboardSalesOverviewPayload{
 		Period:            period,
 		TotalSales:        totalSales,
+		PaymentFees:       paymentFees,
+		NetSales:          totalSales - paymentFees,
 		TotalOrders:       totalOrders,
 		AverageOrderValue: aov,
 		SalesData:         salesData,
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIncorrectPassword is returned when deleting an account with the wrong password
+	ErrIncorrectPassword = errors.New("password is incorrect")
+	// ErrAccountDeletionNotAllowed is returned when staff try to delete their own account; an
+	// administrator removes staff accounts instead
+	ErrAccountDeletionNotAllowed = errors.New("staff accounts are removed by an administrator")
+	// ErrAccountDeletionBlocked is wrapped by data sources that cannot erase a user's data yet,
+	// e.g. while an order is on its way
+	ErrAccountDeletionBlocked = errors.New("account cannot be deleted yet")
+)
+
+// AccountDataSource is a part of the app that holds personal data about a user, e.g. their orders.
+// It contributes a section to the user's data export and erases or anonymizes that data when the
+// user deletes their account, keeping whatever is needed for accounting without the personal parts.
+type AccountDataSource interface {
+	ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error)
+	EraseUserData(ctx context.Context, userID uuid.UUID) error
+}
+
+// NamedAccountDataSource is an AccountDataSource with the key of its section in the export
+type NamedAccountDataSource struct {
+	Name   string
+	Source AccountDataSource
+}
+
+// SetAccountDataSources sets where account exports and deletions find a user's data. Deletion
+// erases the sources in the order given and stops at the first that refuses (e.g. while orders
+// are in progress), so sources that can refuse should come first.
+func (s *Service) SetAccountDataSources(sources ...NamedAccountDataSource) {
+	s.accountDataSources = sources
+}
+
+// ExportAccountData bundles everything the app holds about the user: the account itself plus a
+// section from each data source
+func (s *Service) ExportAccountData(ctx context.Context, userID uuid.UUID, session SessionInfo) (map[string]interface{}, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := map[string]interface{}{
+		"exportedAt": time.Now().UTC(),
+		"account":    user,
+	}
+	for _, named := range s.accountDataSources {
+		data, err := named.Source.ExportUserData(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", named.Name, err)
+		}
+		export[named.Name] = data
+	}
+
+	s.AuditService.LogUserAction(ctx, userID, "account_data_exported", "user", map[string]interface{}{
+		"sections": len(s.accountDataSources),
+	}, session.IPAddress, session.UserAgent)
+
+	return export, nil
+}
+
+// DeleteAccount erases a customer's personal data after checking their password. Each data source
+// erases or anonymizes its part, then the account is anonymized, signed out everywhere and soft
+// deleted. Orders stay, stripped of personal details, so sales figures are unchanged.
+func (s *Service) DeleteAccount(ctx context.Context, userID uuid.UUID, password string, session SessionInfo) error {
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.CheckPassword(password) {
+		return ErrIncorrectPassword
+	}
+	if user.Role != "customer" {
+		return ErrAccountDeletionNotAllowed
+	}
+
+	for _, named := range s.accountDataSources {
+		if err := named.Source.EraseUserData(ctx, userID); err != nil {
+			return fmt.Errorf("failed to erase %s: %w", named.Name, err)
+		}
+	}
+	if err := s.Repo.AnonymizeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// The email is gone, so the audit trail keeps only the account ID
+	s.AuditService.LogUserAction(ctx, userID, "account_deleted", "user", map[string]interface{}{
+		"sections": len(s.accountDataSources),
+	}, session.IPAddress, session.UserAgent)
+
+	return nil
+}
+
+// AnonymizeUser overwrites a user's personal details with placeholders, signs them out everywhere
+// and soft deletes them. The email is replaced with a unique placeholder, freeing the original to register again.
+func (r *Repository) AnonymizeUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"first_name":        "Deleted",
+			"last_name":         "User",
+			"name":              "Deleted User",
+			"email":             fmt.Sprintf("deleted-%s@deleted.invalid", userID),
+			"phone":             "",
+			"phone_verified_at": nil,
+			"avatar":            nil,
+			"password":          "!", // not a bcrypt hash, so no password matches
+			"status":            "deleted",
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&OTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&User{}, "id = ?", userID).Error
+	})
+}
@@ -1,11 +1,22 @@
 package auth
 
+import (
+	"time"
+
+	"errandShop/internal/domain/customers"
+
+	"github.com/google/uuid"
+)
+
 
 
 // Mobile App DTOs
+// LoginRequest takes an email address or phone number in Identifier. Email is still accepted
+// from older clients and is used when Identifier is empty.
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Identifier string `json:"identifier" validate:"required_without=Email"`
+	Email      string `json:"email" validate:"omitempty,email"`
+	Password   string `json:"password" validate:"required,min=6"`
 }
 
 type RegisterRequest struct {
@@ -15,6 +26,7 @@ type RegisterRequest struct {
 	Email     string `json:"email" validate:"required,email"`
 	Password  string `json:"password" validate:"required,min=6"`
 	Phone     string `json:"phone" validate:"required,min=8"`
+	ReferralCode string `json:"referral_code,omitempty" validate:"omitempty,max=16"` // code of the friend who invited them
 }
 
 type AuthResponse struct {
@@ -36,9 +48,35 @@ type UserResponse struct {
 	Role        string   `json:"role"`
 	Permissions []string `json:"permissions"`
 	Status      string   `json:"status"`
+	ReferralCode string  `json:"referralCode"`
+	PhoneVerified bool   `json:"phoneVerified"` // whether Phone was confirmed by SMS code
 	CreatedAt   string   `json:"createdAt"`
 }
 
+// ImpersonateRequest starts an impersonation session; the reason is kept in the audit log
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,min=5,max=500"`
+}
+
+// ImpersonationResponse carries the impersonation token. There is no refresh token: when it
+// expires support must start a new session.
+type ImpersonationResponse struct {
+	Token          string       `json:"token"`
+	ExpiresIn      int          `json:"expiresIn"`
+	ExpiresAt      time.Time    `json:"expiresAt"`
+	SessionID      uuid.UUID    `json:"sessionId"`
+	ImpersonatedBy uuid.UUID    `json:"impersonatedBy"`
+	User           UserResponse `json:"user"`
+}
+
+// MeFullResponse bundles the user with their customer profile and default address.
+// Customer and DefaultAddress are null when the user has no profile or no addresses.
+type MeFullResponse struct {
+	User           *UserResponse               `json:"user"`
+	Customer       *customers.CustomerResponse `json:"customer"`
+	DefaultAddress *customers.AddressResponse  `json:"defaultAddress"`
+}
+
 
 
 // OTP DTOs
@@ -46,6 +84,11 @@ type VerifyEmailRequest struct {
 	Code string `json:"code" validate:"required,len=6"`
 }
 
+// VerifyPhoneRequest carries the code texted by POST /auth/phone/send-code
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,len=6"`
+}
+
 type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
@@ -67,6 +110,24 @@ type RefreshTokenResponse struct {
 	ExpiresIn    int    `json:"expiresIn"`
 }
 
+// SessionInfo describes the client a refresh token is issued to
+type SessionInfo struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
+// SessionResponse is one active refresh token, without the token itself
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceName string    `json:"deviceName"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
 
 
 // Admin DTOs - ADD THESE
@@ -75,7 +136,7 @@ type CreateUserRequest struct {
 	Email    string  `json:"email" validate:"required,email"`
 	Password string  `json:"password" validate:"required,min=6"`
 	Phone    *string `json:"phone,omitempty" validate:"omitempty,min=8"`
-	Role     string  `json:"role" validate:"required,oneof=customer admin superadmin"`
+	Role     string  `json:"role" validate:"required,oneof=customer driver admin superadmin"`
 	Status   string  `json:"status" validate:"required,oneof=active inactive suspended"`
 }
 
@@ -83,7 +144,7 @@ type UpdateUserRequest struct {
 	Name   *string `json:"name,omitempty" validate:"omitempty,min=2"`
 	Email  *string `json:"email,omitempty" validate:"omitempty,email"`
 	Phone  *string `json:"phone,omitempty" validate:"omitempty,min=8"`
-	Role   *string `json:"role,omitempty" validate:"omitempty,oneof=customer admin superadmin"`
+	Role   *string `json:"role,omitempty" validate:"omitempty,oneof=customer driver admin superadmin"`
 	Status *string `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended"`
 }
 
@@ -99,3 +160,8 @@ type ChangePasswordRequest struct {
 	CurrentPassword string `json:"currentPassword" validate:"required,min=6"`
 	NewPassword     string `json:"newPassword" validate:"required,min=6"`
 }
+
+// DeleteAccountRequest confirms deleting one's own account with the current password
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"errandShop/internal/presenter"
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -22,6 +24,23 @@ func NewHandler(service *Service) *Handler {
 	}
 }
 
+// sessionInfo describes the calling client for the refresh token being issued.
+// Apps can name the device with the X-Device-Name header.
+func sessionInfo(c *fiber.Ctx) SessionInfo {
+	return SessionInfo{
+		DeviceName: truncate(strings.TrimSpace(c.Get("X-Device-Name")), 100),
+		UserAgent:  truncate(c.Get(fiber.HeaderUserAgent), 500),
+		IPAddress:  c.IP(),
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
 // Register handles user registration
 func (h *Handler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
@@ -33,11 +52,14 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 		return presenter.Err(c, fiber.StatusBadRequest, "Validation failed: "+err.Error())
 	}
 
-	response, err := h.Service.Register(c.Context(), req)
+	response, err := h.Service.Register(c.Context(), req, sessionInfo(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
 			return presenter.Err(c, fiber.StatusConflict, err.Error())
 		}
+		if errors.Is(err, ErrInvalidReferralCode) {
+			return presenter.Err(c, fiber.StatusBadRequest, err.Error())
+		}
 		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to register user")
 	}
 
@@ -55,7 +77,7 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return presenter.Err(c, fiber.StatusBadRequest, "Validation failed: "+err.Error())
 	}
 
-	response, err := h.Service.Login(c.Context(), req)
+	response, err := h.Service.Login(c.Context(), req, sessionInfo(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid credentials") || strings.Contains(err.Error(), "not found") {
 			return presenter.Err(c, fiber.StatusUnauthorized, "Invalid credentials")
@@ -80,7 +102,7 @@ func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
 		return presenter.Err(c, fiber.StatusBadRequest, "Validation failed: "+err.Error())
 	}
 
-	response, err := h.Service.VerifyEmailWithCode(c.Context(), req.Code)
+	response, err := h.Service.VerifyEmailWithCode(c.Context(), req.Code, sessionInfo(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "expired") {
 			return presenter.Err(c, fiber.StatusBadRequest, err.Error())
@@ -117,7 +139,7 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 		return presenter.Err(c, fiber.StatusBadRequest, "Refresh token required")
 	}
 
-	response, err := h.Service.RefreshToken(c.Context(), refreshToken)
+	response, err := h.Service.RefreshToken(c.Context(), refreshToken, sessionInfo(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "expired") {
 			return presenter.Err(c, fiber.StatusUnauthorized, "Invalid or expired refresh token")
@@ -148,6 +170,43 @@ func (h *Handler) Logout(c *fiber.Ctx) error {
 	return presenter.OK(c, fiber.Map{"message": "Logged out successfully"}, nil)
 }
 
+// ListSessions lists the current user's active sessions
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	sessions, err := h.Service.ListSessions(c.Context(), userID)
+	if err != nil {
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to list sessions")
+	}
+
+	return presenter.OK(c, sessions, nil)
+}
+
+// RevokeSession signs the current user out of one session
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, "Invalid session ID")
+	}
+
+	if err := h.Service.RevokeSession(c.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return presenter.Err(c, fiber.StatusNotFound, "Session not found")
+		}
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to revoke session")
+	}
+
+	return presenter.OK(c, fiber.Map{"message": "Session revoked successfully"}, nil)
+}
+
 // ForgotPassword handles password reset request
 func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
 	var req ForgotPasswordRequest
@@ -209,6 +268,21 @@ func (h *Handler) Me(c *fiber.Ctx) error {
 	return presenter.OK(c, user, nil)
 }
 
+// MeFull returns the current user with their customer profile and default address
+func (h *Handler) MeFull(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	resp, err := h.Service.GetUserWithProfile(c.Context(), userID)
+	if err != nil {
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to get user info")
+	}
+
+	return presenter.OK(c, resp, nil)
+}
+
 // Admin endpoints
 
 // GetUsers handles getting all users (admin only)
@@ -423,6 +497,57 @@ func (h *Handler) ForcePasswordReset(c *fiber.Ctx) error {
 	return presenter.OK(c, fiber.Map{"message": "Password reset forced successfully"}, nil)
 }
 
+// ImpersonateUser starts a support session as another user (superadmin only)
+func (h *Handler) ImpersonateUser(c *fiber.Ctx) error {
+	adminID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req ImpersonateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	response, err := h.Service.Impersonate(c.Context(), adminID, userID, req.Reason, sessionInfo(c))
+	if err != nil {
+		if errors.Is(err, ErrImpersonationNotAllowed) {
+			return presenter.Err(c, fiber.StatusForbidden, err.Error())
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return presenter.Err(c, fiber.StatusNotFound, "User not found")
+		}
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to start impersonation")
+	}
+
+	return presenter.Created(c, response)
+}
+
+// EndImpersonation ends the impersonation session of the token making the request
+func (h *Handler) EndImpersonation(c *fiber.Ctx) error {
+	sessionID, ok := c.Locals("impersonationSessionID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusBadRequest, "Not an impersonation token")
+	}
+
+	if err := h.Service.EndImpersonation(c.Context(), sessionID, sessionInfo(c)); err != nil {
+		if errors.Is(err, ErrImpersonationNotActive) {
+			return presenter.Err(c, fiber.StatusNotFound, "Impersonation session is not active")
+		}
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to end impersonation")
+	}
+
+	return presenter.OK(c, fiber.Map{"message": "Impersonation ended"}, nil)
+}
+
 // ChangePassword handles password change for authenticated users
 func (h *Handler) ChangePassword(c *fiber.Ctx) error {
 	userID := c.Locals("userID")
@@ -455,6 +580,98 @@ func (h *Handler) ChangePassword(c *fiber.Ctx) error {
 	return presenter.OK(c, fiber.Map{"message": "Password changed successfully"}, nil)
 }
 
+// SendPhoneCode texts the current user a code to confirm their phone number
+func (h *Handler) SendPhoneCode(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	if err := h.Service.SendPhoneVerification(c.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, ErrPhoneAlreadyVerified):
+			return presenter.Err(c, fiber.StatusBadRequest, "Phone number already verified")
+		case errors.Is(err, ErrPhoneVerificationUnavailable):
+			return presenter.Err(c, fiber.StatusServiceUnavailable, "Phone verification is not available for this account")
+		}
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to send verification code")
+	}
+
+	return presenter.OK(c, fiber.Map{"message": "Verification code sent"}, nil)
+}
+
+// VerifyPhone confirms the current user's phone number with the texted code
+func (h *Handler) VerifyPhone(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req VerifyPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := h.Validator.Struct(&req); err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.Service.VerifyPhone(c.Context(), userID, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidPhoneCode) {
+			return presenter.Err(c, fiber.StatusBadRequest, "Invalid or expired code")
+		}
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to verify phone number")
+	}
+
+	return presenter.OK(c, fiber.Map{"message": "Phone number verified"}, nil)
+}
+
+// ExportMyData downloads everything held about the current user as a JSON file
+func (h *Handler) ExportMyData(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	export, err := h.Service.ExportAccountData(c.Context(), userID, sessionInfo(c))
+	if err != nil {
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to export account data")
+	}
+
+	filename := "errandshop-data-" + time.Now().UTC().Format("2006-01-02") + ".json"
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.JSON(export)
+}
+
+// DeleteMyAccount erases the current user's personal data and closes their account
+func (h *Handler) DeleteMyAccount(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Err(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := h.Validator.Struct(&req); err != nil {
+		return presenter.Err(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.Service.DeleteAccount(c.Context(), userID, req.Password, sessionInfo(c)); err != nil {
+		switch {
+		case errors.Is(err, ErrIncorrectPassword):
+			return presenter.Err(c, fiber.StatusBadRequest, "Password is incorrect")
+		case errors.Is(err, ErrAccountDeletionNotAllowed):
+			return presenter.Err(c, fiber.StatusForbidden, err.Error())
+		case errors.Is(err, ErrAccountDeletionBlocked):
+			return presenter.Err(c, fiber.StatusConflict, err.Error())
+		}
+		return presenter.Err(c, fiber.StatusInternalServerError, "Failed to delete account")
+	}
+
+	return presenter.OK(c, fiber.Map{"message": "Your account and personal data have been deleted"}, nil)
+}
+
 // ResendOTP handles resending OTP for email verification
 func (h *Handler) ResendOTP(c *fiber.Ctx) error {
 	var req ResendOTPRequest
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultImpersonationTTL is how long an impersonation token lasts when none is configured
+const DefaultImpersonationTTL = 30 * time.Minute
+
+// ErrImpersonationNotAllowed is returned when the target account cannot be impersonated
+var ErrImpersonationNotAllowed = errors.New("user cannot be impersonated")
+
+// ErrImpersonationNotActive is returned when ending a session that has already ended or expired
+var ErrImpersonationNotActive = errors.New("impersonation session is not active")
+
+// Impersonate starts a support session in which adminID acts as userID. It issues a short-lived
+// access token marked with the impersonatedBy claim and no refresh token, so the session cannot
+// outlive its TTL. Staff accounts cannot be impersonated.
+func (s *Service) Impersonate(ctx context.Context, adminID, userID uuid.UUID, reason string, session SessionInfo) (*ImpersonationResponse, error) {
+	reason = strings.TrimSpace(reason)
+	if adminID == userID {
+		return nil, fmt.Errorf("%w: cannot impersonate yourself", ErrImpersonationNotAllowed)
+	}
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == "admin" || user.Role == "superadmin" {
+		return nil, fmt.Errorf("%w: staff accounts cannot be impersonated", ErrImpersonationNotAllowed)
+	}
+
+	ttl := s.Cfg.ImpersonationTTL
+	if ttl <= 0 {
+		ttl = DefaultImpersonationTTL
+	}
+	record := &ImpersonationSession{
+		ID:        uuid.New(),
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    reason,
+		IPAddress: session.IPAddress,
+		UserAgent: session.UserAgent,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.Repo.CreateImpersonationSession(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to start impersonation session: %w", err)
+	}
+
+	token, err := s.JWTService.GenerateImpersonationToken(user, adminID, record.ID, record.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	s.logImpersonation(ctx, adminID, "impersonation_started", map[string]interface{}{
+		"session_id":  record.ID,
+		"target_user": userID,
+		"target_role": user.Role,
+		"reason":      reason,
+		"expires_at":  record.ExpiresAt,
+	}, session.IPAddress, session.UserAgent)
+
+	return &ImpersonationResponse{
+		Token:          token,
+		ExpiresIn:      int(ttl / time.Second),
+		ExpiresAt:      record.ExpiresAt,
+		SessionID:      record.ID,
+		ImpersonatedBy: adminID,
+		User:           s.toUserResponse(user),
+	}, nil
+}
+
+// EndImpersonation ends a session early; its token is rejected from then on
+func (s *Service) EndImpersonation(ctx context.Context, sessionID uuid.UUID, session SessionInfo) error {
+	ended, err := s.Repo.EndImpersonationSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+	if ended == nil {
+		return ErrImpersonationNotActive
+	}
+
+	s.logImpersonation(ctx, ended.AdminID, "impersonation_ended", map[string]interface{}{
+		"session_id":  ended.ID,
+		"target_user": ended.UserID,
+		"duration_s":  int(ended.EndedAt.Sub(ended.CreatedAt) / time.Second),
+	}, session.IPAddress, session.UserAgent)
+	return nil
+}
+
+// ImpersonationActive reports whether an impersonation token's session may still be used
+func (s *Service) ImpersonationActive(ctx context.Context, sessionID uuid.UUID) bool {
+	active, err := s.Repo.ImpersonationSessionActive(ctx, sessionID)
+	if err != nil {
+		log.Printf("Failed to check impersonation session %s: %v", sessionID, err)
+		return false
+	}
+	return active
+}
+
+// LogImpersonatedRequest audits one request made with an impersonation token, against the admin
+func (s *Service) LogImpersonatedRequest(ctx context.Context, sessionID, adminID, userID uuid.UUID, method, path, ipAddress, userAgent string) {
+	s.logImpersonation(ctx, adminID, "impersonated_request", map[string]interface{}{
+		"session_id":  sessionID,
+		"target_user": userID,
+		"method":      method,
+		"path":        path,
+	}, ipAddress, userAgent)
+}
+
+func (s *Service) logImpersonation(ctx context.Context, adminID uuid.UUID, action string, metadata map[string]interface{}, ipAddress, userAgent string) {
+	if s.AuditService == nil {
+		return
+	}
+	if err := s.AuditService.LogUserAction(ctx, adminID, action, "impersonation", metadata, ipAddress, userAgent); err != nil {
+		log.Printf("Failed to audit %s by %s: %v", action, adminID, err)
+	}
+}
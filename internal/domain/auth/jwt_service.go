@@ -44,6 +44,38 @@ func (j *JWTService) GenerateToken(user *User) (string, error) {
 	return token.SignedString(j.secret)
 }
 
+// GenerateImpersonationToken issues a short-lived access token for user on behalf of adminID.
+// The token carries the impersonatedBy claim and sessionID as its jti so the middleware can
+// audit every request, reject sensitive actions and honour an early end of the session.
+func (j *JWTService) GenerateImpersonationToken(user *User, adminID, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
+	permissions := user.Permissions
+	if len(permissions) == 0 {
+		permissions = GetUserPermissions(user.Role)
+	}
+
+	now := time.Now()
+	claims := &jwt.JWTClaims{
+		UserID:         user.ID,
+		Sub:            user.ID.String(),
+		Email:          user.Email,
+		Name:           user.Name,
+		Role:           user.Role,
+		Permissions:    permissions,
+		Iat:            now.Unix(),
+		Exp:            expiresAt.Unix(),
+		ImpersonatedBy: &adminID,
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			ID:        sessionID.String(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwtlib.NewNumericDate(now),
+			ExpiresAt: jwtlib.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
+	return token.SignedString(j.secret)
+}
+
 func (j *JWTService) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 	claims := jwtlib.MapClaims{
 		"sub":  userID.String(),
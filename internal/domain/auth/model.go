@@ -15,3 +15,6 @@ type RefreshToken = models.RefreshToken
 
 // OTP type alias for the shared model
 type OTP = models.OTP
+
+// ImpersonationSession type alias for the shared model
+type ImpersonationSession = models.ImpersonationSession
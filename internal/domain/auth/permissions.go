@@ -40,6 +40,10 @@ var RolePermissions = map[string][]Permission{
 		PermissionManageProducts,
 		PermissionViewAnalytics,
 	},
+	"driver": {
+		PermissionReadProfile,
+		PermissionUpdateProfile,
+	},
 	"superadmin": {
 		PermissionAll,
 	},
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// phoneVerificationOTP is the OTP type for codes sent by SMS to confirm a phone number
+const phoneVerificationOTP = "phone_verification"
+
+// ErrPhoneVerificationUnavailable is returned when no SMS sender is configured or the user has no phone number
+var ErrPhoneVerificationUnavailable = errors.New("phone verification is unavailable")
+
+// ErrPhoneAlreadyVerified is returned when asking to verify a phone number that is already confirmed
+var ErrPhoneAlreadyVerified = errors.New("phone number already verified")
+
+// ErrInvalidPhoneCode is returned for a phone verification code that is wrong, used or expired
+var ErrInvalidPhoneCode = errors.New("invalid or expired code")
+
+// SendPhoneVerification texts the user a code that proves they own their phone number. Only a
+// confirmed number receives transactional SMS; a verified email is not enough.
+func (s *Service) SendPhoneVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.SMSSender == nil || user.Phone == "" {
+		return ErrPhoneVerificationUnavailable
+	}
+	if user.PhoneVerifiedAt != nil {
+		return ErrPhoneAlreadyVerified
+	}
+
+	code, err := s.generateOTP()
+	if err != nil {
+		return err
+	}
+	if err := s.Repo.SaveOTP(ctx, &OTP{
+		UserID:    userID,
+		Email:     user.Email,
+		Code:      code,
+		Type:      phoneVerificationOTP,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}); err != nil {
+		return fmt.Errorf("failed to save OTP: %w", err)
+	}
+
+	message := fmt.Sprintf("Your Errand Shop phone verification code is %s. It expires in 10 minutes.", code)
+	if err := s.SMSSender.SendSMS(ctx, user.Phone, message); err != nil {
+		return fmt.Errorf("failed to send verification SMS: %w", err)
+	}
+	return nil
+}
+
+// VerifyPhone confirms the user's phone number with a code from SendPhoneVerification
+func (s *Service) VerifyPhone(ctx context.Context, userID uuid.UUID, code string) error {
+	otp, err := s.Repo.GetValidUserOTP(ctx, userID, code, phoneVerificationOTP)
+	if err != nil {
+		return ErrInvalidPhoneCode
+	}
+	otp.Used = true
+	if err := s.Repo.UpdateOTP(ctx, otp); err != nil {
+		return err
+	}
+
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	if err := s.Repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if s.AuditService != nil {
+		s.AuditService.LogUserAction(ctx, userID, "phone_verified", "user", map[string]interface{}{
+			"phone": user.Phone,
+		}, "", "")
+	}
+	return nil
+}
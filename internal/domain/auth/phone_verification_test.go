@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type recordingSMS struct {
+	messages []string
+}
+
+func (r *recordingSMS) SendSMS(ctx context.Context, to, message string) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func TestVerifiedPhoneNeedsAConfirmedNumber(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			first_name TEXT NOT NULL DEFAULT '',
+			last_name TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL,
+			password TEXT NOT NULL DEFAULT '',
+			phone TEXT NOT NULL,
+			avatar TEXT,
+			role TEXT DEFAULT 'customer',
+			permissions TEXT,
+			status TEXT DEFAULT 'active',
+			is_verified BOOLEAN DEFAULT 0,
+			phone_verified_at DATETIME,
+			force_reset BOOLEAN DEFAULT 0,
+			last_login_at DATETIME,
+			referral_code TEXT,
+			referred_by_id TEXT,
+			referral_credited_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE otps (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+			user_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			code TEXT NOT NULL,
+			type TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			used BOOLEAN DEFAULT 0,
+			created_at DATETIME,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	userID := uuid.New()
+	if err := db.Exec(`INSERT INTO users (id, email, phone, is_verified) VALUES (?, 'ada@example.com', '+2348000000000', 1)`, userID).Error; err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+
+	texts := &recordingSMS{}
+	s := &Service{Repo: NewRepository(db), SMSSender: texts}
+	ctx := context.Background()
+
+	// A verified email does not vouch for the phone number
+	if phone, err := s.VerifiedPhone(ctx, userID); err != nil || phone != "" {
+		t.Fatalf("VerifiedPhone before confirming = %q, %v; want none", phone, err)
+	}
+
+	if err := s.SendPhoneVerification(ctx, userID); err != nil {
+		t.Fatalf("SendPhoneVerification: %v", err)
+	}
+	if len(texts.messages) != 1 {
+		t.Fatalf("sent %d texts, want 1", len(texts.messages))
+	}
+	code := regexp.MustCompile(`\d{6}`).FindString(texts.messages[0])
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if err := s.VerifyPhone(ctx, userID, wrong); !errors.Is(err, ErrInvalidPhoneCode) {
+		t.Fatalf("VerifyPhone with a wrong code: err = %v, want ErrInvalidPhoneCode", err)
+	}
+	if err := s.VerifyPhone(ctx, userID, code); err != nil {
+		t.Fatalf("VerifyPhone: %v", err)
+	}
+	if phone, err := s.VerifiedPhone(ctx, userID); err != nil || phone != "+2348000000000" {
+		t.Fatalf("VerifiedPhone after confirming = %q, %v; want the number", phone, err)
+	}
+	if err := s.SendPhoneVerification(ctx, userID); !errors.Is(err, ErrPhoneAlreadyVerified) {
+		t.Fatalf("SendPhoneVerification again: err = %v, want ErrPhoneAlreadyVerified", err)
+	}
+}
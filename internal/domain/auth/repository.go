@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,7 +27,7 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 	var user User
 	// Make email lookup case-insensitive and avoid scanning permissions column
 	err := r.db.WithContext(ctx).
-		Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, force_reset, last_login_at, password, created_at, updated_at").
+		Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, phone_verified_at, force_reset, last_login_at, password, referral_code, referred_by_id, referral_credited_at, created_at, updated_at").
 		Where("LOWER(email) = LOWER(?)", email).
 		First(&user).Error
 	if err != nil {
@@ -42,7 +43,7 @@ func (r *Repository) GetByPhone(ctx context.Context, phone string) (*User, error
 	var user User
 	// Avoid scanning permissions column
 	err := r.db.WithContext(ctx).
-		Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, force_reset, last_login_at, password, created_at, updated_at").
+		Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, phone_verified_at, force_reset, last_login_at, password, referral_code, referred_by_id, referral_credited_at, created_at, updated_at").
 		Where("phone = ?", phone).
 		First(&user).Error
 	if err != nil {
@@ -58,7 +59,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	var user User
 	// Avoid scanning permissions column
 	err := r.db.WithContext(ctx).
-		Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, force_reset, last_login_at, password, created_at, updated_at").
+		Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, phone_verified_at, force_reset, last_login_at, password, referral_code, referred_by_id, referral_credited_at, created_at, updated_at").
 		Where("id = ?", id).
 		First(&user).Error
 	if err != nil {
@@ -70,6 +71,22 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	return &user, nil
 }
 
+// GetByReferralCode finds the user who owns a referral code (case-insensitive)
+func (r *Repository) GetByReferralCode(ctx context.Context, code string) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).
+		Select("id, email, phone, status").
+		Where("referral_code = ?", strings.ToUpper(strings.TrimSpace(code))).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *Repository) Update(ctx context.Context, user *User) error {
 	return r.db.WithContext(ctx).Save(user).Error
 }
@@ -93,7 +110,7 @@ func (r *Repository) GetUsers(ctx context.Context, offset, limit int, search, ro
 	}
 
 	// Avoid scanning permissions column by selecting safe fields only
-	query = query.Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, force_reset, last_login_at, created_at, updated_at")
+	query = query.Select("id, first_name, last_name, name, email, phone, avatar, role, status, is_verified, force_reset, last_login_at, referral_code, created_at, updated_at")
 
 	var users []*User
 	err := query.Offset(offset).Limit(limit).Find(&users).Error
@@ -141,6 +158,23 @@ func (r *Repository) GetValidOTP(ctx context.Context, email, otpCode, purpose st
 	return &otp, nil
 }
 
+// GetValidUserOTP finds an unused, unexpired code of the given purpose issued to the user
+func (r *Repository) GetValidUserOTP(ctx context.Context, userID uuid.UUID, code, purpose string) (*OTP, error) {
+	var otp OTP
+	err := r.db.WithContext(ctx).Where(
+		"user_id = ? AND code = ? AND type = ? AND expires_at > ? AND used = false",
+		userID, code, purpose, time.Now(),
+	).First(&otp).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid or expired OTP")
+		}
+		return nil, err
+	}
+	return &otp, nil
+}
+
 func (r *Repository) UpdateOTP(ctx context.Context, otp *OTP) error {
 	return r.db.WithContext(ctx).Save(otp).Error
 }
@@ -182,15 +216,64 @@ func (r *Repository) DeleteUserRefreshTokens(ctx context.Context, userID uuid.UU
 	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&RefreshToken{}).Error
 }
 
-func (r *Repository) StoreRefreshToken(ctx context.Context, userID uuid.UUID, token string) error {
+func (r *Repository) StoreRefreshToken(ctx context.Context, userID uuid.UUID, token string, session SessionInfo) error {
 	refreshToken := &RefreshToken{
-		UserID:    userID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), // 7 days
+		UserID:     userID,
+		Token:      token,
+		ExpiresAt:  time.Now().Add(7 * 24 * time.Hour), // 7 days
+		DeviceName: session.DeviceName,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
 	}
 	return r.db.WithContext(ctx).Create(refreshToken).Error
 }
 
+// ListActiveRefreshTokens returns a user's unexpired refresh tokens, most recently used first
+func (r *Repository) ListActiveRefreshTokens(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	var tokens []RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("updated_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// DeleteUserRefreshTokenByID revokes one of the user's refresh tokens; false means none matched
+func (r *Repository) DeleteUserRefreshTokenByID(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&RefreshToken{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Impersonation session operations
+func (r *Repository) CreateImpersonationSession(ctx context.Context, session *ImpersonationSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// ImpersonationSessionActive reports whether a session exists, has not been ended and has not expired
+func (r *Repository) ImpersonationSessionActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&ImpersonationSession{}).
+		Where("id = ? AND ended_at IS NULL AND expires_at > ?", id, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// EndImpersonationSession marks an active session ended, returning it, or nil if it was not active
+func (r *Repository) EndImpersonationSession(ctx context.Context, id uuid.UUID) (*ImpersonationSession, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&ImpersonationSession{}).
+		Where("id = ? AND ended_at IS NULL AND expires_at > ?", id, now).
+		Update("ended_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	var session ImpersonationSession
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 // Address operations
 func (r *Repository) CreateAddress(ctx context.Context, address *Address) error {
 	return r.db.WithContext(ctx).Create(address).Error
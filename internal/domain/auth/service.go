@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto/rand"
 	"errandShop/config"
+	"errors"
 	"errandShop/internal/domain/customers"
 	"errandShop/internal/services/audit"
 	"errandShop/internal/services/email"
+	"errandShop/internal/services/sms"
 	"fmt"
 	"log"
 	"math/big"
@@ -17,6 +19,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrSessionNotFound is returned when revoking a session the user does not have
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrInvalidReferralCode is returned when registering with a referral code nobody owns, or one's own
+var ErrInvalidReferralCode = errors.New("invalid referral code")
+
 // Matches jwt.GenerateToken access TTL (24h).
 const accessTokenExpiresInSeconds = int(24 * time.Hour / time.Second)
 
@@ -33,6 +41,10 @@ type Service struct {
 	EmailService    *email.ResendService
 	AuditService    *audit.AuditService
 	CustomerService customers.Service
+	// SMSSender is optional; when set, OTPs fall back to SMS if the email send fails
+	SMSSender sms.SMSSender
+
+	accountDataSources []NamedAccountDataSource // see account_data.go
 }
 
 // Update NewService function
@@ -47,11 +59,72 @@ func NewService(repo *Repository, cfg *config.Config, emailService *email.Resend
 	}
 }
 
+// SetSMSSender enables SMS delivery of OTPs when email fails
+func (s *Service) SetSMSSender(sender sms.SMSSender) {
+	s.SMSSender = sender
+}
+
+// VerifiedPhone returns the user's phone number if they have confirmed it (see phone_verification.go),
+// or "" otherwise. A verified email says nothing about the phone, so it does not count.
+func (s *Service) VerifiedPhone(ctx context.Context, userID uuid.UUID) (string, error) {
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.PhoneVerifiedAt == nil {
+		return "", nil
+	}
+	return user.Phone, nil
+}
+
+// IsEmailVerified reports whether the user has verified their email address
+func (s *Service) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.IsVerified, nil
+}
+
+// VerifiedEmail returns the user's email address if their account is verified, or "" otherwise
+func (s *Service) VerifiedEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	user, err := s.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if !user.IsVerified {
+		return "", nil
+	}
+	return user.Email, nil
+}
+
+// sendOTPBySMS is the fallback for a failed OTP email; it returns the email error if SMS is unavailable or also fails
+func (s *Service) sendOTPBySMS(ctx context.Context, phone, otp, purpose string, emailErr error) error {
+	if s.SMSSender == nil || phone == "" {
+		return emailErr
+	}
+	message := fmt.Sprintf("Your Errand Shop verification code is %s. It expires in 10 minutes.", otp)
+	if purpose == "password_reset" {
+		message = fmt.Sprintf("Your Errand Shop password reset code is %s. It expires in 10 minutes.", otp)
+	}
+	if err := s.SMSSender.SendSMS(ctx, phone, message); err != nil {
+		log.Printf("SMS fallback for %s OTP failed: %v", purpose, err)
+		return emailErr
+	}
+	log.Printf("Email failed for %s OTP, sent by SMS instead: %v", purpose, emailErr)
+	return nil
+}
+
+// normalizePhone is how phone numbers are stored and looked up
+func normalizePhone(phone string) string {
+	return strings.TrimSpace(phone)
+}
+
 // Update Register method to include permissions and email
-func (s *Service) Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error) {
+func (s *Service) Register(ctx context.Context, req RegisterRequest, session SessionInfo) (*AuthResponse, error) {
 	// Normalize email and phone to prevent duplicates
 	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
-	req.Phone = strings.TrimSpace(req.Phone)
+	req.Phone = normalizePhone(req.Phone)
 
 	// Check if user already exists
 	existingUser, _ := s.Repo.GetByEmail(ctx, req.Email)
@@ -80,6 +153,16 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 		fullName = firstName + " " + lastName
 	}
 
+	// Link the referrer; the same phone number on both sides counts as self-referral
+	var referredByID *uuid.UUID
+	if code := strings.TrimSpace(req.ReferralCode); code != "" {
+		referrer, err := s.Repo.GetByReferralCode(ctx, code)
+		if err != nil || referrer.Phone == req.Phone {
+			return nil, ErrInvalidReferralCode
+		}
+		referredByID = &referrer.ID
+	}
+
 	// Create user
 	user := &User{
 		Name:      fullName,
@@ -89,6 +172,7 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 		Password:  req.Password,
 		Phone:     req.Phone,
 		Role:      "customer",
+		ReferredByID: referredByID,
 	}
 
 	if err := s.Repo.Create(ctx, user); err != nil {
@@ -125,9 +209,12 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 
 	// Save refresh token
 	refreshTokenRecord := &RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		UserID:     user.ID,
+		Token:      refreshToken,
+		ExpiresAt:  time.Now().Add(7 * 24 * time.Hour),
+		DeviceName: session.DeviceName,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
 	}
 	s.Repo.CreateRefreshToken(ctx, refreshTokenRecord)
 
@@ -181,7 +268,9 @@ func (s *Service) ForgotPassword(ctx context.Context, email string) error {
 
 	// Send email using Resend
 	if err := s.EmailService.SendOTPEmail(ctx, email, otp, "password_reset"); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		if err := s.sendOTPBySMS(ctx, user.Phone, otp, "password_reset", err); err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
 	}
 
 	// Audit log
@@ -192,8 +281,20 @@ func (s *Service) ForgotPassword(ctx context.Context, email string) error {
 	return nil
 }
 
-func (s *Service) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
-	user, err := s.Repo.GetByEmail(ctx, req.Email)
+func (s *Service) Login(ctx context.Context, req LoginRequest, session SessionInfo) (*AuthResponse, error) {
+	identifier := strings.TrimSpace(req.Identifier)
+	if identifier == "" {
+		identifier = strings.TrimSpace(req.Email)
+	}
+
+	// Anything with an @ is an email address; everything else is looked up as a phone number
+	var user *User
+	var err error
+	if strings.Contains(identifier, "@") {
+		user, err = s.Repo.GetByEmail(ctx, strings.ToLower(identifier))
+	} else {
+		user, err = s.Repo.GetByPhone(ctx, normalizePhone(identifier))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid credentials")
 	}
@@ -236,9 +337,12 @@ func (s *Service) Login(ctx context.Context, req LoginRequest) (*AuthResponse, e
 		return nil, err
 	}
 	refreshTokenRecord := &RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		UserID:     user.ID,
+		Token:      refreshToken,
+		ExpiresAt:  time.Now().Add(7 * 24 * time.Hour),
+		DeviceName: session.DeviceName,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
 	}
 	s.Repo.CreateRefreshToken(ctx, refreshTokenRecord)
 
@@ -277,7 +381,9 @@ func (s *Service) SendVerificationEmail(ctx context.Context, userID uuid.UUID) e
 
 	// Send OTP via email
 	if err := s.EmailService.SendOTPEmail(ctx, user.Email, code, "email_verification"); err != nil {
-		return fmt.Errorf("failed to send verification email: %w", err)
+		if err := s.sendOTPBySMS(ctx, user.Phone, code, "email_verification", err); err != nil {
+			return fmt.Errorf("failed to send verification email: %w", err)
+		}
 	}
 
 	return nil
@@ -314,7 +420,7 @@ func (s *Service) generateOTP() (string, error) {
 // Add missing methods:
 
 // VerifyEmailWithCode verifies email using only the OTP code
-func (s *Service) VerifyEmailWithCode(ctx context.Context, code string) (*AuthResponse, error) {
+func (s *Service) VerifyEmailWithCode(ctx context.Context, code string, session SessionInfo) (*AuthResponse, error) {
 	// Find OTP record by code
 	otpRecord, err := s.Repo.GetValidOTPByCode(ctx, code, "email_verification")
 	if err != nil {
@@ -352,7 +458,7 @@ func (s *Service) VerifyEmailWithCode(ctx context.Context, code string) (*AuthRe
 	}
 
 	// Store refresh token
-	if err := s.Repo.StoreRefreshToken(ctx, user.ID, refreshToken); err != nil {
+	if err := s.Repo.StoreRefreshToken(ctx, user.ID, refreshToken, session); err != nil {
 		return nil, err
 	}
 
@@ -372,7 +478,7 @@ func (s *Service) VerifyEmailWithCode(ctx context.Context, code string) (*AuthRe
 }
 
 // VerifyEmail with email and OTP parameters (kept for backward compatibility)
-func (s *Service) VerifyEmailWithOTP(ctx context.Context, email, otp string) (*AuthResponse, error) {
+func (s *Service) VerifyEmailWithOTP(ctx context.Context, email, otp string, session SessionInfo) (*AuthResponse, error) {
 	// Get user by email
 	user, err := s.Repo.GetByEmail(ctx, email)
 	if err != nil {
@@ -410,7 +516,7 @@ func (s *Service) VerifyEmailWithOTP(ctx context.Context, email, otp string) (*A
 	}
 
 	// Store refresh token
-	if err := s.Repo.StoreRefreshToken(ctx, user.ID, refreshToken); err != nil {
+	if err := s.Repo.StoreRefreshToken(ctx, user.ID, refreshToken, session); err != nil {
 		return nil, err
 	}
 
@@ -430,7 +536,7 @@ func (s *Service) VerifyEmailWithOTP(ctx context.Context, email, otp string) (*A
 }
 
 // RefreshToken method
-func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*RefreshTokenResponse, error) {
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string, session SessionInfo) (*RefreshTokenResponse, error) {
 	// Validate refresh token
 	tokenRecord, err := s.Repo.GetRefreshToken(ctx, refreshToken)
 	if err != nil {
@@ -457,11 +563,18 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Refre
 	// Delete old refresh token
 	s.Repo.DeleteRefreshToken(ctx, refreshToken)
 
-	// Save new refresh token
+	// Save new refresh token; the session keeps its device and start time across rotations
 	refreshTokenRecord := &RefreshToken{
-		UserID:    user.ID,
-		Token:     newRefreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		UserID:     user.ID,
+		Token:      newRefreshToken,
+		ExpiresAt:  time.Now().Add(7 * 24 * time.Hour),
+		DeviceName: tokenRecord.DeviceName,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  tokenRecord.CreatedAt,
+	}
+	if session.DeviceName != "" {
+		refreshTokenRecord.DeviceName = session.DeviceName
 	}
 	s.Repo.CreateRefreshToken(ctx, refreshTokenRecord)
 
@@ -477,6 +590,46 @@ func (s *Service) Logout(ctx context.Context, userID uuid.UUID) error {
 	return s.Repo.DeleteUserRefreshTokens(ctx, userID)
 }
 
+// ListSessions returns the user's active sessions (unexpired refresh tokens)
+func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID) ([]SessionResponse, error) {
+	tokens, err := s.Repo.ListActiveRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]SessionResponse, len(tokens))
+	for i, t := range tokens {
+		sessions[i] = SessionResponse{
+			ID:         t.ID,
+			DeviceName: t.DeviceName,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			CreatedAt:  t.CreatedAt,
+			LastUsedAt: t.UpdatedAt,
+			ExpiresAt:  t.ExpiresAt,
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs out a single session by deleting its refresh token
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	deleted, err := s.Repo.DeleteUserRefreshTokenByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+
+	if s.AuditService != nil {
+		s.AuditService.LogUserAction(ctx, userID, "session_revoked", "session", map[string]interface{}{
+			"session_id": sessionID,
+		}, "", "")
+	}
+	return nil
+}
+
 // ForgotPassword method
 func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
 	user, err := s.Repo.GetByEmail(ctx, email)
@@ -556,6 +709,41 @@ func (s *Service) GetUserByID(ctx context.Context, userID uuid.UUID) (*UserRespo
 	return &userResponse, nil
 }
 
+// GetUserWithProfile returns the user plus their customer profile and default address.
+// Profile lookup failures are logged and leave those fields empty rather than failing the request.
+func (s *Service) GetUserWithProfile(ctx context.Context, userID uuid.UUID) (*MeFullResponse, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &MeFullResponse{User: user}
+	// Only customers carry a profile; looking one up for staff would auto-create it
+	if s.CustomerService == nil || user.Role != "customer" {
+		return resp, nil
+	}
+
+	customer, err := s.CustomerService.GetCustomerByUserID(userID)
+	if err != nil {
+		log.Printf("Failed to load customer profile for user %s: %v", userID, err)
+		return resp, nil
+	}
+	resp.Customer = customer
+
+	for i := range customer.Addresses {
+		if customer.Addresses[i].IsDefault {
+			resp.DefaultAddress = &customer.Addresses[i]
+			break
+		}
+	}
+	// Fall back to the only address when none is flagged default
+	if resp.DefaultAddress == nil && len(customer.Addresses) == 1 {
+		resp.DefaultAddress = &customer.Addresses[0]
+	}
+
+	return resp, nil
+}
+
 // GetUsers method
 func (s *Service) GetUsers(ctx context.Context, page, limit int, search, role, status string) ([]*UserResponse, int64, error) {
 	offset := (page - 1) * limit
@@ -597,6 +785,8 @@ func (s *Service) toUserResponse(user *User) UserResponse {
 		Role:        user.Role,
 		Permissions: permissions,
 		Status:      user.Status,
+		ReferralCode: user.ReferralCode,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
 		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
 	}
 }
@@ -645,10 +835,11 @@ func (s *Service) UpdateUser(ctx context.Context, userID uuid.UUID, req UpdateUs
 	if err != nil {
 		return nil, err
 	}
+	previousPhone := user.Phone
 
 	// Check for phone number uniqueness if phone is being updated
 	if req.Phone != nil {
-		normalizedPhone := strings.TrimSpace(*req.Phone)
+		normalizedPhone := normalizePhone(*req.Phone)
 		if normalizedPhone != user.Phone {
 			existingUser, err := s.Repo.GetByPhone(ctx, normalizedPhone)
 			if err == nil && existingUser.ID != userID {
@@ -686,6 +877,9 @@ func (s *Service) UpdateUser(ctx context.Context, userID uuid.UUID, req UpdateUs
 	if req.Status != nil {
 		user.Status = *req.Status
 	}
+	if user.Phone != previousPhone {
+		user.PhoneVerifiedAt = nil // the new number has to be confirmed again
+	}
 
 	if err := s.Repo.Update(ctx, user); err != nil {
 		return nil, err
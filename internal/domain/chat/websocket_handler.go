@@ -87,6 +87,10 @@ func (wh *WebSocketHandler) extractUserInfo(c *websocket.Conn) (uuid.UUID, strin
 		log.Printf("DEBUG: Token claims validation failed")
 		return uuid.Nil, "", nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid token claims")
 	}
+	// Live chat goes out under the user's name, which support must not do while impersonating
+	if claims.ImpersonatedBy != nil {
+		return uuid.Nil, "", nil, fiber.NewError(fiber.StatusForbidden, "Chat is not available while impersonating a user")
+	}
 
 	// DEBUG: Log token claims
 	log.Printf("DEBUG: Token claims - UserID: %s, Role: %s", claims.UserID, claims.Role)
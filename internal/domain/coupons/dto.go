@@ -8,35 +8,57 @@ import (
 // Request DTOs
 type CreateCouponRequest struct {
 	Code               string     `json:"code" validate:"required,min=3,max=50"`
-	Type               CouponType `json:"type" validate:"required,oneof=percentage fixed"`
-	Value              float64    `json:"value" validate:"required,gt=0"`
+	Type               CouponType `json:"type" validate:"required,oneof=percentage fixed free_delivery"`
+	Value              float64    `json:"value" validate:"required_unless=Type free_delivery,gte=0"` // percent, or kobo for fixed coupons
 	Description        string     `json:"description"`
 	MaxUsage           *int       `json:"maxUsage" validate:"omitempty,gt=0"`
+	StartsAt           *time.Time `json:"startsAt"`
 	ExpiryDate         *time.Time `json:"expiryDate"`
 	IsActive           *bool      `json:"isActive"`
 	LinkedUserID       *uuid.UUID `json:"linkedUserId"`
-	MinimumOrderAmount float64    `json:"minimumOrderAmount" validate:"gte=0"`
+	MinimumOrderAmount int64      `json:"minimumOrderAmount" validate:"gte=0"` // in kobo
+	ApplicableZoneIDs  []string   `json:"applicableZoneIds" validate:"omitempty,dive,required"` // empty means every zone
+}
+
+// BulkGenerateCouponsRequest creates Count single-use coupons with random codes
+// of the form PREFIX-XXXXXXXX, e.g. for printed campaign handouts.
+type BulkGenerateCouponsRequest struct {
+	Count              int        `json:"count" validate:"required,min=1,max=1000"`
+	Prefix             string     `json:"prefix" validate:"required,alphanum,min=2,max=20"`
+	Type               CouponType `json:"type" validate:"required,oneof=percentage fixed free_delivery"`
+	Value              float64    `json:"value" validate:"required_unless=Type free_delivery,gte=0"`
+	Description        string     `json:"description"`
+	StartsAt           *time.Time `json:"startsAt"`
+	ExpiryDate         *time.Time `json:"expiryDate" validate:"required"`
+	MinimumOrderAmount int64      `json:"minimumOrderAmount" validate:"gte=0"` // in kobo
+	ApplicableZoneIDs  []string   `json:"applicableZoneIds" validate:"omitempty,dive,required"`
 }
 
 type UpdateCouponRequest struct {
 	Description        *string    `json:"description"`
 	MaxUsage           *int       `json:"maxUsage" validate:"omitempty,gt=0"`
+	StartsAt           *time.Time `json:"startsAt"`
 	ExpiryDate         *time.Time `json:"expiryDate"`
 	IsActive           *bool      `json:"isActive"`
-	MinimumOrderAmount *float64   `json:"minimumOrderAmount" validate:"omitempty,gte=0"`
+	MinimumOrderAmount *int64     `json:"minimumOrderAmount" validate:"omitempty,gte=0"`
+	ApplicableZoneIDs  *[]string  `json:"applicableZoneIds" validate:"omitempty,dive,required"` // [] lifts the zone restriction
 }
 
 type ValidateCouponRequest struct {
 	Code        string    `json:"code" validate:"required"`
 	UserID      uuid.UUID `json:"userId" validate:"required"`
-	OrderAmount float64   `json:"orderAmount" validate:"required,gt=0"`
+	OrderAmount int64     `json:"orderAmount" validate:"required,gt=0"` // in kobo
+	DeliveryFee int64     `json:"deliveryFee" validate:"gte=0"`         // in kobo; what a free delivery coupon takes off
+	ZoneID      string    `json:"zoneId"`                               // matched delivery zone, checked against zone-restricted coupons
 }
 
 type ApplyCouponRequest struct {
 	Code        string    `json:"code" validate:"required"`
 	UserID      uuid.UUID `json:"userId" validate:"required"`
 	OrderID     uuid.UUID `json:"orderId" validate:"required"`
-	OrderAmount float64   `json:"orderAmount" validate:"required,gt=0"`
+	OrderAmount int64     `json:"orderAmount" validate:"required,gt=0"` // in kobo
+	DeliveryFee int64     `json:"deliveryFee" validate:"gte=0"`         // in kobo
+	ZoneID      string    `json:"zoneId"`
 }
 
 type ConvertRefundRequest struct {
@@ -44,10 +66,13 @@ type ConvertRefundRequest struct {
 	CouponCode     *string   `json:"couponCode"`
 }
 
+// MaxMobileFixedCouponKobo caps fixed coupons customers generate from the app (₦50)
+const MaxMobileFixedCouponKobo = 5000
+
 // Mobile App Auto-Generation Request
 type MobileAutoGenerateCouponRequest struct {
 	Type        CouponType `json:"type" validate:"required,oneof=percentage fixed"`
-	Value       float64    `json:"value" validate:"required,gt=0,lte=50"` // Max 50% or $50
+	Value       float64    `json:"value" validate:"required,gt=0"` // Max 50%, or MaxMobileFixedCouponKobo for fixed
 	Description string     `json:"description" validate:"required,min=10,max=200"`
 	ExpiryDays  *int       `json:"expiryDays" validate:"omitempty,min=1,max=365"` // Optional, max 1 year
 }
@@ -55,7 +80,7 @@ type MobileAutoGenerateCouponRequest struct {
 type CreateRefundCreditRequest struct {
 	OrderID      uuid.UUID `json:"orderId" validate:"required"`
 	UserID       uuid.UUID `json:"userId" validate:"required"`
-	RefundAmount float64   `json:"refundAmount" validate:"required,gt=0"`
+	RefundAmount int64     `json:"refundAmount" validate:"required,gt=0"` // in kobo
 	Reason       string    `json:"reason" validate:"required"`
 }
 
@@ -68,19 +93,33 @@ type CouponResponse struct {
 	Description        string     `json:"description"`
 	MaxUsage           *int       `json:"maxUsage"`
 	UsageCount         int        `json:"usageCount"`
+	StartsAt           *time.Time `json:"startsAt"`
 	ExpiryDate         *time.Time `json:"expiryDate"`
 	IsActive           bool       `json:"isActive"`
 	CreatedBy          string     `json:"createdBy"`
 	LinkedOrderID      *uuid.UUID `json:"linkedOrderId"`
 	LinkedUserID       *uuid.UUID `json:"linkedUserId"`
-	MinimumOrderAmount float64    `json:"minimumOrderAmount"`
+	MinimumOrderAmount int64      `json:"minimumOrderAmount"`
+	ApplicableZoneIDs  []string   `json:"applicableZoneIds"`
 	CreatedAt          time.Time  `json:"createdAt"`
 	UpdatedAt          time.Time  `json:"updatedAt"`
 }
 
+type BulkGenerateCouponsResponse struct {
+	Count              int        `json:"count"`
+	Type               CouponType `json:"type"`
+	Value              float64    `json:"value"`
+	StartsAt           *time.Time `json:"startsAt"`
+	ExpiryDate         *time.Time `json:"expiryDate"`
+	MinimumOrderAmount int64      `json:"minimumOrderAmount"`
+	Codes              []string   `json:"codes"`
+}
+
 type CouponValidationResponse struct {
 	Valid          bool    `json:"valid"`
-	DiscountAmount float64 `json:"discountAmount"`
+	DiscountAmount int64   `json:"discountAmount"` // in kobo
+	AppliesToDelivery bool `json:"appliesToDelivery"` // the discount comes off the delivery fee, not the items
+	DiscountAmountNaira float64 `json:"discountAmountNaira"`
 	Message        string  `json:"message"`
 	Coupon         *CouponResponse `json:"coupon,omitempty"`
 }
@@ -89,7 +128,7 @@ type UserRefundCreditResponse struct {
 	ID                uuid.UUID        `json:"id"`
 	UserID            uuid.UUID        `json:"userId"`
 	OriginalOrderID   uuid.UUID        `json:"originalOrderId"`
-	RefundAmount      float64          `json:"refundAmount"`
+	RefundAmount      int64            `json:"refundAmount"`
 	ConvertedToCoupon bool             `json:"convertedToCoupon"`
 	CouponID          *uuid.UUID       `json:"couponId"`
 	CreatedAt         time.Time        `json:"createdAt"`
@@ -101,14 +140,28 @@ type CouponStatsResponse struct {
 	ActiveCoupons          int     `json:"activeCoupons"`
 	TotalUsage             int     `json:"totalUsage"`
 	SystemCoupons          int     `json:"systemCoupons"`
-	TotalDiscountGiven     float64 `json:"totalDiscountGiven"`
+	TotalDiscountGiven     int64   `json:"totalDiscountGiven"` // in kobo
 	TopPerformingCoupons   []CouponPerformance `json:"topPerformingCoupons"`
 }
 
 type CouponPerformance struct {
 	Coupon         CouponResponse `json:"coupon"`
 	UsageCount     int            `json:"usageCount"`
-	TotalDiscount  float64        `json:"totalDiscount"`
+	TotalDiscount  int64          `json:"totalDiscount"`
+}
+
+// CouponFilter narrows coupon list queries. Zero values are ignored.
+type CouponFilter struct {
+	IsActive     *bool
+	Type         CouponType
+	CreatedBy    string
+	LinkedUserID *uuid.UUID
+	Search       string     // Matches code or description (case-insensitive)
+	CreatedAfter *time.Time // created_at >= CreatedAfter
+
+	// AvailableFor limits results to coupons the user can still redeem: not expired,
+	// under their usage limit, and either general or linked to this user.
+	AvailableFor *uuid.UUID
 }
 
 // Pagination
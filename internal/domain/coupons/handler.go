@@ -1,10 +1,14 @@
 package coupons
 
 import (
+	"bytes"
+	"encoding/csv"
 	"errandShop/internal/presenter"
 	"errandShop/internal/validation"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -51,12 +55,84 @@ func (h *Handler) CreateCoupon(c *fiber.Ctx) error {
 
 	coupon, err := h.service.CreateCoupon(req, createdByUserID)
 	if err != nil {
+		if strings.Contains(err.Error(), "start date") {
+			return presenter.BadRequest(c, err.Error())
+		}
 		return presenter.InternalServerError(c, "Failed to create coupon")
 	}
 
 	return presenter.Success(c, "Coupon created successfully", coupon)
 }
 
+// BulkGenerateCoupons creates many single-use coupons at once.
+// Pass ?format=csv to download the codes instead of receiving JSON.
+// POST /api/v1/admin/coupons/bulk-generate
+func (h *Handler) BulkGenerateCoupons(c *fiber.Ctx) error {
+	var req BulkGenerateCouponsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Unauthorized(c, "User not authenticated")
+	}
+	var createdByUserID *uuid.UUID
+	if userID != uuid.Nil {
+		createdByUserID = &userID
+	}
+
+	result, err := h.service.BulkGenerateCoupons(req, createdByUserID)
+	if err != nil {
+		msg := err.Error()
+		if strings.Contains(msg, "cannot exceed") || strings.Contains(msg, "date must be") {
+			return presenter.BadRequest(c, msg)
+		}
+		return presenter.InternalServerError(c, "Failed to generate coupons")
+	}
+
+	if strings.EqualFold(c.Query("format"), "csv") {
+		return writeCouponCodesCSV(c, result)
+	}
+
+	return presenter.Success(c, fmt.Sprintf("%d coupons generated successfully", result.Count), result)
+}
+
+func writeCouponCodesCSV(c *fiber.Ctx, result *BulkGenerateCouponsResponse) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"code", "type", "value", "starts_at", "expiry_date", "minimum_order_amount_kobo"})
+	startsAt, expiryDate := "", ""
+	if result.StartsAt != nil {
+		startsAt = result.StartsAt.Format(time.RFC3339)
+	}
+	if result.ExpiryDate != nil {
+		expiryDate = result.ExpiryDate.Format(time.RFC3339)
+	}
+	for _, code := range result.Codes {
+		_ = w.Write([]string{
+			code,
+			string(result.Type),
+			strconv.FormatFloat(result.Value, 'f', 2, 64),
+			startsAt,
+			expiryDate,
+			strconv.FormatInt(result.MinimumOrderAmount, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return presenter.InternalServerError(c, "Failed to write coupon CSV")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="coupons-%s.csv"`, time.Now().Format("20060102-150405")))
+	return c.Send(buf.Bytes())
+}
+
 // GetCoupon gets a single coupon by ID
 // GET /api/v1/admin/coupons/:id
 func (h *Handler) GetCoupon(c *fiber.Ctx) error {
@@ -100,6 +176,9 @@ func (h *Handler) UpdateCoupon(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "not found") {
 			return presenter.NotFound(c, "Coupon not found")
 		}
+		if strings.Contains(err.Error(), "start date") {
+			return presenter.BadRequest(c, err.Error())
+		}
 		return presenter.InternalServerError(c, "Failed to update coupon")
 	}
 
@@ -132,34 +211,34 @@ func (h *Handler) ListCoupons(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
-	filters := make(map[string]interface{})
+	var filter CouponFilter
 
 	// Apply filters
 	if isActive := c.Query("is_active"); isActive != "" {
 		if active, err := strconv.ParseBool(isActive); err == nil {
-			filters["is_active"] = active
+			filter.IsActive = &active
 		}
 	}
 
 	if couponType := c.Query("type"); couponType != "" {
-		filters["type"] = couponType
+		filter.Type = CouponType(couponType)
 	}
 
 	if createdBy := c.Query("created_by"); createdBy != "" {
-		filters["created_by"] = createdBy
+		filter.CreatedBy = createdBy
 	}
 
 	if search := c.Query("search"); search != "" {
-		filters["search"] = search
+		filter.Search = search
 	}
 
 	if linkedUserID := c.Query("linked_user_id"); linkedUserID != "" {
 		if userID, err := uuid.Parse(linkedUserID); err == nil {
-			filters["linked_user_id"] = userID
+			filter.LinkedUserID = &userID
 		}
 	}
 
-	coupons, err := h.service.ListCoupons(page, limit, filters)
+	coupons, err := h.service.ListCoupons(page, limit, filter)
 	if err != nil {
 		return presenter.InternalServerError(c, "Failed to list coupons")
 	}
@@ -391,7 +470,7 @@ func (h *Handler) GenerateRefundCoupon(c *fiber.Ctx) error {
 	type GenerateRefundCouponRequest struct {
 		OrderID      uuid.UUID `json:"orderId" validate:"required"`
 		UserID       uuid.UUID `json:"userId" validate:"required"`
-		RefundAmount float64   `json:"refundAmount" validate:"required,gt=0"`
+		RefundAmount int64     `json:"refundAmount" validate:"required,gt=0"` // in kobo
 	}
 
 	var req GenerateRefundCouponRequest
@@ -514,7 +593,7 @@ func (h *Handler) GetPublicCoupons(c *fiber.Ctx) error {
 func (h *Handler) ValidatePublicCoupon(c *fiber.Ctx) error {
 	var req struct {
 		Code        string  `json:"code" validate:"required"`
-		OrderAmount float64 `json:"order_amount" validate:"required,min=0"`
+		OrderAmount int64  `json:"order_amount" validate:"required,gt=0"` // in kobo
 	}
 
 	if err := c.BodyParser(&req); err != nil {
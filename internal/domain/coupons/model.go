@@ -10,28 +10,44 @@ type Coupon struct {
 	ID                   uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Code                 string         `gorm:"uniqueIndex;size:50;not null" json:"code"`
 	Type                 CouponType     `gorm:"type:varchar(20);not null" json:"type"`
-	Value                float64        `gorm:"type:decimal(10,2);not null" json:"value"`
+	Value                float64        `gorm:"type:decimal(14,2);not null" json:"value"` // percent for percentage coupons, kobo for fixed
 	Description          string         `gorm:"type:text" json:"description"`
 	MaxUsage             *int           `json:"maxUsage"`
 	UsageCount           int            `gorm:"default:0" json:"usageCount"`
+	StartsAt             *time.Time     `json:"startsAt"` // nil means usable immediately
 	ExpiryDate           *time.Time     `json:"expiryDate"`
 	IsActive             bool           `gorm:"default:true" json:"isActive"`
 	CreatedBy            string         `gorm:"size:20;not null" json:"createdBy"` // 'owner', 'system'
 	CreatedByUserID      *uuid.UUID     `gorm:"type:uuid" json:"createdByUserId"`
 	LinkedOrderID        *uuid.UUID     `gorm:"type:uuid" json:"linkedOrderId"`
 	LinkedUserID         *uuid.UUID     `gorm:"type:uuid" json:"linkedUserId"`
-	MinimumOrderAmount   float64        `gorm:"type:decimal(10,2);default:0" json:"minimumOrderAmount"`
+	MinimumOrderAmount   int64          `gorm:"default:0" json:"minimumOrderAmount"` // in kobo
+	ApplicableZoneIDs    []string       `gorm:"type:text;serializer:json" json:"applicableZoneIds"` // delivery zones the coupon is limited to; empty means every zone
 	CreatedAt            time.Time      `json:"createdAt"`
 	UpdatedAt            time.Time      `json:"updatedAt"`
 	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// AppliesToZone reports whether the coupon can be used for a delivery to zoneID. A coupon with no
+// zones applies everywhere; a zone-restricted coupon never applies when the zone is unknown.
+func (c *Coupon) AppliesToZone(zoneID string) bool {
+	if len(c.ApplicableZoneIDs) == 0 {
+		return true
+	}
+	for _, id := range c.ApplicableZoneIDs {
+		if id == zoneID {
+			return true
+		}
+	}
+	return false
+}
+
 type CouponUsage struct {
 	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	CouponID       uuid.UUID `gorm:"type:uuid;not null" json:"couponId"`
 	UserID         uuid.UUID `gorm:"type:uuid;not null" json:"userId"`
 	OrderID        uuid.UUID `gorm:"type:uuid;not null" json:"orderId"`
-	DiscountAmount float64   `gorm:"type:decimal(10,2)" json:"discountAmount"`
+	DiscountAmount int64     `json:"discountAmount"` // in kobo
 	UsedAt         time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"usedAt"`
 	Coupon         Coupon    `gorm:"foreignKey:CouponID" json:"coupon,omitempty"`
 }
@@ -40,18 +56,21 @@ type UserRefundCredit struct {
 	ID                  uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID              uuid.UUID  `gorm:"type:uuid;not null" json:"userId"`
 	OriginalOrderID     uuid.UUID  `gorm:"type:uuid;not null" json:"originalOrderId"`
-	RefundAmount        float64    `gorm:"type:decimal(10,2)" json:"refundAmount"`
+	RefundAmount        int64      `json:"refundAmount"` // in kobo
 	ConvertedToCoupon   bool       `gorm:"default:false" json:"convertedToCoupon"`
 	CouponID            *uuid.UUID `gorm:"type:uuid" json:"couponId"`
 	CreatedAt           time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
 	Coupon              *Coupon    `gorm:"foreignKey:CouponID" json:"coupon,omitempty"`
 }
 
+// Coupon money is in kobo throughout, like order amounts: a fixed coupon's Value, minimum
+// order amounts, recorded discounts and refund credits. Percentage coupons keep Value in percent.
 type CouponType string
 
 const (
-	CouponPercentage CouponType = "percentage"
-	CouponFixed      CouponType = "fixed"
+	CouponPercentage   CouponType = "percentage"
+	CouponFixed        CouponType = "fixed"
+	CouponFreeDelivery CouponType = "free_delivery" // takes the delivery fee off; Value is unused
 )
 
 type CreatedBy string
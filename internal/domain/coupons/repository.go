@@ -2,6 +2,7 @@ package coupons
 
 import (
 	"fmt"
+	"strings"
 	"time"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -10,11 +11,12 @@ import (
 type Repository interface {
 	// Coupon CRUD
 	Create(coupon *Coupon) error
+	CreateBulk(coupons []Coupon, newCode func() string) error
 	GetByID(id uuid.UUID) (*Coupon, error)
 	GetByCode(code string) (*Coupon, error)
 	Update(coupon *Coupon) error
 	Delete(id uuid.UUID) error
-	List(page, limit int, filters map[string]interface{}) ([]Coupon, int64, error)
+	List(page, limit int, filter CouponFilter) ([]Coupon, int64, error)
 	ToggleActive(id uuid.UUID) error
 	
 	// Coupon Usage
@@ -22,6 +24,7 @@ type Repository interface {
 	GetUsageByUserAndCoupon(userID, couponID uuid.UUID) (*CouponUsage, error)
 	GetUsageCountByCoupon(couponID uuid.UUID) (int64, error)
 	GetUsageCountByUserAndCoupon(userID, couponID uuid.UUID) (int64, error)
+	UpdateUsageDiscount(couponID, orderID uuid.UUID, discountAmount int64) error
 	
 	// Refund Credits
 	CreateRefundCredit(credit *UserRefundCredit) error
@@ -50,6 +53,37 @@ func (r *repository) Create(coupon *Coupon) error {
 	return r.db.Create(coupon).Error
 }
 
+// maxBulkCodeAttempts bounds how often one bulk coupon is re-coded after a collision
+const maxBulkCodeAttempts = 5
+
+// CreateBulk inserts all coupons in one transaction. A code that collides with an
+// existing one (soft-deleted coupons still hold the unique index) is replaced with
+// newCode() and retried; the batch fails only if a coupon keeps colliding.
+func (r *repository) CreateBulk(coupons []Coupon, newCode func() string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range coupons {
+			savepoint := fmt.Sprintf("bulk_coupon_%d", i)
+			for attempt := 1; ; attempt++ {
+				if err := tx.SavePoint(savepoint).Error; err != nil {
+					return err
+				}
+				err := tx.Create(&coupons[i]).Error
+				if err == nil {
+					break
+				}
+				if !strings.Contains(err.Error(), "duplicate key") || attempt >= maxBulkCodeAttempts {
+					return err
+				}
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+				coupons[i].Code = newCode()
+			}
+		}
+		return nil
+	})
+}
+
 func (r *repository) GetByID(id uuid.UUID) (*Coupon, error) {
 	var coupon Coupon
 	err := r.db.Where("id = ? AND deleted_at IS NULL", id).First(&coupon).Error
@@ -76,27 +110,38 @@ func (r *repository) Delete(id uuid.UUID) error {
 	return r.db.Where("id = ?", id).Delete(&Coupon{}).Error
 }
 
-func (r *repository) List(page, limit int, filters map[string]interface{}) ([]Coupon, int64, error) {
+func (r *repository) List(page, limit int, filter CouponFilter) ([]Coupon, int64, error) {
 	var coupons []Coupon
 	var total int64
 	
 	query := r.db.Model(&Coupon{}).Where("deleted_at IS NULL")
 	
 	// Apply filters
-	for key, value := range filters {
-		switch key {
-		case "is_active":
-			query = query.Where("is_active = ?", value)
-		case "type":
-			query = query.Where("type = ?", value)
-		case "created_by":
-			query = query.Where("created_by = ?", value)
-		case "linked_user_id":
-			query = query.Where("linked_user_id = ?", value)
-		case "search":
-			query = query.Where("code ILIKE ? OR description ILIKE ?", 
-				fmt.Sprintf("%%%s%%", value), fmt.Sprintf("%%%s%%", value))
-		}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.CreatedBy != "" {
+		query = query.Where("created_by = ?", filter.CreatedBy)
+	}
+	if filter.LinkedUserID != nil {
+		query = query.Where("linked_user_id = ?", *filter.LinkedUserID)
+	}
+	if filter.Search != "" {
+		pattern := fmt.Sprintf("%%%s%%", filter.Search)
+		query = query.Where("code ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.AvailableFor != nil {
+		query = query.
+			Where("starts_at IS NULL OR starts_at <= ?", time.Now()).
+			Where("expiry_date IS NULL OR expiry_date >= ?", time.Now()).
+			Where("max_usage IS NULL OR usage_count < max_usage").
+			Where("linked_user_id IS NULL OR linked_user_id = ?", *filter.AvailableFor)
 	}
 	
 	// Count total
@@ -124,6 +169,12 @@ func (r *repository) CreateUsage(usage *CouponUsage) error {
 	return r.db.Create(usage).Error
 }
 
+func (r *repository) UpdateUsageDiscount(couponID, orderID uuid.UUID, discountAmount int64) error {
+	return r.db.Model(&CouponUsage{}).
+		Where("coupon_id = ? AND order_id = ?", couponID, orderID).
+		Update("discount_amount", discountAmount).Error
+}
+
 func (r *repository) GetUsageByUserAndCoupon(userID, couponID uuid.UUID) (*CouponUsage, error) {
 	var usage CouponUsage
 	err := r.db.Where("user_id = ? AND coupon_id = ?", userID, couponID).First(&usage).Error
@@ -216,7 +267,7 @@ func (r *repository) GetCouponStats() (*CouponStatsResponse, error) {
 	// Total usage and discount
 	var result struct {
 		TotalUsage    int64   `json:"total_usage"`
-		TotalDiscount float64 `json:"total_discount"`
+		TotalDiscount int64   `json:"total_discount"`
 	}
 	
 	err = r.db.Model(&CouponUsage{}).
@@ -245,7 +296,7 @@ func (r *repository) GetTopPerformingCoupons(limit int) ([]CouponPerformance, er
 	type result struct {
 		CouponID      uuid.UUID `json:"coupon_id"`
 		UsageCount    int64     `json:"usage_count"`
-		TotalDiscount float64   `json:"total_discount"`
+		TotalDiscount int64     `json:"total_discount"`
 	}
 	
 	var results []result
@@ -279,7 +330,8 @@ func (r *repository) GetTopPerformingCoupons(limit int) ([]CouponPerformance, er
 // GetPublicCoupons gets publicly available coupons
 func (r *repository) GetPublicCoupons() ([]Coupon, error) {
 	var coupons []Coupon
-	err := r.db.Where("is_active = ? AND expiry_date > ?", true, time.Now()).Find(&coupons).Error
+	err := r.db.Where("is_active = ? AND expiry_date > ?", true, time.Now()).
+		Where("starts_at IS NULL OR starts_at <= ?", time.Now()).Find(&coupons).Error
 	return coupons, err
 }
 
@@ -293,6 +345,7 @@ func (r *repository) toCouponResponse(coupon *Coupon) *CouponResponse {
 		Description:        coupon.Description,
 		MaxUsage:           coupon.MaxUsage,
 		UsageCount:         coupon.UsageCount,
+		StartsAt:           coupon.StartsAt,
 		ExpiryDate:         coupon.ExpiryDate,
 		IsActive:           coupon.IsActive,
 		CreatedBy:          coupon.CreatedBy,
@@ -1,6 +1,8 @@
 package coupons
 
 import (
+	"time"
+
 	"errandShop/config"
 	"errandShop/internal/middleware"
 
@@ -22,6 +24,7 @@ func SetupRoutes(app *fiber.App, handler *Handler, cfg *config.Config) {
 	couponAdmin.Get("/", handler.ListCoupons)              // GET /api/v1/admin/coupons
 	couponAdmin.Post("/", handler.CreateCoupon)             // POST /api/v1/admin/coupons
 	couponAdmin.Get("/stats", handler.GetCouponStats)       // GET /api/v1/admin/coupons/stats
+	couponAdmin.Post("/bulk-generate", handler.BulkGenerateCoupons) // POST /api/v1/admin/coupons/bulk-generate
 	couponAdmin.Get("/:id", handler.GetCoupon)              // GET /api/v1/admin/coupons/:id
 	couponAdmin.Put("/:id", handler.UpdateCoupon)           // PUT /api/v1/admin/coupons/:id
 	couponAdmin.Delete("/:id", handler.DeleteCoupon)        // DELETE /api/v1/admin/coupons/:id
@@ -36,12 +39,12 @@ func SetupRoutes(app *fiber.App, handler *Handler, cfg *config.Config) {
 	couponUser.Get("/available", handler.GetAvailableCoupons) // GET /api/v1/user/coupons/available
 	couponUser.Post("/validate", handler.ValidateCoupon)      // POST /api/v1/user/coupons/validate
 	couponUser.Post("/apply", handler.ApplyCoupon)           // POST /api/v1/user/coupons/apply
-	couponUser.Post("/generate", handler.MobileAutoGenerateCoupon) // POST /api/v1/user/coupons/generate
+	couponUser.Post("/generate", middleware.UserRateLimit(cfg.CouponGenerateRateLimit, time.Minute), handler.MobileAutoGenerateCoupon) // POST /api/v1/user/coupons/generate
 	
 	// User Refund Credits
 	refundUser := userRoutes.Group("/refund-credits")
 	refundUser.Get("/", handler.GetUserRefundCredits)        // GET /api/v1/user/refund-credits
-	refundUser.Post("/convert", handler.ConvertRefundToCredit) // POST /api/v1/user/refund-credits/convert
+	refundUser.Post("/convert", middleware.NoImpersonation(), handler.ConvertRefundToCredit) // POST /api/v1/user/refund-credits/convert
 	
 	// System routes (admin only - for internal operations)
 	systemRoutes := api.Group("/system")
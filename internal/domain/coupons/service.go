@@ -1,11 +1,14 @@
 package coupons
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"math"
 	"strings"
 	"time"
+
+	"errandShop/pkg/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -16,21 +19,25 @@ type Service interface {
 	GetCoupon(id uuid.UUID) (*CouponResponse, error)
 	UpdateCoupon(id uuid.UUID, req UpdateCouponRequest) (*CouponResponse, error)
 	DeleteCoupon(id uuid.UUID) error
-	ListCoupons(page, limit int, filters map[string]interface{}) (*CouponListResponse, error)
+	ListCoupons(page, limit int, filter CouponFilter) (*CouponListResponse, error)
 	ToggleCouponActive(id uuid.UUID) (*CouponResponse, error)
+	BulkGenerateCoupons(req BulkGenerateCouponsRequest, createdByUserID *uuid.UUID) (*BulkGenerateCouponsResponse, error)
 	
 	// User Coupon Operations
 	GetAvailableCoupons(userID uuid.UUID, page, limit int) (*CouponListResponse, error)
 	ValidateCoupon(req ValidateCouponRequest) (*CouponValidationResponse, error)
+	BestCouponForOrder(userID uuid.UUID, orderAmount, deliveryFee int64, zoneID string) (*CouponValidationResponse, error)
 	ApplyCoupon(req ApplyCouponRequest) (*CouponValidationResponse, error)
+	RepriceOrderCoupon(code string, orderID uuid.UUID, orderAmount, deliveryFee int64) (*CouponValidationResponse, error)
 	
 	// Refund Credits
 	GetUserRefundCredits(userID uuid.UUID, page, limit int) (*RefundCreditListResponse, error)
 	CreateRefundCredit(req CreateRefundCreditRequest) (*UserRefundCreditResponse, error)
 	ConvertRefundToCredit(req ConvertRefundRequest) (*CouponResponse, error)
+	IssueStoreCredit(orderID, userID uuid.UUID, amountKobo int64, reason string) (*CouponResponse, error)
 	
 	// System Operations
-	GenerateRefundCoupon(orderID, userID uuid.UUID, refundAmount float64) (*CouponResponse, error)
+	GenerateRefundCoupon(orderID, userID uuid.UUID, refundAmount int64) (*CouponResponse, error)
 	AutoGenerateUserCoupon(userID uuid.UUID, couponType CouponType, value float64, description string) (*CouponResponse, error)
 	
 	// Mobile App Operations
@@ -66,6 +73,13 @@ func (s *service) CreateCoupon(req CreateCouponRequest, createdByUserID *uuid.UU
 	if req.Type == CouponPercentage && req.Value > 100 {
 		return nil, errors.New("percentage discount cannot exceed 100%")
 	}
+	if req.Type == CouponFreeDelivery {
+		req.Value = 0
+	}
+	
+	if req.StartsAt != nil && req.ExpiryDate != nil && !req.StartsAt.Before(*req.ExpiryDate) {
+		return nil, errors.New("start date must be before expiry date")
+	}
 	
 	// Set default values
 	isActive := true
@@ -81,12 +95,14 @@ func (s *service) CreateCoupon(req CreateCouponRequest, createdByUserID *uuid.UU
 		Description:        req.Description,
 		MaxUsage:           req.MaxUsage,
 		UsageCount:         0,
+		StartsAt:           req.StartsAt,
 		ExpiryDate:         req.ExpiryDate,
 		IsActive:           isActive,
 		CreatedBy:          string(CreatedByOwner),
 		CreatedByUserID:    createdByUserID,
 		LinkedUserID:       req.LinkedUserID,
 		MinimumOrderAmount: req.MinimumOrderAmount,
+		ApplicableZoneIDs:  normalizeZoneIDs(req.ApplicableZoneIDs),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -99,6 +115,86 @@ func (s *service) CreateCoupon(req CreateCouponRequest, createdByUserID *uuid.UU
 	return s.toCouponResponse(coupon), nil
 }
 
+// BulkGenerateCoupons creates req.Count single-use coupons sharing the same terms,
+// each with a random code under req.Prefix.
+func (s *service) BulkGenerateCoupons(req BulkGenerateCouponsRequest, createdByUserID *uuid.UUID) (*BulkGenerateCouponsResponse, error) {
+	if req.Type == CouponPercentage && req.Value > 100 {
+		return nil, errors.New("percentage discount cannot exceed 100%")
+	}
+	if req.Type == CouponFreeDelivery {
+		req.Value = 0
+	}
+	if req.ExpiryDate != nil && !req.ExpiryDate.After(time.Now()) {
+		return nil, errors.New("expiry date must be in the future")
+	}
+	if req.StartsAt != nil && req.ExpiryDate != nil && !req.StartsAt.Before(*req.ExpiryDate) {
+		return nil, errors.New("start date must be before expiry date")
+	}
+
+	prefix := strings.ToUpper(req.Prefix)
+	zoneIDs := normalizeZoneIDs(req.ApplicableZoneIDs)
+	newCode := func() string {
+		return prefix + "-" + randomCouponSuffix()
+	}
+
+	now := time.Now()
+	coupons := make([]Coupon, req.Count)
+	for i := range coupons {
+		coupons[i] = Coupon{
+			ID:                 uuid.New(),
+			Code:               newCode(),
+			Type:               req.Type,
+			Value:              req.Value,
+			Description:        req.Description,
+			MaxUsage:           &[]int{1}[0], // One-time use
+			UsageCount:         0,
+			StartsAt:           req.StartsAt,
+			ExpiryDate:         req.ExpiryDate,
+			IsActive:           true,
+			CreatedBy:          string(CreatedByOwner),
+			CreatedByUserID:    createdByUserID,
+			MinimumOrderAmount: req.MinimumOrderAmount,
+			ApplicableZoneIDs:  zoneIDs,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+	}
+
+	if err := s.repo.CreateBulk(coupons, newCode); err != nil {
+		return nil, fmt.Errorf("error creating coupons: %w", err)
+	}
+
+	codes := make([]string, len(coupons))
+	for i, coupon := range coupons {
+		codes[i] = coupon.Code
+	}
+	return &BulkGenerateCouponsResponse{
+		Count:              len(codes),
+		Type:               req.Type,
+		Value:              req.Value,
+		StartsAt:           req.StartsAt,
+		ExpiryDate:         req.ExpiryDate,
+		MinimumOrderAmount: req.MinimumOrderAmount,
+		Codes:              codes,
+	}, nil
+}
+
+// couponCodeAlphabet leaves out 0/O and 1/I so printed codes can be typed back reliably
+const couponCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// randomCouponSuffix returns 8 random characters from couponCodeAlphabet
+func randomCouponSuffix() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to a UUID fragment
+		return strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:8])
+	}
+	for i := range b {
+		b[i] = couponCodeAlphabet[int(b[i])%len(couponCodeAlphabet)]
+	}
+	return string(b)
+}
+
 func (s *service) GetCoupon(id uuid.UUID) (*CouponResponse, error) {
 	coupon, err := s.repo.GetByID(id)
 	if err != nil {
@@ -127,6 +223,9 @@ func (s *service) UpdateCoupon(id uuid.UUID, req UpdateCouponRequest) (*CouponRe
 	if req.MaxUsage != nil {
 		coupon.MaxUsage = req.MaxUsage
 	}
+	if req.StartsAt != nil {
+		coupon.StartsAt = req.StartsAt
+	}
 	if req.ExpiryDate != nil {
 		coupon.ExpiryDate = req.ExpiryDate
 	}
@@ -136,6 +235,12 @@ func (s *service) UpdateCoupon(id uuid.UUID, req UpdateCouponRequest) (*CouponRe
 	if req.MinimumOrderAmount != nil {
 		coupon.MinimumOrderAmount = *req.MinimumOrderAmount
 	}
+	if req.ApplicableZoneIDs != nil {
+		coupon.ApplicableZoneIDs = normalizeZoneIDs(*req.ApplicableZoneIDs)
+	}
+	if coupon.StartsAt != nil && coupon.ExpiryDate != nil && !coupon.StartsAt.Before(*coupon.ExpiryDate) {
+		return nil, errors.New("start date must be before expiry date")
+	}
 	
 	coupon.UpdatedAt = time.Now()
 	
@@ -159,7 +264,7 @@ func (s *service) DeleteCoupon(id uuid.UUID) error {
 	return s.repo.Delete(id)
 }
 
-func (s *service) ListCoupons(page, limit int, filters map[string]interface{}) (*CouponListResponse, error) {
+func (s *service) ListCoupons(page, limit int, filter CouponFilter) (*CouponListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -167,7 +272,7 @@ func (s *service) ListCoupons(page, limit int, filters map[string]interface{}) (
 		limit = 20
 	}
 	
-	coupons, total, err := s.repo.List(page, limit, filters)
+	coupons, total, err := s.repo.List(page, limit, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error listing coupons: %w", err)
 	}
@@ -196,42 +301,33 @@ func (s *service) ToggleCouponActive(id uuid.UUID) (*CouponResponse, error) {
 
 // User Coupon Operations
 func (s *service) GetAvailableCoupons(userID uuid.UUID, page, limit int) (*CouponListResponse, error) {
-	filters := map[string]interface{}{
-		"is_active": true,
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
 	}
 	
-	// Include general coupons and user-specific coupons
-	coupons, _, err := s.repo.List(page, limit, filters)
+	// Active, unexpired, under usage limit, and either general or linked to this user
+	isActive := true
+	filter := CouponFilter{
+		IsActive:     &isActive,
+		AvailableFor: &userID,
+	}
+	
+	coupons, total, err := s.repo.List(page, limit, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error getting available coupons: %w", err)
 	}
 	
-	// Filter out expired coupons and user-specific coupons for other users
-	var availableCoupons []CouponResponse
-	now := time.Now()
-	
-	for _, coupon := range coupons {
-		// Skip expired coupons
-		if coupon.ExpiryDate != nil && coupon.ExpiryDate.Before(now) {
-			continue
-		}
-		
-		// Skip user-specific coupons for other users
-		if coupon.LinkedUserID != nil && *coupon.LinkedUserID != userID {
-			continue
-		}
-		
-		// Skip coupons that have reached usage limit
-		if coupon.MaxUsage != nil && coupon.UsageCount >= *coupon.MaxUsage {
-			continue
-		}
-		
-		availableCoupons = append(availableCoupons, *s.toCouponResponse(&coupon))
+	availableCoupons := make([]CouponResponse, len(coupons))
+	for i, coupon := range coupons {
+		availableCoupons[i] = *s.toCouponResponse(&coupon)
 	}
 	
 	return &CouponListResponse{
 		Coupons: availableCoupons,
-		Total:   int64(len(availableCoupons)),
+		Total:   total,
 		Page:    page,
 		Limit:   limit,
 	}, nil
@@ -249,12 +345,45 @@ func (s *service) ValidateCoupon(req ValidateCouponRequest) (*CouponValidationRe
 		return nil, fmt.Errorf("error getting coupon: %w", err)
 	}
 	
-	validation := s.validateCouponForUser(coupon, req.UserID, req.OrderAmount)
+	validation := s.validateCouponForUser(coupon, req.UserID, req.OrderAmount, req.DeliveryFee, req.ZoneID)
 	validation.Coupon = s.toCouponResponse(coupon)
 	
 	return validation, nil
 }
 
+// BestCouponForOrder evaluates every coupon available to the user against the order amount,
+// delivery fee and zone and returns the valid one with the largest discount, or nil when none applies
+func (s *service) BestCouponForOrder(userID uuid.UUID, orderAmount, deliveryFee int64, zoneID string) (*CouponValidationResponse, error) {
+	isActive := true
+	filter := CouponFilter{
+		IsActive:     &isActive,
+		AvailableFor: &userID,
+	}
+
+	var best *CouponValidationResponse
+	const pageSize = 100
+	for page := 1; ; page++ {
+		coupons, total, err := s.repo.List(page, pageSize, filter)
+		if err != nil {
+			return nil, fmt.Errorf("error getting available coupons: %w", err)
+		}
+		for i := range coupons {
+			validation := s.validateCouponForUser(&coupons[i], userID, orderAmount, deliveryFee, zoneID)
+			if !validation.Valid || validation.DiscountAmount <= 0 {
+				continue
+			}
+			if best == nil || validation.DiscountAmount > best.DiscountAmount {
+				validation.Coupon = s.toCouponResponse(&coupons[i])
+				best = validation
+			}
+		}
+		if len(coupons) < pageSize || int64(page*pageSize) >= total {
+			break
+		}
+	}
+	return best, nil
+}
+
 func (s *service) ApplyCoupon(req ApplyCouponRequest) (*CouponValidationResponse, error) {
 	coupon, err := s.repo.GetByCode(req.Code)
 	if err != nil {
@@ -267,7 +396,7 @@ func (s *service) ApplyCoupon(req ApplyCouponRequest) (*CouponValidationResponse
 		return nil, fmt.Errorf("error getting coupon: %w", err)
 	}
 	
-	validation := s.validateCouponForUser(coupon, req.UserID, req.OrderAmount)
+	validation := s.validateCouponForUser(coupon, req.UserID, req.OrderAmount, req.DeliveryFee, req.ZoneID)
 	if !validation.Valid {
 		return validation, nil
 	}
@@ -298,6 +427,43 @@ func (s *service) ApplyCoupon(req ApplyCouponRequest) (*CouponValidationResponse
 	return validation, nil
 }
 
+// RepriceOrderCoupon recalculates the discount of a coupon already applied to an order whose
+// amount changed. Usage limits were settled when the coupon was applied, so only the minimum
+// order amount is re-checked; the recorded usage is updated with the new discount.
+func (s *service) RepriceOrderCoupon(code string, orderID uuid.UUID, orderAmount, deliveryFee int64) (*CouponValidationResponse, error) {
+	coupon, err := s.repo.GetByCode(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CouponValidationResponse{
+				Valid:   false,
+				Message: "Coupon code not found",
+			}, nil
+		}
+		return nil, fmt.Errorf("error getting coupon: %w", err)
+	}
+
+	if orderAmount < coupon.MinimumOrderAmount {
+		return &CouponValidationResponse{
+			Valid:   false,
+			Message: minimumOrderMessage(coupon),
+		}, nil
+	}
+
+	discountAmount := s.calculateDiscount(coupon, orderAmount, deliveryFee)
+	if err := s.repo.UpdateUsageDiscount(coupon.ID, orderID, discountAmount); err != nil {
+		return nil, fmt.Errorf("error updating coupon usage: %w", err)
+	}
+
+	return &CouponValidationResponse{
+		Valid:               true,
+		DiscountAmount:      discountAmount,
+		DiscountAmountNaira: money.Money(discountAmount).Naira(),
+		AppliesToDelivery:   coupon.Type == CouponFreeDelivery,
+		Message:             "Coupon is valid",
+		Coupon:              s.toCouponResponse(coupon),
+	}, nil
+}
+
 // Refund Credits
 func (s *service) GetUserRefundCredits(userID uuid.UUID, page, limit int) (*RefundCreditListResponse, error) {
 	if page < 1 {
@@ -368,7 +534,7 @@ func (s *service) ConvertRefundToCredit(req ConvertRefundRequest) (*CouponRespon
 		ID:                 uuid.New(),
 		Code:               strings.ToUpper(couponCode),
 		Type:               CouponFixed,
-		Value:              credit.RefundAmount,
+		Value:              float64(credit.RefundAmount),
 		Description:        fmt.Sprintf("Refund credit from order %s", credit.OriginalOrderID.String()[:8]),
 		MaxUsage:           &[]int{1}[0], // One-time use
 		UsageCount:         0,
@@ -397,15 +563,61 @@ func (s *service) ConvertRefundToCredit(req ConvertRefundRequest) (*CouponRespon
 	return s.toCouponResponse(coupon), nil
 }
 
+// IssueStoreCredit refunds an order as store credit: a one-time fixed coupon for amountKobo,
+// usable only by the customer, recorded as an already-converted refund credit
+func (s *service) IssueStoreCredit(orderID, userID uuid.UUID, amountKobo int64, reason string) (*CouponResponse, error) {
+	if amountKobo <= 0 {
+		return nil, errors.New("store credit amount must be positive")
+	}
+
+	description := fmt.Sprintf("Store credit for order %s", orderID.String()[:8])
+	if reason != "" {
+		description += ": " + reason
+	}
+	coupon := &Coupon{
+		ID:                 uuid.New(),
+		Code:               "CREDIT-" + randomCouponSuffix(),
+		Type:               CouponFixed,
+		Value:              float64(amountKobo),
+		Description:        description,
+		MaxUsage:           &[]int{1}[0], // One-time use
+		IsActive:           true,
+		CreatedBy:          string(CreatedBySystem),
+		LinkedOrderID:      &orderID,
+		LinkedUserID:       &userID,
+		MinimumOrderAmount: 0,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	if err := s.repo.Create(coupon); err != nil {
+		return nil, fmt.Errorf("error creating store credit coupon: %w", err)
+	}
+
+	credit := &UserRefundCredit{
+		ID:                uuid.New(),
+		UserID:            userID,
+		OriginalOrderID:   orderID,
+		RefundAmount:      amountKobo,
+		ConvertedToCoupon: true,
+		CouponID:          &coupon.ID,
+		CreatedAt:         time.Now(),
+	}
+	if err := s.repo.CreateRefundCredit(credit); err != nil {
+		return nil, fmt.Errorf("error recording refund credit: %w", err)
+	}
+
+	return s.toCouponResponse(coupon), nil
+}
+
 // System Operations
-func (s *service) GenerateRefundCoupon(orderID, userID uuid.UUID, refundAmount float64) (*CouponResponse, error) {
+func (s *service) GenerateRefundCoupon(orderID, userID uuid.UUID, refundAmount int64) (*CouponResponse, error) {
 	couponCode := fmt.Sprintf("SORRY-%s", orderID.String()[:8])
 	
 	coupon := &Coupon{
 		ID:                 uuid.New(),
 		Code:               couponCode,
 		Type:               CouponFixed,
-		Value:              refundAmount,
+		Value:              float64(refundAmount),
 		Description:        fmt.Sprintf("Apology coupon for order %s", orderID.String()[:8]),
 		MaxUsage:           &[]int{1}[0], // One-time use
 		UsageCount:         0,
@@ -457,18 +669,18 @@ func (s *service) AutoGenerateUserCoupon(userID uuid.UUID, couponType CouponType
 func (s *service) MobileAutoGenerateCoupon(userID uuid.UUID, req MobileAutoGenerateCouponRequest) (*CouponResponse, error) {
 	// Check if user has reached daily limit (max 3 coupons per day)
 	startOfDay := time.Now().Truncate(24 * time.Hour)
-	filters := map[string]interface{}{
-		"linked_user_id": userID,
-		"created_by":     string(CreatedBySystem),
-		"created_at >=":  startOfDay,
+	filter := CouponFilter{
+		LinkedUserID: &userID,
+		CreatedBy:    string(CreatedBySystem),
+		CreatedAfter: &startOfDay,
 	}
 	
-	coupons, _, err := s.repo.List(1, 10, filters)
+	_, generatedToday, err := s.repo.List(1, 1, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error checking daily coupon limit: %w", err)
 	}
 	
-	if len(coupons) >= 3 {
+	if generatedToday >= 3 {
 		return nil, errors.New("daily coupon generation limit reached (3 per day)")
 	}
 	
@@ -492,8 +704,8 @@ func (s *service) MobileAutoGenerateCoupon(userID uuid.UUID, req MobileAutoGener
 	}
 	
 	// Additional validation for fixed coupons
-	if req.Type == CouponFixed && req.Value > 50 {
-		return nil, errors.New("mobile-generated fixed coupons cannot exceed $50")
+	if req.Type == CouponFixed && req.Value > MaxMobileFixedCouponKobo {
+		return nil, fmt.Errorf("mobile-generated fixed coupons cannot exceed %s", money.Money(MaxMobileFixedCouponKobo))
 	}
 	
 	coupon := &Coupon{
@@ -542,7 +754,10 @@ func (s *service) GetPublicCoupons() ([]CouponResponse, error) {
 }
 
 // Helper methods
-func (s *service) validateCouponForUser(coupon *Coupon, userID uuid.UUID, orderAmount float64) *CouponValidationResponse {
+// validateCouponForUser checks whether the coupon can be used on an order of orderAmount kobo
+// delivered to zoneID and, if so, returns the discount in kobo. deliveryFee is only used to
+// price free delivery coupons.
+func (s *service) validateCouponForUser(coupon *Coupon, userID uuid.UUID, orderAmount, deliveryFee int64, zoneID string) *CouponValidationResponse {
 	now := time.Now()
 	
 	// Check if coupon is active
@@ -553,6 +768,14 @@ func (s *service) validateCouponForUser(coupon *Coupon, userID uuid.UUID, orderA
 		}
 	}
 	
+	// Check start date
+	if coupon.StartsAt != nil && coupon.StartsAt.After(now) {
+		return &CouponValidationResponse{
+			Valid:   false,
+			Message: "Coupon is not yet active",
+		}
+	}
+	
 	// Check expiry date
 	if coupon.ExpiryDate != nil && coupon.ExpiryDate.Before(now) {
 		return &CouponValidationResponse{
@@ -577,11 +800,19 @@ func (s *service) validateCouponForUser(coupon *Coupon, userID uuid.UUID, orderA
 		}
 	}
 	
+	// Check delivery zone
+	if !coupon.AppliesToZone(zoneID) {
+		return &CouponValidationResponse{
+			Valid:   false,
+			Message: "Coupon is not valid for deliveries to this area",
+		}
+	}
+	
 	// Check minimum order amount
 	if orderAmount < coupon.MinimumOrderAmount {
 		return &CouponValidationResponse{
 			Valid:   false,
-			Message: fmt.Sprintf("Minimum order amount is %.2f", coupon.MinimumOrderAmount),
+			Message: minimumOrderMessage(coupon),
 		}
 	}
 	
@@ -597,27 +828,54 @@ func (s *service) validateCouponForUser(coupon *Coupon, userID uuid.UUID, orderA
 	}
 	
 	// Calculate discount amount
-	discountAmount := s.calculateDiscount(coupon, orderAmount)
+	discountAmount := s.calculateDiscount(coupon, orderAmount, deliveryFee)
 	
 	return &CouponValidationResponse{
-		Valid:          true,
-		DiscountAmount: discountAmount,
-		Message:        "Coupon is valid",
+		Valid:               true,
+		DiscountAmount:      discountAmount,
+		DiscountAmountNaira: money.Money(discountAmount).Naira(),
+		AppliesToDelivery:   coupon.Type == CouponFreeDelivery,
+		Message:             "Coupon is valid",
 	}
 }
 
-func (s *service) calculateDiscount(coupon *Coupon, orderAmount float64) float64 {
+// calculateDiscount returns the discount in kobo, never more than the order amount. A free
+// delivery coupon is worth the delivery fee instead.
+func (s *service) calculateDiscount(coupon *Coupon, orderAmount, deliveryFee int64) int64 {
+	var discount int64
 	switch coupon.Type {
+	case CouponFreeDelivery:
+		return deliveryFee
 	case CouponPercentage:
-		return math.Round((orderAmount * coupon.Value / 100) * 100) / 100
+		discount = int64(math.Round(float64(orderAmount) * coupon.Value / 100))
 	case CouponFixed:
-		if coupon.Value > orderAmount {
-			return orderAmount
-		}
-		return coupon.Value
+		discount = int64(math.Round(coupon.Value))
 	default:
 		return 0
 	}
+	if discount > orderAmount {
+		return orderAmount
+	}
+	return discount
+}
+
+// normalizeZoneIDs trims and de-duplicates zone IDs, keeping their order
+func normalizeZoneIDs(zoneIDs []string) []string {
+	normalized := make([]string, 0, len(zoneIDs))
+	seen := make(map[string]bool, len(zoneIDs))
+	for _, id := range zoneIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		normalized = append(normalized, id)
+	}
+	return normalized
+}
+
+func minimumOrderMessage(coupon *Coupon) string {
+	return "Minimum order amount is " + money.Money(coupon.MinimumOrderAmount).String()
 }
 
 func (s *service) toCouponResponse(coupon *Coupon) *CouponResponse {
@@ -629,12 +887,14 @@ func (s *service) toCouponResponse(coupon *Coupon) *CouponResponse {
 		Description:        coupon.Description,
 		MaxUsage:           coupon.MaxUsage,
 		UsageCount:         coupon.UsageCount,
+		StartsAt:           coupon.StartsAt,
 		ExpiryDate:         coupon.ExpiryDate,
 		IsActive:           coupon.IsActive,
 		CreatedBy:          coupon.CreatedBy,
 		LinkedOrderID:      coupon.LinkedOrderID,
 		LinkedUserID:       coupon.LinkedUserID,
 		MinimumOrderAmount: coupon.MinimumOrderAmount,
+		ApplicableZoneIDs:  coupon.ApplicableZoneIDs,
 		CreatedAt:          coupon.CreatedAt,
 		UpdatedAt:          coupon.UpdatedAt,
 	}
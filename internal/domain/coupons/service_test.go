@@ -0,0 +1,249 @@
+package coupons_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	coupons "errandShop/internal/domain/coupons"
+)
+
+// stubRepo serves a single coupon by code; any other repository call panics via the nil embedded interface
+type stubRepo struct {
+	coupons.Repository
+	coupon *coupons.Coupon
+}
+
+func (r *stubRepo) GetByCode(code string) (*coupons.Coupon, error) {
+	if r.coupon == nil || r.coupon.Code != code {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.coupon, nil
+}
+
+func (r *stubRepo) GetUsageCountByUserAndCoupon(userID, couponID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func validate(t *testing.T, coupon *coupons.Coupon, orderAmountKobo int64) *coupons.CouponValidationResponse {
+	t.Helper()
+	svc := coupons.NewService(&stubRepo{coupon: coupon})
+	res, err := svc.ValidateCoupon(coupons.ValidateCouponRequest{
+		Code:        coupon.Code,
+		UserID:      uuid.New(),
+		OrderAmount: orderAmountKobo,
+	})
+	if err != nil {
+		t.Fatalf("ValidateCoupon returned error: %v", err)
+	}
+	return res
+}
+
+func TestValidateCouponAmountsInKobo(t *testing.T) {
+	tests := []struct {
+		name      string
+		coupon    coupons.Coupon
+		orderKobo int64
+		wantValid bool
+		wantKobo  int64
+	}{
+		{
+			name:      "₦500 fixed coupon takes ₦500 off a ₦2000 order",
+			coupon:    coupons.Coupon{Code: "SAVE500", Type: coupons.CouponFixed, Value: 50000},
+			orderKobo: 200000,
+			wantValid: true,
+			wantKobo:  50000,
+		},
+		{
+			name:      "10% coupon on a ₦2000 order is ₦200",
+			coupon:    coupons.Coupon{Code: "TENOFF", Type: coupons.CouponPercentage, Value: 10},
+			orderKobo: 200000,
+			wantValid: true,
+			wantKobo:  20000,
+		},
+		{
+			name:      "fixed coupon larger than the order is capped at the order amount",
+			coupon:    coupons.Coupon{Code: "BIG", Type: coupons.CouponFixed, Value: 500000},
+			orderKobo: 200000,
+			wantValid: true,
+			wantKobo:  200000,
+		},
+		{
+			name:      "₦2000 order meets a ₦2000 minimum",
+			coupon:    coupons.Coupon{Code: "MIN2K", Type: coupons.CouponFixed, Value: 50000, MinimumOrderAmount: 200000},
+			orderKobo: 200000,
+			wantValid: true,
+			wantKobo:  50000,
+		},
+		{
+			name:      "₦1999.99 order is below a ₦2000 minimum",
+			coupon:    coupons.Coupon{Code: "MIN2K", Type: coupons.CouponFixed, Value: 50000, MinimumOrderAmount: 200000},
+			orderKobo: 199999,
+			wantValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coupon := tt.coupon
+			coupon.ID = uuid.New()
+			coupon.IsActive = true
+
+			res := validate(t, &coupon, tt.orderKobo)
+			if res.Valid != tt.wantValid {
+				t.Fatalf("Valid = %v (%s), want %v", res.Valid, res.Message, tt.wantValid)
+			}
+			if res.DiscountAmount != tt.wantKobo {
+				t.Fatalf("DiscountAmount = %d kobo, want %d", res.DiscountAmount, tt.wantKobo)
+			}
+		})
+	}
+}
+
+func TestValidateCouponMinimumMessageInNaira(t *testing.T) {
+	coupon := &coupons.Coupon{
+		ID:                 uuid.New(),
+		Code:               "MIN2K",
+		Type:               coupons.CouponFixed,
+		Value:              50000,
+		MinimumOrderAmount: 200000,
+		IsActive:           true,
+	}
+
+	res := validate(t, coupon, 100000)
+	if want := "Minimum order amount is ₦2000.00"; res.Message != want {
+		t.Fatalf("Message = %q, want %q", res.Message, want)
+	}
+}
+
+func TestValidateCouponDeliveryZones(t *testing.T) {
+	lekki := &coupons.Coupon{
+		ID:                uuid.New(),
+		Code:              "LEKKIFREE",
+		Type:              coupons.CouponFreeDelivery,
+		IsActive:          true,
+		ApplicableZoneIDs: []string{"3", "4"},
+	}
+	svc := coupons.NewService(&stubRepo{coupon: lekki})
+
+	tests := []struct {
+		name      string
+		zoneID    string
+		wantValid bool
+	}{
+		{"order delivered to an allowed zone", "4", true},
+		{"order delivered to another zone", "1", false},
+		{"order with no matched zone", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := svc.ValidateCoupon(coupons.ValidateCouponRequest{
+				Code:        lekki.Code,
+				UserID:      uuid.New(),
+				OrderAmount: 200000,
+				DeliveryFee: 150000,
+				ZoneID:      tt.zoneID,
+			})
+			if err != nil {
+				t.Fatalf("ValidateCoupon returned error: %v", err)
+			}
+			if res.Valid != tt.wantValid {
+				t.Fatalf("Valid = %v (%s), want %v", res.Valid, res.Message, tt.wantValid)
+			}
+			if !tt.wantValid {
+				return
+			}
+			if !res.AppliesToDelivery || res.DiscountAmount != 150000 {
+				t.Fatalf("got %d kobo off (delivery %v), want the 150000 kobo delivery fee", res.DiscountAmount, res.AppliesToDelivery)
+			}
+		})
+	}
+}
+
+// listRepo serves a fixed set of available coupons to BestCouponForOrder
+type listRepo struct {
+	stubRepo
+	available []coupons.Coupon
+}
+
+func (r *listRepo) List(page, limit int, filter coupons.CouponFilter) ([]coupons.Coupon, int64, error) {
+	return r.available, int64(len(r.available)), nil
+}
+
+func TestBestCouponForOrderPicksLargestValidDiscount(t *testing.T) {
+	available := []coupons.Coupon{
+		{Code: "TENOFF", Type: coupons.CouponPercentage, Value: 10},                           // ₦200 on ₦2000
+		{Code: "SAVE500", Type: coupons.CouponFixed, Value: 50000},                            // ₦500
+		{Code: "MIN5K", Type: coupons.CouponFixed, Value: 100000, MinimumOrderAmount: 500000}, // below minimum
+		{Code: "OFF", Type: coupons.CouponFixed, Value: 150000, IsActive: false},              // inactive
+	}
+	for i := range available {
+		available[i].ID = uuid.New()
+		if available[i].Code != "OFF" {
+			available[i].IsActive = true
+		}
+	}
+
+	svc := coupons.NewService(&listRepo{available: available})
+	best, err := svc.BestCouponForOrder(uuid.New(), 200000, 0, "")
+	if err != nil {
+		t.Fatalf("BestCouponForOrder returned error: %v", err)
+	}
+	if best == nil || best.Coupon == nil {
+		t.Fatal("BestCouponForOrder returned no coupon, want SAVE500")
+	}
+	if best.Coupon.Code != "SAVE500" || best.DiscountAmount != 50000 {
+		t.Fatalf("best = %s (%d kobo), want SAVE500 (50000 kobo)", best.Coupon.Code, best.DiscountAmount)
+	}
+
+	none, err := svc.BestCouponForOrder(uuid.New(), 0, 0, "")
+	if err != nil {
+		t.Fatalf("BestCouponForOrder returned error: %v", err)
+	}
+	if none != nil {
+		t.Fatalf("BestCouponForOrder on an empty order = %s, want nil", none.Coupon.Code)
+	}
+}
+
+// creditRepo records the coupon and refund credit a store credit refund creates
+type creditRepo struct {
+	coupons.Repository
+	coupon *coupons.Coupon
+	credit *coupons.UserRefundCredit
+}
+
+func (r *creditRepo) Create(coupon *coupons.Coupon) error {
+	r.coupon = coupon
+	return nil
+}
+
+func (r *creditRepo) CreateRefundCredit(credit *coupons.UserRefundCredit) error {
+	r.credit = credit
+	return nil
+}
+
+func TestIssueStoreCreditCreatesOneTimeCouponForCustomer(t *testing.T) {
+	repo := &creditRepo{}
+	svc := coupons.NewService(repo)
+	orderID, userID := uuid.New(), uuid.New()
+
+	res, err := svc.IssueStoreCredit(orderID, userID, 250000, "customer cancelled")
+	if err != nil {
+		t.Fatalf("IssueStoreCredit returned error: %v", err)
+	}
+	if res.Type != coupons.CouponFixed || res.Value != 250000 {
+		t.Fatalf("expected a fixed ₦2500 coupon, got %s %v", res.Type, res.Value)
+	}
+	c := repo.coupon
+	if c.MaxUsage == nil || *c.MaxUsage != 1 || c.LinkedUserID == nil || *c.LinkedUserID != userID || *c.LinkedOrderID != orderID {
+		t.Fatalf("coupon is not one-time and linked to the customer and order: %+v", c)
+	}
+	if repo.credit == nil || !repo.credit.ConvertedToCoupon || *repo.credit.CouponID != c.ID || repo.credit.RefundAmount != 250000 {
+		t.Fatalf("refund credit not recorded against the coupon: %+v", repo.credit)
+	}
+
+	if _, err := svc.IssueStoreCredit(orderID, userID, 0, ""); err == nil {
+		t.Fatal("expected an error for a zero amount")
+	}
+}
@@ -0,0 +1,72 @@
+package custom_requests
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportUserData returns all of the user's custom requests with their items, quotes and messages
+func (s *service) ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error) {
+	requests, err := s.repo.GetCustomRequestsForExport(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom requests: %w", err)
+	}
+
+	responses := make([]CustomRequestRes, len(requests))
+	for i := range requests {
+		responses[i] = requests[i].ToCustomRequestRes()
+	}
+	return responses, nil
+}
+
+// EraseUserData cancels the user's open custom requests and removes what they wrote: request
+// notes, item descriptions and photos, and their messages. Item names and quotes stay so accepted
+// quotes still account for what was sold.
+func (s *service) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.EraseUserData(userID)
+}
+
+// GetCustomRequestsForExport returns every custom request of the user with all details, newest first
+func (r *repository) GetCustomRequestsForExport(userID uuid.UUID) ([]CustomRequest, error) {
+	var requests []CustomRequest
+	err := r.db.Preload("Items").
+		Preload("Quotes").
+		Preload("Quotes.Items").
+		Preload("Messages").
+		Where("user_id = ?", userID).
+		Order("submitted_at DESC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// EraseUserData cancels the user's open custom requests and blanks or deletes the text and photos
+// they supplied
+func (r *repository) EraseUserData(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		requestIDs := tx.Model(&CustomRequest{}).Select("id").Where("user_id = ?", userID)
+
+		if err := tx.Model(&CustomRequest{}).
+			Where("user_id = ? AND status IN ?", userID, openRequestStatuses).
+			Update("status", RequestCancelled).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&CustomRequest{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"notes":               "",
+			"delivery_address_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&RequestItem{}).Where("custom_request_id IN (?)", requestIDs).Updates(map[string]interface{}{
+			"description":     "",
+			"preferred_brand": "",
+			"images":          "[]",
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("custom_request_id IN (?) AND sender_type = ?", requestIDs, SenderUser).
+			Delete(&CustomRequestMessage{}).Error
+	})
+}
@@ -0,0 +1,276 @@
+package custom_requests
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"errandShop/internal/domain/notifications"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// AutoAssignMode decides whether newly submitted requests are handed to the assignee pool, and how
+type AutoAssignMode string
+
+const (
+	// AutoAssignOff leaves new requests unassigned for manual triage
+	AutoAssignOff AutoAssignMode = "off"
+	// AutoAssignRoundRobin gives each request to the available assignee who has waited longest for one
+	AutoAssignRoundRobin AutoAssignMode = "round_robin"
+	// AutoAssignLeastLoaded gives each request to the available assignee with the fewest open requests
+	AutoAssignLeastLoaded AutoAssignMode = "least_loaded"
+)
+
+var (
+	// ErrAssigneeNotAdmin is returned when a pool member is not an active admin account
+	ErrAssigneeNotAdmin = errors.New("assignee must be an active admin")
+	// ErrAssigneeNotInPool is returned when removing an admin who is not in the pool
+	ErrAssigneeNotInPool = errors.New("admin is not in the assignee pool")
+)
+
+// openRequestStatuses are the statuses that count towards an assignee's load
+var openRequestStatuses = []RequestStatus{RequestSubmitted, RequestUnderReview, RequestNeedsInfo, RequestQuoteReady, RequestQuoteSent}
+
+// Assignee is an admin in the auto-assignment pool. Unavailable members stay in the pool but are
+// skipped until they are marked available again.
+type Assignee struct {
+	AdminID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"adminId"`
+	Available      bool       `gorm:"not null;default:true" json:"available"`
+	LastAssignedAt *time.Time `json:"lastAssignedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func (Assignee) TableName() string {
+	return "custom_request_assignees"
+}
+
+// SetAutoAssignMode turns auto-assignment of new requests on or off (the default)
+func (s *service) SetAutoAssignMode(mode AutoAssignMode) error {
+	switch mode {
+	case AutoAssignOff, AutoAssignRoundRobin, AutoAssignLeastLoaded:
+		s.autoAssignMode = mode
+		return nil
+	}
+	return fmt.Errorf("invalid auto-assign mode: %q", mode)
+}
+
+// ListAssignees returns the assignee pool with each member's open request count
+func (s *service) ListAssignees() (*AssigneePoolRes, error) {
+	assignees, err := s.repo.ListAssignees()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignees: %w", err)
+	}
+	loads, err := s.repo.CountOpenRequestsByAssignee(assigneeIDs(assignees))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open requests: %w", err)
+	}
+
+	mode := s.autoAssignMode
+	if mode == "" {
+		mode = AutoAssignOff
+	}
+	res := &AssigneePoolRes{Mode: mode, Data: make([]AssigneeRes, 0, len(assignees))}
+	for _, a := range assignees {
+		res.Data = append(res.Data, AssigneeRes{
+			AdminID:        a.AdminID,
+			Available:      a.Available,
+			OpenRequests:   loads[a.AdminID],
+			LastAssignedAt: a.LastAssignedAt,
+		})
+	}
+	return res, nil
+}
+
+// SetAssigneeAvailability adds an admin to the pool, or updates whether they take new requests
+func (s *service) SetAssigneeAvailability(adminID uuid.UUID, available bool) (*AssigneeRes, error) {
+	isAdmin, err := s.repo.IsActiveAdmin(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check admin: %w", err)
+	}
+	if !isAdmin {
+		return nil, ErrAssigneeNotAdmin
+	}
+
+	assignee, err := s.repo.SaveAssigneeAvailability(adminID, available)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save assignee: %w", err)
+	}
+	loads, err := s.repo.CountOpenRequestsByAssignee([]uuid.UUID{adminID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open requests: %w", err)
+	}
+	return &AssigneeRes{
+		AdminID:        assignee.AdminID,
+		Available:      assignee.Available,
+		OpenRequests:   loads[adminID],
+		LastAssignedAt: assignee.LastAssignedAt,
+	}, nil
+}
+
+// RemoveAssignee takes an admin out of the pool. Requests already assigned to them stay assigned.
+func (s *service) RemoveAssignee(adminID uuid.UUID) error {
+	removed, err := s.repo.DeleteAssignee(adminID)
+	if err != nil {
+		return fmt.Errorf("failed to remove assignee: %w", err)
+	}
+	if !removed {
+		return ErrAssigneeNotInPool
+	}
+	return nil
+}
+
+// autoAssign hands a newly submitted request to the pool and notifies the assignee. With nobody
+// available the request stays unassigned for manual triage; failures are logged only.
+func (s *service) autoAssign(cr *CustomRequest) {
+	if s.autoAssignMode == "" || s.autoAssignMode == AutoAssignOff {
+		return
+	}
+
+	candidates, err := s.repo.ListAvailableAssignees()
+	if err != nil {
+		log.Printf("custom requests: failed to load assignee pool for %s: %v", cr.ID, err)
+		return
+	}
+	var loads map[uuid.UUID]int64
+	if s.autoAssignMode == AutoAssignLeastLoaded {
+		if loads, err = s.repo.CountOpenRequestsByAssignee(assigneeIDs(candidates)); err != nil {
+			log.Printf("custom requests: failed to count assignee load for %s: %v", cr.ID, err)
+			return
+		}
+	}
+	assignee := pickAssignee(candidates, loads, s.autoAssignMode)
+	if assignee == nil {
+		return
+	}
+
+	if _, err := s.AssignCustomRequest(cr.ID, assignee.AdminID); err != nil {
+		log.Printf("custom requests: failed to auto-assign %s: %v", cr.ID, err)
+		return
+	}
+	if err := s.repo.MarkAssigneeAssigned(assignee.AdminID, time.Now()); err != nil {
+		log.Printf("custom requests: failed to record assignment for %s: %v", assignee.AdminID, err)
+	}
+
+	body := fmt.Sprintf("A %s priority custom request with %d item(s) is waiting on you.", cr.Priority, len(cr.Items))
+	if target := s.responseSLA[cr.Priority]; target > 0 {
+		body += fmt.Sprintf(" Respond by %s.", cr.SubmittedAt.Add(target).Format("15:04 on 2 Jan"))
+	}
+	s.notify(assignee.AdminID, notifications.RecipientAdmin, "Custom request assigned to you", body, map[string]interface{}{
+		"customRequestId": cr.ID.String(),
+		"reason":          "auto_assigned",
+	})
+}
+
+// pickAssignee chooses from the available assignees: in round robin the one who has waited longest
+// since their last assignment (never assigned first), in least loaded the one with the fewest open
+// requests, ties going to round robin order. It returns nil for an empty pool.
+func pickAssignee(candidates []Assignee, loads map[uuid.UUID]int64, mode AutoAssignMode) *Assignee {
+	if len(candidates) == 0 {
+		return nil
+	}
+	sorted := append([]Assignee(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if mode == AutoAssignLeastLoaded && loads[a.AdminID] != loads[b.AdminID] {
+			return loads[a.AdminID] < loads[b.AdminID]
+		}
+		switch {
+		case a.LastAssignedAt == nil || b.LastAssignedAt == nil:
+			if (a.LastAssignedAt == nil) != (b.LastAssignedAt == nil) {
+				return a.LastAssignedAt == nil
+			}
+		case !a.LastAssignedAt.Equal(*b.LastAssignedAt):
+			return a.LastAssignedAt.Before(*b.LastAssignedAt)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return &sorted[0]
+}
+
+func assigneeIDs(assignees []Assignee) []uuid.UUID {
+	ids := make([]uuid.UUID, len(assignees))
+	for i, a := range assignees {
+		ids[i] = a.AdminID
+	}
+	return ids
+}
+
+// Assignee pool operations
+
+func (r *repository) ListAssignees() ([]Assignee, error) {
+	var assignees []Assignee
+	err := r.db.Order("created_at ASC").Find(&assignees).Error
+	return assignees, err
+}
+
+// ListAvailableAssignees returns the pool members marked available whose accounts are still active admins
+func (r *repository) ListAvailableAssignees() ([]Assignee, error) {
+	var assignees []Assignee
+	err := r.db.Table("custom_request_assignees AS a").
+		Select("a.*").
+		Joins("JOIN users u ON u.id = a.admin_id").
+		Where("a.available = ?", true).
+		Where("u.role IN ? AND u.status = ?", []string{"admin", "superadmin"}, "active").
+		Order("a.created_at ASC").
+		Find(&assignees).Error
+	return assignees, err
+}
+
+func (r *repository) IsActiveAdmin(adminID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Table("users").
+		Where("id = ? AND role IN ? AND status = ?", adminID, []string{"admin", "superadmin"}, "active").
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) SaveAssigneeAvailability(adminID uuid.UUID, available bool) (*Assignee, error) {
+	assignee := Assignee{AdminID: adminID, Available: available}
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "admin_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"available", "updated_at"}),
+	}).Create(&assignee).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.First(&assignee, "admin_id = ?", adminID).Error; err != nil {
+		return nil, err
+	}
+	return &assignee, nil
+}
+
+func (r *repository) DeleteAssignee(adminID uuid.UUID) (bool, error) {
+	res := r.db.Delete(&Assignee{}, "admin_id = ?", adminID)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *repository) MarkAssigneeAssigned(adminID uuid.UUID, at time.Time) error {
+	return r.db.Model(&Assignee{}).Where("admin_id = ?", adminID).Update("last_assigned_at", at).Error
+}
+
+// CountOpenRequestsByAssignee returns how many open requests each admin is assigned; admins with
+// none are absent from the map
+func (r *repository) CountOpenRequestsByAssignee(adminIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	loads := make(map[uuid.UUID]int64, len(adminIDs))
+	if len(adminIDs) == 0 {
+		return loads, nil
+	}
+	var rows []struct {
+		AssigneeID uuid.UUID
+		Count      int64
+	}
+	if err := r.db.Model(&CustomRequest{}).
+		Select("assignee_id, COUNT(*) AS count").
+		Where("assignee_id IN ? AND status IN ?", adminIDs, openRequestStatuses).
+		Group("assignee_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		loads[row.AssigneeID] = row.Count
+	}
+	return loads, nil
+}
@@ -0,0 +1,48 @@
+package custom_requests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPickAssignee(t *testing.T) {
+	base := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	earlier, later := base.Add(-2*time.Hour), base.Add(-time.Hour)
+
+	ada := Assignee{AdminID: uuid.New(), LastAssignedAt: &later, CreatedAt: base.Add(-72 * time.Hour)}
+	bola := Assignee{AdminID: uuid.New(), LastAssignedAt: &earlier, CreatedAt: base.Add(-48 * time.Hour)}
+	chidi := Assignee{AdminID: uuid.New(), CreatedAt: base.Add(-24 * time.Hour)}
+
+	if got := pickAssignee(nil, nil, AutoAssignRoundRobin); got != nil {
+		t.Fatalf("empty pool: expected nil, got %v", got.AdminID)
+	}
+
+	pool := []Assignee{ada, bola, chidi}
+	if got := pickAssignee(pool, nil, AutoAssignRoundRobin); got.AdminID != chidi.AdminID {
+		t.Fatalf("round robin should pick the member never assigned, got %v", got.AdminID)
+	}
+	if got := pickAssignee([]Assignee{ada, bola}, nil, AutoAssignRoundRobin); got.AdminID != bola.AdminID {
+		t.Fatalf("round robin should pick the longest wait, got %v", got.AdminID)
+	}
+
+	loads := map[uuid.UUID]int64{ada.AdminID: 1, bola.AdminID: 4, chidi.AdminID: 3}
+	if got := pickAssignee(pool, loads, AutoAssignLeastLoaded); got.AdminID != ada.AdminID {
+		t.Fatalf("least loaded should pick the fewest open requests, got %v", got.AdminID)
+	}
+	loads[chidi.AdminID] = 1
+	if got := pickAssignee(pool, loads, AutoAssignLeastLoaded); got.AdminID != chidi.AdminID {
+		t.Fatalf("least loaded ties should go to round robin order, got %v", got.AdminID)
+	}
+}
+
+func TestSetAutoAssignMode(t *testing.T) {
+	s := &service{autoAssignMode: AutoAssignOff}
+	if err := s.SetAutoAssignMode(AutoAssignLeastLoaded); err != nil || s.autoAssignMode != AutoAssignLeastLoaded {
+		t.Fatalf("least_loaded: err=%v mode=%q", err, s.autoAssignMode)
+	}
+	if err := s.SetAutoAssignMode("random"); err == nil || s.autoAssignMode != AutoAssignLeastLoaded {
+		t.Fatalf("invalid mode should be rejected and leave the mode unchanged: err=%v mode=%q", err, s.autoAssignMode)
+	}
+}
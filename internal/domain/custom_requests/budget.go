@@ -0,0 +1,68 @@
+package custom_requests
+
+import (
+	"errors"
+	"fmt"
+
+	"errandShop/pkg/money"
+)
+
+// BudgetCapMode decides what happens when a quote's grand total is above the customer's budget cap
+type BudgetCapMode string
+
+const (
+	// BudgetCapWarn lets the quote through and flags it for the admin
+	BudgetCapWarn BudgetCapMode = "warn"
+	// BudgetCapBlock rejects the quote until it fits the cap
+	BudgetCapBlock BudgetCapMode = "block"
+)
+
+var (
+	// ErrInvalidBudgetCap is returned when a custom request's budget cap is not a positive amount
+	ErrInvalidBudgetCap = errors.New("budget cap must be greater than zero")
+	// ErrQuoteExceedsBudget is returned in block mode when a quote costs more than the customer's cap
+	ErrQuoteExceedsBudget = errors.New("quote exceeds the customer's budget cap")
+)
+
+// SetBudgetCapMode sets whether over-budget quotes are only flagged (the default) or rejected
+func (s *service) SetBudgetCapMode(mode BudgetCapMode) error {
+	switch mode {
+	case BudgetCapWarn, BudgetCapBlock:
+		s.budgetCapMode = mode
+		return nil
+	}
+	return fmt.Errorf("invalid budget cap mode: %q", mode)
+}
+
+// OverBudgetKobo returns how far grandTotal is above the request's budget cap, or 0 when it fits
+// or there is no cap
+func (cr *CustomRequest) OverBudgetKobo(grandTotal int64) int64 {
+	if cr.BudgetCapKobo == nil || grandTotal <= *cr.BudgetCapKobo {
+		return 0
+	}
+	return grandTotal - *cr.BudgetCapKobo
+}
+
+// annotateBudget flags a quote response that goes over the request's budget cap
+func (cr *CustomRequest) annotateBudget(res *QuoteRes) {
+	over := cr.OverBudgetKobo(res.GrandTotal)
+	if over == 0 {
+		return
+	}
+	res.OverBudgetKobo = over
+	res.BudgetWarning = fmt.Sprintf("Quote total of ₦%.2f is ₦%.2f over the customer's budget of ₦%.2f",
+		money.Money(res.GrandTotal).Naira(), money.Money(over).Naira(), money.Money(*cr.BudgetCapKobo).Naira())
+}
+
+// checkBudget rejects an over-budget quote total in block mode; in warn mode the response carries
+// the warning instead
+func (s *service) checkBudget(cr *CustomRequest, grandTotal int64) error {
+	if s.budgetCapMode != BudgetCapBlock {
+		return nil
+	}
+	if over := cr.OverBudgetKobo(grandTotal); over > 0 {
+		return fmt.Errorf("%w: total is ₦%.2f over the ₦%.2f cap",
+			ErrQuoteExceedsBudget, money.Money(over).Naira(), money.Money(*cr.BudgetCapKobo).Naira())
+	}
+	return nil
+}
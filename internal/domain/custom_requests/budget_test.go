@@ -0,0 +1,39 @@
+package custom_requests
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckBudget(t *testing.T) {
+	budget := int64(2_000_000) // ₦20,000
+	capped := &CustomRequest{BudgetCapKobo: &budget}
+	uncapped := &CustomRequest{}
+
+	warn := &service{budgetCapMode: BudgetCapWarn}
+	block := &service{budgetCapMode: BudgetCapBlock}
+
+	if err := block.checkBudget(capped, budget); err != nil {
+		t.Fatalf("a quote at the cap should pass: %v", err)
+	}
+	if err := block.checkBudget(uncapped, 50_000_000); err != nil {
+		t.Fatalf("requests without a cap are never blocked: %v", err)
+	}
+	if err := warn.checkBudget(capped, budget+1); err != nil {
+		t.Fatalf("warn mode should not reject: %v", err)
+	}
+	if err := block.checkBudget(capped, budget+150_000); !errors.Is(err, ErrQuoteExceedsBudget) {
+		t.Fatalf("block mode: expected ErrQuoteExceedsBudget, got %v", err)
+	}
+
+	res := QuoteRes{GrandTotal: budget + 150_000}
+	capped.annotateBudget(&res)
+	if res.OverBudgetKobo != 150_000 || res.BudgetWarning == "" {
+		t.Fatalf("over-budget quote not flagged: %+v", res)
+	}
+	res = QuoteRes{GrandTotal: budget}
+	capped.annotateBudget(&res)
+	if res.OverBudgetKobo != 0 || res.BudgetWarning != "" {
+		t.Fatalf("quote within budget flagged: %+v", res)
+	}
+}
@@ -0,0 +1,169 @@
+package custom_requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"errandShop/internal/domain/products"
+	"errandShop/pkg/textnorm"
+	"github.com/google/uuid"
+	"github.com/xrash/smetrics"
+	"gorm.io/gorm"
+)
+
+const (
+	// MinCatalogMatchScore is the lowest name similarity (0-1) reported as a catalog suggestion
+	MinCatalogMatchScore = 0.6
+	// maxSuggestionsPerItem caps the catalog products suggested for one requested item
+	maxSuggestionsPerItem = 3
+	// catalogCandidatesPerTerm caps the products fetched per search term before ranking
+	catalogCandidatesPerTerm = 10
+)
+
+// CatalogSearcher is the product search used to find catalog matches for requested items
+type CatalogSearcher interface {
+	List(ctx context.Context, q products.ListQuery) (products.ListResult, error)
+}
+
+// SetCatalogSearch enables catalog suggestions for requested items
+func (s *service) SetCatalogSearch(catalog CatalogSearcher) {
+	s.catalog = catalog
+}
+
+// SuggestCatalogMatches finds active catalog products whose names resemble each requested item,
+// so an admin can quote the line at catalog price or replace it with the catalog product
+func (s *service) SuggestCatalogMatches(requestID uuid.UUID) (*CatalogSuggestionsRes, error) {
+	customRequest, err := s.repo.GetCustomRequestByIDWithDetails(requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get custom request: %w", err)
+	}
+
+	res := &CatalogSuggestionsRes{
+		CustomRequestID: customRequest.ID,
+		Items:           make([]ItemCatalogSuggestionsRes, 0, len(customRequest.Items)),
+	}
+	for _, item := range customRequest.Items {
+		suggestions := []CatalogSuggestionRes{}
+		if s.catalog != nil {
+			candidates, err := s.catalogCandidates(item)
+			if err != nil {
+				return nil, err
+			}
+			suggestions = rankCatalogMatches(item, candidates)
+		}
+		if len(suggestions) > 0 {
+			res.MatchedItems++
+		}
+		res.Items = append(res.Items, ItemCatalogSuggestionsRes{
+			RequestItemID: item.ID,
+			Name:          item.Name,
+			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			Suggestions:   suggestions,
+		})
+	}
+
+	return res, nil
+}
+
+// catalogCandidates runs the product search once per significant word of the item name, since
+// customers rarely type a catalog name exactly, and returns the distinct products found
+func (s *service) catalogCandidates(item RequestItem) ([]products.ProductResponse, error) {
+	seen := make(map[uuid.UUID]struct{})
+	var candidates []products.ProductResponse
+	for _, term := range searchTerms(item.Name) {
+		result, err := s.catalog.List(context.Background(), products.ListQuery{Q: term, Page: 1, Limit: catalogCandidatesPerTerm})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search catalog: %w", err)
+		}
+		for _, p := range result.Data {
+			if _, ok := seen[p.ID]; ok {
+				continue
+			}
+			seen[p.ID] = struct{}{}
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates, nil
+}
+
+// searchTerms splits a normalized item name into words long enough to search on
+func searchTerms(name string) []string {
+	var terms []string
+	seen := make(map[string]struct{})
+	for _, word := range strings.Fields(textnorm.Normalize(name)) {
+		if len(word) < 3 {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// rankCatalogMatches scores candidates against the requested item and returns the best few
+// at or above MinCatalogMatchScore, most similar first
+func rankCatalogMatches(item RequestItem, candidates []products.ProductResponse) []CatalogSuggestionRes {
+	suggestions := []CatalogSuggestionRes{}
+	for _, p := range candidates {
+		score := nameSimilarity(item.Name, p.Name)
+		if item.PreferredBrand != "" {
+			if branded := nameSimilarity(item.PreferredBrand+" "+item.Name, p.Name); branded > score {
+				score = branded
+			}
+		}
+		if score < MinCatalogMatchScore {
+			continue
+		}
+		suggestions = append(suggestions, CatalogSuggestionRes{
+			ProductID:     p.ID,
+			Name:          p.Name,
+			SKU:           p.SKU,
+			Category:      p.Category,
+			Price:         p.EffectivePrice,
+			StockQuantity: p.StockQuantity,
+			InStock:       p.StockQuantity > 0,
+			ImageURL:      p.ImageURL,
+			Score:         score,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > maxSuggestionsPerItem {
+		suggestions = suggestions[:maxSuggestionsPerItem]
+	}
+	return suggestions
+}
+
+// nameSimilarity scores how well a requested name matches a product name (0-1) as the share of
+// requested words found in the product name. Words match when they are near-identical by
+// Jaro-Winkler, so "tomatoe" still finds "tomatoes".
+func nameSimilarity(requested, product string) float64 {
+	requestedWords := strings.Fields(textnorm.Normalize(requested))
+	productWords := strings.Fields(textnorm.Normalize(product))
+	if len(requestedWords) == 0 || len(productWords) == 0 {
+		return 0
+	}
+
+	matched := 0
+	for _, rw := range requestedWords {
+		for _, pw := range productWords {
+			if smetrics.JaroWinkler(rw, pw, 0.7, 4) >= 0.9 {
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(requestedWords))
+}
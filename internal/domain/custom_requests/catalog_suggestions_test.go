@@ -0,0 +1,34 @@
+package custom_requests
+
+import (
+	"testing"
+
+	"errandShop/internal/domain/products"
+	"github.com/google/uuid"
+)
+
+func TestRankCatalogMatches(t *testing.T) {
+	rice := products.ProductResponse{ID: uuid.New(), Name: "Mama Gold Rice 5kg", StockQuantity: 4}
+	milk := products.ProductResponse{ID: uuid.New(), Name: "Peak Evaporated Milk", StockQuantity: 0}
+	soap := products.ProductResponse{ID: uuid.New(), Name: "Dettol Soap"}
+
+	got := rankCatalogMatches(RequestItem{Name: "rice"}, []products.ProductResponse{milk, rice, soap})
+	if len(got) != 1 || got[0].ProductID != rice.ID || !got[0].InStock {
+		t.Fatalf("rice suggestions = %+v, want only the in-stock rice product", got)
+	}
+
+	got = rankCatalogMatches(RequestItem{Name: "evaporated milk", PreferredBrand: "Peak"}, []products.ProductResponse{soap, milk})
+	if len(got) != 1 || got[0].ProductID != milk.ID || got[0].Score != 1 || got[0].InStock {
+		t.Fatalf("milk suggestions = %+v, want the out-of-stock milk product with a full score", got)
+	}
+
+	if got := rankCatalogMatches(RequestItem{Name: "fresh tomatoes"}, []products.ProductResponse{rice, milk, soap}); len(got) != 0 {
+		t.Fatalf("tomato suggestions = %+v, want none", got)
+	}
+}
+
+func TestNameSimilarityToleratesTypos(t *testing.T) {
+	if score := nameSimilarity("tomatoe paste", "Gino Tomato Paste"); score < MinCatalogMatchScore {
+		t.Fatalf("nameSimilarity = %v, want at least %v", score, MinCatalogMatchScore)
+	}
+}
@@ -13,6 +13,7 @@ type CreateCustomRequestReq struct {
 	AllowSubstitutions bool                 `json:"allowSubstitutions"`
 	Notes              string               `json:"notes"`
 	Priority           RequestPriority      `json:"priority"`
+	BudgetCapKobo      *int64               `json:"budgetCapKobo"` // optional spending cap, in kobo
 }
 
 // CreateRequestItem represents an item in a custom request creation
@@ -33,6 +34,7 @@ type UpdateCustomRequestReq struct {
 	AllowSubstitutions *bool                `json:"allowSubstitutions"`
 	Notes              *string              `json:"notes"`
 	Priority           *RequestPriority     `json:"priority"`
+	BudgetCapKobo      *int64               `json:"budgetCapKobo"` // in kobo; 0 removes the cap
 }
 
 // UpdateRequestItem represents an item update in a custom request
@@ -106,6 +108,7 @@ type CustomRequestRes struct {
 	SubmittedAt        time.Time              `json:"submittedAt"`
 	UpdatedAt          time.Time              `json:"updatedAt"`
 	ExpiresAt          *time.Time             `json:"expiresAt"`
+	BudgetCapKobo      *int64                 `json:"budgetCapKobo"` // in kobo
 	Items              []RequestItemRes       `json:"items"`
 	Quotes             []QuoteRes             `json:"quotes,omitempty"`
 	Messages           []CustomRequestMsgRes  `json:"messages,omitempty"`
@@ -130,6 +133,9 @@ type RequestItemRes struct {
 type QuoteRes struct {
 	ID            uuid.UUID      `json:"id"`
 	ItemsSubtotal int64          `json:"itemsSubtotal"` // in kobo
+	DeliveryFee   int64          `json:"deliveryFee"`   // in kobo
+	ServiceFee    int64          `json:"serviceFee"`    // in kobo
+	PackagingFee  int64          `json:"packagingFee"`  // in kobo
 	Fees          QuoteFees      `json:"fees"`
 	FeesTotal     int64          `json:"feesTotal"`     // in kobo
 	GrandTotal    int64          `json:"grandTotal"`    // in kobo
@@ -140,6 +146,9 @@ type QuoteRes struct {
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
 	Items         []QuoteItemRes `json:"items"`
+	// Set when the grand total is above the request's budget cap
+	OverBudgetKobo int64  `json:"overBudgetKobo,omitempty"`
+	BudgetWarning  string `json:"budgetWarning,omitempty"`
 }
 
 // QuoteItemRes represents a quote item response
@@ -178,6 +187,77 @@ type CustomRequestListRes struct {
 	TotalPages int                `json:"totalPages"`
 }
 
+// CustomRequestQueueItemRes is one request in the admin work queue with its SLA position
+type CustomRequestQueueItemRes struct {
+	ID               uuid.UUID       `json:"id"`
+	UserID           uuid.UUID       `json:"userId"`
+	Status           RequestStatus   `json:"status"`
+	Priority         RequestPriority `json:"priority"`
+	AssigneeID       *uuid.UUID      `json:"assigneeId"`
+	ItemCount        int             `json:"itemCount"`
+	SubmittedAt      time.Time       `json:"submittedAt"`
+	BudgetCapKobo    *int64          `json:"budgetCapKobo"`
+	AgeMinutes       int64           `json:"ageMinutes"`
+	SLATargetMinutes int64           `json:"slaTargetMinutes"` // 0 when no target is configured for the priority
+	SLADueAt         *time.Time      `json:"slaDueAt"`
+	SLABreached      bool            `json:"slaBreached"`
+}
+
+// CustomRequestQueueRes is the admin work queue, most urgent first
+type CustomRequestQueueRes struct {
+	Data     []CustomRequestQueueItemRes `json:"data"`
+	Total    int                         `json:"total"`
+	Breached int                         `json:"breached"`
+}
+
+// SetAssigneeAvailabilityReq adds an admin to the assignee pool or changes their availability
+type SetAssigneeAvailabilityReq struct {
+	Available *bool `json:"available"`
+}
+
+// AssigneeRes is an admin in the assignee pool with their current load
+type AssigneeRes struct {
+	AdminID        uuid.UUID  `json:"adminId"`
+	Available      bool       `json:"available"`
+	OpenRequests   int64      `json:"openRequests"` // assigned requests not yet accepted, declined or cancelled
+	LastAssignedAt *time.Time `json:"lastAssignedAt"`
+}
+
+// AssigneePoolRes is the auto-assignment pool and the mode new requests are assigned in
+type AssigneePoolRes struct {
+	Mode AutoAssignMode `json:"mode"`
+	Data []AssigneeRes  `json:"data"`
+}
+
+// CatalogSuggestionRes is a catalog product that resembles a requested item
+type CatalogSuggestionRes struct {
+	ProductID     uuid.UUID `json:"productId"`
+	Name          string    `json:"name"`
+	SKU           string    `json:"sku"`
+	Category      string    `json:"category"`
+	Price         float64   `json:"price"` // current effective price, in naira
+	StockQuantity int       `json:"stockQuantity"`
+	InStock       bool      `json:"inStock"`
+	ImageURL      string    `json:"imageUrl"`
+	Score         float64   `json:"score"` // name similarity, 0-1
+}
+
+// ItemCatalogSuggestionsRes lists the catalog suggestions for one requested item, best first
+type ItemCatalogSuggestionsRes struct {
+	RequestItemID uuid.UUID              `json:"requestItemId"`
+	Name          string                 `json:"name"`
+	Quantity      float64                `json:"quantity"`
+	Unit          string                 `json:"unit"`
+	Suggestions   []CatalogSuggestionRes `json:"suggestions"`
+}
+
+// CatalogSuggestionsRes holds catalog suggestions for every item of a custom request
+type CatalogSuggestionsRes struct {
+	CustomRequestID uuid.UUID                   `json:"customRequestId"`
+	Items           []ItemCatalogSuggestionsRes `json:"items"`
+	MatchedItems    int                         `json:"matchedItems"` // items with at least one suggestion
+}
+
 // CustomRequestStatsRes represents statistics for custom requests
 type CustomRequestStatsRes struct {
 	TotalRequests     int64                        `json:"totalRequests"`
@@ -241,6 +321,7 @@ func (cr *CustomRequest) ToCustomRequestRes() CustomRequestRes {
 		SubmittedAt:        cr.SubmittedAt,
 		UpdatedAt:          cr.UpdatedAt,
 		ExpiresAt:          cr.ExpiresAt,
+		BudgetCapKobo:      cr.BudgetCapKobo,
 	}
 
 	// Convert items
@@ -250,7 +331,9 @@ func (cr *CustomRequest) ToCustomRequestRes() CustomRequestRes {
 
 	// Convert quotes
 	for _, quote := range cr.Quotes {
-		res.Quotes = append(res.Quotes, quote.ToQuoteRes())
+		quoteRes := quote.ToQuoteRes()
+		cr.annotateBudget(&quoteRes)
+		res.Quotes = append(res.Quotes, quoteRes)
 	}
 
 	// Convert messages
@@ -261,6 +344,7 @@ func (cr *CustomRequest) ToCustomRequestRes() CustomRequestRes {
 	// Set active quote
 	if activeQuote := cr.GetActiveQuote(); activeQuote != nil {
 		quoteRes := activeQuote.ToQuoteRes()
+		cr.annotateBudget(&quoteRes)
 		res.ActiveQuote = &quoteRes
 	}
 
@@ -294,6 +378,9 @@ func (q *Quote) ToQuoteRes() QuoteRes {
 	return QuoteRes{
 		ID:            q.ID,
 		ItemsSubtotal: q.ItemsSubtotal,
+		DeliveryFee:   q.Fees.Delivery,
+		ServiceFee:    q.Fees.Service,
+		PackagingFee:  q.Fees.Packaging,
 		Fees:          q.Fees,
 		FeesTotal:     q.FeesTotal,
 		GrandTotal:    q.GrandTotal,
@@ -229,6 +229,41 @@ func (h *Handler) CancelCustomRequest(c *fiber.Ctx) error {
 	})
 }
 
+// DuplicateCustomRequest creates a new request with the items of an existing one
+// @Summary Duplicate custom request
+// @Description Submit a new custom request copying the items of one of the user's past requests
+// @Tags custom-requests
+// @Produce json
+// @Param id path string true "Custom Request ID"
+// @Success 201 {object} CustomRequestRes
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/custom-requests/{id}/duplicate [post]
+func (h *Handler) DuplicateCustomRequest(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request ID",
+		})
+	}
+
+	result, err := h.service.DuplicateCustomRequest(userID, requestID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(result)
+}
+
 // ListUserCustomRequests lists user's custom requests
 // @Summary List user custom requests
 // @Description List custom requests for the authenticated user
@@ -441,6 +476,128 @@ func (h *Handler) ListCustomRequestsAdmin(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
+// GetAdminQueue returns the admin work queue
+// @Summary Custom request work queue
+// @Description Submitted and under-review requests ordered by priority then age, with SLA breach flags (admin only)
+// @Tags admin,custom-requests
+// @Produce json
+// @Param unassigned query bool false "Only requests nobody is assigned to"
+// @Success 200 {object} CustomRequestQueueRes
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/admin/custom-requests/queue [get]
+func (h *Handler) GetAdminQueue(c *fiber.Ctx) error {
+	result, err := h.service.GetAdminQueue(c.QueryBool("unassigned", false))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// ListAssignees returns the auto-assignment pool
+// @Summary Custom request assignee pool
+// @Description Admins new requests can be auto-assigned to, with availability and open request counts (admin only)
+// @Tags admin,custom-requests
+// @Produce json
+// @Success 200 {object} AssigneePoolRes
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/admin/custom-requests/assignees [get]
+func (h *Handler) ListAssignees(c *fiber.Ctx) error {
+	result, err := h.service.ListAssignees()
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// SetAssigneeAvailability adds an admin to the pool or marks them available/unavailable
+// @Summary Set assignee availability
+// @Description Add an admin to the auto-assignment pool, or change whether they receive new requests (admin only)
+// @Tags admin,custom-requests
+// @Accept json
+// @Produce json
+// @Param admin_id path string true "Admin ID"
+// @Param request body SetAssigneeAvailabilityReq true "Availability"
+// @Success 200 {object} AssigneeRes
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/admin/custom-requests/assignees/{admin_id} [put]
+func (h *Handler) SetAssigneeAvailability(c *fiber.Ctx) error {
+	adminID, err := uuid.Parse(c.Params("admin_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid admin ID",
+		})
+	}
+
+	var req SetAssigneeAvailabilityReq
+	if err := c.BodyParser(&req); err != nil || req.Available == nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "available is required",
+		})
+	}
+
+	result, err := h.service.SetAssigneeAvailability(adminID, *req.Available)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// RemoveAssignee takes an admin out of the auto-assignment pool
+// @Summary Remove assignee
+// @Description Remove an admin from the auto-assignment pool; their current requests stay assigned (admin only)
+// @Tags admin,custom-requests
+// @Param admin_id path string true "Admin ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/custom-requests/assignees/{admin_id} [delete]
+func (h *Handler) RemoveAssignee(c *fiber.Ctx) error {
+	adminID, err := uuid.Parse(c.Params("admin_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid admin ID",
+		})
+	}
+
+	if err := h.service.RemoveAssignee(adminID); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetCatalogSuggestions suggests catalog products for each requested item
+// @Summary Catalog suggestions for a custom request
+// @Description Catalog products whose names resemble each requested item, so the line can be quoted at catalog price or replaced (admin only)
+// @Tags admin,custom-requests
+// @Produce json
+// @Param id path string true "Custom Request ID"
+// @Success 200 {object} CatalogSuggestionsRes
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/custom-requests/{id}/suggestions [get]
+func (h *Handler) GetCatalogSuggestions(c *fiber.Ctx) error {
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request ID",
+		})
+	}
+
+	result, err := h.service.SuggestCatalogMatches(requestID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
 // UpdateCustomRequestStatus updates the status of a custom request
 // @Summary Update custom request status
 // @Description Update the status of a custom request (admin only)
@@ -945,6 +1102,11 @@ func (h *Handler) getUserID(c *fiber.Ctx) (uuid.UUID, error) {
 }
 
 func (h *Handler) handleError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ErrQuoteExceedsBudget) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	switch err {
 	case ErrCustomRequestNotFound:
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{
@@ -990,6 +1152,18 @@ func (h *Handler) handleError(c *fiber.Ctx, err error) error {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid quote status",
 		})
+	case ErrInvalidBudgetCap:
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Budget cap must be greater than zero",
+		})
+	case ErrAssigneeNotAdmin:
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Assignee must be an active admin",
+		})
+	case ErrAssigneeNotInPool:
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Admin is not in the assignee pool",
+		})
 	default:
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Internal server error",
@@ -24,6 +24,7 @@ type CustomRequest struct {
 	SubmittedAt        time.Time      `gorm:"default:now()" json:"submittedAt"`
 	UpdatedAt          time.Time      `gorm:"default:now()" json:"updatedAt"`
 	ExpiresAt          *time.Time     `json:"expiresAt"`
+	BudgetCapKobo      *int64         `gorm:"column:budget_cap_kobo" json:"budgetCapKobo"` // most the customer wants to spend, quote grand total included; nil for no cap
 
 	// Relationships
 	Items    []RequestItem           `gorm:"foreignKey:CustomRequestID;constraint:OnDelete:CASCADE" json:"items"`
@@ -61,6 +62,7 @@ type Quote struct {
 	ValidUntil       *time.Time  `json:"validUntil"`
 	SentAt           *time.Time  `json:"sentAt"`
 	AcceptedAt       *time.Time  `json:"acceptedAt"`
+	ReminderSentAt   *time.Time  `json:"reminderSentAt"` // pre-expiry reminder sent to the customer
 	CreatedAt        time.Time   `gorm:"default:now()" json:"createdAt"`
 	UpdatedAt        time.Time   `gorm:"default:now()" json:"updatedAt"`
 
@@ -99,9 +101,9 @@ type CustomRequestMessage struct {
 
 // QuoteFees represents the fees structure in a quote
 type QuoteFees struct {
-	Delivery  int64 `json:"delivery"`  // in kobo
-	Service   int64 `json:"service"`   // in kobo
-	Packaging int64 `json:"packaging"` // in kobo
+	Delivery  int64 `json:"delivery" validate:"gte=0"`  // in kobo
+	Service   int64 `json:"service" validate:"gte=0"`   // in kobo
+	Packaging int64 `json:"packaging" validate:"gte=0"` // in kobo
 }
 
 // Value implements the driver.Valuer interface for database storage
@@ -199,7 +201,8 @@ func (q *Quote) IsExpired() bool {
 	return !q.IsValid()
 }
 
-// CalculateTotal calculates the grand total from items subtotal and fees
+// CalculateTotal calculates the grand total from items subtotal and fees.
+// GrandTotal is exactly what the customer pays for this request when it is ordered.
 func (q *Quote) CalculateTotal() {
 	q.FeesTotal = q.Fees.Delivery + q.Fees.Service + q.Fees.Packaging
 	q.GrandTotal = q.ItemsSubtotal + q.FeesTotal
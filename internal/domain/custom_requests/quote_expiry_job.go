@@ -0,0 +1,57 @@
+package custom_requests
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// QuoteExpiryJob warns customers before a sent quote lapses and declines quotes
+// whose ValidUntil has passed. Both steps are idempotent, so the job can run often.
+type QuoteExpiryJob struct {
+	service      Service
+	remindBefore time.Duration
+	interval     time.Duration
+	logger       *log.Logger
+}
+
+func NewQuoteExpiryJob(service Service, remindBefore time.Duration) *QuoteExpiryJob {
+	return &QuoteExpiryJob{
+		service:      service,
+		remindBefore: remindBefore,
+		interval:     15 * time.Minute,
+		logger:       log.New(log.Writer(), "[QUOTE-EXPIRY] ", log.LstdFlags),
+	}
+}
+
+// Start runs the job on a fixed interval until ctx is cancelled
+func (j *QuoteExpiryJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.RunOnce()
+			}
+		}
+	}()
+}
+
+// RunOnce sends due pre-expiry reminders, then expires lapsed quotes
+func (j *QuoteExpiryJob) RunOnce() {
+	if j.remindBefore > 0 {
+		if sent, err := j.service.SendQuoteExpiryReminders(j.remindBefore); err != nil {
+			j.logger.Printf("reminders failed: %v", err)
+		} else if sent > 0 {
+			j.logger.Printf("sent %d quote expiry reminders", sent)
+		}
+	}
+
+	if err := j.service.ProcessExpiredQuotes(); err != nil {
+		j.logger.Printf("expiring quotes failed: %v", err)
+	}
+}
@@ -19,6 +19,7 @@ type Repository interface {
 	ListCustomRequests(query CustomRequestListQuery) ([]CustomRequest, int64, error)
 	GetCustomRequestsByUserID(userID uuid.UUID, query CustomRequestListQuery) ([]CustomRequest, int64, error)
 	GetCustomRequestsByAssigneeID(assigneeID uuid.UUID, query CustomRequestListQuery) ([]CustomRequest, int64, error)
+	GetQueue(statuses []RequestStatus, unassignedOnly bool) ([]CustomRequest, error)
 
 	// Request Item operations
 	CreateRequestItem(item *RequestItem) error
@@ -51,6 +52,21 @@ type Repository interface {
 	GetCustomRequestStatsByDateRange(startDate, endDate time.Time) (*CustomRequestStatsRes, error)
 	GetExpiredCustomRequests() ([]CustomRequest, error)
 	GetExpiredQuotes() ([]Quote, error)
+	GetQuotesExpiringBefore(deadline time.Time) ([]Quote, error)
+	MarkQuoteReminderSent(id uuid.UUID, at time.Time) error
+
+	// Assignee pool operations
+	ListAssignees() ([]Assignee, error)
+	ListAvailableAssignees() ([]Assignee, error)
+	IsActiveAdmin(adminID uuid.UUID) (bool, error)
+	SaveAssigneeAvailability(adminID uuid.UUID, available bool) (*Assignee, error)
+	DeleteAssignee(adminID uuid.UUID) (bool, error)
+	MarkAssigneeAssigned(adminID uuid.UUID, at time.Time) error
+	CountOpenRequestsByAssignee(adminIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+
+	// Account data export and erasure
+	GetCustomRequestsForExport(userID uuid.UUID) ([]CustomRequest, error)
+	EraseUserData(userID uuid.UUID) error
 
 	// Bulk operations
 	BulkUpdateCustomRequestStatus(ids []uuid.UUID, status RequestStatus, assigneeID *uuid.UUID) error
@@ -143,6 +159,20 @@ func (r *repository) GetCustomRequestsByAssigneeID(assigneeID uuid.UUID, query C
 	return r.ListCustomRequests(query)
 }
 
+// GetQueue returns requests in the given statuses ordered by priority (urgent first), then oldest submission
+func (r *repository) GetQueue(statuses []RequestStatus, unassignedOnly bool) ([]CustomRequest, error) {
+	var requests []CustomRequest
+	db := r.db.Model(&CustomRequest{}).Where("status IN ?", statuses)
+	if unassignedOnly {
+		db = db.Where("assignee_id IS NULL")
+	}
+	err := db.Preload("Items").
+		Order(`CASE priority WHEN 'URGENT' THEN 0 WHEN 'HIGH' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'LOW' THEN 3 ELSE 2 END`).
+		Order("submitted_at ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
 // Request Item operations
 
 func (r *repository) CreateRequestItem(item *RequestItem) error {
@@ -372,6 +402,19 @@ func (r *repository) GetExpiredQuotes() ([]Quote, error) {
 	return quotes, err
 }
 
+// GetQuotesExpiringBefore returns sent, still-valid quotes lapsing before deadline that haven't been reminded yet
+func (r *repository) GetQuotesExpiringBefore(deadline time.Time) ([]Quote, error) {
+	var quotes []Quote
+	err := r.db.Where("status = ? AND reminder_sent_at IS NULL", QuoteSent).
+		Where("valid_until IS NOT NULL AND valid_until > ? AND valid_until <= ?", time.Now(), deadline).
+		Find(&quotes).Error
+	return quotes, err
+}
+
+func (r *repository) MarkQuoteReminderSent(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&Quote{}).Where("id = ?", id).UpdateColumn("reminder_sent_at", at).Error
+}
+
 // Bulk operations
 
 func (r *repository) BulkUpdateCustomRequestStatus(ids []uuid.UUID, status RequestStatus, assigneeID *uuid.UUID) error {
@@ -18,6 +18,7 @@ func SetupRoutes(api fiber.Router, handler *Handler, cfg *config.Config) {
 	customRequestRoutes.Delete("/:id", handler.DeleteCustomRequest)               // Delete custom request
 	customRequestRoutes.Delete("/:id/permanent-delete", handler.PermanentlyDeleteCustomRequest) // Permanently delete cancelled custom request
 	customRequestRoutes.Post("/:id/cancel", handler.CancelCustomRequest)          // Cancel custom request
+	customRequestRoutes.Post("/:id/duplicate", handler.DuplicateCustomRequest)    // Submit a copy of a past request
 	customRequestRoutes.Get("/", handler.ListUserCustomRequests)                 // List user's custom requests
 	customRequestRoutes.Post("/accept-quote", handler.AcceptQuote)               // Accept quote
 	customRequestRoutes.Post("/:id/accept", handler.AcceptQuoteByRequestID)        // Accept quote by request ID
@@ -31,7 +32,12 @@ func SetupAdminRoutes(adminRoutes fiber.Router, handler *Handler, cfg *config.Co
 	
 	// Admin endpoints
 	customRequestAdminRoutes.Get("/", handler.ListCustomRequestsAdmin)                    // List all custom requests
+	customRequestAdminRoutes.Get("/queue", handler.GetAdminQueue)                         // Work queue by priority and age
+	customRequestAdminRoutes.Get("/assignees", handler.ListAssignees)                     // Auto-assignment pool
+	customRequestAdminRoutes.Put("/assignees/:admin_id", handler.SetAssigneeAvailability) // Add to pool / set availability
+	customRequestAdminRoutes.Delete("/assignees/:admin_id", handler.RemoveAssignee)       // Remove from pool
 	customRequestAdminRoutes.Get("/:id", handler.GetCustomRequestAdmin)                   // Get custom request by ID (admin)
+	customRequestAdminRoutes.Get("/:id/suggestions", handler.GetCatalogSuggestions)       // Catalog matches for requested items
 	customRequestAdminRoutes.Put("/:id/status", handler.UpdateCustomRequestStatus)        // Update request status
 	customRequestAdminRoutes.Put("/:id/assign/:assignee_id", handler.AssignCustomRequest) // Assign request
 	customRequestAdminRoutes.Post("/quotes", handler.CreateQuote)                        // Create quote
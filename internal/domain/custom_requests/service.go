@@ -1,10 +1,13 @@
 package custom_requests
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"errandShop/internal/domain/notifications"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -31,6 +34,7 @@ type Service interface {
 	DeleteCustomRequest(userID uuid.UUID, requestID uuid.UUID) error
 	PermanentlyDeleteCustomRequest(userID uuid.UUID, requestID uuid.UUID) error
 	CancelCustomRequest(userID uuid.UUID, requestID uuid.UUID, reason string) (*CustomRequestRes, error)
+	DuplicateCustomRequest(userID uuid.UUID, requestID uuid.UUID) (*CustomRequestRes, error)
 	ListUserCustomRequests(userID uuid.UUID, query CustomRequestListQuery) (*CustomRequestListRes, error)
 	AcceptQuote(userID uuid.UUID, req AcceptQuoteReq) (*CustomRequestRes, error)
 	AcceptQuoteByRequestID(userID uuid.UUID, requestID uuid.UUID) (*CustomRequestRes, error)
@@ -56,7 +60,20 @@ type Service interface {
 	// Background tasks
 	ProcessExpiredRequests() error
 	ProcessExpiredQuotes() error
+	SendQuoteExpiryReminders(within time.Duration) (int, error)
+	SetNotificationService(notificationService notifications.NotificationService)
+	SetResponseSLA(targets map[RequestPriority]time.Duration)
+	GetAdminQueue(unassignedOnly bool) (*CustomRequestQueueRes, error)
+	SetCatalogSearch(catalog CatalogSearcher)
+	SetBudgetCapMode(mode BudgetCapMode) error
+	SetAutoAssignMode(mode AutoAssignMode) error
+	ListAssignees() (*AssigneePoolRes, error)
+	SetAssigneeAvailability(adminID uuid.UUID, available bool) (*AssigneeRes, error)
+	RemoveAssignee(adminID uuid.UUID) error
+	SuggestCatalogMatches(requestID uuid.UUID) (*CatalogSuggestionsRes, error)
 	CleanupOldMessages(olderThan time.Time) error
+	ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error)
+	EraseUserData(ctx context.Context, userID uuid.UUID) error
 
 	// Bulk operations
 	BulkUpdateStatus(req BulkUpdateStatusReq) (*BulkUpdateStatusRes, error)
@@ -64,11 +81,39 @@ type Service interface {
 }
 
 type service struct {
-	repo Repository
+	repo                Repository
+	notificationService notifications.NotificationService
+	responseSLA         map[RequestPriority]time.Duration // target time to first admin action, per priority
+	catalog             CatalogSearcher                   // product search for catalog suggestions; nil disables them
+	budgetCapMode       BudgetCapMode
+	autoAssignMode      AutoAssignMode // how new requests are handed to the assignee pool; off leaves them for triage
 }
 
 func NewService(repo Repository) Service {
-	return &service{repo: repo}
+	return &service{repo: repo, budgetCapMode: BudgetCapWarn, autoAssignMode: AutoAssignOff}
+}
+
+// SetNotificationService enables customer/admin notifications for quote reminders and expiry
+func (s *service) SetNotificationService(notificationService notifications.NotificationService) {
+	s.notificationService = notificationService
+}
+
+// notify sends an in-app/push notification if a notification service is configured; failures are logged only
+func (s *service) notify(recipientID uuid.UUID, recipientType notifications.NotificationRecipient, title, body string, data map[string]interface{}) {
+	if s.notificationService == nil {
+		return
+	}
+	req := &notifications.CreateNotificationRequest{
+		RecipientID:   recipientID,
+		RecipientType: recipientType,
+		Type:          notifications.TypeOrderUpdate,
+		Title:         title,
+		Body:          body,
+		Data:          data,
+	}
+	if _, err := s.notificationService.CreateNotification(req); err != nil {
+		log.Printf("custom requests: failed to notify %s %s: %v", recipientType, recipientID, err)
+	}
 }
 
 // User operations
@@ -83,6 +128,10 @@ func (s *service) CreateCustomRequest(userID uuid.UUID, req CreateCustomRequestR
 		return nil, ErrInvalidPriority
 	}
 
+	if req.BudgetCapKobo != nil && *req.BudgetCapKobo <= 0 {
+		return nil, ErrInvalidBudgetCap
+	}
+
 	// Set default priority if not provided
 	if req.Priority == "" {
 		req.Priority = PriorityMedium
@@ -97,6 +146,7 @@ func (s *service) CreateCustomRequest(userID uuid.UUID, req CreateCustomRequestR
 		Priority:           req.Priority,
 		AllowSubstitutions: req.AllowSubstitutions,
 		Notes:              req.Notes,
+		BudgetCapKobo:      req.BudgetCapKobo,
 		SubmittedAt:        time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -126,6 +176,8 @@ func (s *service) CreateCustomRequest(userID uuid.UUID, req CreateCustomRequestR
 	if err := s.repo.CreateCustomRequestWithItems(customRequest, items); err != nil {
 		return nil, fmt.Errorf("failed to create custom request: %w", err)
 	}
+	customRequest.Items = items
+	s.autoAssign(customRequest)
 
 	// Fetch the created request with details
 	createdRequest, err := s.repo.GetCustomRequestByIDWithDetails(customRequest.ID)
@@ -190,6 +242,16 @@ func (s *service) UpdateCustomRequest(userID uuid.UUID, requestID uuid.UUID, req
 		}
 		request.Priority = *req.Priority
 	}
+	if req.BudgetCapKobo != nil {
+		switch {
+		case *req.BudgetCapKobo < 0:
+			return nil, ErrInvalidBudgetCap
+		case *req.BudgetCapKobo == 0:
+			request.BudgetCapKobo = nil
+		default:
+			request.BudgetCapKobo = req.BudgetCapKobo
+		}
+	}
 
 	// Update items if provided
 	var items []RequestItem
@@ -319,6 +381,43 @@ func (s *service) CancelCustomRequest(userID uuid.UUID, requestID uuid.UUID, rea
 	return &res, nil
 }
 
+// DuplicateCustomRequest submits a fresh request with the same items as one of the caller's
+// past requests. Quotes, messages, admin notes and quoted prices are not carried over.
+func (s *service) DuplicateCustomRequest(userID uuid.UUID, requestID uuid.UUID) (*CustomRequestRes, error) {
+	source, err := s.repo.GetCustomRequestByIDWithDetails(requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get custom request: %w", err)
+	}
+
+	if source.UserID != userID {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	req := CreateCustomRequestReq{
+		DeliveryAddressID:  source.DeliveryAddressID,
+		AllowSubstitutions: source.AllowSubstitutions,
+		Notes:              source.Notes,
+		Priority:           source.Priority,
+		BudgetCapKobo:      source.BudgetCapKobo,
+	}
+	for _, item := range source.Items {
+		req.Items = append(req.Items, CreateRequestItem{
+			Name:           item.Name,
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			Unit:           item.Unit,
+			PreferredBrand: item.PreferredBrand,
+			EstimatedPrice: item.EstimatedPrice,
+			Images:         item.Images,
+		})
+	}
+
+	return s.CreateCustomRequest(userID, req)
+}
+
 func (s *service) ListUserCustomRequests(userID uuid.UUID, query CustomRequestListQuery) (*CustomRequestListRes, error) {
 	requests, total, err := s.repo.GetCustomRequestsByUserID(userID, query)
 	if err != nil {
@@ -531,6 +630,51 @@ func (s *service) ListCustomRequestsAdmin(query CustomRequestListQuery) (*Custom
 	}, nil
 }
 
+// SetResponseSLA sets the response target per priority; priorities without a positive target are never flagged
+func (s *service) SetResponseSLA(targets map[RequestPriority]time.Duration) {
+	s.responseSLA = targets
+}
+
+// queueStatuses are the statuses still waiting on an admin
+var queueStatuses = []RequestStatus{RequestSubmitted, RequestUnderReview}
+
+// GetAdminQueue lists requests waiting on an admin, most urgent first, with their age against the response SLA
+func (s *service) GetAdminQueue(unassignedOnly bool) (*CustomRequestQueueRes, error) {
+	requests, err := s.repo.GetQueue(queueStatuses, unassignedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom request queue: %w", err)
+	}
+
+	now := time.Now()
+	res := &CustomRequestQueueRes{Data: make([]CustomRequestQueueItemRes, 0, len(requests))}
+	for _, req := range requests {
+		item := CustomRequestQueueItemRes{
+			ID:          req.ID,
+			UserID:      req.UserID,
+			Status:      req.Status,
+			Priority:    req.Priority,
+			AssigneeID:  req.AssigneeID,
+			ItemCount:   len(req.Items),
+			SubmittedAt: req.SubmittedAt,
+			BudgetCapKobo: req.BudgetCapKobo,
+			AgeMinutes:  int64(now.Sub(req.SubmittedAt) / time.Minute),
+		}
+		if target := s.responseSLA[req.Priority]; target > 0 {
+			due := req.SubmittedAt.Add(target)
+			item.SLATargetMinutes = int64(target / time.Minute)
+			item.SLADueAt = &due
+			item.SLABreached = now.After(due)
+		}
+		if item.SLABreached {
+			res.Breached++
+		}
+		res.Data = append(res.Data, item)
+	}
+	res.Total = len(res.Data)
+
+	return res, nil
+}
+
 func (s *service) UpdateCustomRequestStatus(requestID uuid.UUID, req UpdateRequestStatusReq) (*CustomRequestRes, error) {
 	customRequest, err := s.repo.GetCustomRequestByID(requestID)
 	if err != nil {
@@ -636,6 +780,9 @@ func (s *service) CreateQuote(adminID uuid.UUID, req CreateQuoteReq) (*QuoteRes,
 		UpdatedAt:       time.Now(),
 	}
 	quote.CalculateTotal()
+	if err := s.checkBudget(customRequest, quote.GrandTotal); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.CreateQuote(quote); err != nil {
 		return nil, fmt.Errorf("failed to create quote: %w", err)
@@ -678,6 +825,7 @@ func (s *service) CreateQuote(adminID uuid.UUID, req CreateQuoteReq) (*QuoteRes,
 	}
 
 	res := quoteWithItems.ToQuoteRes()
+	customRequest.annotateBudget(&res)
 	return &res, nil
 }
 
@@ -707,6 +855,14 @@ func (s *service) UpdateQuote(quoteID uuid.UUID, req CreateQuoteReq) (*QuoteRes,
 	quote.ValidUntil = req.ValidUntil
 	quote.CalculateTotal()
 
+	customRequest, err := s.repo.GetCustomRequestByID(quote.CustomRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom request: %w", err)
+	}
+	if err := s.checkBudget(customRequest, quote.GrandTotal); err != nil {
+		return nil, err
+	}
+
 	if err := s.repo.UpdateQuote(quote); err != nil {
 		return nil, fmt.Errorf("failed to update quote: %w", err)
 	}
@@ -750,6 +906,7 @@ func (s *service) UpdateQuote(quoteID uuid.UUID, req CreateQuoteReq) (*QuoteRes,
 	}
 
 	res := updatedQuote.ToQuoteRes()
+	customRequest.annotateBudget(&res)
 	return &res, nil
 }
 
@@ -767,18 +924,26 @@ func (s *service) SendQuote(quoteID uuid.UUID) (*QuoteRes, error) {
 		return nil, ErrInvalidQuoteStatus
 	}
 
+	// The customer may have changed their cap since the quote was drafted
+	customRequest, err := s.repo.GetCustomRequestByID(quote.CustomRequestID)
+	if err == nil {
+		if err := s.checkBudget(customRequest, quote.GrandTotal); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update quote status
 	now := time.Now()
 	quote.Status = QuoteSent
 	quote.SentAt = &now
+	quote.ReminderSentAt = nil // a (re)sent quote gets its own pre-expiry reminder
 
 	if err := s.repo.UpdateQuote(quote); err != nil {
 		return nil, fmt.Errorf("failed to send quote: %w", err)
 	}
 
 	// Update custom request status
-	customRequest, err := s.repo.GetCustomRequestByID(quote.CustomRequestID)
-	if err == nil {
+	if customRequest != nil {
 		customRequest.Status = RequestQuoteSent
 		if updateErr := s.repo.UpdateCustomRequest(customRequest); updateErr != nil {
 			// Log error but don't fail the quote sending
@@ -787,6 +952,9 @@ func (s *service) SendQuote(quoteID uuid.UUID) (*QuoteRes, error) {
 	}
 
 	res := quote.ToQuoteRes()
+	if customRequest != nil {
+		customRequest.annotateBudget(&res)
+	}
 	return &res, nil
 }
 
@@ -861,15 +1029,68 @@ func (s *service) ProcessExpiredQuotes() error {
 
 		// Update custom request status back to under review
 		customRequest, err := s.repo.GetCustomRequestByID(quote.CustomRequestID)
-		if err == nil {
-			customRequest.Status = RequestUnderReview
-			s.repo.UpdateCustomRequest(customRequest) // Ignore error
+		if err != nil {
+			continue
+		}
+		customRequest.Status = RequestUnderReview
+		s.repo.UpdateCustomRequest(customRequest) // Ignore error
+
+		data := map[string]interface{}{
+			"customRequestId": quote.CustomRequestID.String(),
+			"quoteId":         quote.ID.String(),
+			"reason":          "quote_expired",
+		}
+		s.notify(customRequest.UserID, notifications.RecipientCustomer,
+			"Your quote has expired",
+			"The quote for your custom request expired before it was accepted. Message us if you'd still like these items and we'll send a fresh quote.",
+			data)
+		if customRequest.AssigneeID != nil {
+			s.notify(*customRequest.AssigneeID, notifications.RecipientAdmin,
+				"Quote expired",
+				fmt.Sprintf("Quote %s expired without being accepted; the request is back under review.", quote.ID),
+				data)
 		}
 	}
 
 	return nil
 }
 
+// SendQuoteExpiryReminders reminds customers about sent quotes that lapse within the given window.
+// Each quote is reminded at most once (tracked by ReminderSentAt). Returns how many reminders were sent.
+func (s *service) SendQuoteExpiryReminders(within time.Duration) (int, error) {
+	quotes, err := s.repo.GetQuotesExpiringBefore(time.Now().Add(within))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expiring quotes: %w", err)
+	}
+
+	sent := 0
+	for _, quote := range quotes {
+		customRequest, err := s.repo.GetCustomRequestByID(quote.CustomRequestID)
+		if err != nil {
+			continue
+		}
+
+		s.notify(customRequest.UserID, notifications.RecipientCustomer,
+			"Your quote expires soon",
+			fmt.Sprintf("Your quote of ₦%.2f expires on %s. Accept it before then to place your order.",
+				float64(quote.GrandTotal)/100, quote.ValidUntil.Format("Jan 2, 3:04 PM")),
+			map[string]interface{}{
+				"customRequestId": quote.CustomRequestID.String(),
+				"quoteId":         quote.ID.String(),
+				"validUntil":      quote.ValidUntil.Format(time.RFC3339),
+				"reason":          "quote_expiring",
+			})
+
+		if err := s.repo.MarkQuoteReminderSent(quote.ID, time.Now()); err != nil {
+			log.Printf("custom requests: failed to record reminder for quote %s: %v", quote.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
 func (s *service) CleanupOldMessages(olderThan time.Time) error {
 	// This would require a new repository method to delete messages older than a certain date
 	// For now, we'll leave this as a placeholder
@@ -0,0 +1,45 @@
+package customers
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportUserData returns the user's customer profile with their saved addresses, or nil if they
+// never had one. Unlike GetCustomerByUserID it does not create a missing profile.
+func (s *service) ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error) {
+	customer, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if err.Error() == "customer not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.toCustomerResponse(customer), nil
+}
+
+// EraseUserData blanks the user's customer profile and deletes their saved addresses
+func (s *service) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.EraseByUserID(userID)
+}
+
+// EraseByUserID blanks the personal fields of the user's customer profile, marking it inactive,
+// and deletes their addresses
+func (r *repository) EraseByUserID(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Customer{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"first_name":    "",
+			"last_name":     "",
+			"phone":         "",
+			"date_of_birth": nil,
+			"gender":        "",
+			"avatar":        "",
+			"status":        CustomerStatusInactive,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&Address{}).Error
+	})
+}
@@ -21,6 +21,7 @@ type Repository interface {
 	UpdateAddress(address *Address) error
 	DeleteAddress(id uint) error
 	SetDefaultAddress(customerID, addressID uint) error
+	EraseByUserID(userID uuid.UUID) error
 }
 
 type repository struct {
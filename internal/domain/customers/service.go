@@ -1,6 +1,7 @@
 package customers
 
 import (
+	"context"
 	"errors"
 	"log"
 	"github.com/google/uuid"
@@ -20,6 +21,10 @@ type Service interface {
 	UpdateAddress(customerID, addressID uint, req *CreateAddressRequest) (*AddressResponse, error)
 	DeleteAddress(customerID, addressID uint) error
 	SetDefaultAddress(customerID, addressID uint) error
+
+	// Account data export and erasure (see account_data.go)
+	ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error)
+	EraseUserData(ctx context.Context, userID uuid.UUID) error
 }
 
 type service struct {
@@ -0,0 +1,112 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProofAssetStore deletes hosted proof-of-delivery photos and signatures
+type ProofAssetStore interface {
+	PublicIDFromURL(rawURL string) (string, bool)
+	DeleteImage(publicID string) error
+}
+
+// SetProofAssetStore enables deleting hosted proof photos and signatures when a customer erases
+// their data; without it only the links are removed
+func (s *deliveryService) SetProofAssetStore(store ProofAssetStore) {
+	s.proofAssets = store
+}
+
+// ExportUserData returns the deliveries of the customer's orders, newest first
+func (s *deliveryService) ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error) {
+	deliveries, err := s.repo.GetDeliveriesByCustomer(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deliveries: %w", err)
+	}
+
+	responses := make([]DeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *s.mapDeliveryToResponse(&deliveries[i])
+	}
+	return responses, nil
+}
+
+// EraseUserData blanks the address, drop-off coordinates, recipient and proof of delivery on the
+// deliveries of the customer's orders and deletes the hosted proof photos and signatures. Fees,
+// distances and drivers stay so driver earnings still add up. A proof asset that cannot be deleted
+// is logged; its link is gone either way.
+func (s *deliveryService) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	proofURLs, err := s.repo.EraseCustomerDeliveries(userID)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize deliveries: %w", err)
+	}
+	if s.proofAssets == nil {
+		return nil
+	}
+	for _, proofURL := range proofURLs {
+		publicID, ok := s.proofAssets.PublicIDFromURL(proofURL)
+		if !ok {
+			continue
+		}
+		if err := s.proofAssets.DeleteImage(publicID); err != nil {
+			log.Printf("Warning: failed to delete proof of delivery %s: %v", publicID, err)
+		}
+	}
+	return nil
+}
+
+// customerOrderIDs selects the IDs of the customer's orders, for matching their deliveries
+func customerOrderIDs(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Table("orders").Select("id").Where("customer_id = ?", userID)
+}
+
+// GetDeliveriesByCustomer returns the deliveries of the customer's orders, newest first
+func (r *deliveryRepository) GetDeliveriesByCustomer(userID uuid.UUID) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := r.db.Where("order_id IN (?)", customerOrderIDs(r.db, userID)).
+		Order("created_at DESC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// EraseCustomerDeliveries blanks the personal fields on the deliveries of the customer's orders and
+// returns the proof photo and signature URLs that were on them
+func (r *deliveryRepository) EraseCustomerDeliveries(userID uuid.UUID) ([]string, error) {
+	var proofURLs []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var proofs []struct {
+			ProofPhotoURL     string
+			ProofSignatureURL string
+		}
+		if err := tx.Model(&Delivery{}).
+			Select("proof_photo_url, proof_signature_url").
+			Where("order_id IN (?)", customerOrderIDs(tx, userID)).
+			Scan(&proofs).Error; err != nil {
+			return err
+		}
+		for _, p := range proofs {
+			for _, u := range []string{p.ProofPhotoURL, p.ProofSignatureURL} {
+				if u != "" {
+					proofURLs = append(proofURLs, u)
+				}
+			}
+		}
+
+		return tx.Model(&Delivery{}).Where("order_id IN (?)", customerOrderIDs(tx, userID)).Updates(map[string]interface{}{
+			"delivery_address":     "",
+			"delivery_latitude":    nil,
+			"delivery_longitude":   nil,
+			"delivery_notes":       "",
+			"recipient_name":       "",
+			"recipient_phone":      "",
+			"proof_photo_url":      "",
+			"proof_signature_url":  "",
+			"proof_recipient_name": "",
+		}).Error
+	})
+	return proofURLs, err
+}
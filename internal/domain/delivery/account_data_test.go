@@ -0,0 +1,106 @@
+package delivery_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	delivery "errandShop/internal/domain/delivery"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeProofStore treats URLs under https://cdn.test/ as hosted and records deletions
+type fakeProofStore struct {
+	deleted []string
+}
+
+func (f *fakeProofStore) PublicIDFromURL(rawURL string) (string, bool) {
+	if !strings.HasPrefix(rawURL, "https://cdn.test/") {
+		return "", false
+	}
+	return strings.TrimPrefix(rawURL, "https://cdn.test/"), true
+}
+
+func (f *fakeProofStore) DeleteImage(publicID string) error {
+	f.deleted = append(f.deleted, publicID)
+	return nil
+}
+
+func TestEraseUserDataBlanksDeliveriesAndDeletesProof(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL)`,
+		`CREATE TABLE deliveries (
+			id INTEGER PRIMARY KEY,
+			order_id TEXT NOT NULL,
+			delivery_address TEXT NOT NULL,
+			delivery_latitude REAL,
+			delivery_longitude REAL,
+			delivery_notes TEXT,
+			recipient_name TEXT NOT NULL,
+			recipient_phone TEXT NOT NULL,
+			proof_photo_url TEXT,
+			proof_signature_url TEXT,
+			proof_recipient_name TEXT,
+			delivery_fee INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	userID, otherID := uuid.New(), uuid.New()
+	insert := func(id int, customerID uuid.UUID) {
+		orderID := uuid.New()
+		if err := db.Exec(`INSERT INTO orders (id, customer_id) VALUES (?, ?)`, orderID, customerID).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+		if err := db.Exec(`INSERT INTO deliveries (id, order_id, delivery_address, delivery_latitude, delivery_longitude, recipient_name, recipient_phone,
+			proof_photo_url, proof_signature_url, proof_recipient_name, delivery_fee)
+			VALUES (?, ?, '12 Allen Ave', 6.6, 3.35, 'Ada', '08030000000', ?, 'https://elsewhere.test/sig.png', 'Ada', 150000)`,
+			id, orderID, "https://cdn.test/proof-"+customerID.String()).Error; err != nil {
+			t.Fatalf("failed to insert delivery: %v", err)
+		}
+	}
+	insert(1, userID)
+	insert(2, otherID)
+
+	store := &fakeProofStore{}
+	s := delivery.NewDeliveryService(delivery.NewDeliveryRepository(db), nil, nil, nil)
+	s.SetProofAssetStore(store)
+	if err := s.EraseUserData(context.Background(), userID); err != nil {
+		t.Fatalf("EraseUserData: %v", err)
+	}
+
+	var rows []struct {
+		ID                                             int
+		DeliveryAddress, RecipientName, RecipientPhone string
+		ProofPhotoURL, ProofSignatureURL               string
+		DeliveryLatitude                               *float64
+		DeliveryFee                                    int64
+	}
+	db.Raw(`SELECT id, delivery_address, recipient_name, recipient_phone, proof_photo_url, proof_signature_url, delivery_latitude, delivery_fee
+		FROM deliveries ORDER BY id`).Scan(&rows)
+	erased, kept := rows[0], rows[1]
+	if erased.DeliveryAddress != "" || erased.RecipientName != "" || erased.RecipientPhone != "" ||
+		erased.ProofPhotoURL != "" || erased.ProofSignatureURL != "" || erased.DeliveryLatitude != nil {
+		t.Fatalf("delivery not anonymized: %+v", erased)
+	}
+	if erased.DeliveryFee != 150000 {
+		t.Fatalf("delivery fee = %d, want it kept for driver earnings", erased.DeliveryFee)
+	}
+	if kept.RecipientName != "Ada" || kept.ProofPhotoURL == "" {
+		t.Fatalf("another customer's delivery was touched: %+v", kept)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "proof-"+userID.String() {
+		t.Fatalf("deleted %v, want only the erased customer's hosted proof photo", store.deleted)
+	}
+}
@@ -0,0 +1,202 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrDeliveryNotBatchable is returned when a selected delivery is not pending or already rides in a batch
+	ErrDeliveryNotBatchable = errors.New("delivery is not pending or is already in a batch")
+	// ErrDriverNotAvailable is returned when batching for an inactive or busy driver
+	ErrDriverNotAvailable = errors.New("driver is not available")
+)
+
+// CreateBatch assigns the selected pending deliveries to one driver as a single trip and plans the
+// stop order by proximity, starting from the requested point or the driver's last known location.
+func (s *deliveryService) CreateBatch(req *CreateBatchRequest) (*DeliveryBatchResponse, error) {
+	driver, err := s.repo.GetDriverByID(req.DriverID)
+	if err != nil {
+		return nil, err
+	}
+	if !driver.IsActive || !driver.IsAvailable {
+		return nil, ErrDriverNotAvailable
+	}
+
+	ids := make([]uint, 0, len(req.DeliveryIDs))
+	seen := make(map[uint]bool, len(req.DeliveryIDs))
+	for _, id := range req.DeliveryIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	deliveries, err := s.repo.GetDeliveriesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) != len(ids) {
+		return nil, fmt.Errorf("%w: some deliveries were not found", ErrDeliveryNotBatchable)
+	}
+	// Keep the admin's selection order as the tie-break for stops without coordinates
+	byID := make(map[uint]Delivery, len(deliveries))
+	for _, d := range deliveries {
+		if d.Status != DeliveryStatusPending || d.BatchID != nil {
+			return nil, fmt.Errorf("%w: delivery %d", ErrDeliveryNotBatchable, d.ID)
+		}
+		byID[d.ID] = d
+	}
+	selected := make([]Delivery, len(ids))
+	for i, id := range ids {
+		selected[i] = byID[id]
+	}
+
+	startLat, startLng := req.StartLatitude, req.StartLongitude
+	if startLat == nil || startLng == nil {
+		startLat, startLng = driver.CurrentLatitude, driver.CurrentLongitude
+	}
+
+	stops := OrderStopsByProximity(startLat, startLng, selected)
+	batch := &DeliveryBatch{
+		DriverID:       driver.ID,
+		Status:         BatchStatusActive,
+		StartLatitude:  startLat,
+		StartLongitude: startLng,
+		TotalDistance:  routeDistance(startLat, startLng, stops),
+	}
+	if err := s.repo.CreateBatch(batch, stops); err != nil {
+		return nil, err
+	}
+
+	return s.GetBatch(batch.ID)
+}
+
+// GetBatch returns a batch with its stops in route order
+func (s *deliveryService) GetBatch(id uint) (*DeliveryBatchResponse, error) {
+	batch, err := s.repo.GetBatchByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapBatchToResponse(batch), nil
+}
+
+// GetDriverRoute returns the driver's current trip, stops in the order they should be visited
+func (s *deliveryService) GetDriverRoute(driverID uint) (*DeliveryBatchResponse, error) {
+	batch, err := s.repo.GetActiveBatchByDriver(driverID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapBatchToResponse(batch), nil
+}
+
+// completeBatchStop closes the delivery's batch once its last open stop is finished
+func (s *deliveryService) completeBatchStop(delivery *Delivery) {
+	if delivery.BatchID == nil {
+		return
+	}
+	switch delivery.Status {
+	case DeliveryStatusDelivered, DeliveryStatusCancelled, DeliveryStatusReturned:
+		if err := s.repo.CompleteBatchIfDone(*delivery.BatchID); err != nil {
+			fmt.Printf("Failed to update delivery batch %d: %v\n", *delivery.BatchID, err)
+		}
+	}
+}
+
+// OrderStopsByProximity orders deliveries by repeatedly visiting the nearest unvisited drop-off,
+// starting from the given point (or the first located stop when there is none). Deliveries without
+// coordinates keep their relative order and go last.
+func OrderStopsByProximity(startLat, startLng *float64, deliveries []Delivery) []Delivery {
+	var located, unlocated []Delivery
+	for _, d := range deliveries {
+		if d.DeliveryLatitude != nil && d.DeliveryLongitude != nil {
+			located = append(located, d)
+		} else {
+			unlocated = append(unlocated, d)
+		}
+	}
+
+	ordered := make([]Delivery, 0, len(deliveries))
+	if len(located) > 0 && (startLat == nil || startLng == nil) {
+		ordered = append(ordered, located[0])
+		located = located[1:]
+		startLat, startLng = ordered[0].DeliveryLatitude, ordered[0].DeliveryLongitude
+	}
+
+	lat, lng := startLat, startLng
+	for len(located) > 0 {
+		nearest, best := 0, math.MaxFloat64
+		for i, d := range located {
+			if dist := haversineKm(*lat, *lng, *d.DeliveryLatitude, *d.DeliveryLongitude); dist < best {
+				nearest, best = i, dist
+			}
+		}
+		next := located[nearest]
+		ordered = append(ordered, next)
+		located = append(located[:nearest], located[nearest+1:]...)
+		lat, lng = next.DeliveryLatitude, next.DeliveryLongitude
+	}
+
+	return append(ordered, unlocated...)
+}
+
+// routeDistance sums the straight-line legs between located stops, starting from the given point
+func routeDistance(startLat, startLng *float64, stops []Delivery) float64 {
+	total := 0.0
+	lat, lng := startLat, startLng
+	for _, stop := range stops {
+		if stop.DeliveryLatitude == nil || stop.DeliveryLongitude == nil {
+			continue
+		}
+		if lat != nil && lng != nil {
+			total += haversineKm(*lat, *lng, *stop.DeliveryLatitude, *stop.DeliveryLongitude)
+		}
+		lat, lng = stop.DeliveryLatitude, stop.DeliveryLongitude
+	}
+	return math.Round(total*100) / 100
+}
+
+func (s *deliveryService) mapBatchToResponse(batch *DeliveryBatch) *DeliveryBatchResponse {
+	response := &DeliveryBatchResponse{
+		ID:             batch.ID,
+		DriverID:       batch.DriverID,
+		Status:         batch.Status,
+		StartLatitude:  batch.StartLatitude,
+		StartLongitude: batch.StartLongitude,
+		TotalDistance:  batch.TotalDistance,
+		Stops:          make([]BatchStopResponse, 0, len(batch.Deliveries)),
+		CompletedAt:    batch.CompletedAt,
+		CreatedAt:      batch.CreatedAt,
+	}
+
+	prevLat, prevLng := batch.StartLatitude, batch.StartLongitude
+	for i, d := range batch.Deliveries {
+		stop := BatchStopResponse{
+			Sequence:        i + 1,
+			DeliveryID:      d.ID,
+			OrderID:         d.OrderID,
+			TrackingNumber:  d.TrackingNumber,
+			Status:          d.Status,
+			DeliveryAddress: d.DeliveryAddress,
+			Latitude:        d.DeliveryLatitude,
+			Longitude:       d.DeliveryLongitude,
+			RecipientName:   d.RecipientName,
+			RecipientPhone:  d.RecipientPhone,
+			DeliveryNotes:   d.DeliveryNotes,
+		}
+		if d.BatchSequence != nil {
+			stop.Sequence = *d.BatchSequence
+		}
+		if prevLat != nil && prevLng != nil && d.DeliveryLatitude != nil && d.DeliveryLongitude != nil {
+			dist := math.Round(haversineKm(*prevLat, *prevLng, *d.DeliveryLatitude, *d.DeliveryLongitude)*100) / 100
+			stop.DistanceFromPrev = &dist
+		}
+		if d.DeliveryLatitude != nil && d.DeliveryLongitude != nil {
+			prevLat, prevLng = d.DeliveryLatitude, d.DeliveryLongitude
+		}
+		response.Stops = append(response.Stops, stop)
+	}
+
+	return response
+}
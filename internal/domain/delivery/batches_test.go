@@ -0,0 +1,46 @@
+package delivery_test
+
+import (
+	"testing"
+
+	delivery "errandShop/internal/domain/delivery"
+)
+
+func stop(id uint, lat, lng float64) delivery.Delivery {
+	return delivery.Delivery{ID: id, DeliveryLatitude: &lat, DeliveryLongitude: &lng}
+}
+
+func TestOrderStopsByProximity(t *testing.T) {
+	startLat, startLng := 6.4300, 3.4200 // Victoria Island
+	stops := []delivery.Delivery{
+		stop(1, 6.6018, 3.3515), // Ikeja, furthest
+		{ID: 2},                 // no coordinates
+		stop(3, 6.4474, 3.4722), // Lekki Phase 1, nearest
+		stop(4, 6.5244, 3.3792), // Yaba, in between
+	}
+
+	got := delivery.OrderStopsByProximity(&startLat, &startLng, stops)
+	want := []uint{3, 4, 1, 2}
+	for i, d := range got {
+		if d.ID != want[i] {
+			t.Fatalf("stop %d = delivery %d, want %d (order %v)", i+1, d.ID, want[i], ids(got))
+		}
+	}
+
+	// Without a start point the first located stop is visited first
+	got = delivery.OrderStopsByProximity(nil, nil, stops)
+	want = []uint{1, 4, 3, 2}
+	for i, d := range got {
+		if d.ID != want[i] {
+			t.Fatalf("without start: stop %d = delivery %d, want %d (order %v)", i+1, d.ID, want[i], ids(got))
+		}
+	}
+}
+
+func ids(deliveries []delivery.Delivery) []uint {
+	out := make([]uint, len(deliveries))
+	for i, d := range deliveries {
+		out[i] = d.ID
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"errandShop/internal/domain/orders"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNoOutstandingCash is returned when remitting for a driver who holds no outstanding cash
+	ErrNoOutstandingCash = errors.New("driver has no outstanding cash")
+	// ErrCashNotOutstanding is returned when a selected collection is already remitted or belongs to another driver
+	ErrCashNotOutstanding = errors.New("cash collection is not outstanding")
+)
+
+// recordCashCollection notes the cash a driver should now hold for a cash-on-delivery order that
+// has just been delivered. Deliveries handed to a third-party provider have no driver and are skipped.
+func (s *deliveryService) recordCashCollection(delivery *Delivery) {
+	if s.ordersRepo == nil || delivery.DriverID == nil {
+		return
+	}
+	orderUUID, err := uuid.Parse(delivery.OrderID)
+	if err != nil {
+		return
+	}
+	order, err := s.ordersRepo.AdminGet(context.Background(), orderUUID)
+	if err != nil {
+		fmt.Printf("Failed to load order %s for cash collection: %v\n", delivery.OrderID, err)
+		return
+	}
+	if order.PaymentMethod != orders.PaymentMethodCashOnDelivery || order.PaymentStatus == orders.PaymentStatusPaid {
+		return
+	}
+
+	collectedAt := time.Now()
+	if delivery.DeliveryTime != nil {
+		collectedAt = *delivery.DeliveryTime
+	}
+	collection := &CashCollection{
+		DeliveryID:  delivery.ID,
+		OrderID:     delivery.OrderID,
+		DriverID:    *delivery.DriverID,
+		AmountKobo:  order.TotalAmount,
+		Status:      CashCollectionOutstanding,
+		CollectedAt: collectedAt,
+	}
+	if err := s.repo.CreateCashCollection(collection); err != nil {
+		fmt.Printf("Failed to record cash collection for delivery %d: %v\n", delivery.ID, err)
+	}
+}
+
+// GetOutstandingCash reports the cash each driver has collected but not yet remitted, or just one
+// driver's when driverID is set
+func (s *deliveryService) GetOutstandingCash(driverID *uint) (*OutstandingCashReport, error) {
+	if driverID != nil {
+		if _, err := s.repo.GetDriverByID(*driverID); err != nil {
+			return nil, err
+		}
+	}
+	collections, err := s.repo.GetOutstandingCash(driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OutstandingCashReport{
+		Drivers:     SummarizeOutstandingCash(collections),
+		GeneratedAt: time.Now(),
+	}
+	for i := range report.Drivers {
+		balance := &report.Drivers[i]
+		if driver, err := s.repo.GetDriverByID(balance.DriverID); err == nil {
+			balance.DriverName = driver.FirstName
+			balance.VehicleNumber = driver.VehicleNumber
+		}
+		report.TotalOutstandingKobo += balance.OutstandingKobo
+	}
+	return report, nil
+}
+
+// RemitDriverCash records that a driver has handed in cash: the selected collections, or everything
+// they hold when none are selected
+func (s *deliveryService) RemitDriverCash(driverID uint, adminID uuid.UUID, req *RemitCashRequest) (*CashRemittanceResponse, error) {
+	if _, err := s.repo.GetDriverByID(driverID); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(req.CollectionIDs))
+	seen := make(map[uint]bool, len(req.CollectionIDs))
+	for _, id := range req.CollectionIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	collections, err := s.repo.RemitCash(driverID, ids, adminID, req.Reference, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CashRemittanceResponse{
+		DriverID:    driverID,
+		Reference:   req.Reference,
+		Collections: collections,
+	}
+	for _, c := range collections {
+		response.TotalKobo += c.AmountKobo
+		if c.RemittedAt != nil {
+			response.RemittedAt = *c.RemittedAt
+		}
+	}
+	response.CollectionCount = len(collections)
+	return response, nil
+}
+
+// SummarizeOutstandingCash groups outstanding collections by driver, keeping the order drivers
+// first appear in
+func SummarizeOutstandingCash(collections []CashCollection) []DriverCashBalance {
+	balances := make([]DriverCashBalance, 0)
+	index := make(map[uint]int)
+	for _, c := range collections {
+		if c.Status != CashCollectionOutstanding {
+			continue
+		}
+		i, ok := index[c.DriverID]
+		if !ok {
+			i = len(balances)
+			index[c.DriverID] = i
+			balances = append(balances, DriverCashBalance{DriverID: c.DriverID, OldestCollectedAt: c.CollectedAt})
+		}
+		balance := &balances[i]
+		balance.OutstandingCount++
+		balance.OutstandingKobo += c.AmountKobo
+		if c.CollectedAt.Before(balance.OldestCollectedAt) {
+			balance.OldestCollectedAt = c.CollectedAt
+		}
+		balance.Collections = append(balance.Collections, c)
+	}
+	return balances
+}
@@ -0,0 +1,36 @@
+package delivery_test
+
+import (
+	"testing"
+	"time"
+
+	delivery "errandShop/internal/domain/delivery"
+)
+
+func TestSummarizeOutstandingCash(t *testing.T) {
+	day := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	collections := []delivery.CashCollection{
+		{ID: 1, DriverID: 7, AmountKobo: 1_250_000, Status: delivery.CashCollectionOutstanding, CollectedAt: day.Add(2 * time.Hour)},
+		{ID: 2, DriverID: 3, AmountKobo: 800_000, Status: delivery.CashCollectionOutstanding, CollectedAt: day.Add(time.Hour)},
+		{ID: 3, DriverID: 7, AmountKobo: 450_000, Status: delivery.CashCollectionOutstanding, CollectedAt: day},
+		{ID: 4, DriverID: 3, AmountKobo: 999_999, Status: delivery.CashCollectionRemitted, CollectedAt: day},
+	}
+
+	balances := delivery.SummarizeOutstandingCash(collections)
+	if len(balances) != 2 {
+		t.Fatalf("expected 2 drivers, got %d", len(balances))
+	}
+
+	first := balances[0]
+	if first.DriverID != 7 || first.OutstandingCount != 2 || first.OutstandingKobo != 1_700_000 {
+		t.Fatalf("driver 7: got %+v", first)
+	}
+	if !first.OldestCollectedAt.Equal(day) {
+		t.Fatalf("driver 7: oldest collection = %v, want %v", first.OldestCollectedAt, day)
+	}
+
+	second := balances[1]
+	if second.DriverID != 3 || second.OutstandingCount != 1 || second.OutstandingKobo != 800_000 {
+		t.Fatalf("driver 3: remitted cash should not count, got %+v", second)
+	}
+}
@@ -1,6 +1,8 @@
 package delivery
 
 import (
+	"time"
+
 	"errandShop/config"
 	"errandShop/internal/middleware"
 
@@ -12,8 +14,13 @@ func SetupDeliveryRoutes(app *fiber.App, handler *DeliveryHandler, cfg *config.C
 	// Public routes
 	public := app.Group("/api/v1/delivery")
 	public.Post("/quote", handler.GetDeliveryQuote)
-	public.Get("/track/:tracking_number", handler.GetDeliveryByTrackingNumber)
-	
+
+	// Shareable tracking link for people without an account; sanitized and rate limited per IP.
+	// The older /delivery/track path serves the same view, never the full delivery.
+	trackLimit := middleware.NewRateLimiter(30, time.Minute).Middleware()
+	app.Get("/api/v1/track/:tracking_number", trackLimit, handler.TrackPublic)
+	public.Get("/track/:tracking_number", trackLimit, handler.TrackPublic)
+
 	// Order confirmation route (separate group for correct path)
 	orders := app.Group("/api/v1/orders")
 	orders.Post("/confirm", handler.ConfirmOrder)
@@ -30,6 +37,9 @@ func SetupDeliveryRoutes(app *fiber.App, handler *DeliveryHandler, cfg *config.C
 	protected.Get("/:id", handler.GetDelivery)
 	protected.Get("/order/:order_id", handler.GetDeliveryByOrderID)
 	protected.Get("/:id/tracking", handler.GetTrackingUpdates)
+	protected.Get("/drivers/:id/earnings", middleware.RBACMiddleware("admin", "superadmin"), handler.GetDriverEarnings)
+	protected.Post("/:id/failed-attempt", middleware.RBACMiddleware("admin", "superadmin"), handler.RecordFailedAttempt)
+	protected.Get("/drivers/:id/route", middleware.RBACMiddleware("admin", "superadmin"), handler.GetDriverRoute)
 
 	// Admin routes (for managing third-party logistics)
 	admin := app.Group("/api/v1/delivery/admin")
@@ -42,11 +52,38 @@ func SetupDeliveryRoutes(app *fiber.App, handler *DeliveryHandler, cfg *config.C
 	admin.Put("/deliveries/:id/assign-provider", handler.AssignLogisticsProvider)
 	admin.Put("/deliveries/:id/cancel", handler.CancelDelivery)
 
+	// Multi-drop trips
+	admin.Post("/batches", handler.CreateBatch)
+	admin.Get("/batches/:id", handler.GetBatch)
+
+	// Cash-on-delivery reconciliation
+	admin.Get("/cash/outstanding", handler.GetOutstandingCash)
+	admin.Post("/drivers/:id/cash/remit", handler.RemitDriverCash)
+
+	// Surge pricing windows
+	admin.Get("/surge-windows", handler.ListSurgeWindows)
+	admin.Post("/surge-windows", handler.CreateSurgeWindow)
+	admin.Delete("/surge-windows/:id", handler.DeleteSurgeWindow)
+
 	// Analytics
 	admin.Get("/stats", handler.GetDeliveryStats)
 	admin.Get("/providers", handler.GetLogisticsProviders)
 	// Replace RBAC middleware line with:
 	protected.Post("/", handler.CreateDelivery) // Remove RBAC middleware
 	// Or use: deliveryRoutes.Post("/", middleware.JWTAuth(), handler.CreateDelivery)
-	protected.Post("/drivers", handler.CreateDriver)
+	// Creating a driver links a login to it, so only admins may do it
+	protected.Post("/drivers", middleware.RBACMiddleware("admin", "superadmin"), handler.CreateDriver)
+
+	// Driver app: the driver is resolved from the JWT, never from the path
+	driver := app.Group("/api/v1/driver")
+	driver.Use(middleware.JWTMiddleware(cfg))
+	driver.Use(middleware.RBACMiddleware(RoleDriver))
+	driver.Use(handler.RequireDriver())
+
+	driver.Get("/me", handler.GetMyDriverProfile)
+	driver.Put("/me/location", handler.UpdateDriverLocation)
+	driver.Put("/me/availability", handler.ToggleDriverAvailability)
+	driver.Get("/me/deliveries", handler.GetDriverDeliveries)
+	driver.Get("/me/route", handler.GetMyRoute)
+	driver.Post("/me/deliveries/:id/failed-attempt", handler.RecordMyFailedAttempt)
 }
@@ -0,0 +1,29 @@
+package delivery
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTraveledLegKm(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lat, lng := 6.4300, 3.4200 // Victoria Island
+	prevAt := now.Add(-5 * time.Minute)
+
+	// About 1.1 km north in five minutes
+	got := traveledLegKm(&lat, &lng, &prevAt, 6.4400, 3.4200, now)
+	if math.Abs(got-1.112) > 0.01 {
+		t.Fatalf("leg = %.3f km, want about 1.112", got)
+	}
+
+	if got := traveledLegKm(nil, nil, nil, 6.4400, 3.4200, now); got != 0 {
+		t.Fatalf("first ping leg = %.3f km, want 0", got)
+	}
+
+	// Ikeja in ten seconds is a GPS jump, not travel
+	jumpAt := now.Add(-10 * time.Second)
+	if got := traveledLegKm(&lat, &lng, &jumpAt, 6.6018, 3.3515, now); got != 0 {
+		t.Fatalf("implausible leg = %.3f km, want 0", got)
+	}
+}
@@ -0,0 +1,45 @@
+package delivery
+
+import (
+	"errors"
+
+	"errandShop/internal/presenter"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleDriver is the JWT role of driver app accounts
+const RoleDriver = "driver"
+
+// RequireDriver resolves the signed-in driver from the JWT subject and stores its ID for the
+// driver-scoped handlers, so drivers can only ever act on their own record. It must run after
+// JWTMiddleware and a role check for RoleDriver.
+func (h *DeliveryHandler) RequireDriver() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		accountID, ok := c.Locals("userID").(uuid.UUID)
+		if !ok {
+			return presenter.Err(c, fiber.StatusUnauthorized, "Unauthorized")
+		}
+
+		driver, err := h.service.GetDriverByAccountID(accountID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return presenter.Err(c, fiber.StatusForbidden, "No driver profile is linked to this account")
+			}
+			return presenter.InternalServerError(c, "Failed to load driver profile")
+		}
+		if !driver.IsActive {
+			return presenter.Err(c, fiber.StatusForbidden, "Driver account is deactivated")
+		}
+
+		c.Locals("driverID", driver.ID)
+		return c.Next()
+	}
+}
+
+// currentDriverID returns the driver resolved by RequireDriver
+func currentDriverID(c *fiber.Ctx) uint {
+	id, _ := c.Locals("driverID").(uint)
+	return id
+}
@@ -1,6 +1,10 @@
 package delivery
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // CreateDeliveryRequest represents request to create a delivery
 type CreateDeliveryRequest struct {
@@ -14,20 +18,73 @@ type CreateDeliveryRequest struct {
 	DeliveryLatitude  *float64     `json:"delivery_latitude"`
 	DeliveryLongitude *float64     `json:"delivery_longitude"`
 	DeliveryNotes     string       `json:"delivery_notes" validate:"max=500"`
-	RecipientName     string       `json:"recipient_name" validate:"required,min=2,max=100"`
-	RecipientPhone    string       `json:"recipient_phone" validate:"required,min=10,max=20"`
+	RecipientName     string       `json:"recipient_name" validate:"omitempty,min=2,max=100"` // defaults to the order's recipient, then the customer
+	RecipientPhone    string       `json:"recipient_phone" validate:"omitempty,min=10,max=20"`
 	ScheduledDate     *time.Time   `json:"scheduled_date"`
 }
 
 // UpdateDeliveryStatusRequest represents request to update delivery status (Admin only)
 type UpdateDeliveryStatusRequest struct {
-	Status             DeliveryStatus    `json:"status" validate:"required,oneof=pending sent_out in_progress delivered cancelled returned"`
-	Message            string            `json:"message" validate:"max=500"`
-	LogisticsProvider  LogisticsProvider `json:"logistics_provider" validate:"omitempty,oneof=dhl fedex ups gig kwik sendbox"`
-	ProviderTrackingID string            `json:"provider_tracking_id" validate:"max=100"`
-	InternalNotes      string            `json:"internal_notes" validate:"max=1000"`
-	Latitude           *float64          `json:"latitude"`
-	Longitude          *float64          `json:"longitude"`
+	Status             DeliveryStatus        `json:"status" validate:"required,oneof=pending sent_out in_progress delivered cancelled returned"`
+	Message            string                `json:"message" validate:"max=500"`
+	LogisticsProvider  LogisticsProvider     `json:"logistics_provider" validate:"omitempty,oneof=dhl fedex ups gig kwik sendbox"`
+	ProviderTrackingID string                `json:"provider_tracking_id" validate:"max=100"`
+	InternalNotes      string                `json:"internal_notes" validate:"max=1000"`
+	Latitude           *float64              `json:"latitude"`
+	Longitude          *float64              `json:"longitude"`
+	Proof              *DeliveryProofRequest `json:"proof"`
+}
+
+// RecordFailedAttemptRequest is sent by the driver when the order could not be handed over
+type RecordFailedAttemptRequest struct {
+	Reason    string   `json:"reason" validate:"required,min=3,max=500"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// CreateBatchRequest groups pending deliveries into one trip for a driver. The route starts at
+// the given point, or the driver's last known location when it is omitted.
+type CreateBatchRequest struct {
+	DriverID       uint     `json:"driver_id" validate:"required"`
+	DeliveryIDs    []uint   `json:"delivery_ids" validate:"required,min=2,max=20,dive,required"`
+	StartLatitude  *float64 `json:"start_latitude" validate:"omitempty,latitude"`
+	StartLongitude *float64 `json:"start_longitude" validate:"omitempty,longitude"`
+}
+
+// BatchStopResponse is one stop on a batch route
+type BatchStopResponse struct {
+	Sequence         int            `json:"sequence"`
+	DeliveryID       uint           `json:"delivery_id"`
+	OrderID          string         `json:"order_id"`
+	TrackingNumber   string         `json:"tracking_number"`
+	Status           DeliveryStatus `json:"status"`
+	DeliveryAddress  string         `json:"delivery_address"`
+	Latitude         *float64       `json:"latitude"`
+	Longitude        *float64       `json:"longitude"`
+	RecipientName    string         `json:"recipient_name"`
+	RecipientPhone   string         `json:"recipient_phone"`
+	DeliveryNotes    string         `json:"delivery_notes"`
+	DistanceFromPrev *float64       `json:"distance_from_previous"` // km; nil when either end has no coordinates
+}
+
+// DeliveryBatchResponse is a batch with its stops in route order
+type DeliveryBatchResponse struct {
+	ID             uint                `json:"id"`
+	DriverID       uint                `json:"driver_id"`
+	Status         BatchStatus         `json:"status"`
+	StartLatitude  *float64            `json:"start_latitude"`
+	StartLongitude *float64            `json:"start_longitude"`
+	TotalDistance  float64             `json:"total_distance"`
+	Stops          []BatchStopResponse `json:"stops"`
+	CompletedAt    *time.Time          `json:"completed_at"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+// DeliveryProofRequest carries proof of delivery; at least a photo or a signature is required
+type DeliveryProofRequest struct {
+	PhotoURL      string `json:"photo_url" validate:"omitempty,url,max=500"`
+	SignatureURL  string `json:"signature_url" validate:"omitempty,url,max=500"`
+	RecipientName string `json:"recipient_name" validate:"max=100"`
 }
 
 // AssignLogisticsProviderRequest represents request to assign logistics provider
@@ -62,14 +119,49 @@ type DeliveryResponse struct {
 	EstimatedTime      *time.Time               `json:"estimated_time"`
 	ActualTime         *time.Time               `json:"actual_time"`
 	DeliveryFee        int64                    `json:"delivery_fee"`
-	Distance           *float64                 `json:"distance"`
+	TipKobo            int64                    `json:"tip_kobo"`
+	Distance           *float64                 `json:"distance"`        // estimated km
+	ActualDistance     float64                  `json:"actual_distance"` // km traveled per driver location pings
 	Duration           *int                     `json:"duration"`
 	InternalNotes      string                   `json:"internal_notes,omitempty"` // Only for admins
+	FailedAttempts     int                      `json:"failed_attempts"`
+	LastFailureReason  string                   `json:"last_failure_reason,omitempty"`
+	BatchID            *uint                    `json:"batch_id,omitempty"`
+	BatchSequence      *int                     `json:"batch_sequence,omitempty"`
+	Proof              *DeliveryProofResponse   `json:"proof,omitempty"`
 	TrackingUpdates    []TrackingUpdateResponse `json:"tracking_updates,omitempty"`
 	CreatedAt          time.Time                `json:"created_at"`
 	UpdatedAt          time.Time                `json:"updated_at"`
 }
 
+// DeliveryProofResponse represents the captured proof of delivery
+type DeliveryProofResponse struct {
+	PhotoURL      string    `json:"photo_url,omitempty"`
+	SignatureURL  string    `json:"signature_url,omitempty"`
+	RecipientName string    `json:"recipient_name,omitempty"`
+	CapturedAt    time.Time `json:"captured_at"`
+}
+
+// PublicTrackingResponse is the unauthenticated view of a delivery behind a shared tracking link.
+// It deliberately omits addresses, coordinates, phone numbers, proof of delivery and fees.
+type PublicTrackingResponse struct {
+	TrackingNumber  string                 `json:"tracking_number"`
+	Status          DeliveryStatus         `json:"status"`
+	DeliveryType    DeliveryType           `json:"delivery_type"`
+	ScheduledDate   *time.Time             `json:"scheduled_date,omitempty"`
+	EstimatedTime   *time.Time             `json:"estimated_time,omitempty"`
+	DeliveredAt     *time.Time             `json:"delivered_at,omitempty"`
+	DriverFirstName string                 `json:"driver_first_name,omitempty"`
+	TrackingUpdates []PublicTrackingUpdate `json:"tracking_updates"`
+}
+
+// PublicTrackingUpdate is a tracking update without internal identifiers
+type PublicTrackingUpdate struct {
+	Status    DeliveryStatus `json:"status"`
+	Message   string         `json:"message"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
 // TrackingUpdateResponse represents tracking update response
 type TrackingUpdateResponse struct {
 	ID          uint           `json:"id"`
@@ -93,6 +185,75 @@ type DeliveryStatsResponse struct {
 	ProviderBreakdown    map[LogisticsProvider]int64 `json:"provider_breakdown"`
 }
 
+// DriverEarningsLine is what a driver earned on one completed delivery (kobo)
+type DriverEarningsLine struct {
+	DeliveryID     uint      `json:"delivery_id"`
+	OrderID        string    `json:"order_id"`
+	TrackingNumber string    `json:"tracking_number"`
+	DeliveredAt    time.Time `json:"delivered_at"`
+	DeliveryFee    int64     `json:"delivery_fee"`
+	FeeShare       int64     `json:"fee_share"`
+	Tip            int64     `json:"tip"`
+	Total          int64     `json:"total"`
+}
+
+// DriverEarningsResponse is a driver's earnings statement for deliveries completed in [start, end)
+type DriverEarningsResponse struct {
+	DriverID        uint                 `json:"driver_id"`
+	Start           time.Time            `json:"start"`
+	End             time.Time            `json:"end"`
+	FeeSharePercent int                  `json:"fee_share_percent"`
+	Deliveries      []DriverEarningsLine `json:"deliveries"`
+	DeliveryCount   int                  `json:"delivery_count"`
+	TotalFeeShare   int64                `json:"total_fee_share"`
+	TotalTips       int64                `json:"total_tips"`
+	TotalEarnings   int64                `json:"total_earnings"`
+}
+
+// RemitCashRequest marks cash handed in by a driver; no collection IDs means all of it
+type RemitCashRequest struct {
+	CollectionIDs []uint `json:"collection_ids"`
+	Reference     string `json:"reference" validate:"max=100"` // bank transfer or receipt reference
+	Notes         string `json:"notes" validate:"max=500"`
+}
+
+// CashRemittanceResponse is the outcome of a remittance (kobo)
+type CashRemittanceResponse struct {
+	DriverID        uint             `json:"driver_id"`
+	CollectionCount int              `json:"collection_count"`
+	TotalKobo       int64            `json:"total_kobo"`
+	Reference       string           `json:"reference"`
+	RemittedAt      time.Time        `json:"remitted_at"`
+	Collections     []CashCollection `json:"collections"`
+}
+
+// DriverCashBalance is the cash one driver holds from cash-on-delivery orders (kobo)
+type DriverCashBalance struct {
+	DriverID          uint             `json:"driver_id"`
+	DriverName        string           `json:"driver_name"`
+	VehicleNumber     string           `json:"vehicle_number"`
+	OutstandingCount  int              `json:"outstanding_count"`
+	OutstandingKobo   int64            `json:"outstanding_kobo"`
+	OldestCollectedAt time.Time        `json:"oldest_collected_at"`
+	Collections       []CashCollection `json:"collections"`
+}
+
+// OutstandingCashReport lists the cash drivers have collected but not yet remitted
+type OutstandingCashReport struct {
+	Drivers              []DriverCashBalance `json:"drivers"`
+	TotalOutstandingKobo int64               `json:"total_outstanding_kobo"`
+	GeneratedAt          time.Time           `json:"generated_at"`
+}
+
+// CreateSurgeWindowRequest schedules a surge window; no zone_id applies it to every zone
+type CreateSurgeWindowRequest struct {
+	Name       string    `json:"name" validate:"max=100"`
+	ZoneID     *int      `json:"zone_id" validate:"omitempty,min=1"`
+	Multiplier float64   `json:"multiplier" validate:"required,gt=1,lte=5"`
+	StartsAt   time.Time `json:"starts_at" validate:"required"`
+	EndsAt     time.Time `json:"ends_at" validate:"required"`
+}
+
 // DeliveryQuoteRequest represents request for delivery quote
 type DeliveryQuoteRequest struct {
 	PickupLatitude    float64      `json:"pickup_latitude" validate:"required,min=-90,max=90"`
@@ -101,6 +262,7 @@ type DeliveryQuoteRequest struct {
 	DeliveryLongitude float64      `json:"delivery_longitude" validate:"required,min=-180,max=180"`
 	DeliveryType      DeliveryType `json:"delivery_type" validate:"required,oneof=standard express same_day scheduled"`
 	ScheduledDate     *time.Time   `json:"scheduled_date"`
+	WeightGrams       int          `json:"weight_grams" validate:"min=0"` // total parcel weight; adds a surcharge above the free allowance
 }
 
 // DeliveryQuoteResponse represents delivery quote response
@@ -108,6 +270,8 @@ type DeliveryQuoteResponse struct {
 	Distance          float64    `json:"distance"`
 	EstimatedTime     int        `json:"estimated_time"`
 	DeliveryFee       int64      `json:"delivery_fee"`
+	SurgeMultiplier   float64    `json:"surge_multiplier"`           // 1 outside surge windows; DeliveryFee already includes it
+	HighDemandNote    string     `json:"high_demand_note,omitempty"` // shown to the customer while a surge applies
 	EstimatedPickup   *time.Time `json:"estimated_pickup"`
 	EstimatedDelivery *time.Time `json:"estimated_delivery"`
 }
@@ -115,6 +279,8 @@ type DeliveryQuoteResponse struct {
 // CreateDriverRequest represents request to create a delivery driver
 type CreateDriverRequest struct {
 	UserID           uint        `json:"user_id" validate:"required"`
+	AccountID        *uuid.UUID  `json:"account_id"` // login (users.id, role "driver") for the driver app
+	FirstName        string      `json:"first_name" validate:"omitempty,max=100"`
 	LicenseNumber    string      `json:"license_number" validate:"required,min=5,max=50"`
 	VehicleType      VehicleType `json:"vehicle_type" validate:"required,oneof=bike motorcycle car van truck"`
 	VehicleNumber    string      `json:"vehicle_number" validate:"required,min=3,max=50"`
@@ -137,6 +303,8 @@ type UpdateDriverLocationRequest struct {
 type DeliveryDriverResponse struct {
 	ID                 uint        `json:"id"`
 	UserID             uint        `json:"user_id"`
+	AccountID          *uuid.UUID  `json:"account_id"`
+	FirstName          string      `json:"first_name"`
 	LicenseNumber      string      `json:"license_number"`
 	VehicleType        VehicleType `json:"vehicle_type"`
 	VehicleNumber      string      `json:"vehicle_number"`
@@ -0,0 +1,77 @@
+package delivery
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"errandShop/internal/core/types"
+)
+
+// defaultQuotePickupMinutes is how long a quote assumes before pickup when the zone has no prep time
+const defaultQuotePickupMinutes = 30
+
+// ZoneMatcher places a delivery address or map pin in a delivery zone
+type ZoneMatcher interface {
+	MatchAddress(address string) (*types.MatchResult, *types.NoMatchResult)
+	MatchCoordinates(lat, lng float64) (*types.MatchResult, *types.NoMatchResult)
+}
+
+// SetZoneMatcher enables zone-specific delivery time estimates; nil keeps the speed model only
+func (s *deliveryService) SetZoneMatcher(matcher ZoneMatcher) {
+	s.zoneMatcher = matcher
+}
+
+// matchZone finds the delivery zone for an address, trying the text first and then the pin, or
+// returns nil when there is no matcher or no zone covers the address
+func (s *deliveryService) matchZone(address string, lat, lng *float64) *types.MatchResult {
+	if s.zoneMatcher == nil {
+		return nil
+	}
+	if strings.TrimSpace(address) != "" {
+		if zone, _ := s.zoneMatcher.MatchAddress(address); zone != nil {
+			return zone
+		}
+	}
+	if lat != nil && lng != nil {
+		if zone, _ := s.zoneMatcher.MatchCoordinates(*lat, *lng); zone != nil {
+			return zone
+		}
+	}
+	return nil
+}
+
+// speedModelMinutes is the ride time for distance km at the typical speed of the delivery type
+func speedModelMinutes(distance float64, deliveryType DeliveryType) int {
+	// Base speed in km/h
+	speed := 30.0
+
+	switch deliveryType {
+	case DeliveryTypeExpress:
+		speed = 45.0
+	case DeliveryTypeSameDay:
+		speed = 50.0
+	}
+
+	return int(math.Round(distance / speed * 60))
+}
+
+// estimateMinutes returns the prep and ride minutes for a delivery. A matched zone's typical prep
+// and transit times are used where configured, with the ride never shorter than the speed model
+// allows for the distance, so far addresses in a large zone are not promised the zone's typical
+// time. Without zone times the ride comes from the speed model alone and prep is 0.
+func estimateMinutes(distance float64, deliveryType DeliveryType, zone *types.MatchResult) (prep, ride int) {
+	ride = speedModelMinutes(distance, deliveryType)
+	if zone == nil {
+		return 0, ride
+	}
+	if zone.TransitMinutes > ride {
+		ride = zone.TransitMinutes
+	}
+	return zone.PrepMinutes, ride
+}
+
+func (s *deliveryService) calculateEstimatedTime(distance float64, deliveryType DeliveryType, zone *types.MatchResult) time.Time {
+	prep, ride := estimateMinutes(distance, deliveryType, zone)
+	return time.Now().Add(time.Duration(prep+ride) * time.Minute)
+}
@@ -0,0 +1,31 @@
+package delivery
+
+import (
+	"testing"
+
+	"errandShop/internal/core/types"
+)
+
+func TestEstimateMinutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance float64
+		zone     *types.MatchResult
+		wantPrep int
+		wantRide int
+	}{
+		{"no zone uses the speed model", 10, nil, 0, 20},
+		{"zone without times uses the speed model", 10, &types.MatchResult{ZoneID: 1}, 0, 20},
+		{"zone times replace the speed model for a short trip", 5, &types.MatchResult{PrepMinutes: 15, TransitMinutes: 40}, 15, 40},
+		{"a far address is not promised the zone's typical ride", 30, &types.MatchResult{PrepMinutes: 15, TransitMinutes: 40}, 15, 60},
+		{"prep alone keeps the speed model ride", 10, &types.MatchResult{PrepMinutes: 20}, 20, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prep, ride := estimateMinutes(tt.distance, DeliveryTypeStandard, tt.zone)
+			if prep != tt.wantPrep || ride != tt.wantRide {
+				t.Fatalf("estimateMinutes = (%d, %d), want (%d, %d)", prep, ride, tt.wantPrep, tt.wantRide)
+			}
+		})
+	}
+}
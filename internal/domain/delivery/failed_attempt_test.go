@@ -0,0 +1,50 @@
+package delivery_test
+
+import (
+	"errors"
+	"testing"
+
+	delivery "errandShop/internal/domain/delivery"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDriverRecordsFailedAttemptOnlyOnOwnDelivery(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	if err := db.AutoMigrate(&delivery.DeliveryDriver{}, &delivery.Delivery{}, &delivery.TrackingUpdate{}); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	driverID, otherDriverID := uint(7), uint(8)
+	d := &delivery.Delivery{
+		OrderID:         "5f0c2f43-8c4b-4d6e-9a51-3f1a2b3c4d5e",
+		TrackingNumber:  "TRK-1",
+		DeliveryType:    "standard",
+		Status:          delivery.DeliveryStatusInTransit,
+		PickupAddress:   "Store",
+		DeliveryAddress: "12 Allen Ave",
+		RecipientName:   "Ada",
+		RecipientPhone:  "08030000000",
+		DriverID:        &driverID,
+	}
+	if err := db.Create(d).Error; err != nil {
+		t.Fatalf("failed to insert delivery: %v", err)
+	}
+
+	s := delivery.NewDeliveryService(delivery.NewDeliveryRepository(db), nil, nil, nil)
+	req := &delivery.RecordFailedAttemptRequest{Reason: "customer not answering"}
+
+	if _, err := s.RecordDriverFailedAttempt(otherDriverID, d.ID, req); !errors.Is(err, delivery.ErrDeliveryNotAssigned) {
+		t.Fatalf("another driver: err = %v, want ErrDeliveryNotAssigned", err)
+	}
+	got, err := s.RecordDriverFailedAttempt(driverID, d.ID, req)
+	if err != nil {
+		t.Fatalf("assigned driver: %v", err)
+	}
+	if got.Status != delivery.DeliveryStatusFailedAttempt || got.FailedAttempts != 1 {
+		t.Fatalf("delivery is %s after %d attempts, want failed_attempt after 1", got.Status, got.FailedAttempts)
+	}
+}
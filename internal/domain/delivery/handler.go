@@ -3,13 +3,17 @@ package delivery
 import (
 	"errandShop/internal/presenter"
 	"errandShop/internal/validation"
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"errandShop/internal/core/match"
 	"errandShop/internal/core/types"
 	"errandShop/internal/repos"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // DeliveryHandler handles delivery HTTP requests
@@ -48,6 +52,9 @@ func (h *DeliveryHandler) CreateDelivery(c *fiber.Ctx) error {
 
 	delivery, err := h.service.CreateDelivery(&req)
 	if err != nil {
+		if errors.Is(err, ErrRecipientRequired) {
+			return presenter.BadRequest(c, err.Error())
+		}
 		return presenter.InternalServerError(c, err.Error())
 	}
 
@@ -69,19 +76,19 @@ func (h *DeliveryHandler) GetDelivery(c *fiber.Ctx) error {
 	return presenter.Success(c, "Delivery retrieved successfully", delivery)
 }
 
-// GetDeliveryByTrackingNumber gets delivery by tracking number
-func (h *DeliveryHandler) GetDeliveryByTrackingNumber(c *fiber.Ctx) error {
+// TrackPublic returns the sanitized tracking view for a shared tracking link (no auth)
+func (h *DeliveryHandler) TrackPublic(c *fiber.Ctx) error {
 	trackingNumber := c.Params("tracking_number")
 	if trackingNumber == "" {
 		return presenter.BadRequest(c, "Tracking number is required")
 	}
 
-	delivery, err := h.service.GetDeliveryByTrackingNumber(trackingNumber)
+	tracking, err := h.service.GetPublicTracking(trackingNumber)
 	if err != nil {
 		return presenter.NotFound(c, "Delivery not found")
 	}
 
-	return presenter.Success(c, "Delivery retrieved successfully", delivery)
+	return presenter.Success(c, "Tracking retrieved successfully", tracking)
 }
 
 // GetDeliveryByOrderID gets delivery by order ID
@@ -184,13 +191,18 @@ func (h *DeliveryHandler) GetDriverByUserID(c *fiber.Ctx) error {
 	return presenter.Success(c, "Driver retrieved successfully", driver)
 }
 
-// UpdateDriverLocation updates driver location
-func (h *DeliveryHandler) UpdateDriverLocation(c *fiber.Ctx) error {
-	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+// GetMyDriverProfile returns the signed-in driver's profile
+func (h *DeliveryHandler) GetMyDriverProfile(c *fiber.Ctx) error {
+	driver, err := h.service.GetDriver(currentDriverID(c))
 	if err != nil {
-		return presenter.BadRequest(c, "Invalid driver ID")
+		return presenter.NotFound(c, "Driver not found")
 	}
 
+	return presenter.Success(c, "Driver retrieved successfully", driver)
+}
+
+// UpdateDriverLocation updates the signed-in driver's location
+func (h *DeliveryHandler) UpdateDriverLocation(c *fiber.Ctx) error {
 	var req UpdateDriverLocationRequest
 	if err := c.BodyParser(&req); err != nil {
 		return presenter.BadRequest(c, "Invalid request body")
@@ -200,7 +212,7 @@ func (h *DeliveryHandler) UpdateDriverLocation(c *fiber.Ctx) error {
 		return presenter.BadRequest(c, err.Error())
 	}
 
-	err = h.service.UpdateDriverLocation(uint(id), &req)
+	err := h.service.UpdateDriverLocation(currentDriverID(c), &req)
 	if err != nil {
 		return presenter.InternalServerError(c, "Failed to update driver location")
 	}
@@ -208,13 +220,8 @@ func (h *DeliveryHandler) UpdateDriverLocation(c *fiber.Ctx) error {
 	return presenter.Success(c, "Driver location updated successfully", nil)
 }
 
-// ToggleDriverAvailability toggles driver availability
+// ToggleDriverAvailability sets whether the signed-in driver is available for deliveries
 func (h *DeliveryHandler) ToggleDriverAvailability(c *fiber.Ctx) error {
-	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return presenter.BadRequest(c, "Invalid driver ID")
-	}
-
 	var req struct {
 		Available bool `json:"available"`
 	}
@@ -222,7 +229,7 @@ func (h *DeliveryHandler) ToggleDriverAvailability(c *fiber.Ctx) error {
 		return presenter.BadRequest(c, "Invalid request body")
 	}
 
-	err = h.service.ToggleDriverAvailability(uint(id), req.Available)
+	err := h.service.ToggleDriverAvailability(currentDriverID(c), req.Available)
 	if err != nil {
 		return presenter.InternalServerError(c, "Failed to update driver availability")
 	}
@@ -230,17 +237,12 @@ func (h *DeliveryHandler) ToggleDriverAvailability(c *fiber.Ctx) error {
 	return presenter.Success(c, "Driver availability updated successfully", nil)
 }
 
-// GetDriverDeliveries gets deliveries for a driver
+// GetDriverDeliveries gets the deliveries assigned to the signed-in driver
 func (h *DeliveryHandler) GetDriverDeliveries(c *fiber.Ctx) error {
-	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return presenter.BadRequest(c, "Invalid driver ID")
-	}
-
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	offset, _ := strconv.Atoi(c.Query("offset", "0"))
 
-	deliveries, total, err := h.service.GetDeliveriesByDriver(uint(id), limit, offset)
+	deliveries, total, err := h.service.GetDeliveriesByDriver(currentDriverID(c), limit, offset)
 	if err != nil {
 		return presenter.InternalServerError(c, "Failed to get driver deliveries")
 	}
@@ -273,6 +275,9 @@ func (h *DeliveryHandler) UpdateDeliveryStatus(c *fiber.Ctx) error {
 
 	delivery, err := h.service.UpdateDeliveryStatus(uint(id), &req)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "proof of delivery") {
+			return presenter.BadRequest(c, err.Error())
+		}
 		return presenter.InternalServerError(c, err.Error())
 	}
 
@@ -405,6 +410,123 @@ func (h *DeliveryHandler) CancelDelivery(c *fiber.Ctx) error {
 	return presenter.Success(c, "Delivery cancelled successfully", delivery)
 }
 
+// RecordFailedAttempt records a delivery the driver could not hand over (admin)
+func (h *DeliveryHandler) RecordFailedAttempt(c *fiber.Ctx) error {
+	return h.recordFailedAttempt(c, h.service.RecordFailedAttempt)
+}
+
+// RecordMyFailedAttempt records a hand-over the signed-in driver could not complete on one of their deliveries
+func (h *DeliveryHandler) RecordMyFailedAttempt(c *fiber.Ctx) error {
+	driverID := currentDriverID(c)
+	return h.recordFailedAttempt(c, func(id uint, req *RecordFailedAttemptRequest) (*DeliveryResponse, error) {
+		return h.service.RecordDriverFailedAttempt(driverID, id, req)
+	})
+}
+
+func (h *DeliveryHandler) recordFailedAttempt(c *fiber.Ctx, record func(id uint, req *RecordFailedAttemptRequest) (*DeliveryResponse, error)) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid delivery ID")
+	}
+
+	var req RecordFailedAttemptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	delivery, err := record(uint(id), &req)
+	if err != nil {
+		// Another driver's delivery is reported as missing, so IDs cannot be probed
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrDeliveryNotAssigned) {
+			return presenter.NotFound(c, "Delivery not found")
+		}
+		if errors.Is(err, ErrDeliveryNotAttemptable) {
+			return presenter.BadRequest(c, err.Error())
+		}
+		return presenter.InternalServerError(c, err.Error())
+	}
+
+	return presenter.Success(c, "Failed delivery attempt recorded", delivery)
+}
+
+// CreateBatch groups pending deliveries into one trip for a driver with a proximity-ordered route (admin)
+func (h *DeliveryHandler) CreateBatch(c *fiber.Ctx) error {
+	var req CreateBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	batch, err := h.service.CreateBatch(&req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Driver not found")
+		}
+		if errors.Is(err, ErrDeliveryNotBatchable) || errors.Is(err, ErrDriverNotAvailable) {
+			return presenter.BadRequest(c, err.Error())
+		}
+		return presenter.InternalServerError(c, err.Error())
+	}
+
+	return presenter.Created(c, batch)
+}
+
+// GetBatch gets a delivery batch with its stops in route order (admin)
+func (h *DeliveryHandler) GetBatch(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid batch ID")
+	}
+
+	batch, err := h.service.GetBatch(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Batch not found")
+		}
+		return presenter.InternalServerError(c, err.Error())
+	}
+
+	return presenter.Success(c, "Batch retrieved successfully", batch)
+}
+
+// GetDriverRoute lists the stops of a driver's current trip in sequence
+func (h *DeliveryHandler) GetDriverRoute(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid driver ID")
+	}
+
+	route, err := h.service.GetDriverRoute(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Driver has no active batch")
+		}
+		return presenter.InternalServerError(c, err.Error())
+	}
+
+	return presenter.Success(c, "Driver route retrieved successfully", route)
+}
+
+// GetMyRoute returns the signed-in driver's active batch with stops in driving order
+func (h *DeliveryHandler) GetMyRoute(c *fiber.Ctx) error {
+	route, err := h.service.GetDriverRoute(currentDriverID(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "You have no active batch")
+		}
+		return presenter.InternalServerError(c, err.Error())
+	}
+
+	return presenter.Success(c, "Driver route retrieved successfully", route)
+}
+
 // GetAvailableDrivers gets available drivers (admin)
 func (h *DeliveryHandler) GetAvailableDrivers(c *fiber.Ctx) error {
 	vehicleTypeStr := c.Query("vehicle_type")
@@ -493,10 +615,162 @@ func (h *DeliveryHandler) GetDriverStats(c *fiber.Ctx) error {
 	return presenter.Success(c, "Driver statistics retrieved successfully", stats)
 }
 
-// EstimateDelivery handles POST /api/v1/delivery/estimate
+// GetDriverEarnings returns a driver's earnings statement (admin). start and end are
+// YYYY-MM-DD, end inclusive; they default to the current month so far.
+func (h *DeliveryHandler) GetDriverEarnings(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid driver ID")
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := now.Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if v := c.Query("start"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return presenter.BadRequest(c, "Invalid start date, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+	if v := c.Query("end"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return presenter.BadRequest(c, "Invalid end date, expected YYYY-MM-DD")
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return presenter.BadRequest(c, "end must not be before start")
+	}
+
+	earnings, err := h.service.GetDriverEarnings(uint(id), start, end)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Driver not found")
+		}
+		return presenter.InternalServerError(c, "Failed to get driver earnings")
+	}
+
+	return presenter.Success(c, "Driver earnings retrieved successfully", earnings)
+}
+
+// GetOutstandingCash reports cash-on-delivery money drivers still hold, optionally for one driver (admin)
+func (h *DeliveryHandler) GetOutstandingCash(c *fiber.Ctx) error {
+	var driverID *uint
+	if v := c.Query("driver_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return presenter.BadRequest(c, "Invalid driver ID")
+		}
+		driver := uint(id)
+		driverID = &driver
+	}
+
+	report, err := h.service.GetOutstandingCash(driverID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Driver not found")
+		}
+		return presenter.InternalServerError(c, "Failed to get outstanding cash")
+	}
+
+	return presenter.Success(c, "Outstanding cash retrieved successfully", report)
+}
+
+// RemitDriverCash marks cash a driver has handed in as remitted (admin)
+func (h *DeliveryHandler) RemitDriverCash(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid driver ID")
+	}
+	adminID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Unauthorized(c, "User not authenticated")
+	}
+
+	var req RemitCashRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	remittance, err := h.service.RemitDriverCash(uint(id), adminID, &req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Driver not found")
+		}
+		if errors.Is(err, ErrNoOutstandingCash) || errors.Is(err, ErrCashNotOutstanding) {
+			return presenter.BadRequest(c, err.Error())
+		}
+		return presenter.InternalServerError(c, err.Error())
+	}
+
+	return presenter.Success(c, "Cash remittance recorded", remittance)
+}
+
+// CreateSurgeWindow schedules a window of raised delivery fees (admin)
+func (h *DeliveryHandler) CreateSurgeWindow(c *fiber.Ctx) error {
+	adminID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Unauthorized(c, "User not authenticated")
+	}
+
+	var req CreateSurgeWindowRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	window, err := h.service.CreateSurgeWindow(adminID, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSurgeWindow) {
+			return presenter.BadRequest(c, err.Error())
+		}
+		return presenter.InternalServerError(c, "Failed to create surge window")
+	}
+
+	return presenter.Created(c, window)
+}
+
+// ListSurgeWindows lists current and upcoming surge windows, or all of them with ?include_ended=true (admin)
+func (h *DeliveryHandler) ListSurgeWindows(c *fiber.Ctx) error {
+	windows, err := h.service.ListSurgeWindows(c.QueryBool("include_ended"))
+	if err != nil {
+		return presenter.InternalServerError(c, "Failed to list surge windows")
+	}
+
+	return presenter.Success(c, "Surge windows retrieved successfully", windows)
+}
+
+// DeleteSurgeWindow ends a surge window early or cancels an upcoming one (admin)
+func (h *DeliveryHandler) DeleteSurgeWindow(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid surge window ID")
+	}
+
+	if err := h.service.DeleteSurgeWindow(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return presenter.NotFound(c, "Surge window not found")
+		}
+		return presenter.InternalServerError(c, "Failed to delete surge window")
+	}
+
+	return presenter.Success(c, "Surge window deleted", nil)
+}
+
+// EstimateDelivery handles POST /api/v1/delivery/estimate. Logged-in users send a saved addressId;
+// guests and new users can send a typed address and/or the coordinates of a map pin instead.
+// Text is matched first, and the pin is used when the text matches no zone.
 func (h *DeliveryHandler) EstimateDelivery(c *fiber.Ctx) error {
 	// Check if costing functionality is available
-	if h.matcher == nil || h.addressRepo == nil {
+	if h.matcher == nil {
 		return presenter.BadRequest(c, "Delivery costing functionality not available")
 	}
 
@@ -507,32 +781,58 @@ func (h *DeliveryHandler) EstimateDelivery(c *fiber.Ctx) error {
 	}
 
 	// Validate required fields
-	if req.AddressID == "" {
-		return presenter.BadRequest(c, "addressId is required")
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return presenter.BadRequest(c, "latitude and longitude must be sent together")
 	}
-
-	// Get user ID from JWT context if available, otherwise from request
-	userID := c.Locals("userID")
-	var userIDStr string
-	if userID != nil {
-		userIDStr = userID.(string)
-	} else if req.UserID != "" {
-		userIDStr = req.UserID
-	} else {
-		return presenter.BadRequest(c, "userID is required when not authenticated")
+	hasPin := req.Latitude != nil
+	if hasPin && (*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180) {
+		return presenter.BadRequest(c, "latitude or longitude is out of range")
+	}
+	addressText := strings.TrimSpace(req.Address)
+	if req.AddressID == "" && addressText == "" && !hasPin {
+		return presenter.BadRequest(c, "addressId, address, or latitude and longitude is required")
 	}
 
-	// Fetch address by ID
-	address, err := h.addressRepo.GetByID(userIDStr, req.AddressID)
-	if err != nil {
-		return presenter.NotFound(c, "Address not found or does not belong to user")
+	if req.AddressID != "" {
+		if h.addressRepo == nil {
+			return presenter.BadRequest(c, "Delivery costing functionality not available")
+		}
+
+		// Get user ID from JWT context if available, otherwise from request
+		userID := c.Locals("userID")
+		var userIDStr string
+		if userID != nil {
+			userIDStr = userID.(string)
+		} else if req.UserID != "" {
+			userIDStr = req.UserID
+		} else {
+			return presenter.BadRequest(c, "userID is required when not authenticated")
+		}
+
+		// Fetch address by ID
+		address, err := h.addressRepo.GetByID(userIDStr, req.AddressID)
+		if err != nil {
+			return presenter.NotFound(c, "Address not found or does not belong to user")
+		}
+		addressText = address.Text
 	}
 
 	// Match address to delivery zone
-	matchResult, noMatchResult := h.matcher.MatchAddress(address.Text)
+	var matchResult *types.MatchResult
+	var noMatchResult *types.NoMatchResult
+	if addressText != "" {
+		matchResult, noMatchResult = h.matcher.MatchAddress(addressText)
+	}
+	if matchResult == nil && hasPin {
+		pinMatch, pinNoMatch := h.matcher.MatchCoordinates(*req.Latitude, *req.Longitude)
+		if pinMatch != nil || noMatchResult == nil || len(pinNoMatch.Suggestions) > 0 {
+			matchResult, noMatchResult = pinMatch, pinNoMatch
+		}
+	}
 
 	if matchResult != nil {
-		// Success - return match result
+		// Success - return match result, priced with any surge in effect
+		h.service.ApplyZoneSurge(matchResult, time.Now())
 		return c.Status(fiber.StatusOK).JSON(matchResult)
 	}
 
@@ -586,19 +886,22 @@ func (h *DeliveryHandler) ConfirmOrder(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if client price matches computed price
+	// Check if client price matches computed price, including any surge the estimate showed
+	h.service.ApplyZoneSurge(matchResult, time.Now())
 	if req.ClientPrice != matchResult.Price {
 		return presenter.Conflict(c, "Client price does not match computed delivery price")
 	}
 
 	// Price matches - order can be confirmed
 	return presenter.Success(c, "Order delivery price confirmed", map[string]interface{}{
-		"addressId":      req.AddressID,
-		"confirmedPrice": matchResult.Price,
-		"zoneId":         matchResult.ZoneID,
-		"zoneName":       matchResult.ZoneName,
-		"matchedBy":      matchResult.MatchedBy,
-		"confidence":     matchResult.Confidence,
+		"addressId":       req.AddressID,
+		"confirmedPrice":  matchResult.Price,
+		"zoneId":          matchResult.ZoneID,
+		"zoneName":        matchResult.ZoneName,
+		"matchedBy":       matchResult.MatchedBy,
+		"confidence":      matchResult.Confidence,
+		"surgeMultiplier": matchResult.SurgeMultiplier,
+		"highDemandNote":  matchResult.HighDemandNote,
 	})
 }
 
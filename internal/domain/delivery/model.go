@@ -3,6 +3,7 @@ package delivery
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +20,10 @@ const (
 	DeliveryStatusDelivered   DeliveryStatus = "delivered"
 	DeliveryStatusCancelled   DeliveryStatus = "cancelled"
 	DeliveryStatusReturned    DeliveryStatus = "returned"
+	// DeliveryStatusFailedAttempt means the driver could not hand the order over and will try again
+	DeliveryStatusFailedAttempt DeliveryStatus = "failed_attempt"
+	// DeliveryStatusNeedsAttention means the attempt limit was reached and an admin must decide what happens next
+	DeliveryStatusNeedsAttention DeliveryStatus = "needs_attention"
 )
 
 // DeliveryType represents the type of delivery service
@@ -87,13 +92,29 @@ type Delivery struct {
 
 	// Pricing
 	DeliveryFee int64    `json:"delivery_fee" gorm:"not null"` // in kobo
-	Distance    *float64 `json:"distance"`                     // in kilometers
+	TipKobo     int64    `json:"tip_kobo" gorm:"default:0"`    // customer tip for the driver, copied from the order
+	Distance    *float64 `json:"distance"`                     // estimated straight-line kilometers, pickup to drop-off
+	ActualDistance float64 `json:"actual_distance" gorm:"default:0"` // kilometers traveled, accumulated from driver location pings
 	Duration    *int     `json:"duration"`                     // in minutes
 
 	// Driver assignment
 	DriverID *uint           `json:"driver_id" gorm:"index"`
 	Driver   *DeliveryDriver `json:"driver,omitempty" gorm:"foreignKey:DriverID"`
 
+	// Proof of delivery (captured when the driver marks the delivery delivered)
+	ProofPhotoURL      string     `json:"proof_photo_url" gorm:"size:500"`
+	ProofSignatureURL  string     `json:"proof_signature_url" gorm:"size:500"`
+	ProofRecipientName string     `json:"proof_recipient_name" gorm:"size:100"`
+	ProofCapturedAt    *time.Time `json:"proof_captured_at"`
+
+	// Failed hand-over attempts (customer unreachable, nobody home, ...)
+	FailedAttempts    int    `json:"failed_attempts" gorm:"default:0"`
+	LastFailureReason string `json:"last_failure_reason" gorm:"type:text"`
+
+	// Multi-drop trip: the batch this delivery rides in and its 1-based stop number on the route
+	BatchID       *uint `json:"batch_id" gorm:"index"`
+	BatchSequence *int  `json:"batch_sequence"`
+
 	// Tracking
 	TrackingUpdates []TrackingUpdate `json:"tracking_updates,omitempty" gorm:"foreignKey:DeliveryID"` 
 
@@ -119,6 +140,56 @@ type TrackingUpdate struct {
 	CreatedAt   time.Time      `json:"created_at"`
 }
 
+// BatchStatus represents the status of a delivery batch
+type BatchStatus string
+
+const (
+	BatchStatusActive    BatchStatus = "active"
+	BatchStatusCompleted BatchStatus = "completed" // every stop is delivered, cancelled or returned
+)
+
+// DeliveryBatch groups deliveries one driver carries in a single trip. Its stops are the
+// deliveries with this BatchID, visited in BatchSequence order.
+type DeliveryBatch struct {
+	ID             uint        `json:"id" gorm:"primaryKey"`
+	DriverID       uint        `json:"driver_id" gorm:"not null;index"`
+	Status         BatchStatus `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	StartLatitude  *float64    `json:"start_latitude"` // where the route was planned from
+	StartLongitude *float64    `json:"start_longitude"`
+	TotalDistance  float64     `json:"total_distance"` // straight-line km along the planned route
+	CompletedAt    *time.Time  `json:"completed_at"`
+	Deliveries     []Delivery  `json:"deliveries,omitempty" gorm:"foreignKey:BatchID"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// CashCollectionStatus tracks cash a driver took on a cash-on-delivery order until it reaches the business
+type CashCollectionStatus string
+
+const (
+	CashCollectionOutstanding CashCollectionStatus = "outstanding" // collected by the driver, not yet handed in
+	CashCollectionRemitted    CashCollectionStatus = "remitted"
+)
+
+// CashCollection is the cash a driver is expected to have collected on one cash-on-delivery
+// delivery. It is recorded when the delivery is marked delivered and stays outstanding until an
+// admin confirms the driver has remitted it.
+type CashCollection struct {
+	ID                  uint                 `json:"id" gorm:"primaryKey"`
+	DeliveryID          uint                 `json:"delivery_id" gorm:"not null;uniqueIndex"`
+	OrderID             string               `json:"order_id" gorm:"type:uuid;not null;index"`
+	DriverID            uint                 `json:"driver_id" gorm:"not null;index"`
+	AmountKobo          int64                `json:"amount_kobo" gorm:"not null"` // the order total the customer owed
+	Status              CashCollectionStatus `json:"status" gorm:"type:varchar(20);not null;default:'outstanding';index"`
+	CollectedAt         time.Time            `json:"collected_at" gorm:"not null"`
+	RemittedAt          *time.Time           `json:"remitted_at"`
+	RemittedBy          *uuid.UUID           `json:"remitted_by" gorm:"type:uuid"` // admin who confirmed the hand-in
+	RemittanceReference string               `json:"remittance_reference" gorm:"size:100"`
+	Notes               string               `json:"notes" gorm:"type:text"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
+}
+
 // DeliveryZone represents delivery service areas
 type DeliveryZone struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
@@ -138,6 +209,8 @@ type DeliveryZone struct {
 type DeliveryDriver struct {
 	ID                 uint           `json:"id" gorm:"primaryKey"`
 	UserID             uint           `json:"user_id" gorm:"not null;uniqueIndex"`
+	AccountID          *uuid.UUID     `json:"account_id" gorm:"type:uuid;uniqueIndex"` // users.id the driver app signs in with; nil until linked
+	FirstName          string         `json:"first_name" gorm:"size:100"` // shown to customers on public tracking
 	LicenseNumber      string         `json:"license_number" gorm:"size:50;not null;uniqueIndex"`
 	VehicleType        VehicleType    `json:"vehicle_type" gorm:"type:varchar(20);not null"`
 	VehicleNumber      string         `json:"vehicle_number" gorm:"size:20;not null"`
@@ -1,9 +1,13 @@
 package delivery
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // DeliveryRepository interface defines delivery repository methods
@@ -23,11 +27,13 @@ type DeliveryRepository interface {
 	CreateDriver(driver *DeliveryDriver) error
 	GetDriverByID(id uint) (*DeliveryDriver, error)
 	GetDriverByUserID(userID uint) (*DeliveryDriver, error)
+	GetDriverByAccountID(accountID uuid.UUID) (*DeliveryDriver, error)
 	UpdateDriver(driver *DeliveryDriver) error
 	DeleteDriver(id uint) error
 	ListDrivers(limit, offset int, isActive *bool) ([]DeliveryDriver, int64, error)
 	GetAvailableDrivers(vehicleType *VehicleType, lat, lng *float64, radius float64) ([]DeliveryDriver, error)
 	UpdateDriverLocation(driverID uint, lat, lng float64) error
+	AddActualDistance(driverID uint, km float64) error
 
 	// Tracking methods
 	CreateTrackingUpdate(update *TrackingUpdate) error
@@ -44,6 +50,29 @@ type DeliveryRepository interface {
 	// Analytics methods
 	GetDeliveryStats(startDate, endDate *time.Time) (*DeliveryStatsResponse, error)
 	GetDriverStats(driverID uint, startDate, endDate *time.Time) (map[string]interface{}, error)
+	GetCompletedDeliveriesByDriver(driverID uint, start, end time.Time) ([]Delivery, error)
+
+	// Batch methods
+	GetDeliveriesByIDs(ids []uint) ([]Delivery, error)
+	CreateBatch(batch *DeliveryBatch, stops []Delivery) error
+	GetBatchByID(id uint) (*DeliveryBatch, error)
+	GetActiveBatchByDriver(driverID uint) (*DeliveryBatch, error)
+	CompleteBatchIfDone(batchID uint) error
+
+	// Cash-on-delivery collection methods
+	CreateCashCollection(collection *CashCollection) error
+	GetOutstandingCash(driverID *uint) ([]CashCollection, error)
+	RemitCash(driverID uint, ids []uint, remittedBy uuid.UUID, reference, notes string) ([]CashCollection, error)
+
+	// Surge pricing methods
+	CreateSurgeWindow(window *SurgeWindow) error
+	ListSurgeWindows(endedAfter *time.Time) ([]SurgeWindow, error)
+	DeleteSurgeWindow(id uint) error
+	GetActiveSurgeWindows(zoneID *int, at time.Time) ([]SurgeWindow, error)
+
+	// Account data methods
+	GetDeliveriesByCustomer(userID uuid.UUID) ([]Delivery, error)
+	EraseCustomerDeliveries(userID uuid.UUID) ([]string, error)
 }
 
 // deliveryRepository implements DeliveryRepository
@@ -167,6 +196,15 @@ func (r *deliveryRepository) GetDriverByUserID(userID uint) (*DeliveryDriver, er
 	return &driver, nil
 }
 
+func (r *deliveryRepository) GetDriverByAccountID(accountID uuid.UUID) (*DeliveryDriver, error) {
+	var driver DeliveryDriver
+	err := r.db.Where("account_id = ?", accountID).First(&driver).Error
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
 func (r *deliveryRepository) UpdateDriver(driver *DeliveryDriver) error {
 	return r.db.Save(driver).Error
 }
@@ -203,7 +241,7 @@ func (r *deliveryRepository) GetAvailableDrivers(vehicleType *VehicleType, lat,
 
 	// If coordinates provided, filter by radius (simplified - in production use PostGIS)
 	if lat != nil && lng != nil && radius > 0 {
-		query = query.Where("current_lat IS NOT NULL AND current_lng IS NOT NULL")
+		query = query.Where("current_latitude IS NOT NULL AND current_longitude IS NOT NULL")
 	}
 
 	err := query.Find(&drivers).Error
@@ -213,12 +251,30 @@ func (r *deliveryRepository) GetAvailableDrivers(vehicleType *VehicleType, lat,
 func (r *deliveryRepository) UpdateDriverLocation(driverID uint, lat, lng float64) error {
 	now := time.Now()
 	return r.db.Model(&DeliveryDriver{}).Where("id = ?", driverID).Updates(map[string]interface{}{
-		"current_lat":          lat,
-		"current_lng":          lng,
+		"current_latitude":     lat,
+		"current_longitude":    lng,
 		"last_location_update": now,
 	}).Error
 }
 
+// AddActualDistance adds km to the driver's current in-progress delivery. On a multi-drop trip
+// that is the next stop on the route, so each leg is counted once.
+func (r *deliveryRepository) AddActualDistance(driverID uint, km float64) error {
+	var current Delivery
+	err := r.db.Select("id").
+		Where("driver_id = ? AND status IN ?", driverID, []DeliveryStatus{DeliveryStatusInProgress, DeliveryStatusPickedUp, DeliveryStatusInTransit}).
+		Order("CASE WHEN batch_sequence IS NULL THEN 1 ELSE 0 END, batch_sequence, id").
+		First(&current).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // not on a delivery; the move is not billable distance
+		}
+		return err
+	}
+	return r.db.Model(&Delivery{}).Where("id = ?", current.ID).
+		Update("actual_distance", gorm.Expr("actual_distance + ?", km)).Error
+}
+
 // Tracking methods implementation
 func (r *deliveryRepository) CreateTrackingUpdate(update *TrackingUpdate) error {
 	return r.db.Create(update).Error
@@ -308,25 +364,199 @@ func (r *deliveryRepository) GetDeliveryStats(startDate, endDate *time.Time) (*D
 	return stats, nil
 }
 
+// GetCompletedDeliveriesByDriver returns the driver's deliveries marked delivered within [start, end), oldest first
+func (r *deliveryRepository) GetCompletedDeliveriesByDriver(driverID uint, start, end time.Time) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := r.db.Where("driver_id = ? AND status = ?", driverID, DeliveryStatusDelivered).
+		Where("delivery_time >= ? AND delivery_time < ?", start, end).
+		Order("delivery_time ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
 func (r *deliveryRepository) GetDriverStats(driverID uint, startDate, endDate *time.Time) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
-	query := r.db.Model(&Delivery{}).Where("driver_id = ?", driverID)
-	if startDate != nil && endDate != nil {
-		query = query.Where("created_at BETWEEN ? AND ?", *startDate, *endDate)
+	scoped := func() *gorm.DB {
+		query := r.db.Model(&Delivery{}).Where("driver_id = ?", driverID)
+		if startDate != nil && endDate != nil {
+			query = query.Where("created_at BETWEEN ? AND ?", *startDate, *endDate)
+		}
+		return query
 	}
 
 	var totalDeliveries int64
 	var completedDeliveries int64
 	var totalRevenue int64
+	var totalTips int64
 
-	query.Count(&totalDeliveries)
-	query.Where("status = ?", DeliveryStatusDelivered).Count(&completedDeliveries)
-	query.Where("status = ?", DeliveryStatusDelivered).Select("SUM(delivery_fee)").Scan(&totalRevenue)
+	scoped().Count(&totalDeliveries)
+	scoped().Where("status = ?", DeliveryStatusDelivered).Count(&completedDeliveries)
+	scoped().Where("status = ?", DeliveryStatusDelivered).Select("COALESCE(SUM(delivery_fee), 0)").Scan(&totalRevenue)
+	// Tips are only credited once the delivery is completed
+	scoped().Where("status = ?", DeliveryStatusDelivered).Select("COALESCE(SUM(tip_kobo), 0)").Scan(&totalTips)
 
 	stats["total_deliveries"] = totalDeliveries
 	stats["completed_deliveries"] = completedDeliveries
 	stats["total_revenue"] = totalRevenue
+	stats["total_tips"] = totalTips
+
+	// Estimated (straight-line) vs. actually traveled kilometers on completed deliveries
+	var distances struct {
+		Estimated float64
+		Actual    float64
+	}
+	scoped().Where("status = ?", DeliveryStatusDelivered).
+		Select("COALESCE(SUM(distance), 0) AS estimated, COALESCE(SUM(actual_distance), 0) AS actual").
+		Scan(&distances)
+	stats["estimated_distance_km"] = distances.Estimated
+	stats["actual_distance_km"] = distances.Actual
 
 	return stats, nil
 }
+
+// Batch methods implementation
+func (r *deliveryRepository) GetDeliveriesByIDs(ids []uint) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := r.db.Where("id IN ?", ids).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// CreateBatch saves the batch and assigns its stops to the driver in route order, in one
+// transaction. A stop that was assigned or batched since it was read fails the whole batch with
+// ErrDeliveryNotBatchable.
+func (r *deliveryRepository) CreateBatch(batch *DeliveryBatch, stops []Delivery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i, stop := range stops {
+			sequence := i + 1
+			res := tx.Model(&Delivery{}).
+				Where("id = ? AND status = ? AND batch_id IS NULL", stop.ID, DeliveryStatusPending).
+				Updates(map[string]interface{}{
+					"driver_id":      batch.DriverID,
+					"status":         DeliveryStatusAssigned,
+					"batch_id":       batch.ID,
+					"batch_sequence": sequence,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("%w: delivery %d", ErrDeliveryNotBatchable, stop.ID)
+			}
+			if err := tx.Create(&TrackingUpdate{
+				DeliveryID: stop.ID,
+				Status:     DeliveryStatusAssigned,
+				Message:    fmt.Sprintf("Assigned to driver as stop %d of %d", sequence, len(stops)),
+				Timestamp:  now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&DeliveryDriver{}).Where("id = ?", batch.DriverID).Update("is_available", false).Error
+	})
+}
+
+func (r *deliveryRepository) GetBatchByID(id uint) (*DeliveryBatch, error) {
+	var batch DeliveryBatch
+	err := r.db.Preload("Deliveries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("batch_sequence ASC")
+	}).First(&batch, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *deliveryRepository) GetActiveBatchByDriver(driverID uint) (*DeliveryBatch, error) {
+	var batch DeliveryBatch
+	err := r.db.Preload("Deliveries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("batch_sequence ASC")
+	}).Where("driver_id = ? AND status = ?", driverID, BatchStatusActive).
+		Order("created_at DESC").
+		First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// CompleteBatchIfDone marks an active batch completed once none of its stops is still open
+func (r *deliveryRepository) CompleteBatchIfDone(batchID uint) error {
+	return r.db.Exec(`
+		UPDATE delivery_batches SET status = ?, completed_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND status = ? AND NOT EXISTS (
+			SELECT 1 FROM deliveries
+			WHERE batch_id = ? AND deleted_at IS NULL AND status NOT IN ?
+		)`,
+		BatchStatusCompleted, batchID, BatchStatusActive, batchID,
+		[]DeliveryStatus{DeliveryStatusDelivered, DeliveryStatusCancelled, DeliveryStatusReturned}).Error
+}
+
+// CreateCashCollection records cash expected from a delivery; a delivery already recorded is left as is
+func (r *deliveryRepository) CreateCashCollection(collection *CashCollection) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "delivery_id"}},
+		DoNothing: true,
+	}).Create(collection).Error
+}
+
+// GetOutstandingCash returns cash not yet remitted, for one driver or all of them, oldest first per driver
+func (r *deliveryRepository) GetOutstandingCash(driverID *uint) ([]CashCollection, error) {
+	var collections []CashCollection
+	query := r.db.Where("status = ?", CashCollectionOutstanding)
+	if driverID != nil {
+		query = query.Where("driver_id = ?", *driverID)
+	}
+	err := query.Order("driver_id ASC, collected_at ASC").Find(&collections).Error
+	return collections, err
+}
+
+// RemitCash marks a driver's outstanding collections remitted: the given ones, or all of them when
+// ids is empty. Every given collection must belong to the driver and still be outstanding.
+func (r *deliveryRepository) RemitCash(driverID uint, ids []uint, remittedBy uuid.UUID, reference, notes string) ([]CashCollection, error) {
+	var collections []CashCollection
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("driver_id = ? AND status = ?", driverID, CashCollectionOutstanding)
+		if len(ids) > 0 {
+			query = query.Where("id IN ?", ids)
+		}
+		if err := query.Order("collected_at ASC").Find(&collections).Error; err != nil {
+			return err
+		}
+		if len(collections) == 0 {
+			return ErrNoOutstandingCash
+		}
+		if len(ids) > 0 && len(collections) != len(ids) {
+			return fmt.Errorf("%w: some collections are not outstanding for this driver", ErrCashNotOutstanding)
+		}
+
+		now := time.Now()
+		remitted := make([]uint, len(collections))
+		for i := range collections {
+			remitted[i] = collections[i].ID
+			collections[i].Status = CashCollectionRemitted
+			collections[i].RemittedAt = &now
+			collections[i].RemittedBy = &remittedBy
+			collections[i].RemittanceReference = reference
+			collections[i].Notes = notes
+		}
+		return tx.Model(&CashCollection{}).Where("id IN ?", remitted).Updates(map[string]interface{}{
+			"status":               CashCollectionRemitted,
+			"remitted_at":          now,
+			"remitted_by":          remittedBy,
+			"remittance_reference": reference,
+			"notes":                notes,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
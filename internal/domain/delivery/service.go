@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"time"
+	"errandShop/internal/core/types"
 	"errandShop/internal/domain/notifications"
 	"errandShop/internal/domain/orders"
 	"errandShop/internal/domain/customers"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // DeliveryService interface defines delivery service methods
@@ -19,17 +22,27 @@ type DeliveryService interface {
 	CreateDelivery(req *CreateDeliveryRequest) (*DeliveryResponse, error)
 	GetDelivery(id uint) (*DeliveryResponse, error)
 	GetDeliveryByTrackingNumber(trackingNumber string) (*DeliveryResponse, error)
+	GetPublicTracking(trackingNumber string) (*PublicTrackingResponse, error)
+	TrackOrderDelivery(orderID uuid.UUID) (*orders.DeliveryTracking, error)
 	GetDeliveryByOrderID(orderID string) (*DeliveryResponse, error)
 	UpdateDeliveryStatus(id uint, req *UpdateDeliveryStatusRequest) (*DeliveryResponse, error)
 	AssignDriver(id uint, driverID uint) (*DeliveryResponse, error)
 	ListDeliveries(limit, offset int, status *DeliveryStatus) ([]DeliveryResponse, int64, error)
 	GetDeliveriesByDriver(driverID uint, limit, offset int) ([]DeliveryResponse, int64, error)
 	CancelDelivery(id uint, reason string) (*DeliveryResponse, error)
+	RecordFailedAttempt(id uint, req *RecordFailedAttemptRequest) (*DeliveryResponse, error)
+	RecordDriverFailedAttempt(driverID, id uint, req *RecordFailedAttemptRequest) (*DeliveryResponse, error)
+
+	// Batch methods (several deliveries in one driver trip)
+	CreateBatch(req *CreateBatchRequest) (*DeliveryBatchResponse, error)
+	GetBatch(id uint) (*DeliveryBatchResponse, error)
+	GetDriverRoute(driverID uint) (*DeliveryBatchResponse, error)
 
 	// Driver methods
 	CreateDriver(req *CreateDriverRequest) (*DeliveryDriverResponse, error)
 	GetDriver(id uint) (*DeliveryDriverResponse, error)
 	GetDriverByUserID(userID uint) (*DeliveryDriverResponse, error)
+	GetDriverByAccountID(accountID uuid.UUID) (*DeliveryDriverResponse, error)
 	UpdateDriverLocation(driverID uint, req *UpdateDriverLocationRequest) error
 	ListDrivers(limit, offset int, isActive *bool) ([]DeliveryDriverResponse, int64, error)
 	GetAvailableDrivers(vehicleType *VehicleType, lat, lng *float64, radius float64) ([]DeliveryDriverResponse, error)
@@ -42,19 +55,77 @@ type DeliveryService interface {
 	// Quote and pricing
 	GetDeliveryQuote(req *DeliveryQuoteRequest) (*DeliveryQuoteResponse, error)
 	CalculateDeliveryFee(distance float64, deliveryType string) int64
+	ApplySurge(feeKobo int64, zoneID *int, at time.Time) (int64, float64)
+	ApplyZoneSurge(zone *types.MatchResult, at time.Time)
+
+	// Surge windows
+	CreateSurgeWindow(adminID uuid.UUID, req *CreateSurgeWindowRequest) (*SurgeWindow, error)
+	ListSurgeWindows(includeEnded bool) ([]SurgeWindow, error)
+	DeleteSurgeWindow(id uint) error
 
 	// Stats methods
 	GetDeliveryStats(startDate, endDate *time.Time) (*DeliveryStatsResponse, error)
 	GetDriverStats(driverID uint, startDate, endDate *time.Time) (map[string]interface{}, error)
+	GetDriverEarnings(driverID uint, start, end time.Time) (*DriverEarningsResponse, error)
 	GetDeliveryByID(id uint) (*DeliveryResponse, error)
+
+	// Cash-on-delivery reconciliation
+	GetOutstandingCash(driverID *uint) (*OutstandingCashReport, error)
+	RemitDriverCash(driverID uint, adminID uuid.UUID, req *RemitCashRequest) (*CashRemittanceResponse, error)
+
+	// SetProofThreshold sets the order total (kobo) at or above which proof is required to mark delivered
+	SetProofThreshold(kobo int64)
+	// SetWeightPricing sets the free weight allowance and the per-kg surcharge above it
+	SetWeightPricing(freeGrams int, perKgKobo int64)
+	// SetDriverFeeShare sets the percentage of each delivery fee paid to the driver
+	SetDriverFeeShare(percent int)
+	// SetMaxFailedAttempts sets how many failed attempts a delivery gets before it needs an admin
+	SetMaxFailedAttempts(attempts int)
+	// SetZoneMatcher enables delivery time estimates from each zone's typical prep and transit times
+	SetZoneMatcher(matcher ZoneMatcher)
+	// WeightSurcharge returns the delivery surcharge (kobo) for an order of the given weight
+	WeightSurcharge(weightGrams int) int64
+
+	// Account data export and erasure (see auth.AccountDataSource)
+	ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error)
+	EraseUserData(ctx context.Context, userID uuid.UUID) error
+	// SetProofAssetStore enables deleting hosted proof photos and signatures on erasure
+	SetProofAssetStore(store ProofAssetStore)
 }
 
+// ErrProofOfDeliveryRequired is returned when a high-value delivery is marked delivered without proof
+var ErrProofOfDeliveryRequired = errors.New("proof of delivery (photo or signature) is required for this order")
+
+// ErrRecipientRequired is returned when a delivery has no recipient and none can be taken from its order
+var ErrRecipientRequired = errors.New("recipient name and phone are required")
+
+// DefaultMaxFailedAttempts is how many failed hand-overs a delivery gets before an admin has to step in
+const DefaultMaxFailedAttempts = 3
+
+// ErrDeliveryNotAttemptable is returned when a failed attempt is recorded on a delivery that is finished or waiting for an admin
+var ErrDeliveryNotAttemptable = errors.New("delivery is already finished or waiting for an admin; no more attempts can be recorded")
+
+// ErrDeliveryNotAssigned is returned when a driver acts on a delivery that is not assigned to them
+var ErrDeliveryNotAssigned = errors.New("delivery is not assigned to you")
+
 // deliveryService implements DeliveryService
 type deliveryService struct {
 	repo                DeliveryRepository
 	notificationService notifications.NotificationService
 	ordersRepo          *orders.Repository
 	customersService    customers.Service
+	proofThresholdKobo  int64 // 0 disables the proof requirement
+
+	// Weight surcharge: perKgSurchargeKobo for every started kg above freeWeightGrams (0 disables)
+	freeWeightGrams    int
+	perKgSurchargeKobo int64
+
+	driverFeeSharePercent int
+	maxFailedAttempts     int
+
+	zoneMatcher ZoneMatcher // nil estimates delivery times from distance alone
+
+	proofAssets ProofAssetStore // nil leaves hosted proof assets in place on erasure
 }
 
 // NewDeliveryService creates a new delivery service
@@ -64,7 +135,47 @@ func NewDeliveryService(repo DeliveryRepository, notificationService notificatio
 		notificationService: notificationService,
 		ordersRepo: ordersRepo,
 		customersService: customersService,
+		maxFailedAttempts: DefaultMaxFailedAttempts,
+	}
+}
+
+// SetProofThreshold sets the order total above which proof of delivery is mandatory
+func (s *deliveryService) SetProofThreshold(kobo int64) {
+	s.proofThresholdKobo = kobo
+}
+
+// SetWeightPricing configures the weight surcharge added on top of distance/zone delivery fees
+func (s *deliveryService) SetWeightPricing(freeGrams int, perKgKobo int64) {
+	s.freeWeightGrams = freeGrams
+	s.perKgSurchargeKobo = perKgKobo
+}
+
+// SetDriverFeeShare sets the driver's cut of delivery fees, clamped to 0-100
+func (s *deliveryService) SetDriverFeeShare(percent int) {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	s.driverFeeSharePercent = percent
+}
+
+// SetMaxFailedAttempts sets the attempt limit; values below 1 keep the default
+func (s *deliveryService) SetMaxFailedAttempts(attempts int) {
+	if attempts >= 1 {
+		s.maxFailedAttempts = attempts
+	}
+}
+
+// WeightSurcharge charges perKgSurchargeKobo for every started kilogram above the free allowance
+func (s *deliveryService) WeightSurcharge(weightGrams int) int64 {
+	excess := weightGrams - s.freeWeightGrams
+	if s.perKgSurchargeKobo <= 0 || excess <= 0 {
+		return 0
 	}
+	startedKg := int64((excess + 999) / 1000)
+	return startedKg * s.perKgSurchargeKobo
 }
 
 // generateTrackingNumber generates a unique tracking number
@@ -78,7 +189,8 @@ func (s *deliveryService) CreateDelivery(req *CreateDeliveryRequest) (*DeliveryR
 	// Calculate delivery fee and estimated time
 	distance := s.calculateDistance(req.PickupLatitude, req.PickupLongitude, req.DeliveryLatitude, req.DeliveryLongitude)
 	deliveryFee := s.CalculateDeliveryFee(distance, string(req.DeliveryType))
-	estimatedTime := s.calculateEstimatedTime(distance, req.DeliveryType)
+	zone := s.matchZone(req.DeliveryAddress, req.DeliveryLatitude, req.DeliveryLongitude)
+	estimatedTime := s.calculateEstimatedTime(distance, req.DeliveryType, zone)
 
 	delivery := &Delivery{
 		OrderID:           req.OrderID,
@@ -100,6 +212,10 @@ func (s *deliveryService) CreateDelivery(req *CreateDeliveryRequest) (*DeliveryR
 		DeliveryFee:       deliveryFee,
 		Distance:          &distance,
 	}
+	s.applyOrderRecipient(delivery)
+	if delivery.RecipientName == "" || delivery.RecipientPhone == "" {
+		return nil, ErrRecipientRequired
+	}
 
 	err := s.repo.CreateDelivery(delivery)
 	if err != nil {
@@ -128,6 +244,80 @@ func (s *deliveryService) GetDeliveryByTrackingNumber(trackingNumber string) (*D
 	return s.mapDeliveryToResponse(delivery), nil
 }
 
+// GetPublicTracking returns the PII-free tracking view used by shared tracking links
+func (s *deliveryService) GetPublicTracking(trackingNumber string) (*PublicTrackingResponse, error) {
+	delivery, err := s.repo.GetDeliveryByTrackingNumber(trackingNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PublicTrackingResponse{
+		TrackingNumber:  delivery.TrackingNumber,
+		Status:          delivery.Status,
+		DeliveryType:    delivery.DeliveryType,
+		ScheduledDate:   delivery.ScheduledDate,
+		EstimatedTime:   delivery.EstimatedTime,
+		TrackingUpdates: make([]PublicTrackingUpdate, len(delivery.TrackingUpdates)),
+	}
+	if delivery.Status == DeliveryStatusDelivered {
+		response.DeliveredAt = delivery.ActualTime
+	}
+	if delivery.Driver != nil {
+		response.DriverFirstName = delivery.Driver.FirstName
+	}
+	for i, update := range delivery.TrackingUpdates {
+		response.TrackingUpdates[i] = PublicTrackingUpdate{
+			Status:    update.Status,
+			Message:   update.Message,
+			Timestamp: update.Timestamp,
+		}
+	}
+
+	return response, nil
+}
+
+// TrackOrderDelivery returns the customer view of an order's delivery for order tracking, or nil
+// when the order has no delivery yet. Like public tracking, it leaves out contact details, proof
+// of delivery and fees, but names the vehicle so the customer can spot the driver.
+func (s *deliveryService) TrackOrderDelivery(orderID uuid.UUID) (*orders.DeliveryTracking, error) {
+	delivery, err := s.repo.GetDeliveryByOrderID(orderID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	tracking := &orders.DeliveryTracking{
+		TrackingNumber: delivery.TrackingNumber,
+		Status:         string(delivery.Status),
+		DeliveryType:   string(delivery.DeliveryType),
+		EstimatedTime:  delivery.EstimatedTime,
+		Timeline:       make([]orders.TrackingEvent, len(delivery.TrackingUpdates)),
+	}
+	if delivery.Status == DeliveryStatusDelivered {
+		tracking.DeliveredAt = delivery.ActualTime
+	}
+	if d := delivery.Driver; d != nil {
+		tracking.Driver = &orders.TrackingDriver{
+			FirstName:    d.FirstName,
+			VehicleType:  string(d.VehicleType),
+			VehicleModel: d.VehicleModel,
+			VehicleColor: d.VehicleColor,
+			VehiclePlate: d.VehiclePlate,
+			Rating:       d.Rating,
+		}
+	}
+	for i, update := range delivery.TrackingUpdates {
+		tracking.Timeline[i] = orders.TrackingEvent{
+			Status:    string(update.Status),
+			Message:   update.Message,
+			Timestamp: update.Timestamp,
+		}
+	}
+	return tracking, nil
+}
+
 func (s *deliveryService) GetDeliveryByOrderID(orderID string) (*DeliveryResponse, error) {
 	delivery, err := s.repo.GetDeliveryByOrderID(orderID)
 	if err != nil {
@@ -142,9 +332,23 @@ func (s *deliveryService) UpdateDeliveryStatus(id uint, req *UpdateDeliveryStatu
 		return nil, err
 	}
 
+	now := time.Now()
+	if req.Proof != nil {
+		if req.Proof.PhotoURL == "" && req.Proof.SignatureURL == "" {
+			return nil, errors.New("proof of delivery must include a photo or signature URL")
+		}
+		delivery.ProofPhotoURL = req.Proof.PhotoURL
+		delivery.ProofSignatureURL = req.Proof.SignatureURL
+		delivery.ProofRecipientName = req.Proof.RecipientName
+		delivery.ProofCapturedAt = &now
+	}
+
+	if req.Status == DeliveryStatusDelivered && delivery.ProofCapturedAt == nil && s.requiresProof(delivery) {
+		return nil, ErrProofOfDeliveryRequired
+	}
+
 	// Update status and timing
 	delivery.Status = req.Status
-	now := time.Now()
 
 	switch req.Status {
 	case DeliveryStatusPickedUp:
@@ -152,6 +356,7 @@ func (s *deliveryService) UpdateDeliveryStatus(id uint, req *UpdateDeliveryStatu
 	case DeliveryStatusDelivered:
 		delivery.DeliveryTime = &now
 		delivery.ActualTime = &now
+		s.syncTipFromOrder(delivery)
 	}
 
 	err = s.repo.UpdateDelivery(delivery)
@@ -161,6 +366,10 @@ func (s *deliveryService) UpdateDeliveryStatus(id uint, req *UpdateDeliveryStatu
 
 	// Add tracking update
 	s.AddTrackingUpdate(id, req.Status, req.Message, req.Latitude, req.Longitude)
+	s.completeBatchStop(delivery)
+	if req.Status == DeliveryStatusDelivered {
+		s.recordCashCollection(delivery)
+	}
 
 	// Send notification to customer about delivery status update
 	if s.notificationService != nil {
@@ -195,6 +404,7 @@ func (s *deliveryService) AssignDriver(id uint, driverID uint) (*DeliveryRespons
 
 	delivery.DriverID = &driverID
 	delivery.Status = DeliveryStatusAssigned
+	s.syncTipFromOrder(delivery)
 
 	err = s.repo.UpdateDelivery(delivery)
 	if err != nil {
@@ -255,8 +465,8 @@ func (s *deliveryService) CancelDelivery(id uint, reason string) (*DeliveryRespo
 		return nil, err
 	}
 
-	// Free up driver if assigned
-	if delivery.DriverID != nil {
+	// Free up driver if assigned; a batched driver still has the trip's other stops
+	if delivery.DriverID != nil && delivery.BatchID == nil {
 		driver, _ := s.repo.GetDriverByID(*delivery.DriverID)
 		if driver != nil {
 			driver.IsAvailable = true
@@ -266,14 +476,100 @@ func (s *deliveryService) CancelDelivery(id uint, reason string) (*DeliveryRespo
 
 	// Add tracking update
 	s.AddTrackingUpdate(id, DeliveryStatusCancelled, fmt.Sprintf("Delivery cancelled: %s", reason), nil, nil)
+	s.completeBatchStop(delivery)
 
 	return s.mapDeliveryToResponse(delivery), nil
 }
 
+// RecordFailedAttempt logs a hand-over the driver could not complete. The delivery moves to
+// failed_attempt and the customer is asked to reschedule; once maxFailedAttempts is reached it
+// moves to needs_attention instead and waits for an admin to resend, return or cancel it.
+func (s *deliveryService) RecordFailedAttempt(id uint, req *RecordFailedAttemptRequest) (*DeliveryResponse, error) {
+	delivery, err := s.repo.GetDeliveryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.recordFailedAttempt(delivery, req)
+}
+
+// RecordDriverFailedAttempt is RecordFailedAttempt from the driver app; the delivery must be
+// assigned to driverID
+func (s *deliveryService) RecordDriverFailedAttempt(driverID, id uint, req *RecordFailedAttemptRequest) (*DeliveryResponse, error) {
+	delivery, err := s.repo.GetDeliveryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.DriverID == nil || *delivery.DriverID != driverID {
+		return nil, ErrDeliveryNotAssigned
+	}
+	return s.recordFailedAttempt(delivery, req)
+}
+
+func (s *deliveryService) recordFailedAttempt(delivery *Delivery, req *RecordFailedAttemptRequest) (*DeliveryResponse, error) {
+	switch delivery.Status {
+	case DeliveryStatusDelivered, DeliveryStatusCancelled, DeliveryStatusReturned, DeliveryStatusNeedsAttention:
+		return nil, ErrDeliveryNotAttemptable
+	}
+
+	delivery.FailedAttempts++
+	delivery.LastFailureReason = req.Reason
+	delivery.Status = DeliveryStatusFailedAttempt
+	message := fmt.Sprintf("Delivery attempt %d failed: %s", delivery.FailedAttempts, req.Reason)
+	if delivery.FailedAttempts >= s.maxFailedAttempts {
+		delivery.Status = DeliveryStatusNeedsAttention
+		message = fmt.Sprintf("%s. Attempt limit reached, waiting for admin", message)
+	}
+
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	s.AddTrackingUpdate(delivery.ID, delivery.Status, message, req.Latitude, req.Longitude)
+	s.sendFailedAttemptNotification(delivery)
+
+	return s.mapDeliveryToResponse(delivery), nil
+}
+
+// sendFailedAttemptNotification tells the customer the driver missed them and prompts a reschedule
+func (s *deliveryService) sendFailedAttemptNotification(delivery *Delivery) {
+	if s.notificationService == nil {
+		return
+	}
+	customerID, err := s.getCustomerIDFromDelivery(delivery)
+	if err != nil {
+		return
+	}
+
+	body := fmt.Sprintf("Our driver couldn't deliver your order (%s). Tap to pick a new delivery time.", delivery.LastFailureReason)
+	if delivery.Status == DeliveryStatusNeedsAttention {
+		body = fmt.Sprintf("We couldn't deliver your order after %d attempts. Our team will contact you to arrange a new delivery.", delivery.FailedAttempts)
+	}
+
+	req := &notifications.CreateNotificationRequest{
+		RecipientID:   customerID,
+		RecipientType: notifications.RecipientCustomer,
+		Type:          notifications.TypeDeliveryUpdate,
+		Title:         "Delivery attempt missed",
+		Body:          body,
+		Data: map[string]interface{}{
+			"deliveryId":     delivery.ID,
+			"orderId":        delivery.OrderID,
+			"trackingNumber": delivery.TrackingNumber,
+			"failedAttempts": delivery.FailedAttempts,
+			"action":         "reschedule",
+		},
+	}
+	if _, err := s.notificationService.CreateNotification(req); err != nil {
+		fmt.Printf("Failed to send failed attempt notification: %v\n", err)
+	}
+}
+
 // Driver methods implementation
 func (s *deliveryService) CreateDriver(req *CreateDriverRequest) (*DeliveryDriverResponse, error) {
 	driver := &DeliveryDriver{
 		UserID:           req.UserID,
+		AccountID:        req.AccountID,
+		FirstName:        strings.TrimSpace(req.FirstName),
 		LicenseNumber:    req.LicenseNumber,
 		VehicleType:      req.VehicleType,
 		VehicleNumber:    req.VehicleNumber,
@@ -309,8 +605,52 @@ func (s *deliveryService) GetDriverByUserID(userID uint) (*DeliveryDriverRespons
 	return s.mapDriverToResponse(driver), nil
 }
 
+// GetDriverByAccountID returns the driver linked to a signed-in user account
+func (s *deliveryService) GetDriverByAccountID(accountID uuid.UUID) (*DeliveryDriverResponse, error) {
+	driver, err := s.repo.GetDriverByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapDriverToResponse(driver), nil
+}
+
+// UpdateDriverLocation records the driver's position and adds the leg traveled since their last
+// ping to the delivery they are currently on
 func (s *deliveryService) UpdateDriverLocation(driverID uint, req *UpdateDriverLocationRequest) error {
-	return s.repo.UpdateDriverLocation(driverID, req.Latitude, req.Longitude)
+	driver, err := s.repo.GetDriverByID(driverID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateDriverLocation(driverID, req.Latitude, req.Longitude); err != nil {
+		return err
+	}
+
+	leg := traveledLegKm(driver.CurrentLatitude, driver.CurrentLongitude, driver.LastLocationUpdate, req.Latitude, req.Longitude, time.Now())
+	if leg > 0 {
+		return s.repo.AddActualDistance(driverID, leg)
+	}
+	return nil
+}
+
+// maxPlausibleSpeedKmh is the fastest a driver is believed to move between two pings; faster
+// legs are GPS jumps and are not counted as distance traveled
+const maxPlausibleSpeedKmh = 150
+
+// traveledLegKm is the distance between a driver's previous and current positions, or 0 when
+// there is no previous position or the implied speed is not plausible
+func traveledLegKm(prevLat, prevLng *float64, prevAt *time.Time, lat, lng float64, now time.Time) float64 {
+	if prevLat == nil || prevLng == nil || prevAt == nil {
+		return 0
+	}
+	elapsed := now.Sub(*prevAt).Hours()
+	if elapsed <= 0 {
+		return 0
+	}
+	km := haversineKm(*prevLat, *prevLng, lat, lng)
+	if km/elapsed > maxPlausibleSpeedKmh {
+		return 0
+	}
+	return km
 }
 
 func (s *deliveryService) ListDrivers(limit, offset int, isActive *bool) ([]DeliveryDriverResponse, int64, error) {
@@ -387,25 +727,40 @@ func (s *deliveryService) AddTrackingUpdate(deliveryID uint, status DeliveryStat
 // Quote and pricing implementation
 func (s *deliveryService) GetDeliveryQuote(req *DeliveryQuoteRequest) (*DeliveryQuoteResponse, error) {
 	distance := s.calculateDistance(&req.PickupLatitude, &req.PickupLongitude, &req.DeliveryLatitude, &req.DeliveryLongitude)
-	estimatedTime := s.calculateEstimatedTime(distance, req.DeliveryType)
-	deliveryFee := s.CalculateDeliveryFee(distance, string(req.DeliveryType))
+	zone := s.matchZone("", &req.DeliveryLatitude, &req.DeliveryLongitude)
 
-	now := time.Now()
-	estimatedPickup := now.Add(30 * time.Minute) // 30 minutes from now
-	estimatedDelivery := estimatedPickup.Add(time.Duration(estimatedTime.Sub(time.Now()).Minutes()) * time.Minute)
+	// Surge follows the time the order would be placed, as at checkout, not the scheduled date
+	var zoneID *int
+	if zone != nil {
+		zoneID = &zone.ZoneID
+	}
+	deliveryFee, surge := s.ApplySurge(s.CalculateDeliveryFee(distance, string(req.DeliveryType)), zoneID, time.Now())
+	deliveryFee += s.WeightSurcharge(req.WeightGrams)
+
+	// Pickup waits for the zone's prep time (30 minutes if unset); the ride follows
+	prep, ride := estimateMinutes(distance, req.DeliveryType, zone)
+	if zone == nil || zone.PrepMinutes == 0 {
+		prep = defaultQuotePickupMinutes
+	}
 
+	estimatedPickup := time.Now().Add(time.Duration(prep) * time.Minute)
 	if req.ScheduledDate != nil {
 		estimatedPickup = *req.ScheduledDate
-		estimatedDelivery = req.ScheduledDate.Add(time.Duration(estimatedTime.Sub(time.Now()).Minutes()) * time.Minute)
 	}
+	estimatedDelivery := estimatedPickup.Add(time.Duration(ride) * time.Minute)
 
-	return &DeliveryQuoteResponse{
+	quote := &DeliveryQuoteResponse{
 		Distance:          distance,
-		EstimatedTime:     int(estimatedTime.Sub(time.Now()).Minutes()),
+		EstimatedTime:     ride,
 		DeliveryFee:       deliveryFee,
+		SurgeMultiplier:   surge,
 		EstimatedPickup:   &estimatedPickup,
 		EstimatedDelivery: &estimatedDelivery,
-	}, nil
+	}
+	if surge > 1 {
+		quote.HighDemandNote = highDemandNote(surge)
+	}
+	return quote, nil
 }
 
 func (s *deliveryService) CalculateDeliveryFee(distance float64, deliveryType string) int64 {
@@ -438,41 +793,74 @@ func (s *deliveryService) GetDriverStats(driverID uint, startDate, endDate *time
 	return s.repo.GetDriverStats(driverID, startDate, endDate)
 }
 
+// GetDriverEarnings builds a driver's statement from their completed deliveries: the configured
+// share of each delivery fee plus the whole tip.
+func (s *deliveryService) GetDriverEarnings(driverID uint, start, end time.Time) (*DriverEarningsResponse, error) {
+	if !end.After(start) {
+		return nil, errors.New("end must be after start")
+	}
+	if _, err := s.repo.GetDriverByID(driverID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.GetCompletedDeliveriesByDriver(driverID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &DriverEarningsResponse{
+		DriverID:        driverID,
+		Start:           start,
+		End:             end,
+		FeeSharePercent: s.driverFeeSharePercent,
+		Deliveries:      make([]DriverEarningsLine, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		feeShare := int64(math.Round(float64(d.DeliveryFee) * float64(s.driverFeeSharePercent) / 100))
+		line := DriverEarningsLine{
+			DeliveryID:     d.ID,
+			OrderID:        d.OrderID,
+			TrackingNumber: d.TrackingNumber,
+			DeliveryFee:    d.DeliveryFee,
+			FeeShare:       feeShare,
+			Tip:            d.TipKobo,
+			Total:          feeShare + d.TipKobo,
+		}
+		if d.DeliveryTime != nil {
+			line.DeliveredAt = *d.DeliveryTime
+		}
+		statement.Deliveries = append(statement.Deliveries, line)
+		statement.TotalFeeShare += line.FeeShare
+		statement.TotalTips += line.Tip
+	}
+	statement.DeliveryCount = len(statement.Deliveries)
+	statement.TotalEarnings = statement.TotalFeeShare + statement.TotalTips
+
+	return statement, nil
+}
+
 // Helper methods
 func (s *deliveryService) calculateDistance(lat1, lng1, lat2, lng2 *float64) float64 {
 	if lat1 == nil || lng1 == nil || lat2 == nil || lng2 == nil {
 		return 5.0 // Default 5km if coordinates not provided
 	}
 
-	// Haversine formula for calculating distance between two points
+	return haversineKm(*lat1, *lng1, *lat2, *lng2)
+}
+
+// haversineKm is the great-circle distance between two points in kilometers
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
 	const earthRadius = 6371 // Earth's radius in kilometers
 
-	dLat := (*lat2 - *lat1) * math.Pi / 180
-	dLng := (*lng2 - *lng1) * math.Pi / 180
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
 
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(*lat1*math.Pi/180)*math.Cos(*lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
 	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
 
 	return earthRadius * c
 }
 
-func (s *deliveryService) calculateEstimatedTime(distance float64, deliveryType DeliveryType) time.Time {
-	// Base speed in km/h
-	speed := 30.0
-
-	switch deliveryType {
-	case DeliveryTypeExpress:
-		speed = 45.0
-	case DeliveryTypeSameDay:
-		speed = 50.0
-	}
-
-	timeInHours := distance / speed
-	timeInMinutes := timeInHours * 60
-
-	return time.Now().Add(time.Duration(timeInMinutes) * time.Minute)
-}
-
 func (s *deliveryService) mapDeliveryToResponse(delivery *Delivery) *DeliveryResponse {
 	response := &DeliveryResponse{
 		ID:                delivery.ID,
@@ -496,12 +884,27 @@ func (s *deliveryService) mapDeliveryToResponse(delivery *Delivery) *DeliveryRes
 		EstimatedTime:     delivery.EstimatedTime,
 		ActualTime:        delivery.ActualTime,
 		DeliveryFee:       delivery.DeliveryFee,
+		TipKobo:           delivery.TipKobo,
 		Distance:          delivery.Distance,
+		ActualDistance:    delivery.ActualDistance,
 		Duration:          delivery.Duration,
+		FailedAttempts:    delivery.FailedAttempts,
+		LastFailureReason: delivery.LastFailureReason,
+		BatchID:           delivery.BatchID,
+		BatchSequence:     delivery.BatchSequence,
 		CreatedAt:         delivery.CreatedAt,
 		UpdatedAt:         delivery.UpdatedAt,
 	}
 
+	if delivery.ProofCapturedAt != nil {
+		response.Proof = &DeliveryProofResponse{
+			PhotoURL:      delivery.ProofPhotoURL,
+			SignatureURL:  delivery.ProofSignatureURL,
+			RecipientName: delivery.ProofRecipientName,
+			CapturedAt:    *delivery.ProofCapturedAt,
+		}
+	}
+
 	if len(delivery.TrackingUpdates) > 0 {
 		response.TrackingUpdates = make([]TrackingUpdateResponse, len(delivery.TrackingUpdates))
 		for i, update := range delivery.TrackingUpdates {
@@ -530,6 +933,8 @@ func (s *deliveryService) mapDriverToResponse(driver *DeliveryDriver) *DeliveryD
 	return &DeliveryDriverResponse{
 		ID:                 driver.ID,
 		UserID:             driver.UserID,
+		AccountID:          driver.AccountID,
+		FirstName:          driver.FirstName,
 		LicenseNumber:      driver.LicenseNumber,
 		VehicleType:        driver.VehicleType,
 		VehicleNumber:      driver.VehicleNumber,
@@ -549,6 +954,88 @@ func (s *deliveryService) mapDriverToResponse(driver *DeliveryDriver) *DeliveryD
 	}
 }
 
+// requiresProof reports whether the delivery's order total meets the proof threshold.
+// If the order can't be loaded we err on the side of requiring proof.
+func (s *deliveryService) requiresProof(delivery *Delivery) bool {
+	if s.proofThresholdKobo <= 0 {
+		return false
+	}
+	if s.ordersRepo == nil {
+		return true
+	}
+	orderUUID, err := uuid.Parse(delivery.OrderID)
+	if err != nil {
+		return true
+	}
+	order, err := s.ordersRepo.AdminGet(context.Background(), orderUUID)
+	if err != nil {
+		return true
+	}
+	return order.TotalAmount >= s.proofThresholdKobo
+}
+
+// syncTipFromOrder copies the order's tip onto the delivery so it is credited to the
+// assigned driver; lookup failures keep the current value.
+func (s *deliveryService) syncTipFromOrder(delivery *Delivery) {
+	if s.ordersRepo == nil {
+		return
+	}
+	orderUUID, err := uuid.Parse(delivery.OrderID)
+	if err != nil {
+		return
+	}
+	order, err := s.ordersRepo.AdminGet(context.Background(), orderUUID)
+	if err != nil {
+		fmt.Printf("Failed to load order %s for tip attribution: %v\n", delivery.OrderID, err)
+		return
+	}
+	delivery.TipKobo = order.TipKobo
+}
+
+// applyOrderRecipient fills recipient details the request left out: first from the
+// recipient given at checkout, then from the customer's own profile. The order's
+// delivery instructions become the delivery notes unless notes were given.
+func (s *deliveryService) applyOrderRecipient(delivery *Delivery) {
+	if s.ordersRepo == nil {
+		return
+	}
+	orderUUID, err := uuid.Parse(delivery.OrderID)
+	if err != nil {
+		return
+	}
+	order, err := s.ordersRepo.AdminGet(context.Background(), orderUUID)
+	if err != nil {
+		fmt.Printf("Failed to load order %s for recipient details: %v\n", delivery.OrderID, err)
+		return
+	}
+
+	if delivery.DeliveryNotes == "" {
+		delivery.DeliveryNotes = order.DeliveryInstructions
+	}
+	if delivery.RecipientName == "" && delivery.RecipientPhone == "" && order.RecipientName != "" {
+		delivery.RecipientName = order.RecipientName
+		delivery.RecipientPhone = order.RecipientPhone
+	}
+	if delivery.RecipientName != "" && delivery.RecipientPhone != "" {
+		return
+	}
+
+	if s.customersService == nil {
+		return
+	}
+	customer, err := s.customersService.GetCustomerByUserID(order.CustomerID)
+	if err != nil {
+		fmt.Printf("Failed to load customer for order %s recipient details: %v\n", delivery.OrderID, err)
+		return
+	}
+	if delivery.RecipientName == "" {
+		delivery.RecipientName = strings.TrimSpace(customer.FirstName + " " + customer.LastName)
+	}
+	if delivery.RecipientPhone == "" {
+		delivery.RecipientPhone = customer.Phone
+	}
+}
+
 // getCustomerIDFromDelivery gets the customer ID from the delivery's associated order
 func (s *deliveryService) getCustomerIDFromDelivery(delivery *Delivery) (uuid.UUID, error) {
 	// Parse the order UUID from the delivery
@@ -0,0 +1,170 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"errandShop/internal/core/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxSurgeMultiplier caps how far a surge window can raise delivery fees
+const MaxSurgeMultiplier = 5.0
+
+// ErrInvalidSurgeWindow is returned when a surge window's time range or multiplier is not usable
+var ErrInvalidSurgeWindow = errors.New("invalid surge window")
+
+// SurgeWindow raises delivery fees by Multiplier from StartsAt until EndsAt, in one zone or, with
+// no zone, everywhere. Where windows overlap the highest multiplier applies; they do not stack.
+type SurgeWindow struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"size:100"` // e.g. "Friday evening rush"
+	ZoneID     *int       `json:"zone_id" gorm:"index"` // nil applies to every zone and to fallback pricing
+	Multiplier float64    `json:"multiplier" gorm:"type:numeric(4,2);not null"`
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null;index"`
+	EndsAt     time.Time  `json:"ends_at" gorm:"not null;index"`
+	CreatedBy  *uuid.UUID `json:"created_by" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateSurgeWindow schedules a surge window. Windows that have already ended are rejected.
+func (s *deliveryService) CreateSurgeWindow(adminID uuid.UUID, req *CreateSurgeWindowRequest) (*SurgeWindow, error) {
+	multiplier := math.Round(req.Multiplier*100) / 100
+	if multiplier <= 1 || multiplier > MaxSurgeMultiplier {
+		return nil, fmt.Errorf("%w: multiplier must be above 1 and at most %g", ErrInvalidSurgeWindow, MaxSurgeMultiplier)
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSurgeWindow)
+	}
+	if !req.EndsAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the window has already ended", ErrInvalidSurgeWindow)
+	}
+
+	window := &SurgeWindow{
+		Name:       strings.TrimSpace(req.Name),
+		ZoneID:     req.ZoneID,
+		Multiplier: multiplier,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		CreatedBy:  &adminID,
+	}
+	if err := s.repo.CreateSurgeWindow(window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// ListSurgeWindows returns current and upcoming surge windows, or every window with includeEnded
+func (s *deliveryService) ListSurgeWindows(includeEnded bool) ([]SurgeWindow, error) {
+	var endedAfter *time.Time
+	if !includeEnded {
+		now := time.Now()
+		endedAfter = &now
+	}
+	return s.repo.ListSurgeWindows(endedAfter)
+}
+
+// DeleteSurgeWindow removes a surge window. Orders already priced in it keep their fee.
+func (s *deliveryService) DeleteSurgeWindow(id uint) error {
+	return s.repo.DeleteSurgeWindow(id)
+}
+
+// ApplySurge raises a delivery fee (kobo) by the surge in effect for the zone at the given time,
+// returning the fee and the multiplier applied (1 outside surge windows). zoneID nil means the
+// address matched no zone, so only windows covering every zone apply. The quote, the zone
+// estimate and the order's charge all price through here, so they agree for the same moment.
+func (s *deliveryService) ApplySurge(feeKobo int64, zoneID *int, at time.Time) (int64, float64) {
+	windows, err := s.repo.GetActiveSurgeWindows(zoneID, at)
+	if err != nil {
+		// Charge the usual fee rather than failing checkout
+		fmt.Printf("Failed to load surge windows: %v\n", err)
+		return feeKobo, 1
+	}
+	multiplier := surgeMultiplier(windows)
+	return surgedFee(feeKobo, multiplier), multiplier
+}
+
+// ApplyZoneSurge raises a matched zone's price (naira) by the surge in effect at the given time
+// and notes the surge on the result, for the estimate and confirmation endpoints
+func (s *deliveryService) ApplyZoneSurge(zone *types.MatchResult, at time.Time) {
+	zoneID := zone.ZoneID
+	feeKobo, multiplier := s.ApplySurge(int64(zone.Price)*100, &zoneID, at)
+	if multiplier <= 1 {
+		return
+	}
+	zone.Price = int(feeKobo / 100)
+	zone.SurgeMultiplier = multiplier
+	zone.HighDemandNote = highDemandNote(multiplier)
+}
+
+// surgeMultiplier is the highest multiplier among the active windows, or 1 when there are none
+func surgeMultiplier(windows []SurgeWindow) float64 {
+	multiplier := 1.0
+	for _, w := range windows {
+		if w.Multiplier > multiplier {
+			multiplier = w.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// surgedFee multiplies a fee (kobo) and rounds it to the nearest naira, so a zone price shown in
+// naira and the same fee charged in kobo come out identical
+func surgedFee(feeKobo int64, multiplier float64) int64 {
+	if multiplier <= 1 {
+		return feeKobo
+	}
+	return int64(math.Round(float64(feeKobo)*multiplier/100)) * 100
+}
+
+// highDemandNote is the customer-facing explanation of a surge
+func highDemandNote(multiplier float64) string {
+	return fmt.Sprintf("High demand right now: delivery fees are %gx the usual rate", multiplier)
+}
+
+// CreateSurgeWindow saves a new surge window
+func (r *deliveryRepository) CreateSurgeWindow(window *SurgeWindow) error {
+	return r.db.Create(window).Error
+}
+
+// ListSurgeWindows returns surge windows by start time, only those ending after endedAfter when set
+func (r *deliveryRepository) ListSurgeWindows(endedAfter *time.Time) ([]SurgeWindow, error) {
+	var windows []SurgeWindow
+	query := r.db.Model(&SurgeWindow{})
+	if endedAfter != nil {
+		query = query.Where("ends_at > ?", *endedAfter)
+	}
+	err := query.Order("starts_at ASC, id ASC").Find(&windows).Error
+	return windows, err
+}
+
+// DeleteSurgeWindow deletes a surge window, or returns gorm.ErrRecordNotFound
+func (r *deliveryRepository) DeleteSurgeWindow(id uint) error {
+	result := r.db.Delete(&SurgeWindow{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetActiveSurgeWindows returns the windows covering the given time that apply to every zone or
+// to zoneID
+func (r *deliveryRepository) GetActiveSurgeWindows(zoneID *int, at time.Time) ([]SurgeWindow, error) {
+	var windows []SurgeWindow
+	query := r.db.Where("starts_at <= ? AND ends_at > ?", at, at)
+	if zoneID != nil {
+		query = query.Where("zone_id IS NULL OR zone_id = ?", *zoneID)
+	} else {
+		query = query.Where("zone_id IS NULL")
+	}
+	err := query.Find(&windows).Error
+	return windows, err
+}
@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"testing"
+	"time"
+
+	"errandShop/internal/core/types"
+)
+
+// surgeRepo serves fixed active surge windows, filtered by zone like the real query
+type surgeRepo struct {
+	DeliveryRepository
+	windows []SurgeWindow
+}
+
+func (r *surgeRepo) GetActiveSurgeWindows(zoneID *int, at time.Time) ([]SurgeWindow, error) {
+	var active []SurgeWindow
+	for _, w := range r.windows {
+		if w.StartsAt.After(at) || !w.EndsAt.After(at) {
+			continue
+		}
+		if w.ZoneID == nil || (zoneID != nil && *w.ZoneID == *zoneID) {
+			active = append(active, w)
+		}
+	}
+	return active, nil
+}
+
+func TestApplySurge(t *testing.T) {
+	start := time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)
+	zone := 2
+	s := &deliveryService{repo: &surgeRepo{windows: []SurgeWindow{
+		{ID: 1, Multiplier: 1.25, StartsAt: start, EndsAt: start.Add(2 * time.Hour)},
+		{ID: 2, ZoneID: &zone, Multiplier: 1.5, StartsAt: start, EndsAt: start.Add(time.Hour)},
+	}}}
+	otherZone := 3
+
+	tests := []struct {
+		name     string
+		zoneID   *int
+		at       time.Time
+		wantFee  int64
+		wantMult float64
+	}{
+		{"before any window", &zone, start.Add(-time.Minute), 150_000, 1},
+		{"the highest overlapping window wins", &zone, start.Add(30 * time.Minute), 225_000, 1.5},
+		{"other zones only get the global window", &otherZone, start.Add(30 * time.Minute), 187_500, 1.25},
+		{"fallback pricing gets the global window", nil, start.Add(30 * time.Minute), 187_500, 1.25},
+		{"the zone window has ended", &zone, start.Add(time.Hour), 187_500, 1.25},
+		{"every window has ended", &zone, start.Add(2 * time.Hour), 150_000, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, mult := s.ApplySurge(150_000, tt.zoneID, tt.at)
+			if fee != tt.wantFee || mult != tt.wantMult {
+				t.Fatalf("ApplySurge = (%d, %g), want (%d, %g)", fee, mult, tt.wantFee, tt.wantMult)
+			}
+		})
+	}
+}
+
+func TestApplyZoneSurgeMatchesCharge(t *testing.T) {
+	start := time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)
+	s := &deliveryService{repo: &surgeRepo{windows: []SurgeWindow{
+		{ID: 1, Multiplier: 1.35, StartsAt: start, EndsAt: start.Add(time.Hour)},
+	}}}
+	at := start.Add(time.Minute)
+
+	// The estimate shows naira and the order charges kobo; both must land on the same amount
+	zone := &types.MatchResult{ZoneID: 4, Price: 1_333}
+	s.ApplyZoneSurge(zone, at)
+	zoneID := 4
+	charged, _ := s.ApplySurge(1_333*100, &zoneID, at)
+	if int64(zone.Price)*100 != charged {
+		t.Fatalf("estimate shows ₦%d but the order charges %d kobo", zone.Price, charged)
+	}
+	if zone.SurgeMultiplier != 1.35 || zone.HighDemandNote == "" {
+		t.Fatalf("surge not noted on the estimate: %+v", zone)
+	}
+
+	calm := &types.MatchResult{ZoneID: 4, Price: 1_333}
+	s.ApplyZoneSurge(calm, start.Add(-time.Minute))
+	if calm.Price != 1_333 || calm.SurgeMultiplier != 0 || calm.HighDemandNote != "" {
+		t.Fatalf("estimate changed outside a surge window: %+v", calm)
+	}
+}
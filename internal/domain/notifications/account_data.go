@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportUserData returns the devices the customer registered for push notifications
+func (s *notificationService) ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error) {
+	tokens, err := s.pushTokenRepo.GetByUserID(userID, string(RecipientCustomer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load push tokens: %w", err)
+	}
+
+	responses := make([]PushTokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = PushTokenResponse{
+			ID:         token.ID,
+			Token:      token.Token,
+			Platform:   token.Platform,
+			DeviceID:   token.DeviceID,
+			IsActive:   token.IsActive,
+			LastUsedAt: token.LastUsedAt,
+			CreatedAt:  token.CreatedAt,
+		}
+	}
+	return responses, nil
+}
+
+// EraseUserData removes every push token the user registered, so an erased account gets no more pushes
+func (s *notificationService) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	if err := s.pushTokenRepo.DeleteByUserID(userID); err != nil {
+		return fmt.Errorf("failed to remove push tokens: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUserID deletes the user's push tokens, including those registered through the FCM endpoints
+func (r *pushTokenRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&PushToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&FCMToken{}).Error
+	})
+}
@@ -0,0 +1,108 @@
+package notifications_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	notifications "errandShop/internal/domain/notifications"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newBroadcastTestService(t *testing.T) (notifications.NotificationService, *gorm.DB) {
+	t.Helper()
+	// A file rather than :memory: so the background push sees the same database
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "notifications.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite db: %v", err)
+	}
+	if err := db.AutoMigrate(&notifications.Notification{}, &notifications.NotificationPreference{}, &notifications.PushToken{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	service := notifications.NewNotificationService(
+		notifications.NewNotificationRepository(db),
+		notifications.NewTemplateRepository(db),
+		notifications.NewPushTokenRepository(db),
+	)
+	return service, db
+}
+
+func addPushRecipient(t *testing.T, db *gorm.DB, pref *notifications.NotificationPreference) uuid.UUID {
+	t.Helper()
+	userID := uuid.New()
+	for _, token := range []string{"phone-" + userID.String(), "tablet-" + userID.String()} {
+		if err := db.Create(&notifications.PushToken{UserID: userID, UserType: "customer", Token: token, Platform: "android", IsActive: true}).Error; err != nil {
+			t.Fatalf("failed to add push token: %v", err)
+		}
+	}
+	if pref != nil {
+		pref.RecipientID, pref.RecipientType = userID, notifications.RecipientCustomer
+		if err := db.Create(pref).Error; err != nil {
+			t.Fatalf("failed to save preference: %v", err)
+		}
+	}
+	return userID
+}
+
+func broadcastReceived(t *testing.T, db *gorm.DB, userID uuid.UUID) notifications.Notification {
+	t.Helper()
+	var received []notifications.Notification
+	if err := db.Where("recipient_id = ?", userID).Find(&received).Error; err != nil {
+		t.Fatalf("failed to load notifications: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("recipient %s has %d notifications, want exactly one", userID, len(received))
+	}
+	return received[0]
+}
+
+func TestBroadcastHoldsPushDuringQuietHours(t *testing.T) {
+	service, db := newBroadcastTestService(t)
+
+	now := time.Now().UTC()
+	quiet := addPushRecipient(t, db, &notifications.NotificationPreference{
+		QuietHoursStart: now.Add(-time.Hour).Format("15:04"),
+		QuietHoursEnd:   now.Add(time.Hour).Format("15:04"),
+		Timezone:        "UTC",
+	})
+	awake := addPushRecipient(t, db, nil)
+
+	if err := service.SendBroadcastNotification(&notifications.BroadcastNotificationRequest{Title: "Store update", Body: "We now deliver on Sundays"}); err != nil {
+		t.Fatalf("SendBroadcastNotification: %v", err)
+	}
+
+	held := broadcastReceived(t, db, quiet)
+	if held.SendStatus != notifications.SendQuietHours || held.NextSendAt == nil || !held.NextSendAt.After(now) {
+		t.Fatalf("quiet recipient's broadcast is %s (next %v), want held until quiet hours end", held.SendStatus, held.NextSendAt)
+	}
+	if sent := broadcastReceived(t, db, awake); sent.SendStatus == notifications.SendQuietHours {
+		t.Fatalf("recipient outside quiet hours had the broadcast held")
+	}
+}
+
+func TestBroadcastPromotionsWaitForDigest(t *testing.T) {
+	service, db := newBroadcastTestService(t)
+	digest := addPushRecipient(t, db, &notifications.NotificationPreference{DigestEnabled: true})
+
+	if err := service.SendBroadcastNotification(&notifications.BroadcastNotificationRequest{Title: "Weekend sale", Body: "10% off groceries"}); err != nil {
+		t.Fatalf("SendBroadcastNotification: %v", err)
+	}
+	if held := broadcastReceived(t, db, digest); held.Type != notifications.TypePromotion || held.SendStatus != notifications.SendDigestPending {
+		t.Fatalf("promotion broadcast is %s/%s, want a promotion held for the digest", held.Type, held.SendStatus)
+	}
+
+	// Service announcements are not digest material and go out straight away
+	if err := service.SendBroadcastNotification(&notifications.BroadcastNotificationRequest{Type: notifications.TypeSystem, Title: "Store update", Body: "We now deliver on Sundays"}); err != nil {
+		t.Fatalf("SendBroadcastNotification: %v", err)
+	}
+	var system notifications.Notification
+	if err := db.Where("recipient_id = ? AND type = ?", digest, notifications.TypeSystem).First(&system).Error; err != nil {
+		t.Fatalf("failed to load system broadcast: %v", err)
+	}
+	if system.SendStatus == notifications.SendDigestPending {
+		t.Fatalf("system broadcast was held for the digest")
+	}
+}
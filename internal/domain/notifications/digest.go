@@ -0,0 +1,241 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// digestTypes are the low-priority types held for the daily digest when a recipient opts in.
+// Transactional types (order, delivery and payment updates) are always sent straight away.
+var digestTypes = map[NotificationType]bool{
+	TypePromotion: true,
+}
+
+// digestPreviewTitles is how many held notifications are named in the digest push body
+const digestPreviewTitles = 3
+
+// DigestEmailer sends the daily digest by email
+type DigestEmailer interface {
+	SendDigestEmail(ctx context.Context, to, subject string, lines []string) error
+}
+
+// EmailLookup returns a user's verified email address, or "" if they have none
+type EmailLookup func(ctx context.Context, userID uuid.UUID) (string, error)
+
+// Digest is one recipient's held notifications rolled up into a single notification
+type Digest struct {
+	RecipientID     uuid.UUID
+	RecipientType   NotificationRecipient
+	NotificationIDs []uint
+	Title           string
+	Body            string
+	Lines           []string // one "title: body" line per held notification, oldest first
+}
+
+// BuildDigests groups held notifications by recipient, keeping the order recipients first appear in
+func BuildDigests(pending []Notification) []Digest {
+	type recipientKey struct {
+		id  uuid.UUID
+		typ NotificationRecipient
+	}
+
+	digests := make([]Digest, 0)
+	titles := make([][]string, 0)
+	index := make(map[recipientKey]int)
+	for _, n := range pending {
+		key := recipientKey{n.RecipientID, n.RecipientType}
+		i, ok := index[key]
+		if !ok {
+			i = len(digests)
+			index[key] = i
+			digests = append(digests, Digest{RecipientID: n.RecipientID, RecipientType: n.RecipientType})
+			titles = append(titles, nil)
+		}
+		d := &digests[i]
+		d.NotificationIDs = append(d.NotificationIDs, n.ID)
+		d.Lines = append(d.Lines, fmt.Sprintf("%s: %s", n.Title, n.Body))
+		titles[i] = append(titles[i], n.Title)
+	}
+
+	for i := range digests {
+		d := &digests[i]
+		count := len(d.NotificationIDs)
+		if count == 1 {
+			d.Title = "Your daily digest: 1 update"
+		} else {
+			d.Title = fmt.Sprintf("Your daily digest: %d updates", count)
+		}
+		shown := titles[i]
+		if len(shown) > digestPreviewTitles {
+			shown = shown[:digestPreviewTitles]
+		}
+		d.Body = strings.Join(shown, "; ")
+		if more := count - len(shown); more > 0 {
+			d.Body += fmt.Sprintf(" and %d more", more)
+		}
+	}
+	return digests
+}
+
+// NextDigestRun returns the first time after now when the clock reads hour:00
+func NextDigestRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SetDigestEmailer also sends each digest by email; a nil emailer leaves digests as push only
+func (s *notificationService) SetDigestEmailer(emailer DigestEmailer, emailLookup EmailLookup) {
+	s.digestEmailer = emailer
+	s.emailLookup = emailLookup
+}
+
+// heldForDigest reports whether a new notification should wait for the recipient's daily digest.
+// If the preference cannot be read the notification is sent straight away.
+func (s *notificationService) heldForDigest(recipientID uuid.UUID, recipientType NotificationRecipient, notificationType NotificationType) bool {
+	if !digestTypes[notificationType] {
+		return false
+	}
+	pref, err := s.notificationRepo.GetPreference(recipientID, recipientType)
+	if err != nil {
+		log.Printf("Failed to load notification preferences for %s: %v", recipientID, err)
+		return false
+	}
+	return pref.DigestEnabled
+}
+
+// GetPreferences returns the recipient's notification preferences
+func (s *notificationService) GetPreferences(recipientID uuid.UUID, recipientType NotificationRecipient) (*NotificationPreferenceResponse, error) {
+	pref, err := s.notificationRepo.GetPreference(recipientID, recipientType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return toPreferenceResponse(pref), nil
+}
+
+// UpdatePreferences saves the recipient's notification preferences. Notifications already held
+// still go out in the next digest after digest mode is turned off, and those held for quiet hours
+// at the end of the window they arrived in. A new locale applies to notifications created from
+// then on.
+func (s *notificationService) UpdatePreferences(recipientID uuid.UUID, recipientType NotificationRecipient, req *UpdateNotificationPreferenceRequest) (*NotificationPreferenceResponse, error) {
+	pref, err := s.notificationRepo.GetPreference(recipientID, recipientType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if req.DigestEnabled != nil {
+		pref.DigestEnabled = *req.DigestEnabled
+	}
+	if req.Locale != nil {
+		pref.Locale = normalizeLocale(*req.Locale)
+	}
+	if err := applyQuietHours(pref, req); err != nil {
+		return nil, err
+	}
+	if err := s.notificationRepo.SavePreference(pref); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return toPreferenceResponse(pref), nil
+}
+
+// SendDigests rolls every held notification up into one digest per recipient and returns how
+// many digests were sent
+func (s *notificationService) SendDigests(limit int) (int, error) {
+	sent := 0
+	for {
+		pending, err := s.notificationRepo.GetDigestPending(limit)
+		if err != nil {
+			return sent, fmt.Errorf("failed to load held notifications: %w", err)
+		}
+
+		marked := 0
+		for _, digest := range BuildDigests(pending) {
+			if err := s.sendDigest(digest); err != nil {
+				log.Printf("Failed to send digest to %s: %v", digest.RecipientID, err)
+				continue
+			}
+			sent++
+			marked += len(digest.NotificationIDs)
+		}
+
+		// A short batch means everything held has been seen; no progress means every send failed
+		if len(pending) < limit || marked == 0 {
+			return sent, nil
+		}
+	}
+}
+
+// sendDigest stores and pushes a digest notification, then marks the notifications it covers
+func (s *notificationService) sendDigest(digest Digest) error {
+	notification := &Notification{
+		RecipientID:   digest.RecipientID,
+		RecipientType: digest.RecipientType,
+		Type:          TypeDigest,
+		Title:         digest.Title,
+		Body:          digest.Body,
+		Data: map[string]interface{}{
+			"notificationIds": digest.NotificationIDs,
+			"count":           len(digest.NotificationIDs),
+		},
+		Status: StatusPending,
+	}
+	held := s.holdForQuietHours(notification)
+	if err := s.notificationRepo.Create(notification); err != nil {
+		return fmt.Errorf("failed to create digest notification: %w", err)
+	}
+	if err := s.notificationRepo.MarkDigested(digest.NotificationIDs); err != nil {
+		return fmt.Errorf("failed to mark notifications digested: %w", err)
+	}
+
+	if !held {
+		s.deliver(*notification)
+	}
+	s.sendDigestEmail(digest)
+	return nil
+}
+
+// sendDigestEmail is best effort: failures are only logged
+func (s *notificationService) sendDigestEmail(digest Digest) {
+	if s.digestEmailer == nil || s.emailLookup == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	to, err := s.emailLookup(ctx, digest.RecipientID)
+	if err != nil || to == "" {
+		return
+	}
+	if err := s.digestEmailer.SendDigestEmail(ctx, to, digest.Title, digest.Lines); err != nil {
+		log.Printf("Failed to email digest to %s: %v", digest.RecipientID, err)
+	}
+}
+
+func toPreferenceResponse(pref *NotificationPreference) *NotificationPreferenceResponse {
+	types := make([]NotificationType, 0, len(digestTypes))
+	bypass := make([]NotificationType, 0, len(quietHoursBypassTypes))
+	for _, t := range NotificationTypes {
+		if digestTypes[t] {
+			types = append(types, t)
+		}
+		if quietHoursBypassTypes[t] {
+			bypass = append(bypass, t)
+		}
+	}
+	return &NotificationPreferenceResponse{
+		DigestEnabled:         pref.DigestEnabled,
+		DigestTypes:           types,
+		Locale:                normalizeLocale(pref.Locale),
+		AvailableLocales:      SupportedLocales,
+		QuietHoursStart:       pref.QuietHoursStart,
+		QuietHoursEnd:         pref.QuietHoursEnd,
+		Timezone:              pref.Timezone,
+		QuietHoursBypassTypes: bypass,
+	}
+}
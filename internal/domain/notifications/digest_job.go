@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DigestJob sends the daily digest of held low-priority notifications once a day at a fixed
+// hour. Held notifications are marked as they are rolled up, so a late or repeated run is harmless.
+type DigestJob struct {
+	service   NotificationService
+	hour      int
+	batchSize int
+	logger    *log.Logger
+}
+
+func NewDigestJob(service NotificationService, hour int) *DigestJob {
+	return &DigestJob{
+		service:   service,
+		hour:      hour,
+		batchSize: 500,
+		logger:    log.New(log.Writer(), "[NOTIFICATION-DIGEST] ", log.LstdFlags),
+	}
+}
+
+// Start runs the job every day at the configured hour until ctx is cancelled
+func (j *DigestJob) Start(ctx context.Context) {
+	if j.hour < 0 || j.hour > 23 {
+		j.logger.Println("disabled (no digest hour configured)")
+		return
+	}
+
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(NextDigestRun(time.Now(), j.hour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				j.RunOnce()
+			}
+		}
+	}()
+}
+
+// RunOnce sends a digest to every recipient with held notifications
+func (j *DigestJob) RunOnce() {
+	if sent, err := j.service.SendDigests(j.batchSize); err != nil {
+		j.logger.Printf("run failed: %v", err)
+	} else if sent > 0 {
+		j.logger.Printf("sent %d digests", sent)
+	}
+}
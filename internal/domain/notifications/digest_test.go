@@ -0,0 +1,61 @@
+package notifications_test
+
+import (
+	"testing"
+	"time"
+
+	notifications "errandShop/internal/domain/notifications"
+	"github.com/google/uuid"
+)
+
+func TestBuildDigests(t *testing.T) {
+	alice, bola := uuid.New(), uuid.New()
+	pending := []notifications.Notification{
+		{ID: 1, RecipientID: alice, RecipientType: notifications.RecipientCustomer, Title: "10% off rice", Body: "This weekend only"},
+		{ID: 2, RecipientID: bola, RecipientType: notifications.RecipientCustomer, Title: "Free delivery", Body: "On orders over ₦20,000"},
+		{ID: 3, RecipientID: alice, RecipientType: notifications.RecipientCustomer, Title: "New in: plantain", Body: "Fresh stock"},
+		{ID: 4, RecipientID: alice, RecipientType: notifications.RecipientCustomer, Title: "Drinks sale", Body: "Buy 2 get 1"},
+		{ID: 5, RecipientID: alice, RecipientType: notifications.RecipientCustomer, Title: "Weekend deals", Body: "Up to 30% off"},
+	}
+
+	digests := notifications.BuildDigests(pending)
+	if len(digests) != 2 {
+		t.Fatalf("expected 2 digests, got %d", len(digests))
+	}
+
+	first := digests[0]
+	if first.RecipientID != alice || len(first.NotificationIDs) != 4 || len(first.Lines) != 4 {
+		t.Fatalf("alice: got %+v", first)
+	}
+	if first.Title != "Your daily digest: 4 updates" {
+		t.Fatalf("alice title = %q", first.Title)
+	}
+	if want := "10% off rice; New in: plantain; Drinks sale and 1 more"; first.Body != want {
+		t.Fatalf("alice body = %q, want %q", first.Body, want)
+	}
+
+	second := digests[1]
+	if second.RecipientID != bola || second.Title != "Your daily digest: 1 update" || second.Body != "Free delivery" {
+		t.Fatalf("bola: got %+v", second)
+	}
+}
+
+func TestNextDigestRun(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"later today", time.Date(2025, 6, 1, 9, 30, 0, 0, lagos), time.Date(2025, 6, 1, 18, 0, 0, 0, lagos)},
+		{"exactly on the hour", time.Date(2025, 6, 1, 18, 0, 0, 0, lagos), time.Date(2025, 6, 2, 18, 0, 0, 0, lagos)},
+		{"already passed", time.Date(2025, 6, 30, 20, 0, 0, 0, lagos), time.Date(2025, 7, 1, 18, 0, 0, 0, lagos)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notifications.NextDigestRun(tt.now, 18); !got.Equal(tt.want) {
+				t.Fatalf("NextDigestRun(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -25,9 +25,10 @@ type SendPushNotificationRequest struct {
 }
 
 type BroadcastNotificationRequest struct {
-	Title    string                 `json:"title" validate:"required,max=200"`
-	Body     string                 `json:"body" validate:"required"`
-	Data     map[string]interface{} `json:"data,omitempty"`
+	Type  NotificationType       `json:"type,omitempty" validate:"omitempty,oneof=promotion system"` // defaults to promotion, which opted-in recipients get in their digest
+	Title string                 `json:"title" validate:"required,max=200"`
+	Body  string                 `json:"body" validate:"required"`
+	Data  map[string]interface{} `json:"data,omitempty"`
 }
 
 type RegisterPushTokenRequest struct {
@@ -53,13 +54,33 @@ type UpdateNotificationStatusRequest struct {
 	Status NotificationStatus `json:"status" validate:"required,oneof=read"`
 }
 
+// CreateTemplateRequest creates or replaces a template. Key defaults to the type and Locale to
+// English; each (key, locale) pair holds one template.
 type CreateTemplateRequest struct {
 	Type     NotificationType `json:"type" validate:"required"`
+	Key      string           `json:"key" validate:"omitempty,max=60"`
+	Locale   string           `json:"locale" validate:"omitempty,oneof=en yo ha ig"`
 	Title    string           `json:"title" validate:"required,max=200"`
 	Body     string           `json:"body" validate:"required"`
 	IsActive bool             `json:"isActive"`
 }
 
+// TemplatePreviewRequest optionally overrides the sample values used to fill a template's placeholders
+type TemplatePreviewRequest struct {
+	Data map[string]string `json:"data"`
+}
+
+// UpdateNotificationPreferenceRequest turns the daily digest of low-priority notifications on or
+// off, sets the language notifications are sent in and sets quiet hours ("HH:MM" start and end;
+// both empty turns them off). Fields left out are unchanged.
+type UpdateNotificationPreferenceRequest struct {
+	DigestEnabled   *bool   `json:"digestEnabled" validate:"required_without_all=Locale QuietHoursStart QuietHoursEnd Timezone"`
+	Locale          *string `json:"locale" validate:"omitempty,oneof=en yo ha ig"`
+	QuietHoursStart *string `json:"quietHoursStart"`
+	QuietHoursEnd   *string `json:"quietHoursEnd"`
+	Timezone        *string `json:"timezone" validate:"omitempty,max=64"` // IANA name, e.g. "Africa/Lagos"; "" uses the store's
+}
+
 // Response DTOs
 type NotificationResponse struct {
 	ID            uint                   `json:"id"`
@@ -72,6 +93,10 @@ type NotificationResponse struct {
 	Status        NotificationStatus     `json:"status"`
 	ReadAt        *time.Time             `json:"readAt,omitempty"`
 	SentAt        *time.Time             `json:"sentAt,omitempty"`
+	SendStatus    SendStatus             `json:"sendStatus"`
+	SendAttempts  int                    `json:"sendAttempts"`
+	LastSendError string                 `json:"lastSendError,omitempty"`
+	NextSendAt    *time.Time             `json:"nextSendAt,omitempty"`
 	CreatedAt     time.Time              `json:"createdAt"`
 }
 
@@ -83,9 +108,30 @@ type NotificationListResponse struct {
 	UnreadCount   int64                  `json:"unreadCount"`
 }
 
+// UnreadCountsResponse drives per-category badges; every known type is present, zero when nothing is unread
+type UnreadCountsResponse struct {
+	Total  int64                      `json:"total"`
+	ByType map[NotificationType]int64 `json:"byType"`
+}
+
+// NotificationPreferenceResponse lists which types are held for the digest when it is enabled and
+// which are still pushed during quiet hours
+type NotificationPreferenceResponse struct {
+	DigestEnabled         bool               `json:"digestEnabled"`
+	DigestTypes           []NotificationType `json:"digestTypes"`
+	Locale                string             `json:"locale"`
+	AvailableLocales      []string           `json:"availableLocales"`
+	QuietHoursStart       string             `json:"quietHoursStart"` // empty when quiet hours are off
+	QuietHoursEnd         string             `json:"quietHoursEnd"`
+	Timezone              string             `json:"timezone"`              // empty means the store's timezone
+	QuietHoursBypassTypes []NotificationType `json:"quietHoursBypassTypes"` // critical types still pushed during quiet hours
+}
+
 type TemplateResponse struct {
 	ID        uint             `json:"id"`
 	Type      NotificationType `json:"type"`
+	Key       string           `json:"key"`
+	Locale    string           `json:"locale"`
 	Title     string           `json:"title"`
 	Body      string           `json:"body"`
 	IsActive  bool             `json:"isActive"`
@@ -93,6 +139,15 @@ type TemplateResponse struct {
 	UpdatedAt time.Time        `json:"updatedAt"`
 }
 
+// TemplatePreviewResponse is a template rendered with the placeholder data shown in Data
+type TemplatePreviewResponse struct {
+	TemplateID uint              `json:"templateId"`
+	Type       NotificationType  `json:"type"`
+	Title      string            `json:"title"`
+	Body       string            `json:"body"`
+	Data       map[string]string `json:"data"`
+}
+
 type PushTokenResponse struct {
 	ID         uint           `json:"id"`
 	Token      string         `json:"token"`
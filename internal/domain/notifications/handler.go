@@ -3,10 +3,12 @@ package notifications
 import (
 	"errandShop/internal/presenter"
 	"errandShop/internal/validation"
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type NotificationHandler struct {
@@ -17,11 +19,12 @@ func NewNotificationHandler(service NotificationService) *NotificationHandler {
 	return &NotificationHandler{service: service}
 }
 
-// GET /api/v1/notifications
-func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
+// recipientFromLocals resolves the authenticated user and the recipient type their role reads
+// notifications as. Failures are *fiber.Error carrying the status to respond with.
+func recipientFromLocals(c *fiber.Ctx) (uuid.UUID, NotificationRecipient, error) {
 	userID := c.Locals("userID")
 	if userID == nil {
-		return presenter.ErrorResponse(c, 401, "User not authenticated")
+		return uuid.Nil, "", fiber.NewError(401, "User not authenticated")
 	}
 
 	// Handle different types from JWT claims
@@ -32,21 +35,21 @@ func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
 	case string:
 		parsedUUID, err := uuid.Parse(v)
 		if err != nil {
-			return presenter.ErrorResponse(c, 400, "Invalid user ID format")
+			return uuid.Nil, "", fiber.NewError(400, "Invalid user ID format")
 		}
 		userIDUUID = parsedUUID
 	default:
-		return presenter.ErrorResponse(c, 500, "Invalid user ID type")
+		return uuid.Nil, "", fiber.NewError(500, "Invalid user ID type")
 	}
 
 	role := c.Locals("role")
 	if role == nil {
-		return presenter.ErrorResponse(c, 401, "User role not found")
+		return uuid.Nil, "", fiber.NewError(401, "User role not found")
 	}
 
 	roleStr, ok := role.(string)
 	if !ok {
-		return presenter.ErrorResponse(c, 500, "Invalid role type")
+		return uuid.Nil, "", fiber.NewError(500, "Invalid role type")
 	}
 
 	var recipientType NotificationRecipient
@@ -59,7 +62,30 @@ func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
 		recipientType = RecipientCustomer
 	}
 
-	notifications, err := h.service.GetNotifications(userIDUUID, recipientType, 0, 10)
+	return userIDUUID, recipientType, nil
+}
+
+// recipientError writes the response for a recipientFromLocals failure
+func recipientError(c *fiber.Ctx, err error) error {
+	if fe, ok := err.(*fiber.Error); ok {
+		return presenter.ErrorResponse(c, fe.Code, fe.Message)
+	}
+	return presenter.ErrorResponse(c, 500, err.Error())
+}
+
+// GET /api/v1/notifications?type=promotion
+func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
+	userIDUUID, recipientType, err := recipientFromLocals(c)
+	if err != nil {
+		return recipientError(c, err)
+	}
+
+	notificationType := NotificationType(c.Query("type"))
+	if notificationType != "" && !notificationType.IsValid() {
+		return presenter.ErrorResponse(c, 400, "Invalid notification type")
+	}
+
+	notifications, err := h.service.GetNotifications(userIDUUID, recipientType, notificationType, 0, 10)
 	if err != nil {
 		return presenter.ErrorResponse(c, 500, err.Error())
 	}
@@ -71,6 +97,25 @@ func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
 	})
 }
 
+// GET /api/v1/notifications/unread-counts
+func (h *NotificationHandler) GetUnreadCounts(c *fiber.Ctx) error {
+	userID, recipientType, err := recipientFromLocals(c)
+	if err != nil {
+		return recipientError(c, err)
+	}
+
+	counts, err := h.service.GetUnreadCounts(userID, recipientType)
+	if err != nil {
+		return presenter.ErrorResponse(c, 500, err.Error())
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Unread counts retrieved successfully",
+		"data":    counts,
+	})
+}
+
 // PUT /api/v1/notifications/:id/read
 func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 	notificationID, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -86,55 +131,61 @@ func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 
 // PUT /api/v1/notifications/read-all
 func (h *NotificationHandler) MarkAllAsRead(c *fiber.Ctx) error {
-	userIDRaw := c.Locals("userID")
-	if userIDRaw == nil {
-		return presenter.ErrorResponse(c, 401, "User not authenticated")
+	userID, recipientType, err := recipientFromLocals(c)
+	if err != nil {
+		return recipientError(c, err)
 	}
 
-	// Handle different types from JWT claims
-	var userID uuid.UUID
-	switch v := userIDRaw.(type) {
-	case uuid.UUID:
-		userID = v
-	case string:
-		parsedUUID, err := uuid.Parse(v)
-		if err != nil {
-			return presenter.ErrorResponse(c, 400, "Invalid user ID format")
-		}
-		userID = parsedUUID
-	default:
-		return presenter.ErrorResponse(c, 500, "Invalid user ID type")
+	if err := h.service.MarkAllAsRead(userID, recipientType); err != nil {
+		return presenter.ErrorResponse(c, 500, err.Error())
 	}
 
-	role := c.Locals("role")
-	if role == nil {
-		return presenter.ErrorResponse(c, 401, "User role not found")
+	return c.Status(200).JSON(fiber.Map{
+		"status":  "success",
+		"message": "All notifications marked as read",
+		"data":    nil,
+	})
+}
+
+// GET /api/v1/notifications/preferences
+func (h *NotificationHandler) GetPreferences(c *fiber.Ctx) error {
+	userID, recipientType, err := recipientFromLocals(c)
+	if err != nil {
+		return recipientError(c, err)
 	}
 
-	roleStr, ok := role.(string)
-	if !ok {
-		return presenter.ErrorResponse(c, 500, "Invalid role type")
+	prefs, err := h.service.GetPreferences(userID, recipientType)
+	if err != nil {
+		return presenter.ErrorResponse(c, 500, err.Error())
 	}
 
-	var recipientType NotificationRecipient
-	switch roleStr {
-	case "admin", "superadmin":
-		recipientType = RecipientAdmin
-	case "customer":
-		recipientType = RecipientCustomer
-	default:
-		recipientType = RecipientCustomer
+	return presenter.SuccessResponse(c, "Notification preferences retrieved successfully", prefs)
+}
+
+// PUT /api/v1/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	userID, recipientType, err := recipientFromLocals(c)
+	if err != nil {
+		return recipientError(c, err)
 	}
 
-	if err := h.service.MarkAllAsRead(userID, recipientType); err != nil {
+	var req UpdateNotificationPreferenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.ErrorResponse(c, 400, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.ErrorResponse(c, 400, err.Error())
+	}
+
+	prefs, err := h.service.UpdatePreferences(userID, recipientType, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidQuietHours) {
+			return presenter.ErrorResponse(c, 400, err.Error())
+		}
 		return presenter.ErrorResponse(c, 500, err.Error())
 	}
 
-	return c.Status(200).JSON(fiber.Map{
-		"status":  "success",
-		"message": "All notifications marked as read",
-		"data":    nil,
-	})
+	return presenter.SuccessResponse(c, "Notification preferences updated successfully", prefs)
 }
 
 // POST /api/v1/notifications/push-token
@@ -300,6 +351,101 @@ func (h *NotificationHandler) DeleteNotificationTemplate(c *fiber.Ctx) error {
 	})
 }
 
+// POST /api/v1/admin/notifications/templates/:id/preview
+func (h *NotificationHandler) PreviewNotificationTemplate(c *fiber.Ctx) error {
+	id, req, err := templatePreviewParams(c)
+	if err != nil {
+		return recipientError(c, err)
+	}
+
+	preview, err := h.service.PreviewTemplate(id, req.Data)
+	if err != nil {
+		return templatePreviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   preview,
+	})
+}
+
+// POST /api/v1/admin/notifications/templates/:id/test-send
+func (h *NotificationHandler) TestSendNotificationTemplate(c *fiber.Ctx) error {
+	adminID, _, err := recipientFromLocals(c)
+	if err != nil {
+		return recipientError(c, err)
+	}
+	id, req, err := templatePreviewParams(c)
+	if err != nil {
+		return recipientError(c, err)
+	}
+
+	preview, err := h.service.TestSendTemplate(id, adminID, req.Data)
+	if err != nil {
+		return templatePreviewError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Test notification sent to your devices",
+		"data":    preview,
+	})
+}
+
+// templatePreviewParams reads the template ID and the optional sample data overrides
+func templatePreviewParams(c *fiber.Ctx) (uint, TemplatePreviewRequest, error) {
+	var req TemplatePreviewRequest
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, req, fiber.NewError(400, "Invalid template ID")
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return 0, req, fiber.NewError(400, "Invalid request body")
+		}
+	}
+	return uint(id), req, nil
+}
+
+func templatePreviewError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return presenter.ErrorResponse(c, 404, "Template not found")
+	case errors.Is(err, ErrTemplateRender), errors.Is(err, ErrNoTestDevices):
+		return presenter.ErrorResponse(c, 400, err.Error())
+	}
+	return presenter.ErrorResponse(c, 500, err.Error())
+}
+
+// GET /api/v1/admin/notifications/failed?status=failed|retrying|dead_letter&page=1&limit=20
+func (h *NotificationHandler) GetFailedNotifications(c *fiber.Ctx) error {
+	status := SendStatus(c.Query("status"))
+	switch status {
+	case "", SendFailed, SendRetrying, SendDeadLetter:
+	default:
+		return presenter.ErrorResponse(c, 400, "Invalid status (use failed, retrying or dead_letter)")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	notifications, err := h.service.GetFailedSends(status, page, limit)
+	if err != nil {
+		return presenter.ErrorResponse(c, 500, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   notifications,
+	})
+}
+
 func (h *NotificationHandler) GetNotificationStats(c *fiber.Ctx) error {
 	// Implement notification statistics logic
 	return c.JSON(fiber.Map{
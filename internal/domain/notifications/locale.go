@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Languages notifications can be rendered in
+const (
+	LocaleEnglish = "en"
+	LocaleYoruba  = "yo"
+	LocaleHausa   = "ha"
+	LocaleIgbo    = "ig"
+)
+
+// SupportedLocales lists the languages a recipient can choose, English (the fallback) first
+var SupportedLocales = []string{LocaleEnglish, LocaleYoruba, LocaleHausa, LocaleIgbo}
+
+// ErrTemplateNotFound is returned when there is no active template for a key in the recipient's
+// language or in English. Callers fall back to their built-in English text.
+var ErrTemplateNotFound = errors.New("notification template not found")
+
+// normalizeLocale returns locale lowercased if it is supported, otherwise English
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	for _, supported := range SupportedLocales {
+		if locale == supported {
+			return locale
+		}
+	}
+	return LocaleEnglish
+}
+
+// recipientLocale returns the language the recipient chose, or English if none was saved or the
+// preference cannot be read
+func (s *notificationService) recipientLocale(recipientID uuid.UUID, recipientType NotificationRecipient) string {
+	pref, err := s.notificationRepo.GetPreference(recipientID, recipientType)
+	if err != nil {
+		log.Printf("Failed to load notification locale for %s: %v", recipientID, err)
+		return LocaleEnglish
+	}
+	return normalizeLocale(pref.Locale)
+}
+
+// localizedTemplate returns the active template for key in locale, falling back to English
+func (s *notificationService) localizedTemplate(key, locale string) (*NotificationTemplate, error) {
+	locales := []string{locale}
+	if locale != LocaleEnglish {
+		locales = append(locales, LocaleEnglish)
+	}
+	for _, l := range locales {
+		tmpl, err := s.templateRepo.GetByKey(key, l)
+		if err == nil {
+			return tmpl, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get template %s (%s): %w", key, l, err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, key)
+}
+
+// RenderLocalized renders the template for key in the recipient's language, or in English when
+// there is no variant for it, filling placeholders from data
+func (s *notificationService) RenderLocalized(recipientID uuid.UUID, recipientType NotificationRecipient, key string, data map[string]string) (string, string, error) {
+	tmpl, err := s.localizedTemplate(key, s.recipientLocale(recipientID, recipientType))
+	if err != nil {
+		return "", "", err
+	}
+	return RenderTemplate(tmpl.Title, tmpl.Body, data)
+}
@@ -0,0 +1,68 @@
+package notifications_test
+
+import (
+	"errors"
+	"testing"
+
+	notifications "errandShop/internal/domain/notifications"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type localeNotificationRepo struct {
+	notifications.NotificationRepository
+	locale string
+}
+
+func (r *localeNotificationRepo) GetPreference(recipientID uuid.UUID, recipientType notifications.NotificationRecipient) (*notifications.NotificationPreference, error) {
+	return &notifications.NotificationPreference{RecipientID: recipientID, RecipientType: recipientType, Locale: r.locale}, nil
+}
+
+type localeTemplateRepo struct {
+	notifications.TemplateRepository
+	templates []notifications.NotificationTemplate
+}
+
+func (r *localeTemplateRepo) GetByKey(key, locale string) (*notifications.NotificationTemplate, error) {
+	for i := range r.templates {
+		if r.templates[i].Key == key && r.templates[i].Locale == locale {
+			return &r.templates[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestRenderLocalized(t *testing.T) {
+	templates := &localeTemplateRepo{templates: []notifications.NotificationTemplate{
+		{Key: "order_delivered", Locale: notifications.LocaleEnglish, Title: "Order Delivered", Body: "Order {{.orderId}} has been delivered."},
+		{Key: "order_delivered", Locale: notifications.LocaleYoruba, Title: "A ti fi ọja ranṣẹ", Body: "A ti fi aṣẹ {{.orderId}} ranṣẹ."},
+	}}
+	data := map[string]string{"orderId": "ORD-000001"}
+
+	tests := []struct {
+		name      string
+		locale    string
+		wantTitle string
+	}{
+		{"localized variant", notifications.LocaleYoruba, "A ti fi ọja ranṣẹ"},
+		{"falls back to English", notifications.LocaleHausa, "Order Delivered"},
+		{"no preference saved", "", "Order Delivered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := notifications.NewNotificationService(&localeNotificationRepo{locale: tt.locale}, templates, nil)
+			title, _, err := svc.RenderLocalized(uuid.New(), notifications.RecipientCustomer, "order_delivered", data)
+			if err != nil {
+				t.Fatalf("RenderLocalized returned error: %v", err)
+			}
+			if title != tt.wantTitle {
+				t.Fatalf("title = %q, want %q", title, tt.wantTitle)
+			}
+		})
+	}
+
+	svc := notifications.NewNotificationService(&localeNotificationRepo{locale: notifications.LocaleIgbo}, templates, nil)
+	if _, _, err := svc.RenderLocalized(uuid.New(), notifications.RecipientCustomer, "order_cancelled", data); !errors.Is(err, notifications.ErrTemplateNotFound) {
+		t.Fatalf("err = %v, want ErrTemplateNotFound", err)
+	}
+}
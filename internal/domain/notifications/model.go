@@ -12,6 +12,7 @@ import (
 type NotificationRecipient string
 type NotificationType string
 type NotificationStatus string
+type SendStatus string
 type DevicePlatform string
 
 const (
@@ -26,19 +27,51 @@ const (
 	TypePaymentUpdate  NotificationType = "payment_update"
 	TypePromotion      NotificationType = "promotion"
 	TypeSystem         NotificationType = "system"
+	TypeDigest         NotificationType = "digest" // daily roll-up of low-priority notifications
 
-	// Status
+	// Status (pending means delivered in-app but not yet read)
 	StatusPending NotificationStatus = "pending"
 	StatusSent    NotificationStatus = "sent"
 	StatusRead    NotificationStatus = "read"
 	StatusFailed  NotificationStatus = "failed"
 
+	// Push delivery status (separate from read status above)
+	SendPending       SendStatus = "pending"
+	SendSent          SendStatus = "sent"
+	SendSkipped       SendStatus = "skipped"        // recipient has no active push tokens
+	SendFailed        SendStatus = "failed"         // last attempt failed, retry scheduled at NextSendAt
+	SendRetrying      SendStatus = "retrying"       // claimed by the retry worker
+	SendDeadLetter    SendStatus = "dead_letter"    // gave up after the maximum number of attempts
+	SendDigestPending SendStatus = "digest_pending" // held for the recipient's next daily digest
+	SendDigested      SendStatus = "digested"       // rolled up into a digest instead of pushed on its own
+	SendQuietHours    SendStatus = "quiet_hours"    // held until the recipient's quiet hours end at NextSendAt
+
 	// Platforms
 	PlatformIOS     DevicePlatform = "ios"
 	PlatformAndroid DevicePlatform = "android"
 	PlatformWeb     DevicePlatform = "web"
 )
 
+// NotificationTypes lists every in-app notification category, in the order badges are shown
+var NotificationTypes = []NotificationType{
+	TypeOrderUpdate,
+	TypeDeliveryUpdate,
+	TypePaymentUpdate,
+	TypePromotion,
+	TypeSystem,
+	TypeDigest,
+}
+
+// IsValid reports whether t is a known notification category
+func (t NotificationType) IsValid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 type Notification struct {
 	ID            uint                  `gorm:"primaryKey" json:"id"`
 	RecipientID   uuid.UUID             `gorm:"type:uuid;not null" json:"recipientId"`
@@ -50,14 +83,37 @@ type Notification struct {
 	Status        NotificationStatus    `gorm:"type:varchar(20);default:'pending'" json:"status"`
 	ReadAt        *time.Time            `json:"readAt,omitempty"`
 	SentAt        *time.Time            `json:"sentAt,omitempty"`
+	SendStatus    SendStatus            `gorm:"type:varchar(20);default:'pending';index" json:"sendStatus"`
+	SendAttempts  int                   `gorm:"default:0" json:"sendAttempts"`
+	LastSendError string                `gorm:"type:text" json:"lastSendError,omitempty"`
+	NextSendAt    *time.Time            `json:"nextSendAt,omitempty"`
 	CreatedAt     time.Time             `json:"createdAt"`
 	UpdatedAt     time.Time             `json:"updatedAt"`
 	DeletedAt     gorm.DeletedAt        `gorm:"index" json:"-"`
 }
 
+// NotificationPreference holds a recipient's delivery choices. Recipients without a row get every
+// notification pushed straight away.
+type NotificationPreference struct {
+	RecipientID     uuid.UUID             `gorm:"type:uuid;primaryKey" json:"recipientId"`
+	RecipientType   NotificationRecipient `gorm:"type:varchar(20);primaryKey" json:"recipientType"`
+	DigestEnabled   bool                  `gorm:"default:false" json:"digestEnabled"`                   // batch low-priority notifications into a daily digest
+	Locale          string                `gorm:"type:varchar(10);not null;default:'en'" json:"locale"` // language notifications are rendered in
+	QuietHoursStart string                `gorm:"size:5;not null;default:''" json:"quietHoursStart"`    // "HH:MM"; non-critical pushes wait until QuietHoursEnd
+	QuietHoursEnd   string                `gorm:"size:5;not null;default:''" json:"quietHoursEnd"`
+	Timezone        string                `gorm:"size:64;not null;default:''" json:"timezone"` // IANA zone quiet hours are read in; empty uses the store's
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+}
+
+// NotificationTemplate is the title and body for one message in one language. Templates are
+// looked up by (Key, Locale); Key defaults to the notification type, and messages that need
+// their own wording per event use their own key, e.g. "order_delivered".
 type NotificationTemplate struct {
 	ID        uint             `gorm:"primaryKey" json:"id"`
-	Type      NotificationType `gorm:"type:varchar(30);uniqueIndex;not null" json:"type"`
+	Type      NotificationType `gorm:"type:varchar(30);index;not null" json:"type"`
+	Key       string           `gorm:"column:template_key;size:60;not null;uniqueIndex:idx_notification_templates_key_locale,priority:1" json:"key"`
+	Locale    string           `gorm:"type:varchar(10);not null;default:'en';uniqueIndex:idx_notification_templates_key_locale,priority:2" json:"locale"`
 	Title     string           `gorm:"size:200;not null" json:"title"`
 	Body      string           `gorm:"type:text;not null" json:"body"`
 	IsActive  bool             `gorm:"default:true" json:"isActive"`
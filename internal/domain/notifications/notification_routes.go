@@ -15,8 +15,11 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, handler *NotificationHandle
 	// Protected routes (require authentication)
 	protected := notifications.Group("", middleware.JWTMiddleware(cfg))
 	protected.Get("/", handler.GetNotifications)
+	protected.Get("/unread-counts", handler.GetUnreadCounts)
 	protected.Put("/:id/read", handler.MarkAsRead)
 	protected.Put("/read-all", handler.MarkAllAsRead)
+	protected.Get("/preferences", handler.GetPreferences)
+	protected.Put("/preferences", handler.UpdatePreferences)
 	protected.Post("/push-token", handler.RegisterPushToken)
 }
 
@@ -32,5 +35,8 @@ func SetupAdminRoutes(app *fiber.App, cfg *config.Config, handler *NotificationH
 	admin.Post("/templates", handler.CreateNotificationTemplate)
 	admin.Put("/templates/:id", handler.UpdateNotificationTemplate)
 	admin.Delete("/templates/:id", handler.DeleteNotificationTemplate)
+	admin.Post("/templates/:id/preview", handler.PreviewNotificationTemplate)
+	admin.Post("/templates/:id/test-send", handler.TestSendNotificationTemplate)
 	admin.Get("/stats", handler.GetNotificationStats)
+	admin.Get("/failed", handler.GetFailedNotifications)
 }
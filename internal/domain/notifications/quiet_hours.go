@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidQuietHours is returned for quiet hours that are not a pair of "HH:MM" times or name an
+// unknown timezone
+var ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+// quietHoursBypassTypes are the critical types pushed even during a recipient's quiet hours, so a
+// customer still hears that their order is on its way or their payment failed
+var quietHoursBypassTypes = map[NotificationType]bool{
+	TypeOrderUpdate:    true,
+	TypeDeliveryUpdate: true,
+	TypePaymentUpdate:  true,
+}
+
+// SetDefaultTimezone sets the timezone quiet hours are read in for recipients who have not chosen
+// one, normally the store's
+func (s *notificationService) SetDefaultTimezone(loc *time.Location) {
+	if loc != nil {
+		s.defaultLocation = loc
+	}
+}
+
+// QuietUntil reports whether now falls in the recipient's quiet hours and, if so, when they end.
+// Start and end are "HH:MM" in the preference's timezone (fallback when it has none); a window
+// whose end is before its start runs overnight, e.g. 22:00-07:00. Empty or equal times turn quiet
+// hours off.
+func (p *NotificationPreference) QuietUntil(now time.Time, fallback *time.Location) (time.Time, bool) {
+	start, okStart := parseClock(p.QuietHoursStart)
+	end, okEnd := parseClock(p.QuietHoursEnd)
+	if !okStart || !okEnd || start == end {
+		return time.Time{}, false
+	}
+	loc := fallback
+	if p.Timezone != "" {
+		if named, err := time.LoadLocation(p.Timezone); err == nil {
+			loc = named
+		}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	endAt := func(dayOffset int) time.Time {
+		return midnight.AddDate(0, 0, dayOffset).Add(time.Duration(end) * time.Minute)
+	}
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endAt(0), true
+	case start > end && minute >= start:
+		return endAt(1), true
+	case start > end && minute < end:
+		return endAt(0), true
+	}
+	return time.Time{}, false
+}
+
+// quietHoursRelease returns when a new notification may be pushed if it arrives during the
+// recipient's quiet hours, or nil to push it now. Critical types are never held, and if the
+// preference cannot be read the notification is sent straight away.
+func (s *notificationService) quietHoursRelease(recipientID uuid.UUID, recipientType NotificationRecipient, notificationType NotificationType, now time.Time) *time.Time {
+	if quietHoursBypassTypes[notificationType] {
+		return nil
+	}
+	pref, err := s.notificationRepo.GetPreference(recipientID, recipientType)
+	if err != nil {
+		log.Printf("Failed to load notification preferences for %s: %v", recipientID, err)
+		return nil
+	}
+	until, quiet := pref.QuietUntil(now, s.defaultLocation)
+	if !quiet {
+		return nil
+	}
+	return &until
+}
+
+// holdForQuietHours queues a notification that arrives during quiet hours for the retry worker to
+// push once they end, returning whether it was held
+func (s *notificationService) holdForQuietHours(notification *Notification) bool {
+	until := s.quietHoursRelease(notification.RecipientID, notification.RecipientType, notification.Type, time.Now())
+	if until == nil {
+		return false
+	}
+	notification.SendStatus = SendQuietHours
+	notification.NextSendAt = until
+	return true
+}
+
+// applyQuietHours validates and sets quiet hours from a preferences update. Sending both times
+// empty turns quiet hours off.
+func applyQuietHours(pref *NotificationPreference, req *UpdateNotificationPreferenceRequest) error {
+	start, end := pref.QuietHoursStart, pref.QuietHoursEnd
+	if req.QuietHoursStart != nil {
+		start = strings.TrimSpace(*req.QuietHoursStart)
+	}
+	if req.QuietHoursEnd != nil {
+		end = strings.TrimSpace(*req.QuietHoursEnd)
+	}
+	if (start == "") != (end == "") {
+		return fmt.Errorf("%w: set both a start and an end, or neither", ErrInvalidQuietHours)
+	}
+	for _, clock := range []string{start, end} {
+		if _, ok := parseClock(clock); clock != "" && !ok {
+			return fmt.Errorf("%w: %q is not an HH:MM time", ErrInvalidQuietHours, clock)
+		}
+	}
+	if start != "" && start == end {
+		return fmt.Errorf("%w: start and end must differ", ErrInvalidQuietHours)
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if timezone != "" {
+			if _, err := time.LoadLocation(timezone); err != nil {
+				return fmt.Errorf("%w: unknown timezone %q", ErrInvalidQuietHours, timezone)
+			}
+		}
+		pref.Timezone = timezone
+	}
+	pref.QuietHoursStart, pref.QuietHoursEnd = start, end
+	return nil
+}
+
+// parseClock returns the minutes since midnight of an "HH:MM" time
+func parseClock(clock string) (int, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
@@ -0,0 +1,52 @@
+package notifications_test
+
+import (
+	"testing"
+	"time"
+
+	notifications "errandShop/internal/domain/notifications"
+)
+
+func TestQuietUntil(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, lagos)
+	}
+	overnight := &notifications.NotificationPreference{QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+	afternoon := &notifications.NotificationPreference{QuietHoursStart: "13:00", QuietHoursEnd: "15:30"}
+
+	tests := []struct {
+		name      string
+		pref      *notifications.NotificationPreference
+		now       time.Time
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		{"3am is inside an overnight window", overnight, at(16, 3, 0), true, at(16, 7, 0)},
+		{"late evening runs to tomorrow morning", overnight, at(16, 23, 15), true, at(17, 7, 0)},
+		{"the window starts on the minute", overnight, at(16, 22, 0), true, at(17, 7, 0)},
+		{"the window ends on the minute", overnight, at(16, 7, 0), false, time.Time{}},
+		{"daytime is outside an overnight window", overnight, at(16, 12, 0), false, time.Time{}},
+		{"inside a same-day window", afternoon, at(16, 14, 0), true, at(16, 15, 30)},
+		{"after a same-day window", afternoon, at(16, 16, 0), false, time.Time{}},
+		{"no quiet hours set", &notifications.NotificationPreference{}, at(16, 3, 0), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := tt.pref.QuietUntil(tt.now, lagos)
+			if quiet != tt.wantQuiet || !until.Equal(tt.wantUntil) {
+				t.Fatalf("QuietUntil(%v) = (%v, %v), want (%v, %v)", tt.now, until, quiet, tt.wantUntil, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestQuietUntilUsesRecipientTimezone(t *testing.T) {
+	// 22:00-07:00 in London; 03:30 UTC is 03:30 in London in winter, 04:30 in Lagos
+	pref := &notifications.NotificationPreference{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Europe/London"}
+	now := time.Date(2026, 12, 1, 3, 30, 0, 0, time.UTC)
+	until, quiet := pref.QuietUntil(now, time.FixedZone("WAT", 3600))
+	if !quiet || !until.Equal(time.Date(2026, 12, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("QuietUntil = (%v, %v), want quiet until 07:00 London time", until, quiet)
+	}
+}
@@ -1,6 +1,9 @@
 package notifications
 
 import (
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -8,19 +11,32 @@ import (
 type NotificationRepository interface {
 	Create(notification *Notification) error
 	GetByID(id uint) (*Notification, error)
-	GetByRecipient(recipientID uuid.UUID, recipientType NotificationRecipient, page, limit int) ([]Notification, int64, error)
+	GetByRecipient(recipientID uuid.UUID, recipientType NotificationRecipient, notificationType NotificationType, page, limit int) ([]Notification, int64, error)
 	GetUnreadCount(recipientID uuid.UUID, recipientType NotificationRecipient) (int64, error)
+	GetUnreadCountByType(recipientID uuid.UUID, recipientType NotificationRecipient) (map[NotificationType]int64, error)
 	MarkAsRead(id uint) error
 	MarkAllAsRead(recipientID uuid.UUID, recipientType NotificationRecipient) error
 	Delete(id uint) error
 	GetPendingNotifications(limit int) ([]Notification, error)
 	UpdateStatus(id uint, status NotificationStatus) error
+
+	// Push delivery tracking
+	RecordSendResult(id uint, status SendStatus, attempts int, lastError string, nextSendAt *time.Time) error
+	ClaimForRetry(id uint) (bool, error)
+	GetDueForRetry(now time.Time, staleClaim time.Duration, limit int) ([]Notification, error)
+	GetBySendStatus(statuses []SendStatus, page, limit int) ([]Notification, int64, error)
+
+	// Digest preferences and held notifications
+	GetPreference(recipientID uuid.UUID, recipientType NotificationRecipient) (*NotificationPreference, error)
+	SavePreference(pref *NotificationPreference) error
+	GetDigestPending(limit int) ([]Notification, error)
+	MarkDigested(ids []uint) error
 }
 
 type TemplateRepository interface {
 	Create(template *NotificationTemplate) error
 	GetByID(id uint) (*NotificationTemplate, error)
-	GetByType(notificationType NotificationType) (*NotificationTemplate, error)
+	GetByKey(key, locale string) (*NotificationTemplate, error)
 	GetAll() ([]NotificationTemplate, error)
 	Update(id uint, template *NotificationTemplate) error
 	Delete(id uint) error
@@ -30,9 +46,11 @@ type PushTokenRepository interface {
 	Create(token *PushToken) error
 	GetByUserID(userID uuid.UUID, userType string) ([]PushToken, error)
 	GetActiveTokens(userID uuid.UUID, userType string) ([]PushToken, error)
+	GetActiveRecipients() ([]PushRecipient, error)
 	UpdateLastUsed(id uint) error
 	Deactivate(id uint) error
 	DeleteByToken(token string) error
+	DeleteByUserID(userID uuid.UUID) error
 }
 
 // FCM-specific repositories
@@ -123,11 +141,15 @@ func (r *notificationRepository) GetByID(id uint) (*Notification, error) {
 	return &notification, err
 }
 
-func (r *notificationRepository) GetByRecipient(recipientID uuid.UUID, recipientType NotificationRecipient, page, limit int) ([]Notification, int64, error) {
+// GetByRecipient lists a recipient's notifications, newest first. An empty notificationType returns every category.
+func (r *notificationRepository) GetByRecipient(recipientID uuid.UUID, recipientType NotificationRecipient, notificationType NotificationType, page, limit int) ([]Notification, int64, error) {
 	var notifications []Notification
 	var total int64
 
 	query := r.db.Model(&Notification{}).Where("recipient_id = ? AND recipient_type = ?", recipientID, recipientType)
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
 	query.Count(&total)
 
 	offset := (page - 1) * limit
@@ -142,6 +164,28 @@ func (r *notificationRepository) GetUnreadCount(recipientID uuid.UUID, recipient
 	return count, err
 }
 
+// GetUnreadCountByType returns unread counts keyed by notification type; types with nothing unread are absent
+func (r *notificationRepository) GetUnreadCountByType(recipientID uuid.UUID, recipientType NotificationRecipient) (map[NotificationType]int64, error) {
+	var rows []struct {
+		Type  NotificationType
+		Count int64
+	}
+	err := r.db.Model(&Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("recipient_id = ? AND recipient_type = ? AND status = ?", recipientID, recipientType, StatusPending).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[NotificationType]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *notificationRepository) MarkAsRead(id uint) error {
 	return r.db.Model(&Notification{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"status":  StatusRead,
@@ -174,6 +218,86 @@ func (r *notificationRepository) UpdateStatus(id uint, status NotificationStatus
 	return r.db.Model(&Notification{}).Where("id = ?", id).Updates(updates).Error
 }
 
+func (r *notificationRepository) RecordSendResult(id uint, status SendStatus, attempts int, lastError string, nextSendAt *time.Time) error {
+	updates := map[string]interface{}{
+		"send_status":     status,
+		"send_attempts":   attempts,
+		"last_send_error": lastError,
+		"next_send_at":    nextSendAt,
+	}
+	if status == SendSent {
+		updates["sent_at"] = time.Now()
+	}
+	return r.db.Model(&Notification{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ClaimForRetry marks a failed (or stale retrying) notification as retrying; it reports false
+// if another worker got to it first.
+func (r *notificationRepository) ClaimForRetry(id uint) (bool, error) {
+	res := r.db.Model(&Notification{}).
+		Where("id = ? AND send_status IN ?", id, []SendStatus{SendFailed, SendRetrying, SendQuietHours}).
+		Updates(map[string]interface{}{"send_status": SendRetrying})
+	return res.RowsAffected > 0, res.Error
+}
+
+// GetDueForRetry returns failed sends whose backoff has elapsed and sends held for quiet hours
+// that have ended, plus retries that were claimed more than staleClaim ago (e.g. the worker died
+// mid-send).
+func (r *notificationRepository) GetDueForRetry(now time.Time, staleClaim time.Duration, limit int) ([]Notification, error) {
+	var notifications []Notification
+	err := r.db.
+		Where("(send_status IN ? AND (next_send_at IS NULL OR next_send_at <= ?)) OR (send_status = ? AND updated_at < ?)",
+			[]SendStatus{SendFailed, SendQuietHours}, now, SendRetrying, now.Add(-staleClaim)).
+		Order("next_send_at ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) GetBySendStatus(statuses []SendStatus, page, limit int) ([]Notification, int64, error) {
+	var notifications []Notification
+	var total int64
+
+	query := r.db.Model(&Notification{}).Where("send_status IN ?", statuses)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("updated_at DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// GetPreference returns the recipient's preferences, or defaults when none were saved
+func (r *notificationRepository) GetPreference(recipientID uuid.UUID, recipientType NotificationRecipient) (*NotificationPreference, error) {
+	pref := NotificationPreference{RecipientID: recipientID, RecipientType: recipientType, Locale: LocaleEnglish}
+	err := r.db.Where("recipient_id = ? AND recipient_type = ?", recipientID, recipientType).First(&pref).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *notificationRepository) SavePreference(pref *NotificationPreference) error {
+	return r.db.Save(pref).Error
+}
+
+// GetDigestPending returns held notifications grouped by recipient, oldest first
+func (r *notificationRepository) GetDigestPending(limit int) ([]Notification, error) {
+	var notifications []Notification
+	err := r.db.Where("send_status = ?", SendDigestPending).
+		Order("recipient_id ASC, recipient_type ASC, created_at ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) MarkDigested(ids []uint) error {
+	return r.db.Model(&Notification{}).
+		Where("id IN ? AND send_status = ?", ids, SendDigestPending).
+		Update("send_status", SendDigested).Error
+}
+
 // Template Repository Implementation
 func (r *templateRepository) Create(template *NotificationTemplate) error {
 	return r.db.Create(template).Error
@@ -185,9 +309,9 @@ func (r *templateRepository) GetByID(id uint) (*NotificationTemplate, error) {
 	return &template, err
 }
 
-func (r *templateRepository) GetByType(notificationType NotificationType) (*NotificationTemplate, error) {
+func (r *templateRepository) GetByKey(key, locale string) (*NotificationTemplate, error) {
 	var template NotificationTemplate
-	err := r.db.Where("type = ? AND is_active = ?", notificationType, true).First(&template).Error
+	err := r.db.Where("template_key = ? AND locale = ? AND is_active = ?", key, locale, true).First(&template).Error
 	return &template, err
 }
 
@@ -222,6 +346,19 @@ func (r *pushTokenRepository) GetActiveTokens(userID uuid.UUID, userType string)
 	return tokens, err
 }
 
+// PushRecipient is a user with at least one active push token
+type PushRecipient struct {
+	UserID   uuid.UUID
+	UserType string
+}
+
+// GetActiveRecipients returns each user with an active push token once
+func (r *pushTokenRepository) GetActiveRecipients() ([]PushRecipient, error) {
+	var recipients []PushRecipient
+	err := r.db.Model(&PushToken{}).Distinct("user_id", "user_type").Where("is_active = ?", true).Scan(&recipients).Error
+	return recipients, err
+}
+
 func (r *pushTokenRepository) UpdateLastUsed(id uint) error {
 	return r.db.Model(&PushToken{}).Where("id = ?", id).Update("last_used_at", "NOW()").Error
 }
@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RetryWorker periodically re-sends push notifications that failed, with the backoff and
+// dead-letter limit configured on the NotificationService.
+type RetryWorker struct {
+	service   NotificationService
+	interval  time.Duration
+	batchSize int
+	logger    *log.Logger
+}
+
+func NewRetryWorker(service NotificationService, interval time.Duration) *RetryWorker {
+	return &RetryWorker{
+		service:   service,
+		interval:  interval,
+		batchSize: 100,
+		logger:    log.New(log.Writer(), "[NOTIFICATION-RETRY] ", log.LstdFlags),
+	}
+}
+
+// Start runs the worker on a fixed interval until ctx is cancelled
+func (w *RetryWorker) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		w.logger.Println("disabled (no interval configured)")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if retried, err := w.service.RetryFailedSends(w.batchSize); err != nil {
+					w.logger.Printf("run failed: %v", err)
+				} else if retried > 0 {
+					w.logger.Printf("retried %d notifications", retried)
+				}
+			}
+		}
+	}()
+}
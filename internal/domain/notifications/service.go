@@ -3,9 +3,10 @@ package notifications
 import (
 	"context"
 	"errandShop/internal/services/firebase"
+	"errandShop/internal/services/sms"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +15,8 @@ import (
 type NotificationService interface {
 	// Core notification methods
 	CreateNotification(req *CreateNotificationRequest) (*NotificationResponse, error)
-	GetNotifications(recipientID uuid.UUID, recipientType NotificationRecipient, page, limit int) (*NotificationListResponse, error)
+	GetNotifications(recipientID uuid.UUID, recipientType NotificationRecipient, notificationType NotificationType, page, limit int) (*NotificationListResponse, error)
+	GetUnreadCounts(recipientID uuid.UUID, recipientType NotificationRecipient) (*UnreadCountsResponse, error)
 	MarkAsRead(id uint) error
 	MarkAllAsRead(recipientID uuid.UUID, recipientType NotificationRecipient) error
 	DeleteNotification(id uint) error
@@ -30,13 +32,65 @@ type NotificationService interface {
 	GetTemplates() ([]TemplateResponse, error)
 	UpdateTemplate(id uint, req *CreateTemplateRequest) (*TemplateResponse, error)
 	DeleteTemplate(id uint) error
+	PreviewTemplate(id uint, data map[string]string) (*TemplatePreviewResponse, error)
+	TestSendTemplate(id uint, adminID uuid.UUID, data map[string]string) (*TemplatePreviewResponse, error)
+	RenderLocalized(recipientID uuid.UUID, recipientType NotificationRecipient, key string, data map[string]string) (string, string, error)
+
+	// Push delivery retries
+	SetRetryPolicy(maxAttempts int, baseBackoff time.Duration)
+	RetryFailedSends(limit int) (int, error)
+	GetFailedSends(status SendStatus, page, limit int) (*NotificationListResponse, error)
+
+	// SMS channel for transactional updates
+	SetSMSSender(sender sms.SMSSender, phoneLookup PhoneLookup)
+
+	// Daily digest of low-priority notifications
+	GetPreferences(recipientID uuid.UUID, recipientType NotificationRecipient) (*NotificationPreferenceResponse, error)
+	UpdatePreferences(recipientID uuid.UUID, recipientType NotificationRecipient, req *UpdateNotificationPreferenceRequest) (*NotificationPreferenceResponse, error)
+	SendDigests(limit int) (int, error)
+	SetDigestEmailer(emailer DigestEmailer, emailLookup EmailLookup)
+
+	// Quiet hours, read in this timezone unless the recipient chose one
+	SetDefaultTimezone(loc *time.Location)
+
+	// Account data export and erasure (see auth.AccountDataSource)
+	ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error)
+	EraseUserData(ctx context.Context, userID uuid.UUID) error
 }
 
+// PhoneLookup returns a customer's verified phone number, or "" if they have none
+type PhoneLookup func(ctx context.Context, userID uuid.UUID) (string, error)
+
+// smsTypes are the high-priority transactional types also sent by SMS when a sender is configured
+var smsTypes = map[NotificationType]bool{
+	TypeOrderUpdate:    true,
+	TypeDeliveryUpdate: true,
+	TypePaymentUpdate:  true,
+}
+
+// errNoPushTarget means the recipient has no active push tokens, so there is nothing to retry
+var errNoPushTarget = errors.New("no active push tokens")
+
+const (
+	defaultMaxSendAttempts = 5
+	defaultSendBackoff     = time.Minute
+	maxSendBackoff         = time.Hour
+	// staleRetryClaim is how long a claimed retry may sit before another run picks it up again
+	staleRetryClaim = 15 * time.Minute
+)
+
 type notificationService struct {
 	notificationRepo NotificationRepository
 	templateRepo     TemplateRepository
 	pushTokenRepo    PushTokenRepository
 	fcmService       *firebase.FCMService
+	maxSendAttempts  int
+	sendBackoff      time.Duration
+	smsSender        sms.SMSSender
+	phoneLookup      PhoneLookup
+	digestEmailer    DigestEmailer
+	emailLookup      EmailLookup
+	defaultLocation  *time.Location // quiet hours timezone for recipients without one
 }
 
 func NewNotificationService(
@@ -56,11 +110,28 @@ func NewNotificationService(
 		templateRepo:     templateRepo,
 		pushTokenRepo:    pushTokenRepo,
 		fcmService:       fcmService,
+		maxSendAttempts:  defaultMaxSendAttempts,
+		sendBackoff:      defaultSendBackoff,
+		defaultLocation:  time.UTC,
 	}
 }
 
 // Core notification methods
 func (s *notificationService) CreateNotification(req *CreateNotificationRequest) (*NotificationResponse, error) {
+	notification, err := s.store(req)
+	if err != nil {
+		return nil, err
+	}
+	if !notification.held() {
+		// Send push notification asynchronously; failures are retried by the retry worker
+		go s.deliver(*notification)
+	}
+
+	return s.toNotificationResponse(notification), nil
+}
+
+// store saves a new notification, holding its push for the recipient's digest or quiet hours
+func (s *notificationService) store(req *CreateNotificationRequest) (*Notification, error) {
 	notification := &Notification{
 		RecipientID:   req.RecipientID,
 		RecipientType: req.RecipientType,
@@ -70,19 +141,26 @@ func (s *notificationService) CreateNotification(req *CreateNotificationRequest)
 		Data:          req.Data,
 		Status:        StatusPending,
 	}
+	if s.heldForDigest(req.RecipientID, req.RecipientType, req.Type) {
+		notification.SendStatus = SendDigestPending
+	} else {
+		s.holdForQuietHours(notification)
+	}
 
 	if err := s.notificationRepo.Create(notification); err != nil {
 		return nil, fmt.Errorf("failed to create notification: %w", err)
 	}
+	return notification, nil
+}
 
-	// Send push notification asynchronously
-	go s.sendPushToUser(req.RecipientID, string(req.RecipientType), req.Title, req.Body, req.Data)
-
-	return s.toNotificationResponse(notification), nil
+// held reports whether the push waits for the digest or, after quiet hours, the retry worker; the
+// in-app notification is visible straight away either way
+func (n *Notification) held() bool {
+	return n.SendStatus == SendDigestPending || n.SendStatus == SendQuietHours
 }
 
-func (s *notificationService) GetNotifications(recipientID uuid.UUID, recipientType NotificationRecipient, page, limit int) (*NotificationListResponse, error) {
-	notifications, total, err := s.notificationRepo.GetByRecipient(recipientID, recipientType, page, limit)
+func (s *notificationService) GetNotifications(recipientID uuid.UUID, recipientType NotificationRecipient, notificationType NotificationType, page, limit int) (*NotificationListResponse, error) {
+	notifications, total, err := s.notificationRepo.GetByRecipient(recipientID, recipientType, notificationType, page, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get notifications: %w", err)
 	}
@@ -107,6 +185,23 @@ func (s *notificationService) GetNotifications(recipientID uuid.UUID, recipientT
 	}, nil
 }
 
+func (s *notificationService) GetUnreadCounts(recipientID uuid.UUID, recipientType NotificationRecipient) (*UnreadCountsResponse, error) {
+	counts, err := s.notificationRepo.GetUnreadCountByType(recipientID, recipientType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unread counts: %w", err)
+	}
+
+	res := &UnreadCountsResponse{ByType: make(map[NotificationType]int64, len(NotificationTypes))}
+	for _, t := range NotificationTypes {
+		res.ByType[t] = 0
+	}
+	for t, count := range counts {
+		res.ByType[t] = count
+		res.Total += count
+	}
+	return res, nil
+}
+
 func (s *notificationService) MarkAsRead(id uint) error {
 	return s.notificationRepo.MarkAsRead(id)
 }
@@ -121,80 +216,52 @@ func (s *notificationService) DeleteNotification(id uint) error {
 
 // Push notification methods
 func (s *notificationService) SendPushNotification(req *SendPushNotificationRequest) error {
-	return s.sendPushToUser(req.UserID, req.UserType, req.Title, req.Body, req.Data)
+	if err := s.sendPushToUser(req.UserID, req.UserType, req.Title, req.Body, req.Data); err != nil && !errors.Is(err, errNoPushTarget) {
+		return err
+	}
+	return nil
 }
 
+// SendBroadcastNotification sends a notification to everyone with an active push token. Each
+// recipient gets their own notification, so digest mode and quiet hours hold the push just as
+// they do for CreateNotification.
 func (s *notificationService) SendBroadcastNotification(req *BroadcastNotificationRequest) error {
-	log.Printf("[BROADCAST] Sending broadcast notification: %s - %s", req.Title, req.Body)
-	
-	// Get all active push tokens
-	tokens, err := s.pushTokenRepo.GetActiveTokens(uuid.Nil, "")
-	if err != nil {
-		// Try a different approach - get all tokens by querying without user filter
-		log.Printf("[BROADCAST] Failed to get active tokens, trying alternative approach: %v", err)
-		// For now, we'll use FCM topic-based broadcasting
-		if s.fcmService != nil {
-			ctx := context.Background()
-			// Convert data map from interface{} to string for FCM
-			fcmData := make(map[string]string)
-			for k, v := range req.Data {
-				if str, ok := v.(string); ok {
-					fcmData[k] = str
-				} else {
-					fcmData[k] = fmt.Sprintf("%v", v)
-				}
-			}
-			
-			// Use topic-based broadcasting to "all_users" topic
-			_, err := s.fcmService.SendToTopic(ctx, "all_users", req.Title, req.Body, fcmData, "")
-			if err != nil {
-				log.Printf("[BROADCAST] FCM topic broadcast failed: %v", err)
-				return fmt.Errorf("failed to send broadcast notification: %w", err)
-			}
-			log.Printf("[BROADCAST] Successfully sent broadcast notification via FCM topic")
-			return nil
-		}
-		
-		// Fallback: log the broadcast (FCM not configured)
-		log.Printf("[BROADCAST] FCM not configured, broadcast logged only: Title=%s, Body=%s", req.Title, req.Body)
-		return nil
+	notificationType := req.Type
+	if notificationType == "" {
+		notificationType = TypePromotion
 	}
-	
-	if len(tokens) == 0 {
-		log.Printf("[BROADCAST] No active push tokens found")
-		return nil
+
+	recipients, err := s.pushTokenRepo.GetActiveRecipients()
+	if err != nil {
+		return fmt.Errorf("failed to get broadcast recipients: %w", err)
 	}
-	
-	// Send push notifications via FCM if available
-	if s.fcmService != nil {
-		// Convert data map from interface{} to string for FCM
-		fcmData := make(map[string]string)
-		for k, v := range req.Data {
-			if str, ok := v.(string); ok {
-				fcmData[k] = str
-			} else {
-				fcmData[k] = fmt.Sprintf("%v", v)
-			}
-		}
-		
-		// Extract token strings for multicast
-		tokenStrings := make([]string, len(tokens))
-		for i, token := range tokens {
-			tokenStrings[i] = token.Token
-		}
-		
-		ctx := context.Background()
-		_, err := s.fcmService.SendMulticast(ctx, tokenStrings, req.Title, req.Body, fcmData, "")
+
+	var ready []Notification
+	for _, recipient := range recipients {
+		notification, err := s.store(&CreateNotificationRequest{
+			RecipientID:   recipient.UserID,
+			RecipientType: NotificationRecipient(recipient.UserType),
+			Type:          notificationType,
+			Title:         req.Title,
+			Body:          req.Body,
+			Data:          req.Data,
+		})
 		if err != nil {
-			log.Printf("[BROADCAST] FCM multicast failed: %v", err)
-			return fmt.Errorf("failed to send broadcast notification: %w", err)
+			log.Printf("[BROADCAST] Failed to create notification for %s: %v", recipient.UserID, err)
+			continue
+		}
+		if !notification.held() {
+			ready = append(ready, *notification)
 		}
-		log.Printf("[BROADCAST] Successfully sent broadcast notification to %d tokens via FCM multicast", len(tokens))
-		return nil
 	}
-	
-	// Fallback: log the broadcast (FCM not configured)
-	log.Printf("[BROADCAST] FCM not configured - Logging broadcast notification to %d tokens: %s - %s", len(tokens), req.Title, req.Body)
+	log.Printf("[BROADCAST] %q to %d recipients, %d pushed now and the rest held", req.Title, len(recipients), len(ready))
+
+	// One worker rather than a goroutine per recipient; failures are retried by the retry worker
+	go func() {
+		for _, notification := range ready {
+			s.deliver(notification)
+		}
+	}()
 	return nil
 }
 
@@ -248,20 +315,16 @@ func (s *notificationService) RegisterPushToken(userID uuid.UUID, userType strin
 }
 
 func (s *notificationService) SendDeliveryUpdate(deliveryID uint, status string, customerID uuid.UUID) error {
-	// Get template for delivery updates
-	template, err := s.templateRepo.GetByType(TypeDeliveryUpdate)
+	// Use the delivery update template in the customer's language, or the default if there is none
+	title, body, err := s.RenderLocalized(customerID, RecipientCustomer, string(TypeDeliveryUpdate), map[string]string{"status": status})
 	if err != nil {
-		// Use default template if not found
-		template = &NotificationTemplate{
-			Title: "Delivery Update",
-			Body:  "Your delivery status has been updated to: {{.status}}",
+		if !errors.Is(err, ErrTemplateNotFound) {
+			log.Printf("Failed to render delivery update template: %v", err)
 		}
+		title = "Delivery Update"
+		body = "Your delivery status has been updated to: " + status
 	}
 
-	// Replace placeholders
-	title := strings.ReplaceAll(template.Title, "{{.status}}", status)
-	body := strings.ReplaceAll(template.Body, "{{.status}}", status)
-
 	// Create notification
 	notificationReq := &CreateNotificationRequest{
 		RecipientID:   customerID,
@@ -281,8 +344,14 @@ func (s *notificationService) SendDeliveryUpdate(deliveryID uint, status string,
 
 // Template methods
 func (s *notificationService) CreateTemplate(req *CreateTemplateRequest) (*TemplateResponse, error) {
+	key := req.Key
+	if key == "" {
+		key = string(req.Type)
+	}
 	template := &NotificationTemplate{
 		Type:     req.Type,
+		Key:      key,
+		Locale:   normalizeLocale(req.Locale),
 		Title:    req.Title,
 		Body:     req.Body,
 		IsActive: req.IsActive,
@@ -295,6 +364,8 @@ func (s *notificationService) CreateTemplate(req *CreateTemplateRequest) (*Templ
 	return &TemplateResponse{
 		ID:        template.ID,
 		Type:      template.Type,
+		Key:       template.Key,
+		Locale:    template.Locale,
 		Title:     template.Title,
 		Body:      template.Body,
 		IsActive:  template.IsActive,
@@ -314,6 +385,8 @@ func (s *notificationService) GetTemplates() ([]TemplateResponse, error) {
 		responses[i] = TemplateResponse{
 			ID:        template.ID,
 			Type:      template.Type,
+			Key:       template.Key,
+			Locale:    template.Locale,
 			Title:     template.Title,
 			Body:      template.Body,
 			IsActive:  template.IsActive,
@@ -326,12 +399,17 @@ func (s *notificationService) GetTemplates() ([]TemplateResponse, error) {
 }
 
 func (s *notificationService) UpdateTemplate(id uint, req *CreateTemplateRequest) (*TemplateResponse, error) {
+	// Key and Locale are left unchanged when omitted
 	template := &NotificationTemplate{
 		Type:     req.Type,
+		Key:      req.Key,
 		Title:    req.Title,
 		Body:     req.Body,
 		IsActive: req.IsActive,
 	}
+	if req.Locale != "" {
+		template.Locale = normalizeLocale(req.Locale)
+	}
 
 	if err := s.templateRepo.Update(id, template); err != nil {
 		return nil, fmt.Errorf("failed to update template: %w", err)
@@ -345,6 +423,8 @@ func (s *notificationService) UpdateTemplate(id uint, req *CreateTemplateRequest
 	return &TemplateResponse{
 		ID:        updatedTemplate.ID,
 		Type:      updatedTemplate.Type,
+		Key:       updatedTemplate.Key,
+		Locale:    updatedTemplate.Locale,
 		Title:     updatedTemplate.Title,
 		Body:      updatedTemplate.Body,
 		IsActive:  updatedTemplate.IsActive,
@@ -357,7 +437,136 @@ func (s *notificationService) DeleteTemplate(id uint) error {
 	return s.templateRepo.Delete(id)
 }
 
+// SetRetryPolicy configures how many push attempts are made before dead-lettering and the
+// initial backoff, which doubles after each failure (capped at an hour)
+func (s *notificationService) SetRetryPolicy(maxAttempts int, baseBackoff time.Duration) {
+	if maxAttempts > 0 {
+		s.maxSendAttempts = maxAttempts
+	}
+	if baseBackoff > 0 {
+		s.sendBackoff = baseBackoff
+	}
+}
+
+// RetryFailedSends re-attempts failed pushes whose backoff has elapsed, and pushes notifications
+// held for quiet hours that have ended, returning how many were sent
+func (s *notificationService) RetryFailedSends(limit int) (int, error) {
+	due, err := s.notificationRepo.GetDueForRetry(time.Now(), staleRetryClaim, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load notifications due for retry: %w", err)
+	}
+
+	retried := 0
+	for _, notification := range due {
+		claimed, err := s.notificationRepo.ClaimForRetry(notification.ID)
+		if err != nil {
+			log.Printf("Failed to claim notification %d for retry: %v", notification.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.deliver(notification)
+		retried++
+	}
+	return retried, nil
+}
+
+// GetFailedSends lists notifications whose push failed; an empty status returns failed,
+// retrying and dead-lettered notifications together
+func (s *notificationService) GetFailedSends(status SendStatus, page, limit int) (*NotificationListResponse, error) {
+	statuses := []SendStatus{SendFailed, SendRetrying, SendDeadLetter}
+	if status != "" {
+		statuses = []SendStatus{status}
+	}
+
+	notifications, total, err := s.notificationRepo.GetBySendStatus(statuses, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failed notifications: %w", err)
+	}
+
+	responses := make([]NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = *s.toNotificationResponse(&notification)
+	}
+
+	return &NotificationListResponse{
+		Notifications: responses,
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+	}, nil
+}
+
 // Helper methods
+
+// SetSMSSender enables the SMS channel; a nil sender disables it
+func (s *notificationService) SetSMSSender(sender sms.SMSSender, phoneLookup PhoneLookup) {
+	s.smsSender = sender
+	s.phoneLookup = phoneLookup
+}
+
+// sendSMS is best effort: it runs once per notification, never on push retries, and failures are only logged
+func (s *notificationService) sendSMS(notification Notification) {
+	if s.smsSender == nil || s.phoneLookup == nil || notification.RecipientType != RecipientCustomer || !smsTypes[notification.Type] {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	phone, err := s.phoneLookup(ctx, notification.RecipientID)
+	if err != nil || phone == "" {
+		return
+	}
+	if err := s.smsSender.SendSMS(ctx, phone, fmt.Sprintf("%s: %s", notification.Title, notification.Body)); err != nil {
+		log.Printf("Failed to send SMS for notification %d: %v", notification.ID, err)
+	}
+}
+
+// deliver pushes a stored notification and records the outcome, scheduling a retry with
+// exponential backoff or dead-lettering it once maxSendAttempts is reached
+func (s *notificationService) deliver(notification Notification) {
+	if notification.SendAttempts == 0 {
+		s.sendSMS(notification)
+	}
+	err := s.sendPushToUser(notification.RecipientID, string(notification.RecipientType), notification.Title, notification.Body, notification.Data)
+	attempts := notification.SendAttempts + 1
+
+	var status SendStatus
+	var lastError string
+	var nextSendAt *time.Time
+	switch {
+	case err == nil:
+		status = SendSent
+	case errors.Is(err, errNoPushTarget):
+		status = SendSkipped
+	case attempts >= s.maxSendAttempts:
+		status = SendDeadLetter
+		lastError = err.Error()
+		log.Printf("Notification %d dead-lettered after %d attempts: %v", notification.ID, attempts, err)
+	default:
+		status = SendFailed
+		lastError = err.Error()
+		next := time.Now().Add(s.backoff(attempts))
+		nextSendAt = &next
+	}
+
+	if recErr := s.notificationRepo.RecordSendResult(notification.ID, status, attempts, lastError, nextSendAt); recErr != nil {
+		log.Printf("Failed to record send result for notification %d: %v", notification.ID, recErr)
+	}
+}
+
+func (s *notificationService) backoff(attempts int) time.Duration {
+	d := s.sendBackoff
+	for i := 1; i < attempts && d < maxSendBackoff; i++ {
+		d *= 2
+	}
+	if d > maxSendBackoff {
+		d = maxSendBackoff
+	}
+	return d
+}
+
 func (s *notificationService) sendPushToUser(userID uuid.UUID, userType, title, body string, data map[string]interface{}) error {
 	tokens, err := s.pushTokenRepo.GetActiveTokens(userID, userType)
 	if err != nil {
@@ -367,7 +576,7 @@ func (s *notificationService) sendPushToUser(userID uuid.UUID, userType, title,
 
 	if len(tokens) == 0 {
 		log.Printf("No active push tokens found for user %s", userID)
-		return nil
+		return errNoPushTarget
 	}
 
 	// Send push notifications via FCM if available
@@ -382,6 +591,8 @@ func (s *notificationService) sendPushToUser(userID uuid.UUID, userType, title,
 			}
 		}
 
+		var failed int
+		var lastErr error
 		for _, token := range tokens {
 			fcmMsg := &firebase.FCMMessage{
 				Token: token.Token,
@@ -393,6 +604,8 @@ func (s *notificationService) sendPushToUser(userID uuid.UUID, userType, title,
 			_, err := s.fcmService.SendMessage(context.Background(), fcmMsg)
 			if err != nil {
 				log.Printf("Failed to send FCM to token %s: %v", token.Token, err)
+				failed++
+				lastErr = err
 			// TODO: Handle invalid tokens (remove from database)
 		} else {
 			log.Printf("Successfully sent FCM to user %s via token %s", userID, token.Token[:10]+"...")
@@ -401,6 +614,11 @@ func (s *notificationService) sendPushToUser(userID uuid.UUID, userType, title,
 			// Update last used timestamp
 			s.pushTokenRepo.UpdateLastUsed(token.ID)
 		}
+
+		// Only a failure on every device counts as a failed send
+		if failed == len(tokens) {
+			return fmt.Errorf("push failed on all %d devices: %w", failed, lastErr)
+		}
 	} else {
 		// Fallback: log the notification
 		for _, token := range tokens {
@@ -429,6 +647,10 @@ func (s *notificationService) toNotificationResponse(notification *Notification)
 		Status:        notification.Status,
 		ReadAt:        notification.ReadAt,
 		SentAt:        notification.SentAt,
+		SendStatus:    notification.SendStatus,
+		SendAttempts:  notification.SendAttempts,
+		LastSendError: notification.LastSendError,
+		NextSendAt:    notification.NextSendAt,
 		CreatedAt:     notification.CreatedAt,
 	}
 }
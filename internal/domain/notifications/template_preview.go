@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+)
+
+// ErrTemplateRender is returned when a template's title or body does not render, e.g. a
+// placeholder the data does not provide or malformed {{ }} syntax
+var ErrTemplateRender = errors.New("template does not render")
+
+// ErrNoTestDevices is returned by a test send when the admin has no active push tokens
+var ErrNoTestDevices = errors.New("no active devices registered for your account")
+
+// templateSampleData is the placeholder data used for previews and test sends, per template type.
+// Admin-supplied values override these.
+func templateSampleData(notificationType NotificationType) map[string]string {
+	data := map[string]string{
+		"customerName": "Ada",
+		"orderId":      "ORD-004217",
+		"amount":       "₦12,500.00",
+	}
+	switch notificationType {
+	case TypeOrderUpdate:
+		data["status"] = "confirmed"
+	case TypeDeliveryUpdate:
+		data["status"] = "out_for_delivery"
+		data["driverName"] = "Tunde"
+		data["eta"] = "15 minutes"
+	case TypePaymentUpdate:
+		data["status"] = "paid"
+	case TypePromotion:
+		data["code"] = "WELCOME10"
+		data["discount"] = "10%"
+	}
+	return data
+}
+
+// RenderTemplate fills a template's {{.key}} placeholders from data. A placeholder with no
+// value is an error, so a typo shows up in the preview rather than as a blank in a broadcast.
+func RenderTemplate(title, body string, data map[string]string) (string, string, error) {
+	renderedTitle, err := renderTemplateText("title", title, data)
+	if err != nil {
+		return "", "", err
+	}
+	renderedBody, err := renderTemplateText("body", body, data)
+	if err != nil {
+		return "", "", err
+	}
+	return renderedTitle, renderedBody, nil
+}
+
+func renderTemplateText(name, text string, data map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrTemplateRender, name, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrTemplateRender, name, err)
+	}
+	return out.String(), nil
+}
+
+// PreviewTemplate renders a stored template with sample data, overridden by data
+func (s *notificationService) PreviewTemplate(id uint, data map[string]string) (*TemplatePreviewResponse, error) {
+	tmpl, err := s.templateRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := templateSampleData(tmpl.Type)
+	for k, v := range data {
+		sample[k] = v
+	}
+	title, body, err := RenderTemplate(tmpl.Title, tmpl.Body, sample)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplatePreviewResponse{
+		TemplateID: tmpl.ID,
+		Type:       tmpl.Type,
+		Title:      title,
+		Body:       body,
+		Data:       sample,
+	}, nil
+}
+
+// TestSendTemplate renders a template like PreviewTemplate and pushes it to the requesting admin's
+// own devices only. Nothing is stored in anyone's notification inbox.
+func (s *notificationService) TestSendTemplate(id uint, adminID uuid.UUID, data map[string]string) (*TemplatePreviewResponse, error) {
+	preview, err := s.PreviewTemplate(id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	pushData := map[string]interface{}{
+		"type":       string(preview.Type),
+		"templateId": preview.TemplateID,
+		"test":       "true",
+	}
+	// Push tokens are registered under the customer user type for every role
+	if err := s.sendPushToUser(adminID, string(RecipientCustomer), preview.Title, preview.Body, pushData); err != nil {
+		if errors.Is(err, errNoPushTarget) {
+			return nil, ErrNoTestDevices
+		}
+		return nil, fmt.Errorf("failed to send test notification: %w", err)
+	}
+	return preview, nil
+}
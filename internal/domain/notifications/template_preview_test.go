@@ -0,0 +1,39 @@
+package notifications_test
+
+import (
+	"errors"
+	"testing"
+
+	notifications "errandShop/internal/domain/notifications"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	title, body, err := notifications.RenderTemplate(
+		"Order {{.orderId}}",
+		"Hi {{.customerName}}, your order is now {{.status}}.",
+		map[string]string{"orderId": "ORD-000001", "customerName": "Ada", "status": "confirmed"},
+	)
+	if err != nil {
+		t.Fatalf("RenderTemplate returned error: %v", err)
+	}
+	if title != "Order ORD-000001" {
+		t.Fatalf("title = %q", title)
+	}
+	if body != "Hi Ada, your order is now confirmed." {
+		t.Fatalf("body = %q", body)
+	}
+}
+
+func TestRenderTemplateRejectsMissingAndMalformedPlaceholders(t *testing.T) {
+	data := map[string]string{"status": "confirmed"}
+	for name, body := range map[string]string{
+		"missing key": "Your order is {{.stauts}}",
+		"malformed":   "Your order is {{.status}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := notifications.RenderTemplate("Order update", body, data); !errors.Is(err, notifications.ErrTemplateRender) {
+				t.Fatalf("err = %v, want ErrTemplateRender", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+
+	"errandShop/internal/domain/auth"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// inProgressStatuses are the order statuses that still need the customer's delivery details
+var inProgressStatuses = []OrderStatus{
+	OrderStatusScheduled,
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusPreparing,
+	OrderStatusOutForDelivery,
+}
+
+// ExportUserData returns every order the customer has placed, newest first, for their data export
+func (s *Service) ExportUserData(ctx context.Context, userID uuid.UUID) (interface{}, error) {
+	var orders []Order
+	if err := s.db.WithContext(ctx).Preload("Items").
+		Where("customer_id = ?", userID).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	responses := make([]OrderResponse, len(orders))
+	for i := range orders {
+		responses[i] = *s.toOrderResponseWithContext(ctx, &orders[i])
+	}
+	return responses, nil
+}
+
+// EraseUserData strips the recipient, contact and note fields from the customer's orders and
+// empties their cart and wishlist. Amounts, items and zones stay so sales reports still add up.
+// It refuses while an order is in progress, as the delivery still needs those details.
+func (s *Service) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	var inProgress int64
+	if err := s.db.WithContext(ctx).Model(&Order{}).
+		Where("customer_id = ? AND status IN ?", userID, inProgressStatuses).
+		Count(&inProgress).Error; err != nil {
+		return fmt.Errorf("failed to check orders in progress: %w", err)
+	}
+	if inProgress > 0 {
+		return fmt.Errorf("%w: %d order(s) still in progress; cancel them or wait until they are delivered", auth.ErrAccountDeletionBlocked, inProgress)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Order{}).Where("customer_id = ?", userID).Updates(map[string]interface{}{
+			"recipient_name":        "",
+			"recipient_phone":       "",
+			"delivery_instructions": "",
+			"notes":                 "",
+			"delivery_address_id":   nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymize orders: %w", err)
+		}
+		if err := tx.Where("cart_id IN (?)", tx.Model(&Cart{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&CartItem{}).Error; err != nil {
+			return fmt.Errorf("failed to clear cart: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&Cart{}).Error; err != nil {
+			return fmt.Errorf("failed to clear cart: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&SavedItem{}).Error; err != nil {
+			return fmt.Errorf("failed to clear wishlist: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"errandShop/internal/domain/auth"
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestEraseUserData(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (
+			id TEXT PRIMARY KEY,
+			customer_id TEXT,
+			status TEXT,
+			recipient_name TEXT,
+			recipient_phone TEXT,
+			delivery_instructions TEXT,
+			notes TEXT,
+			delivery_address_id INTEGER,
+			total_amount INTEGER,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE carts (id TEXT PRIMARY KEY, user_id TEXT)`,
+		`CREATE TABLE cart_items (id TEXT PRIMARY KEY, cart_id TEXT)`,
+		`CREATE TABLE saved_items (id TEXT PRIMARY KEY, user_id TEXT, product_id TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+
+	userID, otherID := uuid.New(), uuid.New()
+	addOrder := func(customerID uuid.UUID, status OrderStatus) string {
+		id := uuid.NewString()
+		if err := db.Exec(`INSERT INTO orders VALUES (?, ?, ?, 'Ada Obi', '08030000000', 'Leave with the gateman', 'Ring twice', 7, 250000, NULL)`,
+			id, customerID, status).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+		return id
+	}
+	delivered := addOrder(userID, OrderStatusDelivered)
+	inFlight := addOrder(userID, OrderStatusOutForDelivery)
+	others := addOrder(otherID, OrderStatusDelivered)
+	cartID := uuid.NewString()
+	for _, stmt := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"INSERT INTO carts VALUES (?, ?)", []interface{}{cartID, userID}},
+		{"INSERT INTO cart_items VALUES (?, ?)", []interface{}{uuid.NewString(), cartID}},
+		{"INSERT INTO saved_items VALUES (?, ?, ?)", []interface{}{uuid.NewString(), userID, uuid.NewString()}},
+	} {
+		if err := db.Exec(stmt.sql, stmt.args...).Error; err != nil {
+			t.Fatalf("failed to insert fixture: %v", err)
+		}
+	}
+
+	s := &Service{db: db}
+	ctx := context.Background()
+
+	if err := s.EraseUserData(ctx, userID); !errors.Is(err, auth.ErrAccountDeletionBlocked) {
+		t.Fatalf("with an order out for delivery: err = %v, want ErrAccountDeletionBlocked", err)
+	}
+	var recipient string
+	db.Raw("SELECT recipient_name FROM orders WHERE id = ?", delivered).Scan(&recipient)
+	if recipient == "" {
+		t.Fatal("a refused erasure must not change any order")
+	}
+
+	if err := db.Exec("UPDATE orders SET status = ? WHERE id = ?", OrderStatusDelivered, inFlight).Error; err != nil {
+		t.Fatalf("failed to deliver order: %v", err)
+	}
+	if err := s.EraseUserData(ctx, userID); err != nil {
+		t.Fatalf("EraseUserData returned error: %v", err)
+	}
+
+	type orderRow struct {
+		RecipientName        string
+		RecipientPhone       string
+		DeliveryInstructions string
+		Notes                string
+		DeliveryAddressID    *uint
+		TotalAmount          int64
+	}
+	for _, id := range []string{delivered, inFlight} {
+		var row orderRow
+		db.Raw("SELECT * FROM orders WHERE id = ?", id).Scan(&row)
+		if row.RecipientName != "" || row.RecipientPhone != "" || row.DeliveryInstructions != "" || row.Notes != "" || row.DeliveryAddressID != nil {
+			t.Fatalf("order %s still holds personal data: %+v", id, row)
+		}
+		if row.TotalAmount != 250000 {
+			t.Fatalf("order %s total = %d, want it kept at 250000", id, row.TotalAmount)
+		}
+	}
+	var kept orderRow
+	db.Raw("SELECT * FROM orders WHERE id = ?", others).Scan(&kept)
+	if kept.RecipientName != "Ada Obi" {
+		t.Fatalf("another customer's order was changed: %+v", kept)
+	}
+
+	for _, table := range []string{"carts", "cart_items", "saved_items"} {
+		var count int64
+		db.Table(table).Count(&count)
+		if count != 0 {
+			t.Fatalf("%s has %d rows left, want 0", table, count)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"errandShop/internal/domain/payments"
+	"errandShop/pkg/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidAdjustment is returned when a manual adjustment cannot be applied to an order
+var ErrInvalidAdjustment = errors.New("invalid order adjustment")
+
+// ErrAdjustmentRefundUnavailable is returned when a refund is requested for an adjustment that cannot be refunded
+var ErrAdjustmentRefundUnavailable = errors.New("adjustment cannot be refunded")
+
+// OrderAdjustment is a manual change to an order total made by support, e.g. goodwill credit for a
+// late delivery. Negative amounts reduce the total; positive amounts add to it.
+type OrderAdjustment struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID        uuid.UUID     `gorm:"type:uuid;not null;index" json:"orderId"`
+	AmountKobo     int64         `gorm:"not null" json:"amountKobo"`
+	Reason         string        `gorm:"type:text;not null" json:"reason"`
+	CreatedBy      uuid.UUID     `gorm:"type:uuid;not null" json:"createdBy"`  // admin who made the adjustment
+	RefundMethod   *RefundMethod `gorm:"type:varchar(20)" json:"refundMethod"` // set when a negative adjustment was refunded
+	RefundID       *string       `gorm:"type:uuid" json:"refundId"`            // payment refund, for original_payment refunds
+	RefundCouponID *uuid.UUID    `gorm:"type:uuid" json:"refundCouponId"`      // coupon issued, for store_credit refunds
+	CreatedAt      time.Time     `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// OrderRefunder raises refunds against an order's captured payments
+type OrderRefunder interface {
+	GetOrderPayments(orderID string) ([]payments.PaymentResponse, error)
+	InitiateRefund(req payments.RefundPaymentRequest) (*payments.RefundResponse, error)
+}
+
+// SetRefunder enables refunds to the original payment for adjustments and cancellations
+func (s *Service) SetRefunder(refunder OrderRefunder) {
+	s.refunder = refunder
+}
+
+// AdminAdjustOrder applies a manual adjustment to an order total and records which admin made it.
+// Positive adjustments are only allowed before the order is paid.
+// With req.Refund, a negative adjustment on a paid order also refunds that amount, to the order's
+// completed payment or as store credit, and marks the order partially refunded.
+func (s *Service) AdminAdjustOrder(ctx context.Context, id, adminID uuid.UUID, req AdjustOrderRequest) (*AdjustOrderResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if req.AmountKobo == 0 || reason == "" {
+		return nil, fmt.Errorf("%w: amount must be non-zero and a reason is required", ErrInvalidAdjustment)
+	}
+	if req.Refund && req.AmountKobo > 0 {
+		return nil, fmt.Errorf("%w: only negative adjustments can be refunded", ErrAdjustmentRefundUnavailable)
+	}
+	refundMethod := req.RefundMethod
+	if refundMethod == "" {
+		refundMethod = RefundToOriginalPayment
+	}
+
+	adjustment := OrderAdjustment{
+		OrderID:    id,
+		AmountKobo: req.AmountKobo,
+		Reason:     reason,
+		CreatedBy:  adminID,
+	}
+	var refund *OrderRefundResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error; err != nil {
+			return err
+		}
+		if order.Status == OrderStatusCancelled {
+			return fmt.Errorf("%w: order is cancelled", ErrInvalidAdjustment)
+		}
+		newTotal := order.TotalAmount + req.AmountKobo
+		if newTotal < 0 {
+			return fmt.Errorf("%w: adjustment would make the total negative", ErrInvalidAdjustment)
+		}
+		if req.AmountKobo > 0 && !awaitingPayment(order.PaymentStatus) {
+			// Nothing collects a balance after payment, so the extra would be shown but never charged
+			return fmt.Errorf("%w: order has already been paid, charge the extra separately", ErrInvalidAdjustment)
+		}
+		if req.Refund && order.PaymentStatus != PaymentStatusPaid && order.PaymentStatus != PaymentStatusPartiallyRefunded {
+			return fmt.Errorf("%w: order has not been paid", ErrAdjustmentRefundUnavailable)
+		}
+
+		if err := tx.Create(&adjustment).Error; err != nil {
+			return fmt.Errorf("failed to record adjustment: %w", err)
+		}
+		updates := map[string]interface{}{
+			"adjustment_kobo": gorm.Expr("adjustment_kobo + ?", req.AmountKobo),
+			"total_amount":    newTotal,
+			"version":         gorm.Expr("version + 1"),
+		}
+		if req.Refund {
+			updates["payment_status"] = PaymentStatusPartiallyRefunded
+		}
+		if err := tx.Model(&Order{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Issued last so a failed refund rolls the adjustment back with it
+		if req.Refund {
+			var err error
+			if refund, err = s.issueRefund(&order, -req.AmountKobo, refundMethod, "Order adjustment: "+reason); err != nil {
+				return err
+			}
+			adjustment.RefundMethod = &refundMethod
+			adjustment.RefundCouponID = storeCreditID(refund)
+			if refund.PaymentRefund != nil {
+				adjustment.RefundID = &refund.PaymentRefund.ID
+			}
+			return tx.Model(&OrderAdjustment{}).Where("id = ?", adjustment.ID).Updates(map[string]interface{}{
+				"refund_id":        adjustment.RefundID,
+				"refund_method":    refundMethod,
+				"refund_coupon_id": adjustment.RefundCouponID,
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.AdminGet(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	res := &AdjustOrderResponse{
+		Order:      s.toOrderResponseWithContext(ctx, order),
+		Adjustment: toOrderAdjustmentResponse(&adjustment),
+	}
+	if refund != nil {
+		res.Refund = refund.PaymentRefund
+		res.StoreCredit = refund.StoreCredit
+	}
+	return res, nil
+}
+
+// awaitingPayment reports whether the customer has yet to pay, so a raised total is what they will be charged
+func awaitingPayment(status PaymentStatus) bool {
+	return status == PaymentStatusUnpaid || status == PaymentStatusFailed || status == PaymentStatusExpired
+}
+
+func toOrderAdjustmentResponse(a *OrderAdjustment) OrderAdjustmentResponse {
+	return OrderAdjustmentResponse{
+		ID:             a.ID,
+		OrderID:        a.OrderID,
+		AmountKobo:     a.AmountKobo,
+		AmountNaira:    money.Money(a.AmountKobo).Naira(),
+		Reason:         a.Reason,
+		CreatedBy:      a.CreatedBy,
+		RefundID:       a.RefundID,
+		RefundMethod:   a.RefundMethod,
+		RefundCouponID: a.RefundCouponID,
+		CreatedAt:      a.CreatedAt,
+	}
+}
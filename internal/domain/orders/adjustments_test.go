@@ -0,0 +1,161 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"errandShop/internal/domain/auth"
+	"errandShop/internal/domain/customers"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// noCustomers has no customer profiles or users, which is enough to build order responses
+type noCustomers struct {
+	customers.Service
+}
+
+func (noCustomers) GetCustomerByUserID(uuid.UUID) (*customers.CustomerResponse, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (noCustomers) GetUserByID(context.Context, uuid.UUID) (*auth.UserResponse, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func newAdjustmentTestService(t *testing.T) (*Service, *gorm.DB, *fakeRefunder) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (
+			id TEXT PRIMARY KEY,
+			customer_id TEXT NOT NULL,
+			status TEXT,
+			payment_status TEXT,
+			total_amount INTEGER NOT NULL DEFAULT 0,
+			adjustment_kobo INTEGER NOT NULL DEFAULT 0,
+			version INTEGER NOT NULL DEFAULT 1,
+			custom_requests TEXT DEFAULT '[]',
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE order_adjustments (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+			order_id TEXT NOT NULL,
+			amount_kobo INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			refund_method TEXT,
+			refund_id TEXT,
+			refund_coupon_id TEXT,
+			created_at DATETIME
+		)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY, order_id TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	refunder := &fakeRefunder{}
+	return &Service{db: db, repo: NewRepository(db), refunder: refunder, customerService: noCustomers{}, authService: noCustomers{}}, db, refunder
+}
+
+func insertAdjustmentTestOrder(t *testing.T, db *gorm.DB, paymentStatus PaymentStatus) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status, payment_status, total_amount) VALUES (?, ?, ?, ?, 500000)`,
+		id, uuid.New(), OrderStatusConfirmed, paymentStatus).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	return id
+}
+
+func loadAdjustedOrder(t *testing.T, db *gorm.DB, id uuid.UUID) (Order, int64) {
+	t.Helper()
+	var order Order
+	if err := db.First(&order, "id = ?", id).Error; err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+	var adjustments int64
+	db.Model(&OrderAdjustment{}).Where("order_id = ?", id).Count(&adjustments)
+	return order, adjustments
+}
+
+func TestAdminAdjustOrderRaisesUnpaidTotal(t *testing.T) {
+	s, db, _ := newAdjustmentTestService(t)
+	id := insertAdjustmentTestOrder(t, db, PaymentStatusUnpaid)
+
+	if _, err := s.AdminAdjustOrder(context.Background(), id, uuid.New(), AdjustOrderRequest{AmountKobo: 20000, Reason: "extra packaging"}); err != nil {
+		t.Fatalf("AdminAdjustOrder: %v", err)
+	}
+
+	order, adjustments := loadAdjustedOrder(t, db, id)
+	if order.TotalAmount != 520000 || order.AdjustmentKobo != 20000 || order.PaymentStatus != PaymentStatusUnpaid {
+		t.Fatalf("order total %d, adjustment %d, payment %s; want 520000, 20000, unpaid",
+			order.TotalAmount, order.AdjustmentKobo, order.PaymentStatus)
+	}
+	if adjustments != 1 {
+		t.Fatalf("recorded %d adjustments, want 1", adjustments)
+	}
+}
+
+func TestAdminAdjustOrderRejectsChargingPaidOrders(t *testing.T) {
+	s, db, _ := newAdjustmentTestService(t)
+
+	for _, status := range []PaymentStatus{PaymentStatusPaid, PaymentStatusPending, PaymentStatusPartiallyRefunded} {
+		id := insertAdjustmentTestOrder(t, db, status)
+		_, err := s.AdminAdjustOrder(context.Background(), id, uuid.New(), AdjustOrderRequest{AmountKobo: 20000, Reason: "extra packaging"})
+		if !errors.Is(err, ErrInvalidAdjustment) {
+			t.Fatalf("%s order: err = %v, want ErrInvalidAdjustment", status, err)
+		}
+
+		order, adjustments := loadAdjustedOrder(t, db, id)
+		if order.TotalAmount != 500000 || adjustments != 0 {
+			t.Fatalf("%s order: total %d with %d adjustments, want it untouched", status, order.TotalAmount, adjustments)
+		}
+	}
+}
+
+func TestAdminAdjustOrderRefundsCreditOnPaidOrders(t *testing.T) {
+	s, db, refunder := newAdjustmentTestService(t)
+	id := insertAdjustmentTestOrder(t, db, PaymentStatusPaid)
+
+	if _, err := s.AdminAdjustOrder(context.Background(), id, uuid.New(), AdjustOrderRequest{AmountKobo: -50000, Reason: "late delivery", Refund: true}); err != nil {
+		t.Fatalf("AdminAdjustOrder: %v", err)
+	}
+
+	order, adjustments := loadAdjustedOrder(t, db, id)
+	if order.TotalAmount != 450000 || order.PaymentStatus != PaymentStatusPartiallyRefunded || adjustments != 1 {
+		t.Fatalf("order total %d, payment %s, %d adjustments; want 450000, partially_refunded, 1",
+			order.TotalAmount, order.PaymentStatus, adjustments)
+	}
+	if len(refunder.refunds) != 1 || refunder.refunds[0].AmountKobo != 50000 || refunder.refunds[0].PaymentID != "pay_1" {
+		t.Fatalf("refunds = %+v, want 50000 against pay_1", refunder.refunds)
+	}
+}
+
+func TestAdminAdjustOrderCreditWithoutRefundOnPaidOrder(t *testing.T) {
+	s, db, refunder := newAdjustmentTestService(t)
+	id := insertAdjustmentTestOrder(t, db, PaymentStatusPaid)
+
+	// Goodwill credit that is not paid back only lowers the recorded total
+	if _, err := s.AdminAdjustOrder(context.Background(), id, uuid.New(), AdjustOrderRequest{AmountKobo: -50000, Reason: "late delivery"}); err != nil {
+		t.Fatalf("AdminAdjustOrder: %v", err)
+	}
+
+	order, _ := loadAdjustedOrder(t, db, id)
+	if order.TotalAmount != 450000 || order.PaymentStatus != PaymentStatusPaid {
+		t.Fatalf("order total %d, payment %s; want 450000, paid", order.TotalAmount, order.PaymentStatus)
+	}
+	if len(refunder.refunds) != 0 {
+		t.Fatalf("refunds = %+v, want none", refunder.refunds)
+	}
+}
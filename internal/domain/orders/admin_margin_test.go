@@ -0,0 +1,30 @@
+package orders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAdminOrderResponseLeavesUncostedLinesOutOfGrossProfit(t *testing.T) {
+	s := &Service{customerService: noCustomers{}, authService: noCustomers{}}
+	ctx := context.Background()
+	line := func(quantity int, unitPrice, unitCost int64) OrderItem {
+		return OrderItem{ID: uuid.New(), ProductID: uuid.New(), Quantity: quantity, UnitPrice: unitPrice, TotalPrice: unitPrice * int64(quantity), UnitCost: unitCost}
+	}
+
+	costed := &Order{ID: uuid.New(), Items: []OrderItem{line(2, 150000, 100000), line(1, 50000, 0)}}
+	res := s.toAdminOrderResponse(ctx, costed)
+	if res.GrossProfit == nil || *res.GrossProfit != 100000 || !res.GrossProfitPartial {
+		t.Fatalf("gross profit = %v (partial %v), want 100000 from the costed line only", res.GrossProfit, res.GrossProfitPartial)
+	}
+	if res.Items[0].UnitCost == nil || *res.Items[0].UnitCost != 100000 || res.Items[1].UnitCost != nil {
+		t.Fatalf("unit costs = %v, %v; want 100000 and none recorded", res.Items[0].UnitCost, res.Items[1].UnitCost)
+	}
+
+	uncosted := &Order{ID: uuid.New(), Items: []OrderItem{line(1, 50000, 0)}}
+	if res := s.toAdminOrderResponse(ctx, uncosted); res.GrossProfit != nil || res.GrossProfitPartial {
+		t.Fatalf("gross profit = %v (partial %v), want unknown with no recorded costs", res.GrossProfit, res.GrossProfitPartial)
+	}
+}
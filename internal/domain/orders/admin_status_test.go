@@ -0,0 +1,59 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAdminUpdateStatusRejectsInvalidTransitions(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT, version INTEGER NOT NULL DEFAULT 1, custom_requests TEXT DEFAULT '[]', created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), order_id TEXT, from_status TEXT, to_status TEXT, by_admin_id TEXT, note TEXT, created_at DATETIME)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	s := &Service{repo: NewRepository(db)}
+	ctx := context.Background()
+
+	tests := []struct {
+		from, to OrderStatus
+		wantErr  bool
+	}{
+		{OrderStatusScheduled, OrderStatusConfirmed, true}, // would skip the stock taken at release
+		{OrderStatusPending, OrderStatusDelivered, true},
+		{OrderStatusDelivered, OrderStatusPreparing, true},
+		{OrderStatusConfirmed, OrderStatusPreparing, false},
+	}
+	for _, tt := range tests {
+		id := uuid.New()
+		if err := db.Exec(`INSERT INTO orders (id, customer_id, status) VALUES (?, ?, ?)`, id, uuid.New(), tt.from).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+		err := s.AdminUpdateStatus(ctx, id, tt.to, nil)
+		if tt.wantErr != errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("%s -> %s: err = %v, want invalid transition %v", tt.from, tt.to, err, tt.wantErr)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt.from, tt.to, err)
+		}
+
+		var status OrderStatus
+		db.Raw(`SELECT status FROM orders WHERE id = ?`, id).Scan(&status)
+		if want := map[bool]OrderStatus{true: tt.from, false: tt.to}[tt.wantErr]; status != want {
+			t.Errorf("%s -> %s: status = %s, want %s", tt.from, tt.to, status, want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package orders
+
+import "strings"
+
+// AttributionDirect is the source of orders placed without attribution
+const AttributionDirect = "direct"
+
+// normalizeAttribution trims and lowercases the attribution fields so "Instagram" and "instagram "
+// are reported together, and falls back to AttributionDirect without a source
+func normalizeAttribution(a Attribution) Attribution {
+	a.Source = strings.ToLower(strings.TrimSpace(a.Source))
+	a.Campaign = strings.ToLower(strings.TrimSpace(a.Campaign))
+	a.Medium = strings.ToLower(strings.TrimSpace(a.Medium))
+	if a.Source == "" {
+		a.Source = AttributionDirect
+	}
+	return a
+}
@@ -0,0 +1,78 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminBulkUpdateStatus moves each order to status when that is a valid transition from its
+// current status, and reports a result per order. One order failing does not stop the rest.
+// Cancellation is not offered here: it restocks and refunds, so it goes through AdminCancelOrder.
+func (s *Service) AdminBulkUpdateStatus(ctx context.Context, orderIDs []uuid.UUID, status OrderStatus) *BulkStatusUpdateResponse {
+	response := &BulkStatusUpdateResponse{
+		Status:  status,
+		Results: make([]BulkStatusResult, 0, len(orderIDs)),
+	}
+	seen := make(map[uuid.UUID]bool, len(orderIDs))
+	for _, id := range orderIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := s.bulkUpdateOne(ctx, id, status)
+		if result.Updated {
+			response.Updated++
+		} else {
+			response.Skipped++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
+
+// bulkUpdateOne checks and applies one order's status change, then notifies the customer as
+// AdminUpdateStatus does
+func (s *Service) bulkUpdateOne(ctx context.Context, id uuid.UUID, status OrderStatus) BulkStatusResult {
+	result := BulkStatusResult{OrderID: id}
+
+	order, err := s.repo.AdminGet(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Reason = "order not found"
+		} else {
+			log.Printf("Bulk status update: failed to get order %s: %v", id, err)
+			result.Reason = "failed to load order"
+		}
+		return result
+	}
+	result.FromStatus = order.Status
+
+	if !s.isValidStatusTransition(order.Status, status) {
+		result.Reason = fmt.Sprintf("cannot move a %s order to %s", order.Status, status)
+		return result
+	}
+
+	if err := s.repo.AdminUpdateStatus(ctx, id, status, order.Version); err != nil {
+		if errors.Is(err, ErrOrderVersionConflict) {
+			result.Reason = "order changed during the update; refetch it and try again"
+		} else {
+			log.Printf("Bulk status update: failed to update order %s: %v", id, err)
+			result.Reason = "failed to update order"
+		}
+		return result
+	}
+
+	s.sendOrderStatusNotification(order.CustomerID, id, status)
+	if status == OrderStatusDelivered {
+		s.creditReferral(ctx, order.CustomerID)
+		s.orderDelivered(id)
+	}
+	result.Updated = true
+	return result
+}
@@ -0,0 +1,151 @@
+package orders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// deliveredRecorder is a FunnelRecorder that only remembers delivered orders
+type deliveredRecorder struct {
+	delivered []uuid.UUID
+}
+
+func (r *deliveredRecorder) CartStarted(uuid.UUID)            {}
+func (r *deliveredRecorder) OrderPlaced(uuid.UUID, uuid.UUID) {}
+func (r *deliveredRecorder) OrderPaid(uuid.UUID)              {}
+func (r *deliveredRecorder) OrderDelivered(orderID uuid.UUID) {
+	r.delivered = append(r.delivered, orderID)
+}
+
+func newBulkStatusTestService(t *testing.T) (*Service, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT, version INTEGER NOT NULL DEFAULT 1, custom_requests TEXT DEFAULT '[]', estimated_delivery DATETIME, delivered_at DATETIME, delivery_variance_minutes INTEGER, created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), order_id TEXT, from_status TEXT, to_status TEXT, by_admin_id TEXT, note TEXT, created_at DATETIME)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	return &Service{repo: NewRepository(db), db: db}, db
+}
+
+func insertBulkTestOrder(t *testing.T, db *gorm.DB, status OrderStatus) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status) VALUES (?, ?, ?)`, id, uuid.New(), status).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	return id
+}
+
+func TestAdminBulkUpdateStatusRecordsDeliveredOrders(t *testing.T) {
+	s, db := newBulkStatusTestService(t)
+	recorder := &deliveredRecorder{}
+	s.funnel = recorder
+
+	id := insertBulkTestOrder(t, db, OrderStatusOutForDelivery)
+	response := s.AdminBulkUpdateStatus(context.Background(), []uuid.UUID{id}, OrderStatusDelivered)
+	if response.Updated != 1 {
+		t.Fatalf("updated = %d, want 1 (results %+v)", response.Updated, response.Results)
+	}
+	if len(recorder.delivered) != 1 || recorder.delivered[0] != id {
+		t.Fatalf("delivered funnel events = %v, want [%s]", recorder.delivered, id)
+	}
+}
+
+func TestBulkUpdateOrderStatusRequestValidation(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	tests := []struct {
+		name    string
+		req     BulkUpdateOrderStatusRequest
+		wantErr bool
+	}{
+		{"preparing", BulkUpdateOrderStatusRequest{OrderIDs: ids, Status: OrderStatusPreparing}, false},
+		{"delivered", BulkUpdateOrderStatusRequest{OrderIDs: ids, Status: OrderStatusDelivered}, false},
+		{"cancelled goes through the cancel endpoint", BulkUpdateOrderStatusRequest{OrderIDs: ids, Status: OrderStatusCancelled}, true},
+		{"no orders", BulkUpdateOrderStatusRequest{OrderIDs: []uuid.UUID{}, Status: OrderStatusPreparing}, true},
+		{"too many orders", BulkUpdateOrderStatusRequest{OrderIDs: make([]uuid.UUID, 201), Status: OrderStatusPreparing}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminBulkUpdateStatusReportsEachOrder(t *testing.T) {
+	s, db := newBulkStatusTestService(t)
+	ctx := context.Background()
+
+	confirmed := insertBulkTestOrder(t, db, OrderStatusConfirmed)
+	delivered := insertBulkTestOrder(t, db, OrderStatusDelivered)
+	missing := uuid.New()
+
+	// The confirmed order is listed twice; it is moved and reported once
+	response := s.AdminBulkUpdateStatus(ctx, []uuid.UUID{confirmed, delivered, missing, confirmed}, OrderStatusPreparing)
+	if response.Updated != 1 || response.Skipped != 2 || len(response.Results) != 3 {
+		t.Fatalf("updated %d, skipped %d, %d results; want 1, 2, 3", response.Updated, response.Skipped, len(response.Results))
+	}
+
+	want := []BulkStatusResult{
+		{OrderID: confirmed, Updated: true, FromStatus: OrderStatusConfirmed},
+		{OrderID: delivered, FromStatus: OrderStatusDelivered, Reason: "cannot move a delivered order to preparing"},
+		{OrderID: missing, Reason: "order not found"},
+	}
+	for i, w := range want {
+		if response.Results[i] != w {
+			t.Errorf("result %d = %+v, want %+v", i, response.Results[i], w)
+		}
+	}
+
+	var status OrderStatus
+	db.Raw(`SELECT status FROM orders WHERE id = ?`, delivered).Scan(&status)
+	if status != OrderStatusDelivered {
+		t.Fatalf("skipped order status = %s, want it left delivered", status)
+	}
+}
+
+func TestAdminBulkUpdateStatusSkipsOrdersChangedMidUpdate(t *testing.T) {
+	s, db := newBulkStatusTestService(t)
+	id := insertBulkTestOrder(t, db, OrderStatusConfirmed)
+
+	// Another admin edits the order between the bulk update reading and writing it
+	changed := false
+	if err := db.Callback().Update().Before("gorm:update").Register("test:concurrent_edit", func(tx *gorm.DB) {
+		if !changed {
+			changed = true
+			tx.Session(&gorm.Session{NewDB: true}).Exec(`UPDATE orders SET version = version + 1 WHERE id = ?`, id)
+		}
+	}); err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+
+	response := s.AdminBulkUpdateStatus(context.Background(), []uuid.UUID{id}, OrderStatusPreparing)
+	if response.Updated != 0 || response.Skipped != 1 {
+		t.Fatalf("updated %d, skipped %d; want the changed order skipped", response.Updated, response.Skipped)
+	}
+	if reason := response.Results[0].Reason; reason != "order changed during the update; refetch it and try again" {
+		t.Fatalf("reason = %q, want a version conflict", reason)
+	}
+
+	var status OrderStatus
+	db.Raw(`SELECT status FROM orders WHERE id = ?`, id).Scan(&status)
+	if status != OrderStatusConfirmed {
+		t.Fatalf("status = %s, want the conflicting update not applied", status)
+	}
+}
@@ -1,9 +1,12 @@
 package orders
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"errandShop/internal/domain/products"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -87,6 +90,21 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 
 	cart, err := h.service.AddToCart(c.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, errProductNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Product not found",
+			})
+		}
+		if errors.Is(err, errProductOutOfStock) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Product is out of stock",
+			})
+		}
+		if errors.Is(err, products.ErrOrderQuantityLimit) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to add item to cart",
 		})
@@ -95,6 +113,51 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	return c.JSON(cart)
 }
 
+// MergeCart godoc
+// @Summary Merge guest cart
+// @Description Merge a client-side guest cart into the user's cart after login
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MergeCartRequest true "Merge cart request"
+// @Success 200 {object} CartResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/cart/merge [post]
+func (h *CartHandler) MergeCart(c *fiber.Ctx) error {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req MergeCartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Validate request
+	if err := validate.Struct(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Each item needs a product ID and a quantity between 1 and 100",
+		})
+	}
+
+	cart, err := h.service.MergeCart(c.Context(), userID, req)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to merge cart",
+		})
+	}
+
+	return c.JSON(cart)
+}
+
 // UpdateCartItem godoc
 // @Summary Update cart item quantity
 // @Description Update the quantity of an item in the cart
@@ -142,11 +205,26 @@ func (h *CartHandler) UpdateCartItem(c *fiber.Ctx) error {
 
 	cart, err := h.service.UpdateCartItem(c.Context(), userID, itemID, req)
 	if err != nil {
-		if err.Error() == "cart item not found" {
+		if strings.Contains(err.Error(), "cart item not found") {
 			return c.Status(http.StatusNotFound).JSON(fiber.Map{
 				"error": "Cart item not found",
 			})
 		}
+		if errors.Is(err, errProductNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Product not found",
+			})
+		}
+		if errors.Is(err, errProductOutOfStock) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Product is out of stock",
+			})
+		}
+		if errors.Is(err, products.ErrOrderQuantityLimit) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update cart item",
 		})
@@ -225,6 +303,200 @@ func (h *CartHandler) ClearCart(c *fiber.Ctx) error {
 	})
 }
 
+// ListSavedItems godoc
+// @Summary Get wishlist
+// @Description Get the products the user has saved for later
+// @Tags Wishlist
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} SavedItemResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/wishlist [get]
+func (h *CartHandler) ListSavedItems(c *fiber.Ctx) error {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	items, err := h.service.ListSavedItems(c.Context(), userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get wishlist",
+		})
+	}
+
+	return c.JSON(items)
+}
+
+// SaveItem godoc
+// @Summary Save product to wishlist
+// @Description Bookmark a product without adding it to the cart
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SaveItemRequest true "Save item request"
+// @Success 200 {object} SavedItemResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/wishlist [post]
+func (h *CartHandler) SaveItem(c *fiber.Ctx) error {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req SaveItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.ProductID == uuid.Nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Product ID is required",
+		})
+	}
+
+	item, err := h.service.SaveItem(c.Context(), userID, req.ProductID)
+	if err != nil {
+		if errors.Is(err, errProductNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Product not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save item",
+		})
+	}
+
+	return c.JSON(item)
+}
+
+// RemoveSavedItem godoc
+// @Summary Remove product from wishlist
+// @Tags Wishlist
+// @Produce json
+// @Security BearerAuth
+// @Param productId path string true "Product ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/wishlist/{productId} [delete]
+func (h *CartHandler) RemoveSavedItem(c *fiber.Ctx) error {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	productID, err := uuid.Parse(c.Params("productId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid product ID",
+		})
+	}
+
+	if err := h.service.RemoveSavedItem(c.Context(), userID, productID); err != nil {
+		if errors.Is(err, ErrSavedItemNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove saved item",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Item removed from wishlist",
+	})
+}
+
+// MoveSavedItemToCart godoc
+// @Summary Move wishlist item to cart
+// @Description Add a saved product to the cart, checking stock, and remove it from the wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param productId path string true "Product ID"
+// @Param request body MoveToCartRequest false "Quantity to add (default 1)"
+// @Success 200 {object} CartResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/wishlist/{productId}/move-to-cart [post]
+func (h *CartHandler) MoveSavedItemToCart(c *fiber.Ctx) error {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	productID, err := uuid.Parse(c.Params("productId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid product ID",
+		})
+	}
+
+	var req MoveToCartRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+	if req.Quantity < 0 || req.Quantity > 100 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Quantity must be between 1 and 100",
+		})
+	}
+
+	cart, err := h.service.MoveSavedItemToCart(c.Context(), userID, productID, req.Quantity)
+	if err != nil {
+		if errors.Is(err, ErrSavedItemNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, errProductNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Product not found",
+			})
+		}
+		if errors.Is(err, errProductOutOfStock) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Product is out of stock",
+			})
+		}
+		if errors.Is(err, products.ErrOrderQuantityLimit) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to move item to cart",
+		})
+	}
+
+	return c.JSON(cart)
+}
+
 // ValidateCoupon godoc
 // @Summary Validate coupon for cart
 // @Description Validate a coupon code against the current cart total
@@ -0,0 +1,116 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"errandShop/internal/domain/notifications"
+	"gorm.io/gorm"
+)
+
+// CartReminderJob periodically nudges customers who left items in their cart.
+// A cart is considered abandoned when none of its items changed in the last
+// idleAfter window and its owner has not placed an order in that window.
+// Each cart receives at most maxReminders reminders, spaced idleAfter apart;
+// the counter resets when the customer adds items again.
+type CartReminderJob struct {
+	db                  *gorm.DB
+	notificationService notifications.NotificationService
+	idleAfter           time.Duration
+	maxReminders        int
+	interval            time.Duration
+	logger              *log.Logger
+}
+
+func NewCartReminderJob(db *gorm.DB, notificationService notifications.NotificationService, idleAfter time.Duration, maxReminders int) *CartReminderJob {
+	return &CartReminderJob{
+		db:                  db,
+		notificationService: notificationService,
+		idleAfter:           idleAfter,
+		maxReminders:        maxReminders,
+		interval:            time.Hour,
+		logger:              log.New(log.Writer(), "[CART-REMINDER] ", log.LstdFlags),
+	}
+}
+
+// Start runs the job on a fixed interval until ctx is cancelled
+func (j *CartReminderJob) Start(ctx context.Context) {
+	if j.maxReminders <= 0 || j.idleAfter <= 0 {
+		j.logger.Println("disabled (no reminders configured)")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if sent, err := j.RunOnce(ctx); err != nil {
+					j.logger.Printf("run failed: %v", err)
+				} else if sent > 0 {
+					j.logger.Printf("sent %d reminders", sent)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce sends reminders for all currently abandoned carts and returns how many were sent
+func (j *CartReminderJob) RunOnce(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-j.idleAfter)
+
+	var carts []Cart
+	err := j.db.WithContext(ctx).
+		Where("reminder_count < ?", j.maxReminders).
+		Where("last_reminder_at IS NULL OR last_reminder_at < ?", cutoff).
+		Where("EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = carts.id)").
+		Where("(SELECT MAX(ci.updated_at) FROM cart_items ci WHERE ci.cart_id = carts.id) < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = carts.user_id AND o.created_at >= ?)", cutoff).
+		Preload("Items").
+		Find(&carts).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find abandoned carts: %w", err)
+	}
+
+	sent := 0
+	for _, cart := range carts {
+		itemCount := 0
+		for _, item := range cart.Items {
+			itemCount += item.Quantity
+		}
+
+		req := &notifications.CreateNotificationRequest{
+			RecipientID:   cart.UserID,
+			RecipientType: notifications.RecipientCustomer,
+			Type:          notifications.TypePromotion,
+			Title:         "You left items in your cart",
+			Body:          fmt.Sprintf("You still have %d item(s) waiting in your cart. Complete your order whenever you're ready.", itemCount),
+			Data: map[string]interface{}{
+				"cartId": cart.ID.String(),
+				"reason": "abandoned_cart",
+			},
+		}
+		if _, err := j.notificationService.CreateNotification(req); err != nil {
+			j.logger.Printf("failed to notify user %s: %v", cart.UserID, err)
+			continue
+		}
+
+		// UpdateColumns keeps updated_at untouched so reminders don't count as cart activity
+		now := time.Now()
+		if err := j.db.WithContext(ctx).Model(&Cart{}).Where("id = ?", cart.ID).UpdateColumns(map[string]interface{}{
+			"last_reminder_at": now,
+			"reminder_count":   gorm.Expr("reminder_count + 1"),
+		}).Error; err != nil {
+			j.logger.Printf("failed to record reminder for cart %s: %v", cart.ID, err)
+		}
+		sent++
+	}
+
+	return sent, nil
+}
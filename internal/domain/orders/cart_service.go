@@ -9,9 +9,15 @@ import (
 	"errandShop/internal/domain/products"
 )
 
+var (
+	errProductNotFound   = errors.New("product not found")
+	errProductOutOfStock = errors.New("product is out of stock")
+)
+
 type CartService struct {
 	db          *gorm.DB
 	productRepo *products.Repository
+	funnel      FunnelRecorder // see Service.SetFunnelRecorder
 }
 
 func NewCartService(db *gorm.DB, productRepo *products.Repository) *CartService {
@@ -41,45 +47,198 @@ func (s *CartService) GetOrCreateCart(userID uuid.UUID) (*Cart, error) {
 	return &cart, nil
 }
 
-// AddToCart adds an item to the user's cart
-func (s *CartService) AddToCart(userID uuid.UUID, req AddToCartRequest) (*Cart, error) {
+// AddToCart adds an item to the user's cart. The resulting quantity is capped at the
+// product's available stock, in which case a warning is returned alongside the cart, and must
+// fall within the product's per-order quantity limits.
+func (s *CartService) AddToCart(userID uuid.UUID, req AddToCartRequest) (*Cart, []string, error) {
 	cart, err := s.GetOrCreateCart(userID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	wasEmpty := len(cart.Items) == 0
+
+	product, err := s.getPurchasableProduct(req.ProductID)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Check if item already exists in cart
+	var warnings []string
 	var existingItem CartItem
 	err = s.db.Where("cart_id = ? AND product_id = ?", cart.ID, req.ProductID).First(&existingItem).Error
 	if err == nil {
 		// Update quantity
-		existingItem.Quantity += req.Quantity
+		quantity, warning := capToStock(product, existingItem.Quantity+req.Quantity)
+		if err := product.CheckOrderQuantity(quantity); err != nil {
+			return nil, nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		existingItem.Quantity = quantity
 		if err := s.db.Save(&existingItem).Error; err != nil {
-			return nil, fmt.Errorf("failed to update cart item: %w", err)
+			return nil, nil, fmt.Errorf("failed to update cart item: %w", err)
 		}
 	} else if errors.Is(err, gorm.ErrRecordNotFound) {
 		// Add new item
+		quantity, warning := capToStock(product, req.Quantity)
+		if err := product.CheckOrderQuantity(quantity); err != nil {
+			return nil, nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
 		newItem := CartItem{
 			CartID:    cart.ID,
 			ProductID: req.ProductID,
-			Quantity:  req.Quantity,
+			Quantity:  quantity,
 		}
 		if err := s.db.Create(&newItem).Error; err != nil {
-			return nil, fmt.Errorf("failed to add cart item: %w", err)
+			return nil, nil, fmt.Errorf("failed to add cart item: %w", err)
 		}
 	} else {
-		return nil, fmt.Errorf("failed to check existing cart item: %w", err)
+		return nil, nil, fmt.Errorf("failed to check existing cart item: %w", err)
 	}
 
+	s.resetReminders(cart.ID)
+
 	// Reload cart with items
-	return s.GetOrCreateCart(userID)
+	cart, err = s.GetOrCreateCart(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.cartStarted(userID, wasEmpty, cart)
+	return cart, warnings, nil
 }
 
-// UpdateCartItem updates the quantity of a cart item
-func (s *CartService) UpdateCartItem(userID uuid.UUID, itemID uuid.UUID, req UpdateCartItemRequest) (*Cart, error) {
+// getPurchasableProduct loads an active product that has stock left
+func (s *CartService) getPurchasableProduct(productID uuid.UUID) (*products.Product, error) {
+	product, err := s.productRepo.GetByID(context.Background(), productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product.StockQuantity <= 0 {
+		return nil, errProductOutOfStock
+	}
+	return product, nil
+}
+
+// capToStock limits a requested quantity to available stock, returning a warning when it does
+func capToStock(product *products.Product, quantity int) (int, string) {
+	if quantity <= product.StockQuantity {
+		return quantity, ""
+	}
+	return product.StockQuantity, fmt.Sprintf("only %d of %s in stock; quantity adjusted", product.StockQuantity, product.Name)
+}
+
+// MergeCart merges guest cart lines into the user's cart. Duplicate products are summed,
+// inactive products are skipped and quantities are capped at available stock; each
+// adjustment is reported as a warning.
+func (s *CartService) MergeCart(userID uuid.UUID, items []AddToCartRequest) (*Cart, []string, error) {
 	cart, err := s.GetOrCreateCart(userID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	wasEmpty := len(cart.Items) == 0
+
+	// Sum duplicate products from the incoming cart, preserving order
+	incoming := make(map[uuid.UUID]int)
+	var productIDs []uuid.UUID
+	for _, item := range items {
+		if _, seen := incoming[item.ProductID]; !seen {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		incoming[item.ProductID] += item.Quantity
+	}
+
+	var warnings []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, productID := range productIDs {
+			var product products.Product
+			if err := tx.Where("id = ? AND is_active = ?", productID, true).First(&product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					warnings = append(warnings, fmt.Sprintf("product %s is no longer available and was skipped", productID))
+					continue
+				}
+				return fmt.Errorf("failed to get product %s: %w", productID, err)
+			}
+
+			var existingItem CartItem
+			err := tx.Where("cart_id = ? AND product_id = ?", cart.ID, productID).First(&existingItem).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to check existing cart item: %w", err)
+			}
+			exists := err == nil
+
+			quantity, warning := capToStock(&product, existingItem.Quantity+incoming[productID])
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+			// Merging is never rejected; an over-limit quantity is trimmed and checkout enforces the minimum
+			if product.MaxOrderQty > 0 && quantity > product.MaxOrderQty {
+				quantity = product.MaxOrderQty
+				warnings = append(warnings, fmt.Sprintf("%s is limited to %d per order; quantity reduced", product.Name, product.MaxOrderQty))
+			}
+
+			switch {
+			case quantity <= 0 && exists:
+				if err := tx.Delete(&existingItem).Error; err != nil {
+					return fmt.Errorf("failed to remove cart item: %w", err)
+				}
+			case quantity <= 0:
+				// Nothing in stock and nothing in the cart yet
+			case exists:
+				existingItem.Quantity = quantity
+				if err := tx.Save(&existingItem).Error; err != nil {
+					return fmt.Errorf("failed to update cart item: %w", err)
+				}
+			default:
+				newItem := CartItem{
+					CartID:    cart.ID,
+					ProductID: productID,
+					Quantity:  quantity,
+				}
+				if err := tx.Create(&newItem).Error; err != nil {
+					return fmt.Errorf("failed to add cart item: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.resetReminders(cart.ID)
+
+	// Reload cart with items
+	cart, err = s.GetOrCreateCart(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.cartStarted(userID, wasEmpty, cart)
+	return cart, warnings, nil
+}
+
+// resetReminders re-arms abandoned cart reminders once the customer is active again
+func (s *CartService) resetReminders(cartID uuid.UUID) {
+	if err := s.db.Model(&Cart{}).Where("id = ? AND reminder_count > 0", cartID).UpdateColumns(map[string]interface{}{
+		"reminder_count":   0,
+		"last_reminder_at": nil,
+	}).Error; err != nil {
+		fmt.Printf("Warning: failed to reset cart reminders for cart %s: %v\n", cartID, err)
+	}
+}
+
+// UpdateCartItem updates the quantity of a cart item, capping it at available stock. The
+// quantity must fall within the product's per-order quantity limits.
+func (s *CartService) UpdateCartItem(userID uuid.UUID, itemID uuid.UUID, req UpdateCartItemRequest) (*Cart, []string, error) {
+	cart, err := s.GetOrCreateCart(userID)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Find the cart item
@@ -87,19 +246,37 @@ func (s *CartService) UpdateCartItem(userID uuid.UUID, itemID uuid.UUID, req Upd
 	err = s.db.Where("id = ? AND cart_id = ?", itemID, cart.ID).First(&cartItem).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("cart item not found")
+			return nil, nil, errors.New("cart item not found")
 		}
-		return nil, fmt.Errorf("failed to find cart item: %w", err)
+		return nil, nil, fmt.Errorf("failed to find cart item: %w", err)
+	}
+
+	product, err := s.getPurchasableProduct(cartItem.ProductID)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Update quantity
-	cartItem.Quantity = req.Quantity
+	var warnings []string
+	quantity, warning := capToStock(product, req.Quantity)
+	if err := product.CheckOrderQuantity(quantity); err != nil {
+		return nil, nil, err
+	}
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	cartItem.Quantity = quantity
 	if err := s.db.Save(&cartItem).Error; err != nil {
-		return nil, fmt.Errorf("failed to update cart item: %w", err)
+		return nil, nil, fmt.Errorf("failed to update cart item: %w", err)
 	}
+	s.resetReminders(cart.ID)
 
 	// Reload cart with items
-	return s.GetOrCreateCart(userID)
+	cart, err = s.GetOrCreateCart(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cart, warnings, nil
 }
 
 // RemoveFromCart removes an item from the cart
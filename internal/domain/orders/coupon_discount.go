@@ -0,0 +1,35 @@
+package orders
+
+import (
+	"strconv"
+
+	"errandShop/internal/core/types"
+	"errandShop/internal/domain/coupons"
+)
+
+// couponZoneID is the zone ID zone-restricted coupons are checked against, or "" when the order
+// matched no delivery zone
+func couponZoneID(zone *types.MatchResult) string {
+	if zone == nil {
+		return ""
+	}
+	return strconv.Itoa(zone.ZoneID)
+}
+
+// applyCouponDiscount puts a valid coupon's discount on the order. A free delivery coupon comes
+// off the delivery fee line, never below zero; any other coupon is a discount on the total. It
+// returns the coupon discount and the delivery discount, both in kobo.
+func applyCouponDiscount(fees *OrderFees, validation *coupons.CouponValidationResponse) (couponDiscount, deliveryDiscount int64) {
+	if validation == nil || !validation.Valid {
+		return 0, 0
+	}
+	if !validation.AppliesToDelivery {
+		return validation.DiscountAmount, 0
+	}
+	deliveryDiscount = validation.DiscountAmount
+	if deliveryDiscount > fees.DeliveryFee {
+		deliveryDiscount = fees.DeliveryFee
+	}
+	fees.DeliveryFee -= deliveryDiscount
+	return 0, deliveryDiscount
+}
@@ -0,0 +1,44 @@
+package orders
+
+import (
+	"testing"
+
+	"errandShop/internal/core/types"
+	"errandShop/internal/domain/coupons"
+)
+
+func TestApplyCouponDiscount(t *testing.T) {
+	tests := []struct {
+		name            string
+		validation      *coupons.CouponValidationResponse
+		wantCoupon      int64
+		wantDelivery    int64
+		wantDeliveryFee int64
+	}{
+		{"no coupon", nil, 0, 0, 150000},
+		{"items coupon is a discount on the total", &coupons.CouponValidationResponse{Valid: true, DiscountAmount: 50000}, 50000, 0, 150000},
+		{"free delivery comes off the delivery fee", &coupons.CouponValidationResponse{Valid: true, DiscountAmount: 150000, AppliesToDelivery: true}, 0, 150000, 0},
+		{"delivery discount never exceeds the fee", &coupons.CouponValidationResponse{Valid: true, DiscountAmount: 200000, AppliesToDelivery: true}, 0, 150000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fees := OrderFees{ItemsSubtotal: 200000, DeliveryFee: 150000, ServiceFee: 10000}
+			coupon, delivery := applyCouponDiscount(&fees, tt.validation)
+			if coupon != tt.wantCoupon || delivery != tt.wantDelivery {
+				t.Fatalf("discounts = (%d, %d), want (%d, %d)", coupon, delivery, tt.wantCoupon, tt.wantDelivery)
+			}
+			if fees.DeliveryFee != tt.wantDeliveryFee {
+				t.Fatalf("DeliveryFee = %d, want %d", fees.DeliveryFee, tt.wantDeliveryFee)
+			}
+		})
+	}
+}
+
+func TestCouponZoneID(t *testing.T) {
+	if got := couponZoneID(nil); got != "" {
+		t.Fatalf("couponZoneID(nil) = %q, want empty", got)
+	}
+	if got := couponZoneID(&types.MatchResult{ZoneID: 3}); got != "3" {
+		t.Fatalf("couponZoneID = %q, want \"3\"", got)
+	}
+}
@@ -0,0 +1,75 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateStatusOnlyLetsCustomersCancel(t *testing.T) {
+	s, db := newBulkStatusTestService(t)
+	recorder := &deliveredRecorder{}
+	s.funnel = recorder
+	ctx := context.Background()
+
+	tests := []struct {
+		from, to OrderStatus
+	}{
+		{OrderStatusPending, OrderStatusConfirmed},
+		{OrderStatusConfirmed, OrderStatusPreparing},
+		{OrderStatusOutForDelivery, OrderStatusDelivered},
+	}
+	for _, tt := range tests {
+		customerID := uuid.New()
+		id := uuid.New()
+		if err := db.Exec(`INSERT INTO orders (id, customer_id, status) VALUES (?, ?, ?)`, id, customerID, tt.from).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+
+		if err := s.UpdateStatus(ctx, id, customerID, tt.to); !errors.Is(err, ErrCustomerStatusChange) {
+			t.Errorf("%s -> %s: err = %v, want ErrCustomerStatusChange", tt.from, tt.to, err)
+		}
+		var status OrderStatus
+		db.Raw(`SELECT status FROM orders WHERE id = ?`, id).Scan(&status)
+		if status != tt.from {
+			t.Errorf("%s -> %s: status = %s, want it unchanged", tt.from, tt.to, status)
+		}
+	}
+	if len(recorder.delivered) != 0 {
+		t.Fatalf("recorded delivered orders %v, want none", recorder.delivered)
+	}
+}
+
+func TestUpdateStatusCancelsThroughCancelOrder(t *testing.T) {
+	s, db := newBulkStatusTestService(t)
+	s.customerCancelCutoff = OrderStatusPreparing
+	ctx := context.Background()
+
+	customerID := uuid.New()
+	late := uuid.New()
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status) VALUES (?, ?, ?)`, late, customerID, OrderStatusOutForDelivery).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	// Past the self-cancel cutoff the generic status route refuses just like the cancel route
+	if err := s.UpdateStatus(ctx, late, customerID, OrderStatusCancelled); !errors.Is(err, ErrCancelRequiresSupport) {
+		t.Fatalf("cancelling an order out for delivery: err = %v, want ErrCancelRequiresSupport", err)
+	}
+
+	early := uuid.New()
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status) VALUES (?, ?, ?)`, early, customerID, OrderStatusPending).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	if err := s.UpdateStatus(ctx, early, customerID, OrderStatusCancelled); err != nil {
+		t.Fatalf("cancelling a pending order: %v", err)
+	}
+
+	for id, want := range map[uuid.UUID]OrderStatus{late: OrderStatusOutForDelivery, early: OrderStatusCancelled} {
+		var status OrderStatus
+		db.Raw(`SELECT status FROM orders WHERE id = ?`, id).Scan(&status)
+		if status != want {
+			t.Errorf("order %s status = %s, want %s", id, status, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"errandShop/internal/core/types"
+	"errandShop/internal/repos"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidDeliveryAddress is returned when a delivery address ID is malformed or no such address exists
+	ErrInvalidDeliveryAddress = errors.New("invalid delivery address")
+	// ErrDeliveryAddressForbidden is returned when the delivery address belongs to another user
+	ErrDeliveryAddressForbidden = errors.New("delivery address does not belong to you")
+)
+
+// ParseDeliveryAddressID reads the optional delivery address ID sent by the client. An empty ID
+// means no address; anything other than a positive integer is rejected rather than priced as if
+// no address had been given.
+func ParseDeliveryAddressID(raw *string) (*uint, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(*raw), 10, 32)
+	if err != nil || id == 0 {
+		return nil, fmt.Errorf("%w: %q is not a valid address ID", ErrInvalidDeliveryAddress, *raw)
+	}
+	addressID := uint(id)
+	return &addressID, nil
+}
+
+// ownedDeliveryAddress loads a delivery address and asserts that it belongs to userID
+func (s *Service) ownedDeliveryAddress(userID uuid.UUID, addressID uint) (*types.Address, error) {
+	address, err := s.addressRepo.GetByID(userID.String(), strconv.FormatUint(uint64(addressID), 10))
+	switch {
+	case errors.Is(err, repos.ErrAddressNotOwned):
+		return nil, fmt.Errorf("%w: address %d", ErrDeliveryAddressForbidden, addressID)
+	case errors.Is(err, repos.ErrAddressNotFound):
+		return nil, fmt.Errorf("%w: address %d not found", ErrInvalidDeliveryAddress, addressID)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get delivery address: %w", err)
+	}
+	if address.UserID != userID.String() {
+		return nil, fmt.Errorf("%w: address %d", ErrDeliveryAddressForbidden, addressID)
+	}
+	return address, nil
+}
@@ -0,0 +1,77 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+
+	"errandShop/internal/core/types"
+	"errandShop/internal/repos"
+	"github.com/google/uuid"
+)
+
+// stubAddressRepo holds addresses by ID with their owner, like the DB repo
+type stubAddressRepo map[string]*types.Address
+
+func (r stubAddressRepo) GetByID(userID, addressID string) (*types.Address, error) {
+	addr, ok := r[addressID]
+	if !ok {
+		return nil, repos.ErrAddressNotFound
+	}
+	if addr.UserID != userID {
+		return nil, repos.ErrAddressNotOwned
+	}
+	return addr, nil
+}
+
+func TestParseDeliveryAddressID(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		raw     *string
+		want    uint
+		wantErr bool
+	}{
+		{"no address", nil, 0, false},
+		{"blank address", str("  "), 0, false},
+		{"numeric ID", str("42"), 42, false},
+		{"padded ID", str(" 7 "), 7, false},
+		{"zero", str("0"), 0, true},
+		{"negative", str("-3"), 0, true},
+		{"not a number", str("ADDR-1"), 0, true},
+		{"too large", str("99999999999"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDeliveryAddressID(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDeliveryAddress) {
+					t.Fatalf("expected ErrInvalidDeliveryAddress, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (tt.want == 0) || (got != nil && *got != tt.want) {
+				t.Fatalf("ParseDeliveryAddressID = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnedDeliveryAddress(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	s := &Service{addressRepo: stubAddressRepo{
+		"5": {ID: "5", UserID: owner.String(), Text: "12 Admiralty Way, Lekki"},
+	}}
+
+	if addr, err := s.ownedDeliveryAddress(owner, 5); err != nil || addr.ID != "5" {
+		t.Fatalf("owner: got %v, %v", addr, err)
+	}
+	if _, err := s.ownedDeliveryAddress(other, 5); !errors.Is(err, ErrDeliveryAddressForbidden) {
+		t.Fatalf("other user: expected ErrDeliveryAddressForbidden, got %v", err)
+	}
+	if _, err := s.ownedDeliveryAddress(owner, 6); !errors.Is(err, ErrInvalidDeliveryAddress) {
+		t.Fatalf("missing address: expected ErrInvalidDeliveryAddress, got %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package orders
 
 import (
 	"time"
+
+	"errandShop/internal/domain/coupons"
+	"errandShop/internal/domain/payments"
 	"github.com/google/uuid"
 )
 
@@ -15,6 +18,11 @@ type UpdateCartItemRequest struct {
 	Quantity int `json:"quantity" validate:"required,min=1,max=100"`
 }
 
+// MergeCartRequest carries a guest (client-side) cart to merge into the user's server cart
+type MergeCartRequest struct {
+	Items []AddToCartRequest `json:"items" validate:"required,min=1,dive"`
+}
+
 type CartResponse struct {
 	ID        uuid.UUID          `json:"id"`
 	UserID    uuid.UUID          `json:"userId"`
@@ -22,6 +30,7 @@ type CartResponse struct {
 	TotalItems int               `json:"totalItems"`
 	TotalKobo  int64             `json:"totalKobo"`
 	TotalNaira float64           `json:"totalNaira"`
+	Warnings  []string           `json:"warnings,omitempty"`
 	CreatedAt time.Time          `json:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt"`
 }
@@ -39,6 +48,41 @@ type CartItemResponse struct {
 	UpdatedAt     time.Time    `json:"updatedAt"`
 }
 
+// ReorderSkippedItem describes a line from a past order that could not be re-added to the cart
+type ReorderSkippedItem struct {
+	ProductID       *uuid.UUID `json:"productId,omitempty"`
+	CustomRequestID *uuid.UUID `json:"customRequestId,omitempty"`
+	Name            string     `json:"name"`
+	Quantity        int        `json:"quantity"`
+	Reason          string     `json:"reason"`
+}
+
+// ReorderResponse is returned after cloning a past order into the cart
+type ReorderResponse struct {
+	Cart    *CartResponse        `json:"cart"`
+	Skipped []ReorderSkippedItem `json:"skipped"`
+}
+
+// Wishlist DTOs
+type SaveItemRequest struct {
+	ProductID uuid.UUID `json:"productId" validate:"required"`
+}
+
+// MoveToCartRequest moves a saved product into the cart; Quantity defaults to 1
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity" validate:"omitempty,min=1,max=100"`
+}
+
+// SavedItemResponse is a product on the customer's wishlist. InStock tells the client whether it
+// can be moved to the cart right now.
+type SavedItemResponse struct {
+	ID        uuid.UUID    `json:"id"`
+	ProductID uuid.UUID    `json:"productId"`
+	Product   *ProductInfo `json:"product,omitempty"`
+	InStock   bool         `json:"inStock"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
 // Order Request DTOs
 type CreateOrderRequest struct {
 	DeliveryAddressID *string                   `json:"delivery_address_id"`
@@ -47,10 +91,36 @@ type CreateOrderRequest struct {
 	Items             []CreateOrderItemRequest  `json:"items" validate:"dive"`
 	CustomRequests    []CreateOrderCustomRequest `json:"custom_requests,omitempty"`
 	CouponCode        *string                   `json:"couponCode"`
+	AutoApplyCoupon   bool                      `json:"autoApplyCoupon"` // With no couponCode, apply the user's best eligible coupon
+	TipKobo           int64                     `json:"tipKobo" validate:"min=0,max=5000000"` // Optional driver tip, capped at MaxTipKobo
 	Notes             string                    `json:"notes"`
-	IdempotencyKey    string                    `json:"IdempotencyKey" validate:"required"`
+	Recipient
+	Attribution
+	ScheduledFor      *time.Time                `json:"scheduledFor"` // Optional future delivery time; the order is held until shortly before it
+	StockShortfall    string                    `json:"stockShortfall" validate:"omitempty,oneof=reject remove substitute"` // what to do with items short of stock; default reject
+	IdempotencyKey    string                    `json:"IdempotencyKey"` // optional; without one, identical orders within a short window are merged (see SetDuplicateOrderWindow)
+}
+
+// Recipient is who the driver hands the order to when it isn't the customer (e.g. a gift).
+// Left empty, the customer's own name and phone are used.
+type Recipient struct {
+	RecipientName        string `json:"recipientName" validate:"omitempty,min=2,max=100"`
+	RecipientPhone       string `json:"recipientPhone" validate:"required_with=RecipientName,omitempty,min=10,max=20"`
+	DeliveryInstructions string `json:"deliveryInstructions" validate:"max=500"`
 }
 
+// Attribution is the marketing source that brought the customer to checkout, usually taken from
+// the utm_source, utm_campaign and utm_medium parameters of the landing link. Without a source the
+// order is attributed to "direct".
+type Attribution struct {
+	Source   string `json:"source" validate:"max=100"`
+	Campaign string `json:"campaign" validate:"max=100"`
+	Medium   string `json:"medium" validate:"max=100"`
+}
+
+// MaxTipKobo caps the driver tip on a single order (₦50,000)
+const MaxTipKobo int64 = 5000000
+
 type CreateOrderCustomRequest struct {
 	CustomRequestID uuid.UUID `json:"CustomRequestID" validate:"required"`
 	Title           string    `json:"title,omitempty"`
@@ -58,11 +128,23 @@ type CreateOrderCustomRequest struct {
 	Price           int64     `json:"price,omitempty"`
 }
 
+// UpdateOrderItemsRequest edits the catalog items of a pending, unpaid order. Each change sets
+// the final quantity for a product: unknown products are added, 0 removes the line.
+type UpdateOrderItemsRequest struct {
+	Items []OrderItemChange `json:"items" validate:"required,min=1,dive"`
+}
+
+type OrderItemChange struct {
+	ProductID uuid.UUID `json:"productId" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"min=0,max=100"`
+}
+
 type CreateOrderItemRequest struct {
 	ProductID uuid.UUID `json:"ProductID" validate:"required"`
 	Quantity  int       `json:"quantity" validate:"required,min=1,max=100"`
 	Name      string    `json:"name,omitempty"`
 	SKU       string    `json:"sku,omitempty"`
+	SubstituteFor *uuid.UUID `json:"-"` // set on lines added in place of a short product
 }
 
 type CreateOrderFromCartRequest struct {
@@ -70,34 +152,112 @@ type CreateOrderFromCartRequest struct {
 	DeliveryMode      string  `json:"delivery_mode"`
 	PaymentMethod     string  `json:"payment_method"`
 	CouponCode        *string `json:"couponCode"`
+	AutoApplyCoupon   bool    `json:"autoApplyCoupon"`
+	TipKobo           int64   `json:"tipKobo" validate:"min=0,max=5000000"`
 	Notes             string  `json:"notes"`
-	IdempotencyKey    string  `json:"IdempotencyKey" validate:"required"`
+	Recipient
+	Attribution
+	ScheduledFor      *time.Time `json:"scheduledFor"`
+	StockShortfall    string  `json:"stockShortfall" validate:"omitempty,oneof=reject remove substitute"`
+	IdempotencyKey    string  `json:"IdempotencyKey"` // optional; see CreateOrderRequest
 }
 
 type UpdateOrderStatusRequest struct {
 	Status OrderStatus `json:"status" validate:"required,oneof=pending confirmed preparing ready shipped out_for_delivery delivered cancelled refunded"`
 	Notes  string      `json:"notes"`
+	Version *int64     `json:"version"` // order version the change was based on; a stale one is rejected with 409
 }
 
 type UpdatePaymentStatusRequest struct {
-	PaymentStatus PaymentStatus `json:"paymentStatus" validate:"required,oneof=unpaid pending paid partially_refunded refunded failed expired"`
+	PaymentStatus PaymentStatus `json:"paymentStatus" validate:"required,oneof=unpaid pending paid partially_refunded refunded failed expired refund_due"`
+	Version       *int64        `json:"version"`
+}
+
+// BulkUpdateOrderStatusRequest moves many orders to one status, e.g. confirmed to preparing at dispatch
+type BulkUpdateOrderStatusRequest struct {
+	OrderIDs []uuid.UUID `json:"orderIds" validate:"required,min=1,max=200"`
+	Status   OrderStatus `json:"status" validate:"required,oneof=confirmed preparing out_for_delivery delivered"`
+}
+
+// BulkStatusResult is the outcome for one order in a bulk status update
+type BulkStatusResult struct {
+	OrderID    uuid.UUID   `json:"orderId"`
+	Updated    bool        `json:"updated"`
+	FromStatus OrderStatus `json:"fromStatus,omitempty"`
+	Reason     string      `json:"reason,omitempty"` // why the order was skipped
 }
 
+// BulkStatusUpdateResponse reports which orders moved and which were skipped
+type BulkStatusUpdateResponse struct {
+	Status  OrderStatus        `json:"status"`
+	Updated int                `json:"updated"`
+	Skipped int                `json:"skipped"`
+	Results []BulkStatusResult `json:"results"`
+}
+
+// CancelOrderRequest cancels an order. A paid order is refunded by RefundMethod; when it is empty,
+// customer cancellations get store credit and admin cancellations go back to the original payment.
 type CancelOrderRequest struct {
-	Reason string `json:"reason" validate:"required,min=3,max=500"`
+	Reason       string       `json:"reason" validate:"required,min=3,max=500"`
+	RefundMethod RefundMethod `json:"refundMethod" validate:"omitempty,oneof=original_payment store_credit"`
+}
+
+// RefundOrderRequest refunds a cancelled, paid order, e.g. to retry a refund that failed on cancel
+type RefundOrderRequest struct {
+	Method RefundMethod `json:"method" validate:"required,oneof=original_payment store_credit"`
+	Reason string       `json:"reason" validate:"required,min=3,max=500"`
+}
+
+// OrderRefundResponse is a refund issued for an order: a Paystack refund or a store credit coupon
+type OrderRefundResponse struct {
+	Method        RefundMethod             `json:"method"`
+	AmountKobo    int64                    `json:"amountKobo"`
+	AmountNaira   float64                  `json:"amountNaira"`
+	PaymentRefund *payments.RefundResponse `json:"paymentRefund,omitempty"`
+	StoreCredit   *coupons.CouponResponse  `json:"storeCredit,omitempty"`
+}
+
+// AdjustOrderRequest is a manual adjustment to an order total. A negative amount is a credit to
+// the customer; with Refund set it is also refunded if the order was paid, by RefundMethod
+// (default original_payment).
+type AdjustOrderRequest struct {
+	AmountKobo   int64        `json:"amountKobo" validate:"required"`
+	Reason       string       `json:"reason" validate:"required,min=3,max=500"`
+	Refund       bool         `json:"refund"`
+	RefundMethod RefundMethod `json:"refundMethod" validate:"omitempty,oneof=original_payment store_credit"`
+}
+
+type OrderAdjustmentResponse struct {
+	ID             uuid.UUID     `json:"id"`
+	OrderID        uuid.UUID     `json:"orderId"`
+	AmountKobo     int64         `json:"amountKobo"`
+	AmountNaira    float64       `json:"amountNaira"`
+	Reason         string        `json:"reason"`
+	CreatedBy      uuid.UUID     `json:"createdBy"`
+	RefundID       *string       `json:"refundId,omitempty"`
+	RefundMethod   *RefundMethod `json:"refundMethod,omitempty"`
+	RefundCouponID *uuid.UUID    `json:"refundCouponId,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
+}
+
+type AdjustOrderResponse struct {
+	Order       *OrderResponse           `json:"order"`
+	Adjustment  OrderAdjustmentResponse  `json:"adjustment"`
+	Refund      *payments.RefundResponse `json:"refund,omitempty"`
+	StoreCredit *coupons.CouponResponse  `json:"storeCredit,omitempty"`
 }
 
 // Query DTOs
 type ListQuery struct {
 	Page   int         `query:"page" validate:"omitempty,min=1"`
 	Limit  int         `query:"limit" validate:"omitempty,min=1,max=100"`
-	Status OrderStatus `query:"status" validate:"omitempty,oneof=pending confirmed preparing ready shipped out_for_delivery delivered cancelled refunded"`
+	Status OrderStatus `query:"status" validate:"omitempty,oneof=scheduled pending confirmed preparing ready shipped out_for_delivery delivered cancelled refunded"`
 }
 
 type AdminListQuery struct {
 	ListQuery
 	UserID        *uuid.UUID    `query:"user_id"`
-	PaymentStatus PaymentStatus `query:"payment_status" validate:"omitempty,oneof=unpaid pending paid partially_refunded refunded failed expired"`
+	PaymentStatus PaymentStatus `query:"payment_status" validate:"omitempty,oneof=unpaid pending paid partially_refunded refunded failed expired refund_due"`
 	SortBy        string        `query:"sort_by" validate:"omitempty,oneof=created_at total_amount status"`
 	SortOrder     string        `query:"sort_order" validate:"omitempty,oneof=asc desc"`
 	DateFrom      *time.Time    `query:"date_from"`
@@ -119,26 +279,59 @@ type OrderResponse struct {
 	DeliveryAddress   *AddressInfo            `json:"deliveryAddress,omitempty"`
 	Status            OrderStatus             `json:"status"`
 	PaymentStatus     PaymentStatus           `json:"paymentStatus"`
+	PaymentMethod     string                  `json:"paymentMethod"`
 	IdempotencyKey    string                  `json:"idempotencyKey"`
 	CouponCode        *string                 `json:"couponCode"`
+	CouponAutoApplied bool                    `json:"couponAutoApplied"`
 	CouponDiscount    int64                   `json:"couponDiscount"`
 	CouponDiscountNaira float64               `json:"couponDiscountNaira"`
 	ItemsSubtotal     int64                   `json:"itemsSubtotal"`
 	ItemsSubtotalNaira float64                `json:"itemsSubtotalNaira"`
 	DeliveryFee       int64                   `json:"deliveryFee"`
 	DeliveryFeeNaira  float64                 `json:"deliveryFeeNaira"`
+	DeliveryDiscount  int64                   `json:"deliveryDiscount"` // free delivery coupon; DeliveryFee is already net of it
+	DeliveryDiscountNaira float64             `json:"deliveryDiscountNaira"`
+	DeliverySurgeMultiplier float64           `json:"deliverySurgeMultiplier"` // above 1 when the order was placed during high demand
 	ServiceFee        int64                   `json:"serviceFee"`
 	ServiceFeeNaira   float64                 `json:"serviceFeeNaira"`
+	TaxKobo           int64                   `json:"taxKobo"`
+	TaxNaira          float64                 `json:"taxNaira"`
+	TaxMode           string                  `json:"taxMode"` // "inclusive": the totals already contain TaxKobo; "exclusive": TaxKobo was added to them
+	AdjustmentKobo    int64                   `json:"adjustmentKobo"` // manual admin adjustments; negative is a credit
+	AdjustmentNaira   float64                 `json:"adjustmentNaira"`
+	RefundMethod      *RefundMethod           `json:"refundMethod,omitempty"`   // how a cancelled order was refunded
+	RefundedKobo      int64                   `json:"refundedKobo"`
+	RefundedNaira     float64                 `json:"refundedNaira"`
+	RefundReference   *string                 `json:"refundReference,omitempty"` // Paystack refund ID or store credit coupon code
+	TipKobo           int64                   `json:"tipKobo"`
+	TipNaira          float64                 `json:"tipNaira"`
 	TotalAmount       int64                   `json:"totalAmount"`
 	TotalAmountNaira  float64                 `json:"totalAmountNaira"`
+	GrossProfit       *int64                  `json:"grossProfit,omitempty"`      // Admin only
+	GrossProfitNaira  float64                 `json:"grossProfitNaira,omitempty"` // Admin only
+	GrossProfitPartial bool                   `json:"grossProfitPartial,omitempty"` // Admin only; some lines had no recorded cost and are left out
 	CustomRequests    []uuid.UUID             `json:"customRequests"`
 	CustomRequestDetails []CustomRequestInfo  `json:"customRequestDetails"`
+	QuoteSnapshots    []QuoteSnapshot         `json:"quoteSnapshots"` // the quotes as accepted when the order was placed; details above show their current state
 	Notes             string                  `json:"notes"` 
+	RecipientName     string                  `json:"recipientName"`
+	RecipientPhone    string                  `json:"recipientPhone"`
+	DeliveryInstructions string               `json:"deliveryInstructions"`
+	UTMSource         string                  `json:"utmSource"`
+	UTMCampaign       string                  `json:"utmCampaign"`
+	UTMMedium         string                  `json:"utmMedium"`
+	ScheduledFor      *time.Time              `json:"scheduledFor"`
+	StockReservedUntil *time.Time             `json:"stockReservedUntil,omitempty"` // pay before this or the order is cancelled
 	EstimatedDelivery *time.Time              `json:"estimatedDelivery"`
 	DeliveredAt       *time.Time              `json:"deliveredAt"`
+	DeliveryVarianceMinutes *int              `json:"deliveryVarianceMinutes"`
+	ReceivedAt        *time.Time              `json:"receivedAt"`
+	PaidAt            *time.Time              `json:"paidAt"`
 	CancelledAt       *time.Time              `json:"cancelledAt"`
 	CancellationReason string                 `json:"cancellationReason"`
+	Version           int64                   `json:"version"`
 	Items             []OrderItemResponse     `json:"items"`
+	StockAdjustments  []StockShortfall        `json:"stockAdjustments,omitempty"` // short items removed or substituted at creation
 	StatusHistory     []OrderStatusHistoryResponse `json:"statusHistory,omitempty"`
 	CreatedAt         time.Time               `json:"createdAt"`
 	UpdatedAt         time.Time               `json:"updatedAt"`
@@ -154,6 +347,9 @@ type OrderItemResponse struct {
 	UnitPriceNaira float64    `json:"unitPriceNaira"`
 	TotalPrice   int64        `json:"totalPrice"`
 	TotalPriceNaira float64   `json:"totalPriceNaira"`
+	UnitCost     *int64       `json:"unitCost,omitempty"`      // Admin only; absent when no cost was recorded
+	UnitCostNaira float64     `json:"unitCostNaira,omitempty"` // Admin only
+	SubstituteFor *uuid.UUID  `json:"substituteFor,omitempty"` // the out-of-stock product this line replaced
 	Product      *ProductInfo `json:"product,omitempty"`
 	CreatedAt    time.Time    `json:"createdAt"`
 	UpdatedAt    time.Time    `json:"updatedAt"`
@@ -178,6 +374,7 @@ type ProductInfo struct {
 
 type OrderStats struct {
 	TotalOrders       int64   `json:"totalOrders"`
+	ScheduledOrders   int64   `json:"scheduledOrders"`
 	PendingOrders     int64   `json:"pendingOrders"`
 	ConfirmedOrders   int64   `json:"confirmedOrders"`
 	PreparingOrders   int64   `json:"preparingOrders"`
@@ -191,6 +388,27 @@ type OrderStats struct {
 	AverageOrderValueNaira float64 `json:"averageOrderValueNaira"`
 }
 
+// OrderStatsDelta compares a stats window with the prior period of equal length
+type OrderStatsDelta struct {
+	TotalOrders          int64   `json:"totalOrders"`
+	TotalOrdersPct       float64 `json:"totalOrdersPct"`
+	TotalRevenue         int64   `json:"totalRevenue"`
+	TotalRevenuePct      float64 `json:"totalRevenuePct"`
+	AverageOrderValue    int64   `json:"averageOrderValue"`
+	AverageOrderValuePct float64 `json:"averageOrderValuePct"`
+}
+
+// OrderStatsResponse wraps stats for a window with an optional prior-period comparison
+type OrderStatsResponse struct {
+	OrderStats
+	Start         *time.Time       `json:"start,omitempty"`
+	End           *time.Time       `json:"end,omitempty"`
+	PreviousStart *time.Time       `json:"previousStart,omitempty"`
+	PreviousEnd   *time.Time       `json:"previousEnd,omitempty"`
+	Previous      *OrderStats      `json:"previous,omitempty"`
+	Delta         *OrderStatsDelta `json:"delta,omitempty"`
+}
+
 // Validation DTOs
 type ValidateCouponRequest struct {
 	CouponCode    string `json:"couponCode" validate:"required"`
@@ -257,6 +475,9 @@ type CustomRequestQuoteInfo struct {
 	ID            uuid.UUID `json:"id"`
 	ItemsSubtotal int64     `json:"itemsSubtotal"`
 	ItemsSubtotalNaira float64 `json:"itemsSubtotalNaira"`
+	DeliveryFee   int64     `json:"deliveryFee"`
+	ServiceFee    int64     `json:"serviceFee"`
+	PackagingFee  int64     `json:"packagingFee"`
 	GrandTotal    int64     `json:"grandTotal"`
 	GrandTotalNaira float64 `json:"grandTotalNaira"`
 	Status        string    `json:"status"`
@@ -269,6 +490,7 @@ type CreateOrderResponse struct {
 	OrderID     uuid.UUID `json:"order_id"`
 	OrderNumber string    `json:"order_number"`
 	Payment     PaymentInfo `json:"payment"`
+	StockAdjustments []StockShortfall `json:"stockAdjustments,omitempty"` // short items removed or substituted
 }
 
 // PaymentInfo represents payment initialization data
@@ -278,7 +500,45 @@ type PaymentInfo struct {
 	PaymentURL string `json:"payment_url"`
 }
 
-// Helper function to convert kobo to naira (now returns same value in kobo format)
-func KoboToNaira(kobo int64) float64 {
-	return float64(kobo)
+// OrderTrackingResponse is the order detail screen's single view of where an order is: its own
+// status, payment, and the delivery with its driver and timeline once one exists
+type OrderTrackingResponse struct {
+	OrderID       uuid.UUID         `json:"orderId"`
+	OrderNumber   string            `json:"orderNumber"`
+	Status        OrderStatus       `json:"status"`
+	PaymentStatus PaymentStatus     `json:"paymentStatus"`
+	PaymentMethod string            `json:"paymentMethod"`
+	ScheduledFor  *time.Time        `json:"scheduledFor,omitempty"`
+	ETA           *time.Time        `json:"eta"`
+	DeliveredAt   *time.Time        `json:"deliveredAt,omitempty"`
+	ReceivedAt    *time.Time        `json:"receivedAt,omitempty"`
+	Delivery      *DeliveryTracking `json:"delivery"` // nil until a delivery is created for the order
+}
+
+// DeliveryTracking is the customer-safe state of an order's delivery
+type DeliveryTracking struct {
+	TrackingNumber string          `json:"trackingNumber"`
+	Status         string          `json:"status"`
+	DeliveryType   string          `json:"deliveryType"`
+	EstimatedTime  *time.Time      `json:"estimatedTime,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	Driver         *TrackingDriver `json:"driver,omitempty"`
+	Timeline       []TrackingEvent `json:"timeline"`
+}
+
+// TrackingDriver identifies the driver and vehicle to the customer; no contact or licence details
+type TrackingDriver struct {
+	FirstName    string   `json:"firstName"`
+	VehicleType  string   `json:"vehicleType"`
+	VehicleModel string   `json:"vehicleModel"`
+	VehicleColor string   `json:"vehicleColor"`
+	VehiclePlate string   `json:"vehiclePlate"`
+	Rating       *float64 `json:"rating,omitempty"`
+}
+
+// TrackingEvent is one entry in a delivery's timeline
+type TrackingEvent struct {
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
 }
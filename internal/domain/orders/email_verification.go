@@ -0,0 +1,79 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrEmailNotVerified is returned when checkout requires a verified email and the customer has not
+// verified theirs. Browsing and building a cart stay open.
+var ErrEmailNotVerified = errors.New("verify your email first")
+
+// verificationResendCooldown stops repeated checkout attempts from sending a new code every time
+const verificationResendCooldown = 5 * time.Minute
+
+// EmailVerifier reports whether a customer has verified their email and resends the verification code
+type EmailVerifier interface {
+	IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+	SendVerificationEmail(ctx context.Context, userID uuid.UUID) error
+}
+
+// verificationResends remembers when each customer was last sent a code from checkout
+type verificationResends struct {
+	mu     sync.Mutex
+	sentAt map[uuid.UUID]time.Time
+}
+
+// due reports whether a code may be sent to userID at now, and if so records the send
+func (r *verificationResends) due(userID uuid.UUID, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.sentAt[userID]; ok && now.Sub(last) < verificationResendCooldown {
+		return false
+	}
+	for id, at := range r.sentAt {
+		if now.Sub(at) >= verificationResendCooldown {
+			delete(r.sentAt, id)
+		}
+	}
+	r.sentAt[userID] = now
+	return true
+}
+
+// SetEmailVerifier requires customers to verify their email before placing an order when required
+// is true; false or a nil verifier lets unverified customers check out
+func (s *Service) SetEmailVerifier(verifier EmailVerifier, required bool) {
+	s.emailVerifier = verifier
+	s.requireVerifiedEmail = required
+	s.verificationResends = &verificationResends{sentAt: make(map[uuid.UUID]time.Time)}
+}
+
+// checkEmailVerified returns ErrEmailNotVerified for an unverified customer when checkout requires
+// verification, sending them a fresh code unless one went out in the last few minutes
+func (s *Service) checkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	if !s.requireVerifiedEmail || s.emailVerifier == nil {
+		return nil
+	}
+	verified, err := s.emailVerifier.IsEmailVerified(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+	if verified {
+		return nil
+	}
+
+	if !s.verificationResends.due(userID, time.Now()) {
+		return fmt.Errorf("%w: enter the code we sent to your email", ErrEmailNotVerified)
+	}
+	if err := s.emailVerifier.SendVerificationEmail(ctx, userID); err != nil {
+		log.Printf("Failed to resend verification email to %s: %v", userID, err)
+		return fmt.Errorf("%w: request a new code from your account settings", ErrEmailNotVerified)
+	}
+	return fmt.Errorf("%w: we've sent a new verification code to your email", ErrEmailNotVerified)
+}
@@ -0,0 +1,64 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// stubVerifier reports a fixed verification state and counts the codes it sends
+type stubVerifier struct {
+	verified bool
+	sent     int
+}
+
+func (v *stubVerifier) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return v.verified, nil
+}
+
+func (v *stubVerifier) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	v.sent++
+	return nil
+}
+
+func TestCheckEmailVerified(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("verified customer can check out", func(t *testing.T) {
+		verifier := &stubVerifier{verified: true}
+		s := &Service{}
+		s.SetEmailVerifier(verifier, true)
+		if err := s.checkEmailVerified(ctx, userID); err != nil {
+			t.Fatalf("checkEmailVerified = %v, want nil", err)
+		}
+	})
+
+	t.Run("gate off lets unverified customers check out", func(t *testing.T) {
+		verifier := &stubVerifier{}
+		s := &Service{}
+		s.SetEmailVerifier(verifier, false)
+		if err := s.checkEmailVerified(ctx, userID); err != nil {
+			t.Fatalf("checkEmailVerified = %v, want nil", err)
+		}
+		if verifier.sent != 0 {
+			t.Fatalf("sent %d codes with the gate off, want 0", verifier.sent)
+		}
+	})
+
+	t.Run("unverified customer is blocked and sent one code", func(t *testing.T) {
+		verifier := &stubVerifier{}
+		s := &Service{}
+		s.SetEmailVerifier(verifier, true)
+		for i := 0; i < 3; i++ {
+			if err := s.checkEmailVerified(ctx, userID); !errors.Is(err, ErrEmailNotVerified) {
+				t.Fatalf("attempt %d: checkEmailVerified = %v, want ErrEmailNotVerified", i+1, err)
+			}
+		}
+		if verifier.sent != 1 {
+			t.Fatalf("sent %d codes for 3 attempts within the cooldown, want 1", verifier.sent)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package orders
+
+// Fee policy
+//
+// An order can contain catalog items, accepted custom requests, or both. Fees are applied
+// the same way regardless of the mix:
+//
+//   - Catalog items pay a ServiceFeePercent service fee on their subtotal.
+//   - Custom requests are charged exactly as quoted: the quote's items subtotal, service and
+//     packaging fees are added as-is and never re-priced, so a custom-only order costs the
+//     sum of the accepted quotes (with a single quote this is exactly its GrandTotal).
+//   - Delivery is charged per source: the zone fee when catalog items are present, plus each
+//     accepted quote's delivery fee as quoted. Quotes already price delivery for their items,
+//     so a custom-only order never pays the zone fee, and every quote's delivery the
+//     customer accepted is charged.
+//
+// Coupon discounts and tips are applied on top of these figures by the caller, except that a
+// free delivery coupon reduces DeliveryFee itself (see applyCouponDiscount). VAT is charged
+// on the discounted amount (fees minus coupon discount) and never on the driver tip; see
+// CalculateTax. When the store prices tax-inclusive, that amount already contains the VAT, so
+// the tax line is backed out of it (CalculateIncludedTax) instead of being added on top.
+
+// ServiceFeePercent is the service fee charged on catalog items
+const ServiceFeePercent = 5
+
+// QuoteCharges is the part of an accepted custom-request quote that feeds the order totals
+type QuoteCharges struct {
+	ItemsSubtotal int64 // in kobo
+	DeliveryFee   int64 // in kobo
+	ServiceFee    int64 // service + packaging, in kobo
+}
+
+// OrderFeeInput collects everything the fee policy needs to price an order
+type OrderFeeInput struct {
+	CatalogSubtotal int64 // sum of catalog line totals, in kobo
+	ZoneDeliveryFee int64 // delivery fee for the matched zone (or fallback), in kobo
+	Quotes          []QuoteCharges
+}
+
+// OrderFees is the priced breakdown stored on the order
+type OrderFees struct {
+	ItemsSubtotal int64
+	DeliveryFee   int64
+	ServiceFee    int64
+}
+
+// Total is the amount before coupon discounts and tips
+func (f OrderFees) Total() int64 {
+	return f.ItemsSubtotal + f.DeliveryFee + f.ServiceFee
+}
+
+// CalculateOrderFees applies the fee policy above
+func CalculateOrderFees(in OrderFeeInput) OrderFees {
+	fees := OrderFees{
+		ItemsSubtotal: in.CatalogSubtotal,
+		ServiceFee:    in.CatalogSubtotal * ServiceFeePercent / 100,
+	}
+
+	if in.CatalogSubtotal > 0 {
+		fees.DeliveryFee = in.ZoneDeliveryFee
+	}
+
+	for _, q := range in.Quotes {
+		fees.ItemsSubtotal += q.ItemsSubtotal
+		fees.ServiceFee += q.ServiceFee
+		fees.DeliveryFee += q.DeliveryFee
+	}
+
+	return fees
+}
+
+// Tax modes an order can be priced in, as labelled on OrderResponse
+const (
+	TaxModeExclusive = "exclusive" // VAT is added on top of the prices at checkout
+	TaxModeInclusive = "inclusive" // prices already include VAT; the tax line is the part of the total that is VAT
+)
+
+// CalculateTax returns VAT on the taxable amount at rateBasisPoints (750 = 7.5%), rounded to the
+// nearest kobo. A zero rate or a non-positive amount yields no tax.
+func CalculateTax(taxableKobo, rateBasisPoints int64) int64 {
+	if taxableKobo <= 0 || rateBasisPoints <= 0 {
+		return 0
+	}
+	return (taxableKobo*rateBasisPoints + 5000) / 10000
+}
+
+// CalculateIncludedTax returns the VAT contained in a tax-inclusive amount at rateBasisPoints,
+// i.e. amount * rate / (100% + rate), rounded to the nearest kobo. A zero rate or a non-positive
+// amount contains no tax.
+func CalculateIncludedTax(inclusiveKobo, rateBasisPoints int64) int64 {
+	if inclusiveKobo <= 0 || rateBasisPoints <= 0 {
+		return 0
+	}
+	divisor := 10000 + rateBasisPoints
+	return (inclusiveKobo*rateBasisPoints + divisor/2) / divisor
+}
+
+// OrderTax returns the VAT on the discounted fees and how much of it is added to the order total:
+// all of it when prices exclude tax, none when they already include it
+func OrderTax(taxableKobo, rateBasisPoints int64, inclusive bool) (taxKobo, addedKobo int64) {
+	if inclusive {
+		return CalculateIncludedTax(taxableKobo, rateBasisPoints), 0
+	}
+	taxKobo = CalculateTax(taxableKobo, rateBasisPoints)
+	return taxKobo, taxKobo
+}
@@ -0,0 +1,158 @@
+package orders_test
+
+import (
+	"testing"
+
+	orders "errandShop/internal/domain/orders"
+)
+
+func TestCalculateOrderFees(t *testing.T) {
+	tests := []struct {
+		name string
+		in   orders.OrderFeeInput
+		want orders.OrderFees
+	}{
+		{
+			name: "catalog only pays zone delivery and percentage service fee",
+			in: orders.OrderFeeInput{
+				CatalogSubtotal: 1000000,
+				ZoneDeliveryFee: 150000,
+			},
+			want: orders.OrderFees{ItemsSubtotal: 1000000, DeliveryFee: 150000, ServiceFee: 50000},
+		},
+		{
+			name: "custom only is charged exactly as quoted",
+			in: orders.OrderFeeInput{
+				ZoneDeliveryFee: 150000,
+				Quotes: []orders.QuoteCharges{
+					{ItemsSubtotal: 800000, DeliveryFee: 200000, ServiceFee: 30000},
+				},
+			},
+			want: orders.OrderFees{ItemsSubtotal: 800000, DeliveryFee: 200000, ServiceFee: 30000},
+		},
+		{
+			name: "custom only without quoted delivery does not fall back to zone fee",
+			in: orders.OrderFeeInput{
+				ZoneDeliveryFee: 150000,
+				Quotes: []orders.QuoteCharges{
+					{ItemsSubtotal: 500000, ServiceFee: 10000},
+				},
+			},
+			want: orders.OrderFees{ItemsSubtotal: 500000, DeliveryFee: 0, ServiceFee: 10000},
+		},
+		{
+			name: "mixed charges the zone fee plus the quoted delivery",
+			in: orders.OrderFeeInput{
+				CatalogSubtotal: 1000000,
+				ZoneDeliveryFee: 150000,
+				Quotes: []orders.QuoteCharges{
+					{ItemsSubtotal: 800000, DeliveryFee: 100000, ServiceFee: 30000},
+				},
+			},
+			want: orders.OrderFees{ItemsSubtotal: 1800000, DeliveryFee: 250000, ServiceFee: 80000},
+		},
+		{
+			name: "mixed with several quotes charges every quoted delivery",
+			in: orders.OrderFeeInput{
+				CatalogSubtotal: 200000,
+				ZoneDeliveryFee: 150000,
+				Quotes: []orders.QuoteCharges{
+					{ItemsSubtotal: 400000, DeliveryFee: 250000},
+					{ItemsSubtotal: 100000, DeliveryFee: 50000, ServiceFee: 5000},
+				},
+			},
+			want: orders.OrderFees{ItemsSubtotal: 700000, DeliveryFee: 450000, ServiceFee: 15000},
+		},
+		{
+			name: "custom only with several quotes charges every quoted delivery",
+			in: orders.OrderFeeInput{
+				ZoneDeliveryFee: 150000,
+				Quotes: []orders.QuoteCharges{
+					{ItemsSubtotal: 400000, DeliveryFee: 120000, ServiceFee: 8000},
+					{ItemsSubtotal: 300000, DeliveryFee: 90000, ServiceFee: 6000},
+				},
+			},
+			want: orders.OrderFees{ItemsSubtotal: 700000, DeliveryFee: 210000, ServiceFee: 14000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orders.CalculateOrderFees(tt.in)
+			if got != tt.want {
+				t.Fatalf("CalculateOrderFees() = %+v, want %+v", got, tt.want)
+			}
+			if got.Total() != got.ItemsSubtotal+got.DeliveryFee+got.ServiceFee {
+				t.Fatalf("Total() = %d, want sum of breakdown", got.Total())
+			}
+		})
+	}
+}
+
+func TestCalculateOrderFeesSingleQuoteMatchesGrandTotal(t *testing.T) {
+	quote := orders.QuoteCharges{ItemsSubtotal: 1234500, DeliveryFee: 180000, ServiceFee: 45000}
+	grandTotal := quote.ItemsSubtotal + quote.DeliveryFee + quote.ServiceFee
+
+	got := orders.CalculateOrderFees(orders.OrderFeeInput{
+		ZoneDeliveryFee: 999999,
+		Quotes:          []orders.QuoteCharges{quote},
+	})
+	if got.Total() != grandTotal {
+		t.Fatalf("custom-only total = %d, want quote grand total %d", got.Total(), grandTotal)
+	}
+}
+
+func TestCalculateTax(t *testing.T) {
+	tests := []struct {
+		name    string
+		taxable int64
+		rate    int64
+		want    int64
+	}{
+		{name: "zero rate charges nothing", taxable: 1000000, rate: 0, want: 0},
+		{name: "7.5 percent", taxable: 1000000, rate: 750, want: 75000},
+		{name: "rounds to the nearest kobo", taxable: 333, rate: 750, want: 25},
+		{name: "non-positive amount charges nothing", taxable: -5000, rate: 750, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orders.CalculateTax(tt.taxable, tt.rate); got != tt.want {
+				t.Fatalf("CalculateTax(%d, %d) = %d, want %d", tt.taxable, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateIncludedTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		inclusive int64
+		rate      int64
+		want      int64
+	}{
+		{name: "zero rate contains nothing", inclusive: 1075000, rate: 0, want: 0},
+		{name: "backs 7.5 percent out of the inclusive amount", inclusive: 1075000, rate: 750, want: 75000},
+		{name: "rounds to the nearest kobo", inclusive: 1000, rate: 750, want: 70},
+		{name: "non-positive amount contains nothing", inclusive: -5000, rate: 750, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orders.CalculateIncludedTax(tt.inclusive, tt.rate); got != tt.want {
+				t.Fatalf("CalculateIncludedTax(%d, %d) = %d, want %d", tt.inclusive, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderTotalByTaxMode(t *testing.T) {
+	order := orders.Order{ItemsSubtotal: 1000000, DeliveryFee: 75000, TaxKobo: 75000, TipKobo: 20000}
+	if got := order.CalculateTotal(); got != 1170000 || order.TaxMode() != orders.TaxModeExclusive {
+		t.Fatalf("exclusive: total = %d, mode = %s; want VAT added on top", got, order.TaxMode())
+	}
+	order.TaxInclusive = true
+	if got := order.CalculateTotal(); got != 1095000 || order.TaxMode() != orders.TaxModeInclusive {
+		t.Fatalf("inclusive: total = %d, mode = %s; want VAT already in the prices", got, order.TaxMode())
+	}
+}
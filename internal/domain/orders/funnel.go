@@ -0,0 +1,32 @@
+package orders
+
+import "github.com/google/uuid"
+
+// FunnelRecorder is told as carts and orders move from shopping to delivery, for the conversion
+// funnel report. Calls happen after the change is saved and must not block for long.
+type FunnelRecorder interface {
+	CartStarted(userID uuid.UUID)
+	OrderPlaced(userID, orderID uuid.UUID)
+	OrderPaid(orderID uuid.UUID)
+	OrderDelivered(orderID uuid.UUID)
+}
+
+// SetFunnelRecorder enables funnel tracking for orders and carts; nil disables it
+func (s *Service) SetFunnelRecorder(recorder FunnelRecorder) {
+	s.funnel = recorder
+	s.cartService.funnel = recorder
+}
+
+// orderDelivered runs after an order is marked delivered
+func (s *Service) orderDelivered(orderID uuid.UUID) {
+	if s.funnel != nil {
+		s.funnel.OrderDelivered(orderID)
+	}
+}
+
+// cartStarted records a cart that was empty before this change and has items after it
+func (s *CartService) cartStarted(userID uuid.UUID, wasEmpty bool, cart *Cart) {
+	if s.funnel != nil && wasEmpty && len(cart.Items) > 0 {
+		s.funnel.CartStarted(userID)
+	}
+}
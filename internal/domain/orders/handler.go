@@ -5,7 +5,9 @@ import (
     "fmt"
     "log"
     "strings"
+    "time"
 
+    "errandShop/internal/domain/products"
     "github.com/go-playground/validator/v10"
     "github.com/gofiber/fiber/v2"
     "github.com/google/uuid"
@@ -37,6 +39,20 @@ func (h *Handler) errorResponse(c *fiber.Ctx, statusCode int, message string, er
 	})
 }
 
+// stockShortfallResponse lists the items an order is short of so the customer can retry with
+// stockShortfall "remove" or "substitute"
+func (h *Handler) stockShortfallResponse(c *fiber.Ctx, shortfall *StockShortfallError) error {
+	h.logger.Printf("Error: %v", shortfall)
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error":   true,
+		"message": "Insufficient stock for one or more items",
+		"data": fiber.Map{
+			"shortfalls": shortfall.Items,
+			"options":    []string{ShortfallRemove, ShortfallSubstitute},
+		},
+	})
+}
+
 func (h *Handler) successResponse(c *fiber.Ctx, data interface{}, message string) error {
 	response := fiber.Map{
 		"error":   false,
@@ -128,6 +144,56 @@ func (h *Handler) Get(c *fiber.Ctx) error {
 	return h.successResponse(c, order, "Order retrieved successfully")
 }
 
+// Invoice returns the order as a PDF invoice, for the order's owner or an admin
+func (h *Handler) Invoice(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+
+	role, _ := c.Locals("role").(string)
+	pdf, filename, err := h.svc.Invoice(c.Context(), id, userID, role == "admin" || role == "superadmin")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to generate invoice", err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+	return c.Send(pdf)
+}
+
+// Tracking returns the order, payment and delivery status in one response (owner or admin)
+func (h *Handler) Tracking(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+
+	role, _ := c.Locals("role").(string)
+	tracking, err := h.svc.Tracking(c.Context(), id, userID, role == "admin" || role == "superadmin")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get order tracking", err)
+	}
+
+	return h.successResponse(c, tracking, "Order tracking retrieved successfully")
+}
+
 func (h *Handler) Create(c *fiber.Ctx) error {
 	userID, err := h.getUserID(c)
 	if err != nil {
@@ -145,8 +211,25 @@ func (h *Handler) Create(c *fiber.Ctx) error {
 
 	order, err := h.svc.CreateWithPayment(c.Context(), userID, req)
 	if err != nil {
-		if err.Error() == "insufficient stock" {
-			return h.errorResponse(c, fiber.StatusBadRequest, "Insufficient stock for one or more items", err)
+		if errors.Is(err, ErrIdempotencyKeyReused) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrIdempotencyKeyReused.Error(), err)
+		}
+		if errors.Is(err, ErrDeliveryAddressForbidden) {
+			return h.errorResponse(c, fiber.StatusForbidden, ErrDeliveryAddressForbidden.Error(), err)
+		}
+		if errors.Is(err, ErrEmailNotVerified) {
+			return h.errorResponse(c, fiber.StatusForbidden, err.Error(), err)
+		}
+		if errors.Is(err, ErrInvalidSchedule) || errors.Is(err, products.ErrOrderQuantityLimit) || errors.Is(err, products.ErrProductUnavailable) ||
+			errors.Is(err, ErrInvalidDeliveryAddress) {
+			return h.errorResponse(c, fiber.StatusBadRequest, strings.TrimPrefix(err.Error(), "failed to create order: "), err)
+		}
+		var shortfall *StockShortfallError
+		if errors.As(err, &shortfall) {
+			return h.stockShortfallResponse(c, shortfall)
+		}
+		if strings.HasPrefix(err.Error(), "failed to create order: minimum order") || strings.HasPrefix(err.Error(), "failed to create order: invalid tip") {
+			return h.errorResponse(c, fiber.StatusBadRequest, strings.TrimPrefix(err.Error(), "failed to create order: "), err)
 		}
 		// Map expired custom request error to 400 to support user-facing popup
 		if strings.Contains(err.Error(), "custom request") && strings.Contains(err.Error(), "has expired") {
@@ -182,8 +265,25 @@ func (h *Handler) CreateFromCart(c *fiber.Ctx) error {
 
 	order, err := h.svc.CreateFromCart(c.Context(), userID, req)
 	if err != nil {
-		if err.Error() == "insufficient stock" {
-			return h.errorResponse(c, fiber.StatusBadRequest, "Insufficient stock for one or more items", err)
+		if errors.Is(err, ErrIdempotencyKeyReused) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrIdempotencyKeyReused.Error(), err)
+		}
+		if errors.Is(err, ErrDeliveryAddressForbidden) {
+			return h.errorResponse(c, fiber.StatusForbidden, ErrDeliveryAddressForbidden.Error(), err)
+		}
+		if errors.Is(err, ErrEmailNotVerified) {
+			return h.errorResponse(c, fiber.StatusForbidden, err.Error(), err)
+		}
+		if errors.Is(err, ErrInvalidSchedule) || errors.Is(err, products.ErrOrderQuantityLimit) || errors.Is(err, products.ErrProductUnavailable) ||
+			errors.Is(err, ErrInvalidDeliveryAddress) {
+			return h.errorResponse(c, fiber.StatusBadRequest, strings.TrimPrefix(err.Error(), "failed to create order: "), err)
+		}
+		var shortfall *StockShortfallError
+		if errors.As(err, &shortfall) {
+			return h.stockShortfallResponse(c, shortfall)
+		}
+		if strings.HasPrefix(err.Error(), "minimum order") || strings.HasPrefix(err.Error(), "invalid tip") {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
 		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to create order from cart", err)
 	}
@@ -217,11 +317,13 @@ func (h *Handler) UpdateStatus(c *fiber.Ctx) error {
 	}
 
 	if err := h.svc.UpdateStatus(c.Context(), id, userID, req.Status); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "order not found" {
 			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
 		}
-		if err.Error() == "customers can only cancel orders" ||
-			err.Error() == "can only cancel pending orders" {
+		if errors.Is(err, ErrCancelRequiresSupport) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrCancelRequiresSupport.Error(), err)
+		}
+		if errors.Is(err, ErrCustomerStatusChange) || strings.HasPrefix(err.Error(), "order cannot be cancelled") {
 			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
 		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to update order status", err)
@@ -230,6 +332,92 @@ func (h *Handler) UpdateStatus(c *fiber.Ctx) error {
 	return h.successResponse(c, nil, "Order status updated successfully")
 }
 
+// UpdateItems edits the items of a pending, unpaid order
+func (h *Handler) UpdateItems(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+
+	var req UpdateOrderItemsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
+	}
+
+	order, err := h.svc.UpdateItems(c.Context(), id, userID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "order not found":
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		case errors.Is(err, ErrOrderNotEditable):
+			return h.errorResponse(c, fiber.StatusConflict, ErrOrderNotEditable.Error(), err)
+		case errors.Is(err, ErrDeliveryAddressForbidden):
+			return h.errorResponse(c, fiber.StatusForbidden, ErrDeliveryAddressForbidden.Error(), err)
+		case errors.Is(err, ErrInvalidDeliveryAddress), errors.Is(err, products.ErrOrderQuantityLimit),
+			errors.Is(err, products.ErrProductUnavailable):
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		case strings.HasPrefix(err.Error(), "insufficient stock"),
+			strings.HasPrefix(err.Error(), "invalid coupon"),
+			strings.HasPrefix(err.Error(), "minimum order"),
+			strings.HasPrefix(err.Error(), "order must contain"),
+			strings.Contains(err.Error(), "not found"):
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to update order items", err)
+	}
+
+	return h.successResponse(c, order, "Order updated successfully")
+}
+
+// RemoveItem removes a single line item from the caller's pending, unpaid order
+// DELETE /api/v1/orders/:id/items/:itemId
+func (h *Handler) RemoveItem(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+	itemID, err := uuid.Parse(c.Params("itemId"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid item ID", err)
+	}
+
+	order, err := h.svc.RemoveItem(c.Context(), id, userID, itemID)
+	if err != nil {
+		switch {
+		case err.Error() == "order not found":
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		case errors.Is(err, ErrOrderItemNotFound):
+			return h.errorResponse(c, fiber.StatusNotFound, ErrOrderItemNotFound.Error(), err)
+		case errors.Is(err, ErrOrderNotEditable):
+			return h.errorResponse(c, fiber.StatusConflict, ErrOrderNotEditable.Error(), err)
+		case errors.Is(err, products.ErrOrderQuantityLimit),
+			strings.HasPrefix(err.Error(), "invalid coupon"),
+			strings.HasPrefix(err.Error(), "minimum order"):
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to remove order item", err)
+	}
+
+	if order.Status == OrderStatusCancelled {
+		return h.successResponse(c, order, "Last item removed; order cancelled")
+	}
+	return h.successResponse(c, order, "Item removed successfully")
+}
+
 // CancelOrder cancels an order
 func (h *Handler) CancelOrder(c *fiber.Ctx) error {
 	userID, err := h.getUserID(c)
@@ -252,15 +440,71 @@ func (h *Handler) CancelOrder(c *fiber.Ctx) error {
 		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
 	}
 
-	err = h.svc.CancelOrder(c.Context(), id, userID, req.Reason)
+	refund, err := h.svc.CancelOrder(c.Context(), id, userID, req.Reason, req.RefundMethod)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "order not found" {
 			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
 		}
+		if errors.Is(err, ErrCancelRequiresSupport) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrCancelRequiresSupport.Error(), err)
+		}
+		if strings.HasPrefix(err.Error(), "order cannot be cancelled") {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to cancel order", err)
 	}
 
-	return h.successResponse(c, nil, "Order cancelled successfully")
+	return h.successResponse(c, fiber.Map{"refund": refund}, "Order cancelled successfully")
+}
+
+// ConfirmReceived lets the customer acknowledge receipt of a delivered order
+func (h *Handler) ConfirmReceived(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+
+	order, err := h.svc.ConfirmReceived(c.Context(), id, userID)
+	if err != nil {
+		switch {
+		case err.Error() == "order not found":
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		case errors.Is(err, ErrOrderNotDelivered), errors.Is(err, ErrReceiptAlreadyConfirmed):
+			return h.errorResponse(c, fiber.StatusConflict, err.Error(), err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to confirm receipt", err)
+	}
+
+	return h.successResponse(c, order, "Order receipt confirmed")
+}
+
+// Reorder copies the available items of a past order into the user's cart
+func (h *Handler) Reorder(c *fiber.Ctx) error {
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+
+	result, err := h.svc.Reorder(c.Context(), id, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "order not found") {
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to reorder", err)
+	}
+
+	return h.successResponse(c, result, "Order items added to cart")
 }
 
 // Admin endpoints
@@ -328,13 +572,34 @@ func (h *Handler) AdminUpdateStatus(c *fiber.Ctx) error {
 		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
 	}
 
-	if err := h.svc.AdminUpdateStatus(c.Context(), id, req.Status); err != nil {
+	if err := h.svc.AdminUpdateStatus(c.Context(), id, req.Status, req.Version); err != nil {
+		if errors.Is(err, ErrOrderVersionConflict) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrOrderVersionConflict.Error(), err)
+		}
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to update order status", err)
 	}
 
 	return h.successResponse(c, nil, "Order status updated successfully")
 }
 
+// AdminBulkUpdateStatus moves several orders to one status, skipping those that cannot make the transition
+func (h *Handler) AdminBulkUpdateStatus(c *fiber.Ctx) error {
+	var req BulkUpdateOrderStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
+	}
+
+	result := h.svc.AdminBulkUpdateStatus(c.Context(), req.OrderIDs, req.Status)
+	return h.successResponse(c, result, fmt.Sprintf("%d orders updated, %d skipped", result.Updated, result.Skipped))
+}
+
 func (h *Handler) AdminUpdatePaymentStatus(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
@@ -351,7 +616,13 @@ func (h *Handler) AdminUpdatePaymentStatus(c *fiber.Ctx) error {
 		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
 	}
 
-	if err := h.svc.AdminUpdatePaymentStatus(c.Context(), id, req.PaymentStatus); err != nil {
+	if err := h.svc.AdminSetPaymentStatus(c.Context(), id, req.PaymentStatus, req.Version); err != nil {
+		if errors.Is(err, ErrOrderVersionConflict) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrOrderVersionConflict.Error(), err)
+		}
+		if errors.Is(err, ErrCancelledOrderPayment) {
+			return h.errorResponse(c, fiber.StatusConflict, ErrCancelledOrderPayment.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to update payment status", err)
 	}
 
@@ -376,7 +647,7 @@ func (h *Handler) AdminCancelOrder(c *fiber.Ctx) error {
 		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
 	}
 
-	err = h.svc.AdminCancelOrder(c.Context(), id, req.Reason)
+	refund, err := h.svc.AdminCancelOrder(c.Context(), id, req.Reason, req.RefundMethod)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
@@ -384,14 +655,114 @@ func (h *Handler) AdminCancelOrder(c *fiber.Ctx) error {
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to cancel order", err)
 	}
 
-	return h.successResponse(c, nil, "Order cancelled successfully")
+	return h.successResponse(c, fiber.Map{"refund": refund}, "Order cancelled successfully")
+}
+
+// AdminAdjustOrder applies a manual adjustment (e.g. goodwill credit) to an order total
+func (h *Handler) AdminAdjustOrder(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+	adminID, err := h.getUserID(c)
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusUnauthorized, "Authentication required", err)
+	}
+
+	var req AdjustOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := validate.Struct(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
+	}
+
+	res, err := h.svc.AdminAdjustOrder(c.Context(), id, adminID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		case errors.Is(err, ErrInvalidAdjustment), errors.Is(err, ErrAdjustmentRefundUnavailable), errors.Is(err, ErrRefundUnavailable):
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to adjust order", err)
+	}
+
+	return h.successResponse(c, res, "Order adjusted successfully")
 }
 
+// AdminRefundOrder refunds a cancelled, paid order by the chosen method, e.g. when the refund
+// made on cancellation failed
+func (h *Handler) AdminRefundOrder(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid order ID", err)
+	}
+
+	var req RefundOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := validate.Struct(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
+	}
+
+	refund, err := h.svc.RefundCancelledOrder(c.Context(), id, req.Method, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return h.errorResponse(c, fiber.StatusNotFound, "Order not found", err)
+		case errors.Is(err, ErrRefundUnavailable):
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to refund order", err)
+	}
+
+	return h.successResponse(c, refund, "Order refunded successfully")
+}
+
+// GetStats returns order statistics, optionally for a start/end window
+// (RFC3339 or YYYY-MM-DD; a date-only end covers the whole day)
 func (h *Handler) GetStats(c *fiber.Ctx) error {
-	stats, err := h.svc.GetStats(c.Context())
+	var query OrderStatsQuery
+
+	if start := c.Query("start"); start != "" {
+		t, _, err := parseStatsDate(start)
+		if err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid start date", err)
+		}
+		query.DateFrom = &t
+	}
+	if end := c.Query("end"); end != "" {
+		t, dateOnly, err := parseStatsDate(end)
+		if err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid end date", err)
+		}
+		if dateOnly {
+			t = t.Add(24*time.Hour - time.Microsecond)
+		}
+		query.DateTo = &t
+	}
+
+	stats, err := h.svc.GetStats(c.Context(), query)
 	if err != nil {
+		if strings.Contains(err.Error(), "end date must be after start date") {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to fetch order statistics", err)
 	}
 
 	return h.successResponse(c, stats, "Order statistics retrieved successfully")
 }
+
+// parseStatsDate accepts RFC3339 timestamps or plain YYYY-MM-DD dates
+func parseStatsDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
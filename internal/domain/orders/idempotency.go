@@ -0,0 +1,91 @@
+package orders
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultIdempotencyTTL is how long an idempotency key keeps returning the order it created
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// ErrIdempotencyKeyReused is returned when a live idempotency key is sent with a different order payload
+var ErrIdempotencyKeyReused = errors.New("idempotency key has already been used for a different order")
+
+// DefaultDuplicateOrderWindow is how long an order placed without an idempotency key is returned
+// for an identical resubmission, e.g. a double tap on "Place order"
+const DefaultDuplicateOrderWindow = 30 * time.Second
+
+// duplicateGuardKeyPrefix marks idempotency keys the server derived for orders sent without one
+const duplicateGuardKeyPrefix = "auto:"
+
+// duplicateGuardKey is the idempotency key given to an order placed without one: its fingerprint,
+// so an identical order from the same customer maps to the same key. The fingerprint covers the
+// items, custom requests, coupon, address, tip and schedule, which decide the total.
+func duplicateGuardKey(fingerprint string) string {
+	return duplicateGuardKeyPrefix + fingerprint
+}
+
+// isDuplicateKeyError reports whether an insert hit a unique index
+func isDuplicateKeyError(err error) bool {
+	return err != nil && (errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint failed"))
+}
+
+// idempotencyFingerprint hashes the parts of an order request that decide what is bought and
+// what it costs, so a retry can be told apart from a new order that reuses the key. Line order
+// and duplicate lines for the same product do not change the fingerprint.
+func idempotencyFingerprint(req CreateOrderRequest) string {
+	quantities := make(map[string]int)
+	for _, item := range req.Items {
+		quantities[item.ProductID.String()] += item.Quantity
+	}
+	items := make([]string, 0, len(quantities))
+	for productID, quantity := range quantities {
+		items = append(items, fmt.Sprintf("%s:%d", productID, quantity))
+	}
+	sort.Strings(items)
+
+	customRequests := make([]string, len(req.CustomRequests))
+	for i, cr := range req.CustomRequests {
+		customRequests[i] = cr.CustomRequestID.String()
+	}
+	sort.Strings(customRequests)
+
+	coupon := ""
+	if req.CouponCode != nil {
+		coupon = strings.ToUpper(strings.TrimSpace(*req.CouponCode))
+	}
+	address := ""
+	if req.DeliveryAddressID != nil {
+		address = strings.TrimSpace(*req.DeliveryAddressID)
+	}
+
+	payload := strings.Join([]string{
+		strings.Join(items, ","),
+		strings.Join(customRequests, ","),
+		coupon,
+		fmt.Sprintf("%t", req.AutoApplyCoupon),
+		address,
+		fmt.Sprintf("%d", req.TipKobo),
+	}, "|")
+	if req.ScheduledFor != nil {
+		payload += "|" + req.ScheduledFor.UTC().Format(time.RFC3339)
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// logDuplicateOrderMerged records that a keyless resubmission was answered with an existing order,
+// so the number of double taps caught can be measured from the logs
+func logDuplicateOrderMerged(userID uuid.UUID, existing *Order) {
+	log.Printf("duplicate_order_merged: customer %s resubmitted order %s without an idempotency key %s after placing it",
+		userID, existing.ID, time.Since(existing.CreatedAt).Round(time.Millisecond))
+}
@@ -0,0 +1,53 @@
+package orders
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDuplicateGuardKeyCatchesDoubleTap(t *testing.T) {
+	productID := uuid.New()
+	first := CreateOrderRequest{Items: []CreateOrderItemRequest{{ProductID: productID, Quantity: 2}}, TipKobo: 20000}
+	tap := CreateOrderRequest{Items: []CreateOrderItemRequest{{ProductID: productID, Quantity: 1}, {ProductID: productID, Quantity: 1}}, TipKobo: 20000}
+	changed := CreateOrderRequest{Items: []CreateOrderItemRequest{{ProductID: productID, Quantity: 3}}, TipKobo: 20000}
+
+	key := duplicateGuardKey(idempotencyFingerprint(first))
+	if got := duplicateGuardKey(idempotencyFingerprint(tap)); got != key {
+		t.Fatalf("an identical resubmission should get the same key: %q vs %q", got, key)
+	}
+	if got := duplicateGuardKey(idempotencyFingerprint(changed)); got == key {
+		t.Fatalf("a different basket must not be merged with the first order")
+	}
+
+	// Two taps racing past the lookup are stopped by the per-customer unique index
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, idempotency_key TEXT)`,
+		`CREATE UNIQUE INDEX idx_orders_customer_idempotency_key ON orders (customer_id, idempotency_key) WHERE idempotency_key IS NOT NULL AND idempotency_key <> ''`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	customerID := uuid.New()
+	insert := func(key string) error {
+		return db.Exec(`INSERT INTO orders (id, customer_id, idempotency_key) VALUES (?, ?, ?)`, uuid.New(), customerID, key).Error
+	}
+	if err := insert(key); err != nil {
+		t.Fatalf("first order failed: %v", err)
+	}
+	if err := insert(key); !isDuplicateKeyError(err) {
+		t.Fatalf("second tap: err = %v, want a duplicate key error", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := insert(""); err != nil {
+			t.Fatalf("orders without a key must not collide: %v", err)
+		}
+	}
+}
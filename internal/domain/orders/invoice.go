@@ -0,0 +1,187 @@
+package orders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"errandShop/pkg/money"
+	"github.com/go-pdf/fpdf"
+	"github.com/google/uuid"
+)
+
+// orderNumber is the short, human-readable number shown to customers for an order
+func orderNumber(id uuid.UUID) string {
+	return fmt.Sprintf("ORD-%06d", id.ID()%1000000)
+}
+
+// Invoice renders an order as a PDF invoice and returns it with a download file name.
+// Customers can only fetch their own orders; admins (asAdmin) can fetch any order.
+func (s *Service) Invoice(ctx context.Context, id, userID uuid.UUID, asAdmin bool) ([]byte, string, error) {
+	var order *Order
+	var err error
+	if asAdmin {
+		order, err = s.repo.AdminGet(ctx, id)
+	} else {
+		order, err = s.repo.Get(ctx, id, userID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	number := orderNumber(order.ID)
+	pdf, err := RenderInvoicePDF(s.toOrderResponseWithContext(ctx, order), number, time.Now())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render invoice: %w", err)
+	}
+	return pdf, fmt.Sprintf("invoice-%s.pdf", number), nil
+}
+
+// RenderInvoicePDF lays out an order as a one-column A4 invoice: header, billing details,
+// line items, then the fee breakdown and total. Amounts are printed in naira as "NGN"
+// because the PDF core fonts have no naira sign.
+func RenderInvoicePDF(order *OrderResponse, number string, generatedAt time.Time) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Invoice "+number, false)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "Errand Shop - Invoice", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "Order number: "+number, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Order ID: "+order.ID.String(), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Order date: "+order.CreatedAt.Format("02 Jan 2006 15:04 MST"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Generated at: "+generatedAt.Format("02 Jan 2006 15:04 MST"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Payment: %s (%s)", order.PaymentStatus, order.PaymentMethod), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Billing details
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Billed to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range invoiceBillingLines(order) {
+		pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Line items
+	widths := []float64{95, 20, 32.5, 32.5}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	for i, heading := range []string{"Item", "Qty", "Unit price", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, heading, "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range order.Items {
+		pdf.CellFormat(widths[0], 6, tr(truncate(item.Name, 55)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, fmt.Sprintf("%d", item.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, invoiceAmount(item.UnitPrice), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, invoiceAmount(item.TotalPrice), "", 1, "R", false, 0, "")
+	}
+	for _, cr := range order.CustomRequestDetails {
+		if cr.ActiveQuote == nil {
+			continue
+		}
+		label := fmt.Sprintf("Custom request %s (%d items)", strings.ToUpper(cr.ID.String()[:8]), len(cr.Items))
+		pdf.CellFormat(widths[0], 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, "1", "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, invoiceAmount(cr.ActiveQuote.ItemsSubtotal), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, invoiceAmount(cr.ActiveQuote.ItemsSubtotal), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	// Totals
+	deliveryLabel := "Delivery fee"
+	if order.DeliveryDiscount > 0 {
+		deliveryLabel += " (less " + invoiceAmount(order.DeliveryDiscount) + " free delivery)"
+	}
+	summary := [][2]string{
+		{"Items subtotal", invoiceAmount(order.ItemsSubtotal)},
+		{deliveryLabel, invoiceAmount(order.DeliveryFee)},
+		{"Service fee", invoiceAmount(order.ServiceFee)},
+	}
+	if order.CouponDiscount > 0 {
+		label := "Discount"
+		if order.CouponCode != nil && *order.CouponCode != "" {
+			label += " (" + *order.CouponCode + ")"
+		}
+		summary = append(summary, [2]string{label, "-" + invoiceAmount(order.CouponDiscount)})
+	}
+	if order.TaxMode == TaxModeInclusive {
+		summary = append(summary, [2]string{"VAT (included in prices)", invoiceAmount(order.TaxKobo)})
+	} else {
+		summary = append(summary, [2]string{"VAT", invoiceAmount(order.TaxKobo)})
+	}
+	if order.AdjustmentKobo != 0 {
+		amount := invoiceAmount(order.AdjustmentKobo)
+		if order.AdjustmentKobo < 0 {
+			amount = "-" + invoiceAmount(-order.AdjustmentKobo)
+		}
+		summary = append(summary, [2]string{"Adjustment", amount})
+	}
+	if order.TipKobo > 0 {
+		summary = append(summary, [2]string{"Driver tip", invoiceAmount(order.TipKobo)})
+	}
+	for _, row := range summary {
+		pdf.CellFormat(widths[0]+widths[1]+widths[2], 6, tr(row[0]), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, row[1], "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 8, "Total", "T", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 8, invoiceAmount(order.TotalAmount), "T", 1, "R", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// invoiceBillingLines lists the customer and delivery details printed under "Billed to"
+func invoiceBillingLines(order *OrderResponse) []string {
+	var lines []string
+	if c := order.Customer; c != nil {
+		lines = append(lines, strings.TrimSpace(c.FirstName+" "+c.LastName), c.Email, c.Phone)
+	}
+	if a := order.DeliveryAddress; a != nil {
+		lines = append(lines, a.Street)
+		lines = append(lines, strings.Join(nonEmpty(a.City, a.State, a.PostalCode, a.Country), ", "))
+	}
+	if order.RecipientName != "" {
+		lines = append(lines, fmt.Sprintf("Recipient: %s %s", order.RecipientName, order.RecipientPhone))
+	}
+	return nonEmpty(lines...)
+}
+
+// invoiceAmount formats kobo as naira for the invoice
+func invoiceAmount(kobo int64) string {
+	return fmt.Sprintf("NGN %.2f", money.Money(kobo).Naira())
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-3]) + "..."
+}
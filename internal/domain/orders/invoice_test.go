@@ -0,0 +1,40 @@
+package orders_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	orders "errandShop/internal/domain/orders"
+	"github.com/google/uuid"
+)
+
+func TestRenderInvoicePDF(t *testing.T) {
+	coupon := "WELCOME"
+	order := &orders.OrderResponse{
+		ID:              uuid.New(),
+		PaymentStatus:   orders.PaymentStatusPaid,
+		PaymentMethod:   "paystack",
+		CouponCode:      &coupon,
+		CouponDiscount:  10000,
+		ItemsSubtotal:   200000,
+		DeliveryFee:     150000,
+		ServiceFee:      10000,
+		TaxKobo:         26250,
+		TotalAmount:     376250,
+		Customer:        &orders.CustomerInfo{FirstName: "Ada", LastName: "Obi", Email: "ada@example.com"},
+		DeliveryAddress: &orders.AddressInfo{Street: "12 Allen Avenue", City: "Ikeja", State: "Lagos"},
+		Items: []orders.OrderItemResponse{
+			{Name: "Golden Penny Semovita 1kg", Quantity: 2, UnitPrice: 100000, TotalPrice: 200000},
+		},
+		CreatedAt: time.Now(),
+	}
+
+	pdf, err := orders.RenderInvoicePDF(order, "ORD-000123", time.Now())
+	if err != nil {
+		t.Fatalf("RenderInvoicePDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("RenderInvoicePDF() did not produce a PDF document")
+	}
+}
@@ -0,0 +1,132 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"errandShop/internal/domain/payments"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeRefunder struct {
+	refunds []payments.RefundPaymentRequest
+}
+
+func (f *fakeRefunder) GetOrderPayments(orderID string) ([]payments.PaymentResponse, error) {
+	return []payments.PaymentResponse{{ID: "pay_1", OrderID: orderID, AmountKobo: 500000, Status: payments.PaymentStatusCompleted}}, nil
+}
+
+func (f *fakeRefunder) InitiateRefund(req payments.RefundPaymentRequest) (*payments.RefundResponse, error) {
+	f.refunds = append(f.refunds, req)
+	return &payments.RefundResponse{ID: "rf_1", PaymentID: req.PaymentID, AmountKobo: req.AmountKobo}, nil
+}
+
+func TestPaymentOnExpiredOrderIsRefundedNotApplied(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (
+			id TEXT PRIMARY KEY,
+			customer_id TEXT NOT NULL,
+			status TEXT,
+			payment_status TEXT,
+			total_amount INTEGER NOT NULL DEFAULT 0,
+			refunded_kobo INTEGER NOT NULL DEFAULT 0,
+			refund_method TEXT,
+			refund_reference TEXT,
+			paid_at DATETIME,
+			version INTEGER NOT NULL DEFAULT 1,
+			custom_requests TEXT DEFAULT '[]',
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY, order_id TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	orderID := uuid.New()
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status, payment_status, total_amount) VALUES (?, ?, ?, ?, 500000)`,
+		orderID, uuid.New(), OrderStatusCancelled, PaymentStatusExpired).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+
+	refunder := &fakeRefunder{}
+	s := &Service{db: db, repo: NewRepository(db), refunder: refunder}
+	ctx := context.Background()
+
+	// Paystack can deliver the same charge more than once; it is refunded once
+	for i := 0; i < 2; i++ {
+		if err := s.AdminUpdatePaymentStatus(ctx, orderID, PaymentStatusPaid); err != nil {
+			t.Fatalf("AdminUpdatePaymentStatus: %v", err)
+		}
+	}
+
+	var got Order
+	if err := db.First(&got, "id = ?", orderID).Error; err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+	if got.Status != OrderStatusCancelled || got.PaymentStatus != PaymentStatusRefunded {
+		t.Fatalf("order is %s/%s, want cancelled/refunded", got.Status, got.PaymentStatus)
+	}
+	if got.PaidAt != nil {
+		t.Fatalf("paid_at = %v, want a cancelled order never marked paid", got.PaidAt)
+	}
+	if len(refunder.refunds) != 1 || refunder.refunds[0].AmountKobo != 500000 {
+		t.Fatalf("refunds = %+v, want one full refund", refunder.refunds)
+	}
+	if got.RefundedKobo != 500000 || got.RefundReference == nil || *got.RefundReference != "rf_1" {
+		t.Fatalf("refunded %d (ref %v), want 500000 against rf_1", got.RefundedKobo, got.RefundReference)
+	}
+}
+
+func TestAdminCannotMarkCancelledOrderPaid(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT, payment_status TEXT, paid_at DATETIME, version INTEGER NOT NULL DEFAULT 1, custom_requests TEXT DEFAULT '[]', created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY, order_id TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	cancelled := uuid.New()
+	confirmed := uuid.New()
+	for id, status := range map[uuid.UUID]OrderStatus{cancelled: OrderStatusCancelled, confirmed: OrderStatusConfirmed} {
+		if err := db.Exec(`INSERT INTO orders (id, customer_id, status, payment_status) VALUES (?, ?, ?, ?)`,
+			id, uuid.New(), status, PaymentStatusUnpaid).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+	}
+	s := &Service{db: db, repo: NewRepository(db)}
+	ctx := context.Background()
+
+	if err := s.AdminSetPaymentStatus(ctx, cancelled, PaymentStatusPaid, nil); !errors.Is(err, ErrCancelledOrderPayment) {
+		t.Fatalf("marking a cancelled order paid: err = %v, want ErrCancelledOrderPayment", err)
+	}
+	if err := s.AdminSetPaymentStatus(ctx, confirmed, PaymentStatusPaid, nil); err != nil {
+		t.Fatalf("marking a confirmed order paid: %v", err)
+	}
+
+	for id, want := range map[uuid.UUID]PaymentStatus{cancelled: PaymentStatusUnpaid, confirmed: PaymentStatusPaid} {
+		var got PaymentStatus
+		db.Raw(`SELECT payment_status FROM orders WHERE id = ?`, id).Scan(&got)
+		if got != want {
+			t.Errorf("order %s payment status = %s, want %s", id, got, want)
+		}
+	}
+}
@@ -11,11 +11,13 @@ import (
 
 // Cart represents a user's shopping cart
 type Cart struct {
-	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"userId"`
-	Items     []CartItem `gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE" json:"items"`
-	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
-	UpdatedAt time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"userId"`
+	Items          []CartItem `gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE" json:"items"`
+	LastReminderAt *time.Time `gorm:"column:last_reminder_at" json:"lastReminderAt,omitempty"`
+	ReminderCount  int        `gorm:"column:reminder_count;not null;default:0" json:"-"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
 }
 
 // CartItem represents an item in a user's cart
@@ -34,43 +36,71 @@ type CartItem struct {
 
 // Order represents a customer order
 type Order struct {
-	ID                 uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	CustomerID         uuid.UUID            `gorm:"type:uuid;not null;column:customer_id" json:"customerId"`
-	DeliveryAddressID  *uint                `gorm:"column:delivery_address_id" json:"deliveryAddressId"`
-	Status             OrderStatus          `gorm:"type:varchar(50);not null;default:'pending'" json:"status"`
-	PaymentStatus      PaymentStatus        `gorm:"type:varchar(50);not null;default:'unpaid'" json:"paymentStatus"`
-	IdempotencyKey     string               `gorm:"type:varchar(255);uniqueIndex" json:"idempotencyKey"`
-	CouponCode         *string              `gorm:"type:varchar(100)" json:"couponCode"`
-	CouponDiscount     int64                `gorm:"default:0" json:"couponDiscount"`               // in kobo
-	ItemsSubtotal      int64                `gorm:"not null" json:"itemsSubtotal"`                 // in kobo
-	DeliveryFee        int64                `gorm:"default:0" json:"deliveryFee"`                  // in kobo
-	ServiceFee         int64                `gorm:"default:0" json:"serviceFee"`                   // in kobo
-	TotalAmount        int64                `gorm:"not null" json:"totalAmount"`                   // in kobo
-	CustomRequests     UUIDSlice            `gorm:"type:jsonb;default:'[]'" json:"customRequests"` // Custom request IDs
-	Notes              string               `gorm:"type:text" json:"notes"`
-	EstimatedDelivery  *time.Time           `json:"estimatedDelivery"`
-	DeliveredAt        *time.Time           `json:"deliveredAt"`
-	CancelledAt        *time.Time           `json:"cancelledAt"`
-	CancellationReason string               `gorm:"type:text" json:"cancellationReason"`
-	Items              []OrderItem          `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"items"`
-	StatusHistory      []OrderStatusHistory `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"statusHistory,omitempty"`
-	CreatedAt          time.Time            `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
-	UpdatedAt          time.Time            `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+	ID                      uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CustomerID              uuid.UUID            `gorm:"type:uuid;not null;column:customer_id;uniqueIndex:idx_orders_customer_idempotency_key,priority:1" json:"customerId"`
+	DeliveryAddressID       *uint                `gorm:"column:delivery_address_id" json:"deliveryAddressId"`
+	Status                  OrderStatus          `gorm:"type:varchar(50);not null;default:'pending'" json:"status"`
+	PaymentStatus           PaymentStatus        `gorm:"type:varchar(50);not null;default:'unpaid'" json:"paymentStatus"`
+	PaymentMethod           string               `gorm:"type:varchar(50)" json:"paymentMethod"`
+	IdempotencyKey          string               `gorm:"type:varchar(255);uniqueIndex:idx_orders_customer_idempotency_key,priority:2" json:"idempotencyKey"`
+	IdempotencyFingerprint  string               `gorm:"type:varchar(64)" json:"-"` // see idempotencyFingerprint
+	CouponCode              *string              `gorm:"type:varchar(100)" json:"couponCode"`
+	CouponAutoApplied       bool                 `gorm:"default:false" json:"couponAutoApplied"`                              // CouponCode was picked by auto-apply, not entered
+	CouponDiscount          int64                `gorm:"default:0" json:"couponDiscount"`                                     // in kobo
+	ItemsSubtotal           int64                `gorm:"not null" json:"itemsSubtotal"`                                       // in kobo
+	DeliveryFee             int64                `gorm:"default:0" json:"deliveryFee"`                                        // in kobo
+	DeliveryDiscount        int64                `gorm:"default:0" json:"deliveryDiscount"`                                   // taken off DeliveryFee by a free delivery coupon, in kobo
+	DeliverySurgeMultiplier float64              `gorm:"type:numeric(4,2);not null;default:1" json:"deliverySurgeMultiplier"` // surge window in effect when the zone fee was priced; 1 when none
+	ServiceFee              int64                `gorm:"default:0" json:"serviceFee"`                                         // in kobo
+	TipKobo                 int64                `gorm:"default:0" json:"tipKobo"`                                            // driver tip, in kobo
+	TaxKobo                 int64                `gorm:"default:0" json:"taxKobo"`                                            // VAT, in kobo
+	TaxInclusive            bool                 `gorm:"not null;default:false" json:"taxInclusive"`                          // prices included TaxKobo rather than having it added; see fees.go
+	AdjustmentKobo          int64                `gorm:"default:0" json:"adjustmentKobo"`                                     // sum of manual admin adjustments, in kobo; see adjustments.go
+	TotalAmount             int64                `gorm:"not null" json:"totalAmount"`                                         // in kobo
+	RefundMethod            *RefundMethod        `gorm:"type:varchar(20)" json:"refundMethod"`                                // how a cancelled order was refunded; see refunds.go
+	RefundedKobo            int64                `gorm:"default:0" json:"refundedKobo"`                                       // refunded on cancellation, in kobo; adjustment refunds are on the adjustments
+	RefundReference         *string              `gorm:"type:varchar(100)" json:"refundReference"`                            // Paystack refund ID or store credit coupon code
+	CustomRequests          UUIDSlice            `gorm:"type:jsonb;default:'[]'" json:"customRequests"`                       // Custom request IDs
+	QuoteSnapshots          QuoteSnapshotList    `gorm:"type:jsonb;not null;default:'[]'" json:"quoteSnapshots"`              // accepted quotes as charged; see quote_snapshots.go
+	Notes                   string               `gorm:"type:text" json:"notes"`
+	RecipientName           string               `gorm:"type:varchar(100)" json:"recipientName"` // set when someone other than the customer receives the order
+	RecipientPhone          string               `gorm:"type:varchar(20)" json:"recipientPhone"`
+	DeliveryInstructions    string               `gorm:"type:text" json:"deliveryInstructions"`                                                // for the driver, e.g. "leave with the gateman"
+	UTMSource               string               `gorm:"column:utm_source;type:varchar(100);not null;default:'direct';index" json:"utmSource"` // marketing attribution; see attribution.go
+	UTMCampaign             string               `gorm:"column:utm_campaign;type:varchar(100)" json:"utmCampaign"`
+	UTMMedium               string               `gorm:"column:utm_medium;type:varchar(100)" json:"utmMedium"`
+	StockReservedUntil      *time.Time           `gorm:"index" json:"stockReservedUntil"` // unpaid online orders give their stock back after this; see stock_reservations.go
+	ScheduledFor            *time.Time           `gorm:"index" json:"scheduledFor"`       // requested delivery time; the order waits in scheduled status until it is released
+	EstimatedDelivery       *time.Time           `json:"estimatedDelivery"`
+	DeliveredAt             *time.Time           `json:"deliveredAt"`
+	DeliveryVarianceMinutes *int                 `json:"deliveryVarianceMinutes"`                     // DeliveredAt minus EstimatedDelivery; positive means late
+	DeliveryZone            string               `gorm:"type:varchar(100);index" json:"deliveryZone"` // Zone matched at checkout, empty when fallback pricing applied
+	ReceivedAt              *time.Time           `json:"receivedAt"`                                  // When the customer confirmed receipt
+	PaidAt                  *time.Time           `gorm:"index" json:"paidAt"`                         // first switch to paid; counts towards that day's sales
+	CancelledAt             *time.Time           `json:"cancelledAt"`
+	CancellationReason      string               `gorm:"type:text" json:"cancellationReason"`
+	Version                 int64                `gorm:"not null;default:1" json:"version"` // bumped on every status/payment change; admin writes must send the version they read
+	Items                   []OrderItem          `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"items"`
+	StatusHistory           []OrderStatusHistory `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"statusHistory,omitempty"`
+	CreatedAt               time.Time            `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt               time.Time            `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
 }
 
 // OrderItem represents an item within an order
 type OrderItem struct {
-	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	OrderID    uuid.UUID `gorm:"type:uuid;not null;column:order_id" json:"orderId"`
-	ProductID  uuid.UUID `gorm:"type:uuid;not null;column:product_id" json:"productId"`
-	Name       string    `gorm:"type:varchar(255);not null" json:"name"`
-	SKU        string    `gorm:"type:varchar(100)" json:"sku"`
-	Source     string    `gorm:"type:varchar(50);default:'catalog'" json:"source"`
-	Quantity   int       `gorm:"not null;check:quantity > 0" json:"quantity"`
-	UnitPrice  int64     `gorm:"not null" json:"unitPrice"`  // Price per unit in kobo at time of order
-	TotalPrice int64     `gorm:"not null" json:"totalPrice"` // Total price for this item in kobo
-	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
-	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID       uuid.UUID  `gorm:"type:uuid;not null;column:order_id" json:"orderId"`
+	ProductID     uuid.UUID  `gorm:"type:uuid;not null;column:product_id" json:"productId"`
+	Name          string     `gorm:"type:varchar(255);not null" json:"name"`
+	SKU           string     `gorm:"type:varchar(100)" json:"sku"`
+	Source        string     `gorm:"type:varchar(50);default:'catalog'" json:"source"`
+	Quantity      int        `gorm:"not null;check:quantity > 0" json:"quantity"`
+	UnitPrice     int64      `gorm:"not null" json:"unitPrice"`                                      // Price per unit in kobo at time of order
+	TotalPrice    int64      `gorm:"not null" json:"totalPrice"`                                     // Total price for this item in kobo
+	UnitCost      int64      `gorm:"not null;default:0" json:"-"`                                    // Product cost per unit in kobo at time of order (0 = not captured)
+	SubstituteFor *uuid.UUID `gorm:"type:uuid;column:substitute_for" json:"substituteFor,omitempty"` // product this line stands in for after a stock shortfall
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
 
 	// Relationships
 	Order   Order            `gorm:"foreignKey:OrderID" json:"-"`
@@ -99,6 +129,7 @@ func (OrderStatusHistory) TableName() string {
 type OrderStatus string
 
 const (
+	OrderStatusScheduled      OrderStatus = "scheduled"
 	OrderStatusPending        OrderStatus = "pending"
 	OrderStatusConfirmed      OrderStatus = "confirmed"
 	OrderStatusPreparing      OrderStatus = "preparing"
@@ -107,6 +138,29 @@ const (
 	OrderStatusCancelled      OrderStatus = "cancelled"
 )
 
+// fulfilmentStages lists the non-terminal order statuses in the order they progress
+var fulfilmentStages = []OrderStatus{
+	OrderStatusScheduled,
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusPreparing,
+	OrderStatusOutForDelivery,
+}
+
+// stageIndex returns the position of status in fulfilmentStages, or -1 for terminal/unknown statuses
+func stageIndex(status OrderStatus) int {
+	for i, stage := range fulfilmentStages {
+		if stage == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// PaymentMethodCashOnDelivery marks orders paid in cash to the driver; they are marked paid when
+// the customer confirms receipt
+const PaymentMethodCashOnDelivery = "cash_on_delivery"
+
 type PaymentStatus string
 
 const (
@@ -117,11 +171,12 @@ const (
 	PaymentStatusRefunded          PaymentStatus = "refunded"
 	PaymentStatusFailed            PaymentStatus = "failed"
 	PaymentStatusExpired           PaymentStatus = "expired"
+	PaymentStatusRefundDue         PaymentStatus = "refund_due" // paid after the order was cancelled; the charge is owed back
 )
 
 // Helper methods for Order
 func (o *Order) CanBeCancelled() bool {
-	return o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed
+	return o.Status == OrderStatusScheduled || o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed
 }
 
 func (o *Order) IsDelivered() bool {
@@ -133,7 +188,19 @@ func (o *Order) IsCancelled() bool {
 }
 
 func (o *Order) CalculateTotal() int64 {
-	return o.ItemsSubtotal + o.DeliveryFee + o.ServiceFee - o.CouponDiscount
+	total := o.ItemsSubtotal + o.DeliveryFee + o.ServiceFee + o.TipKobo + o.AdjustmentKobo - o.CouponDiscount
+	if !o.TaxInclusive {
+		total += o.TaxKobo
+	}
+	return total
+}
+
+// TaxMode is TaxModeInclusive or TaxModeExclusive, whichever the order was priced in
+func (o *Order) TaxMode() string {
+	if o.TaxInclusive {
+		return TaxModeInclusive
+	}
+	return TaxModeExclusive
 }
 
 // BeforeCreate GORM hook
@@ -143,7 +210,10 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	}
 	o.TotalAmount = o.CalculateTotal()
 
-	// Set estimated delivery to 2 hours from now if not already set
+	// Scheduled orders are expected at their slot; others 2 hours from now if not already set
+	if o.EstimatedDelivery == nil && o.ScheduledFor != nil {
+		o.EstimatedDelivery = o.ScheduledFor
+	}
 	if o.EstimatedDelivery == nil {
 		estimatedTime := time.Now().Add(2 * time.Hour)
 		o.EstimatedDelivery = &estimatedTime
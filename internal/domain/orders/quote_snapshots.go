@@ -0,0 +1,106 @@
+package orders
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"errandShop/internal/domain/custom_requests"
+	"github.com/google/uuid"
+)
+
+// QuoteSnapshot is a copy of the quote a customer accepted for one of the order's custom requests,
+// taken when the order is placed. The order is charged from its snapshots, so editing or declining
+// the quote afterwards does not change what the order records as agreed.
+type QuoteSnapshot struct {
+	CustomRequestID uuid.UUID           `json:"customRequestId"`
+	QuoteID         uuid.UUID           `json:"quoteId"`
+	ItemsSubtotal   int64               `json:"itemsSubtotal"` // in kobo
+	DeliveryFee     int64               `json:"deliveryFee"`   // in kobo
+	ServiceFee      int64               `json:"serviceFee"`    // in kobo
+	PackagingFee    int64               `json:"packagingFee"`  // in kobo
+	GrandTotal      int64               `json:"grandTotal"`    // in kobo
+	AcceptedAt      *time.Time          `json:"acceptedAt"`
+	Items           []QuoteSnapshotItem `json:"items"`
+}
+
+// QuoteSnapshotItem is one quoted line of a QuoteSnapshot
+type QuoteSnapshotItem struct {
+	RequestItemID uuid.UUID `json:"requestItemId"`
+	Name          string    `json:"name"`
+	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit"`
+	UnitPrice     int64     `json:"unitPrice"` // in kobo
+}
+
+// newQuoteSnapshot copies an accepted quote for the order being placed
+func newQuoteSnapshot(customRequestID uuid.UUID, quote *custom_requests.QuoteRes) QuoteSnapshot {
+	snapshot := QuoteSnapshot{
+		CustomRequestID: customRequestID,
+		QuoteID:         quote.ID,
+		ItemsSubtotal:   quote.ItemsSubtotal,
+		DeliveryFee:     quote.Fees.Delivery,
+		ServiceFee:      quote.Fees.Service,
+		PackagingFee:    quote.Fees.Packaging,
+		GrandTotal:      quote.GrandTotal,
+		AcceptedAt:      quote.AcceptedAt,
+		Items:           make([]QuoteSnapshotItem, len(quote.Items)),
+	}
+	for i, item := range quote.Items {
+		snapshot.Items[i] = QuoteSnapshotItem{
+			RequestItemID: item.RequestItemID,
+			Name:          item.Name,
+			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			UnitPrice:     item.UnitPrice,
+		}
+	}
+	return snapshot
+}
+
+// Charges is how the snapshot feeds into the order's fees (see fees.go)
+func (q QuoteSnapshot) Charges() QuoteCharges {
+	return QuoteCharges{
+		ItemsSubtotal: q.ItemsSubtotal,
+		DeliveryFee:   q.DeliveryFee,
+		ServiceFee:    q.ServiceFee + q.PackagingFee,
+	}
+}
+
+// QuoteSnapshotList is the JSONB column holding an order's quote snapshots
+type QuoteSnapshotList []QuoteSnapshot
+
+// Value implements the driver.Valuer interface for database storage
+func (l QuoteSnapshotList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements the sql.Scanner interface for database retrieval. Unlike UUIDSlice, a snapshot
+// that cannot be read is an error: it is the record of what the customer was charged.
+func (l *QuoteSnapshotList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported quote snapshot type %T", value)
+	}
+	if len(data) == 0 || string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
@@ -0,0 +1,51 @@
+package orders
+
+import (
+	"testing"
+	"time"
+
+	"errandShop/internal/domain/custom_requests"
+	"github.com/google/uuid"
+)
+
+func TestQuoteSnapshotKeepsAgreedCharges(t *testing.T) {
+	acceptedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	requestID := uuid.New()
+	quote := &custom_requests.QuoteRes{
+		ID:            uuid.New(),
+		ItemsSubtotal: 500000,
+		Fees:          custom_requests.QuoteFees{Delivery: 100000, Service: 25000, Packaging: 5000},
+		GrandTotal:    630000,
+		AcceptedAt:    &acceptedAt,
+		Items: []custom_requests.QuoteItemRes{
+			{RequestItemID: uuid.New(), Name: "Yam tubers", Quantity: 5, Unit: "tuber", UnitPrice: 100000},
+		},
+	}
+	snapshot := newQuoteSnapshot(requestID, quote)
+
+	// The snapshot must survive a round trip through the JSONB column
+	stored, err := QuoteSnapshotList{snapshot}.Value()
+	if err != nil {
+		t.Fatalf("Value returned error: %v", err)
+	}
+	var loaded QuoteSnapshotList
+	if err := loaded.Scan(stored); err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].GrandTotal != 630000 || len(loaded[0].Items) != 1 || loaded[0].Items[0].Name != "Yam tubers" {
+		t.Fatalf("snapshot did not round trip: %+v", loaded)
+	}
+
+	// The quote is then edited; re-pricing must use the snapshot without looking the quote up
+	quote.GrandTotal = 900000
+	order := &Order{CustomRequests: UUIDSlice{requestID}, QuoteSnapshots: loaded}
+	s := &Service{}
+	charges, total, err := s.quoteChargesForOrder(uuid.New(), order)
+	if err != nil {
+		t.Fatalf("quoteChargesForOrder returned error: %v", err)
+	}
+	want := QuoteCharges{ItemsSubtotal: 500000, DeliveryFee: 100000, ServiceFee: 30000}
+	if total != 630000 || len(charges) != 1 || charges[0] != want {
+		t.Fatalf("quoteChargesForOrder = (%+v, %d), want ([%+v], 630000)", charges, total, want)
+	}
+}
@@ -0,0 +1,83 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"errandShop/internal/domain/auth"
+	"errandShop/internal/domain/coupons"
+	"errandShop/internal/domain/notifications"
+	"errandShop/pkg/money"
+	"github.com/google/uuid"
+)
+
+// SetReferralReward sets the fixed coupon (in kobo) the referrer and the referred customer each
+// receive when the referred customer's first order is delivered. Zero disables referral rewards.
+func (s *Service) SetReferralReward(kobo int64) {
+	s.referralRewardKobo = kobo
+}
+
+// creditReferral rewards a referred customer and their referrer once, on the first delivered order.
+// The credit is claimed with a conditional update before any coupon is issued, so two orders
+// delivered at the same time cannot both pay out.
+func (s *Service) creditReferral(ctx context.Context, customerID uuid.UUID) {
+	if s.referralRewardKobo <= 0 {
+		return
+	}
+
+	var user auth.User
+	if err := s.db.WithContext(ctx).Select("id", "referred_by_id", "referral_credited_at").
+		Where("id = ?", customerID).First(&user).Error; err != nil {
+		fmt.Printf("Warning: failed to load referral for user %s: %v\n", customerID, err)
+		return
+	}
+	if user.ReferredByID == nil || *user.ReferredByID == customerID || user.ReferralCreditedAt != nil {
+		return
+	}
+
+	res := s.db.WithContext(ctx).Model(&auth.User{}).
+		Where("id = ? AND referral_credited_at IS NULL", customerID).
+		UpdateColumn("referral_credited_at", time.Now())
+	if res.Error != nil {
+		fmt.Printf("Warning: failed to claim referral credit for user %s: %v\n", customerID, res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		return
+	}
+
+	for _, userID := range []uuid.UUID{customerID, *user.ReferredByID} {
+		coupon, err := s.couponService.AutoGenerateUserCoupon(userID, coupons.CouponFixed, float64(s.referralRewardKobo), "Referral reward")
+		if err != nil {
+			fmt.Printf("Warning: failed to generate referral coupon for user %s: %v\n", userID, err)
+			continue
+		}
+		s.sendReferralNotification(userID, coupon.Code)
+	}
+}
+
+// sendReferralNotification tells a customer about the referral coupon they just received
+func (s *Service) sendReferralNotification(userID uuid.UUID, couponCode string) {
+	if s.notificationService == nil {
+		return
+	}
+
+	notificationReq := &notifications.CreateNotificationRequest{
+		RecipientID:   userID,
+		RecipientType: notifications.RecipientCustomer,
+		Type:          notifications.TypePromotion,
+		Title:         "You earned a referral reward",
+		Body:          fmt.Sprintf("Thanks for spreading the word! Use code %s for %s off your next order.", couponCode, money.Money(s.referralRewardKobo)),
+		Data: map[string]interface{}{
+			"couponCode": couponCode,
+			"reason":     "referral",
+		},
+	}
+
+	go func() {
+		if _, err := s.notificationService.CreateNotification(notificationReq); err != nil {
+			fmt.Printf("Failed to send referral notification: %v\n", err)
+		}
+	}()
+}
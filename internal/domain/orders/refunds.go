@@ -0,0 +1,184 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"errandShop/internal/domain/payments"
+	"errandShop/pkg/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefundMethod is how money goes back to a customer
+type RefundMethod string
+
+const (
+	// RefundToOriginalPayment reverses the charge through Paystack, which costs fees and takes days
+	RefundToOriginalPayment RefundMethod = "original_payment"
+	// RefundToStoreCredit issues a one-time coupon for the amount, usable straight away
+	RefundToStoreCredit RefundMethod = "store_credit"
+)
+
+// ErrRefundUnavailable is returned when an order has nothing that can be refunded the way requested
+var ErrRefundUnavailable = errors.New("order cannot be refunded")
+
+// issueRefund sends amountKobo of a paid order back to its customer by method. It talks to
+// Paystack or the coupons service, so callers run it last in their transaction.
+func (s *Service) issueRefund(order *Order, amountKobo int64, method RefundMethod, reason string) (*OrderRefundResponse, error) {
+	res := &OrderRefundResponse{
+		Method:      method,
+		AmountKobo:  amountKobo,
+		AmountNaira: money.Money(amountKobo).Naira(),
+	}
+	switch method {
+	case RefundToStoreCredit:
+		if s.couponService == nil {
+			return nil, fmt.Errorf("%w: store credit is not configured", ErrRefundUnavailable)
+		}
+		credit, err := s.couponService.IssueStoreCredit(order.ID, order.CustomerID, amountKobo, reason)
+		if err != nil {
+			return nil, fmt.Errorf("failed to issue store credit: %w", err)
+		}
+		res.StoreCredit = credit
+	case RefundToOriginalPayment:
+		if s.refunder == nil {
+			return nil, fmt.Errorf("%w: refunds are not configured", ErrRefundUnavailable)
+		}
+		paymentID, err := s.refundablePayment(order.ID, amountKobo)
+		if err != nil {
+			return nil, err
+		}
+		refund, err := s.refunder.InitiateRefund(payments.RefundPaymentRequest{
+			PaymentID:  paymentID,
+			AmountKobo: amountKobo,
+			Reason:     reason,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initiate refund: %w", err)
+		}
+		res.PaymentRefund = refund
+	default:
+		return nil, fmt.Errorf("%w: unknown refund method %q", ErrRefundUnavailable, method)
+	}
+	return res, nil
+}
+
+// refundablePayment returns the order's completed payment that can cover a refund of amountKobo
+func (s *Service) refundablePayment(orderID uuid.UUID, amountKobo int64) (string, error) {
+	orderPayments, err := s.refunder.GetOrderPayments(orderID.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to get order payments: %w", err)
+	}
+	for _, p := range orderPayments {
+		if p.Status == payments.PaymentStatusCompleted && p.AmountKobo >= amountKobo {
+			return p.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no completed payment covers the refund", ErrRefundUnavailable)
+}
+
+// reference is the Paystack refund ID or store credit coupon code recorded on the order
+func (r *OrderRefundResponse) reference() string {
+	if r.StoreCredit != nil {
+		return r.StoreCredit.Code
+	}
+	if r.PaymentRefund != nil {
+		return r.PaymentRefund.ID
+	}
+	return ""
+}
+
+// RefundCancelledOrder refunds what is left of a cancelled, paid order's total and records the
+// method and reference on the order. It is safe to retry: an order already refunded in full
+// returns ErrRefundUnavailable rather than paying out twice.
+func (s *Service) RefundCancelledOrder(ctx context.Context, id uuid.UUID, method RefundMethod, reason string) (*OrderRefundResponse, error) {
+	var refund *OrderRefundResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error; err != nil {
+			return err
+		}
+		if order.Status != OrderStatusCancelled {
+			return fmt.Errorf("%w: only cancelled orders are refunded in full", ErrRefundUnavailable)
+		}
+		if order.PaymentStatus != PaymentStatusPaid && order.PaymentStatus != PaymentStatusPartiallyRefunded &&
+			order.PaymentStatus != PaymentStatusRefundDue {
+			return fmt.Errorf("%w: order has not been paid", ErrRefundUnavailable)
+		}
+		amount := order.TotalAmount - order.RefundedKobo
+		if amount <= 0 {
+			return fmt.Errorf("%w: order has already been refunded", ErrRefundUnavailable)
+		}
+
+		var err error
+		if refund, err = s.issueRefund(&order, amount, method, "Cancelled order: "+reason); err != nil {
+			return err
+		}
+		return tx.Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"refund_method":    method,
+			"refunded_kobo":    gorm.Expr("refunded_kobo + ?", amount),
+			"refund_reference": refund.reference(),
+			"payment_status":   PaymentStatusRefunded,
+			"version":          gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// refundOnCancel refunds a paid order that has just been cancelled. A failed refund does not undo
+// the cancellation; it is logged and support can retry it from the admin refund endpoint.
+func (s *Service) refundOnCancel(ctx context.Context, order *Order, method RefundMethod, reason string) *OrderRefundResponse {
+	if order.PaymentStatus != PaymentStatusPaid && order.PaymentStatus != PaymentStatusPartiallyRefunded {
+		return nil
+	}
+	refund, err := s.RefundCancelledOrder(ctx, order.ID, method, reason)
+	if err != nil {
+		log.Printf("Warning: failed to refund cancelled order %s by %s: %v", order.ID, method, err)
+		return nil
+	}
+	return refund
+}
+
+// refundLatePayment handles a payment that succeeds after its order was cancelled, e.g. once the
+// stock reservation ran out. The order stays cancelled and unpaid: it is marked refund_due and the
+// charge goes back to the original payment. A failed refund is logged and leaves the order
+// refund_due, so support can retry it from the admin refund endpoint. Repeated callbacks for the
+// same payment find the order already marked and do nothing.
+func (s *Service) refundLatePayment(ctx context.Context, id uuid.UUID) error {
+	res := s.db.WithContext(ctx).Model(&Order{}).
+		Where("id = ? AND status = ? AND payment_status IN ?", id, OrderStatusCancelled, latePaymentStatuses).
+		Updates(map[string]interface{}{
+			"payment_status": PaymentStatusRefundDue,
+			"version":        gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("failed to flag late payment for refund: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	log.Printf("Payment received for cancelled order %s; refunding it", id)
+	if _, err := s.RefundCancelledOrder(ctx, id, RefundToOriginalPayment, "payment received after the order was cancelled"); err != nil {
+		log.Printf("Warning: failed to refund late payment for cancelled order %s: %v", id, err)
+	}
+	return nil
+}
+
+// latePaymentStatuses are the payment states of a cancelled order that was never paid
+var latePaymentStatuses = append([]PaymentStatus{PaymentStatusExpired}, unpaidPaymentStatuses...)
+
+// storeCreditID returns the coupon ID when a refund was issued as store credit
+func storeCreditID(refund *OrderRefundResponse) *uuid.UUID {
+	if refund == nil || refund.StoreCredit == nil {
+		return nil
+	}
+	return &refund.StoreCredit.ID
+}
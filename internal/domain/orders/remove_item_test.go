@@ -0,0 +1,58 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRemoveItemOnlyFromPendingUnpaidOrders(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT, payment_status TEXT, custom_requests TEXT DEFAULT '[]', created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT, quantity INTEGER, unit_price INTEGER, total_price INTEGER)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY, order_id TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	userID := uuid.New()
+	order := func(status OrderStatus, paymentStatus PaymentStatus) (uuid.UUID, uuid.UUID) {
+		orderID, itemID := uuid.New(), uuid.New()
+		if err := db.Exec(`INSERT INTO orders (id, customer_id, status, payment_status) VALUES (?, ?, ?, ?)`, orderID, userID, status, paymentStatus).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+		if err := db.Exec(`INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price) VALUES (?, ?, ?, 2, 100000, 200000)`, itemID, orderID, uuid.New()).Error; err != nil {
+			t.Fatalf("failed to insert order item: %v", err)
+		}
+		return orderID, itemID
+	}
+
+	s := &Service{repo: NewRepository(db)}
+	ctx := context.Background()
+
+	paidID, paidItem := order(OrderStatusPending, PaymentStatusPaid)
+	if _, err := s.RemoveItem(ctx, paidID, userID, paidItem); !errors.Is(err, ErrOrderNotEditable) {
+		t.Fatalf("removing from a paid order: err = %v, want ErrOrderNotEditable", err)
+	}
+	confirmedID, confirmedItem := order(OrderStatusConfirmed, PaymentStatusUnpaid)
+	if _, err := s.RemoveItem(ctx, confirmedID, userID, confirmedItem); !errors.Is(err, ErrOrderNotEditable) {
+		t.Fatalf("removing from a confirmed order: err = %v, want ErrOrderNotEditable", err)
+	}
+	pendingID, _ := order(OrderStatusPending, PaymentStatusUnpaid)
+	if _, err := s.RemoveItem(ctx, pendingID, userID, paidItem); !errors.Is(err, ErrOrderItemNotFound) {
+		t.Fatalf("removing another order's item: err = %v, want ErrOrderItemNotFound", err)
+	}
+	if _, err := s.RemoveItem(ctx, pendingID, uuid.New(), paidItem); err == nil || err.Error() != "order not found" {
+		t.Fatalf("removing from someone else's order: err = %v, want order not found", err)
+	}
+}
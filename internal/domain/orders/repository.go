@@ -3,6 +3,7 @@ package orders
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -58,9 +59,14 @@ func (r *Repository) Create(ctx context.Context, order *Order) error {
 func (r *Repository) UpdateStatus(ctx context.Context, id uuid.UUID, userID uuid.UUID, status OrderStatus, reason string) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Update order status
-		if err := tx.Model(&Order{}).Where("id = ? AND customer_id = ?", id, userID).Updates(map[string]interface{}{
-			"status": status,
-		}).Error; err != nil {
+		updates := map[string]interface{}{
+			"status":  status,
+			"version": gorm.Expr("version + 1"),
+		}
+		if err := addDeliveredUpdates(tx, id, status, updates); err != nil {
+			return err
+		}
+		if err := tx.Model(&Order{}).Where("id = ? AND customer_id = ?", id, userID).Updates(updates).Error; err != nil {
 			return err
 		}
 
@@ -74,11 +80,37 @@ func (r *Repository) UpdateStatus(ctx context.Context, id uuid.UUID, userID uuid
 	})
 }
 
+// addDeliveredUpdates stamps DeliveredAt and the SLA variance against EstimatedDelivery
+// the first time an order moves to delivered.
+func addDeliveredUpdates(tx *gorm.DB, id uuid.UUID, status OrderStatus, updates map[string]interface{}) error {
+	if status != OrderStatusDelivered {
+		return nil
+	}
+	var order Order
+	if err := tx.Select("id", "estimated_delivery", "delivered_at").Where("id = ?", id).First(&order).Error; err != nil {
+		return err
+	}
+	if order.DeliveredAt != nil {
+		return nil
+	}
+	now := time.Now()
+	updates["delivered_at"] = now
+	if order.EstimatedDelivery != nil {
+		updates["delivery_variance_minutes"] = int(math.Round(now.Sub(*order.EstimatedDelivery).Minutes()))
+	}
+	return nil
+}
+
 // CancelOrder cancels an order
 func (r *Repository) CancelOrder(ctx context.Context, id uuid.UUID, userID uuid.UUID, reason string) error {
 	return r.UpdateStatus(ctx, id, userID, OrderStatusCancelled, reason)
 }
 
+// ReleaseIdempotencyKey clears an expired idempotency key from its order so the key can be reused
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&Order{}).Where("id = ?", id).Update("idempotency_key", gorm.Expr("NULL")).Error
+}
+
 // CheckIdempotency checks if an order with the given idempotency key exists
 func (r *Repository) CheckIdempotency(ctx context.Context, userID uuid.UUID, idempotencyKey string) (*Order, error) {
 	var order Order
@@ -141,14 +173,25 @@ func (r *Repository) AdminGet(ctx context.Context, id uuid.UUID) (*Order, error)
 	return &order, nil
 }
 
-func (r *Repository) AdminUpdateStatus(ctx context.Context, id uuid.UUID, status OrderStatus) error {
+// AdminUpdateStatus sets the status only if the order is still at version, returning
+// ErrOrderVersionConflict when someone else changed it first.
+func (r *Repository) AdminUpdateStatus(ctx context.Context, id uuid.UUID, status OrderStatus, version int64) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Update order status
-		if err := tx.Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
-			"status": status,
-		}).Error; err != nil {
+		updates := map[string]interface{}{
+			"status":  status,
+			"version": gorm.Expr("version + 1"),
+		}
+		if err := addDeliveredUpdates(tx, id, status, updates); err != nil {
 			return err
 		}
+		res := tx.Model(&Order{}).Where("id = ? AND version = ?", id, version).Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrOrderVersionConflict
+		}
 
 		//// Add status history entry
 		statusHistory := &OrderStatusHistory{
@@ -160,10 +203,94 @@ func (r *Repository) AdminUpdateStatus(ctx context.Context, id uuid.UUID, status
 	})
 }
 
-func (r *Repository) AdminUpdatePaymentStatus(ctx context.Context, id uuid.UUID, paymentStatus PaymentStatus) error {
-	return r.db.WithContext(ctx).Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+// ConfirmPaidOrder moves a pending, paid order to confirmed. The status check is part of the
+// UPDATE so concurrent cancellations or confirmations are never overwritten; it reports
+// whether the order was transitioned.
+func (r *Repository) ConfirmPaidOrder(ctx context.Context, id uuid.UUID) (bool, error) {
+	confirmed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Order{}).
+			Where("id = ? AND status = ? AND payment_status = ?", id, OrderStatusPending, PaymentStatusPaid).
+			Updates(map[string]interface{}{
+				"status":  OrderStatusConfirmed,
+				"version": gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		fromStatus := OrderStatusPending
+		statusHistory := &OrderStatusHistory{
+			OrderID:    id,
+			FromStatus: &fromStatus,
+			ToStatus:   OrderStatusConfirmed,
+			Note:       "Confirmed automatically after successful payment",
+		}
+		if err := tx.Create(statusHistory).Error; err != nil {
+			return err
+		}
+		confirmed = true
+		return nil
+	})
+	return confirmed, err
+}
+
+// ConfirmReceived stamps received_at on the customer's delivered order, also marking it paid when
+// markPaid is set. It reports false if receipt was already confirmed.
+func (r *Repository) ConfirmReceived(ctx context.Context, id uuid.UUID, userID uuid.UUID, markPaid bool) (bool, error) {
+	updates := map[string]interface{}{
+		"received_at": time.Now(),
+		"version":     gorm.Expr("version + 1"),
+	}
+	if markPaid {
+		updates["payment_status"] = PaymentStatusPaid
+		updates["paid_at"] = gorm.Expr("COALESCE(paid_at, ?)", time.Now())
+	}
+
+	res := r.db.WithContext(ctx).Model(&Order{}).
+		Where("id = ? AND customer_id = ? AND status = ? AND received_at IS NULL", id, userID, OrderStatusDelivered).
+		Updates(updates)
+	return res.RowsAffected > 0, res.Error
+}
+
+// MarkPaid marks an order paid unless it has been cancelled; it reports whether the order was
+// updated. The status check is part of the UPDATE so a concurrent cancellation always wins.
+func (r *Repository) MarkPaid(ctx context.Context, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&Order{}).
+		Where("id = ? AND status <> ?", id, OrderStatusCancelled).
+		Updates(map[string]interface{}{
+			"payment_status": PaymentStatusPaid,
+			"paid_at":        gorm.Expr("COALESCE(paid_at, ?)", time.Now()),
+			"version":        gorm.Expr("version + 1"),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// AdminUpdatePaymentStatus sets the payment status. With a version it only applies to an order still
+// at that version and returns ErrOrderVersionConflict otherwise; nil updates unconditionally.
+func (r *Repository) AdminUpdatePaymentStatus(ctx context.Context, id uuid.UUID, paymentStatus PaymentStatus, version *int64) error {
+	query := r.db.WithContext(ctx).Model(&Order{}).Where("id = ?", id)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+	updates := map[string]interface{}{
 		"payment_status": paymentStatus,
-	}).Error
+		"version":        gorm.Expr("version + 1"),
+	}
+	if paymentStatus == PaymentStatusPaid {
+		updates["paid_at"] = gorm.Expr("COALESCE(paid_at, ?)", time.Now())
+	}
+	res := query.Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if version != nil && res.RowsAffected == 0 {
+		return ErrOrderVersionConflict
+	}
+	return nil
 }
 
 func (r *Repository) AdminCancelOrder(ctx context.Context, id uuid.UUID, reason string) error {
@@ -173,6 +300,7 @@ func (r *Repository) AdminCancelOrder(ctx context.Context, id uuid.UUID, reason
 			"status": OrderStatusCancelled,
 			"cancellation_reason": reason,
 			"cancelled_at": time.Now(),
+			"version": gorm.Expr("version + 1"),
 		}).Error; err != nil {
 			return err
 		}
@@ -190,29 +318,36 @@ func (r *Repository) AdminCancelOrder(ctx context.Context, id uuid.UUID, reason
 func (r *Repository) GetStats(ctx context.Context, query OrderStatsQuery) (*OrderStats, error) {
 	stats := &OrderStats{}
 
-	db := r.db.WithContext(ctx).Model(&Order{})
+	// Build a fresh scoped query each time so conditions don't accumulate across counts
+	scoped := func() *gorm.DB {
+		db := r.db.WithContext(ctx).Model(&Order{})
 
-	// Apply date filters if provided
-	if query.DateFrom != nil {
-		db = db.Where("created_at >= ?", *query.DateFrom)
-	}
-	if query.DateTo != nil {
-		db = db.Where("created_at <= ?", *query.DateTo)
-	}
-	if query.UserID != nil {
-		db = db.Where("customer_id = ?", *query.UserID)
+		// Apply date filters if provided
+		if query.DateFrom != nil {
+			db = db.Where("created_at >= ?", *query.DateFrom)
+		}
+		if query.DateTo != nil {
+			db = db.Where("created_at <= ?", *query.DateTo)
+		}
+		if query.UserID != nil {
+			db = db.Where("customer_id = ?", *query.UserID)
+		}
+		return db
 	}
 
 	// Total orders
-	db.Count(&stats.TotalOrders)
+	if err := scoped().Count(&stats.TotalOrders).Error; err != nil {
+		return nil, err
+	}
 
 	// Orders by status
-	db.Where("status = ?", OrderStatusPending).Count(&stats.PendingOrders)
-	db.Where("status = ?", OrderStatusConfirmed).Count(&stats.ConfirmedOrders)
-	db.Where("status = ?", OrderStatusPreparing).Count(&stats.PreparingOrders)
-	db.Where("status = ?", OrderStatusOutForDelivery).Count(&stats.ShippedOrders)
-	db.Where("status = ?", OrderStatusDelivered).Count(&stats.DeliveredOrders)
-	db.Where("status = ?", OrderStatusCancelled).Count(&stats.CancelledOrders)
+	scoped().Where("status = ?", OrderStatusScheduled).Count(&stats.ScheduledOrders)
+	scoped().Where("status = ?", OrderStatusPending).Count(&stats.PendingOrders)
+	scoped().Where("status = ?", OrderStatusConfirmed).Count(&stats.ConfirmedOrders)
+	scoped().Where("status = ?", OrderStatusPreparing).Count(&stats.PreparingOrders)
+	scoped().Where("status = ?", OrderStatusOutForDelivery).Count(&stats.ShippedOrders)
+	scoped().Where("status = ?", OrderStatusDelivered).Count(&stats.DeliveredOrders)
+	scoped().Where("status = ?", OrderStatusCancelled).Count(&stats.CancelledOrders)
 	// RefundedOrders count removed as status doesn't exist in DB constraint
 	stats.RefundedOrders = 0
 
@@ -222,7 +357,9 @@ func (r *Repository) GetStats(ctx context.Context, query OrderStatsQuery) (*Orde
 		AverageOrder  float64
 	}
 	var revenueResult RevenueResult
-	db.Select("COALESCE(SUM(total_amount), 0) as total_revenue, COALESCE(AVG(total_amount), 0) as average_order").Where("payment_status = ?", PaymentStatusPaid).Scan(&revenueResult)
+	if err := scoped().Select("COALESCE(SUM(total_amount), 0) as total_revenue, COALESCE(AVG(total_amount), 0) as average_order").Where("payment_status = ?", PaymentStatusPaid).Scan(&revenueResult).Error; err != nil {
+		return nil, err
+	}
 	stats.TotalRevenue = int64(revenueResult.TotalRevenue)
 	stats.AverageOrderValue = int64(revenueResult.AverageOrder)
 	stats.RevenueNaira = revenueResult.TotalRevenue
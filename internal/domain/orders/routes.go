@@ -1,6 +1,8 @@
 package orders
 
 import (
+	"time"
+
 	"errandShop/config"
 	"errandShop/internal/middleware"
 
@@ -15,19 +17,34 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, orderHandler *Handler, cart
 	cart := api.Group("/cart", middleware.JWTMiddleware(cfg))
 	cart.Get("/", cartHandler.GetCart)
 	cart.Post("/items", cartHandler.AddToCart)
-	cart.Put("/items/:id", cartHandler.UpdateCartItem)
-	cart.Delete("/items/:id", cartHandler.RemoveFromCart)
+	cart.Post("/merge", cartHandler.MergeCart)
+	cart.Put("/items/:itemId", cartHandler.UpdateCartItem)
+	cart.Delete("/items/:itemId", cartHandler.RemoveFromCart)
 	cart.Delete("/clear", cartHandler.ClearCart)
 
+	// Wishlist routes (protected)
+	wishlist := api.Group("/wishlist", middleware.JWTMiddleware(cfg))
+	wishlist.Get("/", cartHandler.ListSavedItems)
+	wishlist.Post("/", cartHandler.SaveItem)
+	wishlist.Delete("/:productId", cartHandler.RemoveSavedItem)
+	wishlist.Post("/:productId/move-to-cart", cartHandler.MoveSavedItemToCart)
+
 	// Coupon validation (public)
 	api.Post("/coupons/validate", couponHandler.ValidateCoupon)
 
-	// Customer order routes (protected) - specific routes to avoid conflicts
+	// Customer order routes (protected) - specific routes to avoid conflicts. Routes that move
+	// money (placing an order starts a charge, cancelling refunds) refuse impersonation tokens.
 	api.Get("/orders", middleware.JWTMiddleware(cfg), orderHandler.List)
-	api.Post("/orders", middleware.JWTMiddleware(cfg), orderHandler.Create)
+	api.Post("/orders", middleware.JWTMiddleware(cfg), middleware.NoImpersonation(), middleware.UserRateLimit(cfg.OrderCreateRateLimit, time.Minute), orderHandler.Create)
 	api.Get("/orders/:id", middleware.JWTMiddleware(cfg), orderHandler.Get)
+	api.Get("/orders/:id/invoice", middleware.JWTMiddleware(cfg), orderHandler.Invoice) // owner or admin
+	api.Get("/orders/:id/tracking", middleware.JWTMiddleware(cfg), orderHandler.Tracking) // owner or admin
 	api.Put("/orders/:id/status", middleware.JWTMiddleware(cfg), orderHandler.UpdateStatus)
-	api.Post("/orders/:id/cancel", middleware.JWTMiddleware(cfg), orderHandler.CancelOrder)
+	api.Patch("/orders/:id/items", middleware.JWTMiddleware(cfg), orderHandler.UpdateItems)
+	api.Delete("/orders/:id/items/:itemId", middleware.JWTMiddleware(cfg), orderHandler.RemoveItem)
+	api.Post("/orders/:id/cancel", middleware.JWTMiddleware(cfg), middleware.NoImpersonation(), orderHandler.CancelOrder)
+	api.Post("/orders/:id/reorder", middleware.JWTMiddleware(cfg), orderHandler.Reorder)
+	api.Post("/orders/:id/confirm-received", middleware.JWTMiddleware(cfg), orderHandler.ConfirmReceived)
 
 	// Admin routes (require admin role)
 	admin := api.Group("/admin", middleware.JWTMiddleware(cfg), middleware.AdminMiddleware())
@@ -35,8 +52,11 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, orderHandler *Handler, cart
 	adminOrders.Get("/", orderHandler.AdminList)
 	// Register static route before dynamic :id to prevent conflicts
 	adminOrders.Get("/stats", orderHandler.GetStats)
+	adminOrders.Put("/bulk/status", orderHandler.AdminBulkUpdateStatus)
 	adminOrders.Get("/:id", orderHandler.AdminGet)
 	adminOrders.Put("/:id/status", orderHandler.AdminUpdateStatus)
 	adminOrders.Put("/:id/payment-status", orderHandler.AdminUpdatePaymentStatus)
 	adminOrders.Put("/:id/cancel", orderHandler.AdminCancelOrder)
+	adminOrders.Post("/:id/adjust", orderHandler.AdminAdjustOrder)
+	adminOrders.Post("/:id/refund", orderHandler.AdminRefundOrder)
 }
\ No newline at end of file
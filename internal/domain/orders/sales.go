@@ -0,0 +1,46 @@
+package orders
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"errandShop/internal/domain/webhooks"
+	"github.com/google/uuid"
+)
+
+// SaleRecorder is told about every order whose payment succeeds, e.g. to keep a running total of
+// today's sales without querying the orders table
+type SaleRecorder interface {
+	RecordSale(amountKobo int64, paidAt time.Time)
+}
+
+// SetSaleRecorder enables sale recording on payment success; nil disables it
+func (s *Service) SetSaleRecorder(recorder SaleRecorder) {
+	s.saleRecorder = recorder
+}
+
+// orderPaid runs after an order first switches to paid: it emits order.paid and, in the
+// background, records the sale with the order's total
+func (s *Service) orderPaid(orderID uuid.UUID) {
+	s.emitOrderEvent(webhooks.EventOrderPaid, orderID)
+	if s.funnel != nil {
+		s.funnel.OrderPaid(orderID)
+	}
+	if s.saleRecorder == nil {
+		return
+	}
+
+	go func() {
+		order, err := s.repo.AdminGet(context.Background(), orderID)
+		if err != nil {
+			log.Printf("Failed to load order %s to record its sale: %v", orderID, err)
+			return
+		}
+		paidAt := time.Now()
+		if order.PaidAt != nil {
+			paidAt = *order.PaidAt
+		}
+		s.saleRecorder.RecordSale(order.TotalAmount, paidAt)
+	}()
+}
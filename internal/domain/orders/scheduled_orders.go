@@ -0,0 +1,208 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"errandShop/internal/domain/products"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultScheduleLead is the minimum notice for a scheduled order and how long before its
+	// slot it is released for fulfilment
+	DefaultScheduleLead = time.Hour
+	// DefaultScheduleMaxAhead is the furthest ahead an order may be scheduled
+	DefaultScheduleMaxAhead = 7 * 24 * time.Hour
+)
+
+// SetScheduling sets the scheduled order window. Non-positive values keep the defaults.
+func (s *Service) SetScheduling(lead, maxAhead time.Duration) {
+	if lead > 0 {
+		s.scheduleLead = lead
+	}
+	if maxAhead > 0 {
+		s.scheduleMaxAhead = maxAhead
+	}
+}
+
+// validateSchedule checks that scheduledFor, when set, falls inside [now+lead, now+maxAhead]
+func (s *Service) validateSchedule(scheduledFor *time.Time, now time.Time) error {
+	if scheduledFor == nil {
+		return nil
+	}
+	if scheduledFor.Before(now.Add(s.scheduleLead)) {
+		return fmt.Errorf("%w: must be at least %d minutes from now", ErrInvalidSchedule, int(s.scheduleLead.Minutes()))
+	}
+	if scheduledFor.After(now.Add(s.scheduleMaxAhead)) {
+		return fmt.Errorf("%w: must be within %d days", ErrInvalidSchedule, int(s.scheduleMaxAhead.Hours()/24))
+	}
+	return nil
+}
+
+// ReleaseScheduledOrder takes the stock for a scheduled order and moves it into the normal flow:
+// confirmed when it is already paid or cash on delivery, pending otherwise. If stock no longer
+// covers the order it is cancelled instead. Orders that are no longer scheduled are left alone;
+// the returned status is empty in that case.
+func (s *Service) ReleaseScheduledOrder(ctx context.Context, id uuid.UUID) (OrderStatus, error) {
+	var order Order
+	var next OrderStatus
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", id, OrderStatusScheduled).First(&order).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		var items []OrderItem
+		if err := tx.Where("order_id = ?", id).Find(&items).Error; err != nil {
+			return err
+		}
+		quantities := make(map[uuid.UUID]int)
+		for _, item := range items {
+			quantities[item.ProductID] += item.Quantity
+		}
+
+		// Lock every product first so the stock check and the decrement see the same numbers
+		locked := make(map[uuid.UUID]products.Product, len(quantities))
+		var shortfalls []string
+		for productID, quantity := range quantities {
+			var product products.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", productID).First(&product).Error; err != nil {
+				return fmt.Errorf("failed to get product %s: %w", productID, err)
+			}
+			if !product.IsActive || product.StockQuantity < quantity {
+				shortfalls = append(shortfalls, product.Name)
+			}
+			locked[productID] = product
+		}
+
+		fromStatus := OrderStatusScheduled
+		if len(shortfalls) > 0 {
+			next = OrderStatusCancelled
+			reason := fmt.Sprintf("Out of stock at the scheduled time: %s", strings.Join(shortfalls, ", "))
+			if err := tx.Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+				"status":              OrderStatusCancelled,
+				"cancellation_reason": reason,
+				"cancelled_at":        time.Now(),
+				"version":             gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&OrderStatusHistory{OrderID: id, FromStatus: &fromStatus, ToStatus: next, Note: reason}).Error
+		}
+
+		for productID, quantity := range quantities {
+			product := locked[productID]
+			newStock := product.StockQuantity - quantity
+			if err := tx.Model(&products.Product{}).Where("id = ?", productID).Update("stock_quantity", newStock).Error; err != nil {
+				return fmt.Errorf("failed to update stock for product %s: %w", productID, err)
+			}
+			if err := tx.Create(&products.StockHistory{
+				ProductID:        productID,
+				ChangeType:       "REMOVE",
+				QuantityChange:   quantity,
+				PreviousQuantity: product.StockQuantity,
+				NewQuantity:      newStock,
+				ReasonCode:       products.StockReasonSale,
+				Reason:           fmt.Sprintf("Scheduled order %s released", id),
+				CreatedBy:        order.CustomerID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		next = OrderStatusPending
+		if order.PaymentStatus == PaymentStatusPaid || order.PaymentMethod == PaymentMethodCashOnDelivery {
+			next = OrderStatusConfirmed
+		}
+		if err := tx.Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":               next,
+			"stock_reserved_until": stockReservationDeadline(next, order.PaymentMethod, s.stockReservationTTL, time.Now()),
+			"version":              gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&OrderStatusHistory{OrderID: id, FromStatus: &fromStatus, ToStatus: next, Note: "Released from schedule"}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if next != "" {
+		s.sendOrderStatusNotification(order.CustomerID, id, next)
+	}
+	return next, nil
+}
+
+// ScheduledOrderJob releases scheduled orders once they are within the lead time of their slot
+type ScheduledOrderJob struct {
+	svc      *Service
+	interval time.Duration
+	logger   *log.Logger
+}
+
+func NewScheduledOrderJob(svc *Service) *ScheduledOrderJob {
+	return &ScheduledOrderJob{
+		svc:      svc,
+		interval: time.Minute,
+		logger:   log.New(log.Writer(), "[SCHEDULED-ORDERS] ", log.LstdFlags),
+	}
+}
+
+// Start runs the job on a fixed interval until ctx is cancelled
+func (j *ScheduledOrderJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if released, err := j.RunOnce(ctx); err != nil {
+					j.logger.Printf("run failed: %v", err)
+				} else if released > 0 {
+					j.logger.Printf("released %d orders", released)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce releases every scheduled order that is due and returns how many were handled
+func (j *ScheduledOrderJob) RunOnce(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	err := j.svc.db.WithContext(ctx).Model(&Order{}).
+		Where("status = ? AND scheduled_for <= ?", OrderStatusScheduled, time.Now().Add(j.svc.scheduleLead)).
+		Order("scheduled_for ASC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due scheduled orders: %w", err)
+	}
+
+	released := 0
+	for _, id := range ids {
+		status, err := j.svc.ReleaseScheduledOrder(ctx, id)
+		if err != nil {
+			j.logger.Printf("failed to release order %s: %v", id, err)
+			continue
+		}
+		if status == OrderStatusCancelled {
+			j.logger.Printf("cancelled order %s: stock no longer covers it", id)
+		}
+		if status != "" {
+			released++
+		}
+	}
+
+	return released, nil
+}
@@ -4,7 +4,8 @@ import (
     "context"
     "errors"
     "fmt"
-    "strconv"
+    "math"
+    "strings"
     "time"
 
     "errandShop/internal/domain/auth"
@@ -14,11 +15,44 @@ import (
     "errandShop/internal/domain/notifications"
     "errandShop/internal/domain/custom_requests"
     "errandShop/internal/domain/payments"
+    "errandShop/internal/domain/webhooks"
     "errandShop/internal/core/types"
+    "errandShop/pkg/money"
     "github.com/google/uuid"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
+// ErrOrderNotEditable is returned when items are edited on an order that is no longer pending and unpaid
+var ErrOrderNotEditable = errors.New("order can only be edited while it is pending and unpaid")
+
+// ErrOrderItemNotFound is returned when a line item to remove is not on the order
+var ErrOrderItemNotFound = errors.New("item not found on this order")
+
+// ErrCancelRequiresSupport is returned when a customer tries to cancel an order past the self-cancel cutoff
+var ErrCancelRequiresSupport = errors.New("this order is too far along to cancel in the app; please contact support to cancel it")
+
+// ErrOrderVersionConflict is returned when an admin update is based on an order version that has since changed
+var ErrOrderVersionConflict = errors.New("order was changed by someone else; refetch it and try again")
+
+// ErrInvalidStatusTransition is returned when an order cannot move from its current status to the one requested
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+// ErrCustomerStatusChange is returned when a customer asks for any status change other than cancelling
+var ErrCustomerStatusChange = errors.New("customers can only cancel orders")
+
+// ErrCancelledOrderPayment is returned when an admin marks a cancelled order paid
+var ErrCancelledOrderPayment = errors.New("a cancelled order cannot be marked paid")
+
+// ErrInvalidSchedule is returned when an order's ScheduledFor is too soon or too far ahead
+var ErrInvalidSchedule = errors.New("invalid scheduled delivery time")
+
+// ErrOrderNotDelivered is returned when a customer confirms receipt of an order that has not been delivered
+var ErrOrderNotDelivered = errors.New("order has not been delivered yet")
+
+// ErrReceiptAlreadyConfirmed is returned when receipt of an order is confirmed a second time
+var ErrReceiptAlreadyConfirmed = errors.New("receipt of this order has already been confirmed")
+
 // Service interfaces
 type AuthServiceInterface interface {
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*auth.UserResponse, error)
@@ -30,6 +64,8 @@ type PaymentServiceInterface interface {
 
 type DeliveryServiceInterface interface {
 	CalculateDeliveryFee(distance float64, deliveryType string) int64
+	WeightSurcharge(weightGrams int) int64
+	ApplySurge(feeKobo int64, zoneID *int, at time.Time) (int64, float64)
 }
 
 type AddressRepoInterface interface {
@@ -51,8 +87,27 @@ type Service struct {
 	addressRepo AddressRepoInterface
 	deliveryMatcher DeliveryMatcherInterface
 	cartService *CartService
+	funnel FunnelRecorder // see SetFunnelRecorder
 	notificationService notifications.NotificationService
 	customRequestService custom_requests.Service
+	minOrderSubtotalKobo int64
+	customerCancelCutoff OrderStatus
+	idempotencyTTL time.Duration
+	duplicateOrderWindow time.Duration // see SetDuplicateOrderWindow
+	scheduleLead time.Duration
+	scheduleMaxAhead time.Duration
+	referralRewardKobo int64
+	vatRateBasisPoints int64
+	taxInclusive       bool // see SetTaxInclusive
+	stockReservationTTL time.Duration
+	refunder OrderRefunder
+	webhookDispatcher WebhookDispatcher
+	deliveryTracker DeliveryTracker
+	saleRecorder SaleRecorder
+	storeLocation *time.Location
+	emailVerifier EmailVerifier
+	requireVerifiedEmail bool
+	verificationResends *verificationResends
 	db          *gorm.DB
 }
 
@@ -70,10 +125,72 @@ func NewService(repo *Repository, productRepo *products.Repository, couponServic
 		cartService: NewCartService(db, productRepo),
 		notificationService: notificationService,
 		customRequestService: customRequestService,
+		customerCancelCutoff: OrderStatusPreparing,
+		idempotencyTTL: DefaultIdempotencyTTL,
+		duplicateOrderWindow: DefaultDuplicateOrderWindow,
+		scheduleLead: DefaultScheduleLead,
+		scheduleMaxAhead: DefaultScheduleMaxAhead,
+		stockReservationTTL: DefaultStockReservationTTL,
+		storeLocation: time.Local,
 		db:          db,
 	}
 }
 
+// SetMinOrderSubtotal sets the global minimum items subtotal (in kobo) applied when
+// the matched delivery zone has no override of its own. Zero disables the check.
+func (s *Service) SetMinOrderSubtotal(kobo int64) {
+	s.minOrderSubtotalKobo = kobo
+}
+
+// SetStoreLocation sets the timezone product availability schedules are checked in
+func (s *Service) SetStoreLocation(loc *time.Location) {
+	if loc != nil {
+		s.storeLocation = loc
+	}
+}
+
+// SetCustomerCancelCutoff sets the last status at which customers may cancel their own
+// orders (default "preparing"). Admin cancellation is not affected.
+func (s *Service) SetCustomerCancelCutoff(status OrderStatus) error {
+	if stageIndex(status) < 0 {
+		return fmt.Errorf("invalid customer cancel cutoff status: %q", status)
+	}
+	s.customerCancelCutoff = status
+	return nil
+}
+
+// SetIdempotencyTTL sets how long an idempotency key returns the order it created. Older keys
+// no longer match and are released for reuse. Non-positive values keep the default.
+func (s *Service) SetIdempotencyTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.idempotencyTTL = ttl
+	}
+}
+
+// SetDuplicateOrderWindow sets how long an order placed without an idempotency key is returned,
+// instead of a new order being created, when the customer submits an identical order again. Zero
+// turns the guard off.
+func (s *Service) SetDuplicateOrderWindow(window time.Duration) {
+	if window >= 0 {
+		s.duplicateOrderWindow = window
+	}
+}
+
+// SetVATRate sets the VAT charged on new orders, as a percentage (7.5 means 7.5%). Zero, the
+// default, charges no tax; negative values are ignored.
+func (s *Service) SetVATRate(percent float64) {
+	if percent >= 0 {
+		s.vatRateBasisPoints = int64(math.Round(percent * 100))
+	}
+}
+
+// SetTaxInclusive sets whether new orders are priced tax-inclusive: product prices and fees
+// already contain VAT, so it is backed out of the total rather than added. Orders keep the mode
+// they were placed in.
+func (s *Service) SetTaxInclusive(inclusive bool) {
+	s.taxInclusive = inclusive
+}
+
 type PageMeta struct {
 	Page       int   `json:"page"`
 	Limit      int   `json:"limit"`
@@ -96,19 +213,33 @@ func (s *Service) GetCart(ctx context.Context, userID uuid.UUID) (*CartResponse,
 }
 
 func (s *Service) AddToCart(ctx context.Context, userID uuid.UUID, req AddToCartRequest) (*CartResponse, error) {
-	cart, err := s.cartService.AddToCart(userID, req)
+	cart, warnings, err := s.cartService.AddToCart(userID, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add to cart: %w", err)
 	}
-	return s.toCartResponse(*cart), nil
+	response := s.toCartResponse(*cart)
+	response.Warnings = warnings
+	return response, nil
+}
+
+func (s *Service) MergeCart(ctx context.Context, userID uuid.UUID, req MergeCartRequest) (*CartResponse, error) {
+	cart, warnings, err := s.cartService.MergeCart(userID, req.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge cart: %w", err)
+	}
+	response := s.toCartResponse(*cart)
+	response.Warnings = warnings
+	return response, nil
 }
 
 func (s *Service) UpdateCartItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req UpdateCartItemRequest) (*CartResponse, error) {
-	cart, err := s.cartService.UpdateCartItem(userID, itemID, req)
+	cart, warnings, err := s.cartService.UpdateCartItem(userID, itemID, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update cart item: %w", err)
 	}
-	return s.toCartResponse(*cart), nil
+	response := s.toCartResponse(*cart)
+	response.Warnings = warnings
+	return response, nil
 }
 
 func (s *Service) RemoveFromCart(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) (*CartResponse, error) {
@@ -123,6 +254,93 @@ func (s *Service) ClearCart(ctx context.Context, userID uuid.UUID) error {
 	return s.cartService.ClearCart(userID)
 }
 
+// Reorder re-adds the still-available catalog items of a past order to the user's cart.
+// Inactive or out-of-stock products and custom-request lines are reported as skipped.
+func (s *Service) Reorder(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*ReorderResponse, error) {
+	order, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	skipped := []ReorderSkippedItem{}
+	for _, item := range order.Items {
+		if item.Source != "" && item.Source != "catalog" {
+			productID := item.ProductID
+			skipped = append(skipped, ReorderSkippedItem{
+				ProductID: &productID,
+				Name:      item.Name,
+				Quantity:  item.Quantity,
+				Reason:    "custom request items cannot be reordered",
+			})
+			continue
+		}
+
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("failed to get product: %w", err)
+			}
+			productID := item.ProductID
+			skipped = append(skipped, ReorderSkippedItem{
+				ProductID: &productID,
+				Name:      item.Name,
+				Quantity:  item.Quantity,
+				Reason:    "product is no longer available",
+			})
+			continue
+		}
+
+		if product.StockQuantity <= 0 {
+			skipped = append(skipped, ReorderSkippedItem{
+				ProductID: &product.ID,
+				Name:      product.Name,
+				Quantity:  item.Quantity,
+				Reason:    "out of stock",
+			})
+			continue
+		}
+
+		quantity := item.Quantity
+		if product.StockQuantity < quantity {
+			skipped = append(skipped, ReorderSkippedItem{
+				ProductID: &product.ID,
+				Name:      product.Name,
+				Quantity:  quantity - product.StockQuantity,
+				Reason:    fmt.Sprintf("only %d in stock", product.StockQuantity),
+			})
+			quantity = product.StockQuantity
+		}
+
+		if _, _, err := s.cartService.AddToCart(userID, AddToCartRequest{ProductID: product.ID, Quantity: quantity}); err != nil {
+			return nil, fmt.Errorf("failed to add to cart: %w", err)
+		}
+	}
+
+	// Custom requests are one-off quotes and cannot be cloned
+	for _, customRequestID := range order.CustomRequests {
+		crID := customRequestID
+		skipped = append(skipped, ReorderSkippedItem{
+			CustomRequestID: &crID,
+			Name:            "Custom request",
+			Quantity:        1,
+			Reason:          "custom requests cannot be reordered",
+		})
+	}
+
+	cart, err := s.cartService.GetCart(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	return &ReorderResponse{
+		Cart:    s.toCartResponse(*cart),
+		Skipped: skipped,
+	}, nil
+}
+
 // Order methods
 func (s *Service) List(ctx context.Context, userID uuid.UUID, query ListQuery) (*ListResult, error) {
 	if query.Page <= 0 {
@@ -187,9 +405,16 @@ func (s *Service) CreateFromCart(ctx context.Context, userID uuid.UUID, req Crea
 	// Create order request
 	createReq := CreateOrderRequest{
 		DeliveryAddressID: req.DeliveryAddressID,
+		PaymentMethod:     req.PaymentMethod,
 		Items:             orderItems,
 		CouponCode:        req.CouponCode,
+		AutoApplyCoupon:   req.AutoApplyCoupon,
+		TipKobo:           req.TipKobo,
 		Notes:             req.Notes,
+		Recipient:         req.Recipient,
+		Attribution:       req.Attribution,
+		ScheduledFor:      req.ScheduledFor,
+		StockShortfall:    req.StockShortfall,
 		IdempotencyKey:    req.IdempotencyKey,
 	}
 
@@ -213,26 +438,60 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 	if len(req.Items) == 0 && len(req.CustomRequests) == 0 {
 		return nil, fmt.Errorf("order must contain at least one item or custom request")
 	}
+	if req.TipKobo < 0 || req.TipKobo > MaxTipKobo {
+		return nil, fmt.Errorf("invalid tip: must be between 0 and %d kobo", MaxTipKobo)
+	}
+	if err := s.validateSchedule(req.ScheduledFor, time.Now()); err != nil {
+		return nil, err
+	}
+	deliveryAddressID, err := ParseDeliveryAddressID(req.DeliveryAddressID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailVerified(ctx, userID); err != nil {
+		return nil, err
+	}
 
-	// Check for duplicate order using idempotency key
-	if req.IdempotencyKey != "" {
-		existingOrder, err := s.repo.CheckIdempotency(ctx, userID, req.IdempotencyKey)
+	// Check for duplicate order using idempotency key. A retry within the TTL gets the original
+	// order back; the same key with a different payload is rejected; an expired key is released.
+	// Orders sent without a key are keyed by their contents for a short window instead, so a
+	// double tap returns the first order rather than placing the same order twice.
+	fingerprint := idempotencyFingerprint(req)
+	idempotencyKey, idempotencyTTL := req.IdempotencyKey, s.idempotencyTTL
+	guarded := idempotencyKey == "" && s.duplicateOrderWindow > 0
+	if guarded {
+		idempotencyKey, idempotencyTTL = duplicateGuardKey(fingerprint), s.duplicateOrderWindow
+	}
+	if idempotencyKey != "" {
+		existingOrder, err := s.repo.CheckIdempotency(ctx, userID, idempotencyKey)
 		if err != nil && err != gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("failed to check idempotency: %w", err)
 		}
 		if existingOrder != nil {
-			response := s.toOrderResponseWithContext(ctx, existingOrder)
-			return response, nil
+			switch {
+			case time.Since(existingOrder.CreatedAt) > idempotencyTTL, guarded && existingOrder.IsCancelled():
+				if err := s.repo.ReleaseIdempotencyKey(ctx, existingOrder.ID); err != nil {
+					return nil, fmt.Errorf("failed to release expired idempotency key: %w", err)
+				}
+			case existingOrder.IdempotencyFingerprint != "" && existingOrder.IdempotencyFingerprint != fingerprint:
+				return nil, ErrIdempotencyKeyReused
+			default:
+				if guarded {
+					logDuplicateOrderMerged(userID, existingOrder)
+				}
+				response := s.toOrderResponseWithContext(ctx, existingOrder)
+				return response, nil
+			}
 		}
 	}
 
 	// Validate and calculate total
 	var subtotalKobo int64
-	var customRequestsTotal int64
+	var weightGrams int // catalog items only; quotes price their own delivery
+	// Custom-request lines are priced from their quotes (see fees.go), which the order keeps a copy of
+	var quoteCharges []QuoteCharges
+	var quoteSnapshots QuoteSnapshotList
 	var orderItems []OrderItem
-	if len(req.Items) > 0 {
-		orderItems = make([]OrderItem, len(req.Items))
-	}
 
 	// Process custom requests if provided
 	if len(req.CustomRequests) > 0 {
@@ -257,11 +516,38 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 				return nil, fmt.Errorf("custom request %s has no active quote", customReq.CustomRequestID)
 			}
 
-			// Add the quote total to the custom requests total
-			customRequestsTotal += customRequest.ActiveQuote.GrandTotal
+			snapshot := newQuoteSnapshot(customReq.CustomRequestID, customRequest.ActiveQuote)
+			quoteCharges = append(quoteCharges, snapshot.Charges())
+			quoteSnapshots = append(quoteSnapshots, snapshot)
 		}
 	}
 
+	// Items short of stock fail the order unless the customer chose to remove or substitute them
+	items, stockAdjustments, err := s.resolveStockShortfalls(ctx, req.Items, req.StockShortfall)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 && len(req.CustomRequests) == 0 {
+		return nil, &StockShortfallError{Items: stockAdjustments}
+	}
+	req.Items = items
+	if len(req.Items) > 0 {
+		orderItems = make([]OrderItem, len(req.Items))
+	}
+
+	// Per-order quantity limits apply to a product's total across all of its lines
+	productQuantities := make(map[uuid.UUID]int, len(req.Items))
+	for _, item := range req.Items {
+		productQuantities[item.ProductID] += item.Quantity
+	}
+
+	// Scheduled products must be available when the order will be fulfilled, not just now
+	orderTime := time.Now()
+	if req.ScheduledFor != nil {
+		orderTime = *req.ScheduledFor
+	}
+	orderTime = orderTime.In(s.storeLocation)
+
 	for i, item := range req.Items {
 		// Get product to validate and get current price
 		product, err := s.productRepo.GetByID(ctx, item.ProductID)
@@ -272,35 +558,74 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 			return nil, fmt.Errorf("failed to get product: %w", err)
 		}
 
-		// Check stock availability
-		if product.StockQuantity < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d", product.Name, product.StockQuantity, item.Quantity)
+		// Check stock availability against the product's total across its lines
+		if product.StockQuantity < productQuantities[item.ProductID] {
+			return nil, shortOf(product, productQuantities[item.ProductID])
+		}
+		if err := product.CheckOrderQuantity(productQuantities[item.ProductID]); err != nil {
+			return nil, err
+		}
+		if err := product.CheckAvailability(orderTime); err != nil {
+			return nil, err
 		}
 
 		// Convert product price from naira to kobo
-		unitPriceKobo := int64(product.SellingPrice * 100)
+		unitPriceKobo := money.FromNaira(product.EffectivePrice()).Kobo()
 		itemTotal := unitPriceKobo * int64(item.Quantity)
 		subtotalKobo += itemTotal
+		weightGrams += product.WeightGrams * item.Quantity
 
 		orderItems[i] = OrderItem{
-			ProductID:  item.ProductID,
-			Name:       product.Name,
-			SKU:        product.SKU,
-			Quantity:   item.Quantity,
-			UnitPrice:  unitPriceKobo,
-			TotalPrice: itemTotal,
-			Source:     "catalog",
+			ProductID:     item.ProductID,
+			Name:          product.Name,
+			SKU:           product.SKU,
+			Quantity:      item.Quantity,
+			UnitPrice:     unitPriceKobo,
+			TotalPrice:    itemTotal,
+			UnitCost:      money.FromNaira(product.CostPrice).Kobo(),
+			Source:        "catalog",
+			SubstituteFor: item.SubstituteFor,
 		}
 	}
 
-	// Apply coupon if provided
-	var discountKobo int64
+	// Calculate delivery fee based on delivery zone
+	deliveryFeeKobo, surgeMultiplier, matchedZone, err := s.zoneDeliveryFee(userID, deliveryAddressID, weightGrams, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the fee policy for catalog, custom-only and mixed orders (see fees.go)
+	fees := CalculateOrderFees(OrderFeeInput{
+		CatalogSubtotal: subtotalKobo,
+		ZoneDeliveryFee: deliveryFeeKobo,
+		Quotes:          quoteCharges,
+	})
+
+	// Apply coupon if provided, or the user's best eligible coupon when they opted in to auto-apply.
+	// Coupons are checked against the priced delivery fee and zone; free delivery coupons come
+	// off the delivery fee line.
+	var discountKobo, deliveryDiscountKobo int64
+	couponAutoApplied := false
+	couponZone := couponZoneID(matchedZone)
+	if (req.CouponCode == nil || *req.CouponCode == "") && req.AutoApplyCoupon {
+		best, err := s.couponService.BestCouponForOrder(userID, subtotalKobo, fees.DeliveryFee, couponZone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find eligible coupon: %w", err)
+		}
+		if best != nil && best.Coupon != nil {
+			code := best.Coupon.Code
+			req.CouponCode = &code
+			couponAutoApplied = true
+		}
+	}
 	if req.CouponCode != nil && *req.CouponCode != "" {
 		// Validate coupon
 		validationReq := coupons.ValidateCouponRequest{
 			Code:        *req.CouponCode,
 			UserID:      userID,
-			OrderAmount: float64(subtotalKobo), // Keep in kobo format
+			OrderAmount: subtotalKobo,
+			DeliveryFee: fees.DeliveryFee,
+			ZoneID:      couponZone,
 		}
 		
 		validation, err := s.couponService.ValidateCoupon(validationReq)
@@ -312,75 +637,74 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 			return nil, fmt.Errorf("invalid coupon: %s", validation.Message)
 		}
 		
-		// Keep discount amount as is (already in kobo)
-		discountKobo = int64(validation.DiscountAmount)
+		discountKobo, deliveryDiscountKobo = applyCouponDiscount(&fees, validation)
 	}
 
-	// Calculate delivery fee based on delivery zone
-	var deliveryFeeKobo int64 = 0
-	if req.DeliveryAddressID != nil && *req.DeliveryAddressID != "" {
-		// Get the delivery address
-		address, err := s.addressRepo.GetByID(userID.String(), *req.DeliveryAddressID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get delivery address: %w", err)
-		}
-		
-		// Match address to delivery zone
-		fullAddress := address.Text
-		matchResult, noMatchResult := s.deliveryMatcher.MatchAddress(fullAddress)
-
-		if matchResult != nil {
-			// Use zone-based pricing
-			deliveryFeeKobo = int64(matchResult.Price * 100) // Convert to kobo
-		} else if noMatchResult != nil {
-			// Use fallback pricing for unmatched zones
-			deliveryFeeKobo = s.deliveryService.CalculateDeliveryFee(5.0, "standard")
-		} else {
-			// Default fallback
-			deliveryFeeKobo = s.deliveryService.CalculateDeliveryFee(5.0, "standard")
-		}
-	} else {
-		// No delivery address provided, use default
-		deliveryFeeKobo = s.deliveryService.CalculateDeliveryFee(5.0, "standard")
+	// Enforce the minimum order value for the matched zone (falls back to the global default)
+	// on the discounted items alone, so fees and tips cannot make up the minimum
+	if err := s.checkMinimumOrder(fees.ItemsSubtotal-discountKobo, matchedZone); err != nil {
+		return nil, err
 	}
-	
-	// Service fee (can be a fixed amount or percentage - using 5% of subtotal as example)
-	serviceFeeKobo := subtotalKobo * 5 / 100 // 5% service fee
 
-	// Calculate total including custom requests, delivery fee, and service fee
-	totalKobo := subtotalKobo + customRequestsTotal + deliveryFeeKobo + serviceFeeKobo - discountKobo
+	// VAT is charged on the discounted fees, never on the driver tip; tax-inclusive prices already contain it
+	taxKobo, addedTaxKobo := OrderTax(fees.Total()-discountKobo, s.vatRateBasisPoints, s.taxInclusive)
+
+	// Calculate total including custom requests, delivery fee, service fee, VAT and driver tip
+	totalKobo := fees.Total() + addedTaxKobo + req.TipKobo - discountKobo
 	if totalKobo < 0 {
 		totalKobo = 0
 	}
 
-	// Convert delivery address ID from string to uint
-	var deliveryAddressID *uint
-	if req.DeliveryAddressID != nil && *req.DeliveryAddressID != "" {
-		if addressID, err := strconv.ParseUint(*req.DeliveryAddressID, 10, 32); err != nil {
-			return nil, fmt.Errorf("invalid delivery address ID: %w", err)
-		} else {
-			uintAddressID := uint(addressID)
-			deliveryAddressID = &uintAddressID
-		}
+	status := OrderStatusPending
+	if req.ScheduledFor != nil {
+		status = OrderStatusScheduled
 	}
+	attribution := normalizeAttribution(req.Attribution)
 
 	order := &Order{
-		CustomerID:        userID,
-		DeliveryAddressID: deliveryAddressID,
-		Status:            OrderStatusPending,
-		PaymentStatus:     PaymentStatusUnpaid,
-		ItemsSubtotal:     subtotalKobo,
-		DeliveryFee:       deliveryFeeKobo,
-		ServiceFee:        serviceFeeKobo,
-		CouponDiscount:    discountKobo,
-		TotalAmount:       totalKobo,
-		CustomRequests:    extractCustomRequestIDs(req.CustomRequests),
-		CouponCode:        req.CouponCode,
-		Notes:             req.Notes,
-		IdempotencyKey:    req.IdempotencyKey,
+		CustomerID:              userID,
+		DeliveryAddressID:       deliveryAddressID,
+		Status:                  status,
+		PaymentStatus:           PaymentStatusUnpaid,
+		PaymentMethod:           req.PaymentMethod,
+		ItemsSubtotal:           fees.ItemsSubtotal,
+		DeliveryFee:             fees.DeliveryFee,
+		DeliveryDiscount:        deliveryDiscountKobo,
+		DeliverySurgeMultiplier: surgeMultiplier,
+		ServiceFee:              fees.ServiceFee,
+		TaxKobo:                 taxKobo,
+		TaxInclusive:            s.taxInclusive,
+		TipKobo:                 req.TipKobo,
+		CouponDiscount:          discountKobo,
+		TotalAmount:             totalKobo,
+		CustomRequests:          extractCustomRequestIDs(req.CustomRequests),
+		QuoteSnapshots:          quoteSnapshots,
+		CouponCode:              req.CouponCode,
+		CouponAutoApplied:       couponAutoApplied,
+		Notes:                   req.Notes,
+		RecipientName:           strings.TrimSpace(req.RecipientName),
+		RecipientPhone:          strings.TrimSpace(req.RecipientPhone),
+		DeliveryInstructions:    strings.TrimSpace(req.DeliveryInstructions),
+		UTMSource:               attribution.Source,
+		UTMCampaign:             attribution.Campaign,
+		UTMMedium:               attribution.Medium,
+		ScheduledFor:            req.ScheduledFor,
+		StockReservedUntil:      stockReservationDeadline(status, req.PaymentMethod, s.stockReservationTTL, time.Now()),
+		IdempotencyKey:          idempotencyKey,
+		IdempotencyFingerprint:  fingerprint,
+	}
+	if matchedZone != nil {
+		order.DeliveryZone = matchedZone.ZoneName
 	}
 
 	if err := s.repo.Create(ctx, order); err != nil {
+		if guarded && isDuplicateKeyError(err) {
+			// An identical submission was placed while this one was being priced
+			if existingOrder, findErr := s.repo.CheckIdempotency(ctx, userID, idempotencyKey); findErr == nil {
+				logDuplicateOrderMerged(userID, existingOrder)
+				return s.toOrderResponseWithContext(ctx, existingOrder), nil
+			}
+		}
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
 
@@ -397,17 +721,20 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 		}
 	}
 
-	// Update product stock
-	for _, item := range req.Items {
-		stockReq := products.StockUpdateRequest{
-			Quantity:   item.Quantity,
-			ChangeType: "REMOVE",
-			Reason:     "Order creation",
-		}
-		// userID is already uuid.UUID, use it directly
-		if _, err := s.productRepo.UpdateStock(ctx, item.ProductID, stockReq, userID); err != nil {
-			// Log error but don't fail the order creation
-			fmt.Printf("Warning: failed to update stock for product %s: %v\n", item.ProductID, err)
+	// Update product stock. Scheduled orders take their stock when they are released (see scheduled_orders.go)
+	if order.Status != OrderStatusScheduled {
+		for _, item := range req.Items {
+			stockReq := products.StockUpdateRequest{
+				Quantity:   item.Quantity,
+				ChangeType: "REMOVE",
+				ReasonCode: products.StockReasonSale,
+				Reason:     "Order creation",
+			}
+			// userID is already uuid.UUID, use it directly
+			if _, err := s.productRepo.UpdateStock(ctx, item.ProductID, stockReq, userID); err != nil {
+				// Log error but don't fail the order creation
+				fmt.Printf("Warning: failed to update stock for product %s: %v\n", item.ProductID, err)
+			}
 		}
 	}
 
@@ -417,7 +744,9 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 			Code:        *order.CouponCode,
 			UserID:      userID,
 			OrderID:     order.ID,
-			OrderAmount: float64(order.TotalAmount), // Keep in kobo format
+			OrderAmount: subtotalKobo, // Same base the discount was validated against
+			DeliveryFee: order.DeliveryFee + order.DeliveryDiscount,
+			ZoneID:      couponZone,
 		}
 		
 		if _, err := s.couponService.ApplyCoupon(applyReq); err != nil {
@@ -438,7 +767,13 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateOrderR
 		}
 	}
 
+	s.emitOrderEvent(webhooks.EventOrderCreated, order.ID)
+	if s.funnel != nil {
+		s.funnel.OrderPlaced(userID, order.ID)
+	}
+
 	response := s.toOrderResponseWithContext(ctx, order)
+	response.StockAdjustments = stockAdjustments
 	return response, nil
 }
 
@@ -469,92 +804,496 @@ func (s *Service) CreateWithPayment(ctx context.Context, userID uuid.UUID, req C
 		return nil, fmt.Errorf("failed to initialize payment: %w", err)
 	}
 
-	// Generate order number (simple implementation)
-	orderNumber := fmt.Sprintf("ORD-%06d", orderResponse.ID.ID()%1000000)
-
 	// Create response with payment information
 	response := &CreateOrderResponse{
 		OrderID:     orderResponse.ID,
-		OrderNumber: orderNumber,
+		OrderNumber: orderNumber(orderResponse.ID),
 		Payment: PaymentInfo{
 			Provider:   string(req.PaymentMethod),
 			Reference:  paymentInit.TransactionRef,
 			PaymentURL: paymentInit.PaymentURL,
 		},
+		StockAdjustments: orderResponse.StockAdjustments,
 	}
 
 	return response, nil
 }
 
+// UpdateStatus changes the status of the customer's own order. Customers may only cancel, which
+// goes through CancelOrder so the self-cancel cutoff, restock and refund apply; every other step,
+// and the referral and funnel rewards for delivery, is recorded by staff.
 func (s *Service) UpdateStatus(ctx context.Context, id uuid.UUID, userID uuid.UUID, status OrderStatus) error {
+	if status != OrderStatusCancelled {
+		return ErrCustomerStatusChange
+	}
+	_, err := s.CancelOrder(ctx, id, userID, "", "")
+	return err
+}
+
+// CancelOrder cancels the customer's own order and puts its stock back. A paid order is refunded,
+// as store credit unless refundMethod asks for the original payment; the refund is returned.
+func (s *Service) CancelOrder(ctx context.Context, id uuid.UUID, userID uuid.UUID, reason string, refundMethod RefundMethod) (*OrderRefundResponse, error) {
 	// Get the order first to validate ownership and current status
 	order, err := s.repo.Get(ctx, id, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("order not found")
+			return nil, fmt.Errorf("order not found")
 		}
-		return fmt.Errorf("failed to get order: %w", err)
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	// Validate status transition
-	if !s.isValidStatusTransition(order.Status, status) {
-		return fmt.Errorf("invalid status transition from %s to %s", order.Status, status)
+	// Check if order can be cancelled
+	if order.Status == OrderStatusDelivered || order.Status == OrderStatusCancelled {
+		return nil, fmt.Errorf("order cannot be cancelled in %s status", order.Status)
+	}
+	if stageIndex(order.Status) > stageIndex(s.customerCancelCutoff) {
+		return nil, ErrCancelRequiresSupport
 	}
 
-	// Update the status
-	if err := s.repo.UpdateStatus(ctx, id, userID, status, ""); err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+	// Cancel the order
+	if err := s.repo.CancelOrder(ctx, id, userID, reason); err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	s.emitOrderEvent(webhooks.EventOrderCancelled, id)
+
+	// Restore product stock; a still-scheduled order never took any
+	if order.Status != OrderStatusScheduled {
+		for _, item := range order.Items {
+			stockReq := products.StockUpdateRequest{
+				Quantity:   item.Quantity,
+				ChangeType: "ADD",
+				ReasonCode: products.StockReasonCancellation,
+				Reason:     "Order cancellation",
+			}
+			// userID is already uuid.UUID, use it directly
+			if _, err := s.productRepo.UpdateStock(ctx, item.ProductID, stockReq, userID); err != nil {
+				fmt.Printf("Warning: failed to restore stock for product %s: %v\n", item.ProductID, err)
+			}
+		}
 	}
+	
+	// TODO: Implement coupon usage restoration if needed
+	// if order.CouponCode != nil {
+	//     // Restore coupon usage
+	// }
 
-	// Send notification about order status change
-	s.sendOrderStatusNotification(order.CustomerID, id, status)
+	// Customers who cancel get store credit unless they ask for their money back
+	if refundMethod == "" {
+		refundMethod = RefundToStoreCredit
+	}
+	return s.refundOnCancel(ctx, order, refundMethod, reason), nil
+}
 
-	return nil
+// ConfirmReceived records the customer's acknowledgement that a delivered order arrived. The
+// timestamp is kept apart from DeliveredAt, which is set by staff. Cash-on-delivery orders are
+// marked paid here, and the customer is prompted to review the order.
+func (s *Service) ConfirmReceived(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*OrderResponse, error) {
+	order, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order.Status != OrderStatusDelivered {
+		return nil, ErrOrderNotDelivered
+	}
+	if order.ReceivedAt != nil {
+		return nil, ErrReceiptAlreadyConfirmed
+	}
+
+	markPaid := order.PaymentMethod == PaymentMethodCashOnDelivery && order.PaymentStatus != PaymentStatusPaid
+	confirmed, err := s.repo.ConfirmReceived(ctx, id, userID, markPaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm receipt: %w", err)
+	}
+	if !confirmed {
+		return nil, ErrReceiptAlreadyConfirmed
+	}
+	if markPaid {
+		s.orderPaid(id)
+	}
+
+	s.sendReviewPrompt(order.CustomerID, id)
+
+	updated, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return s.toOrderResponseWithContext(ctx, updated), nil
 }
 
-func (s *Service) CancelOrder(ctx context.Context, id uuid.UUID, userID uuid.UUID, reason string) error {
-	// Get the order first to validate ownership and current status
+// UpdateItems adds, removes or re-quantifies catalog items on the caller's pending, unpaid order.
+// Existing lines keep the price they were ordered at; new lines use the current price. Totals,
+// stock and the applied coupon are recalculated, and items, stock and totals change atomically.
+func (s *Service) UpdateItems(ctx context.Context, id uuid.UUID, userID uuid.UUID, req UpdateOrderItemsRequest) (*OrderResponse, error) {
 	order, err := s.repo.Get(ctx, id, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("order not found")
+			return nil, fmt.Errorf("order not found")
 		}
-		return fmt.Errorf("failed to get order: %w", err)
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status != OrderStatusPending || order.PaymentStatus != PaymentStatusUnpaid {
+		return nil, ErrOrderNotEditable
+	}
+
+	// Work out the final lines: existing lines by product, then apply the requested quantities
+	lines := make(map[uuid.UUID]*OrderItem)
+	oldQuantities := make(map[uuid.UUID]int)
+	unitWeights := make(map[uuid.UUID]int)
+	catalog := make(map[uuid.UUID]products.Product) // for the per-order limits on the final lines
+	var oldSubtotalKobo int64
+	for i := range order.Items {
+		item := order.Items[i]
+		unitWeights[item.ProductID] = item.Product.WeightGrams
+		catalog[item.ProductID] = item.Product
+		item.Product = products.Product{} // don't let gorm upsert the preloaded product when lines are re-saved
+		if line, ok := lines[item.ProductID]; ok {
+			line.Quantity += item.Quantity
+			line.TotalPrice += item.TotalPrice
+		} else {
+			lines[item.ProductID] = &item
+		}
+		oldQuantities[item.ProductID] += item.Quantity
+		oldSubtotalKobo += item.TotalPrice
+	}
+	for _, change := range req.Items {
+		line, exists := lines[change.ProductID]
+		if change.Quantity == 0 {
+			delete(lines, change.ProductID)
+			continue
+		}
+		if !exists {
+			product, err := s.productRepo.GetByID(ctx, change.ProductID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, fmt.Errorf("product with ID %s not found", change.ProductID)
+				}
+				return nil, fmt.Errorf("failed to get product: %w", err)
+			}
+			unitWeights[product.ID] = product.WeightGrams
+			catalog[product.ID] = *product
+			line = &OrderItem{
+				OrderID:   order.ID,
+				ProductID: product.ID,
+				Name:      product.Name,
+				SKU:       product.SKU,
+				UnitPrice: money.FromNaira(product.EffectivePrice()).Kobo(),
+				UnitCost:  money.FromNaira(product.CostPrice).Kobo(),
+				Source:    "catalog",
+			}
+			lines[change.ProductID] = line
+		}
+		line.Quantity = change.Quantity
+		line.TotalPrice = line.UnitPrice * int64(change.Quantity)
 	}
 
-	// Check if order can be cancelled
-	if order.Status == OrderStatusDelivered || order.Status == OrderStatusCancelled {
-		return fmt.Errorf("order cannot be cancelled in %s status", order.Status)
+	if len(lines) == 0 && len(order.CustomRequests) == 0 {
+		return nil, fmt.Errorf("order must contain at least one item or custom request; cancel the order instead")
 	}
 
-	// Cancel the order
-	if err := s.repo.CancelOrder(ctx, id, userID, reason); err != nil {
-		return fmt.Errorf("failed to cancel order: %w", err)
+	// Per-order quantity limits apply to each product's final quantity, as at checkout. Added or
+	// increased lines must also be on sale when the order will be fulfilled.
+	orderTime := time.Now()
+	if order.ScheduledFor != nil {
+		orderTime = *order.ScheduledFor
+	}
+	orderTime = orderTime.In(s.storeLocation)
+	for productID, line := range lines {
+		product := catalog[productID]
+		if err := product.CheckOrderQuantity(line.Quantity); err != nil {
+			return nil, err
+		}
+		if line.Quantity > oldQuantities[productID] {
+			if err := product.CheckAvailability(orderTime); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	// Restore product stock
+	var subtotalKobo int64
+	var weightGrams int
+	for productID, line := range lines {
+		subtotalKobo += line.TotalPrice
+		weightGrams += unitWeights[productID] * line.Quantity
+	}
+
+	// Re-price with the same fee policy used at checkout, keeping the surge the order was placed in
+	deliveryFeeKobo, surgeMultiplier, matchedZone, err := s.zoneDeliveryFee(userID, order.DeliveryAddressID, weightGrams, order.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	quoteCharges, _, err := s.quoteChargesForOrder(userID, order)
+	if err != nil {
+		return nil, err
+	}
+	fees := CalculateOrderFees(OrderFeeInput{
+		CatalogSubtotal: subtotalKobo,
+		ZoneDeliveryFee: deliveryFeeKobo,
+		Quotes:          quoteCharges,
+	})
+
+	// The coupon stays applied only while the new amount still qualifies
+	var discountKobo, deliveryDiscountKobo int64
+	if order.CouponCode != nil && *order.CouponCode != "" {
+		validation, err := s.couponService.RepriceOrderCoupon(*order.CouponCode, order.ID, subtotalKobo, fees.DeliveryFee)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate coupon: %w", err)
+		}
+		if !validation.Valid {
+			return nil, fmt.Errorf("invalid coupon: %s", validation.Message)
+		}
+		discountKobo, deliveryDiscountKobo = applyCouponDiscount(&fees, validation)
+	}
+	if err := s.checkMinimumOrder(fees.ItemsSubtotal-discountKobo, matchedZone); err != nil {
+		return nil, err
+	}
+
+	taxKobo, addedTaxKobo := OrderTax(fees.Total()-discountKobo, s.vatRateBasisPoints, order.TaxInclusive)
+	totalKobo := fees.Total() + addedTaxKobo + order.TipKobo + order.AdjustmentKobo - discountKobo
+	if totalKobo < 0 {
+		totalKobo = 0
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the order and make sure it wasn't confirmed or paid while we were pricing
+		var current Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND customer_id = ?", order.ID, userID).First(&current).Error; err != nil {
+			return err
+		}
+		if current.Status != OrderStatusPending || current.PaymentStatus != PaymentStatusUnpaid {
+			return ErrOrderNotEditable
+		}
+
+		// Move stock by the difference between old and new quantities
+		productIDs := make(map[uuid.UUID]struct{})
+		for productID := range oldQuantities {
+			productIDs[productID] = struct{}{}
+		}
+		for productID := range lines {
+			productIDs[productID] = struct{}{}
+		}
+		for productID := range productIDs {
+			newQuantity := 0
+			if line, ok := lines[productID]; ok {
+				newQuantity = line.Quantity
+			}
+			if err := adjustStockForEdit(tx, productID, oldQuantities[productID], newQuantity, order.ID, userID); err != nil {
+				return err
+			}
+		}
+
+		// Replace the order's lines
+		if err := tx.Where("order_id = ?", order.ID).Delete(&OrderItem{}).Error; err != nil {
+			return fmt.Errorf("failed to update order items: %w", err)
+		}
+		items := make([]OrderItem, 0, len(lines))
+		for _, line := range lines {
+			line.ID = uuid.Nil
+			line.OrderID = order.ID
+			items = append(items, *line)
+		}
+		if len(items) > 0 {
+			if err := tx.Create(&items).Error; err != nil {
+				return fmt.Errorf("failed to update order items: %w", err)
+			}
+		}
+
+		return tx.Model(&Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+			"items_subtotal":            fees.ItemsSubtotal,
+			"delivery_fee":              fees.DeliveryFee,
+			"delivery_discount":         deliveryDiscountKobo,
+			"delivery_surge_multiplier": surgeMultiplier,
+			"service_fee":               fees.ServiceFee,
+			"coupon_discount":           discountKobo,
+			"tax_kobo":                  taxKobo,
+			"total_amount":              totalKobo,
+			"version":                   gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		// Put the coupon usage back to the amount that is still on the order
+		if order.CouponCode != nil && *order.CouponCode != "" {
+			s.couponService.RepriceOrderCoupon(*order.CouponCode, order.ID, oldSubtotalKobo, order.DeliveryFee+order.DeliveryDiscount)
+		}
+		return nil, err
+	}
+
+	return s.Get(ctx, order.ID, userID)
+}
+
+// RemoveItem drops one line item from the caller's pending, unpaid order. The line's stock is put
+// back and fees, coupon and total are recalculated as in UpdateItems; removing the order's last
+// item, with no custom requests left either, cancels the whole order instead.
+func (s *Service) RemoveItem(ctx context.Context, id uuid.UUID, userID uuid.UUID, itemID uuid.UUID) (*OrderResponse, error) {
+	order, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status != OrderStatusPending || order.PaymentStatus != PaymentStatusUnpaid {
+		return nil, ErrOrderNotEditable
+	}
+
+	var removed *OrderItem
+	remaining := 0 // of the removed line's product, on its other lines
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			removed = &order.Items[i]
+		}
+	}
+	if removed == nil {
+		return nil, ErrOrderItemNotFound
+	}
 	for _, item := range order.Items {
-		stockReq := products.StockUpdateRequest{
-			Quantity:   item.Quantity,
-			ChangeType: "ADD",
-			Reason:     "Order cancellation",
+		if item.ID != itemID && item.ProductID == removed.ProductID {
+			remaining += item.Quantity
 		}
-		// userID is already uuid.UUID, use it directly
-		if _, err := s.productRepo.UpdateStock(ctx, item.ProductID, stockReq, userID); err != nil {
-			fmt.Printf("Warning: failed to restore stock for product %s: %v\n", item.ProductID, err)
+	}
+
+	if len(order.Items) == 1 && len(order.CustomRequests) == 0 {
+		if _, err := s.CancelOrder(ctx, id, userID, "Last item removed by customer", ""); err != nil {
+			return nil, err
 		}
+		return s.Get(ctx, id, userID)
 	}
-	
-	// TODO: Implement coupon usage restoration if needed
-	// if order.CouponCode != nil {
-	//     // Restore coupon usage
-	// }
 
-	return nil
+	return s.UpdateItems(ctx, id, userID, UpdateOrderItemsRequest{
+		Items: []OrderItemChange{{ProductID: removed.ProductID, Quantity: remaining}},
+	})
+}
+
+// adjustStockForEdit moves a product's stock by the change in ordered quantity and records it
+// in the stock history, failing if an increase is not available
+func adjustStockForEdit(tx *gorm.DB, productID uuid.UUID, oldQuantity, newQuantity int, orderID, userID uuid.UUID) error {
+	delta := newQuantity - oldQuantity
+	if delta == 0 {
+		return nil
+	}
+
+	var product products.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", productID).First(&product).Error; err != nil {
+		return fmt.Errorf("failed to get product %s: %w", productID, err)
+	}
+	if delta > 0 && product.StockQuantity < delta {
+		return fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d", product.Name, product.StockQuantity, delta)
+	}
+
+	changeType, reasonCode := "REMOVE", products.StockReasonSale
+	if delta < 0 {
+		changeType, reasonCode = "ADD", products.StockReasonCancellation
+	}
+	newStock := product.StockQuantity - delta
+	if err := tx.Model(&products.Product{}).Where("id = ?", productID).Update("stock_quantity", newStock).Error; err != nil {
+		return fmt.Errorf("failed to update stock for product %s: %w", productID, err)
+	}
+
+	return tx.Create(&products.StockHistory{
+		ProductID:        productID,
+		ChangeType:       changeType,
+		QuantityChange:   -delta,
+		PreviousQuantity: product.StockQuantity,
+		NewQuantity:      newStock,
+		ReasonCode:       reasonCode,
+		Reason:           fmt.Sprintf("Order %s edited", orderID),
+		CreatedBy:        userID,
+	}).Error
+}
+
+// quoteChargesForOrder returns what an order's custom requests charge for re-pricing: the quotes
+// snapshotted when it was placed or, for orders placed before snapshots, the current active quotes
+func (s *Service) quoteChargesForOrder(userID uuid.UUID, order *Order) ([]QuoteCharges, int64, error) {
+	var charges []QuoteCharges
+	var total int64
+	if len(order.QuoteSnapshots) > 0 {
+		for _, snapshot := range order.QuoteSnapshots {
+			total += snapshot.GrandTotal
+			charges = append(charges, snapshot.Charges())
+		}
+		return charges, total, nil
+	}
+	for _, requestID := range order.CustomRequests {
+		customRequest, err := s.customRequestService.GetCustomRequest(userID, requestID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get custom request %s: %w", requestID, err)
+		}
+		if customRequest.ActiveQuote == nil {
+			return nil, 0, fmt.Errorf("custom request %s has no active quote; contact support to change this order", requestID)
+		}
+		quote := customRequest.ActiveQuote
+		total += quote.GrandTotal
+		charges = append(charges, QuoteCharges{
+			ItemsSubtotal: quote.ItemsSubtotal,
+			DeliveryFee:   quote.Fees.Delivery,
+			ServiceFee:    quote.Fees.Service + quote.Fees.Packaging,
+		})
+	}
+	return charges, total, nil
+}
+
+// zoneDeliveryFee prices delivery for the address' matched zone, falling back to the
+// standard fee when there is no address or no zone matches. Any surge window in effect at
+// the given time raises that fee, and heavy orders (weightGrams of catalog items) pay the
+// delivery service's weight surcharge on top. The surge multiplier applied is returned too.
+func (s *Service) zoneDeliveryFee(userID uuid.UUID, deliveryAddressID *uint, weightGrams int, at time.Time) (int64, float64, *types.MatchResult, error) {
+	surcharge := s.deliveryService.WeightSurcharge(weightGrams)
+
+	if deliveryAddressID == nil {
+		// No delivery address provided, use default
+		fee, surge := s.deliveryService.ApplySurge(s.deliveryService.CalculateDeliveryFee(5.0, "standard"), nil, at)
+		return fee + surcharge, surge, nil, nil
+	}
+
+	// Get the delivery address, which must belong to the ordering user
+	address, err := s.ownedDeliveryAddress(userID, *deliveryAddressID)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// Match address to delivery zone
+	if matchResult, _ := s.deliveryMatcher.MatchAddress(address.Text); matchResult != nil {
+		// Use zone-based pricing
+		fee, surge := s.deliveryService.ApplySurge(int64(matchResult.Price*100), &matchResult.ZoneID, at) // Convert to kobo
+		return fee + surcharge, surge, matchResult, nil
+	}
+
+	// Use fallback pricing for unmatched zones
+	fee, surge := s.deliveryService.ApplySurge(s.deliveryService.CalculateDeliveryFee(5.0, "standard"), nil, at)
+	return fee + surcharge, surge, nil, nil
+}
+
+// checkMinimumOrder rejects orders whose items subtotal, after any coupon discount, is below the
+// zone minimum, telling the customer how much more they need to add.
+func (s *Service) checkMinimumOrder(subtotalKobo int64, zone *types.MatchResult) error {
+	minimumKobo := s.minOrderSubtotalKobo
+	zoneName := ""
+	if zone != nil {
+		zoneName = zone.ZoneName
+		if zone.MinOrder > 0 {
+			minimumKobo = int64(zone.MinOrder) * 100
+		}
+	}
+
+	if minimumKobo <= 0 || subtotalKobo >= minimumKobo {
+		return nil
+	}
+
+	shortfall := minimumKobo - subtotalKobo
+	if zoneName != "" {
+		return fmt.Errorf("minimum order for %s is %s; add %s more to place this order", zoneName, money.Money(minimumKobo), money.Money(shortfall))
+	}
+	return fmt.Errorf("minimum order is %s; add %s more to place this order", money.Money(minimumKobo), money.Money(shortfall))
 }
 
 func (s *Service) isValidStatusTransition(currentStatus, newStatus OrderStatus) bool {
 	switch currentStatus {
+	case OrderStatusScheduled:
+		return newStatus == OrderStatusCancelled // released only by ScheduledOrderJob, which takes the stock
 	case OrderStatusPending:
 		return newStatus == OrderStatusConfirmed || newStatus == OrderStatusCancelled
 	case OrderStatusConfirmed:
@@ -586,7 +1325,7 @@ func (s *Service) AdminList(ctx context.Context, query AdminListQuery) (*ListRes
 
 	responses := make([]OrderResponse, len(orders))
 	for i, order := range orders {
-		responses[i] = *s.toOrderResponseWithContext(ctx, &order)
+		responses[i] = *s.toAdminOrderResponse(ctx, &order)
 	}
 
 	totalPages := int((total + int64(query.Limit) - 1) / int64(query.Limit))
@@ -611,24 +1350,38 @@ func (s *Service) AdminGet(ctx context.Context, id uuid.UUID) (*OrderResponse, e
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	response := s.toOrderResponseWithContext(ctx, order)
+	response := s.toAdminOrderResponse(ctx, order)
 	return response, nil
 }
 
-func (s *Service) AdminUpdateStatus(ctx context.Context, id uuid.UUID, status OrderStatus) error {
+// AdminUpdateStatus sets an order's status. expectedVersion is the version the admin saw; when it is
+// stale, or the order changes between the read and the write, ErrOrderVersionConflict is returned.
+func (s *Service) AdminUpdateStatus(ctx context.Context, id uuid.UUID, status OrderStatus, expectedVersion *int64) error {
 	// Get the order first to get customer ID for notification
 	order, err := s.repo.AdminGet(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get order: %w", err)
 	}
+	if expectedVersion != nil && *expectedVersion != order.Version {
+		return ErrOrderVersionConflict
+	}
+	// Scheduled orders take their stock only when ScheduledOrderJob releases them, so they cannot
+	// be moved on by hand
+	if !s.isValidStatusTransition(order.Status, status) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, order.Status, status)
+	}
 
 	// Update the status
-	if err := s.repo.AdminUpdateStatus(ctx, id, status); err != nil {
+	if err := s.repo.AdminUpdateStatus(ctx, id, status, order.Version); err != nil {
 		return err
 	}
 
 	// Send notification about order status change
 	s.sendOrderStatusNotification(order.CustomerID, id, status)
+	if status == OrderStatusDelivered {
+		s.creditReferral(ctx, order.CustomerID)
+		s.orderDelivered(id)
+	}
 
 	return nil
 }
@@ -650,11 +1403,56 @@ func (s *Service) AdminUpdatePaymentStatus(ctx context.Context, id uuid.UUID, pa
 	default:
 		internalStatus = PaymentStatusPaid // default fallback
 	}
-	return s.repo.AdminUpdatePaymentStatus(ctx, id, internalStatus)
+
+	// Payment callbacks can repeat, so only the first switch to paid emits order.paid
+	wasPaid := false
+	if order, err := s.repo.AdminGet(ctx, id); err == nil {
+		wasPaid = order.PaymentStatus == PaymentStatusPaid
+	}
+	if internalStatus == PaymentStatusPaid {
+		// A cancelled order is never marked paid: a payment that lands after cancellation, e.g.
+		// once its stock reservation expired, is refunded instead
+		applied, err := s.repo.MarkPaid(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			return s.refundLatePayment(ctx, id)
+		}
+	} else if err := s.repo.AdminUpdatePaymentStatus(ctx, id, internalStatus, nil); err != nil {
+		return err
+	}
+	if internalStatus == PaymentStatusPaid && !wasPaid {
+		s.orderPaid(id)
+	}
+	return nil
 }
 
-func (s *Service) AdminCancelOrder(ctx context.Context, id uuid.UUID, reason string) error {
-	// Get the order first to validate current status
+// AdminSetPaymentStatus is the admin endpoint's payment status update, guarded like AdminUpdateStatus
+func (s *Service) AdminSetPaymentStatus(ctx context.Context, id uuid.UUID, paymentStatus PaymentStatus, expectedVersion *int64) error {
+	order, err := s.repo.AdminGet(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	if expectedVersion != nil && *expectedVersion != order.Version {
+		return ErrOrderVersionConflict
+	}
+	// A cancelled order never ships, so it is not marked paid; late payments are refunded instead
+	if paymentStatus == PaymentStatusPaid && order.Status == OrderStatusCancelled {
+		return ErrCancelledOrderPayment
+	}
+	if err := s.repo.AdminUpdatePaymentStatus(ctx, id, paymentStatus, &order.Version); err != nil {
+		return err
+	}
+	if paymentStatus == PaymentStatusPaid && order.PaymentStatus != PaymentStatusPaid {
+		s.orderPaid(id)
+	}
+	return nil
+}
+
+// ConfirmPaidOrder is called from the payment success path. A pending order that is now paid
+// moves to confirmed and the customer is notified; cancelled or already-progressed orders are left alone.
+func (s *Service) ConfirmPaidOrder(ctx context.Context, id uuid.UUID) error {
 	order, err := s.repo.AdminGet(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -663,37 +1461,128 @@ func (s *Service) AdminCancelOrder(ctx context.Context, id uuid.UUID, reason str
 		return fmt.Errorf("failed to get order: %w", err)
 	}
 
+	if order.Status == OrderStatusCancelled {
+		fmt.Printf("Warning: payment received for cancelled order %s; it is refunded, not applied\n", id)
+		return nil
+	}
+	if order.Status != OrderStatusPending || !s.isValidStatusTransition(order.Status, OrderStatusConfirmed) {
+		return nil
+	}
+
+	confirmed, err := s.repo.ConfirmPaidOrder(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to confirm order: %w", err)
+	}
+	if confirmed {
+		s.sendOrderStatusNotification(order.CustomerID, id, OrderStatusConfirmed)
+	}
+
+	return nil
+}
+
+// AdminCancelOrder cancels any order and puts its stock back. A paid order is refunded to its
+// original payment unless refundMethod asks for store credit; the refund is returned.
+func (s *Service) AdminCancelOrder(ctx context.Context, id uuid.UUID, reason string, refundMethod RefundMethod) (*OrderRefundResponse, error) {
+	// Get the order first to validate current status
+	order, err := s.repo.AdminGet(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
 	// Check if order can be cancelled
 	if order.Status == OrderStatusDelivered || order.Status == OrderStatusCancelled {
-		return fmt.Errorf("order cannot be cancelled in %s status", order.Status)
+		return nil, fmt.Errorf("order cannot be cancelled in %s status", order.Status)
 	}
 
 	// Cancel the order using admin method
 	if err := s.repo.AdminCancelOrder(ctx, id, reason); err != nil {
-		return fmt.Errorf("failed to cancel order: %w", err)
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	s.emitOrderEvent(webhooks.EventOrderCancelled, id)
+
+	// Restore product stock; a still-scheduled order never took any
+	if order.Status != OrderStatusScheduled {
+		for _, item := range order.Items {
+			stockReq := products.StockUpdateRequest{
+				Quantity:   item.Quantity,
+				ChangeType: "ADD",
+				ReasonCode: products.StockReasonCancellation,
+				Reason:     "Order cancellation by admin",
+			}
+			// Use a system UUID for admin operations
+			systemUserID := uuid.MustParse("00000000-0000-0000-0000-000000000000")
+			if _, err := s.productRepo.UpdateStock(ctx, item.ProductID, stockReq, systemUserID); err != nil {
+				fmt.Printf("Warning: failed to restore stock for product %s: %v\n", item.ProductID, err)
+			}
+		}
 	}
 
-	// Restore product stock
-	for _, item := range order.Items {
-		stockReq := products.StockUpdateRequest{
-			Quantity:   item.Quantity,
-			ChangeType: "ADD",
-			Reason:     "Order cancellation by admin",
-		}
-		// Use a system UUID for admin operations
-		systemUserID := uuid.MustParse("00000000-0000-0000-0000-000000000000")
-		if _, err := s.productRepo.UpdateStock(ctx, item.ProductID, stockReq, systemUserID); err != nil {
-			fmt.Printf("Warning: failed to restore stock for product %s: %v\n", item.ProductID, err)
-		}
+	if refundMethod == "" {
+		refundMethod = RefundToOriginalPayment
+	}
+	return s.refundOnCancel(ctx, order, refundMethod, reason), nil
+}
+
+// GetStats returns order stats for the query window. When a start date is given the
+// window is also compared with the immediately preceding period of the same length.
+func (s *Service) GetStats(ctx context.Context, query OrderStatsQuery) (*OrderStatsResponse, error) {
+	if query.DateFrom != nil && query.DateTo == nil {
+		now := time.Now()
+		query.DateTo = &now
+	}
+	if query.DateFrom != nil && query.DateTo != nil && query.DateTo.Before(*query.DateFrom) {
+		return nil, fmt.Errorf("end date must be after start date")
 	}
 
-	return nil
+	stats, err := s.repo.GetStats(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order stats: %w", err)
+	}
+
+	response := &OrderStatsResponse{
+		OrderStats: *stats,
+		Start:      query.DateFrom,
+		End:        query.DateTo,
+	}
+	if query.DateFrom == nil {
+		return response, nil
+	}
+
+	// Prior period ends just before the current one starts
+	length := query.DateTo.Sub(*query.DateFrom)
+	prevEnd := query.DateFrom.Add(-time.Microsecond)
+	prevStart := prevEnd.Add(-length)
+	prevQuery := OrderStatsQuery{DateFrom: &prevStart, DateTo: &prevEnd, UserID: query.UserID}
+
+	previous, err := s.repo.GetStats(ctx, prevQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous period stats: %w", err)
+	}
+
+	response.PreviousStart = &prevStart
+	response.PreviousEnd = &prevEnd
+	response.Previous = previous
+	response.Delta = &OrderStatsDelta{
+		TotalOrders:          stats.TotalOrders - previous.TotalOrders,
+		TotalOrdersPct:       percentChange(previous.TotalOrders, stats.TotalOrders),
+		TotalRevenue:         stats.TotalRevenue - previous.TotalRevenue,
+		TotalRevenuePct:      percentChange(previous.TotalRevenue, stats.TotalRevenue),
+		AverageOrderValue:    stats.AverageOrderValue - previous.AverageOrderValue,
+		AverageOrderValuePct: percentChange(previous.AverageOrderValue, stats.AverageOrderValue),
+	}
+
+	return response, nil
 }
 
-func (s *Service) GetStats(ctx context.Context) (*OrderStats, error) {
-	// Create empty query for all stats
-	query := OrderStatsQuery{}
-	return s.repo.GetStats(ctx, query)
+// percentChange returns the change from previous to current as a percentage (0 when previous is 0)
+func percentChange(previous, current int64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return math.Round(float64(current-previous)/float64(previous)*10000) / 100
 }
 
 // Helper methods
@@ -715,11 +1604,12 @@ func (s *Service) toCartResponse(cart Cart) *CartResponse {
 
 		// Add product info if loaded and set prices
 		if item.Product.ID != uuid.Nil {
-			priceKobo := int64(item.Product.SellingPrice) // Keep as is, no conversion
-			itemResponse.PriceKobo = priceKobo
-			itemResponse.PriceNaira = item.Product.SellingPrice
-			itemResponse.SubtotalKobo = priceKobo * int64(item.Quantity)
-			itemResponse.SubtotalNaira = item.Product.SellingPrice * float64(item.Quantity)
+			price := money.FromNaira(item.Product.EffectivePrice())
+			subtotal := price.Times(item.Quantity)
+			itemResponse.PriceKobo = price.Kobo()
+			itemResponse.PriceNaira = price.Naira()
+			itemResponse.SubtotalKobo = subtotal.Kobo()
+			itemResponse.SubtotalNaira = subtotal.Naira()
 			
 			itemResponse.Product = &ProductInfo{
 				ID:       item.Product.ID,
@@ -733,9 +1623,9 @@ func (s *Service) toCartResponse(cart Cart) *CartResponse {
 	}
 
 	// Calculate total from items
-	totalKobo := int64(0)
+	var total money.Money
 	for _, item := range items {
-		totalKobo += item.SubtotalKobo
+		total += money.Money(item.SubtotalKobo)
 	}
 	
 	return &CartResponse{
@@ -743,13 +1633,44 @@ func (s *Service) toCartResponse(cart Cart) *CartResponse {
 		UserID:        cart.UserID,
 		Items:         items,
 		TotalItems:    len(items),
-		TotalKobo:     totalKobo,
-		TotalNaira:    float64(totalKobo),
+		TotalKobo:     total.Kobo(),
+		TotalNaira:    total.Naira(),
 		CreatedAt:     cart.CreatedAt,
 		UpdatedAt:     cart.UpdatedAt,
 	}
 }
 
+// toAdminOrderResponse adds cost and margin figures that are only shown to admins.
+// Gross profit is item revenue minus the unit costs captured when the order was placed. A zero
+// unit cost means none was recorded (products without a cost, orders placed before costs were
+// captured), so those lines are left out rather than counted as pure profit; with no costed lines
+// the gross profit is unknown and omitted.
+func (s *Service) toAdminOrderResponse(ctx context.Context, order *Order) *OrderResponse {
+	response := s.toOrderResponseWithContext(ctx, order)
+
+	var grossProfit int64
+	costed := 0
+	for i, item := range order.Items {
+		if item.UnitCost == 0 {
+			continue
+		}
+		if i < len(response.Items) {
+			unitCost := item.UnitCost
+			response.Items[i].UnitCost = &unitCost
+			response.Items[i].UnitCostNaira = money.Money(item.UnitCost).Naira()
+		}
+		grossProfit += item.TotalPrice - item.UnitCost*int64(item.Quantity)
+		costed++
+	}
+	if costed > 0 {
+		response.GrossProfit = &grossProfit
+		response.GrossProfitNaira = money.Money(grossProfit).Naira()
+		response.GrossProfitPartial = costed < len(order.Items)
+	}
+
+	return response
+}
+
 func (s *Service) toOrderResponse(order *Order) *OrderResponse {
 	return s.toOrderResponseWithContext(context.Background(), order)
 }
@@ -764,24 +1685,24 @@ func (s *Service) toOrderResponseWithContext(ctx context.Context, order *Order)
 			SKU:             item.SKU,
 			Quantity:        item.Quantity,
 			UnitPrice:       item.UnitPrice,
-			UnitPriceNaira:  float64(item.UnitPrice) / 100.0,
+			UnitPriceNaira:  money.Money(item.UnitPrice).Naira(),
 			TotalPrice:      item.TotalPrice,
-			TotalPriceNaira: float64(item.TotalPrice) / 100.0,
+			TotalPriceNaira: money.Money(item.TotalPrice).Naira(),
+			SubstituteFor:   item.SubstituteFor,
 			CreatedAt:       item.CreatedAt,
 			UpdatedAt:       item.UpdatedAt,
 		}
 
 		// Add product info if loaded
 		if item.Product.ID != uuid.Nil {
-			// Convert product price from float64 to kobo (int64)
-			priceKobo := int64(item.Product.SellingPrice * 100)
+			price := money.FromNaira(item.Product.EffectivePrice())
 			itemResponse.Product = &ProductInfo{
 				ID:         item.Product.ID,
 				Name:       item.Product.Name,
 				Slug:       item.Product.Slug,
 				ImageURL:   item.Product.ImageURL,
-				Price:      priceKobo,
-				PriceNaira: item.Product.SellingPrice,
+				Price:      price.Kobo(),
+				PriceNaira: price.Naira(),
 			}
 		}
 
@@ -806,18 +1727,34 @@ func (s *Service) toOrderResponseWithContext(ctx context.Context, order *Order)
 		DeliveryAddressID:     deliveryAddressID,
 		Status:                order.Status,
 		PaymentStatus:         order.PaymentStatus,
+		PaymentMethod:         order.PaymentMethod,
 		IdempotencyKey:        order.IdempotencyKey,
 		CouponCode:            order.CouponCode,
+		CouponAutoApplied:     order.CouponAutoApplied,
 		CouponDiscount:        order.CouponDiscount,
-		CouponDiscountNaira:   float64(order.CouponDiscount) / 100.0,
+		CouponDiscountNaira:   money.Money(order.CouponDiscount).Naira(),
 		ItemsSubtotal:         order.ItemsSubtotal,
-		ItemsSubtotalNaira:    float64(order.ItemsSubtotal) / 100.0,
+		ItemsSubtotalNaira:    money.Money(order.ItemsSubtotal).Naira(),
 		DeliveryFee:           order.DeliveryFee,
-		DeliveryFeeNaira:      float64(order.DeliveryFee) / 100.0,
+		DeliveryFeeNaira:      money.Money(order.DeliveryFee).Naira(),
+		DeliveryDiscount:      order.DeliveryDiscount,
+		DeliveryDiscountNaira: money.Money(order.DeliveryDiscount).Naira(),
+		DeliverySurgeMultiplier: order.DeliverySurgeMultiplier,
 		ServiceFee:            order.ServiceFee,
-		ServiceFeeNaira:       float64(order.ServiceFee) / 100.0,
+		ServiceFeeNaira:       money.Money(order.ServiceFee).Naira(),
+		TaxKobo:               order.TaxKobo,
+		TaxNaira:              money.Money(order.TaxKobo).Naira(),
+		TaxMode:               order.TaxMode(),
+		AdjustmentKobo:        order.AdjustmentKobo,
+		AdjustmentNaira:       money.Money(order.AdjustmentKobo).Naira(),
+		RefundMethod:          order.RefundMethod,
+		RefundedKobo:          order.RefundedKobo,
+		RefundedNaira:         money.Money(order.RefundedKobo).Naira(),
+		RefundReference:       order.RefundReference,
+		TipKobo:               order.TipKobo,
+		TipNaira:              money.Money(order.TipKobo).Naira(),
 		TotalAmount:           order.TotalAmount,
-		TotalAmountNaira:      float64(order.TotalAmount) / 100.0,
+		TotalAmountNaira:      money.Money(order.TotalAmount).Naira(),
 		CustomRequests:        func() []uuid.UUID {
 			if order.CustomRequests == nil {
 				return []uuid.UUID{}
@@ -825,11 +1762,29 @@ func (s *Service) toOrderResponseWithContext(ctx context.Context, order *Order)
 			return []uuid.UUID(order.CustomRequests)
 		}(),
 		CustomRequestDetails:  []CustomRequestInfo{},
+		QuoteSnapshots:        func() []QuoteSnapshot {
+			if order.QuoteSnapshots == nil {
+				return []QuoteSnapshot{}
+			}
+			return order.QuoteSnapshots
+		}(),
 		Notes:                 order.Notes,
+		RecipientName:         order.RecipientName,
+		RecipientPhone:        order.RecipientPhone,
+		DeliveryInstructions:  order.DeliveryInstructions,
+		UTMSource:             order.UTMSource,
+		UTMCampaign:           order.UTMCampaign,
+		UTMMedium:             order.UTMMedium,
+		ScheduledFor:          order.ScheduledFor,
+		StockReservedUntil:    order.StockReservedUntil,
 		EstimatedDelivery:     order.EstimatedDelivery,
 		DeliveredAt:           order.DeliveredAt,
+		DeliveryVarianceMinutes: order.DeliveryVarianceMinutes,
+		ReceivedAt:            order.ReceivedAt,
+		PaidAt:                order.PaidAt,
 		CancelledAt:           order.CancelledAt,
 		CancellationReason:    order.CancellationReason,
+		Version:               order.Version,
 		Items:                 items,
 		CreatedAt:             order.CreatedAt,
 		UpdatedAt:             order.UpdatedAt,
@@ -897,7 +1852,7 @@ func (s *Service) toOrderResponseWithContext(ctx context.Context, order *Order)
 				for i, item := range customRequest.Items {
 					var quotedPriceNaira *float64
 					if item.QuotedPrice != nil {
-						nairaValue := float64(*item.QuotedPrice)
+						nairaValue := money.Money(*item.QuotedPrice).Naira()
 						quotedPriceNaira = &nairaValue
 					}
 
@@ -921,9 +1876,12 @@ func (s *Service) toOrderResponseWithContext(ctx context.Context, order *Order)
 					activeQuote = &CustomRequestQuoteInfo{
 						ID:            customRequest.ActiveQuote.ID,
 						ItemsSubtotal: customRequest.ActiveQuote.ItemsSubtotal,
-						ItemsSubtotalNaira: float64(customRequest.ActiveQuote.ItemsSubtotal),
+						ItemsSubtotalNaira: money.Money(customRequest.ActiveQuote.ItemsSubtotal).Naira(),
+						DeliveryFee:   customRequest.ActiveQuote.Fees.Delivery,
+						ServiceFee:    customRequest.ActiveQuote.Fees.Service,
+						PackagingFee:  customRequest.ActiveQuote.Fees.Packaging,
 						GrandTotal:    customRequest.ActiveQuote.GrandTotal,
-						GrandTotalNaira: float64(customRequest.ActiveQuote.GrandTotal),
+						GrandTotalNaira: money.Money(customRequest.ActiveQuote.GrandTotal).Naira(),
 						Status:        string(customRequest.ActiveQuote.Status),
 						ValidUntil:    customRequest.ActiveQuote.ValidUntil,
 						AcceptedAt:    customRequest.ActiveQuote.AcceptedAt,
@@ -951,6 +1909,8 @@ func (s *Service) toOrderResponseWithContext(ctx context.Context, order *Order)
 
 // sendOrderStatusNotification sends a notification when order status changes
 func (s *Service) sendOrderStatusNotification(customerID uuid.UUID, orderID uuid.UUID, status OrderStatus) {
+	s.emitStatusEvent(orderID, status)
+
 	if s.notificationService == nil {
 		return
 	}
@@ -963,8 +1923,18 @@ func (s *Service) sendOrderStatusNotification(customerID uuid.UUID, orderID uuid
 		return
 	}
 
-	// Create status-specific notification content
+	// Create status-specific notification content, preferring an admin template in the
+	// customer's language over the built-in English text
 	title, body := s.getNotificationContent(status, orderID.String())
+	localizedTitle, localizedBody, err := s.notificationService.RenderLocalized(customer.UserID, notifications.RecipientCustomer, orderStatusTemplateKey(status), map[string]string{
+		"orderId": orderID.String(),
+		"status":  string(status),
+	})
+	if err == nil {
+		title, body = localizedTitle, localizedBody
+	} else if !errors.Is(err, notifications.ErrTemplateNotFound) {
+		fmt.Printf("Failed to render order status template: %v\n", err)
+	}
 
 	// Create notification request
 	notificationReq := &notifications.CreateNotificationRequest{
@@ -988,7 +1958,38 @@ func (s *Service) sendOrderStatusNotification(customerID uuid.UUID, orderID uuid
 	}()
 }
 
-// getNotificationContent returns appropriate title and body for order status
+// sendReviewPrompt asks the customer to rate an order they have just confirmed receiving
+func (s *Service) sendReviewPrompt(customerID uuid.UUID, orderID uuid.UUID) {
+	if s.notificationService == nil {
+		return
+	}
+
+	notificationReq := &notifications.CreateNotificationRequest{
+		RecipientID:   customerID,
+		RecipientType: notifications.RecipientCustomer,
+		Type:          notifications.TypeOrderUpdate,
+		Title:         "How was your order?",
+		Body:          fmt.Sprintf("Thanks for confirming order %s. Tap to rate the items you received.", orderID.String()),
+		Data: map[string]interface{}{
+			"orderId": orderID.String(),
+			"action":  "review",
+		},
+	}
+
+	go func() {
+		if _, err := s.notificationService.CreateNotification(notificationReq); err != nil {
+			fmt.Printf("Failed to send review prompt: %v\n", err)
+		}
+	}()
+}
+
+// orderStatusTemplateKey is the notification template key for an order status, e.g. "order_delivered"
+func orderStatusTemplateKey(status OrderStatus) string {
+	return "order_" + string(status)
+}
+
+// getNotificationContent returns the built-in English title and body for order status, used
+// when no template exists for it
 func (s *Service) getNotificationContent(status OrderStatus, orderID string) (string, string) {
 	switch status {
 	case OrderStatusConfirmed:
@@ -0,0 +1,174 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"errandShop/internal/domain/products"
+	"errandShop/internal/domain/webhooks"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultStockReservationTTL is how long an unpaid online-payment order holds its stock
+const DefaultStockReservationTTL = 15 * time.Minute
+
+// SetStockReservationTTL sets how long an order awaiting online payment holds the stock it
+// took. Zero disables expiry, so unpaid orders keep their stock until cancelled; negative
+// values keep the default.
+func (s *Service) SetStockReservationTTL(ttl time.Duration) {
+	if ttl >= 0 {
+		s.stockReservationTTL = ttl
+	}
+}
+
+// stockReservationDeadline returns when a new pending order's stock is released if it is still
+// unpaid, or nil when the order keeps its stock: cash on delivery, scheduled orders (which take
+// stock at release) and when reservations are disabled.
+func stockReservationDeadline(status OrderStatus, paymentMethod string, ttl time.Duration, now time.Time) *time.Time {
+	if ttl <= 0 || status != OrderStatusPending || paymentMethod == PaymentMethodCashOnDelivery {
+		return nil
+	}
+	until := now.Add(ttl)
+	return &until
+}
+
+// ExpireStockReservation cancels a pending order whose payment did not arrive before its stock
+// reservation ran out, marks the payment expired and puts its items back in stock. Orders that
+// were paid or moved on in the meantime are left alone; it reports whether the order expired.
+func (s *Service) ExpireStockReservation(ctx context.Context, id uuid.UUID) (bool, error) {
+	var order Order
+	expired := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ? AND payment_status IN ? AND stock_reserved_until <= ?",
+				id, OrderStatusPending, unpaidPaymentStatuses, time.Now()).
+			First(&order).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		reason := "Payment not completed in time; reserved stock released"
+		if err := tx.Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":               OrderStatusCancelled,
+			"payment_status":       PaymentStatusExpired,
+			"cancellation_reason":  reason,
+			"cancelled_at":         time.Now(),
+			"stock_reserved_until": nil,
+			"version":              gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		fromStatus := OrderStatusPending
+		if err := tx.Create(&OrderStatusHistory{OrderID: id, FromStatus: &fromStatus, ToStatus: OrderStatusCancelled, Note: reason}).Error; err != nil {
+			return err
+		}
+
+		var items []OrderItem
+		if err := tx.Where("order_id = ?", id).Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			var product products.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", item.ProductID).First(&product).Error; err != nil {
+				return fmt.Errorf("failed to get product %s: %w", item.ProductID, err)
+			}
+			newStock := product.StockQuantity + item.Quantity
+			if err := tx.Model(&products.Product{}).Where("id = ?", item.ProductID).Update("stock_quantity", newStock).Error; err != nil {
+				return fmt.Errorf("failed to restore stock for product %s: %w", item.ProductID, err)
+			}
+			if err := tx.Create(&products.StockHistory{
+				ProductID:        item.ProductID,
+				ChangeType:       "ADD",
+				QuantityChange:   item.Quantity,
+				PreviousQuantity: product.StockQuantity,
+				NewQuantity:      newStock,
+				ReasonCode:       products.StockReasonCancellation,
+				Reason:           fmt.Sprintf("Stock reservation for order %s expired", id),
+				CreatedBy:        order.CustomerID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		expired = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if expired {
+		s.emitOrderEvent(webhooks.EventOrderCancelled, id)
+		s.sendOrderStatusNotification(order.CustomerID, id, OrderStatusCancelled)
+	}
+	return expired, nil
+}
+
+// unpaidPaymentStatuses are the payment states in which a pending order is still waiting on the customer
+var unpaidPaymentStatuses = []PaymentStatus{PaymentStatusUnpaid, PaymentStatusPending, PaymentStatusFailed}
+
+// StockReservationJob cancels unpaid orders whose stock reservation has run out
+type StockReservationJob struct {
+	svc      *Service
+	interval time.Duration
+	logger   *log.Logger
+}
+
+func NewStockReservationJob(svc *Service) *StockReservationJob {
+	return &StockReservationJob{
+		svc:      svc,
+		interval: time.Minute,
+		logger:   log.New(log.Writer(), "[STOCK-RESERVATIONS] ", log.LstdFlags),
+	}
+}
+
+// Start runs the job on a fixed interval until ctx is cancelled
+func (j *StockReservationJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if expired, err := j.RunOnce(ctx); err != nil {
+					j.logger.Printf("run failed: %v", err)
+				} else if expired > 0 {
+					j.logger.Printf("released stock for %d unpaid orders", expired)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce expires every overdue reservation and returns how many orders were cancelled
+func (j *StockReservationJob) RunOnce(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	err := j.svc.db.WithContext(ctx).Model(&Order{}).
+		Where("status = ? AND payment_status IN ? AND stock_reserved_until <= ?", OrderStatusPending, unpaidPaymentStatuses, time.Now()).
+		Order("stock_reserved_until ASC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired stock reservations: %w", err)
+	}
+
+	expired := 0
+	for _, id := range ids {
+		ok, err := j.svc.ExpireStockReservation(ctx, id)
+		if err != nil {
+			j.logger.Printf("failed to expire order %s: %v", id, err)
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,98 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestStockReservationDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		status        OrderStatus
+		paymentMethod string
+		ttl           time.Duration
+		want          *time.Time
+	}{
+		{"online payment holds stock for the TTL", OrderStatusPending, "paystack", 15 * time.Minute, ptrTime(now.Add(15 * time.Minute))},
+		{"cash on delivery keeps its stock", OrderStatusPending, PaymentMethodCashOnDelivery, 15 * time.Minute, nil},
+		{"scheduled orders have not taken stock yet", OrderStatusScheduled, "paystack", 15 * time.Minute, nil},
+		{"zero TTL disables expiry", OrderStatusPending, "paystack", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stockReservationDeadline(tt.status, tt.paymentMethod, tt.ttl, now)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Fatalf("deadline = %v, want none", *got)
+			case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+				t.Fatalf("deadline = %v, want %v", got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestUnpaidOrderCannotBeConfirmedByItsCustomerToKeepStock(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (
+			id TEXT PRIMARY KEY,
+			customer_id TEXT NOT NULL,
+			status TEXT,
+			payment_status TEXT,
+			stock_reserved_until DATETIME,
+			cancellation_reason TEXT,
+			cancelled_at DATETIME,
+			version INTEGER NOT NULL DEFAULT 1,
+			custom_requests TEXT DEFAULT '[]',
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT, quantity INTEGER)`,
+		`CREATE TABLE products (id TEXT PRIMARY KEY, deleted_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), order_id TEXT, from_status TEXT, to_status TEXT, by_admin_id TEXT, note TEXT, created_at DATETIME)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	customerID := uuid.New()
+	id := uuid.New()
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status, payment_status, stock_reserved_until) VALUES (?, ?, ?, ?, ?)`,
+		id, customerID, OrderStatusPending, PaymentStatusUnpaid, time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	s := &Service{db: db, repo: NewRepository(db)}
+	ctx := context.Background()
+
+	// Moving the order off pending would hide it from the expiry job
+	if err := s.UpdateStatus(ctx, id, customerID, OrderStatusConfirmed); !errors.Is(err, ErrCustomerStatusChange) {
+		t.Fatalf("customer confirming an unpaid order: err = %v, want ErrCustomerStatusChange", err)
+	}
+
+	expired, err := NewStockReservationJob(s).RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	var got Order
+	if err := db.First(&got, "id = ?", id).Error; err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+	if expired != 1 || got.Status != OrderStatusCancelled || got.PaymentStatus != PaymentStatusExpired {
+		t.Fatalf("expired %d, order %s/%s; want 1, cancelled/expired", expired, got.Status, got.PaymentStatus)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
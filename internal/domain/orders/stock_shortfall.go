@@ -0,0 +1,189 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"errandShop/internal/domain/products"
+	"errandShop/pkg/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// How an order handles items that are short of stock, chosen by the customer at checkout
+const (
+	ShortfallReject     = "reject"     // default: reject the order with the shortfall listed
+	ShortfallRemove     = "remove"     // order what is in stock and drop the rest
+	ShortfallSubstitute = "substitute" // replace the missing quantity with the offered substitute, else drop it
+)
+
+// ErrInsufficientStock is wrapped by StockShortfallError
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// SubstituteOffer is the admin-chosen product offered for the missing quantity of a short item
+type SubstituteOffer struct {
+	ProductID      uuid.UUID `json:"productId"`
+	Name           string    `json:"name"`
+	UnitPriceKobo  int64     `json:"unitPriceKobo"`
+	UnitPriceNaira float64   `json:"unitPriceNaira"`
+}
+
+// StockShortfall is a product the order asks for more of than is in stock
+type StockShortfall struct {
+	ProductID  uuid.UUID        `json:"productId"`
+	Name       string           `json:"name"`
+	Requested  int              `json:"requested"`
+	Available  int              `json:"available"`
+	Short      int              `json:"short"`
+	Substitute *SubstituteOffer `json:"substitute,omitempty"` // set when the product has a substitute with enough stock
+}
+
+// StockShortfallError lists every item an order is short of. The customer can resubmit with
+// stockShortfall "remove" or "substitute" to go ahead without them.
+type StockShortfallError struct {
+	Items []StockShortfall
+}
+
+func (e *StockShortfallError) Error() string {
+	parts := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		parts[i] = fmt.Sprintf("%s (requested %d, available %d)", item.Name, item.Requested, item.Available)
+	}
+	return fmt.Sprintf("%s: %s", ErrInsufficientStock, strings.Join(parts, "; "))
+}
+
+func (e *StockShortfallError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// shortOf is the shortfall error for one product whose stock no longer covers the order's total
+// quantity of it, e.g. because stock sold between the check and the order being priced
+func shortOf(product *products.Product, requested int) *StockShortfallError {
+	available := max(product.StockQuantity, 0)
+	return &StockShortfallError{Items: []StockShortfall{{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Requested: requested,
+		Available: available,
+		Short:     requested - available,
+	}}}
+}
+
+// resolveStockShortfalls checks each product's total quantity across the order's lines against
+// its stock. With no shortfall the items come back unchanged. Otherwise, by mode, it returns a
+// StockShortfallError or the items trimmed to what is in stock, plus substitute lines, together
+// with the shortfalls that were resolved.
+func (s *Service) resolveStockShortfalls(ctx context.Context, items []CreateOrderItemRequest, mode string) ([]CreateOrderItemRequest, []StockShortfall, error) {
+	requested := make(map[uuid.UUID]int, len(items))
+	var productIDs []uuid.UUID
+	for _, item := range items {
+		if _, seen := requested[item.ProductID]; !seen {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	var shortfalls []StockShortfall
+	available := make(map[uuid.UUID]int, len(productIDs))
+	// Substitute units already offered for an earlier shortfall, so two short products sharing a
+	// substitute are never both offered the same units
+	offered := make(map[uuid.UUID]int)
+	for _, id := range productIDs {
+		product, err := s.productRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, fmt.Errorf("product with ID %s not found", id)
+			}
+			return nil, nil, fmt.Errorf("failed to get product: %w", err)
+		}
+		available[id] = product.StockQuantity
+		if product.StockQuantity >= requested[id] {
+			continue
+		}
+		shortfall := StockShortfall{
+			ProductID: id,
+			Name:      product.Name,
+			Requested: requested[id],
+			Available: max(product.StockQuantity, 0),
+		}
+		shortfall.Short = shortfall.Requested - shortfall.Available
+		shortfall.Substitute, err = s.substituteOffer(ctx, product, shortfall.Short, requested, offered)
+		if err != nil {
+			return nil, nil, err
+		}
+		if shortfall.Substitute != nil {
+			offered[shortfall.Substitute.ProductID] += shortfall.Short
+		}
+		shortfalls = append(shortfalls, shortfall)
+	}
+	if len(shortfalls) == 0 {
+		return items, nil, nil
+	}
+	if mode != ShortfallRemove && mode != ShortfallSubstitute {
+		return nil, nil, &StockShortfallError{Items: shortfalls}
+	}
+	return trimToStock(items, available, shortfalls, mode == ShortfallSubstitute), shortfalls, nil
+}
+
+// substituteOffer returns the product's substitute if it is active and has short units in stock
+// beyond what the order already asks of it and what was offered for its other shortfalls
+func (s *Service) substituteOffer(ctx context.Context, product *products.Product, short int, requested, offered map[uuid.UUID]int) (*SubstituteOffer, error) {
+	if product.SubstituteProductID == nil {
+		return nil, nil
+	}
+	substitute, err := s.productRepo.GetByID(ctx, *product.SubstituteProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get substitute product: %w", err)
+	}
+	if substitute.StockQuantity-requested[substitute.ID]-offered[substitute.ID] < short {
+		return nil, nil
+	}
+	price := money.FromNaira(substitute.EffectivePrice())
+	return &SubstituteOffer{
+		ProductID:      substitute.ID,
+		Name:           substitute.Name,
+		UnitPriceKobo:  price.Kobo(),
+		UnitPriceNaira: price.Naira(),
+	}, nil
+}
+
+// trimToStock cuts each product's lines down to its available stock, dropping lines with nothing
+// left, and with substitute adds a line for each offered substitute covering the missing quantity
+func trimToStock(items []CreateOrderItemRequest, available map[uuid.UUID]int, shortfalls []StockShortfall, substitute bool) []CreateOrderItemRequest {
+	remaining := make(map[uuid.UUID]int, len(available))
+	for id, quantity := range available {
+		remaining[id] = max(quantity, 0)
+	}
+
+	trimmed := make([]CreateOrderItemRequest, 0, len(items))
+	for _, item := range items {
+		quantity := min(item.Quantity, remaining[item.ProductID])
+		remaining[item.ProductID] -= quantity
+		if quantity == 0 {
+			continue
+		}
+		item.Quantity = quantity
+		trimmed = append(trimmed, item)
+	}
+
+	if substitute {
+		for _, shortfall := range shortfalls {
+			if shortfall.Substitute == nil {
+				continue
+			}
+			substituteFor := shortfall.ProductID
+			trimmed = append(trimmed, CreateOrderItemRequest{
+				ProductID:     shortfall.Substitute.ProductID,
+				Quantity:      shortfall.Short,
+				Name:          shortfall.Substitute.Name,
+				SubstituteFor: &substituteFor,
+			})
+		}
+	}
+	return trimmed
+}
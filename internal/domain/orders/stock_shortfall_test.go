@@ -0,0 +1,102 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"errandShop/internal/domain/products"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTrimToStock(t *testing.T) {
+	rice, beans, oil := uuid.New(), uuid.New(), uuid.New()
+	items := []CreateOrderItemRequest{
+		{ProductID: rice, Quantity: 3},
+		{ProductID: beans, Quantity: 2},
+		{ProductID: rice, Quantity: 2},
+	}
+	available := map[uuid.UUID]int{rice: 4, beans: 0}
+	shortfalls := []StockShortfall{
+		{ProductID: rice, Requested: 5, Available: 4, Short: 1},
+		{ProductID: beans, Requested: 2, Available: 0, Short: 2, Substitute: &SubstituteOffer{ProductID: oil, Name: "Oil"}},
+	}
+
+	removed := trimToStock(items, available, shortfalls, false)
+	if got := fmt.Sprint(quantities(removed)); got != fmt.Sprint([]int{3, 1}) {
+		t.Fatalf("remove: quantities = %s, want [3 1]", got)
+	}
+	if removed[0].ProductID != rice || removed[1].ProductID != rice {
+		t.Fatalf("remove: beans should be dropped, got %+v", removed)
+	}
+
+	substituted := trimToStock(items, available, shortfalls, true)
+	if len(substituted) != 3 {
+		t.Fatalf("substitute: got %d lines, want 3", len(substituted))
+	}
+	last := substituted[2]
+	if last.ProductID != oil || last.Quantity != 2 || last.SubstituteFor == nil || *last.SubstituteFor != beans {
+		t.Fatalf("substitute: last line = %+v, want 2 oil for beans", last)
+	}
+}
+
+func TestStockShortfallErrorIsInsufficientStock(t *testing.T) {
+	err := fmt.Errorf("failed to create order: %w", &StockShortfallError{Items: []StockShortfall{{Name: "Rice", Requested: 5, Available: 4, Short: 1}}})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("errors.Is(%v, ErrInsufficientStock) = false", err)
+	}
+	var shortfall *StockShortfallError
+	if !errors.As(err, &shortfall) || len(shortfall.Items) != 1 {
+		t.Fatalf("errors.As did not recover the shortfall from %v", err)
+	}
+}
+
+func TestSharedSubstituteIsNotOfferedTwice(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE products (id TEXT PRIMARY KEY, name TEXT, selling_price REAL, stock_quantity INTEGER, substitute_product_id TEXT, is_active BOOLEAN DEFAULT 1, deleted_at DATETIME)`,
+		`CREATE TABLE product_images (id TEXT PRIMARY KEY, product_id TEXT, sort_order INTEGER, is_primary BOOLEAN, created_at DATETIME)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	rice, beans, oil := uuid.New(), uuid.New(), uuid.New()
+	if err := db.Exec(`INSERT INTO products (id, name, selling_price, stock_quantity, substitute_product_id) VALUES
+		(?, 'Rice', 1000, 0, ?), (?, 'Beans', 800, 0, ?), (?, 'Oil', 1500, 3, NULL)`,
+		rice, oil, beans, oil, oil).Error; err != nil {
+		t.Fatalf("failed to insert products: %v", err)
+	}
+
+	s := &Service{productRepo: products.NewRepository(db)}
+	items := []CreateOrderItemRequest{{ProductID: rice, Quantity: 2}, {ProductID: beans, Quantity: 2}}
+	_, shortfalls, err := s.resolveStockShortfalls(context.Background(), items, ShortfallSubstitute)
+	if err != nil {
+		t.Fatalf("resolveStockShortfalls: %v", err)
+	}
+	if len(shortfalls) != 2 {
+		t.Fatalf("got %d shortfalls, want 2", len(shortfalls))
+	}
+	// Oil covers the 2 rice but has only 1 left for the beans
+	if shortfalls[0].Substitute == nil || shortfalls[0].Substitute.ProductID != oil {
+		t.Fatalf("rice: substitute = %+v, want oil", shortfalls[0].Substitute)
+	}
+	if shortfalls[1].Substitute != nil {
+		t.Fatalf("beans: substitute = %+v, want none once oil is allocated to rice", shortfalls[1].Substitute)
+	}
+}
+
+func quantities(items []CreateOrderItemRequest) []int {
+	out := make([]int, len(items))
+	for i, item := range items {
+		out[i] = item.Quantity
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package orders
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryTracker looks up the delivery for an order. It returns nil, nil when the order has no
+// delivery yet.
+type DeliveryTracker interface {
+	TrackOrderDelivery(orderID uuid.UUID) (*DeliveryTracking, error)
+}
+
+// SetDeliveryTracker enables delivery details in order tracking
+func (s *Service) SetDeliveryTracker(tracker DeliveryTracker) {
+	s.deliveryTracker = tracker
+}
+
+// Tracking returns an order's status, payment and delivery in one response. Customers can only
+// track their own orders; admins (asAdmin) can track any order. A failed delivery lookup still
+// returns the order's own status.
+func (s *Service) Tracking(ctx context.Context, id, userID uuid.UUID, asAdmin bool) (*OrderTrackingResponse, error) {
+	var order *Order
+	var err error
+	if asAdmin {
+		order, err = s.repo.AdminGet(ctx, id)
+	} else {
+		order, err = s.repo.Get(ctx, id, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery *DeliveryTracking
+	if s.deliveryTracker != nil {
+		if delivery, err = s.deliveryTracker.TrackOrderDelivery(order.ID); err != nil {
+			log.Printf("Failed to load delivery for order %s: %v", order.ID, err)
+			delivery = nil
+		}
+	}
+	return orderTracking(order, delivery), nil
+}
+
+// orderTracking combines an order with its delivery, if any. The delivery's estimate replaces the
+// order's until the order is delivered.
+func orderTracking(order *Order, delivery *DeliveryTracking) *OrderTrackingResponse {
+	tracking := &OrderTrackingResponse{
+		OrderID:       order.ID,
+		OrderNumber:   orderNumber(order.ID),
+		Status:        order.Status,
+		PaymentStatus: order.PaymentStatus,
+		PaymentMethod: order.PaymentMethod,
+		ScheduledFor:  order.ScheduledFor,
+		ETA:           order.EstimatedDelivery,
+		DeliveredAt:   order.DeliveredAt,
+		ReceivedAt:    order.ReceivedAt,
+		Delivery:      delivery,
+	}
+	if delivery != nil && delivery.EstimatedTime != nil && order.DeliveredAt == nil {
+		tracking.ETA = delivery.EstimatedTime
+	}
+	return tracking
+}
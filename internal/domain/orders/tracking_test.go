@@ -0,0 +1,39 @@
+package orders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderTrackingETA(t *testing.T) {
+	orderETA := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	deliveryETA := orderETA.Add(25 * time.Minute)
+	deliveredAt := orderETA.Add(10 * time.Minute)
+
+	tests := []struct {
+		name     string
+		order    Order
+		delivery *DeliveryTracking
+		want     time.Time
+	}{
+		{"no delivery yet", Order{EstimatedDelivery: &orderETA}, nil, orderETA},
+		{"delivery without estimate", Order{EstimatedDelivery: &orderETA}, &DeliveryTracking{}, orderETA},
+		{"delivery estimate wins", Order{EstimatedDelivery: &orderETA}, &DeliveryTracking{EstimatedTime: &deliveryETA}, deliveryETA},
+		{"delivered orders keep their own estimate", Order{EstimatedDelivery: &orderETA, DeliveredAt: &deliveredAt}, &DeliveryTracking{EstimatedTime: &deliveryETA}, orderETA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.order.ID = uuid.New()
+			got := orderTracking(&tt.order, tt.delivery)
+			if got.ETA == nil || !got.ETA.Equal(tt.want) {
+				t.Fatalf("ETA = %v, want %v", got.ETA, tt.want)
+			}
+			if got.Delivery != tt.delivery {
+				t.Fatalf("delivery not passed through")
+			}
+		})
+	}
+}
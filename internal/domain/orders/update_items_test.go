@@ -0,0 +1,130 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"errandShop/internal/domain/products"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newUpdateItemsTestService returns a service over an in-memory order with one line of two units
+// of a rationed product (at most 3 per order) and a bulk product (at least 6) in the catalog
+func newUpdateItemsTestService(t *testing.T) (s *Service, db *gorm.DB, orderID, userID, rationed, bulk uuid.UUID) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT, payment_status TEXT, custom_requests TEXT DEFAULT '[]', created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE order_items (id TEXT PRIMARY KEY, order_id TEXT, product_id TEXT, quantity INTEGER, unit_price INTEGER, total_price INTEGER)`,
+		`CREATE TABLE products (
+			id TEXT PRIMARY KEY,
+			name TEXT,
+			selling_price REAL,
+			stock_quantity INTEGER,
+			min_order_qty INTEGER NOT NULL DEFAULT 0,
+			max_order_qty INTEGER NOT NULL DEFAULT 0,
+			available_days TEXT,
+			available_from TEXT,
+			available_until TEXT,
+			is_active BOOLEAN DEFAULT 1,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE product_images (id TEXT PRIMARY KEY, product_id TEXT, sort_order INTEGER, is_primary BOOLEAN, created_at DATETIME)`,
+		`CREATE TABLE order_status_history (id TEXT PRIMARY KEY, order_id TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	orderID, userID, rationed, bulk = uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	if err := db.Exec(`INSERT INTO products (id, name, selling_price, stock_quantity, max_order_qty) VALUES (?, 'Sugar', 1000, 50, 3)`, rationed).Error; err != nil {
+		t.Fatalf("failed to insert product: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO products (id, name, selling_price, stock_quantity, min_order_qty) VALUES (?, 'Water (pack)', 500, 50, 6)`, bulk).Error; err != nil {
+		t.Fatalf("failed to insert product: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO orders (id, customer_id, status, payment_status) VALUES (?, ?, ?, ?)`, orderID, userID, OrderStatusPending, PaymentStatusUnpaid).Error; err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price) VALUES (?, ?, ?, 2, 100000, 200000)`, uuid.New(), orderID, rationed).Error; err != nil {
+		t.Fatalf("failed to insert order item: %v", err)
+	}
+	s = &Service{repo: NewRepository(db), productRepo: products.NewRepository(db), db: db, storeLocation: time.UTC}
+	return s, db, orderID, userID, rationed, bulk
+}
+
+func TestUpdateItemsEnforcesOrderQuantityLimits(t *testing.T) {
+	s, _, orderID, userID, rationed, bulk := newUpdateItemsTestService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		items []OrderItemChange
+	}{
+		{"raising a rationed item above its maximum", []OrderItemChange{{ProductID: rationed, Quantity: 4}}},
+		{"adding a bulk item below its minimum", []OrderItemChange{{ProductID: bulk, Quantity: 2}}},
+	}
+	for _, tt := range tests {
+		_, err := s.UpdateItems(ctx, orderID, userID, UpdateOrderItemsRequest{Items: tt.items})
+		if !errors.Is(err, products.ErrOrderQuantityLimit) {
+			t.Errorf("%s: err = %v, want ErrOrderQuantityLimit", tt.name, err)
+		}
+	}
+}
+
+func TestUpdateItemsRejectsAddingUnavailableProducts(t *testing.T) {
+	s, db, orderID, userID, _, _ := newUpdateItemsTestService(t)
+
+	// Sold only three days from now, so not today
+	days := []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+	otherDay := days[(int(time.Now().UTC().Weekday())+3)%7]
+	bread := uuid.New()
+	if err := db.Exec(`INSERT INTO products (id, name, selling_price, stock_quantity, available_days) VALUES (?, 'Fresh bread', 800, 20, ?)`,
+		bread, fmt.Sprintf(`["%s"]`, otherDay)).Error; err != nil {
+		t.Fatalf("failed to insert product: %v", err)
+	}
+
+	_, err := s.UpdateItems(context.Background(), orderID, userID, UpdateOrderItemsRequest{Items: []OrderItemChange{{ProductID: bread, Quantity: 1}}})
+	if !errors.Is(err, products.ErrProductUnavailable) {
+		t.Fatalf("err = %v, want ErrProductUnavailable", err)
+	}
+}
+
+// flatDelivery prices every delivery at a fixed fee with no surcharge or surge
+type flatDelivery struct{ feeKobo int64 }
+
+func (f flatDelivery) CalculateDeliveryFee(float64, string) int64 { return f.feeKobo }
+func (flatDelivery) WeightSurcharge(int) int64                    { return 0 }
+func (flatDelivery) ApplySurge(feeKobo int64, _ *int, _ time.Time) (int64, float64) {
+	return feeKobo, 1
+}
+
+func TestUpdateItemsMinimumOrderIgnoresQuoteFees(t *testing.T) {
+	s, db, orderID, userID, rationed, _ := newUpdateItemsTestService(t)
+	s.deliveryService = flatDelivery{feeKobo: 150000}
+	s.minOrderSubtotalKobo = 300000
+
+	// ₦500 of quoted items carries ₦5,000 of quote delivery, which must not count towards the minimum
+	if err := db.Exec(`ALTER TABLE orders ADD COLUMN quote_snapshots TEXT DEFAULT '[]'`).Error; err != nil {
+		t.Fatalf("failed to add quote snapshots: %v", err)
+	}
+	if err := db.Exec(`UPDATE orders SET quote_snapshots = ? WHERE id = ?`,
+		`[{"customRequestId":"`+uuid.NewString()+`","itemsSubtotal":50000,"deliveryFee":500000,"grandTotal":550000}]`, orderID).Error; err != nil {
+		t.Fatalf("failed to set quote snapshots: %v", err)
+	}
+
+	_, err := s.UpdateItems(context.Background(), orderID, userID, UpdateOrderItemsRequest{Items: []OrderItemChange{{ProductID: rationed, Quantity: 1}}})
+	if err == nil || !strings.HasPrefix(err.Error(), "minimum order is ₦3000.00; add ₦1500.00 more") {
+		t.Fatalf("err = %v, want the ₦3,000 minimum short by ₦1,500", err)
+	}
+}
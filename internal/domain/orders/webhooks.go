@@ -0,0 +1,82 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"errandShop/internal/domain/webhooks"
+	"github.com/google/uuid"
+)
+
+// WebhookDispatcher sends order events to the endpoints registered by admins
+type WebhookDispatcher interface {
+	Dispatch(event webhooks.EventType, data interface{})
+}
+
+// SetWebhookDispatcher enables outbound order webhooks; nil disables them
+func (s *Service) SetWebhookDispatcher(dispatcher WebhookDispatcher) {
+	s.webhookDispatcher = dispatcher
+}
+
+// OrderEventData is the "data" object of an order webhook payload
+type OrderEventData struct {
+	OrderID       uuid.UUID     `json:"orderId"`
+	CustomerID    uuid.UUID     `json:"customerId"`
+	Status        OrderStatus   `json:"status"`
+	PaymentStatus PaymentStatus `json:"paymentStatus"`
+	PaymentMethod string        `json:"paymentMethod"`
+	ItemsSubtotal int64         `json:"itemsSubtotal"` // in kobo
+	DeliveryFee   int64         `json:"deliveryFee"`   // in kobo
+	TaxKobo       int64         `json:"taxKobo"`       // VAT, in kobo
+	TaxMode       string        `json:"taxMode"`       // whether TotalAmount includes TaxKobo or had it added
+	TotalAmount   int64         `json:"totalAmount"`   // in kobo
+	ItemCount     int           `json:"itemCount"`
+	ScheduledFor  *time.Time    `json:"scheduledFor,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt"`
+}
+
+// emitOrderEvent reloads the order and hands it to the webhook dispatcher in the background,
+// so the payload reflects the committed change and the caller never waits on endpoints
+func (s *Service) emitOrderEvent(event webhooks.EventType, orderID uuid.UUID) {
+	if s.webhookDispatcher == nil {
+		return
+	}
+
+	go func() {
+		order, err := s.repo.AdminGet(context.Background(), orderID)
+		if err != nil {
+			fmt.Printf("Failed to load order %s for %s webhook: %v\n", orderID, event, err)
+			return
+		}
+		itemCount := 0
+		for _, item := range order.Items {
+			itemCount += item.Quantity
+		}
+		s.webhookDispatcher.Dispatch(event, OrderEventData{
+			OrderID:       order.ID,
+			CustomerID:    order.CustomerID,
+			Status:        order.Status,
+			PaymentStatus: order.PaymentStatus,
+			PaymentMethod: order.PaymentMethod,
+			ItemsSubtotal: order.ItemsSubtotal,
+			DeliveryFee:   order.DeliveryFee,
+			TaxKobo:       order.TaxKobo,
+			TaxMode:       order.TaxMode(),
+			TotalAmount:   order.TotalAmount,
+			ItemCount:     itemCount,
+			ScheduledFor:  order.ScheduledFor,
+			CreatedAt:     order.CreatedAt,
+		})
+	}()
+}
+
+// emitStatusEvent maps a status change to its webhook event, if it has one
+func (s *Service) emitStatusEvent(orderID uuid.UUID, status OrderStatus) {
+	switch status {
+	case OrderStatusDelivered:
+		s.emitOrderEvent(webhooks.EventOrderDelivered, orderID)
+	case OrderStatusCancelled:
+		s.emitOrderEvent(webhooks.EventOrderCancelled, orderID)
+	}
+}
@@ -0,0 +1,164 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"errandShop/internal/domain/coupons"
+	"errandShop/internal/domain/notifications"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// winBackBatchSize caps how many customers one run coupons, so a first run over a large
+// customer base spreads its coupons and pushes across several hours
+const winBackBatchSize = 200
+
+// WinBackCoupon records a welcome-back coupon issued to a lapsed customer, so the same customer
+// is not couponed again until the cooldown has passed
+type WinBackCoupon struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index" json:"userId"`
+	CouponID   uuid.UUID `gorm:"type:uuid;not null" json:"couponId"`
+	CouponCode string    `gorm:"type:varchar(50);not null" json:"couponCode"`
+	IssuedAt   time.Time `gorm:"not null;index" json:"issuedAt"`
+}
+
+func (WinBackCoupon) TableName() string {
+	return "winback_coupons"
+}
+
+// WinBackCouponIssuer generates the personal coupon sent to a lapsed customer
+type WinBackCouponIssuer interface {
+	AutoGenerateUserCoupon(userID uuid.UUID, couponType coupons.CouponType, value float64, description string) (*coupons.CouponResponse, error)
+}
+
+// WinBackJob periodically sends a welcome-back coupon to lapsed customers: those whose last
+// delivered order is older than lapsedAfter and who have placed no order since. A customer gets at
+// most one win-back coupon per cooldown.
+type WinBackJob struct {
+	db                  *gorm.DB
+	couponIssuer        WinBackCouponIssuer
+	notificationService notifications.NotificationService
+	lapsedAfter         time.Duration
+	cooldown            time.Duration
+	percentOff          float64
+	interval            time.Duration
+	logger              *log.Logger
+}
+
+func NewWinBackJob(db *gorm.DB, couponIssuer WinBackCouponIssuer, notificationService notifications.NotificationService, lapsedAfter, cooldown time.Duration, percentOff float64) *WinBackJob {
+	return &WinBackJob{
+		db:                  db,
+		couponIssuer:        couponIssuer,
+		notificationService: notificationService,
+		lapsedAfter:         lapsedAfter,
+		cooldown:            cooldown,
+		percentOff:          percentOff,
+		interval:            time.Hour,
+		logger:              log.New(log.Writer(), "[WIN-BACK] ", log.LstdFlags),
+	}
+}
+
+// Start runs the job on a fixed interval until ctx is cancelled
+func (j *WinBackJob) Start(ctx context.Context) {
+	if j.percentOff <= 0 || j.lapsedAfter <= 0 {
+		j.logger.Println("disabled (no win-back discount configured)")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if issued, err := j.RunOnce(ctx); err != nil {
+					j.logger.Printf("run failed: %v", err)
+				} else if issued > 0 {
+					j.logger.Printf("issued %d win-back coupons", issued)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce coupons and notifies a batch of lapsed customers and returns how many were couponed
+func (j *WinBackJob) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	customerIDs, err := j.lapsedCustomers(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	issued := 0
+	for _, customerID := range customerIDs {
+		coupon, err := j.couponIssuer.AutoGenerateUserCoupon(customerID, coupons.CouponPercentage, j.percentOff,
+			fmt.Sprintf("Welcome back: %g%% off your next order", j.percentOff))
+		if err != nil {
+			j.logger.Printf("failed to generate coupon for user %s: %v", customerID, err)
+			continue
+		}
+
+		// Record before notifying, so a failed push never leads to a second coupon. Without the
+		// record the next run coupons the customer again, so this coupon is not announced.
+		if err := j.db.WithContext(ctx).Create(&WinBackCoupon{
+			UserID:     customerID,
+			CouponID:   coupon.ID,
+			CouponCode: coupon.Code,
+			IssuedAt:   now,
+		}).Error; err != nil {
+			j.logger.Printf("failed to record win-back coupon %s for user %s, not notifying: %v", coupon.Code, customerID, err)
+			continue
+		}
+		issued++
+
+		req := &notifications.CreateNotificationRequest{
+			RecipientID:   customerID,
+			RecipientType: notifications.RecipientCustomer,
+			Type:          notifications.TypePromotion,
+			Title:         "We miss you!",
+			Body:          fmt.Sprintf("It's been a while. Here's %g%% off your next order with code %s.", j.percentOff, coupon.Code),
+			Data: map[string]interface{}{
+				"couponCode": coupon.Code,
+				"reason":     "win_back",
+			},
+		}
+		if _, err := j.notificationService.CreateNotification(req); err != nil {
+			j.logger.Printf("failed to notify user %s: %v", customerID, err)
+		}
+	}
+
+	return issued, nil
+}
+
+// lapsedCustomers lists active customers who have had an order delivered, none since lapsedAfter
+// ago, have placed no order since then either, and have not had a win-back coupon within the
+// cooldown. Customers who have never had an order delivered are not lapsed.
+func (j *WinBackJob) lapsedCustomers(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	lapsedCutoff := now.Add(-j.lapsedAfter)
+	cooldownCutoff := now.Add(-j.cooldown)
+
+	var customerIDs []uuid.UUID
+	err := j.db.WithContext(ctx).Table("orders o").
+		Select("o.customer_id").
+		Joins("JOIN users u ON u.id = o.customer_id AND u.deleted_at IS NULL AND u.status = ?", "active").
+		Where("o.status = ?", OrderStatusDelivered).
+		Where("NOT EXISTS (SELECT 1 FROM orders recent WHERE recent.customer_id = o.customer_id AND recent.created_at >= ?)", lapsedCutoff).
+		Where("NOT EXISTS (SELECT 1 FROM winback_coupons w WHERE w.user_id = o.customer_id AND w.issued_at >= ?)", cooldownCutoff).
+		Group("o.customer_id").
+		Having("MAX(COALESCE(o.delivered_at, o.updated_at)) < ?", lapsedCutoff).
+		Order("o.customer_id").
+		Limit(winBackBatchSize).
+		Pluck("o.customer_id", &customerIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lapsed customers: %w", err)
+	}
+	return customerIDs, nil
+}
@@ -0,0 +1,147 @@
+package orders
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"errandShop/internal/domain/coupons"
+	"errandShop/internal/domain/notifications"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestWinBackFindsOnlyLapsedCustomers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE users (id TEXT PRIMARY KEY, status TEXT NOT NULL DEFAULT 'active', deleted_at DATETIME)`,
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT NOT NULL, delivered_at DATETIME, created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE winback_coupons (id TEXT PRIMARY KEY, user_id TEXT NOT NULL, coupon_id TEXT NOT NULL, coupon_code TEXT NOT NULL, issued_at DATETIME NOT NULL)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	customer := func(status string) uuid.UUID {
+		id := uuid.New()
+		if err := db.Exec(`INSERT INTO users (id, status) VALUES (?, ?)`, id, status).Error; err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+		return id
+	}
+	order := func(customerID uuid.UUID, status OrderStatus, placed, delivered time.Time) {
+		if err := db.Exec(`INSERT INTO orders (id, customer_id, status, delivered_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.New(), customerID, status, delivered, placed, delivered).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+	}
+
+	lapsed := customer("active")
+	order(lapsed, OrderStatusDelivered, daysAgo(91), daysAgo(90))
+
+	recent := customer("active")
+	order(recent, OrderStatusDelivered, daysAgo(91), daysAgo(90))
+	order(recent, OrderStatusDelivered, daysAgo(11), daysAgo(10))
+
+	pendingSince := customer("active") // ordered again recently, not delivered yet
+	order(pendingSince, OrderStatusDelivered, daysAgo(91), daysAgo(90))
+	order(pendingSince, OrderStatusPending, daysAgo(1), daysAgo(1))
+
+	neverDelivered := customer("active")
+	order(neverDelivered, OrderStatusCancelled, daysAgo(91), daysAgo(90))
+
+	suspended := customer("suspended")
+	order(suspended, OrderStatusDelivered, daysAgo(91), daysAgo(90))
+
+	couponed := customer("active")
+	order(couponed, OrderStatusDelivered, daysAgo(91), daysAgo(90))
+	if err := db.Create(&WinBackCoupon{ID: uuid.New(), UserID: couponed, CouponID: uuid.New(), CouponCode: "USER-1", IssuedAt: daysAgo(30)}).Error; err != nil {
+		t.Fatalf("failed to insert win-back coupon: %v", err)
+	}
+
+	job := NewWinBackJob(db, nil, nil, 60*24*time.Hour, 90*24*time.Hour, 10)
+	got, err := job.lapsedCustomers(context.Background(), now)
+	if err != nil {
+		t.Fatalf("lapsedCustomers returned error: %v", err)
+	}
+	if len(got) != 1 || got[0] != lapsed {
+		t.Fatalf("lapsedCustomers = %v, want only %s", got, lapsed)
+	}
+
+	// Once the cooldown has passed the earlier coupon no longer holds the customer back
+	job.cooldown = 20 * 24 * time.Hour
+	if got, _ := job.lapsedCustomers(context.Background(), now); len(got) != 2 {
+		t.Fatalf("after the cooldown: lapsedCustomers = %v, want 2 customers", got)
+	}
+}
+
+type fakeWinBackIssuer struct {
+	codes map[uuid.UUID]string
+}
+
+func (f *fakeWinBackIssuer) AutoGenerateUserCoupon(userID uuid.UUID, couponType coupons.CouponType, value float64, description string) (*coupons.CouponResponse, error) {
+	return &coupons.CouponResponse{ID: uuid.New(), Code: f.codes[userID], Type: couponType, Value: value}, nil
+}
+
+type recordingNotifier struct {
+	notifications.NotificationService
+	recipients []uuid.UUID
+}
+
+func (r *recordingNotifier) CreateNotification(req *notifications.CreateNotificationRequest) (*notifications.NotificationResponse, error) {
+	r.recipients = append(r.recipients, req.RecipientID)
+	return &notifications.NotificationResponse{}, nil
+}
+
+func TestWinBackDoesNotAnnounceUnrecordedCoupons(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE users (id TEXT PRIMARY KEY, status TEXT NOT NULL DEFAULT 'active', deleted_at DATETIME)`,
+		`CREATE TABLE orders (id TEXT PRIMARY KEY, customer_id TEXT NOT NULL, status TEXT NOT NULL, delivered_at DATETIME, created_at DATETIME, updated_at DATETIME)`,
+		`CREATE TABLE winback_coupons (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), user_id TEXT NOT NULL, coupon_id TEXT NOT NULL, coupon_code TEXT NOT NULL, issued_at DATETIME NOT NULL)`,
+		// Stands in for the record insert failing, e.g. a lost connection
+		`CREATE TRIGGER winback_record_fails BEFORE INSERT ON winback_coupons WHEN NEW.coupon_code = 'LOST-1' BEGIN SELECT RAISE(ABORT, 'connection reset'); END`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+
+	delivered := time.Now().Add(-90 * 24 * time.Hour)
+	lost, recorded := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{lost, recorded} {
+		if err := db.Exec(`INSERT INTO users (id) VALUES (?)`, id).Error; err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+		if err := db.Exec(`INSERT INTO orders (id, customer_id, status, delivered_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.New(), id, OrderStatusDelivered, delivered, delivered, delivered).Error; err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+	}
+
+	notifier := &recordingNotifier{}
+	issuer := &fakeWinBackIssuer{codes: map[uuid.UUID]string{lost: "LOST-1", recorded: "USER-2"}}
+	job := NewWinBackJob(db, issuer, notifier, 60*24*time.Hour, 90*24*time.Hour, 10)
+
+	issued, err := job.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce returned error: %v", err)
+	}
+	if issued != 1 {
+		t.Fatalf("issued = %d, want only the recorded coupon counted", issued)
+	}
+	if len(notifier.recipients) != 1 || notifier.recipients[0] != recorded {
+		t.Fatalf("notified %v, want only %s", notifier.recipients, recorded)
+	}
+}
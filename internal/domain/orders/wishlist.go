@@ -0,0 +1,139 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"errandShop/internal/domain/products"
+	"errandShop/pkg/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSavedItemNotFound is returned when a product is not on the customer's wishlist
+var ErrSavedItemNotFound = errors.New("product is not in your wishlist")
+
+// SavedItem is a product a customer has bookmarked for later without adding it to their cart.
+// Each product is saved at most once per customer.
+type SavedItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_saved_items_user_product,priority:1" json:"userId"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_saved_items_user_product,priority:2" json:"productId"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	Product products.Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ListSavedItems returns the customer's wishlist, most recently saved first
+func (s *Service) ListSavedItems(ctx context.Context, userID uuid.UUID) ([]SavedItemResponse, error) {
+	var items []SavedItem
+	if err := s.db.WithContext(ctx).Preload("Product").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list saved items: %w", err)
+	}
+
+	responses := make([]SavedItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, toSavedItemResponse(item))
+	}
+	return responses, nil
+}
+
+// SaveItem adds a product to the customer's wishlist. Saving a product that is already on it is a
+// no-op. Out-of-stock products can be saved; stock is only checked when moving them to the cart.
+func (s *Service) SaveItem(ctx context.Context, userID, productID uuid.UUID) (*SavedItemResponse, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	item := SavedItem{UserID: userID, ProductID: productID}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+		return nil, fmt.Errorf("failed to save item: %w", err)
+	}
+
+	var saved SavedItem
+	if err := s.db.WithContext(ctx).Preload("Product").
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		First(&saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to load saved item: %w", err)
+	}
+	response := toSavedItemResponse(saved)
+	return &response, nil
+}
+
+// RemoveSavedItem takes a product off the customer's wishlist
+func (s *Service) RemoveSavedItem(ctx context.Context, userID, productID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).Delete(&SavedItem{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove saved item: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSavedItemNotFound
+	}
+	return nil
+}
+
+// MoveSavedItemToCart adds a saved product to the customer's cart and takes it off the wishlist.
+// The cart applies its usual stock and quantity checks; if they fail the item stays saved.
+func (s *Service) MoveSavedItemToCart(ctx context.Context, userID, productID uuid.UUID, quantity int) (*CartResponse, error) {
+	var saved SavedItem
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).First(&saved).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved item: %w", err)
+	}
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	cart, err := s.AddToCart(ctx, userID, AddToCartRequest{ProductID: productID, Quantity: quantity})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Delete(&saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to remove saved item: %w", err)
+	}
+	return cart, nil
+}
+
+// IsWishlisted reports whether the customer has saved the product. It lets the products service
+// flag wishlisted products without depending on this package.
+func (s *Service) IsWishlisted(ctx context.Context, userID, productID uuid.UUID) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&SavedItem{}).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check wishlist: %w", err)
+	}
+	return count > 0, nil
+}
+
+func toSavedItemResponse(item SavedItem) SavedItemResponse {
+	response := SavedItemResponse{
+		ID:        item.ID,
+		ProductID: item.ProductID,
+		CreatedAt: item.CreatedAt,
+	}
+	if item.Product.ID != uuid.Nil {
+		price := money.FromNaira(item.Product.EffectivePrice())
+		response.InStock = item.Product.IsActive && item.Product.StockQuantity > 0
+		response.Product = &ProductInfo{
+			ID:         item.Product.ID,
+			Name:       item.Product.Name,
+			Slug:       item.Product.Slug,
+			ImageURL:   item.Product.ImageURL,
+			Price:      price.Kobo(),
+			PriceNaira: price.Naira(),
+		}
+	}
+	return response
+}
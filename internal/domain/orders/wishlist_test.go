@@ -0,0 +1,37 @@
+package orders
+
+import (
+	"testing"
+
+	"errandShop/internal/domain/products"
+	"github.com/google/uuid"
+)
+
+func TestToSavedItemResponse(t *testing.T) {
+	productID := uuid.New()
+	tests := []struct {
+		name        string
+		product     products.Product
+		wantInStock bool
+		wantProduct bool
+	}{
+		{"in stock", products.Product{ID: productID, Name: "Rice", SellingPrice: 2500, StockQuantity: 4, IsActive: true}, true, true},
+		{"out of stock", products.Product{ID: productID, Name: "Rice", SellingPrice: 2500, IsActive: true}, false, true},
+		{"inactive", products.Product{ID: productID, Name: "Rice", SellingPrice: 2500, StockQuantity: 4}, false, true},
+		{"product not loaded", products.Product{}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := toSavedItemResponse(SavedItem{ID: uuid.New(), ProductID: productID, Product: tt.product})
+			if response.InStock != tt.wantInStock {
+				t.Fatalf("InStock = %v, want %v", response.InStock, tt.wantInStock)
+			}
+			if (response.Product != nil) != tt.wantProduct {
+				t.Fatalf("Product set = %v, want %v", response.Product != nil, tt.wantProduct)
+			}
+			if tt.wantProduct && response.Product.Price != 250000 {
+				t.Fatalf("Price = %d, want 250000", response.Product.Price)
+			}
+		})
+	}
+}
@@ -34,6 +34,8 @@ type PaymentResponse struct {
 	CustomerID     uint          `json:"customer_id"`
 	AmountKobo     int64         `json:"amount_kobo"`
 	AmountNaira    float64       `json:"amount_naira"`
+	FeesKobo       int64         `json:"fees_kobo"`
+	SettlementKobo int64         `json:"settlement_kobo"`
 	Currency       string        `json:"currency"`
 	PaymentMethod  PaymentMethod `json:"payment_method"`
 	Status         PaymentStatus `json:"status"`
@@ -154,6 +156,7 @@ type PaystackVerifyResponse struct {
 		Status          string `json:"status"`
 		Reference       string `json:"reference"`
 		Amount          int64  `json:"amount"`
+		Fees            int64  `json:"fees"`
 		GatewayResponse string `json:"gateway_response"`
 		PaidAt          string `json:"paid_at"`
 		CreatedAt       string `json:"created_at"`
@@ -44,6 +44,8 @@ type Payment struct {
 	OrderID          string         `json:"order_id" gorm:"type:uuid;not null;index"`
 	CustomerID       uint           `json:"customer_id" gorm:"not null;index"`
 	AmountKobo       int64          `json:"amount_kobo" gorm:"not null"` // Amount in kobo (smallest currency unit)
+	FeesKobo         int64          `json:"fees_kobo" gorm:"not null;default:0"`       // Processing fee deducted by the provider
+	SettlementKobo   int64          `json:"settlement_kobo" gorm:"not null;default:0"` // Amount settled to us (amount minus fees)
 	Currency         string         `json:"currency" gorm:"not null;default:'NGN'"`
 	PaymentMethod    PaymentMethod  `json:"payment_method" gorm:"not null"`
 	Status           PaymentStatus  `json:"status" gorm:"not null;default:'pending'"`
@@ -31,7 +31,8 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, handler *PaymentHandler) {
 	webhooks.Post("/paystack", handler.PaystackWebhook)
 
 	// Protected routes (require authentication) - registered after specific routes
-	protected := payments.Group("", middleware.JWTMiddleware(cfg))
+	// Support staff impersonating a customer cannot pay or refund on their behalf
+	protected := payments.Group("", middleware.JWTMiddleware(cfg), middleware.NoImpersonation())
 	protected.Post("/initialize", handler.InitializePayment)
 	protected.Post("/process", handler.ProcessPayment)
 	protected.Get("/:id", handler.GetPayment)
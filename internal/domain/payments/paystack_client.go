@@ -22,6 +22,7 @@ type PaystackWebhookEvent struct {
 		Status          string `json:"status"`
 		Reference       string `json:"reference"`
 		Amount          int64  `json:"amount"`
+		Fees            int64  `json:"fees"`
 		Message         string `json:"message"`
 		GatewayResponse string `json:"gateway_response"`
 		PaidAt          string `json:"paid_at"`
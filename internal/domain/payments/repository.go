@@ -15,6 +15,7 @@ type Repository interface {
 	GetPaymentsByCustomerID(customerID uint) ([]Payment, error)
 	UpdatePayment(payment *Payment) error
 	UpdatePaymentStatus(id string, status PaymentStatus, providerRef, providerResponse string) error
+	UpdatePaymentSettlement(id string, feesKobo, settlementKobo int64) error
 
 	// Order operations
 	CreateOrder(order *Order) error
@@ -107,6 +108,13 @@ func (r *repository) UpdatePaymentStatus(id string, status PaymentStatus, provid
 	return r.db.Model(&Payment{}).Where("id = ?", id).Updates(updates).Error
 }
 
+func (r *repository) UpdatePaymentSettlement(id string, feesKobo, settlementKobo int64) error {
+	return r.db.Model(&Payment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"fees_kobo":       feesKobo,
+		"settlement_kobo": settlementKobo,
+	}).Error
+}
+
 // Refund operations
 func (r *repository) CreateRefund(refund *PaymentRefund) error {
 	return r.db.Create(refund).Error
@@ -22,6 +22,7 @@ const (
 // OrderServiceInterface defines the interface for orders service operations
 type OrderServiceInterface interface {
 	AdminUpdatePaymentStatus(ctx context.Context, id uuid.UUID, paymentStatus interface{}) error
+	ConfirmPaidOrder(ctx context.Context, id uuid.UUID) error
 }
 
 type Service interface {
@@ -37,6 +38,7 @@ type Service interface {
 	InitializePaystackPayment(email string, amount int64, metadata map[string]interface{}) (*PaystackInitializeResponse, error)
 	VerifyPaystackPayment(reference string) (*PaystackVerifyResponse, error)
 	ProcessPaystackWebhook(signature string, payload []byte) error
+	SetTipRecorder(recorder TipRecorder)
 
 	// Refund operations
 	InitiateRefund(req RefundPaymentRequest) (*RefundResponse, error)
@@ -55,6 +57,7 @@ type service struct {
 	repo           Repository
 	paystackClient *PaystackClient
 	orderService   OrderServiceInterface
+	tipRecorder    TipRecorder // see SetTipRecorder
 }
 
 func NewService(repo Repository, paystackClient *PaystackClient, orderService OrderServiceInterface) Service {
@@ -128,6 +131,8 @@ func (s *service) ProcessPayment(req ProcessPaymentRequest) error {
 			if err := s.orderService.AdminUpdatePaymentStatus(context.Background(), orderID, OrderPaymentStatusPaid); err != nil {
 				// Log error but don't fail the payment processing
 				fmt.Printf("Warning: Failed to update order payment status: %v\n", err)
+			} else if err := s.orderService.ConfirmPaidOrder(context.Background(), orderID); err != nil {
+				fmt.Printf("Warning: Failed to confirm paid order: %v\n", err)
 			}
 		}
 	}
@@ -320,6 +325,8 @@ func (s *service) toPaymentResponse(payment *Payment) *PaymentResponse {
         CustomerID:     payment.CustomerID,
         AmountKobo:     payment.AmountKobo,
         AmountNaira:    float64(payment.AmountKobo) / 100,
+        FeesKobo:       payment.FeesKobo,
+        SettlementKobo: payment.SettlementKobo,
         Currency:       payment.Currency,
         PaymentMethod:  payment.PaymentMethod,
         Status:         payment.Status,
@@ -405,6 +412,10 @@ func (s *service) VerifyPaystackPayment(reference string) (*PaystackVerifyRespon
 			if err := s.repo.UpdateOrderStatus(reference, OrderStatusConfirmed); err != nil {
 				return nil, fmt.Errorf("failed to update order status: %w", err)
 			}
+			s.recordPaystackSettlement(reference, verifyResp.Data.Amount, verifyResp.Data.Fees)
+			if isTipPayment(order) {
+				s.confirmTip(reference)
+			}
 		} else {
 			return nil, errors.New("amount mismatch")
 		}
@@ -446,6 +457,13 @@ func (s *service) ProcessPaystackWebhook(signature string, payload []byte) error
 			if err := s.repo.UpdateOrderStatus(reference, OrderStatusConfirmed); err != nil {
 				return fmt.Errorf("failed to update order status in payments: %w", err)
 			}
+			s.recordPaystackSettlement(reference, amount, event.Data.Fees)
+
+			// A post-delivery tip is recorded for the driver; it does not pay for an order
+			if isTipPayment(order) {
+				s.confirmTip(reference)
+				return nil
+			}
 
 			// Also update payment status in orders domain
 			if s.orderService != nil {
@@ -456,6 +474,9 @@ func (s *service) ProcessPaystackWebhook(signature string, payload []byte) error
 						// Log error but don't fail the webhook processing
 						return fmt.Errorf("failed to update payment status in orders domain: %w", err)
 					}
+					if err := s.orderService.ConfirmPaidOrder(ctx, orderUUID); err != nil {
+						fmt.Printf("Warning: Failed to confirm paid order: %v\n", err)
+					}
 				}
 			}
 		}
@@ -463,3 +484,22 @@ func (s *service) ProcessPaystackWebhook(signature string, payload []byte) error
 
 	return nil
 }
+
+// recordPaystackSettlement stores the fee Paystack deducted and the resulting settlement
+// on the payment for reference. The reference is either our transaction ref or the order ID.
+// Failures are logged only: the charge itself has already succeeded.
+func (s *service) recordPaystackSettlement(reference string, amountKobo, feesKobo int64) {
+	payment, err := s.repo.GetPaymentByTransactionRef(reference)
+	if err != nil {
+		payments, listErr := s.repo.GetPaymentsByOrderID(reference)
+		if listErr != nil || len(payments) == 0 {
+			fmt.Printf("Warning: No payment found to record Paystack fees for %s\n", reference)
+			return
+		}
+		payment = &payments[0]
+	}
+
+	if err := s.repo.UpdatePaymentSettlement(payment.ID, feesKobo, amountKobo-feesKobo); err != nil {
+		fmt.Printf("Warning: Failed to record Paystack fees for payment %s: %v\n", payment.ID, err)
+	}
+}
@@ -0,0 +1,43 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PurposeDeliveryTip is the metadata "purpose" of a Paystack charge for a tip added after delivery.
+// Such charges are not order payments: confirming one records the tip rather than marking an
+// order paid.
+const PurposeDeliveryTip = "delivery_tip"
+
+// TipRecorder records a post-delivery tip once the Paystack charge for it has succeeded. It must
+// be safe to call more than once for the same reference (verify and webhook both confirm).
+type TipRecorder interface {
+	ConfirmTipPayment(reference string) error
+}
+
+// SetTipRecorder sets who records tips paid through Paystack
+func (s *service) SetTipRecorder(recorder TipRecorder) {
+	s.tipRecorder = recorder
+}
+
+// isTipPayment reports whether a Paystack order was opened for a post-delivery tip
+func isTipPayment(order *Order) bool {
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(order.Metadata), &metadata); err != nil {
+		return false
+	}
+	return metadata["purpose"] == PurposeDeliveryTip
+}
+
+// confirmTip hands a successful tip charge to the tip recorder. Failures are logged only, as with
+// settlement: the charge itself has already succeeded and a later verify will retry.
+func (s *service) confirmTip(reference string) {
+	if s.tipRecorder == nil {
+		fmt.Printf("Warning: No tip recorder to record tip payment %s\n", reference)
+		return
+	}
+	if err := s.tipRecorder.ConfirmTipPayment(reference); err != nil {
+		fmt.Printf("Warning: Failed to record tip payment %s: %v\n", reference, err)
+	}
+}
@@ -0,0 +1,175 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrProductUnavailable is returned when a product is ordered outside its availability schedule
+var ErrProductUnavailable = errors.New("product unavailable")
+
+// ErrInvalidAvailability is returned when a product's availability schedule is malformed
+var ErrInvalidAvailability = errors.New("invalid availability schedule")
+
+// weekdayCodes maps the day codes used in AvailableDays to time.Weekday, in schedule order
+var weekdayCodes = []struct {
+	code string
+	day  time.Weekday
+}{
+	{"mon", time.Monday},
+	{"tue", time.Tuesday},
+	{"wed", time.Wednesday},
+	{"thu", time.Thursday},
+	{"fri", time.Friday},
+	{"sat", time.Saturday},
+	{"sun", time.Sunday},
+}
+
+// HasAvailabilitySchedule reports whether the product is restricted to certain days or hours
+func (p *Product) HasAvailabilitySchedule() bool {
+	return len(p.AvailableDays) > 0 || p.AvailableFrom != ""
+}
+
+// AvailableAt reports whether the product can be ordered at t. Callers pass t in the store's
+// timezone; the schedule's days and clock times are read in t's location.
+func (p *Product) AvailableAt(t time.Time) bool {
+	if !p.availableOn(t.Weekday()) {
+		return false
+	}
+	from, until, ok := p.window()
+	if !ok {
+		return true
+	}
+	minute := t.Hour()*60 + t.Minute()
+	return minute >= from && minute < until
+}
+
+// NextAvailableAt returns when the product next becomes orderable after t, or nil if it is
+// available at t or has no schedule
+func (p *Product) NextAvailableAt(t time.Time) *time.Time {
+	if p.AvailableAt(t) {
+		return nil
+	}
+	from, _, _ := p.window()
+	for days := 0; days <= 7; days++ {
+		start := time.Date(t.Year(), t.Month(), t.Day()+days, from/60, from%60, 0, 0, t.Location())
+		if p.availableOn(start.Weekday()) && start.After(t) {
+			return &start
+		}
+	}
+	return nil
+}
+
+// CheckAvailability returns ErrProductUnavailable, with the product's hours and when it is next
+// available, if the product cannot be ordered at t
+func (p *Product) CheckAvailability(t time.Time) error {
+	if p.AvailableAt(t) {
+		return nil
+	}
+	err := fmt.Errorf("%w: %s is only available %s", ErrProductUnavailable, p.Name, p.describeSchedule())
+	if next := p.NextAvailableAt(t); next != nil {
+		err = fmt.Errorf("%w; next available %s", err, next.Format("Mon 2 Jan 15:04"))
+	}
+	return err
+}
+
+// availableOn reports whether day is one of the product's days; no days means every day
+func (p *Product) availableOn(day time.Weekday) bool {
+	if len(p.AvailableDays) == 0 {
+		return true
+	}
+	for _, code := range p.AvailableDays {
+		if d, ok := weekdayFromCode(code); ok && d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the daily time window in minutes after midnight; ok is false when the product
+// is available all day
+func (p *Product) window() (from, until int, ok bool) {
+	if p.AvailableFrom == "" || p.AvailableUntil == "" {
+		return 0, 0, false
+	}
+	from, errFrom := parseClock(p.AvailableFrom)
+	until, errUntil := parseClock(p.AvailableUntil)
+	if errFrom != nil || errUntil != nil {
+		return 0, 0, false
+	}
+	return from, until, true
+}
+
+// describeSchedule renders the schedule for customers, e.g. "08:00-20:00 on Mon, Tue"
+func (p *Product) describeSchedule() string {
+	hours := "all day"
+	if _, _, ok := p.window(); ok {
+		hours = p.AvailableFrom + "-" + p.AvailableUntil
+	}
+	if len(p.AvailableDays) == 0 {
+		return hours + " every day"
+	}
+	days := make([]string, 0, len(p.AvailableDays))
+	for _, code := range p.AvailableDays {
+		days = append(days, strings.ToUpper(code[:1])+code[1:])
+	}
+	return hours + " on " + strings.Join(days, ", ")
+}
+
+// normalizeAvailability validates a schedule and returns its days lowercased, de-duplicated and
+// in week order. The window is optional, but from and until must be set together with from first.
+func normalizeAvailability(days StringSlice, from, until string) (StringSlice, error) {
+	seen := make(map[time.Weekday]bool, len(days))
+	for _, code := range days {
+		day, ok := weekdayFromCode(code)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown day %q", ErrInvalidAvailability, code)
+		}
+		seen[day] = true
+	}
+	normalized := StringSlice{}
+	for _, wd := range weekdayCodes {
+		if seen[wd.day] {
+			normalized = append(normalized, wd.code)
+		}
+	}
+
+	if (from == "") != (until == "") {
+		return nil, fmt.Errorf("%w: availableFrom and availableUntil must be set together", ErrInvalidAvailability)
+	}
+	if from != "" {
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(until)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("%w: availableUntil must be after availableFrom", ErrInvalidAvailability)
+		}
+	}
+	return normalized, nil
+}
+
+func weekdayFromCode(code string) (time.Weekday, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, wd := range weekdayCodes {
+		if wd.code == code {
+			return wd.day, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock reads a 24-hour "HH:MM" time as minutes after midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a 24-hour HH:MM time", ErrInvalidAvailability, clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,62 @@
+package products_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	products "errandShop/internal/domain/products"
+)
+
+func TestProductAvailability(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	hotFood := products.Product{
+		Name:           "Jollof rice",
+		AvailableDays:  products.StringSlice{"mon", "tue", "wed", "thu", "fri"},
+		AvailableFrom:  "08:00",
+		AvailableUntil: "20:00",
+	}
+
+	// 2025-06-02 is a Monday
+	tests := []struct {
+		name      string
+		at        time.Time
+		available bool
+		next      time.Time
+	}{
+		{"inside the window", time.Date(2025, 6, 2, 12, 30, 0, 0, lagos), true, time.Time{}},
+		{"at opening", time.Date(2025, 6, 2, 8, 0, 0, 0, lagos), true, time.Time{}},
+		{"before opening", time.Date(2025, 6, 2, 6, 15, 0, 0, lagos), false, time.Date(2025, 6, 2, 8, 0, 0, 0, lagos)},
+		{"at closing", time.Date(2025, 6, 2, 20, 0, 0, 0, lagos), false, time.Date(2025, 6, 3, 8, 0, 0, 0, lagos)},
+		{"friday night waits for monday", time.Date(2025, 6, 6, 21, 0, 0, 0, lagos), false, time.Date(2025, 6, 9, 8, 0, 0, 0, lagos)},
+		{"weekend", time.Date(2025, 6, 7, 12, 0, 0, 0, lagos), false, time.Date(2025, 6, 9, 8, 0, 0, 0, lagos)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hotFood.AvailableAt(tt.at); got != tt.available {
+				t.Fatalf("AvailableAt(%v) = %v, want %v", tt.at, got, tt.available)
+			}
+			next := hotFood.NextAvailableAt(tt.at)
+			switch {
+			case tt.available && next != nil:
+				t.Fatalf("NextAvailableAt(%v) = %v, want nil", tt.at, *next)
+			case !tt.available && (next == nil || !next.Equal(tt.next)):
+				t.Fatalf("NextAvailableAt(%v) = %v, want %v", tt.at, next, tt.next)
+			}
+
+			err := hotFood.CheckAvailability(tt.at)
+			if tt.available != (err == nil) {
+				t.Fatalf("CheckAvailability(%v) error = %v", tt.at, err)
+			}
+			if err != nil && (!errors.Is(err, products.ErrProductUnavailable) || !strings.Contains(err.Error(), "next available")) {
+				t.Fatalf("expected ErrProductUnavailable with the next time, got %v", err)
+			}
+		})
+	}
+
+	unscheduled := products.Product{Name: "Rice"}
+	if !unscheduled.AvailableAt(time.Date(2025, 6, 8, 3, 0, 0, 0, lagos)) {
+		t.Fatal("products without a schedule should always be available")
+	}
+}
@@ -0,0 +1,72 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidBarcode is returned for a barcode that is not 4-64 letters, digits or hyphens
+	ErrInvalidBarcode = errors.New("invalid barcode")
+	// ErrBarcodeInUse is returned when a barcode is already on another product
+	ErrBarcodeInUse = errors.New("barcode is already assigned to another product")
+)
+
+// normalizeBarcode trims a scanned or typed barcode and checks its characters. An empty code
+// returns nil, meaning the product has no barcode.
+func normalizeBarcode(code string) (*string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, nil
+	}
+	if len(code) < 4 || len(code) > 64 {
+		return nil, fmt.Errorf("%w: must be 4 to 64 characters", ErrInvalidBarcode)
+	}
+	for _, r := range code {
+		if !(r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r == '-') {
+			return nil, fmt.Errorf("%w: %q may only contain letters, digits and hyphens", ErrInvalidBarcode, code)
+		}
+	}
+	return &code, nil
+}
+
+// checkBarcodeFree returns ErrBarcodeInUse if another product, active or not, already has the barcode.
+// productID is uuid.Nil for a product that is being created.
+func (s *Service) checkBarcodeFree(ctx context.Context, productID uuid.UUID, barcode *string) error {
+	if barcode == nil {
+		return nil
+	}
+	existing, err := s.repo.FindByBarcode(ctx, *barcode, false)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to check barcode: %w", err)
+	}
+	if existing.ID != productID {
+		return fmt.Errorf("%w: %s (%s)", ErrBarcodeInUse, existing.Name, existing.SKU)
+	}
+	return nil
+}
+
+// GetByBarcode resolves a scanned barcode to its active product
+func (s *Service) GetByBarcode(ctx context.Context, code string) (*ProductResponse, error) {
+	barcode, err := normalizeBarcode(code)
+	if err != nil {
+		return nil, err
+	}
+	if barcode == nil {
+		return nil, fmt.Errorf("%w: barcode is required", ErrInvalidBarcode)
+	}
+
+	product, err := s.repo.FindByBarcode(ctx, *barcode, true)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+	return s.toProductResponse(product), nil
+}
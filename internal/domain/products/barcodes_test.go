@@ -0,0 +1,25 @@
+package products
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	got, err := normalizeBarcode("  5012345678900 ")
+	if err != nil || got == nil || *got != "5012345678900" {
+		t.Fatalf("EAN-13 with spaces: got %v, %v", got, err)
+	}
+	if got, err := normalizeBarcode("SHELF-A12"); err != nil || *got != "SHELF-A12" {
+		t.Fatalf("shelf label: got %v, %v", got, err)
+	}
+	if got, err := normalizeBarcode("   "); err != nil || got != nil {
+		t.Fatalf("blank code should mean no barcode: got %v, %v", got, err)
+	}
+
+	for _, code := range []string{"123", "5012 3456", "50123456789'; --"} {
+		if _, err := normalizeBarcode(code); !errors.Is(err, ErrInvalidBarcode) {
+			t.Fatalf("%q: expected ErrInvalidBarcode, got %v", code, err)
+		}
+	}
+}
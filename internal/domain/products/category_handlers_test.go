@@ -26,6 +26,7 @@ func setupTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL UNIQUE,
 			description TEXT,
+			parent_id TEXT,
 			is_active BOOLEAN DEFAULT 1,
 			created_at DATETIME,
 			updated_at DATETIME
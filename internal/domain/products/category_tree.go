@@ -0,0 +1,148 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCategoryCycle is returned when a category would become its own ancestor
+	ErrCategoryCycle = errors.New("a category cannot be placed under itself or one of its subcategories")
+	// ErrParentCategoryNotFound is returned when the requested parent is missing or inactive
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	// ErrCategoryHasSubcategories is returned when deleting a category that still has active children
+	ErrCategoryHasSubcategories = errors.New("category has subcategories; move or delete them first")
+)
+
+// GetCategoryTree returns active categories nested under their parents. Categories whose parent
+// is inactive are shown at the top level.
+func (s *Service) GetCategoryTree(ctx context.Context) ([]CategoryTreeNode, error) {
+	cats, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		s.logger.Printf("Error listing categories for tree: %v", err)
+		return nil, fmt.Errorf("failed to get category tree: %w", err)
+	}
+	return buildCategoryTree(cats), nil
+}
+
+// validateCategoryParent checks that parentID is an active category and that placing category id
+// under it does not create a cycle. id is uuid.Nil for categories not created yet.
+func (s *Service) validateCategoryParent(ctx context.Context, id, parentID uuid.UUID) error {
+	if parentID == id {
+		return ErrCategoryCycle
+	}
+	cats, err := s.repo.ListActiveCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load categories: %w", err)
+	}
+	parents := make(map[uuid.UUID]*uuid.UUID, len(cats))
+	for _, c := range cats {
+		parents[c.ID] = c.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		return ErrParentCategoryNotFound
+	}
+
+	// Walk up from the new parent; reaching id means id would be its own ancestor
+	seen := map[uuid.UUID]bool{}
+	for current := &parentID; current != nil; current = parents[*current] {
+		if *current == id || seen[*current] {
+			return ErrCategoryCycle
+		}
+		seen[*current] = true
+	}
+	return nil
+}
+
+// resolveCategoryFilter returns the category names a product filter on name should match:
+// just name, or name and all its descendants when includeSubcategories is set.
+func (s *Service) resolveCategoryFilter(ctx context.Context, name string, includeSubcategories bool) ([]string, error) {
+	if !includeSubcategories {
+		return []string{name}, nil
+	}
+	cats, err := s.repo.ListActiveCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	return descendantCategoryNames(cats, name), nil
+}
+
+// descendantCategoryNames returns name followed by the names of every category below it
+func descendantCategoryNames(cats []Category, name string) []string {
+	children := make(map[uuid.UUID][]Category)
+	var root *Category
+	for i := range cats {
+		if cats[i].ParentID != nil {
+			children[*cats[i].ParentID] = append(children[*cats[i].ParentID], cats[i])
+		}
+		if root == nil && strings.EqualFold(cats[i].Name, name) {
+			root = &cats[i]
+		}
+	}
+
+	names := []string{name}
+	if root == nil {
+		return names
+	}
+	seen := map[uuid.UUID]bool{root.ID: true}
+	queue := []uuid.UUID{root.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			names = append(names, child.Name)
+			queue = append(queue, child.ID)
+		}
+	}
+	return names
+}
+
+// buildCategoryTree nests categories under their parents, keeping the input order among siblings
+func buildCategoryTree(cats []CategoryResponse) []CategoryTreeNode {
+	known := make(map[uuid.UUID]bool, len(cats))
+	for _, c := range cats {
+		known[c.ID] = true
+	}
+	children := make(map[uuid.UUID][]CategoryResponse)
+	var roots []CategoryResponse
+	for _, c := range cats {
+		if c.ParentID != nil && known[*c.ParentID] && *c.ParentID != c.ID {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+			continue
+		}
+		roots = append(roots, c)
+	}
+
+	var build func(c CategoryResponse, depth int) CategoryTreeNode
+	build = func(c CategoryResponse, depth int) CategoryTreeNode {
+		node := CategoryTreeNode{
+			CategoryResponse:  c,
+			TotalProductCount: c.ProductCount,
+			Children:          []CategoryTreeNode{},
+		}
+		// Guards against cycles left in the data by direct DB edits
+		if depth > len(cats) {
+			return node
+		}
+		for _, child := range children[c.ID] {
+			childNode := build(child, depth+1)
+			node.TotalProductCount += childNode.TotalProductCount
+			node.Children = append(node.Children, childNode)
+		}
+		return node
+	}
+
+	tree := make([]CategoryTreeNode, 0, len(roots))
+	for _, r := range roots {
+		tree = append(tree, build(r, 0))
+	}
+	return tree
+}
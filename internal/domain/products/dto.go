@@ -9,6 +9,20 @@ type ListQuery struct {
 	Q     string
 	Page  int
 	Limit int
+
+	// Category filters by category name; IncludeSubcategories widens it to the whole subtree
+	Category             string `query:"category"`
+	IncludeSubcategories bool   `query:"include_subcategories"`
+	categories           []string // Category plus its descendants, resolved by the service
+	inStockOnly          bool     // leave out zero-stock products; set by the service from SetShowOutOfStock
+}
+
+// categoryNames returns the category names a Category filter matches
+func (q ListQuery) categoryNames() []string {
+	if len(q.categories) > 0 {
+		return q.categories
+	}
+	return []string{q.Category}
 }
 
 // Product DTOs
@@ -23,6 +37,18 @@ type CreateProductRequest struct {
 	Category          string   `json:"category" validate:"required,min=2,max=100"`
 	Tags              StringSlice `json:"tags" validate:"omitempty,dive,min=1,max=50"`
 	LowStockThreshold int      `json:"lowStockThreshold" validate:"min=0"`
+	WeightGrams       int      `json:"weightGrams" validate:"min=0,max=1000000"`
+	MinOrderQty       int      `json:"minOrderQty" validate:"min=0"` // 0 = no minimum
+	MaxOrderQty       int      `json:"maxOrderQty" validate:"min=0"` // 0 = no maximum
+	AvailableDays     StringSlice `json:"availableDays"`  // mon..sun; empty = every day
+	AvailableFrom     string      `json:"availableFrom"`  // "08:00"; empty = all day
+	AvailableUntil    string      `json:"availableUntil"` // "20:00"
+	SalePrice         *float64   `json:"salePrice" validate:"omitempty,gt=0"`
+	SaleStartsAt      *time.Time `json:"saleStartsAt"`
+	SaleEndsAt        *time.Time `json:"saleEndsAt"`
+	IsFeatured        bool       `json:"isFeatured"`
+	SubstituteProductID *uuid.UUID `json:"substituteProductId"` // offered in place of this product when it runs short
+	Barcode           string     `json:"barcode" validate:"max=64"`
 }
 
 type UpdateProductRequest struct {
@@ -36,26 +62,60 @@ type UpdateProductRequest struct {
 	Category          *string   `json:"category" validate:"omitempty,min=2,max=100"`
 	Tags              *StringSlice `json:"tags" validate:"omitempty,dive,min=1,max=50"`
 	LowStockThreshold *int      `json:"lowStockThreshold" validate:"omitempty,min=0"`
+	WeightGrams       *int      `json:"weightGrams" validate:"omitempty,min=0,max=1000000"`
+	MinOrderQty       *int      `json:"minOrderQty" validate:"omitempty,min=0"` // 0 removes the limit
+	MaxOrderQty       *int      `json:"maxOrderQty" validate:"omitempty,min=0"`
+	AvailableDays     *StringSlice `json:"availableDays"`  // an empty list sells the product every day
+	AvailableFrom     *string      `json:"availableFrom"`  // "" together with availableUntil "" sells it all day
+	AvailableUntil    *string      `json:"availableUntil"`
 	IsActive          *bool     `json:"isActive" validate:"omitempty"`
+	IsFeatured        *bool     `json:"isFeatured"`
+	SubstituteProductID *uuid.UUID `json:"substituteProductId"`
+	ClearSubstitute   bool       `json:"clearSubstitute"` // removes the substitute product
+	Barcode           *string    `json:"barcode" validate:"omitempty,max=64"` // "" removes the barcode
+	SalePrice         *float64   `json:"salePrice" validate:"omitempty,gt=0"`
+	SaleStartsAt      *time.Time `json:"saleStartsAt"`
+	SaleEndsAt        *time.Time `json:"saleEndsAt"`
+	ClearSale         bool       `json:"clearSale"` // removes the sale price and window
 }
 
 type ProductResponse struct {
 	ID                uuid.UUID `json:"id"`
 	Name              string    `json:"name"`
 	SKU               string    `json:"sku"`
+	Barcode           *string   `json:"barcode,omitempty"`
 	Slug              string    `json:"slug"`
 	Description       string    `json:"description"`
 	CostPrice         float64   `json:"costPrice"`
 	SellingPrice      float64   `json:"sellingPrice"`
+	EffectivePrice    float64    `json:"effectivePrice"`          // what the customer pays right now
+	OriginalPrice     *float64   `json:"originalPrice,omitempty"` // selling price to strike through while on sale
+	OnSale            bool       `json:"onSale"`
+	SalePrice         *float64   `json:"salePrice,omitempty"`
+	SaleStartsAt      *time.Time `json:"saleStartsAt,omitempty"`
+	SaleEndsAt        *time.Time `json:"saleEndsAt,omitempty"`
 	Profit            float64   `json:"profit"`
 	StockQuantity     int       `json:"stockQuantity"`
 	ImageURL          string    `json:"imageUrl"`
 	ImagePublicID     string    `json:"imagePublicId"`
+	Images            []ProductImageResponse `json:"images"` // gallery in display order; imageUrl is its primary image
 	Category          string    `json:"category"`
 	Tags              StringSlice  `json:"tags"`
 	LowStockThreshold int       `json:"lowStockThreshold"`
+	WeightGrams       int       `json:"weightGrams"`
+	MinOrderQty       int       `json:"minOrderQty"`
+	MaxOrderQty       int       `json:"maxOrderQty"`
+	AvailableDays     StringSlice `json:"availableDays,omitempty"`
+	AvailableFrom     string      `json:"availableFrom,omitempty"`
+	AvailableUntil    string      `json:"availableUntil,omitempty"`
+	IsAvailableNow    bool        `json:"isAvailableNow"`            // false outside the availability schedule
+	NextAvailableAt   *time.Time  `json:"nextAvailableAt,omitempty"` // when an unavailable product can next be ordered
 	IsLowStock        bool      `json:"isLowStock"`
+	IsPurchasable     bool      `json:"isPurchasable"` // false when out of stock or inactive; clients disable the buy button
 	IsActive          bool      `json:"isActive"`
+	IsFeatured        bool      `json:"isFeatured"`
+	SubstituteProductID *uuid.UUID `json:"substituteProductId,omitempty"`
+	IsWishlisted      bool      `json:"isWishlisted"` // set on product detail for a signed-in customer
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
 }
@@ -64,7 +124,8 @@ type ProductResponse struct {
 type StockUpdateRequest struct {
 	Quantity   int    `json:"quantity" validate:"required"`
 	ChangeType string `json:"changeType" validate:"required,oneof=ADD REMOVE ADJUST"`
-	Reason     string `json:"reason" validate:"omitempty,max=500"`
+	ReasonCode StockChangeReason `json:"reasonCode" validate:"omitempty,oneof=sale cancellation restock damage correction theft"`
+	Reason     string `json:"reason" validate:"omitempty,max=500"` // free-text note; also accepted alone for older clients
 }
 
 type StockUpdateResponse struct {
@@ -75,23 +136,40 @@ type StockUpdateResponse struct {
 
 // Category DTOs
 type CreateCategoryRequest struct {
-	Name        string `json:"name" validate:"required,min=2,max=100"`
-	Description string `json:"description" validate:"omitempty,max=500"`
+	Name        string     `json:"name" validate:"required,min=2,max=100"`
+	Description string     `json:"description" validate:"omitempty,max=500"`
+	ParentID    *uuid.UUID `json:"parentId"`
 }
 
 type UpdateCategoryRequest struct {
 	Name        *string `json:"name" validate:"omitempty,min=2,max=100"`
 	Description *string `json:"description" validate:"omitempty,max=500"`
+	ParentID    *string `json:"parentId"` // a category ID, or "" to make the category top-level
 	IsActive    *bool   `json:"isActive" validate:"omitempty"`
 }
 
+// TagResponse is a distinct product tag and the number of active products carrying it
+type TagResponse struct {
+	Name         string `json:"name"`
+	ProductCount int    `json:"productCount"`
+}
+
 type CategoryResponse struct {
-	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	ProductCount int       `json:"productCount"`
-	IsActive     bool      `json:"isActive"`
-	CreatedAt    time.Time `json:"createdAt"`
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	ParentID     *uuid.UUID `json:"parentId"`
+	ProductCount int        `json:"productCount"`
+	IsActive     bool       `json:"isActive"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// CategoryTreeNode is a category with its subcategories. ProductCount covers the category itself;
+// TotalProductCount adds every descendant.
+type CategoryTreeNode struct {
+	CategoryResponse
+	TotalProductCount int                `json:"totalProductCount"`
+	Children          []CategoryTreeNode `json:"children"`
 }
 
 // Image Upload DTOs
@@ -104,10 +182,32 @@ type ImageUploadResponse struct {
 	Format      string `json:"format"`
 }
 
+// ProductImageResponse is one image of a product's gallery
+type ProductImageResponse struct {
+	ID        uuid.UUID `json:"id"` // nil for a product's image saved before galleries existed
+	URL       string    `json:"url"`
+	PublicID  string    `json:"publicId"`
+	SortOrder int       `json:"sortOrder"`
+	IsPrimary bool      `json:"isPrimary"`
+}
+
+// AddProductImageRequest adds an already hosted image to a product's gallery
+type AddProductImageRequest struct {
+	ImageURL      string `json:"imageUrl" validate:"required,url"`
+	ImagePublicID string `json:"imagePublicId"`
+	IsPrimary     bool   `json:"isPrimary"`
+}
+
+// ReorderProductImagesRequest lists every image of a product's gallery in its new display order
+type ReorderProductImagesRequest struct {
+	ImageIDs []uuid.UUID `json:"imageIds" validate:"required,min=1"`
+}
+
 // Bulk Operations DTOs
 type BulkUpdateStockRequest struct {
-	Updates []BulkStockUpdate `json:"updates" validate:"required,dive"`
-	Reason  string            `json:"reason" validate:"omitempty,max=500"`
+	Updates    []BulkStockUpdate `json:"updates" validate:"required,dive"`
+	ReasonCode StockChangeReason `json:"reasonCode" validate:"omitempty,oneof=sale cancellation restock damage correction theft"`
+	Reason     string            `json:"reason" validate:"omitempty,max=500"`
 }
 
 type BulkStockUpdate struct {
@@ -119,7 +219,6 @@ type BulkStockUpdate struct {
 // Query DTOs
 type AdminListQuery struct {
 	ListQuery
-	Category   string `query:"category"`
 	LowStock   bool   `query:"low_stock"`
 	OutOfStock bool   `query:"out_of_stock"`
 	Search     string `query:"search"`
@@ -143,6 +242,23 @@ type CategoryStats struct {
 	TotalValue   float64 `json:"totalValue"`
 }
 
+// StockShrinkageByReason sums the stock decreases recorded under one reason code
+type StockShrinkageByReason struct {
+	Reason    StockChangeReason `json:"reason"`
+	Movements int64             `json:"movements"`
+	Units     int64             `json:"units"`
+	CostValue float64           `json:"costValue"` // units lost at each product's current cost price, in naira
+}
+
+// StockShrinkageReport covers stock lost without a sale between From (inclusive) and To (exclusive)
+type StockShrinkageReport struct {
+	From           time.Time                `json:"from"`
+	To             time.Time                `json:"to"`
+	Reasons        []StockShrinkageByReason `json:"reasons"`
+	TotalUnits     int64                    `json:"totalUnits"`
+	TotalCostValue float64                  `json:"totalCostValue"`
+}
+
 // List Result DTOs
 type ListResult struct {
 	Data []ProductResponse `json:"data"`
@@ -0,0 +1,331 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"errandShop/internal/services/upload"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxProductImages caps a product's gallery
+const MaxProductImages = 10
+
+var (
+	// ErrTooManyImages is returned when adding an image to a full gallery
+	ErrTooManyImages = fmt.Errorf("a product can have at most %d images", MaxProductImages)
+	// ErrInvalidImageOrder is returned when a reorder does not list each of the product's images exactly once
+	ErrInvalidImageOrder = errors.New("image order must list each of the product's images exactly once")
+)
+
+// ProductImage is one picture in a product's gallery. Exactly one image of a non-empty gallery is
+// primary, and the product's ImageURL and ImagePublicID always mirror it, so clients that only read
+// ImageURL keep working.
+type ProductImage struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index" json:"productId"`
+	URL       string    `gorm:"size:500;not null" json:"url"`
+	PublicID  string    `gorm:"size:255" json:"publicId"` // Cloudinary asset; empty for externally hosted images
+	SortOrder int       `gorm:"not null;default:0" json:"sortOrder"`
+	IsPrimary bool      `gorm:"not null;default:false" json:"isPrimary"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate GORM hook
+func (i *ProductImage) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// orderedImages preloads a product's gallery in display order
+func orderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, created_at ASC")
+}
+
+// galleryResponse lists the gallery in display order. Products saved before galleries existed may
+// have only ImageURL, which is shown as a one-image gallery.
+func galleryResponse(product *Product) []ProductImageResponse {
+	images := make([]ProductImageResponse, 0, len(product.Images))
+	for _, image := range product.Images {
+		images = append(images, ProductImageResponse{
+			ID:        image.ID,
+			URL:       image.URL,
+			PublicID:  image.PublicID,
+			SortOrder: image.SortOrder,
+			IsPrimary: image.IsPrimary,
+		})
+	}
+	if len(images) == 0 && product.ImageURL != "" {
+		images = append(images, ProductImageResponse{URL: product.ImageURL, PublicID: product.ImagePublicID, IsPrimary: true})
+	}
+	return images
+}
+
+// AddImage adds an already hosted image to the end of a product's gallery. The first image, or one
+// added with IsPrimary, becomes the primary image.
+func (s *Service) AddImage(ctx context.Context, productID uuid.UUID, req AddProductImageRequest) (*ProductResponse, error) {
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+	image := &ProductImage{
+		ProductID: productID,
+		URL:       strings.TrimSpace(req.ImageURL),
+		PublicID:  req.ImagePublicID,
+		IsPrimary: req.IsPrimary,
+	}
+	if err := s.repo.AddProductImage(ctx, image); err != nil {
+		return nil, err
+	}
+	return s.galleryChanged(ctx, productID)
+}
+
+// UploadGalleryImage hosts an image on Cloudinary and adds it to the product's gallery
+func (s *Service) UploadGalleryImage(ctx context.Context, productID uuid.UUID, file *multipart.FileHeader, primary bool) (*ProductResponse, error) {
+	if !s.images.Configured() {
+		return nil, upload.ErrCloudinaryNotConfigured
+	}
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	result, err := s.images.UploadProductImage(file)
+	if err != nil {
+		return nil, err
+	}
+	image := &ProductImage{ProductID: productID, URL: result.SecureURL, PublicID: result.PublicID, IsPrimary: primary}
+	if err := s.repo.AddProductImage(ctx, image); err != nil {
+		// Don't leave an orphaned asset behind if we couldn't record it
+		if delErr := s.images.DeleteImage(result.PublicID); delErr != nil {
+			s.logger.Printf("Failed to remove orphaned image %s: %v", result.PublicID, delErr)
+		}
+		return nil, err
+	}
+	return s.galleryChanged(ctx, productID)
+}
+
+// ReorderImages sets the gallery's display order; imageIDs must list every image of the product
+func (s *Service) ReorderImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) (*ProductResponse, error) {
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+	if len(imageIDs) != len(product.Images) {
+		return nil, ErrInvalidImageOrder
+	}
+	listed := make(map[uuid.UUID]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		listed[id] = true
+	}
+	for _, image := range product.Images {
+		if !listed[image.ID] {
+			return nil, ErrInvalidImageOrder
+		}
+	}
+
+	if err := s.repo.ReorderProductImages(ctx, productID, imageIDs); err != nil {
+		return nil, fmt.Errorf("failed to reorder images: %w", err)
+	}
+	return s.galleryChanged(ctx, productID)
+}
+
+// SetPrimaryImage makes a gallery image the product's primary image
+func (s *Service) SetPrimaryImage(ctx context.Context, productID, imageID uuid.UUID) (*ProductResponse, error) {
+	if err := s.repo.SetPrimaryProductImage(ctx, productID, imageID); err != nil {
+		return nil, err
+	}
+	return s.galleryChanged(ctx, productID)
+}
+
+// DeleteImage removes an image from the gallery and from Cloudinary. Deleting the primary image
+// promotes the next image in display order.
+func (s *Service) DeleteImage(ctx context.Context, productID, imageID uuid.UUID) (*ProductResponse, error) {
+	image, err := s.repo.DeleteProductImage(ctx, productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+	// The image is already out of the gallery, so failures are only logged
+	if image.PublicID != "" && s.images.Configured() {
+		if err := s.images.DeleteImage(image.PublicID); err != nil {
+			s.logger.Printf("Failed to delete image %s for product %s: %v", image.PublicID, productID.String(), err)
+		}
+	}
+	return s.galleryChanged(ctx, productID)
+}
+
+// galleryChanged returns the product after a gallery change
+func (s *Service) galleryChanged(ctx context.Context, productID uuid.UUID) (*ProductResponse, error) {
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product: %w", err)
+	}
+	return s.toProductResponse(product), nil
+}
+
+// AddProductImage appends an image to the product's gallery, making it primary when requested or
+// when the gallery was empty
+func (r *Repository) AddProductImage(ctx context.Context, image *ProductImage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&ProductImage{}).Where("product_id = ?", image.ProductID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= MaxProductImages {
+			return ErrTooManyImages
+		}
+		var last int
+		if err := tx.Model(&ProductImage{}).Where("product_id = ?", image.ProductID).
+			Select("COALESCE(MAX(sort_order), -1)").Scan(&last).Error; err != nil {
+			return err
+		}
+		image.SortOrder = last + 1
+		image.IsPrimary = image.IsPrimary || count == 0
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+		if image.IsPrimary {
+			return makePrimary(tx, image)
+		}
+		return nil
+	})
+}
+
+// ReorderProductImages gives each listed image its position in imageIDs as its sort order
+func (r *Repository) ReorderProductImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range imageIDs {
+			if err := tx.Model(&ProductImage{}).Where("id = ? AND product_id = ?", id, productID).
+				Updates(map[string]interface{}{"sort_order": i, "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetPrimaryProductImage makes one of the product's images primary, or returns gorm.ErrRecordNotFound
+func (r *Repository) SetPrimaryProductImage(ctx context.Context, productID, imageID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var image ProductImage
+		if err := tx.Where("id = ? AND product_id = ?", imageID, productID).First(&image).Error; err != nil {
+			return err
+		}
+		return makePrimary(tx, &image)
+	})
+}
+
+// DeleteProductImage deletes one of the product's images and returns it, promoting the next image
+// when it was primary. It returns gorm.ErrRecordNotFound if the product has no such image.
+func (r *Repository) DeleteProductImage(ctx context.Context, productID, imageID uuid.UUID) (*ProductImage, error) {
+	var image ProductImage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND product_id = ?", imageID, productID).First(&image).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+		if !image.IsPrimary {
+			return nil
+		}
+		return promoteNextImage(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// ClearProductImages deletes a product's whole gallery and its image fields, returning the deleted
+// images so their hosted assets can be removed
+func (r *Repository) ClearProductImages(ctx context.Context, productID uuid.UUID) ([]ProductImage, error) {
+	var images []ProductImage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Find(&images).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", productID).Delete(&ProductImage{}).Error; err != nil {
+			return err
+		}
+		return setProductImageFields(tx, productID, "", "")
+	})
+	return images, err
+}
+
+// makePrimary flags image as the product's only primary image and mirrors it onto the product
+func makePrimary(tx *gorm.DB, image *ProductImage) error {
+	if err := tx.Model(&ProductImage{}).Where("product_id = ? AND id <> ?", image.ProductID, image.ID).
+		Update("is_primary", false).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&ProductImage{}).Where("id = ?", image.ID).Update("is_primary", true).Error; err != nil {
+		return err
+	}
+	image.IsPrimary = true
+	return setProductImageFields(tx, image.ProductID, image.URL, image.PublicID)
+}
+
+// promoteNextImage makes the first remaining image primary, or clears the product's image fields
+// when the gallery is now empty
+func promoteNextImage(tx *gorm.DB, productID uuid.UUID) error {
+	var next ProductImage
+	err := tx.Where("product_id = ?", productID).Order("sort_order ASC, created_at ASC").First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return setProductImageFields(tx, productID, "", "")
+	}
+	if err != nil {
+		return err
+	}
+	return makePrimary(tx, &next)
+}
+
+// setPrimaryImage replaces the primary gallery image with the given URL, adding one first in the
+// gallery when there is none; an empty URL removes the primary image and promotes the next
+func setPrimaryImage(tx *gorm.DB, productID uuid.UUID, imageURL, publicID string) error {
+	var primary ProductImage
+	err := tx.Where("product_id = ? AND is_primary = ?", productID, true).First(&primary).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if imageURL == "" {
+			return setProductImageFields(tx, productID, "", "")
+		}
+		// Put the new primary image first
+		image := &ProductImage{ProductID: productID, URL: imageURL, PublicID: publicID}
+		if err := tx.Model(&ProductImage{}).Where("product_id = ?", productID).
+			Select("COALESCE(MIN(sort_order), 1) - 1").Scan(&image.SortOrder).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+		return makePrimary(tx, image)
+	case err != nil:
+		return err
+	case imageURL == "":
+		if err := tx.Delete(&primary).Error; err != nil {
+			return err
+		}
+		return promoteNextImage(tx, productID)
+	}
+	primary.URL, primary.PublicID = imageURL, publicID
+	if err := tx.Model(&primary).Updates(map[string]interface{}{"url": imageURL, "public_id": publicID, "updated_at": time.Now()}).Error; err != nil {
+		return err
+	}
+	return setProductImageFields(tx, productID, imageURL, publicID)
+}
+
+// setProductImageFields writes the product's primary image fields; it also applies to deactivated products
+func setProductImageFields(tx *gorm.DB, productID uuid.UUID, imageURL, publicID string) error {
+	return tx.Model(&Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"image_url":       imageURL,
+		"image_public_id": publicID,
+		"updated_at":      time.Now(),
+	}).Error
+}
@@ -0,0 +1,103 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGalleryKeepsImageURLOnPrimary(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE products (id TEXT PRIMARY KEY, image_url TEXT, image_public_id TEXT, updated_at DATETIME, deleted_at DATETIME)`,
+		`CREATE TABLE product_images (
+			id TEXT PRIMARY KEY,
+			product_id TEXT NOT NULL,
+			url TEXT NOT NULL,
+			public_id TEXT,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			is_primary BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	productID := uuid.New()
+	if err := db.Exec(`INSERT INTO products (id, image_url, image_public_id) VALUES (?, '', '')`, productID).Error; err != nil {
+		t.Fatalf("failed to insert product: %v", err)
+	}
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+	imageURL := func() string {
+		var url string
+		db.Raw("SELECT image_url FROM products WHERE id = ?", productID).Scan(&url)
+		return url
+	}
+	primaries := func() []string {
+		var urls []string
+		db.Raw("SELECT url FROM product_images WHERE product_id = ? AND is_primary ORDER BY sort_order", productID).Scan(&urls)
+		return urls
+	}
+	add := func(url string, primary bool) *ProductImage {
+		image := &ProductImage{ProductID: productID, URL: url, IsPrimary: primary}
+		if err := repo.AddProductImage(ctx, image); err != nil {
+			t.Fatalf("AddProductImage(%s) returned error: %v", url, err)
+		}
+		return image
+	}
+
+	front := add("https://img.example.com/front.jpg", false)
+	side := add("https://img.example.com/side.jpg", false)
+	if got := imageURL(); got != front.URL {
+		t.Fatalf("the first image should become primary; image_url = %q", got)
+	}
+	if side.SortOrder != front.SortOrder+1 {
+		t.Fatalf("images should be appended in order: front %d, side %d", front.SortOrder, side.SortOrder)
+	}
+
+	back := add("https://img.example.com/back.jpg", true)
+	if got := primaries(); len(got) != 1 || got[0] != back.URL || imageURL() != back.URL {
+		t.Fatalf("adding a primary image: primaries = %v, image_url = %q", got, imageURL())
+	}
+
+	if err := repo.ReorderProductImages(ctx, productID, []uuid.UUID{back.ID, side.ID, front.ID}); err != nil {
+		t.Fatalf("ReorderProductImages returned error: %v", err)
+	}
+	if _, err := repo.DeleteProductImage(ctx, productID, back.ID); err != nil {
+		t.Fatalf("DeleteProductImage returned error: %v", err)
+	}
+	if got := primaries(); len(got) != 1 || got[0] != side.URL || imageURL() != side.URL {
+		t.Fatalf("deleting the primary should promote the next in order: primaries = %v, image_url = %q", got, imageURL())
+	}
+
+	// The single-image upload replaces the primary image in place
+	if err := repo.SetImage(ctx, productID, "https://img.example.com/new-side.jpg", "products/new-side"); err != nil {
+		t.Fatalf("SetImage returned error: %v", err)
+	}
+	var count int64
+	db.Table("product_images").Where("product_id = ?", productID).Count(&count)
+	if count != 2 || imageURL() != "https://img.example.com/new-side.jpg" {
+		t.Fatalf("SetImage: %d images, image_url = %q; want 2 images with the new primary", count, imageURL())
+	}
+
+	if _, err := repo.DeleteProductImage(ctx, uuid.New(), front.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("deleting another product's image: err = %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.ClearProductImages(ctx, productID); err != nil {
+		t.Fatalf("ClearProductImages returned error: %v", err)
+	}
+	if got := imageURL(); got != "" {
+		t.Fatalf("clearing the gallery should clear image_url; got %q", got)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
+	"errandShop/internal/services/upload"
 	"errandShop/internal/services/validation"
 
 	"github.com/go-playground/validator/v10"
@@ -54,9 +56,11 @@ func (h *Handler) successResponse(c *fiber.Ctx, data interface{}, message string
 
 func (h *Handler) List(c *fiber.Ctx) error {
 	q := ListQuery{
-		Q:     strings.TrimSpace(c.Query("q")),
-		Page:  atoiDefault(c.Query("page"), 1),
-		Limit: atoiDefault(c.Query("limit"), 20),
+		Q:                    strings.TrimSpace(c.Query("q")),
+		Page:                 atoiDefault(c.Query("page"), 1),
+		Limit:                atoiDefault(c.Query("limit"), 20),
+		Category:             strings.TrimSpace(c.Query("category")),
+		IncludeSubcategories: c.QueryBool("include_subcategories"),
 	}
 
 	// Validate pagination
@@ -95,7 +99,8 @@ func (h *Handler) Get(c *fiber.Ctx) error {
 		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
 	}
 
-	product, err := h.svc.Get(c.Context(), id)
+	userID, _ := c.Locals("userID").(uuid.UUID) // set by optional auth on the public route
+	product, err := h.svc.GetForCustomer(c.Context(), id, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
@@ -106,6 +111,42 @@ func (h *Handler) Get(c *fiber.Ctx) error {
 	return h.successResponse(c, product, "")
 }
 
+// Related returns "customers also bought" products for a product
+func (h *Handler) Related(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+
+	related, err := h.svc.GetRelated(c.Context(), id, atoiDefault(c.Query("limit"), 8))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get related products", err)
+	}
+
+	return h.successResponse(c, related, "")
+}
+
+// Featured returns the curated featured products rail
+func (h *Handler) Featured(c *fiber.Ctx) error {
+	featured, err := h.svc.GetFeatured(c.Context(), atoiDefault(c.Query("limit"), 10))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get featured products", err)
+	}
+	return h.successResponse(c, featured, "")
+}
+
+// Bestsellers returns the products that sold the most units recently
+func (h *Handler) Bestsellers(c *fiber.Ctx) error {
+	bestsellers, err := h.svc.GetBestsellers(c.Context(), atoiDefault(c.Query("limit"), 10))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get bestselling products", err)
+	}
+	return h.successResponse(c, bestsellers, "")
+}
+
 func (h *Handler) GetBySKU(c *fiber.Ctx) error {
 	sku := c.Params("sku")
 	if sku == "" {
@@ -123,6 +164,22 @@ func (h *Handler) GetBySKU(c *fiber.Ctx) error {
 	return h.successResponse(c, product, "")
 }
 
+// GetByBarcode resolves a scanned barcode to its product, for stock-taking
+func (h *Handler) GetByBarcode(c *fiber.Ctx) error {
+	product, err := h.svc.GetByBarcode(c.Context(), c.Params("code"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidBarcode) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "No product has this barcode", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get product", err)
+	}
+
+	return h.successResponse(c, product, "")
+}
+
 func (h *Handler) Create(c *fiber.Ctx) error {
 	var req CreateProductRequest
 
@@ -143,6 +200,12 @@ func (h *Handler) Create(c *fiber.Ctx) error {
 
 	product, err := h.svc.Create(c.Context(), req)
 	if err != nil {
+		if errors.Is(err, ErrInvalidOrderQtyLimits) || errors.Is(err, ErrInvalidAvailability) || errors.Is(err, ErrInvalidSubstitute) || errors.Is(err, ErrInvalidBarcode) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, ErrBarcodeInUse) {
+			return h.errorResponse(c, fiber.StatusConflict, err.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to create product", err)
 	}
 
@@ -176,11 +239,18 @@ func (h *Handler) Update(c *fiber.Ctx) error {
 		}
 	}
 
-	product, err := h.svc.Update(c.Context(), id, req)
+	changedBy, _ := c.Locals("userID").(uuid.UUID)
+	product, err := h.svc.Update(c.Context(), id, req, changedBy)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) || strings.Contains(err.Error(), "not found") {
 			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
 		}
+		if errors.Is(err, ErrInvalidOrderQtyLimits) || errors.Is(err, ErrInvalidAvailability) || errors.Is(err, ErrInvalidSubstitute) || errors.Is(err, ErrInvalidBarcode) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, ErrBarcodeInUse) {
+			return h.errorResponse(c, fiber.StatusConflict, err.Error(), err)
+		}
 		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") {
 			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product data", err)
 		}
@@ -282,6 +352,54 @@ func (h *Handler) GetLowStock(c *fiber.Ctx) error {
 	return h.successResponse(c, products, "")
 }
 
+// GetStockShrinkage reports stock lost without a sale, by reason. from/to are YYYY-MM-DD
+// (to inclusive) and default to the last 30 days.
+func (h *Handler) GetStockShrinkage(c *fiber.Ctx) error {
+	to := time.Now().UTC().Truncate(24 * time.Hour).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid from date, expected YYYY-MM-DD", err)
+		}
+		from = parsed
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid to date, expected YYYY-MM-DD", err)
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	report, err := h.svc.GetStockShrinkageReport(c.Context(), from, to)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid date range") {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid date range", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get stock shrinkage report", err)
+	}
+
+	return h.successResponse(c, report, "")
+}
+
+func (h *Handler) GetPriceHistory(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+
+	history, err := h.svc.GetPriceHistory(c.Context(), id, atoiDefault(c.Query("limit", "100"), 100))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get price history", err)
+	}
+
+	return h.successResponse(c, history, "")
+}
+
 func (h *Handler) GetCategories(c *fiber.Ctx) error {
 	categories, err := h.svc.GetCategories(c.Context())
 	if err != nil {
@@ -290,6 +408,24 @@ func (h *Handler) GetCategories(c *fiber.Ctx) error {
 	return h.successResponse(c, categories, "Categories retrieved successfully")
 }
 
+// GetTags returns the distinct product tags with product counts, for filter UIs
+func (h *Handler) GetTags(c *fiber.Ctx) error {
+	tags, err := h.svc.GetTags(c.Context())
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get tags", err)
+	}
+	return h.successResponse(c, tags, "Tags retrieved successfully")
+}
+
+// GetCategoryTree returns the active categories nested by parent
+func (h *Handler) GetCategoryTree(c *fiber.Ctx) error {
+	tree, err := h.svc.GetCategoryTree(c.Context())
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to get category tree", err)
+	}
+	return h.successResponse(c, tree, "Category tree retrieved successfully")
+}
+
 // Superadmin-only: Create Category
 func (h *Handler) CreateCategory(c *fiber.Ctx) error {
 	var req CreateCategoryRequest
@@ -302,9 +438,13 @@ func (h *Handler) CreateCategory(c *fiber.Ctx) error {
 	cat := &Category{
 		Name:        strings.TrimSpace(req.Name),
 		Description: strings.TrimSpace(req.Description),
+		ParentID:    req.ParentID,
 		IsActive:    true,
 	}
 	if err := h.svc.CreateCategory(c.Context(), cat); err != nil {
+		if errors.Is(err, ErrParentCategoryNotFound) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to create category", err)
 	}
 	return h.successResponse(c, cat, "Category created successfully")
@@ -349,6 +489,9 @@ func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
 	}
 	cat, err := h.svc.UpdateCategory(c.Context(), id, req)
 	if err != nil {
+		if errors.Is(err, ErrCategoryCycle) || errors.Is(err, ErrParentCategoryNotFound) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
 		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to update category", err)
 	}
 	return h.successResponse(c, cat, "Category updated successfully")
@@ -374,6 +517,9 @@ func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
         if errors.Is(err, gorm.ErrRecordNotFound) || strings.Contains(err.Error(), "not found") {
             return h.errorResponse(c, fiber.StatusNotFound, "Category not found", err)
         }
+        if errors.Is(err, ErrCategoryHasSubcategories) {
+            return h.errorResponse(c, fiber.StatusConflict, err.Error(), err)
+        }
         if strings.Contains(strings.ToLower(err.Error()), "invalid category id") {
             return h.errorResponse(c, fiber.StatusBadRequest, "Invalid category ID", err)
         }
@@ -394,4 +540,144 @@ func atoiDefault(s string, d int) int {
 	return i
 }
 
-// Image upload/delete methods removed - using external image hosting (Cloudinary)
+// UploadImage accepts a multipart "image" file and hosts it on Cloudinary
+func (h *Handler) UploadImage(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Image file is required (form field \"image\")", err)
+	}
+
+	result, err := h.svc.UploadImage(c.Context(), id, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, upload.ErrCloudinaryNotConfigured):
+			return h.errorResponse(c, fiber.StatusServiceUnavailable, "Image hosting is not configured", err)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
+		case strings.Contains(err.Error(), "file size too large") || strings.Contains(err.Error(), "unsupported file type"):
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid image file", err)
+		}
+		return h.errorResponse(c, fiber.StatusBadGateway, "Failed to upload image", err)
+	}
+
+	return h.successResponse(c, result, "Image uploaded successfully")
+}
+
+// AddImage adds an image to a product's gallery: a multipart "image" file hosted on Cloudinary,
+// or a JSON body with an already hosted imageUrl. Send isPrimary to make it the main image.
+func (h *Handler) AddImage(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+
+	var product *ProductResponse
+	if file, fileErr := c.FormFile("image"); fileErr == nil {
+		product, err = h.svc.UploadGalleryImage(c.Context(), id, file, c.FormValue("isPrimary") == "true")
+	} else {
+		var req AddProductImageRequest
+		if err := c.BodyParser(&req); err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid request body", err)
+		}
+		if err := validate.Struct(&req); err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
+		}
+		if err := h.urlValidator.ValidateImageURL(req.ImageURL); err != nil {
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid image URL", err)
+		}
+		product, err = h.svc.AddImage(c.Context(), id, req)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTooManyImages):
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, upload.ErrCloudinaryNotConfigured):
+			return h.errorResponse(c, fiber.StatusServiceUnavailable, "Image hosting is not configured", err)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
+		case strings.Contains(err.Error(), "file size too large") || strings.Contains(err.Error(), "unsupported file type"):
+			return h.errorResponse(c, fiber.StatusBadRequest, "Invalid image file", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to add image", err)
+	}
+
+	return h.successResponse(c, product, "Image added successfully")
+}
+
+// ReorderImages sets the display order of a product's gallery
+func (h *Handler) ReorderImages(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+
+	var req ReorderProductImagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid request body", err)
+	}
+	if err := validate.Struct(&req); err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Validation failed", err)
+	}
+
+	product, err := h.svc.ReorderImages(c.Context(), id, req.ImageIDs)
+	if err != nil {
+		if errors.Is(err, ErrInvalidImageOrder) {
+			return h.errorResponse(c, fiber.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Product not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to reorder images", err)
+	}
+
+	return h.successResponse(c, product, "Images reordered successfully")
+}
+
+// SetPrimaryImage makes a gallery image the product's main image
+func (h *Handler) SetPrimaryImage(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+	imageID, err := uuid.Parse(c.Params("imageId"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid image ID format", err)
+	}
+
+	product, err := h.svc.SetPrimaryImage(c.Context(), id, imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Image not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to set primary image", err)
+	}
+
+	return h.successResponse(c, product, "Primary image updated successfully")
+}
+
+// DeleteImage removes an image from a product's gallery
+func (h *Handler) DeleteImage(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid product ID format", err)
+	}
+	imageID, err := uuid.Parse(c.Params("imageId"))
+	if err != nil {
+		return h.errorResponse(c, fiber.StatusBadRequest, "Invalid image ID format", err)
+	}
+
+	product, err := h.svc.DeleteImage(c.Context(), id, imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return h.errorResponse(c, fiber.StatusNotFound, "Image not found", err)
+		}
+		return h.errorResponse(c, fiber.StatusInternalServerError, "Failed to delete image", err)
+	}
+
+	return h.successResponse(c, product, "Image deleted successfully")
+}
@@ -0,0 +1,60 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultBestsellerWindow is how far back sales count toward the bestsellers rail
+	DefaultBestsellerWindow = 30 * 24 * time.Hour
+	// maxMerchandisingProducts caps the featured and bestsellers rails
+	maxMerchandisingProducts = 50
+)
+
+// bestsellerOrderStatuses are the orders whose items count as sold. They match the statuses the
+// analytics top-products report counts toward revenue, so the rail agrees with the dashboard.
+var bestsellerOrderStatuses = []string{"delivered", "confirmed"}
+
+// SetBestsellerWindow sets how far back sales count toward bestsellers; zero or less keeps the default
+func (s *Service) SetBestsellerWindow(window time.Duration) {
+	if window > 0 {
+		s.bestsellerWindow = window
+	}
+}
+
+// GetFeatured returns the admin-curated featured products that are active and in stock
+func (s *Service) GetFeatured(ctx context.Context, limit int) ([]ProductResponse, error) {
+	items, err := s.repo.GetFeatured(ctx, merchandisingLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get featured products: %w", err)
+	}
+	return s.toProductResponses(items), nil
+}
+
+// GetBestsellers returns the active, in-stock products that sold the most units over the
+// bestseller window
+func (s *Service) GetBestsellers(ctx context.Context, limit int) ([]ProductResponse, error) {
+	since := time.Now().Add(-s.bestsellerWindow)
+	items, err := s.repo.GetBestsellers(ctx, since, merchandisingLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bestselling products: %w", err)
+	}
+	return s.toProductResponses(items), nil
+}
+
+func (s *Service) toProductResponses(items []Product) []ProductResponse {
+	responses := make([]ProductResponse, len(items))
+	for i := range items {
+		responses[i] = *s.toProductResponse(&items[i])
+	}
+	return responses
+}
+
+func merchandisingLimit(limit int) int {
+	if limit <= 0 || limit > maxMerchandisingProducts {
+		return maxMerchandisingProducts
+	}
+	return limit
+}
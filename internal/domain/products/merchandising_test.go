@@ -0,0 +1,35 @@
+package products
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMerchandisingLimit(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{10, 10},
+		{maxMerchandisingProducts, maxMerchandisingProducts},
+		{maxMerchandisingProducts + 1, maxMerchandisingProducts},
+		{0, maxMerchandisingProducts},
+		{-3, maxMerchandisingProducts},
+	}
+	for _, tt := range tests {
+		if got := merchandisingLimit(tt.limit); got != tt.want {
+			t.Errorf("merchandisingLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSetBestsellerWindowKeepsDefaultForNonPositive(t *testing.T) {
+	s := NewService(nil)
+	s.SetBestsellerWindow(0)
+	if s.bestsellerWindow != DefaultBestsellerWindow {
+		t.Fatalf("window = %v, want default %v", s.bestsellerWindow, DefaultBestsellerWindow)
+	}
+	s.SetBestsellerWindow(7 * 24 * time.Hour)
+	if s.bestsellerWindow != 7*24*time.Hour {
+		t.Fatalf("window = %v, want 168h", s.bestsellerWindow)
+	}
+}
@@ -3,6 +3,7 @@ package products
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -56,17 +57,30 @@ type Product struct {
 	ID                uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name              string    `gorm:"size:255;not null" json:"name"`
 	SKU               string    `gorm:"size:50;uniqueIndex;not null" json:"sku"`
+	Barcode           *string   `gorm:"size:64;uniqueIndex:idx_products_barcode,where:barcode IS NOT NULL AND deleted_at IS NULL" json:"barcode"` // EAN/UPC or shelf label; unique where present
 	Slug              string    `gorm:"uniqueIndex;size:200" json:"slug"`
 	Description       string    `gorm:"type:text" json:"description"`
 	CostPrice         float64   `gorm:"type:decimal(10,2);not null" json:"costPrice"`
 	SellingPrice      float64   `gorm:"type:decimal(10,2);not null" json:"sellingPrice"`
+	SalePrice         *float64   `gorm:"type:decimal(10,2)" json:"salePrice"` // charged instead of SellingPrice inside the sale window
+	SaleStartsAt      *time.Time `json:"saleStartsAt"` // nil starts the sale immediately
+	SaleEndsAt        *time.Time `json:"saleEndsAt"`   // nil keeps it running until cleared
 	StockQuantity     int       `gorm:"not null;default:0" json:"stockQuantity"`
 	LowStockThreshold int       `gorm:"not null;default:10" json:"lowStockThreshold"`
+	WeightGrams       int       `gorm:"not null;default:0" json:"weightGrams"` // shipping weight per unit, used for delivery surcharges
+	MinOrderQty       int       `gorm:"not null;default:0" json:"minOrderQty"` // bulk-only items: fewest units per order (0 = no minimum)
+	MaxOrderQty       int       `gorm:"not null;default:0" json:"maxOrderQty"` // rationed items: most units per order (0 = no maximum)
+	AvailableDays     StringSlice `gorm:"type:jsonb" json:"availableDays"`  // day codes (mon..sun) the product is sold on; empty = every day
+	AvailableFrom     string      `gorm:"size:5" json:"availableFrom"`      // daily window start, "HH:MM" store time; empty = all day
+	AvailableUntil    string      `gorm:"size:5" json:"availableUntil"`     // daily window end (exclusive)
 	ImageURL          string    `gorm:"size:500" json:"imageUrl"`
 	ImagePublicID     string    `gorm:"size:255" json:"imagePublicId"`
+	Images            []ProductImage `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"images,omitempty"` // gallery; ImageURL mirrors its primary image
 	Category          string    `gorm:"size:120" json:"category"`
 	Tags              StringSlice  `gorm:"type:jsonb" json:"tags"`
 	IsActive          bool      `gorm:"default:true" json:"isActive"`
+	IsFeatured        bool      `gorm:"default:false;index" json:"isFeatured"` // shown in the home screen's featured rail
+	SubstituteProductID *uuid.UUID `gorm:"type:uuid" json:"substituteProductId"` // admin-chosen stand-in offered when this product runs short at checkout
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
@@ -77,11 +91,71 @@ type Category struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string    `gorm:"size:100;not null;uniqueIndex" json:"name"`
 	Description string    `gorm:"type:text" json:"description"`
+	ParentID    *uuid.UUID `gorm:"type:uuid;index" json:"parentId"` // nil for top-level categories
 	IsActive    bool      `gorm:"default:true" json:"isActive"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
 
+// StockChangeReason classifies why stock moved, for movement and shrinkage reporting
+type StockChangeReason string
+
+const (
+	StockReasonSale         StockChangeReason = "sale"
+	StockReasonCancellation StockChangeReason = "cancellation"
+	StockReasonRestock      StockChangeReason = "restock"
+	StockReasonDamage       StockChangeReason = "damage"
+	StockReasonCorrection   StockChangeReason = "correction"
+	StockReasonTheft        StockChangeReason = "theft"
+)
+
+// StockChangeReasons lists every reason code, in report order
+var StockChangeReasons = []StockChangeReason{
+	StockReasonSale,
+	StockReasonCancellation,
+	StockReasonRestock,
+	StockReasonDamage,
+	StockReasonCorrection,
+	StockReasonTheft,
+}
+
+// ShrinkageReasons are the reasons whose stock decreases count as shrinkage (stock lost without a sale)
+var ShrinkageReasons = []StockChangeReason{
+	StockReasonDamage,
+	StockReasonTheft,
+	StockReasonCorrection,
+}
+
+// IsValid reports whether r is a known reason code
+func (r StockChangeReason) IsValid() bool {
+	for _, known := range StockChangeReasons {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveStockChangeReason picks the reason code for a stock change. An explicit code wins;
+// otherwise the legacy free-text reason is matched against the codes and the phrases the
+// order flow used to write, and anything else is recorded as a correction.
+func ResolveStockChangeReason(code StockChangeReason, note string) StockChangeReason {
+	if code.IsValid() {
+		return code
+	}
+	text := strings.ToLower(strings.TrimSpace(note))
+	if legacy := StockChangeReason(text); legacy.IsValid() {
+		return legacy
+	}
+	switch {
+	case text == "order creation":
+		return StockReasonSale
+	case strings.HasPrefix(text, "order cancellation"):
+		return StockReasonCancellation
+	}
+	return StockReasonCorrection
+}
+
 // StockHistory tracks stock changes
 type StockHistory struct {
 	ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -90,7 +164,8 @@ type StockHistory struct {
 	QuantityChange   int       `gorm:"not null" json:"quantityChange"`
 	PreviousQuantity int       `gorm:"not null" json:"previousQuantity"`
 	NewQuantity      int       `gorm:"not null" json:"newQuantity"`
-	Reason           string    `gorm:"type:text" json:"reason"`
+	ReasonCode       StockChangeReason `gorm:"size:20;not null;default:correction;index" json:"reasonCode"`
+	Reason           string    `gorm:"type:text" json:"reason"` // free-text note
 	CreatedAt        time.Time `json:"createdAt"`
 	CreatedBy        uuid.UUID `gorm:"type:uuid" json:"createdBy"`
 	
@@ -98,6 +173,18 @@ type StockHistory struct {
 	Product Product `gorm:"foreignKey:ProductID" json:"-"`
 }
 
+// ProductPriceHistory records a change to a product's cost or selling price
+type ProductPriceHistory struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID       uuid.UUID `gorm:"type:uuid;not null;index" json:"productId"`
+	OldCostPrice    float64   `gorm:"type:decimal(10,2);not null" json:"oldCostPrice"`
+	NewCostPrice    float64   `gorm:"type:decimal(10,2);not null" json:"newCostPrice"`
+	OldSellingPrice float64   `gorm:"type:decimal(10,2);not null" json:"oldSellingPrice"`
+	NewSellingPrice float64   `gorm:"type:decimal(10,2);not null" json:"newSellingPrice"`
+	ChangedBy       uuid.UUID `gorm:"type:uuid" json:"changedBy"`
+	CreatedAt       time.Time `gorm:"index" json:"createdAt"`
+}
+
 // TableName sets the table name for Product
 func (Product) TableName() string {
 	return "products"
@@ -113,6 +200,11 @@ func (StockHistory) TableName() string {
 	return "stock_history"
 }
 
+// TableName sets the table name for ProductPriceHistory
+func (ProductPriceHistory) TableName() string {
+	return "product_price_history"
+}
+
 // BeforeCreate generates SKU for new products
 func (p *Product) BeforeCreate(tx *gorm.DB) error {
 	if p.SKU == "" {
@@ -126,6 +218,33 @@ func (p *Product) Profit() float64 {
 	return p.SellingPrice - p.CostPrice
 }
 
+// OnSaleAt reports whether the sale price applies at t
+func (p *Product) OnSaleAt(t time.Time) bool {
+	if p.SalePrice == nil || *p.SalePrice <= 0 {
+		return false
+	}
+	if p.SaleStartsAt != nil && t.Before(*p.SaleStartsAt) {
+		return false
+	}
+	if p.SaleEndsAt != nil && !t.Before(*p.SaleEndsAt) {
+		return false
+	}
+	return true
+}
+
+// PriceAt returns the price charged at t: the sale price inside the sale window, else the selling price
+func (p *Product) PriceAt(t time.Time) float64 {
+	if p.OnSaleAt(t) {
+		return *p.SalePrice
+	}
+	return p.SellingPrice
+}
+
+// EffectivePrice returns the price charged right now
+func (p *Product) EffectivePrice() float64 {
+	return p.PriceAt(time.Now())
+}
+
 // IsLowStock checks if product is below threshold
 func (p *Product) IsLowStock() bool {
 	return p.StockQuantity <= p.LowStockThreshold
@@ -0,0 +1,35 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOrderQuantityLimit is returned when a product is ordered outside its per-order quantity limits
+var ErrOrderQuantityLimit = errors.New("quantity not allowed")
+
+// ErrInvalidOrderQtyLimits is returned when a product's per-order quantity limits contradict each other
+var ErrInvalidOrderQtyLimits = errors.New("invalid order quantity limits")
+
+// CheckOrderQuantity checks the total quantity of this product in one cart or order against
+// MinOrderQty and MaxOrderQty. A zero limit does not apply.
+func (p *Product) CheckOrderQuantity(quantity int) error {
+	if p.MinOrderQty > 0 && quantity < p.MinOrderQty {
+		return fmt.Errorf("%w: %s must be ordered in quantities of at least %d", ErrOrderQuantityLimit, p.Name, p.MinOrderQty)
+	}
+	if p.MaxOrderQty > 0 && quantity > p.MaxOrderQty {
+		return fmt.Errorf("%w: %s is limited to %d per order", ErrOrderQuantityLimit, p.Name, p.MaxOrderQty)
+	}
+	return nil
+}
+
+// validateOrderQtyLimits rejects negative limits and a minimum above the maximum
+func validateOrderQtyLimits(minQty, maxQty int) error {
+	if minQty < 0 || maxQty < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", ErrInvalidOrderQtyLimits)
+	}
+	if minQty > 0 && maxQty > 0 && minQty > maxQty {
+		return fmt.Errorf("%w: minimum cannot be above the maximum", ErrInvalidOrderQtyLimits)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package products_test
+
+import (
+	"errors"
+	"testing"
+
+	products "errandShop/internal/domain/products"
+)
+
+func TestCheckOrderQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		product  products.Product
+		quantity int
+		wantErr  bool
+	}{
+		{"no limits", products.Product{Name: "Rice"}, 500, false},
+		{"below minimum", products.Product{Name: "Eggs", MinOrderQty: 6}, 4, true},
+		{"at minimum", products.Product{Name: "Eggs", MinOrderQty: 6}, 6, false},
+		{"at maximum", products.Product{Name: "Sugar", MaxOrderQty: 3}, 3, false},
+		{"above maximum", products.Product{Name: "Sugar", MaxOrderQty: 3}, 4, true},
+		{"inside both", products.Product{Name: "Water", MinOrderQty: 2, MaxOrderQty: 10}, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.product.CheckOrderQuantity(tt.quantity)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("CheckOrderQuantity(%d) error = %v, wantErr %v", tt.quantity, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, products.ErrOrderQuantityLimit) {
+				t.Fatalf("expected ErrOrderQuantityLimit, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package products
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestListOutOfStockProducts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE products (
+			id TEXT PRIMARY KEY,
+			name TEXT,
+			category TEXT,
+			stock_quantity INTEGER NOT NULL DEFAULT 0,
+			is_active BOOLEAN NOT NULL DEFAULT 1,
+			created_at DATETIME,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE product_images (
+			id TEXT PRIMARY KEY,
+			product_id TEXT NOT NULL,
+			url TEXT NOT NULL,
+			public_id TEXT,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			is_primary BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	inStock, soldOut := uuid.New(), uuid.New()
+	if err := db.Exec(`INSERT INTO products (id, name, stock_quantity, created_at) VALUES (?, 'Rice', 5, CURRENT_TIMESTAMP), (?, 'Beans', 0, CURRENT_TIMESTAMP)`,
+		inStock, soldOut).Error; err != nil {
+		t.Fatalf("failed to insert products: %v", err)
+	}
+
+	s := NewService(NewRepository(db))
+	ctx := context.Background()
+
+	shown, err := s.List(ctx, ListQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(shown.Data) != 2 || shown.Meta.Total != 2 {
+		t.Fatalf("got %d products (total %d), want out-of-stock shown by default", len(shown.Data), shown.Meta.Total)
+	}
+	for _, p := range shown.Data {
+		if want := p.ID == inStock; p.IsPurchasable != want {
+			t.Errorf("%s isPurchasable = %v, want %v", p.Name, p.IsPurchasable, want)
+		}
+	}
+
+	s.SetShowOutOfStock(false)
+	hidden, err := s.List(ctx, ListQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(hidden.Data) != 1 || hidden.Meta.Total != 1 || hidden.Data[0].ID != inStock {
+		t.Fatalf("got %+v, want only the in-stock product", hidden.Data)
+	}
+}
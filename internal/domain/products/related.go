@@ -0,0 +1,103 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// relatedCacheTTL bounds how stale "customers also bought" results may get
+	relatedCacheTTL = 30 * time.Minute
+	// maxRelatedProducts is how many related products are computed and cached per product
+	maxRelatedProducts = 20
+)
+
+type relatedCacheEntry struct {
+	products  []ProductResponse
+	expiresAt time.Time
+}
+
+// relatedCache is a small in-process TTL cache for related product lists
+type relatedCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]relatedCacheEntry
+}
+
+func newRelatedCache() *relatedCache {
+	return &relatedCache{entries: make(map[uuid.UUID]relatedCacheEntry)}
+}
+
+func (c *relatedCache) get(id uuid.UUID) ([]ProductResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false
+	}
+	return entry.products, true
+}
+
+func (c *relatedCache) set(id uuid.UUID, products []ProductResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[id] = relatedCacheEntry{products: products, expiresAt: time.Now().Add(relatedCacheTTL)}
+}
+
+// GetRelated returns products most often bought in the same order as the given product,
+// topped up with products from the same category when order history is thin.
+func (s *Service) GetRelated(ctx context.Context, id uuid.UUID, limit int) ([]ProductResponse, error) {
+	if limit <= 0 || limit > maxRelatedProducts {
+		limit = maxRelatedProducts
+	}
+
+	if cached, ok := s.related.get(id); ok {
+		return truncateProducts(cached, limit), nil
+	}
+
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := s.repo.GetCoPurchased(ctx, id, maxRelatedProducts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get co-purchased products: %w", err)
+	}
+
+	if len(related) < maxRelatedProducts && product.Category != "" {
+		exclude := []uuid.UUID{id}
+		for _, p := range related {
+			exclude = append(exclude, p.ID)
+		}
+		sameCategory, err := s.repo.GetByCategory(ctx, product.Category, exclude, maxRelatedProducts-len(related))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get same-category products: %w", err)
+		}
+		related = append(related, sameCategory...)
+	}
+
+	responses := make([]ProductResponse, len(related))
+	for i := range related {
+		responses[i] = *s.toProductResponse(&related[i])
+	}
+
+	s.related.set(id, responses)
+	return truncateProducts(responses, limit), nil
+}
+
+func truncateProducts(products []ProductResponse, limit int) []ProductResponse {
+	if len(products) > limit {
+		return products[:limit]
+	}
+	return products
+}
@@ -3,6 +3,7 @@ package products
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -30,17 +31,23 @@ func (r *Repository) List(ctx context.Context, q ListQuery) (items []Product, to
 		tx = tx.Where("name ILIKE ? OR category ILIKE ? OR description ILIKE ?", 
 			"%"+q.Q+"%", "%"+q.Q+"%", "%"+q.Q+"%")
 	}
+	if q.Category != "" {
+		tx = tx.Where("category IN ?", q.categoryNames())
+	}
+	if q.inStockOnly {
+		tx = tx.Where("stock_quantity > 0")
+	}
 	if err = tx.Count(&total).Error; err != nil {
 		return
 	}
 	offset := (q.Page - 1) * q.Limit
-	err = tx.Order("created_at DESC").Limit(q.Limit).Offset(offset).Find(&items).Error
+	err = tx.Preload("Images", orderedImages).Order("created_at DESC").Limit(q.Limit).Offset(offset).Find(&items).Error
 	return
 }
 
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Product, error) {
 	var p Product
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Where(&Product{IsActive: true}).First(&p).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Images", orderedImages).Where("id = ?", id).Where(&Product{IsActive: true}).First(&p).Error; err != nil {
 		return nil, err
 	}
 	return &p, nil
@@ -48,7 +55,20 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Product, error
 
 func (r *Repository) GetBySKU(ctx context.Context, sku string) (*Product, error) {
 	var p Product
-	if err := r.db.WithContext(ctx).Where("sku = ?", sku).Where(&Product{IsActive: true}).First(&p).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Images", orderedImages).Where("sku = ?", sku).Where(&Product{IsActive: true}).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// FindByBarcode looks a product up by barcode, optionally only among active products
+func (r *Repository) FindByBarcode(ctx context.Context, barcode string, activeOnly bool) (*Product, error) {
+	var p Product
+	tx := r.db.WithContext(ctx).Preload("Images", orderedImages).Where("barcode = ?", barcode)
+	if activeOnly {
+		tx = tx.Where(&Product{IsActive: true})
+	}
+	if err := tx.First(&p).Error; err != nil {
 		return nil, err
 	}
 	return &p, nil
@@ -63,10 +83,98 @@ func (r *Repository) Update(ctx context.Context, id uuid.UUID, updates map[strin
 	return r.db.WithContext(ctx).Model(&Product{}).Where("id = ?", id).Where(&Product{IsActive: true}).Updates(updates).Error
 }
 
+// UpdateWithPriceHistory applies updates and records the price change in one transaction
+func (r *Repository) UpdateWithPriceHistory(ctx context.Context, id uuid.UUID, updates map[string]interface{}, history *ProductPriceHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates["updated_at"] = "NOW()"
+		if err := tx.Model(&Product{}).Where("id = ?", id).Where(&Product{IsActive: true}).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(history).Error
+	})
+}
+
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&Product{}).Where("id = ?", id).Update("is_active", false).Error
 }
 
+// GetCoPurchased returns active products that appeared in the same (non-cancelled) orders as
+// productID, most frequently co-purchased first.
+func (r *Repository) GetCoPurchased(ctx context.Context, productID uuid.UUID, limit int) ([]Product, error) {
+	var items []Product
+	err := r.db.WithContext(ctx).
+		Table("products").
+		Select("products.*").
+		Joins(`JOIN (
+			SELECT oi2.product_id, COUNT(DISTINCT oi2.order_id) AS together
+			FROM order_items oi1
+			JOIN order_items oi2 ON oi2.order_id = oi1.order_id AND oi2.product_id <> oi1.product_id
+			JOIN orders o ON o.id = oi1.order_id AND o.status <> 'cancelled'
+			WHERE oi1.product_id = ?
+			GROUP BY oi2.product_id
+		) co ON co.product_id = products.id`, productID).
+		Where("products.is_active = ? AND products.deleted_at IS NULL", true).
+		Preload("Images", orderedImages).
+		Order("co.together DESC, products.created_at DESC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// GetFeatured returns active, in-stock products flagged as featured, most recently updated first
+func (r *Repository) GetFeatured(ctx context.Context, limit int) ([]Product, error) {
+	var items []Product
+	err := r.db.WithContext(ctx).
+		Where(&Product{IsActive: true, IsFeatured: true}).
+		Where("stock_quantity > 0").
+		Preload("Images", orderedImages).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// GetBestsellers returns active, in-stock products ranked by units sold on orders placed since
+// since. Sales are counted on the same order statuses as the analytics top-products report.
+func (r *Repository) GetBestsellers(ctx context.Context, since time.Time, limit int) ([]Product, error) {
+	var items []Product
+	err := r.db.WithContext(ctx).
+		Table("products").
+		Select("products.*").
+		Joins(`JOIN (
+			SELECT oi.product_id, SUM(oi.quantity) AS quantity_sold
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.status IN ? AND o.created_at >= ?
+			GROUP BY oi.product_id
+		) sold ON sold.product_id = products.id`, bestsellerOrderStatuses, since).
+		Where("products.is_active = ? AND products.deleted_at IS NULL AND products.stock_quantity > 0", true).
+		Preload("Images", orderedImages).
+		Order("sold.quantity_sold DESC, products.name ASC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// GetByCategory returns active products in a category, newest first, skipping excludeIDs
+func (r *Repository) GetByCategory(ctx context.Context, category string, excludeIDs []uuid.UUID, limit int) ([]Product, error) {
+	var items []Product
+	tx := r.db.WithContext(ctx).Where("category = ?", category).Where(&Product{IsActive: true})
+	if len(excludeIDs) > 0 {
+		tx = tx.Where("id NOT IN ?", excludeIDs)
+	}
+	err := tx.Preload("Images", orderedImages).Order("created_at DESC").Limit(limit).Find(&items).Error
+	return items, err
+}
+
+// SetImage stores the hosted image URL and public ID as the product's primary image, replacing
+// the gallery's primary image (see gallery.go); unlike Update it also applies to deactivated products.
+func (r *Repository) SetImage(ctx context.Context, id uuid.UUID, imageURL, publicID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return setPrimaryImage(tx, id, imageURL, publicID)
+	})
+}
+
 // Admin List with Advanced Filtering
 func (r *Repository) AdminList(ctx context.Context, query AdminListQuery) ([]Product, int64, error) {
 	var products []Product
@@ -85,7 +193,7 @@ func (r *Repository) AdminList(ctx context.Context, query AdminListQuery) ([]Pro
 	}
 
 	if query.Category != "" {
-		db = db.Where("category = ?", query.Category)
+		db = db.Where("category IN ?", query.categoryNames())
 	}
 
 	if query.LowStock {
@@ -125,7 +233,7 @@ func (r *Repository) AdminList(ctx context.Context, query AdminListQuery) ([]Pro
 		query.Limit = 20
 	}
 	offset := (query.Page - 1) * query.Limit
-	if err := db.Offset(offset).Limit(query.Limit).Find(&products).Error; err != nil {
+	if err := db.Preload("Images", orderedImages).Offset(offset).Limit(query.Limit).Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -180,6 +288,7 @@ func (r *Repository) UpdateStock(ctx context.Context, productID uuid.UUID, req S
 		QuantityChange:   req.Quantity,
 		PreviousQuantity: previousQuantity,
 		NewQuantity:      newQuantity,
+		ReasonCode:       ResolveStockChangeReason(req.ReasonCode, req.Reason),
 		Reason:           req.Reason,
 		CreatedBy:        userID,
 	}
@@ -248,6 +357,7 @@ func (r *Repository) BulkUpdateStock(ctx context.Context, req BulkUpdateStockReq
 			QuantityChange:   update.Quantity,
 			PreviousQuantity: previousQuantity,
 			NewQuantity:      newQuantity,
+			ReasonCode:       ResolveStockChangeReason(req.ReasonCode, req.Reason),
 			Reason:           req.Reason,
 			CreatedBy:        userID,
 		}
@@ -291,6 +401,23 @@ func (r *Repository) GetCategories(ctx context.Context) ([]CategoryResponse, err
 	return categories, err
 }
 
+// GetTags counts active products per tag, most used first. Rows whose tags are not a JSON
+// array are skipped rather than failing the query.
+func (r *Repository) GetTags(ctx context.Context) ([]TagResponse, error) {
+	var tags []TagResponse
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT tag AS name, COUNT(DISTINCT p.id) AS product_count
+		FROM products p
+		CROSS JOIN LATERAL jsonb_array_elements_text(
+			CASE WHEN jsonb_typeof(p.tags) = 'array' THEN p.tags ELSE '[]'::jsonb END
+		) AS tag
+		WHERE p.is_active = true AND p.deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY product_count DESC, tag ASC`).
+		Scan(&tags).Error
+	return tags, err
+}
+
 func (r *Repository) CreateCategory(ctx context.Context, category *Category) error {
 	return r.db.WithContext(ctx).Create(category).Error
 }
@@ -312,11 +439,24 @@ func (r *Repository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&Category{}).Where("id = ?", id).Update("is_active", false).Error
 }
 
+// ListActiveCategories returns every active category row, for walking the category tree
+func (r *Repository) ListActiveCategories(ctx context.Context) ([]Category, error) {
+	var cats []Category
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("name ASC").Find(&cats).Error
+	return cats, err
+}
+
+func (r *Repository) CountActiveSubcategories(ctx context.Context, parentID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Category{}).Where("parent_id = ? AND is_active = ?", parentID, true).Count(&count).Error
+	return count, err
+}
+
 func (r *Repository) ListCategories(ctx context.Context) ([]CategoryResponse, error) {
 	var out []CategoryResponse
 	q := r.db.WithContext(ctx).
 		Table("categories as c").
-		Select("c.id, c.name, c.description, c.is_active, c.created_at, COALESCE(COUNT(p.id),0) as product_count").
+		Select("c.id, c.name, c.description, c.parent_id, c.is_active, c.created_at, COALESCE(COUNT(p.id),0) as product_count").
 		Joins("LEFT JOIN products p ON p.category = c.name AND p.is_active = ?", true).
 		Where("c.is_active = ?", true).
 		Group("c.id").
@@ -374,6 +514,33 @@ func (r *Repository) GetProductAnalytics(ctx context.Context) (*ProductAnalytics
 	return &analytics, nil
 }
 
+// GetPriceHistory returns a product's price changes, newest first
+func (r *Repository) GetPriceHistory(ctx context.Context, productID uuid.UUID, limit int) ([]ProductPriceHistory, error) {
+	var history []ProductPriceHistory
+	query := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return history, query.Find(&history).Error
+}
+
+// GetStockShrinkage sums stock decreases per reason code for the given reasons within [from, to)
+func (r *Repository) GetStockShrinkage(ctx context.Context, reasons []StockChangeReason, from, to time.Time) ([]StockShrinkageByReason, error) {
+	var rows []StockShrinkageByReason
+	err := r.db.WithContext(ctx).Table("stock_history AS sh").
+		Select(`sh.reason_code AS reason,
+			COUNT(*) AS movements,
+			COALESCE(SUM(sh.previous_quantity - sh.new_quantity), 0) AS units,
+			COALESCE(SUM((sh.previous_quantity - sh.new_quantity) * p.cost_price), 0) AS cost_value`).
+		Joins("JOIN products p ON p.id = sh.product_id").
+		Where("sh.new_quantity < sh.previous_quantity").
+		Where("sh.reason_code IN ?", reasons).
+		Where("sh.created_at >= ? AND sh.created_at < ?", from, to).
+		Group("sh.reason_code").
+		Scan(&rows).Error
+	return rows, err
+}
+
 // Stock History
 func (r *Repository) GetStockHistory(ctx context.Context, productID uuid.UUID, limit int) ([]StockHistory, error) {
 	var history []StockHistory
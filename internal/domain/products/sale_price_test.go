@@ -0,0 +1,41 @@
+package products_test
+
+import (
+	"testing"
+	"time"
+
+	products "errandShop/internal/domain/products"
+)
+
+func TestPriceAt(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	sale := 800.0
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		product  products.Product
+		wantSale bool
+	}{
+		{"no sale price", products.Product{SellingPrice: 1000}, false},
+		{"open-ended sale", products.Product{SellingPrice: 1000, SalePrice: &sale}, true},
+		{"inside window", products.Product{SellingPrice: 1000, SalePrice: &sale, SaleStartsAt: &before, SaleEndsAt: &after}, true},
+		{"not started yet", products.Product{SellingPrice: 1000, SalePrice: &sale, SaleStartsAt: &after}, false},
+		{"ended", products.Product{SellingPrice: 1000, SalePrice: &sale, SaleEndsAt: &before}, false},
+		{"ends exactly now", products.Product{SellingPrice: 1000, SalePrice: &sale, SaleEndsAt: &now}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.product.SellingPrice
+			if tt.wantSale {
+				want = sale
+			}
+			if got := tt.product.OnSaleAt(now); got != tt.wantSale {
+				t.Fatalf("OnSaleAt = %v, want %v", got, tt.wantSale)
+			}
+			if got := tt.product.PriceAt(now); got != want {
+				t.Fatalf("PriceAt = %v, want %v", got, want)
+			}
+		})
+	}
+}
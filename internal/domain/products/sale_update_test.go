@@ -0,0 +1,77 @@
+package products
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestUpdateRejectsSellingPriceAtOrBelowSale(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE products (
+			id TEXT PRIMARY KEY,
+			name TEXT,
+			selling_price REAL NOT NULL DEFAULT 0,
+			sale_price REAL,
+			sale_starts_at DATETIME,
+			sale_ends_at DATETIME,
+			is_active BOOLEAN NOT NULL DEFAULT 1,
+			created_at DATETIME,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE product_images (
+			id TEXT PRIMARY KEY,
+			product_id TEXT NOT NULL,
+			url TEXT NOT NULL,
+			public_id TEXT,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			is_primary BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE product_price_history (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+			product_id TEXT NOT NULL,
+			old_cost_price REAL NOT NULL,
+			new_cost_price REAL NOT NULL,
+			old_selling_price REAL NOT NULL,
+			new_selling_price REAL NOT NULL,
+			changed_by TEXT,
+			created_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	id := uuid.New()
+	if err := db.Exec(`INSERT INTO products (id, name, selling_price, sale_price) VALUES (?, 'Rice', 1000, 800)`, id).Error; err != nil {
+		t.Fatalf("failed to insert product: %v", err)
+	}
+
+	s := NewService(NewRepository(db))
+	for _, price := range []float64{800, 700} {
+		if _, err := s.Update(context.Background(), id, UpdateProductRequest{SellingPrice: &price}, uuid.New()); err == nil {
+			t.Fatalf("selling price %v under a sale price of 800 was accepted", price)
+		}
+	}
+
+	var sellingPrice float64
+	db.Raw(`SELECT selling_price FROM products WHERE id = ?`, id).Scan(&sellingPrice)
+	if sellingPrice != 1000 {
+		t.Fatalf("selling price = %v, want it left at 1000", sellingPrice)
+	}
+
+	raised := 1200.0
+	if _, err := s.Update(context.Background(), id, UpdateProductRequest{SellingPrice: &raised}, uuid.New()); err != nil {
+		t.Fatalf("raising the selling price above the sale: %v", err)
+	}
+}
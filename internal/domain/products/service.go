@@ -7,28 +7,69 @@ import (
 	"log"
 	"strings"
 	"time"
+	"mime/multipart"
 	"unicode"
 
+	"errandShop/internal/services/upload"
+
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo   *Repository
-	logger *log.Logger
+	repo          *Repository
+	images        *upload.CloudinaryService
+	related       *relatedCache
+	storeLocation *time.Location
+	bestsellerWindow time.Duration
+	hideOutOfStock bool // see SetShowOutOfStock
+	wishlist      WishlistChecker
+	logger        *log.Logger
 }
 
 func NewService(r *Repository) *Service {
 	return &Service{
-		repo:   r,
-		logger: log.New(log.Writer(), "[PRODUCTS] ", log.LstdFlags|log.Lshortfile),
+		repo:          r,
+		related:       newRelatedCache(),
+		storeLocation: time.Local,
+		bestsellerWindow: DefaultBestsellerWindow,
+		logger:        log.New(log.Writer(), "[PRODUCTS] ", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// SetStoreLocation sets the timezone product availability schedules are read in
+func (s *Service) SetStoreLocation(loc *time.Location) {
+	if loc != nil {
+		s.storeLocation = loc
 	}
 }
 
+// SetShowOutOfStock sets whether the public product list includes active products with no stock,
+// flagged IsPurchasable false so clients can grey them out, or hides them. They are shown by
+// default. Either way they cannot be added to a cart or ordered.
+func (s *Service) SetShowOutOfStock(show bool) {
+	s.hideOutOfStock = !show
+}
+
+// SetImageStore enables hosted product images; without it uploads are rejected and
+// deletes leave image fields untouched.
+func (s *Service) SetImageStore(images *upload.CloudinaryService) {
+	s.images = images
+}
+
 // Types are defined in dto.go
 
 func (s *Service) List(ctx context.Context, q ListQuery) (ListResult, error) {
 	s.logger.Printf("Listing products with query: %+v", q)
 
+	if q.Category != "" {
+		names, err := s.resolveCategoryFilter(ctx, q.Category, q.IncludeSubcategories)
+		if err != nil {
+			return ListResult{}, fmt.Errorf("failed to list products: %w", err)
+		}
+		q.categories = names
+	}
+	q.inStockOnly = s.hideOutOfStock
+
 	items, total, err := s.repo.List(ctx, q)
 	if err != nil {
 		s.logger.Printf("Error listing products: %v", err)
@@ -113,6 +154,26 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 	if req.StockQuantity < 0 {
 		return nil, errors.New("stock quantity cannot be negative")
 	}
+	if err := validateSale(req.SellingPrice, req.SalePrice, req.SaleStartsAt, req.SaleEndsAt); err != nil {
+		return nil, err
+	}
+	if err := validateOrderQtyLimits(req.MinOrderQty, req.MaxOrderQty); err != nil {
+		return nil, err
+	}
+	availableDays, err := normalizeAvailability(req.AvailableDays, req.AvailableFrom, req.AvailableUntil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateSubstitute(ctx, uuid.Nil, req.SubstituteProductID); err != nil {
+		return nil, err
+	}
+	barcode, err := normalizeBarcode(req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBarcodeFree(ctx, uuid.Nil, barcode); err != nil {
+		return nil, err
+	}
 
 	product := &Product{
 		Name:              strings.TrimSpace(req.Name),
@@ -124,12 +185,28 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 		ImageURL:          strings.TrimSpace(req.ImageURL),
 		ImagePublicID:     req.ImagePublicID,
 		Category:          strings.TrimSpace(req.Category),
-		Tags:              req.Tags,
+		Tags:              NormalizeTags(req.Tags),
 		LowStockThreshold: req.LowStockThreshold,
+		WeightGrams:       req.WeightGrams,
+		MinOrderQty:       req.MinOrderQty,
+		MaxOrderQty:       req.MaxOrderQty,
+		AvailableDays:     availableDays,
+		AvailableFrom:     req.AvailableFrom,
+		AvailableUntil:    req.AvailableUntil,
+		SalePrice:         req.SalePrice,
+		SaleStartsAt:      req.SaleStartsAt,
+		SaleEndsAt:        req.SaleEndsAt,
 		IsActive:          true,
+		IsFeatured:        req.IsFeatured,
+		SubstituteProductID: req.SubstituteProductID,
+		Barcode:           barcode,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
+	if product.ImageURL != "" {
+		// Start the gallery with the product's image
+		product.Images = []ProductImage{{URL: product.ImageURL, PublicID: product.ImagePublicID, IsPrimary: true}}
+	}
 
 	if err := s.repo.Create(ctx, product); err != nil {
 		s.logger.Printf("Error creating product %s: %v", req.Name, err)
@@ -140,7 +217,9 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 	return s.toProductResponse(product), nil
 }
 
-func (s *Service) Update(ctx context.Context, id uuid.UUID, req UpdateProductRequest) (*ProductResponse, error) {
+// Update applies a partial update; cost/selling price changes are recorded in the price history
+// attributed to changedBy.
+func (s *Service) Update(ctx context.Context, id uuid.UUID, req UpdateProductRequest, changedBy uuid.UUID) (*ProductResponse, error) {
 	s.logger.Printf("Updating product ID: %s", id.String())
 
 	if id == uuid.Nil {
@@ -191,7 +270,7 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, req UpdateProductReq
 		updates["category"] = strings.TrimSpace(*req.Category)
 	}
 	if req.Tags != nil {
-		updates["tags"] = *req.Tags
+		updates["tags"] = NormalizeTags(*req.Tags)
 	}
 	if req.LowStockThreshold != nil {
 		if *req.LowStockThreshold < 0 {
@@ -199,9 +278,105 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, req UpdateProductReq
 		}
 		updates["low_stock_threshold"] = *req.LowStockThreshold
 	}
+	if req.WeightGrams != nil {
+		if *req.WeightGrams < 0 {
+			return nil, errors.New("weight cannot be negative")
+		}
+		updates["weight_grams"] = *req.WeightGrams
+	}
+	if req.MinOrderQty != nil || req.MaxOrderQty != nil {
+		// Validate the limits as they will be after the update, keeping whichever wasn't sent
+		minQty, maxQty := existingProduct.MinOrderQty, existingProduct.MaxOrderQty
+		if req.MinOrderQty != nil {
+			minQty = *req.MinOrderQty
+			updates["min_order_qty"] = minQty
+		}
+		if req.MaxOrderQty != nil {
+			maxQty = *req.MaxOrderQty
+			updates["max_order_qty"] = maxQty
+		}
+		if err := validateOrderQtyLimits(minQty, maxQty); err != nil {
+			return nil, err
+		}
+	}
+	if req.AvailableDays != nil || req.AvailableFrom != nil || req.AvailableUntil != nil {
+		// Validate the schedule as it will be after the update, keeping whichever parts weren't sent
+		days, from, until := existingProduct.AvailableDays, existingProduct.AvailableFrom, existingProduct.AvailableUntil
+		if req.AvailableDays != nil {
+			days = *req.AvailableDays
+		}
+		if req.AvailableFrom != nil {
+			from = *req.AvailableFrom
+			updates["available_from"] = from
+		}
+		if req.AvailableUntil != nil {
+			until = *req.AvailableUntil
+			updates["available_until"] = until
+		}
+		normalized, err := normalizeAvailability(days, from, until)
+		if err != nil {
+			return nil, err
+		}
+		if req.AvailableDays != nil {
+			updates["available_days"] = normalized
+		}
+	}
 	if req.IsActive != nil {
 		updates["is_active"] = *req.IsActive
 	}
+	if req.IsFeatured != nil {
+		updates["is_featured"] = *req.IsFeatured
+	}
+	if req.ClearSubstitute {
+		updates["substitute_product_id"] = nil
+	} else if req.SubstituteProductID != nil {
+		if err := s.validateSubstitute(ctx, id, req.SubstituteProductID); err != nil {
+			return nil, err
+		}
+		updates["substitute_product_id"] = *req.SubstituteProductID
+	}
+	if req.Barcode != nil {
+		barcode, err := normalizeBarcode(*req.Barcode)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkBarcodeFree(ctx, id, barcode); err != nil {
+			return nil, err
+		}
+		if barcode == nil {
+			updates["barcode"] = nil
+		} else {
+			updates["barcode"] = *barcode
+		}
+	}
+	if req.ClearSale {
+		updates["sale_price"] = nil
+		updates["sale_starts_at"] = nil
+		updates["sale_ends_at"] = nil
+	} else if req.SalePrice != nil || req.SaleStartsAt != nil || req.SaleEndsAt != nil || req.SellingPrice != nil {
+		// Validate the sale as it will be after the update, keeping fields that weren't sent. A new
+		// selling price is checked too, so it cannot drop to or below a running sale price.
+		sellingPrice, salePrice := existingProduct.SellingPrice, existingProduct.SalePrice
+		startsAt, endsAt := existingProduct.SaleStartsAt, existingProduct.SaleEndsAt
+		if req.SellingPrice != nil {
+			sellingPrice = *req.SellingPrice
+		}
+		if req.SalePrice != nil {
+			salePrice = req.SalePrice
+			updates["sale_price"] = *req.SalePrice
+		}
+		if req.SaleStartsAt != nil {
+			startsAt = req.SaleStartsAt
+			updates["sale_starts_at"] = *req.SaleStartsAt
+		}
+		if req.SaleEndsAt != nil {
+			endsAt = req.SaleEndsAt
+			updates["sale_ends_at"] = *req.SaleEndsAt
+		}
+		if err := validateSale(sellingPrice, salePrice, startsAt, endsAt); err != nil {
+			return nil, err
+		}
+	}
 
 	if len(updates) == 0 {
 		s.logger.Printf("No fields to update for product %s", id.String())
@@ -210,10 +385,46 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, req UpdateProductReq
 
 	updates["updated_at"] = time.Now()
 
-	if err := s.repo.Update(ctx, id, updates); err != nil {
+	newCost, newSelling := existingProduct.CostPrice, existingProduct.SellingPrice
+	if req.CostPrice != nil {
+		newCost = *req.CostPrice
+	}
+	if req.SellingPrice != nil {
+		newSelling = *req.SellingPrice
+	}
+
+	if newCost != existingProduct.CostPrice || newSelling != existingProduct.SellingPrice {
+		history := &ProductPriceHistory{
+			ProductID:       id,
+			OldCostPrice:    existingProduct.CostPrice,
+			NewCostPrice:    newCost,
+			OldSellingPrice: existingProduct.SellingPrice,
+			NewSellingPrice: newSelling,
+			ChangedBy:       changedBy,
+		}
+		if err := s.repo.UpdateWithPriceHistory(ctx, id, updates, history); err != nil {
+			s.logger.Printf("Error updating product %s: %v", id.String(), err)
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+		s.logger.Printf("Price change for product %s: cost %.2f -> %.2f, selling %.2f -> %.2f",
+			id.String(), existingProduct.CostPrice, newCost, existingProduct.SellingPrice, newSelling)
+	} else if err := s.repo.Update(ctx, id, updates); err != nil {
 		s.logger.Printf("Error updating product %s: %v", id.String(), err)
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
+	if req.ImageURL != nil || req.ImagePublicID != nil {
+		// Keep the gallery's primary image in step with the image fields
+		imageURL, publicID := existingProduct.ImageURL, existingProduct.ImagePublicID
+		if req.ImageURL != nil {
+			imageURL = strings.TrimSpace(*req.ImageURL)
+		}
+		if req.ImagePublicID != nil {
+			publicID = *req.ImagePublicID
+		}
+		if err := s.repo.SetImage(ctx, id, imageURL, publicID); err != nil {
+			return nil, fmt.Errorf("failed to update product image: %w", err)
+		}
+	}
 
 	updatedProduct, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -244,10 +455,71 @@ func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
+	// Remove the hosted gallery; the product is already deactivated, so failures are only logged
+	if s.images.Configured() {
+		images, err := s.repo.ClearProductImages(ctx, id)
+		if err != nil {
+			s.logger.Printf("Failed to clear images for product %s: %v", id.String(), err)
+		}
+		if len(images) == 0 && product.ImagePublicID != "" {
+			images = []ProductImage{{PublicID: product.ImagePublicID}}
+		}
+		for _, image := range images {
+			if image.PublicID == "" {
+				continue
+			}
+			if err := s.images.DeleteImage(image.PublicID); err != nil {
+				s.logger.Printf("Failed to delete image %s for product %s: %v", image.PublicID, id.String(), err)
+			}
+		}
+	}
+
 	s.logger.Printf("Successfully deleted product: %s (ID: %s)", product.Name, id.String())
 	return nil
 }
 
+// UploadImage uploads a product image to Cloudinary and stores its URL and public ID.
+// A previously hosted image is removed once the new one is saved.
+func (s *Service) UploadImage(ctx context.Context, id uuid.UUID, file *multipart.FileHeader) (*ImageUploadResponse, error) {
+	if !s.images.Configured() {
+		return nil, upload.ErrCloudinaryNotConfigured
+	}
+
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	result, err := s.images.UploadProductImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetImage(ctx, id, result.SecureURL, result.PublicID); err != nil {
+		// Don't leave an orphaned asset behind if we couldn't record it
+		if delErr := s.images.DeleteImage(result.PublicID); delErr != nil {
+			s.logger.Printf("Failed to remove orphaned image %s: %v", result.PublicID, delErr)
+		}
+		return nil, fmt.Errorf("failed to save product image: %w", err)
+	}
+
+	if product.ImagePublicID != "" && product.ImagePublicID != result.PublicID {
+		if err := s.images.DeleteImage(product.ImagePublicID); err != nil {
+			s.logger.Printf("Failed to delete previous image %s for product %s: %v", product.ImagePublicID, id.String(), err)
+		}
+	}
+
+	s.logger.Printf("Uploaded image for product %s: %s", id.String(), result.PublicID)
+	return &ImageUploadResponse{
+		ImageURL: result.SecureURL,
+		PublicID: result.PublicID,
+		Width:    result.Width,
+		Height:   result.Height,
+		Size:     result.Bytes,
+		Format:   result.Format,
+	}, nil
+}
+
 func (s *Service) AdminList(ctx context.Context, query AdminListQuery) (*ListResult, error) {
 	s.logger.Printf("Admin listing products with query: %+v", query)
 
@@ -257,6 +529,13 @@ func (s *Service) AdminList(ctx context.Context, query AdminListQuery) (*ListRes
 	if query.Limit <= 0 || query.Limit > 100 {
 		query.Limit = 20
 	}
+	if query.Category != "" {
+		names, err := s.resolveCategoryFilter(ctx, query.Category, query.IncludeSubcategories)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list products: %w", err)
+		}
+		query.categories = names
+	}
 
 	products, total, err := s.repo.AdminList(ctx, query)
 	if err != nil {
@@ -397,6 +676,12 @@ func (s *Service) CreateCategory(ctx context.Context, category *Category) error
 	}
 	category.Name = name
 
+	if category.ParentID != nil {
+		if err := s.validateCategoryParent(ctx, uuid.Nil, *category.ParentID); err != nil {
+			return err
+		}
+	}
+
 	// Set portable ID and timestamps to avoid DB-specific defaults
 	if category.ID == uuid.Nil {
 		category.ID = uuid.New()
@@ -455,6 +740,21 @@ func (s *Service) UpdateCategory(ctx context.Context, id uuid.UUID, req UpdateCa
 	if req.Description != nil {
 		updates["description"] = strings.TrimSpace(*req.Description)
 	}
+	if req.ParentID != nil {
+		parent := strings.TrimSpace(*req.ParentID)
+		if parent == "" {
+			updates["parent_id"] = nil
+		} else {
+			parentID, err := uuid.Parse(parent)
+			if err != nil {
+				return nil, ErrParentCategoryNotFound
+			}
+			if err := s.validateCategoryParent(ctx, id, parentID); err != nil {
+				return nil, err
+			}
+			updates["parent_id"] = parentID
+		}
+	}
 	if req.IsActive != nil {
 		updates["is_active"] = *req.IsActive
 	}
@@ -481,6 +781,13 @@ func (s *Service) DeleteCategory(ctx context.Context, id uuid.UUID) error {
 	if err != nil {
 		return fmt.Errorf("category not found: %w", err)
 	}
+	children, err := s.repo.CountActiveSubcategories(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check subcategories: %w", err)
+	}
+	if children > 0 {
+		return ErrCategoryHasSubcategories
+	}
 	if err := s.repo.DeleteCategory(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
@@ -500,6 +807,50 @@ func (s *Service) GetProductAnalytics(ctx context.Context) (*ProductAnalytics, e
 	return analytics, nil
 }
 
+func (s *Service) GetPriceHistory(ctx context.Context, productID uuid.UUID, limit int) ([]ProductPriceHistory, error) {
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	history, err := s.repo.GetPriceHistory(ctx, productID, limit)
+	if err != nil {
+		s.logger.Printf("Error getting price history: %v", err)
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	return history, nil
+}
+
+// GetStockShrinkageReport breaks down stock lost to damage, theft and corrections within [from, to).
+// Every shrinkage reason is listed, with zeros when nothing was recorded.
+func (s *Service) GetStockShrinkageReport(ctx context.Context, from, to time.Time) (*StockShrinkageReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("invalid date range: to must be after from")
+	}
+
+	rows, err := s.repo.GetStockShrinkage(ctx, ShrinkageReasons, from, to)
+	if err != nil {
+		s.logger.Printf("Error getting stock shrinkage: %v", err)
+		return nil, fmt.Errorf("failed to get stock shrinkage: %w", err)
+	}
+
+	byReason := make(map[StockChangeReason]StockShrinkageByReason, len(rows))
+	for _, row := range rows {
+		byReason[row.Reason] = row
+	}
+
+	report := &StockShrinkageReport{From: from, To: to, Reasons: make([]StockShrinkageByReason, 0, len(ShrinkageReasons))}
+	for _, reason := range ShrinkageReasons {
+		row, ok := byReason[reason]
+		if !ok {
+			row = StockShrinkageByReason{Reason: reason}
+		}
+		report.Reasons = append(report.Reasons, row)
+		report.TotalUnits += row.Units
+		report.TotalCostValue += row.CostValue
+	}
+	return report, nil
+}
+
 func (s *Service) GetStockHistory(ctx context.Context, productID uuid.UUID, limit int) ([]StockHistory, error) {
 	s.logger.Printf("Getting stock history for product %s", productID.String())
 
@@ -514,26 +865,67 @@ func (s *Service) GetStockHistory(ctx context.Context, productID uuid.UUID, limi
 }
 
 func (s *Service) toProductResponse(product *Product) *ProductResponse {
-	return &ProductResponse{
+	now := time.Now()
+	response := &ProductResponse{
 		ID:                product.ID,
 		SKU:               product.SKU,
+		Barcode:           product.Barcode,
 		Name:              product.Name,
 		Slug:              product.Slug,
 		Description:       product.Description,
 		CostPrice:         product.CostPrice,
 		SellingPrice:      product.SellingPrice,
+		EffectivePrice:    product.PriceAt(now),
+		OnSale:            product.OnSaleAt(now),
+		SalePrice:         product.SalePrice,
+		SaleStartsAt:      product.SaleStartsAt,
+		SaleEndsAt:        product.SaleEndsAt,
 		Profit:            product.Profit(),
 		StockQuantity:     product.StockQuantity,
 		ImageURL:          product.ImageURL,
 		ImagePublicID:     product.ImagePublicID,
+		Images:            galleryResponse(product),
 		Category:          product.Category,
 		Tags:              product.Tags,
 		LowStockThreshold: product.LowStockThreshold,
+		WeightGrams:       product.WeightGrams,
+		MinOrderQty:       product.MinOrderQty,
+		MaxOrderQty:       product.MaxOrderQty,
+		AvailableDays:     product.AvailableDays,
+		AvailableFrom:     product.AvailableFrom,
+		AvailableUntil:    product.AvailableUntil,
+		IsAvailableNow:    product.AvailableAt(now.In(s.storeLocation)),
+		NextAvailableAt:   product.NextAvailableAt(now.In(s.storeLocation)),
 		IsLowStock:        product.IsLowStock(),
+		IsPurchasable:     product.IsActive && product.StockQuantity > 0,
 		IsActive:          product.IsActive,
+		IsFeatured:        product.IsFeatured,
+		SubstituteProductID: product.SubstituteProductID,
 		CreatedAt:         product.CreatedAt,
 		UpdatedAt:         product.UpdatedAt,
 	}
+	if response.OnSale {
+		original := product.SellingPrice
+		response.OriginalPrice = &original
+	}
+	return response
+}
+
+// validateSale checks that a sale price undercuts the selling price and that its window is not inverted
+func validateSale(sellingPrice float64, salePrice *float64, startsAt, endsAt *time.Time) error {
+	if salePrice == nil {
+		if startsAt != nil || endsAt != nil {
+			return errors.New("sale window requires a sale price")
+		}
+		return nil
+	}
+	if *salePrice <= 0 || *salePrice >= sellingPrice {
+		return errors.New("sale price must be greater than 0 and below the selling price")
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return errors.New("sale must end after it starts")
+	}
+	return nil
 }
 
 func generateSlug(name string) string {
@@ -0,0 +1,29 @@
+package products_test
+
+import (
+	"testing"
+
+	products "errandShop/internal/domain/products"
+)
+
+func TestResolveStockChangeReason(t *testing.T) {
+	tests := []struct {
+		code products.StockChangeReason
+		note string
+		want products.StockChangeReason
+	}{
+		{products.StockReasonDamage, "crushed in transit", products.StockReasonDamage},
+		{"", "theft", products.StockReasonTheft},
+		{"", " Restock ", products.StockReasonRestock},
+		{"", "Order creation", products.StockReasonSale},
+		{"", "Order cancellation by admin", products.StockReasonCancellation},
+		{"", "counted shelf again", products.StockReasonCorrection},
+		{"", "", products.StockReasonCorrection},
+		{"lost", "", products.StockReasonCorrection},
+	}
+	for _, tt := range tests {
+		if got := products.ResolveStockChangeReason(tt.code, tt.note); got != tt.want {
+			t.Errorf("ResolveStockChangeReason(%q, %q) = %q, want %q", tt.code, tt.note, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSubstitute is returned when a product's substitute is itself or not an active product
+var ErrInvalidSubstitute = errors.New("invalid substitute product")
+
+// validateSubstitute checks that substituteID, when set, names another active product.
+// productID is uuid.Nil for a product that is being created.
+func (s *Service) validateSubstitute(ctx context.Context, productID uuid.UUID, substituteID *uuid.UUID) error {
+	if substituteID == nil {
+		return nil
+	}
+	if *substituteID == productID {
+		return fmt.Errorf("%w: a product cannot substitute for itself", ErrInvalidSubstitute)
+	}
+	if _, err := s.repo.GetByID(ctx, *substituteID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s is not an active product", ErrInvalidSubstitute, substituteID)
+		}
+		return fmt.Errorf("failed to check substitute product: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NormalizeTag lowercases and trims a tag and joins its words with hyphens, so "Cold Drink",
+// " cold  drink " and "cold-drink" are stored the same way
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// NormalizeTags normalizes every tag, dropping empty ones and duplicates while keeping the
+// first occurrence's position
+func NormalizeTags(tags StringSlice) StringSlice {
+	out := StringSlice{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// GetTags returns the distinct tags on active products with how many products carry each
+func (s *Service) GetTags(ctx context.Context) ([]TagResponse, error) {
+	tags, err := s.repo.GetTags(ctx)
+	if err != nil {
+		s.logger.Printf("Error getting tags: %v", err)
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,20 @@
+package products_test
+
+import (
+	"reflect"
+	"testing"
+
+	products "errandShop/internal/domain/products"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := products.NormalizeTags(products.StringSlice{"Cold Drink", " cold  drink ", "cold-drink", "", "  ", "Snacks\tand Chips"})
+	want := products.StringSlice{"cold-drink", "snacks-and-chips"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeTags() = %q, want %q", got, want)
+	}
+
+	if got := products.NormalizeTags(nil); got == nil || len(got) != 0 {
+		t.Fatalf("NormalizeTags(nil) = %#v, want empty slice", got)
+	}
+}
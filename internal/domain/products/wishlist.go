@@ -0,0 +1,37 @@
+package products
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// WishlistChecker reports whether a customer has saved a product to their wishlist
+type WishlistChecker interface {
+	IsWishlisted(ctx context.Context, userID, productID uuid.UUID) (bool, error)
+}
+
+// SetWishlistChecker lets product detail responses say whether the customer saved the product
+func (s *Service) SetWishlistChecker(checker WishlistChecker) {
+	s.wishlist = checker
+}
+
+// GetForCustomer returns a product like Get, flagging whether userID has it on their wishlist.
+// Guests (uuid.Nil) get the plain product. A failed wishlist lookup is logged rather than
+// failing the request, since the flag is only a display hint.
+func (s *Service) GetForCustomer(ctx context.Context, id, userID uuid.UUID) (*ProductResponse, error) {
+	product, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.wishlist == nil || userID == uuid.Nil {
+		return product, nil
+	}
+	wishlisted, err := s.wishlist.IsWishlisted(ctx, userID, id)
+	if err != nil {
+		s.logger.Printf("Error checking wishlist for product %s: %v", id.String(), err)
+		return product, nil
+	}
+	product.IsWishlisted = wishlisted
+	return product, nil
+}
@@ -0,0 +1,60 @@
+package reviews
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateReviewRequest submits a review for a delivered order. ProductID is required for product reviews.
+type CreateReviewRequest struct {
+	Type      ReviewType `json:"type" validate:"required,oneof=product delivery"`
+	ProductID *uuid.UUID `json:"productId"`
+	Rating    int        `json:"rating" validate:"required,min=1,max=5"`
+	Comment   string     `json:"comment" validate:"max=1000"`
+}
+
+// FeedbackRequest rates the delivery of an order and optionally tips the driver (kobo, capped at
+// orders.MaxTipKobo). A tip is charged as a new Paystack payment.
+type FeedbackRequest struct {
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment string `json:"comment" validate:"max=1000"`
+	TipKobo int64  `json:"tipKobo" validate:"min=0,max=5000000"`
+}
+
+// FeedbackResponse is the delivery review left and, when the customer tipped, the tip payment to
+// complete at AuthorizationURL
+type FeedbackResponse struct {
+	Review ReviewResponse `json:"review"`
+	Tip    *TipResponse   `json:"tip,omitempty"`
+}
+
+type TipResponse struct {
+	AmountKobo       int64     `json:"amountKobo"`
+	Status           TipStatus `json:"status"`
+	PaymentReference string    `json:"paymentReference"`
+	AuthorizationURL string    `json:"authorizationUrl"`
+}
+
+type ReviewResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	OrderID   uuid.UUID  `json:"orderId"`
+	Type      ReviewType `json:"type"`
+	ProductID *uuid.UUID `json:"productId,omitempty"`
+	Rating    int        `json:"rating"`
+	Comment   string     `json:"comment"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// RatingSummary aggregates the reviews of a product
+type RatingSummary struct {
+	AverageRating float64 `json:"averageRating"`
+	ReviewCount   int64   `json:"reviewCount"`
+}
+
+type ProductReviewsResponse struct {
+	Summary RatingSummary    `json:"summary"`
+	Reviews []ReviewResponse `json:"reviews"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+}
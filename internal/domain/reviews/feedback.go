@@ -0,0 +1,181 @@
+package reviews
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"errandShop/internal/domain/orders"
+	"errandShop/internal/domain/payments"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultFeedbackWindow is how long after delivery a customer can rate and tip the driver
+const DefaultFeedbackWindow = 7 * 24 * time.Hour
+
+var (
+	ErrFeedbackWindowClosed = errors.New("feedback can no longer be left for this delivery")
+	ErrTipNotCardPaid       = errors.New("a tip can only be added after delivery to card-paid orders")
+	ErrTipPaymentFailed     = errors.New("could not start the tip payment")
+)
+
+// TipStatus tracks a post-delivery tip from its Paystack charge to the driver's earnings
+type TipStatus string
+
+const (
+	TipStatusPending TipStatus = "pending" // waiting for the Paystack charge
+	TipStatusPaid    TipStatus = "paid"    // charged and added to the delivery's tip
+)
+
+// DeliveryTip is a tip a customer added after delivery, charged as its own Paystack payment. Once
+// paid it is added to the delivery's TipKobo, so it shows in the driver's earnings. An order takes
+// one post-delivery tip, as it takes one delivery review.
+type DeliveryTip struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"orderId"`
+	CustomerID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"customerId"`
+	DeliveryID       uint       `gorm:"not null;index" json:"deliveryId"`
+	DriverID         *uint      `gorm:"index" json:"driverId,omitempty"`
+	AmountKobo       int64      `gorm:"not null" json:"amountKobo"`
+	Status           TipStatus  `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	PaymentReference string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"paymentReference"`
+	PaidAt           *time.Time `json:"paidAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+func (DeliveryTip) TableName() string {
+	return "delivery_tips"
+}
+
+// TipPaymentInitializer opens the Paystack charge for a post-delivery tip
+type TipPaymentInitializer interface {
+	InitializePaystackPayment(email string, amount int64, metadata map[string]interface{}) (*payments.PaystackInitializeResponse, error)
+}
+
+// SetFeedbackWindow sets how long after delivery feedback is accepted; zero means no limit
+func (s *service) SetFeedbackWindow(window time.Duration) {
+	if window >= 0 {
+		s.feedbackWindow = window
+	}
+}
+
+// SetTipPayments sets the payment service post-delivery tips are charged through
+func (s *service) SetTipPayments(tipPayments TipPaymentInitializer) {
+	s.tipPayments = tipPayments
+}
+
+// SubmitFeedback rates the delivery of an order and, optionally, tips the driver in one step. The
+// rating updates the driver's average straight away; a tip is charged through Paystack and only
+// reaches the driver once the charge succeeds (see ConfirmTipPayment).
+func (s *service) SubmitFeedback(customerID, orderID uuid.UUID, req FeedbackRequest) (*FeedbackResponse, error) {
+	order, err := s.repo.GetCustomerOrder(orderID, customerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status != orders.OrderStatusDelivered {
+		return nil, ErrOrderNotDelivered
+	}
+	if !feedbackOpen(order, s.feedbackWindow, time.Now()) {
+		return nil, fmt.Errorf("%w: feedback closes %s after delivery", ErrFeedbackWindowClosed, s.feedbackWindow)
+	}
+	if req.TipKobo > 0 && order.PaymentMethod == orders.PaymentMethodCashOnDelivery {
+		return nil, ErrTipNotCardPaid
+	}
+
+	d, err := s.repo.GetOrderDelivery(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoDriverToReview
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+	exists, err := s.repo.Exists(orderID, ReviewTypeDelivery, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing review: %w", err)
+	}
+	if exists {
+		return nil, ErrAlreadyReviewed
+	}
+
+	review := &Review{
+		CustomerID: customerID,
+		OrderID:    orderID,
+		Type:       ReviewTypeDelivery,
+		DriverID:   d.DriverID,
+		Rating:     req.Rating,
+		Comment:    strings.TrimSpace(req.Comment),
+	}
+
+	var tip *DeliveryTip
+	var authorizationURL string
+	if req.TipKobo > 0 {
+		if s.tipPayments == nil {
+			return nil, fmt.Errorf("%w: payments are not configured", ErrTipPaymentFailed)
+		}
+		email, err := s.repo.GetCustomerEmail(customerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get customer email: %w", err)
+		}
+		init, err := s.tipPayments.InitializePaystackPayment(email, req.TipKobo, map[string]interface{}{
+			"purpose":     payments.PurposeDeliveryTip,
+			"order_id":    orderID.String(),
+			"customer_id": customerID.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTipPaymentFailed, err)
+		}
+		tip = &DeliveryTip{
+			OrderID:          orderID,
+			CustomerID:       customerID,
+			DeliveryID:       d.ID,
+			DriverID:         d.DriverID,
+			AmountKobo:       req.TipKobo,
+			Status:           TipStatusPending,
+			PaymentReference: init.Data.Reference,
+		}
+		authorizationURL = init.Data.AuthorizationURL
+	}
+
+	if err := s.repo.CreateFeedback(review, tip); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrAlreadyReviewed
+		}
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	response := &FeedbackResponse{Review: *toReviewResponse(review)}
+	if tip != nil {
+		response.Tip = &TipResponse{
+			AmountKobo:       tip.AmountKobo,
+			Status:           tip.Status,
+			PaymentReference: tip.PaymentReference,
+			AuthorizationURL: authorizationURL,
+		}
+	}
+	return response, nil
+}
+
+// ConfirmTipPayment records a tip whose Paystack charge succeeded; see payments.TipRecorder
+func (s *service) ConfirmTipPayment(reference string) error {
+	return s.repo.ConfirmTip(reference, time.Now())
+}
+
+// feedbackOpen reports whether now is within window of the order's delivery. Orders delivered
+// before DeliveredAt was recorded fall back to their last update.
+func feedbackOpen(order *orders.Order, window time.Duration, now time.Time) bool {
+	if window <= 0 {
+		return true
+	}
+	deliveredAt := order.UpdatedAt
+	if order.DeliveredAt != nil {
+		deliveredAt = *order.DeliveredAt
+	}
+	return now.Before(deliveredAt.Add(window))
+}
@@ -0,0 +1,82 @@
+package reviews
+
+import (
+	"testing"
+	"time"
+
+	"errandShop/internal/domain/orders"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestConfirmTipPaysDriverOnce(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite in-memory db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE deliveries (id INTEGER PRIMARY KEY, tip_kobo INTEGER NOT NULL DEFAULT 0)`,
+		`CREATE TABLE delivery_tips (
+			id TEXT PRIMARY KEY,
+			order_id TEXT NOT NULL,
+			customer_id TEXT NOT NULL,
+			delivery_id INTEGER NOT NULL,
+			driver_id INTEGER,
+			amount_kobo INTEGER NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			payment_reference TEXT NOT NULL UNIQUE,
+			paid_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`INSERT INTO deliveries (id, tip_kobo) VALUES (7, 50000)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to create schema: %v", err)
+		}
+	}
+	tip := DeliveryTip{ID: uuid.New(), OrderID: uuid.New(), CustomerID: uuid.New(), DeliveryID: 7, AmountKobo: 100000, Status: TipStatusPending, PaymentReference: "TXN_tip"}
+	if err := db.Create(&tip).Error; err != nil {
+		t.Fatalf("failed to insert tip: %v", err)
+	}
+
+	repo := NewRepository(db)
+	// The verify call and the webhook both confirm the same charge
+	for i := 0; i < 2; i++ {
+		if err := repo.ConfirmTip("TXN_tip", time.Now()); err != nil {
+			t.Fatalf("ConfirmTip #%d returned error: %v", i+1, err)
+		}
+	}
+	var deliveryTip int64
+	db.Raw("SELECT tip_kobo FROM deliveries WHERE id = 7").Scan(&deliveryTip)
+	if deliveryTip != 150000 {
+		t.Fatalf("delivery tip = %d, want the checkout tip plus the post-delivery tip once (150000)", deliveryTip)
+	}
+	var status string
+	db.Raw("SELECT status FROM delivery_tips WHERE id = ?", tip.ID).Scan(&status)
+	if status != string(TipStatusPaid) {
+		t.Fatalf("tip status = %q, want paid", status)
+	}
+
+	if err := repo.ConfirmTip("TXN_unknown", time.Now()); err == nil {
+		t.Fatalf("confirming an unknown reference should fail")
+	}
+}
+
+func TestFeedbackOpenWithinWindowOfDelivery(t *testing.T) {
+	deliveredAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	order := &orders.Order{DeliveredAt: &deliveredAt, UpdatedAt: deliveredAt.Add(30 * 24 * time.Hour)}
+	window := 7 * 24 * time.Hour
+
+	if !feedbackOpen(order, window, deliveredAt.Add(6*24*time.Hour)) {
+		t.Fatalf("feedback should be open six days after delivery")
+	}
+	if feedbackOpen(order, window, deliveredAt.Add(8*24*time.Hour)) {
+		t.Fatalf("feedback should close after the window, even if the order was updated later")
+	}
+	if !feedbackOpen(order, 0, deliveredAt.Add(365*24*time.Hour)) {
+		t.Fatalf("a zero window should never close")
+	}
+}
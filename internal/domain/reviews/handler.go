@@ -0,0 +1,139 @@
+package reviews
+
+import (
+	"errors"
+
+	"errandShop/internal/presenter"
+	"errandShop/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateReview rates a product from, or the delivery of, one of the customer's delivered orders
+// POST /api/v1/orders/:id/reviews
+func (h *Handler) CreateReview(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Unauthorized(c, "User not authenticated")
+	}
+
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid order ID")
+	}
+
+	var req CreateReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	review, err := h.service.CreateReview(userID, orderID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrOrderNotFound):
+			return presenter.NotFound(c, "Order not found")
+		case errors.Is(err, ErrAlreadyReviewed):
+			return presenter.Conflict(c, err.Error())
+		case errors.Is(err, ErrOrderNotDelivered),
+			errors.Is(err, ErrProductRequired),
+			errors.Is(err, ErrProductNotInOrder),
+			errors.Is(err, ErrNoDriverToReview):
+			return presenter.BadRequest(c, err.Error())
+		}
+		return presenter.InternalServerError(c, "Failed to submit review")
+	}
+
+	return presenter.Created(c, review)
+}
+
+// SubmitFeedback rates the delivery of one of the customer's delivered orders and optionally tips
+// the driver, within the feedback window after delivery
+// POST /api/v1/orders/:id/feedback
+func (h *Handler) SubmitFeedback(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Unauthorized(c, "User not authenticated")
+	}
+
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid order ID")
+	}
+
+	var req FeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	feedback, err := h.service.SubmitFeedback(userID, orderID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrOrderNotFound):
+			return presenter.NotFound(c, "Order not found")
+		case errors.Is(err, ErrAlreadyReviewed):
+			return presenter.Conflict(c, err.Error())
+		case errors.Is(err, ErrOrderNotDelivered),
+			errors.Is(err, ErrFeedbackWindowClosed),
+			errors.Is(err, ErrTipNotCardPaid),
+			errors.Is(err, ErrNoDriverToReview):
+			return presenter.BadRequest(c, err.Error())
+		case errors.Is(err, ErrTipPaymentFailed):
+			return presenter.InternalServerError(c, ErrTipPaymentFailed.Error())
+		}
+		return presenter.InternalServerError(c, "Failed to submit feedback")
+	}
+
+	return presenter.Created(c, feedback)
+}
+
+// GetOrderReviews lists the reviews the customer left on an order
+// GET /api/v1/orders/:id/reviews
+func (h *Handler) GetOrderReviews(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return presenter.Unauthorized(c, "User not authenticated")
+	}
+
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid order ID")
+	}
+
+	reviews, err := h.service.GetOrderReviews(userID, orderID)
+	if err != nil {
+		return presenter.InternalServerError(c, "Failed to get reviews")
+	}
+
+	return presenter.Success(c, "Reviews retrieved successfully", reviews)
+}
+
+// GetProductReviews lists a product's reviews with its average rating
+// GET /api/v1/products/:id/reviews
+func (h *Handler) GetProductReviews(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid product ID")
+	}
+
+	result, err := h.service.GetProductReviews(productID, c.QueryInt("page", 1), c.QueryInt("limit", 20))
+	if err != nil {
+		return presenter.InternalServerError(c, "Failed to get reviews")
+	}
+
+	return presenter.Success(c, "Reviews retrieved successfully", result)
+}
@@ -0,0 +1,34 @@
+package reviews
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewType says what a review rates
+type ReviewType string
+
+const (
+	ReviewTypeProduct  ReviewType = "product"
+	ReviewTypeDelivery ReviewType = "delivery"
+)
+
+// Review is a customer's 1-5 star rating of a product they received or of the delivery of an order.
+// Customers may review each product once per order and each order's delivery once.
+type Review struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CustomerID uuid.UUID  `gorm:"type:uuid;not null;index" json:"customerId"`
+	OrderID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"orderId"`
+	Type       ReviewType `gorm:"type:varchar(20);not null" json:"type"`
+	ProductID  *uuid.UUID `gorm:"type:uuid;index" json:"productId,omitempty"` // set for product reviews
+	DriverID   *uint      `gorm:"index" json:"driverId,omitempty"`            // set for delivery reviews
+	Rating     int        `gorm:"not null;check:rating >= 1 AND rating <= 5" json:"rating"`
+	Comment    string     `gorm:"type:text" json:"comment"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+func (Review) TableName() string {
+	return "reviews"
+}
@@ -0,0 +1,155 @@
+package reviews
+
+import (
+	"time"
+
+	"errandShop/internal/domain/delivery"
+	"errandShop/internal/domain/orders"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetCustomerOrder(orderID, customerID uuid.UUID) (*orders.Order, error)
+	GetOrderDelivery(orderID uuid.UUID) (*delivery.Delivery, error)
+	Exists(orderID uuid.UUID, reviewType ReviewType, productID *uuid.UUID) (bool, error)
+	Create(review *Review) error
+	ListByOrder(orderID, customerID uuid.UUID) ([]Review, error)
+	ListByProduct(productID uuid.UUID, page, limit int) ([]Review, error)
+	GetProductSummary(productID uuid.UUID) (*RatingSummary, error)
+	GetCustomerEmail(customerID uuid.UUID) (string, error)
+	CreateFeedback(review *Review, tip *DeliveryTip) error
+	ConfirmTip(reference string, paidAt time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetCustomerOrder(orderID, customerID uuid.UUID) (*orders.Order, error) {
+	var order orders.Order
+	err := r.db.Preload("Items").Where("id = ? AND customer_id = ?", orderID, customerID).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *repository) GetOrderDelivery(orderID uuid.UUID) (*delivery.Delivery, error) {
+	var d delivery.Delivery
+	err := r.db.Where("order_id = ? AND driver_id IS NOT NULL", orderID).Order("created_at DESC").First(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *repository) Exists(orderID uuid.UUID, reviewType ReviewType, productID *uuid.UUID) (bool, error) {
+	var count int64
+	query := r.db.Model(&Review{}).Where("order_id = ? AND type = ?", orderID, reviewType)
+	if productID != nil {
+		query = query.Where("product_id = ?", *productID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// Create saves the review. Delivery reviews also refresh the driver's average rating and
+// delivered count in the same transaction.
+func (r *repository) Create(review *Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createReview(tx, review)
+	})
+}
+
+// CreateFeedback saves a delivery review and, when the customer tipped, the pending tip together
+func (r *repository) CreateFeedback(review *Review, tip *DeliveryTip) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createReview(tx, review); err != nil {
+			return err
+		}
+		if tip == nil {
+			return nil
+		}
+		return tx.Create(tip).Error
+	})
+}
+
+// ConfirmTip marks a pending tip paid and adds it to its delivery's tip. A tip already paid is
+// left alone, so confirming the same charge twice does not pay the driver twice.
+func (r *repository) ConfirmTip(reference string, paidAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tip DeliveryTip
+		if err := tx.Where("payment_reference = ?", reference).First(&tip).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&DeliveryTip{}).
+			Where("id = ? AND status = ?", tip.ID, TipStatusPending).
+			Updates(map[string]interface{}{"status": TipStatusPaid, "paid_at": paidAt})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Exec("UPDATE deliveries SET tip_kobo = tip_kobo + ? WHERE id = ?", tip.AmountKobo, tip.DeliveryID).Error
+	})
+}
+
+func (r *repository) GetCustomerEmail(customerID uuid.UUID) (string, error) {
+	var email string
+	err := r.db.Raw("SELECT email FROM users WHERE id = ? AND deleted_at IS NULL", customerID).Scan(&email).Error
+	if err == nil && email == "" {
+		err = gorm.ErrRecordNotFound
+	}
+	return email, err
+}
+
+// createReview saves the review. Delivery reviews also refresh the driver's average rating and
+// delivered count.
+func createReview(tx *gorm.DB, review *Review) error {
+	if err := tx.Create(review).Error; err != nil {
+		return err
+	}
+	if review.Type != ReviewTypeDelivery || review.DriverID == nil {
+		return nil
+	}
+
+	return tx.Exec(`
+			UPDATE delivery_drivers SET
+				rating = (SELECT AVG(rating) FROM reviews WHERE driver_id = ? AND type = ?),
+				total_deliveries = (SELECT COUNT(*) FROM deliveries WHERE driver_id = ? AND status = ? AND deleted_at IS NULL),
+				updated_at = NOW()
+			WHERE id = ?`,
+		*review.DriverID, ReviewTypeDelivery, *review.DriverID, delivery.DeliveryStatusDelivered, *review.DriverID).Error
+}
+
+func (r *repository) ListByOrder(orderID, customerID uuid.UUID) ([]Review, error) {
+	var reviews []Review
+	err := r.db.Where("order_id = ? AND customer_id = ?", orderID, customerID).Order("created_at ASC").Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *repository) ListByProduct(productID uuid.UUID, page, limit int) ([]Review, error) {
+	var reviews []Review
+	err := r.db.Where("product_id = ? AND type = ?", productID, ReviewTypeProduct).
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *repository) GetProductSummary(productID uuid.UUID) (*RatingSummary, error) {
+	var summary RatingSummary
+	err := r.db.Model(&Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average_rating, COUNT(*) AS review_count").
+		Where("product_id = ? AND type = ?", productID, ReviewTypeProduct).
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
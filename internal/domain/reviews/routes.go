@@ -0,0 +1,22 @@
+package reviews
+
+import (
+	"errandShop/config"
+	"errandShop/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupRoutes sets up review routes
+func SetupRoutes(app *fiber.App, handler *Handler, cfg *config.Config) {
+	api := app.Group("/api/v1")
+
+	// Customer reviews of their delivered orders (protected)
+	api.Post("/orders/:id/reviews", middleware.JWTMiddleware(cfg), handler.CreateReview)   // POST /api/v1/orders/:id/reviews
+	api.Get("/orders/:id/reviews", middleware.JWTMiddleware(cfg), handler.GetOrderReviews) // GET /api/v1/orders/:id/reviews
+	// Feedback posts a review in the customer's name and can open a tip charge, so not while impersonating
+	api.Post("/orders/:id/feedback", middleware.JWTMiddleware(cfg), middleware.NoImpersonation(), handler.SubmitFeedback) // POST /api/v1/orders/:id/feedback
+
+	// Product reviews (public)
+	api.Get("/products/:id/reviews", handler.GetProductReviews) // GET /api/v1/products/:id/reviews
+}
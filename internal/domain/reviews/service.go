@@ -0,0 +1,168 @@
+package reviews
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"errandShop/internal/domain/orders"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderNotDelivered = errors.New("only delivered orders can be reviewed")
+	ErrProductRequired   = errors.New("productId is required for product reviews")
+	ErrProductNotInOrder = errors.New("product is not part of this order")
+	ErrNoDriverToReview  = errors.New("this order has no driver to review")
+	ErrAlreadyReviewed   = errors.New("you have already reviewed this")
+)
+
+type Service interface {
+	CreateReview(customerID, orderID uuid.UUID, req CreateReviewRequest) (*ReviewResponse, error)
+	GetOrderReviews(customerID, orderID uuid.UUID) ([]ReviewResponse, error)
+	GetProductReviews(productID uuid.UUID, page, limit int) (*ProductReviewsResponse, error)
+
+	// Post-delivery feedback (see feedback.go)
+	SubmitFeedback(customerID, orderID uuid.UUID, req FeedbackRequest) (*FeedbackResponse, error)
+	ConfirmTipPayment(reference string) error
+	SetFeedbackWindow(window time.Duration)
+	SetTipPayments(tipPayments TipPaymentInitializer)
+}
+
+type service struct {
+	repo           Repository
+	feedbackWindow time.Duration
+	tipPayments    TipPaymentInitializer
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo, feedbackWindow: DefaultFeedbackWindow}
+}
+
+func (s *service) CreateReview(customerID, orderID uuid.UUID, req CreateReviewRequest) (*ReviewResponse, error) {
+	order, err := s.repo.GetCustomerOrder(orderID, customerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status != orders.OrderStatusDelivered {
+		return nil, ErrOrderNotDelivered
+	}
+
+	review := &Review{
+		CustomerID: customerID,
+		OrderID:    orderID,
+		Type:       req.Type,
+		Rating:     req.Rating,
+		Comment:    strings.TrimSpace(req.Comment),
+	}
+
+	switch req.Type {
+	case ReviewTypeProduct:
+		if req.ProductID == nil {
+			return nil, ErrProductRequired
+		}
+		if !orderHasProduct(order, *req.ProductID) {
+			return nil, ErrProductNotInOrder
+		}
+		review.ProductID = req.ProductID
+	case ReviewTypeDelivery:
+		d, err := s.repo.GetOrderDelivery(orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNoDriverToReview
+			}
+			return nil, fmt.Errorf("failed to get delivery: %w", err)
+		}
+		review.DriverID = d.DriverID
+	}
+
+	exists, err := s.repo.Exists(orderID, review.Type, review.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing review: %w", err)
+	}
+	if exists {
+		return nil, ErrAlreadyReviewed
+	}
+
+	if err := s.repo.Create(review); err != nil {
+		// The unique indexes catch a duplicate submitted concurrently
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrAlreadyReviewed
+		}
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	return toReviewResponse(review), nil
+}
+
+func (s *service) GetOrderReviews(customerID, orderID uuid.UUID) ([]ReviewResponse, error) {
+	reviews, err := s.repo.ListByOrder(orderID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	responses := make([]ReviewResponse, len(reviews))
+	for i := range reviews {
+		responses[i] = *toReviewResponse(&reviews[i])
+	}
+	return responses, nil
+}
+
+func (s *service) GetProductReviews(productID uuid.UUID, page, limit int) (*ProductReviewsResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	summary, err := s.repo.GetProductSummary(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating summary: %w", err)
+	}
+
+	reviews, err := s.repo.ListByProduct(productID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	responses := make([]ReviewResponse, len(reviews))
+	for i := range reviews {
+		responses[i] = *toReviewResponse(&reviews[i])
+	}
+
+	return &ProductReviewsResponse{
+		Summary: *summary,
+		Reviews: responses,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+func orderHasProduct(order *orders.Order, productID uuid.UUID) bool {
+	for _, item := range order.Items {
+		if item.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+func toReviewResponse(review *Review) *ReviewResponse {
+	return &ReviewResponse{
+		ID:        review.ID,
+		OrderID:   review.OrderID,
+		Type:      review.Type,
+		ProductID: review.ProductID,
+		Rating:    review.Rating,
+		Comment:   review.Comment,
+		CreatedAt: review.CreatedAt,
+	}
+}
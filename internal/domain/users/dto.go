@@ -8,7 +8,7 @@ type CreateUserRequest struct {
 	Email       string   `json:"email" binding:"required,email"`
 	Phone       string   `json:"phone"`
 	Password    string   `json:"password" binding:"required,min=8"`
-	Role        string   `json:"role" binding:"required,oneof=admin superadmin customer"`
+	Role        string   `json:"role" binding:"required,oneof=admin superadmin customer driver"`
 	Permissions []string `json:"permissions"`
 }
 
@@ -16,7 +16,7 @@ type UpdateUserRequest struct {
 	Name  string `json:"name"`
 	Email string `json:"email" binding:"omitempty,email"`
 	Phone string `json:"phone"`
-	Role  string `json:"role" binding:"omitempty,oneof=admin superadmin customer"`
+	Role  string `json:"role" binding:"omitempty,oneof=admin superadmin customer driver"`
 }
 
 type UpdateProfileRequest struct {
@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateWebhookRequest registers an endpoint. A secret is generated when none is given; an empty
+// event list subscribes to every event.
+type CreateWebhookRequest struct {
+	URL         string      `json:"url" validate:"required,url,max=500"`
+	Secret      string      `json:"secret" validate:"omitempty,min=16,max=255"`
+	Events      []EventType `json:"events"`
+	Description string      `json:"description" validate:"max=255"`
+}
+
+type WebhookResponse struct {
+	ID          uuid.UUID   `json:"id"`
+	URL         string      `json:"url"`
+	Secret      string      `json:"secret,omitempty"` // only returned when the webhook is created
+	Events      []EventType `json:"events"`
+	Description string      `json:"description"`
+	IsActive    bool        `json:"isActive"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+// DeliveryListResponse is a page of the delivery log
+type DeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+}
+
+// EventPayload is the JSON body POSTed to endpoints
+type EventPayload struct {
+	ID        string      `json:"id"` // delivery-independent event ID; receivers can use it to dedupe retries
+	Event     EventType   `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
@@ -0,0 +1,105 @@
+package webhooks
+
+import (
+	"errors"
+	"strconv"
+
+	"errandShop/internal/presenter"
+	"errandShop/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateWebhook registers an endpoint; the response is the only time the secret is shown
+// POST /api/v1/admin/webhooks
+func (h *Handler) CreateWebhook(c *fiber.Ctx) error {
+	var req CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return presenter.BadRequest(c, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(&req); err != nil {
+		return presenter.BadRequest(c, err.Error())
+	}
+
+	webhook, err := h.service.CreateWebhook(req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidURL) || errors.Is(err, ErrInvalidEvent) {
+			return presenter.BadRequest(c, err.Error())
+		}
+		return presenter.InternalServerError(c, "Failed to create webhook")
+	}
+
+	return presenter.Created(c, webhook)
+}
+
+// ListWebhooks lists registered endpoints
+// GET /api/v1/admin/webhooks
+func (h *Handler) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.service.ListWebhooks()
+	if err != nil {
+		return presenter.InternalServerError(c, "Failed to get webhooks")
+	}
+	return presenter.Success(c, "Webhooks retrieved successfully", webhooks)
+}
+
+// DeleteWebhook removes an endpoint and its delivery log
+// DELETE /api/v1/admin/webhooks/:id
+func (h *Handler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return presenter.BadRequest(c, "Invalid webhook ID")
+	}
+
+	if err := h.service.DeleteWebhook(id); err != nil {
+		if errors.Is(err, ErrWebhookNotFound) {
+			return presenter.NotFound(c, "Webhook not found")
+		}
+		return presenter.InternalServerError(c, "Failed to delete webhook")
+	}
+
+	return presenter.Success(c, "Webhook deleted successfully", nil)
+}
+
+// ListDeliveries pages through the delivery log, optionally for one webhook (?webhookId=) or status (?status=)
+// GET /api/v1/admin/webhooks/deliveries
+func (h *Handler) ListDeliveries(c *fiber.Ctx) error {
+	var webhookID *uuid.UUID
+	if raw := c.Query("webhookId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return presenter.BadRequest(c, "Invalid webhook ID")
+		}
+		webhookID = &id
+	}
+
+	status := DeliveryStatus(c.Query("status"))
+	switch status {
+	case "", DeliveryPending, DeliveryDelivered, DeliveryFailed, DeliveryRetrying, DeliveryDeadLetter:
+	default:
+		return presenter.BadRequest(c, "Invalid status (use pending, delivered, failed, retrying or dead_letter)")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	deliveries, err := h.service.ListDeliveries(webhookID, status, page, limit)
+	if err != nil {
+		return presenter.InternalServerError(c, "Failed to get webhook deliveries")
+	}
+	return presenter.Success(c, "Webhook deliveries retrieved successfully", deliveries)
+}
@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names an order event an endpoint can subscribe to
+type EventType string
+
+const (
+	EventOrderCreated   EventType = "order.created"
+	EventOrderPaid      EventType = "order.paid"
+	EventOrderDelivered EventType = "order.delivered"
+	EventOrderCancelled EventType = "order.cancelled"
+)
+
+// Events lists every event an endpoint can subscribe to
+var Events = []EventType{
+	EventOrderCreated,
+	EventOrderPaid,
+	EventOrderDelivered,
+	EventOrderCancelled,
+}
+
+// IsValid reports whether e is a known event
+func (e EventType) IsValid() bool {
+	for _, known := range Events {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus tracks one POST of an event to an endpoint
+type DeliveryStatus string
+
+const (
+	DeliveryPending    DeliveryStatus = "pending"
+	DeliveryDelivered  DeliveryStatus = "delivered"
+	DeliveryFailed     DeliveryStatus = "failed"      // last attempt failed, retry scheduled at NextAttemptAt
+	DeliveryRetrying   DeliveryStatus = "retrying"    // claimed by the retry worker
+	DeliveryDeadLetter DeliveryStatus = "dead_letter" // gave up after the maximum number of attempts
+)
+
+// Webhook is an admin-registered endpoint that receives signed order events
+type Webhook struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	URL         string    `gorm:"type:varchar(500);not null" json:"url"`
+	Secret      string    `gorm:"type:varchar(255);not null" json:"-"` // HMAC key for the signature header
+	Events      string    `gorm:"type:text" json:"events"`             // comma separated; empty subscribes to every event
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	IsActive    bool      `gorm:"default:true" json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// EventList returns the subscribed events; nil means all of them
+func (w *Webhook) EventList() []EventType {
+	if strings.TrimSpace(w.Events) == "" {
+		return nil
+	}
+	var events []EventType
+	for _, e := range strings.Split(w.Events, ",") {
+		events = append(events, EventType(strings.TrimSpace(e)))
+	}
+	return events
+}
+
+// Subscribes reports whether the endpoint wants event
+func (w *Webhook) Subscribes(event EventType) bool {
+	events := w.EventList()
+	if events == nil {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the delivery log: one row per event sent to an endpoint, updated on every attempt
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	WebhookID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"webhookId"`
+	Event          EventType      `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string         `gorm:"type:jsonb;not null" json:"payload"`
+	Status         DeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Attempts       int            `gorm:"default:0" json:"attempts"`
+	ResponseStatus int            `json:"responseStatus,omitempty"`
+	LastError      string         `gorm:"type:text" json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time     `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time     `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+
+	Webhook Webhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
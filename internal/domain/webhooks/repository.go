@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(webhook *Webhook) error
+	List() ([]Webhook, error)
+	ListActive() ([]Webhook, error)
+	Delete(id uuid.UUID) (bool, error)
+	CreateDelivery(delivery *WebhookDelivery) error
+	RecordAttempt(id uint, status DeliveryStatus, attempts, responseStatus int, lastError string, nextAttemptAt *time.Time) error
+	ClaimForRetry(id uint) (bool, error)
+	GetDueForRetry(now time.Time, staleClaim time.Duration, limit int) ([]WebhookDelivery, error)
+	ListDeliveries(webhookID *uuid.UUID, status DeliveryStatus, page, limit int) ([]WebhookDelivery, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(webhook *Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *repository) List() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.Order("created_at DESC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *repository) ListActive() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.Where("is_active = ?", true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Delete removes the webhook and, through the foreign key, its delivery log
+func (r *repository) Delete(id uuid.UUID) (bool, error) {
+	res := r.db.Delete(&Webhook{}, "id = ?", id)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *repository) CreateDelivery(delivery *WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *repository) RecordAttempt(id uint, status DeliveryStatus, attempts, responseStatus int, lastError string, nextAttemptAt *time.Time) error {
+	updates := map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"response_status": responseStatus,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}
+	if status == DeliveryDelivered {
+		updates["delivered_at"] = time.Now()
+	}
+	return r.db.Model(&WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ClaimForRetry marks a failed (or stale retrying) delivery as retrying; it reports false
+// if another worker got to it first.
+func (r *repository) ClaimForRetry(id uint) (bool, error) {
+	res := r.db.Model(&WebhookDelivery{}).
+		Where("id = ? AND status IN ?", id, []DeliveryStatus{DeliveryFailed, DeliveryRetrying}).
+		Updates(map[string]interface{}{"status": DeliveryRetrying})
+	return res.RowsAffected > 0, res.Error
+}
+
+// GetDueForRetry returns failed deliveries whose backoff has elapsed, plus retries that were
+// claimed more than staleClaim ago, with their webhook loaded.
+func (r *repository) GetDueForRetry(now time.Time, staleClaim time.Duration, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := r.db.Preload("Webhook").
+		Where("(status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)) OR (status = ? AND updated_at < ?)",
+			DeliveryFailed, now, DeliveryRetrying, now.Add(-staleClaim)).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *repository) ListDeliveries(webhookID *uuid.UUID, status DeliveryStatus, page, limit int) ([]WebhookDelivery, int64, error) {
+	query := r.db.Model(&WebhookDelivery{})
+	if webhookID != nil {
+		query = query.Where("webhook_id = ?", *webhookID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []WebhookDelivery
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
@@ -0,0 +1,51 @@
+package webhooks
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RetryWorker periodically re-sends webhook deliveries that failed, with the backoff and
+// attempt limit configured on the Service.
+type RetryWorker struct {
+	service   Service
+	interval  time.Duration
+	batchSize int
+	logger    *log.Logger
+}
+
+func NewRetryWorker(service Service, interval time.Duration) *RetryWorker {
+	return &RetryWorker{
+		service:   service,
+		interval:  interval,
+		batchSize: 100,
+		logger:    log.New(log.Writer(), "[WEBHOOK-RETRY] ", log.LstdFlags),
+	}
+}
+
+// Start runs the worker on a fixed interval until ctx is cancelled
+func (w *RetryWorker) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		w.logger.Println("disabled (no interval configured)")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if retried, err := w.service.RetryFailed(w.batchSize); err != nil {
+					w.logger.Printf("run failed: %v", err)
+				} else if retried > 0 {
+					w.logger.Printf("retried %d deliveries", retried)
+				}
+			}
+		}
+	}()
+}
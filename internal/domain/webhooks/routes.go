@@ -0,0 +1,20 @@
+package webhooks
+
+import (
+	"errandShop/config"
+	"errandShop/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupAdminRoutes sets up webhook management routes
+func SetupAdminRoutes(app *fiber.App, handler *Handler, cfg *config.Config) {
+	admin := app.Group("/api/v1/admin/webhooks")
+	admin.Use(middleware.JWTMiddleware(cfg))
+	admin.Use(middleware.AdminMiddleware())
+
+	admin.Post("/", handler.CreateWebhook)           // POST /api/v1/admin/webhooks
+	admin.Get("/", handler.ListWebhooks)             // GET /api/v1/admin/webhooks
+	admin.Get("/deliveries", handler.ListDeliveries) // GET /api/v1/admin/webhooks/deliveries
+	admin.Delete("/:id", handler.DeleteWebhook)      // DELETE /api/v1/admin/webhooks/:id
+}
@@ -0,0 +1,308 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidURL      = errors.New("webhook url must be an absolute http or https URL")
+	ErrInvalidEvent    = errors.New("unknown webhook event")
+)
+
+const (
+	// Header names sent with every delivery
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	DefaultMaxAttempts = 6
+	DefaultBackoff     = time.Minute
+	maxBackoff         = 6 * time.Hour
+	// staleRetryClaim is how long a claimed retry may sit before another run picks it up again
+	staleRetryClaim = 15 * time.Minute
+	// maxErrorBody caps how much of a failed response is kept in the delivery log
+	maxErrorBody = 500
+)
+
+type Service interface {
+	CreateWebhook(req CreateWebhookRequest) (*WebhookResponse, error)
+	ListWebhooks() ([]WebhookResponse, error)
+	DeleteWebhook(id uuid.UUID) error
+	ListDeliveries(webhookID *uuid.UUID, status DeliveryStatus, page, limit int) (*DeliveryListResponse, error)
+
+	// Dispatch queues event for every active subscribed endpoint and sends it in the background
+	Dispatch(event EventType, data interface{})
+	SetRetryPolicy(maxAttempts int, baseBackoff time.Duration)
+	RetryFailed(limit int) (int, error)
+}
+
+type service struct {
+	repo        Repository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo:        repo,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+	}
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the endpoint secret. Receivers should recompute it and reject
+// stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *service) CreateWebhook(req CreateWebhookRequest) (*WebhookResponse, error) {
+	parsed, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, e := range req.Events {
+		if !e.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidEvent, e)
+		}
+		events = append(events, string(e))
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+	}
+
+	webhook := &Webhook{
+		URL:         parsed.String(),
+		Secret:      secret,
+		Events:      strings.Join(events, ","),
+		Description: strings.TrimSpace(req.Description),
+		IsActive:    true,
+	}
+	if err := s.repo.Create(webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	response := toWebhookResponse(webhook)
+	response.Secret = secret
+	return response, nil
+}
+
+func (s *service) ListWebhooks() ([]WebhookResponse, error) {
+	webhooks, err := s.repo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	responses := make([]WebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = *toWebhookResponse(&webhooks[i])
+	}
+	return responses, nil
+}
+
+func (s *service) DeleteWebhook(id uuid.UUID) error {
+	deleted, err := s.repo.Delete(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s *service) ListDeliveries(webhookID *uuid.UUID, status DeliveryStatus, page, limit int) (*DeliveryListResponse, error) {
+	deliveries, total, err := s.repo.ListDeliveries(webhookID, status, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	return &DeliveryListResponse{Deliveries: deliveries, Total: total, Page: page, Limit: limit}, nil
+}
+
+func (s *service) Dispatch(event EventType, data interface{}) {
+	webhooks, err := s.repo.ListActive()
+	if err != nil {
+		log.Printf("Failed to load webhooks for %s: %v", event, err)
+		return
+	}
+
+	var body []byte
+	for i := range webhooks {
+		webhook := webhooks[i]
+		if !webhook.Subscribes(event) {
+			continue
+		}
+		if body == nil {
+			body, err = json.Marshal(EventPayload{ID: uuid.NewString(), Event: event, CreatedAt: time.Now().UTC(), Data: data})
+			if err != nil {
+				log.Printf("Failed to encode %s webhook payload: %v", event, err)
+				return
+			}
+		}
+
+		delivery := WebhookDelivery{WebhookID: webhook.ID, Event: event, Payload: string(body), Status: DeliveryPending}
+		if err := s.repo.CreateDelivery(&delivery); err != nil {
+			log.Printf("Failed to log %s delivery for webhook %s: %v", event, webhook.ID, err)
+			continue
+		}
+		delivery.Webhook = webhook
+		go s.attempt(delivery)
+	}
+}
+
+// SetRetryPolicy overrides the attempt limit and the first retry delay, which doubles on every failure
+func (s *service) SetRetryPolicy(maxAttempts int, baseBackoff time.Duration) {
+	if maxAttempts > 0 {
+		s.maxAttempts = maxAttempts
+	}
+	if baseBackoff > 0 {
+		s.backoff = baseBackoff
+	}
+}
+
+func (s *service) RetryFailed(limit int) (int, error) {
+	due, err := s.repo.GetDueForRetry(time.Now(), staleRetryClaim, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load webhook deliveries due for retry: %w", err)
+	}
+
+	retried := 0
+	for _, delivery := range due {
+		claimed, err := s.repo.ClaimForRetry(delivery.ID)
+		if err != nil {
+			log.Printf("Failed to claim webhook delivery %d for retry: %v", delivery.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.attempt(delivery)
+		retried++
+	}
+	return retried, nil
+}
+
+// attempt POSTs the delivery once and records the outcome, scheduling a retry or dead-lettering on failure
+func (s *service) attempt(delivery WebhookDelivery) {
+	responseStatus, err := s.post(delivery)
+	attempts := delivery.Attempts + 1
+
+	var status DeliveryStatus
+	var lastError string
+	var nextAttemptAt *time.Time
+	switch {
+	case err == nil:
+		status = DeliveryDelivered
+	case !delivery.Webhook.IsActive || attempts >= s.maxAttempts:
+		status = DeliveryDeadLetter
+		lastError = err.Error()
+		log.Printf("Webhook delivery %d dead-lettered after %d attempts: %v", delivery.ID, attempts, err)
+	default:
+		status = DeliveryFailed
+		lastError = err.Error()
+		next := time.Now().Add(s.retryDelay(attempts))
+		nextAttemptAt = &next
+	}
+
+	if recErr := s.repo.RecordAttempt(delivery.ID, status, attempts, responseStatus, lastError, nextAttemptAt); recErr != nil {
+		log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, recErr)
+	}
+}
+
+func (s *service) post(delivery WebhookDelivery) (int, error) {
+	if !delivery.Webhook.IsActive {
+		return 0, errors.New("webhook is disabled")
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.Event))
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *service) retryDelay(attempts int) time.Duration {
+	d := s.backoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func toWebhookResponse(w *Webhook) *WebhookResponse {
+	events := w.EventList()
+	if events == nil {
+		events = Events
+	}
+	return &WebhookResponse{
+		ID:          w.ID,
+		URL:         w.URL,
+		Events:      events,
+		Description: w.Description,
+		IsActive:    w.IsActive,
+		CreatedAt:   w.CreatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package webhooks_test
+
+import (
+	"testing"
+
+	webhooks "errandShop/internal/domain/webhooks"
+)
+
+func TestSign(t *testing.T) {
+	got := webhooks.Sign("secret", "1700000000", []byte(`{"event":"order.created"}`))
+	want := "sha256=c492664ac93f4257c521b1eb151b4122c684a352aadd33c5d8e6191db424d254"
+	if got != want {
+		t.Fatalf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestSubscribes(t *testing.T) {
+	all := webhooks.Webhook{}
+	if !all.Subscribes(webhooks.EventOrderCancelled) {
+		t.Fatal("webhook with no events should receive every event")
+	}
+
+	some := webhooks.Webhook{Events: "order.created, order.paid"}
+	if !some.Subscribes(webhooks.EventOrderPaid) {
+		t.Fatal("webhook should receive order.paid")
+	}
+	if some.Subscribes(webhooks.EventOrderDelivered) {
+		t.Fatal("webhook should not receive order.delivered")
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"errandShop/internal/core/match"
+)
+
+func TestShippedDeliveryZonesPriceMapPins(t *testing.T) {
+	zones, err := loadDeliveryZones("../../../data/delivery_zones.json")
+	if err != nil {
+		t.Fatalf("failed to load delivery zones: %v", err)
+	}
+	m := match.NewMatcher(zones)
+
+	for _, zone := range zones {
+		if len(zone.Areas) == 0 {
+			t.Errorf("zone %d has no map areas, so dropped pins can never be priced there", zone.ZoneID)
+		}
+		for _, area := range zone.Areas {
+			known := false
+			for _, location := range zone.Locations {
+				if strings.HasPrefix(location, area.Name) {
+					known = true
+				}
+			}
+			if !known {
+				t.Errorf("zone %d area %q is not one of its locations %v", zone.ZoneID, area.Name, zone.Locations)
+			}
+
+			// Overlaps resolve to the nearest centre, so an area's own centre must price in its zone
+			got, noMatch := m.MatchCoordinates(area.Latitude, area.Longitude)
+			if got == nil {
+				t.Errorf("pin at the centre of %q matched no zone: %+v", area.Name, noMatch)
+				continue
+			}
+			if got.ZoneID != zone.ZoneID || got.Price != zone.Price || got.MatchedBy != "coordinates" {
+				t.Errorf("pin at the centre of %q = zone %d (%d, %s), want zone %d (%d)",
+					area.Name, got.ZoneID, got.Price, got.MatchedBy, zone.ZoneID, zone.Price)
+			}
+		}
+	}
+
+	// Kaduna is well outside every Abuja area
+	if got, noMatch := m.MatchCoordinates(10.5105, 7.4165); got != nil || noMatch == nil {
+		t.Fatalf("pin outside the service area matched %+v", got)
+	}
+}
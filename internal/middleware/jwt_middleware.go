@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errandShop/config"
 	"errandShop/internal/pkg/jwt"
 	"errandShop/internal/presenter"
@@ -8,8 +9,23 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// ImpersonationTracker checks impersonation sessions and audits the requests made in them
+type ImpersonationTracker interface {
+	ImpersonationActive(ctx context.Context, sessionID uuid.UUID) bool
+	LogImpersonatedRequest(ctx context.Context, sessionID, adminID, userID uuid.UUID, method, path, ipAddress, userAgent string)
+}
+
+var impersonationTracker ImpersonationTracker
+
+// SetImpersonationTracker enables impersonation tokens. Without a tracker they are rejected,
+// since their requests could be neither audited nor ended early.
+func SetImpersonationTracker(tracker ImpersonationTracker) {
+	impersonationTracker = tracker
+}
+
 // JWTMiddleware validates JWT tokens
 func JWTMiddleware(cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -22,6 +38,9 @@ func JWTMiddleware(cfg *config.Config) fiber.Handler {
 		if err != nil {
 			return presenter.Err(c, fiber.StatusUnauthorized, "Invalid token")
 		}
+		if !trackImpersonation(c, claims) {
+			return presenter.Err(c, fiber.StatusUnauthorized, "Impersonation session has ended")
+		}
 
 		// Set user info in context
 		c.Locals("userID", claims.UserID)
@@ -39,7 +58,7 @@ func OptionalJWTMiddleware(cfg *config.Config) fiber.Handler {
 		token := extractToken(c)
 		if token != "" {
 			claims, err := validateToken(token, cfg.JWTSecret)
-			if err == nil {
+			if err == nil && trackImpersonation(c, claims) {
 				c.Locals("userID", claims.UserID)
 				c.Locals("email", claims.Email)
 				c.Locals("role", claims.Role)
@@ -95,6 +114,40 @@ func SuperAdminMiddleware() fiber.Handler {
 	}
 }
 
+// NoImpersonation rejects requests made with an impersonation token. Support staff acting as a
+// user must not change their credentials or sessions, or move their money.
+func NoImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("impersonatedBy").(uuid.UUID); ok {
+			return presenter.Err(c, fiber.StatusForbidden, "This action is not allowed while impersonating a user")
+		}
+		return c.Next()
+	}
+}
+
+// trackImpersonation lets ordinary tokens through. For an impersonation token it checks the
+// session is still active, audits the request and sets the impersonatedBy and
+// impersonationSessionID locals; it returns false when the token must be rejected.
+func trackImpersonation(c *fiber.Ctx, claims *jwt.JWTClaims) bool {
+	if claims.ImpersonatedBy == nil {
+		return true
+	}
+	sessionID, err := uuid.Parse(claims.ID)
+	if err != nil || impersonationTracker == nil {
+		return false
+	}
+	ctx := context.Background()
+	if !impersonationTracker.ImpersonationActive(ctx, sessionID) {
+		return false
+	}
+	impersonationTracker.LogImpersonatedRequest(ctx, sessionID, *claims.ImpersonatedBy, claims.UserID,
+		c.Method(), c.Path(), c.IP(), c.Get(fiber.HeaderUserAgent))
+
+	c.Locals("impersonatedBy", *claims.ImpersonatedBy)
+	c.Locals("impersonationSessionID", sessionID)
+	return true
+}
+
 func extractToken(c *fiber.Ctx) string {
 	// Try Authorization header first
 	auth := c.Get("Authorization")
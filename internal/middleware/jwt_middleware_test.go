@@ -0,0 +1,92 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"errandShop/config"
+	"errandShop/internal/domain/auth"
+	"errandShop/internal/middleware"
+)
+
+type fakeTracker struct {
+	active   map[uuid.UUID]bool
+	requests []string
+}
+
+func (f *fakeTracker) ImpersonationActive(ctx context.Context, sessionID uuid.UUID) bool {
+	return f.active[sessionID]
+}
+
+func (f *fakeTracker) LogImpersonatedRequest(ctx context.Context, sessionID, adminID, userID uuid.UUID, method, path, ipAddress, userAgent string) {
+	f.requests = append(f.requests, method+" "+path)
+}
+
+func TestImpersonationTokens(t *testing.T) {
+	const secret = "test-secret"
+	tracker := &fakeTracker{active: map[uuid.UUID]bool{}}
+	middleware.SetImpersonationTracker(tracker)
+	defer middleware.SetImpersonationTracker(nil)
+
+	app := fiber.New()
+	protected := app.Group("", middleware.JWTMiddleware(&config.Config{JWTSecret: secret}))
+	protected.Get("/me", func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("impersonatedBy").(uuid.UUID); !ok {
+			return c.SendString("self")
+		}
+		return c.SendString("impersonated")
+	})
+	protected.Post("/password/change", middleware.NoImpersonation(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	jwtService := auth.NewJWTService(secret)
+	user := &auth.User{ID: uuid.New(), Email: "ada@example.com", Role: "customer"}
+	sessionID := uuid.New()
+	impersonation, err := jwtService.GenerateImpersonationToken(user, uuid.New(), sessionID, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to generate impersonation token: %v", err)
+	}
+	own, err := jwtService.GenerateToken(user)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	call := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := call("GET", "/me", impersonation); status != fiber.StatusUnauthorized {
+		t.Fatalf("unknown session: expected 401, got %d", status)
+	}
+
+	tracker.active[sessionID] = true
+	if status := call("GET", "/me", impersonation); status != fiber.StatusOK {
+		t.Fatalf("active session: expected 200, got %d", status)
+	}
+	if status := call("POST", "/password/change", impersonation); status != fiber.StatusForbidden {
+		t.Fatalf("sensitive action: expected 403, got %d", status)
+	}
+	if status := call("POST", "/password/change", own); status != fiber.StatusNoContent {
+		t.Fatalf("own token: expected 204, got %d", status)
+	}
+	if len(tracker.requests) != 2 {
+		t.Fatalf("expected 2 audited requests, got %v", tracker.requests)
+	}
+
+	tracker.active[sessionID] = false
+	if status := call("GET", "/me", impersonation); status != fiber.StatusUnauthorized {
+		t.Fatalf("ended session: expected 401, got %d", status)
+	}
+}
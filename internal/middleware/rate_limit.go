@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ type RateLimiter struct {
 	mu      sync.RWMutex
 	limit   int
 	window  time.Duration
+	keyFunc func(c *fiber.Ctx) string
 }
 
 type ClientInfo struct {
@@ -24,6 +26,7 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 		clients: make(map[string]*ClientInfo),
 		limit:   limit,
 		window:  window,
+		keyFunc: func(c *fiber.Ctx) string { return c.IP() },
 	}
 
 	// Cleanup goroutine
@@ -39,9 +42,9 @@ func (rl *RateLimiter) cleanup() {
 	for range ticker.C {
 		rl.mu.Lock()
 		now := time.Now()
-		for ip, client := range rl.clients {
+		for key, client := range rl.clients {
 			if now.Sub(client.lastReset) > rl.window {
-				delete(rl.clients, ip)
+				delete(rl.clients, key)
 			}
 		}
 		rl.mu.Unlock()
@@ -50,17 +53,17 @@ func (rl *RateLimiter) cleanup() {
 
 func (rl *RateLimiter) Middleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ip := c.IP()
+		key := rl.keyFunc(c)
 		now := time.Now()
 
 		rl.mu.Lock()
-		client, exists := rl.clients[ip]
+		client, exists := rl.clients[key]
 		if !exists {
 			client = &ClientInfo{
 				requests:  0,
 				lastReset: now,
 			}
-			rl.clients[ip] = client
+			rl.clients[key] = client
 		}
 
 		// Reset if window has passed
@@ -85,6 +88,22 @@ func (rl *RateLimiter) Middleware() fiber.Handler {
 	}
 }
 
+// NewUserRateLimiter limits requests per authenticated user (the JWT subject set by
+// JWTMiddleware) rather than per IP, so users behind a shared NAT don't throttle each
+// other. Requests without a user fall back to the client IP. Mount after JWTMiddleware.
+func NewUserRateLimiter(limit int, window time.Duration) *RateLimiter {
+	rl := NewRateLimiter(limit, window)
+	rl.keyFunc = func(c *fiber.Ctx) string {
+		if userID := c.Locals("userID"); userID != nil {
+			if key := fmt.Sprint(userID); key != "" {
+				return "user:" + key
+			}
+		}
+		return "ip:" + c.IP()
+	}
+	return rl
+}
+
 // Predefined rate limiters
 func AuthRateLimit() fiber.Handler {
 	return NewRateLimiter(5, time.Minute).Middleware() // 5 requests per minute for auth
@@ -97,3 +116,8 @@ func APIRateLimit() fiber.Handler {
 func StrictRateLimit() fiber.Handler {
 	return NewRateLimiter(3, time.Minute).Middleware() // 3 requests per minute for sensitive operations
 }
+
+// UserRateLimit limits each authenticated user to limit requests per window
+func UserRateLimit(limit int, window time.Duration) fiber.Handler {
+	return NewUserRateLimiter(limit, window).Middleware()
+}
@@ -16,4 +16,7 @@ type JWTClaims struct {
 	Permissions []string  `json:"permissions"`
 	Iat         int64     `json:"iat"` // Issued at
 	Exp         int64     `json:"exp"` // Expires at
+	// ImpersonatedBy is the superadmin acting as this user; set only on impersonation tokens,
+	// whose RegisteredClaims.ID is the impersonation session
+	ImpersonatedBy *uuid.UUID `json:"impersonatedBy,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationSession records a superadmin signing in as a user for support. Its ID is the
+// impersonation token's jti, so ending the session stops the token working before it expires.
+type ImpersonationSession struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	AdminID   uuid.UUID  `json:"admin_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Reason    string     `json:"reason" gorm:"type:text;not null"`
+	IPAddress string     `json:"ip_address" gorm:"size:45"`
+	UserAgent string     `json:"user_agent" gorm:"size:500"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	EndedAt   *time.Time `json:"ended_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"crypto/rand"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,8 +23,12 @@ type User struct {
 	Permissions []string       `json:"permissions" gorm:"type:text[]"`
 	Status      string         `json:"status" gorm:"default:'active'"`
 	IsVerified  bool           `json:"is_verified" gorm:"default:false"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"` // set once the user confirms a code sent to Phone; cleared when Phone changes
 	ForceReset  bool           `json:"force_reset" gorm:"default:false"`
 	LastLoginAt *time.Time     `json:"last_login_at"`
+	ReferralCode string        `json:"referral_code" gorm:"type:varchar(16)"` // shared with friends; unique, generated on create
+	ReferredByID *uuid.UUID    `json:"referred_by_id" gorm:"type:uuid"`
+	ReferralCreditedAt *time.Time `json:"-"` // set once both parties got their referral coupon
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -33,8 +38,15 @@ type User struct {
 	OTPs          []OTP         `json:"-" gorm:"foreignKey:UserID"`
 }
 
-// BeforeCreate hook to hash password
+// BeforeCreate hook to hash password and assign a referral code
 func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.ReferralCode == "" {
+		code, err := NewReferralCode()
+		if err != nil {
+			return err
+		}
+		u.ReferralCode = code
+	}
 	if u.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 		if err != nil {
@@ -45,6 +57,21 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// referralAlphabet leaves out 0/O and 1/I so codes can be read out loud
+const referralAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NewReferralCode returns a random 8-character referral code
+func NewReferralCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = referralAlphabet[int(b)%len(referralAlphabet)]
+	}
+	return string(buf), nil
+}
+
 // CheckPassword verifies the password
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
@@ -85,6 +112,12 @@ type RefreshToken struct {
 	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
 	Token     string         `json:"token" gorm:"unique;not null"`
 	ExpiresAt time.Time      `json:"expires_at" gorm:"not null"`
+
+	// Session metadata captured when the token is issued; rotation keeps the device and start time
+	DeviceName string `json:"device_name" gorm:"size:100"`
+	UserAgent  string `json:"user_agent" gorm:"size:500"`
+	IPAddress  string `json:"ip_address" gorm:"size:45"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
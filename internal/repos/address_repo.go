@@ -7,6 +7,13 @@ import (
 	"errandShop/internal/core/types"
 )
 
+var (
+	// ErrAddressNotFound is returned when no address has the requested ID
+	ErrAddressNotFound = errors.New("address not found")
+	// ErrAddressNotOwned is returned when the address exists but belongs to another user
+	ErrAddressNotOwned = errors.New("address belongs to another user")
+)
+
 // AddressRepo defines the interface for address repository
 type AddressRepo interface {
 	GetByID(userID, addressID string) (*types.Address, error)
@@ -63,12 +70,12 @@ func (r *InMemoryAddressRepo) GetByID(userID, addressID string) (*types.Address,
 	
 	addr, exists := r.addresses[addressID]
 	if !exists {
-		return nil, errors.New("address not found")
+		return nil, ErrAddressNotFound
 	}
 	
 	// Ensure the address belongs to the user
 	if addr.UserID != userID {
-		return nil, errors.New("address not found")
+		return nil, ErrAddressNotOwned
 	}
 	
 	return addr, nil
@@ -34,15 +34,18 @@ func (r *DBAddressRepo) GetByID(userID, addressID string) (*types.Address, error
 		return nil, fmt.Errorf("invalid user ID: %v", err)
 	}
 
-	// Query the database
+	// Query the database, then check ownership so callers can tell a foreign address from a missing one
 	var dbAddress customers.Address
-	err = r.db.Where("id = ? AND user_id = ?", uint(id), userUUID).First(&dbAddress).Error
+	err = r.db.Where("id = ?", uint(id)).First(&dbAddress).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("address not found")
+			return nil, ErrAddressNotFound
 		}
 		return nil, fmt.Errorf("database error: %v", err)
 	}
+	if dbAddress.UserID != userUUID {
+		return nil, ErrAddressNotOwned
+	}
 
 	// Convert to types.Address format
 	typesAddress := &types.Address{
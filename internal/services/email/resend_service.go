@@ -3,6 +3,8 @@ package email
 import (
 	"context"
 	"fmt"
+	"html"
+	"strings"
 
 	"github.com/resend/resend-go/v2"
 )
@@ -92,3 +94,30 @@ func (r *ResendService) SendWelcomeEmail(ctx context.Context, to, name string) e
 	_, err := r.client.Emails.Send(params)
 	return err
 }
+
+// SendDigestEmail sends a daily roll-up of low-priority notifications, one list item per line
+func (r *ResendService) SendDigestEmail(ctx context.Context, to, subject string, lines []string) error {
+	var items strings.Builder
+	for _, line := range lines {
+		fmt.Fprintf(&items, "<li>%s</li>", html.EscapeString(line))
+	}
+	htmlContent := fmt.Sprintf(`
+		<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto;">
+			<h2 style="color: #333;">%s</h2>
+			<p>Here's what you missed today:</p>
+			<ul>%s</ul>
+			<p>You can turn off the daily digest in your notification settings.</p>
+			<p>The Errand Shop Team</p>
+		</div>
+	`, html.EscapeString(subject), items.String())
+
+	params := &resend.SendEmailRequest{
+		From:    r.fromEmail,
+		To:      []string{to},
+		Subject: subject + " - Errand Shop",
+		Html:    htmlContent,
+	}
+
+	_, err := r.client.Emails.Send(params)
+	return err
+}
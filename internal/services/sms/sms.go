@@ -0,0 +1,54 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SMSSender delivers a plain text message to a phone number
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, message string) error
+}
+
+// Config holds the credentials for every supported provider; only the selected one is used
+type Config struct {
+	TermiiAPIKey     string
+	TermiiSenderID   string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFromPhone  string
+}
+
+// NewSender builds the sender for provider ("termii" or "twilio"). An empty provider disables
+// SMS and returns a nil sender.
+func NewSender(provider string, cfg Config) (SMSSender, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "":
+		return nil, nil
+	case "termii":
+		if cfg.TermiiAPIKey == "" || cfg.TermiiSenderID == "" {
+			return nil, fmt.Errorf("termii requires SMS_API_KEY and SMS_SENDER_ID")
+		}
+		return NewTermiiSender(cfg.TermiiAPIKey, cfg.TermiiSenderID), nil
+	case "twilio":
+		if cfg.TwilioAccountSID == "" || cfg.TwilioAuthToken == "" || cfg.TwilioFromPhone == "" {
+			return nil, fmt.Errorf("twilio requires TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_PHONE")
+		}
+		return NewTwilioSender(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFromPhone), nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider: %q", provider)
+	}
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// checkResponse turns a non-2xx provider response into an error
+func checkResponse(provider string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("%s: unexpected status %d", provider, resp.StatusCode)
+}
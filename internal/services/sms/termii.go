@@ -0,0 +1,47 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+const termiiSendURL = "https://api.ng.termii.com/api/sms/send"
+
+// TermiiSender sends SMS through Termii's generic (non-DND) route
+type TermiiSender struct {
+	apiKey   string
+	senderID string
+}
+
+func NewTermiiSender(apiKey, senderID string) *TermiiSender {
+	return &TermiiSender{apiKey: apiKey, senderID: senderID}
+}
+
+func (t *TermiiSender) SendSMS(ctx context.Context, to, message string) error {
+	payload, err := json.Marshal(map[string]string{
+		"api_key": t.apiKey,
+		"to":      to,
+		"from":    t.senderID,
+		"sms":     message,
+		"type":    "plain",
+		"channel": "generic",
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, termiiSendURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse("termii", resp)
+}
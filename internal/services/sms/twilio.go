@@ -0,0 +1,43 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TwilioSender sends SMS through Twilio's Messages API
+type TwilioSender struct {
+	accountSID string
+	authToken  string
+	fromPhone  string
+}
+
+func NewTwilioSender(accountSID, authToken, fromPhone string) *TwilioSender {
+	return &TwilioSender{accountSID: accountSID, authToken: authToken, fromPhone: fromPhone}
+}
+
+func (t *TwilioSender) SendSMS(ctx context.Context, to, message string) error {
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", t.accountSID)
+	form := url.Values{
+		"To":   {to},
+		"From": {t.fromPhone},
+		"Body": {message},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.accountSID, t.authToken)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse("twilio", resp)
+}
@@ -0,0 +1,263 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCloudinaryNotConfigured is returned when no Cloudinary credentials were provided
+var ErrCloudinaryNotConfigured = errors.New("cloudinary is not configured")
+
+// CloudinaryService uploads and deletes images through Cloudinary's signed REST API
+type CloudinaryService struct {
+	cloudName string
+	apiKey    string
+	apiSecret string
+	folder    string
+	baseURL   string
+	client    *http.Client
+	logger    *log.Logger
+}
+
+// CloudinaryUploadResult is the subset of Cloudinary's upload response we keep
+type CloudinaryUploadResult struct {
+	PublicID  string `json:"public_id"`
+	SecureURL string `json:"secure_url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Bytes     int64  `json:"bytes"`
+	Format    string `json:"format"`
+}
+
+type cloudinaryErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewCloudinaryService creates a Cloudinary client; uploads go into folder (if set)
+func NewCloudinaryService(cloudName, apiKey, apiSecret, folder string) *CloudinaryService {
+	return &CloudinaryService{
+		cloudName: cloudName,
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		folder:    strings.Trim(folder, "/"),
+		baseURL:   "https://api.cloudinary.com/v1_1",
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		logger: log.New(log.Writer(), "[CLOUDINARY] ", log.LstdFlags|log.Lshortfile),
+	}
+}
+
+// Configured reports whether all credentials needed for signed requests are present
+func (s *CloudinaryService) Configured() bool {
+	return s != nil && s.cloudName != "" && s.apiKey != "" && s.apiSecret != ""
+}
+
+// UploadProductImage validates and uploads a product image
+func (s *CloudinaryService) UploadProductImage(file *multipart.FileHeader) (*CloudinaryUploadResult, error) {
+	if !s.Configured() {
+		return nil, ErrCloudinaryNotConfigured
+	}
+	if err := validateImageFile(file); err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	params := map[string]string{
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	if s.folder != "" {
+		params["folder"] = s.folder
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for k, v := range params {
+		if err := writer.WriteField(k, v); err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+	}
+	if err := writer.WriteField("api_key", s.apiKey); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := writer.WriteField("signature", s.sign(params)); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", file.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if _, err := io.Copy(part, src); err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.endpoint("upload"), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var result CloudinaryUploadResult
+	if err := s.do(req, &result); err != nil {
+		s.logger.Printf("Upload of %s failed: %v", file.Filename, err)
+		return nil, err
+	}
+
+	s.logger.Printf("Uploaded image %s as %s (%d bytes)", file.Filename, result.PublicID, result.Bytes)
+	return &result, nil
+}
+
+// DeleteImage removes an asset by public ID. A missing asset is treated as already deleted.
+func (s *CloudinaryService) DeleteImage(publicID string) error {
+	if !s.Configured() {
+		return ErrCloudinaryNotConfigured
+	}
+	if publicID == "" {
+		return errors.New("public ID is required")
+	}
+
+	params := map[string]string{
+		"public_id": publicID,
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	form := &bytes.Buffer{}
+	writer := multipart.NewWriter(form)
+	for k, v := range params {
+		writer.WriteField(k, v)
+	}
+	writer.WriteField("api_key", s.apiKey)
+	writer.WriteField("signature", s.sign(params))
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.endpoint("destroy"), form)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var result struct {
+		Result string `json:"result"`
+	}
+	if err := s.do(req, &result); err != nil {
+		return err
+	}
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("cloudinary destroy returned %q", result.Result)
+	}
+
+	s.logger.Printf("Deleted image %s (%s)", publicID, result.Result)
+	return nil
+}
+
+// PublicIDFromURL returns the public ID of an image delivered from this account, e.g.
+// https://res.cloudinary.com/<cloud>/image/upload/v1712/proofs/abc.jpg gives "proofs/abc". It
+// reports false for URLs hosted anywhere else.
+func (s *CloudinaryService) PublicIDFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || s == nil || u.Host != "res.cloudinary.com" {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] != s.cloudName || parts[1] != "image" || parts[2] != "upload" {
+		return "", false
+	}
+	rest := parts[3:]
+	// Transformations come before the version; without a version the whole rest is the ID
+	for i, part := range rest {
+		if len(part) > 1 && part[0] == 'v' && isDigits(part[1:]) {
+			rest = rest[i+1:]
+			break
+		}
+	}
+	if len(rest) == 0 {
+		return "", false
+	}
+	last := rest[len(rest)-1]
+	if dot := strings.LastIndex(last, "."); dot > 0 {
+		rest[len(rest)-1] = last[:dot]
+	}
+	return strings.Join(rest, "/"), true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *CloudinaryService) endpoint(action string) string {
+	return fmt.Sprintf("%s/%s/image/%s", s.baseURL, s.cloudName, action)
+}
+
+// sign builds the request signature: params sorted by key, joined as k=v&..., suffixed
+// with the API secret and SHA-1 hashed (api_key, file and signature are never signed).
+func (s *CloudinaryService) sign(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+params[k])
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(pairs, "&") + s.apiSecret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *CloudinaryService) do(req *http.Request, out interface{}) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp cloudinaryErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != "" {
+			return fmt.Errorf("cloudinary error: %s", errResp.Error.Message)
+		}
+		return fmt.Errorf("cloudinary error: status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -43,7 +43,7 @@ func (s *ImageService) UploadProductImage(file *multipart.FileHeader) (*UploadRe
 	s.logger.Printf("Uploading product image: %s", file.Filename)
 
 	// Validate file
-	if err := s.validateImageFile(file); err != nil {
+	if err := validateImageFile(file); err != nil {
 		s.logger.Printf("Image validation failed: %v", err)
 		return nil, err
 	}
@@ -118,7 +118,8 @@ func (s *ImageService) DeleteProductImage(filename string) error {
 	return nil
 }
 
-func (s *ImageService) validateImageFile(file *multipart.FileHeader) error {
+// validateImageFile enforces the size and extension limits shared by all image uploads
+func validateImageFile(file *multipart.FileHeader) error {
 	// Check file size (max 5MB)
 	const maxSize = 5 * 1024 * 1024 // 5MB
 	if file.Size > maxSize {
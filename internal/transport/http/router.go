@@ -10,12 +10,13 @@ import (
 )
 
 type Deps struct {
-	Auth       *auth.Handler
-	Products   *products.Handler
-	JWT        fiber.Handler
-	AdminOnly  fiber.Handler
-	CustOnly   fiber.Handler
-	SuperAdmin fiber.Handler
+	Auth        *auth.Handler
+	Products    *products.Handler
+	JWT         fiber.Handler
+	OptionalJWT fiber.Handler
+	AdminOnly   fiber.Handler
+	CustOnly    fiber.Handler
+	SuperAdmin  fiber.Handler
 }
 
 func Build(app *fiber.App, d *Deps) {
@@ -30,9 +31,12 @@ func Build(app *fiber.App, d *Deps) {
 	ag.Post("/refresh", d.Auth.RefreshToken)
 	ag.Post("/logout", d.JWT, d.Auth.Logout)
 	ag.Get("/me", d.JWT, d.Auth.Me)
+	ag.Get("/me/full", d.JWT, d.Auth.MeFull)
+	ag.Get("/sessions", d.JWT, d.Auth.ListSessions)
+	ag.Delete("/sessions/:id", d.JWT, d.Auth.RevokeSession)
 
 	// Public routes
-	v1.MountProductRoutes(v, d.Products)
+	v1.MountProductRoutes(v, d.Products, d.OptionalJWT)
 
 	// Admin group
 	admin := v.Group("/admin", d.JWT, d.AdminOnly)
@@ -47,11 +51,12 @@ func Build(app *fiber.App, d *Deps) {
 
 func DefaultDeps(authH *auth.Handler, prodH *products.Handler) *Deps {
 	return &Deps{
-		Auth:       authH,
-		Products:   prodH,
-		JWT:        middleware.JWTMiddleware(nil), // TODO: Pass proper config
-		AdminOnly:  middleware.AdminOnly(),
-		CustOnly:   middleware.CustomerOnly(),
-		SuperAdmin: middleware.SuperAdminMiddleware(),
+		Auth:        authH,
+		Products:    prodH,
+		JWT:         middleware.JWTMiddleware(nil), // TODO: Pass proper config
+		OptionalJWT: middleware.OptionalJWTMiddleware(nil),
+		AdminOnly:   middleware.AdminOnly(),
+		CustOnly:    middleware.CustomerOnly(),
+		SuperAdmin:  middleware.SuperAdminMiddleware(),
 	}
 }
@@ -31,7 +31,12 @@ func MountCustomRequestRoutes(router fiber.Router, handler *custom_requests.Hand
 	{
 		// Custom request management
 		adminRoutes.Get("/", handler.ListCustomRequestsAdmin)
+		adminRoutes.Get("/queue", handler.GetAdminQueue)
+		adminRoutes.Get("/assignees", handler.ListAssignees)
+		adminRoutes.Put("/assignees/:admin_id", handler.SetAssigneeAvailability)
+		adminRoutes.Delete("/assignees/:admin_id", handler.RemoveAssignee)
 		adminRoutes.Get("/:id", handler.GetCustomRequestAdmin)
+		adminRoutes.Get("/:id/suggestions", handler.GetCatalogSuggestions)
 		adminRoutes.Put("/:id/status", handler.UpdateCustomRequestStatus)
 		adminRoutes.Put("/:id/assign/:assignee_id", handler.AssignCustomRequest)
 
@@ -6,12 +6,20 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// Public product routes
-func MountProductRoutes(r fiber.Router, h *products.Handler) {
+// Public product routes. optionalAuth identifies signed-in customers on product detail so the
+// response can say whether the product is on their wishlist.
+func MountProductRoutes(r fiber.Router, h *products.Handler, optionalAuth fiber.Handler) {
 	r.Get("/products", h.List)
 	r.Get("/products/categories", h.GetCategories)
+	r.Get("/products/categories/tree", h.GetCategoryTree)
+	r.Get("/products/tags", h.GetTags)
+	r.Get("/products/featured", h.Featured)
+	r.Get("/products/bestsellers", h.Bestsellers)
+	r.Get("/products/barcode/:code", h.GetByBarcode)
 	r.Get("/categories", h.GetCategories) // Direct categories endpoint for frontend compatibility
-	r.Get("/products/:id", h.Get)
+	r.Get("/categories/tree", h.GetCategoryTree)
+	r.Get("/products/:id", optionalAuth, h.Get)
+	r.Get("/products/:id/related", h.Related)
 }
 
 // Admin product routes
@@ -26,11 +34,18 @@ func MountAdminProductRoutes(r fiber.Router, h *products.Handler) {
 	// Stock management (before parameterized routes)
 	r.Post("/products/stock/bulk-update", h.BulkUpdateStock)
 	r.Get("/products/low-stock", h.GetLowStock)
+	r.Get("/products/stock/shrinkage", h.GetStockShrinkage)
 
 	// Parameterized routes (must come last)
 	r.Get("/products/:id", h.Get)
 	r.Put("/products/:id", h.Update)
 	r.Delete("/products/:id", h.Delete)
+	r.Post("/products/:id/image", h.UploadImage)
+	r.Post("/products/:id/images", h.AddImage)
+	r.Put("/products/:id/images/order", h.ReorderImages)
+	r.Put("/products/:id/images/:imageId/primary", h.SetPrimaryImage)
+	r.Delete("/products/:id/images/:imageId", h.DeleteImage)
+	r.Get("/products/:id/price-history", h.GetPriceHistory)
 }
 
 // Superadmin-only category CRUD routes (mount under an admin group with SuperAdmin middleware)
@@ -0,0 +1,63 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Money is an amount in kobo. Order, cart and coupon amounts are stored in kobo,
+// while catalog prices are still naira floats; convert at the boundary with
+// FromNaira and Naira instead of multiplying or dividing by 100 by hand.
+type Money int64
+
+// FromNaira converts a naira amount to kobo, rounding to the nearest kobo so
+// prices like 19.99 don't truncate to 1998.
+func FromNaira(naira float64) Money {
+	return Money(math.Round(naira * 100))
+}
+
+// Kobo returns the amount in kobo
+func (m Money) Kobo() int64 {
+	return int64(m)
+}
+
+// Naira returns the amount in naira
+func (m Money) Naira() float64 {
+	return float64(m) / 100
+}
+
+// Times multiplies the amount by a quantity
+func (m Money) Times(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// String formats the amount for messages, e.g. "₦2000.00"
+func (m Money) String() string {
+	return fmt.Sprintf("₦%.2f", m.Naira())
+}
+
+type moneyJSON struct {
+	Kobo  int64   `json:"kobo"`
+	Naira float64 `json:"naira"`
+}
+
+// MarshalJSON emits both units so clients never have to guess which one a field is in
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Kobo: m.Kobo(), Naira: m.Naira()})
+}
+
+// UnmarshalJSON accepts the object form written by MarshalJSON or a bare kobo integer
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var kobo int64
+	if err := json.Unmarshal(data, &kobo); err == nil {
+		*m = Money(kobo)
+		return nil
+	}
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("money: expected kobo integer or {\"kobo\",\"naira\"} object: %w", err)
+	}
+	*m = Money(v.Kobo)
+	return nil
+}
@@ -0,0 +1,63 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"errandShop/pkg/money"
+)
+
+func TestFromNairaRounds(t *testing.T) {
+	tests := []struct {
+		naira float64
+		want  int64
+	}{
+		{19.99, 1999},
+		{0.29, 29},
+		{2000, 200000},
+		{1234.565, 123457},
+	}
+	for _, tt := range tests {
+		if got := money.FromNaira(tt.naira).Kobo(); got != tt.want {
+			t.Errorf("FromNaira(%v) = %d kobo, want %d", tt.naira, got, tt.want)
+		}
+	}
+}
+
+func TestNaira(t *testing.T) {
+	if got := money.Money(199999).Naira(); got != 1999.99 {
+		t.Fatalf("Naira() = %v, want 1999.99", got)
+	}
+	if got := money.Money(50000).String(); got != "₦500.00" {
+		t.Fatalf("String() = %q, want ₦500.00", got)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(money.Money(150050))
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if want := `{"kobo":150050,"naira":1500.5}`; string(data) != want {
+		t.Fatalf("Marshal = %s, want %s", data, want)
+	}
+
+	var m money.Money
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Unmarshal object returned error: %v", err)
+	}
+	if m != 150050 {
+		t.Fatalf("Unmarshal object = %d, want 150050", m)
+	}
+
+	if err := json.Unmarshal([]byte("2500"), &m); err != nil {
+		t.Fatalf("Unmarshal kobo returned error: %v", err)
+	}
+	if m != 2500 {
+		t.Fatalf("Unmarshal kobo = %d, want 2500", m)
+	}
+
+	if err := json.Unmarshal([]byte(`"abc"`), &m); err == nil {
+		t.Fatal("Unmarshal of a string should fail")
+	}
+}